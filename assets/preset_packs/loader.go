@@ -0,0 +1,20 @@
+package presetpacks
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// Preset packs shipped with each release. Add pack files to the packs
+// directory, see core.LoadPresetPack for the format.
+//
+//go:embed all:packs
+var packs embed.FS
+
+func FS() fs.FS {
+	sub, err := fs.Sub(packs, "packs")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 
 	"github.com/spf13/cobra"
 	"github.com/wowsims/wotlk/sim/core"
@@ -11,6 +13,8 @@ import (
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
+var combatLogFile string
+
 var simCmd = &cobra.Command{
 	Use:   "sim",
 	Short: "simulate items & settings",
@@ -21,6 +25,8 @@ func init() {
 	simCmd.Flags().StringVar(&infile, "infile", "input.json", "location of input file (RaidSimRequest in protojson format)")
 	simCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
 	simCmd.Flags().BoolVar(&verbose, "verbose", false, "print information during runtime")
+	simCmd.Flags().StringVar(&combatLogFile, "combatlog", "", "if set, also writes the sim's debug logs to this file in WoWCombatLog.txt format as the sim runs")
+	addCheckpointFlags(simCmd)
 	simCmd.MarkFlagRequired("infile")
 }
 
@@ -36,9 +42,53 @@ func simMain(cmd *cobra.Command, args []string) {
 		log.Fatalf("failed to load input json file: %s", err)
 	}
 
+	var combatLog *combatLogOutput
+	if combatLogFile != "" {
+		if checkpointFile != "" {
+			log.Fatal("--combatlog can't be used with --checkpoint, since debug logs can't be checkpointed")
+		}
+		if input.SimOptions == nil {
+			input.SimOptions = &proto.SimOptions{}
+		}
+		input.SimOptions.Debug = true
+
+		combatLog, err = createCombatLog(combatLogFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	var output []byte
+	// Ctrl-C stops the sim early, still writing the results collected so far.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if sessionTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sessionTime)
+		defer cancel()
+	}
+
 	reporter := make(chan *proto.ProgressMetrics, 10)
-	core.RunRaidSimAsync(input, reporter)
+	if checkpointFile != "" {
+		resume := &proto.SimCheckpoint{}
+		found, err := loadCheckpoint(checkpointFile, resume)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !found {
+			resume = nil
+		} else if verbose {
+			fmt.Printf("Resuming from checkpoint at %d iterations\n", resume.CompletedIterations)
+		}
+		save := func(checkpoint *proto.SimCheckpoint) error {
+			return saveCheckpoint(checkpointFile, checkpoint)
+		}
+		core.RunRaidSimWithCheckpointsAsync(ctx, input, resume, checkpointInterval, save, reporter)
+	} else if combatLog != nil {
+		core.RunRaidSimWithLogEventsAsync(ctx, input, combatLog.writer.WriteEvent, reporter)
+	} else {
+		core.RunRaidSimAsync(ctx, input, reporter)
+	}
 
 	var finalResult *proto.RaidSimResult
 	for v := range reporter {
@@ -51,6 +101,12 @@ func simMain(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	if combatLog != nil {
+		if err := combatLog.Close(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	output, err = protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(finalResult)
 	if err != nil {
 		log.Fatalf("failed to marshal final results: %s", err)
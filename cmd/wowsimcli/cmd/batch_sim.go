@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var (
+	batchInDir    string
+	batchManifest string
+	batchOutDir   string
+	batchSummary  string
+	batchWorkers  int
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "run many sims and write a CSV summary",
+	Long:  "run every request in a directory or manifest on a shared worker pool, writing each sim's result as JSON and a CSV summary of all of them",
+	RunE:  batchSimMain,
+}
+
+func init() {
+	batchCmd.Flags().StringVar(&batchInDir, "indir", "", "directory of input files (RaidSimRequest or sim settings files in protojson format)")
+	batchCmd.Flags().StringVar(&batchManifest, "manifest", "", "file listing input files, one per line as `path` or `name,path`. Paths are relative to the manifest")
+	batchCmd.Flags().StringVar(&batchOutDir, "outdir", "results", "directory to write per-sim JSON results to")
+	batchCmd.Flags().StringVar(&batchSummary, "summary", "", "location of the CSV summary, defaults to summary.csv in outdir")
+	batchCmd.Flags().IntVar(&batchWorkers, "workers", runtime.NumCPU(), "number of sims to run at once")
+	batchCmd.Flags().BoolVar(&verbose, "verbose", false, "print information during runtime")
+	batchCmd.MarkFlagsMutuallyExclusive("indir", "manifest")
+}
+
+type batchSim struct {
+	Name string
+	File string
+
+	Result *proto.RaidSimResult
+	Err    error
+}
+
+func batchSimMain(cmd *cobra.Command, args []string) error {
+	var sims []*batchSim
+	var err error
+	switch {
+	case batchInDir != "":
+		sims, err = batchSimsFromDir(batchInDir)
+	case batchManifest != "":
+		sims, err = batchSimsFromManifest(batchManifest)
+	default:
+		return fmt.Errorf("one of --indir or --manifest is required")
+	}
+	if err != nil {
+		return err
+	}
+	if len(sims) == 0 {
+		return fmt.Errorf("no input files found")
+	}
+
+	if err := os.MkdirAll(batchOutDir, 0777); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Ctrl-C stops all running sims early, still writing the results collected so far.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	runBatchSims(ctx, sims, batchWorkers)
+
+	for _, sim := range sims {
+		if sim.Result == nil {
+			continue
+		}
+		output, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(sim.Result)
+		if err != nil {
+			return fmt.Errorf("failed to marshal results of %s: %w", sim.Name, err)
+		}
+		if err := os.WriteFile(filepath.Join(batchOutDir, sim.Name+".json"), output, 0666); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+	}
+
+	summaryFile := batchSummary
+	if summaryFile == "" {
+		summaryFile = filepath.Join(batchOutDir, "summary.csv")
+	}
+	out, err := os.Create(summaryFile)
+	if err != nil {
+		return fmt.Errorf("failed to create summary file: %w", err)
+	}
+	defer out.Close()
+	if err := writeBatchSummary(out, sims); err != nil {
+		return fmt.Errorf("failed to write summary file: %w", err)
+	}
+	if verbose {
+		fmt.Printf("Wrote summary file: `%s` successfully.\n", summaryFile)
+	}
+	return nil
+}
+
+// Every .json file in the directory, named after the file.
+func batchSimsFromDir(dir string) ([]*batchSim, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return core.MapSlice(files, func(file string) *batchSim {
+		return &batchSim{
+			Name: strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)),
+			File: file,
+		}
+	}), nil
+}
+
+func batchSimsFromManifest(manifest string) ([]*batchSim, error) {
+	in, err := os.Open(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest %q: %w", manifest, err)
+	}
+	defer in.Close()
+
+	reader := csv.NewReader(in)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	names := make(map[string]bool, len(records))
+	sims := make([]*batchSim, 0, len(records))
+	for _, record := range records {
+		var sim batchSim
+		switch len(record) {
+		case 1:
+			sim.File = record[0]
+			sim.Name = strings.TrimSuffix(filepath.Base(sim.File), filepath.Ext(sim.File))
+		case 2:
+			sim.Name, sim.File = record[0], record[1]
+		default:
+			return nil, fmt.Errorf("invalid manifest line %q, expected `path` or `name,path`", strings.Join(record, ","))
+		}
+		if !filepath.IsAbs(sim.File) {
+			sim.File = filepath.Join(filepath.Dir(manifest), sim.File)
+		}
+		// Names are used for output files, so must be unique and stay inside
+		// the output directory.
+		if sim.Name == "" || sim.Name == "." || sim.Name == ".." || strings.ContainsAny(sim.Name, `/\`) {
+			return nil, fmt.Errorf("invalid sim name %q in manifest, names can't be paths", sim.Name)
+		}
+		if names[sim.Name] {
+			return nil, fmt.Errorf("duplicate sim name %q in manifest", sim.Name)
+		}
+		names[sim.Name] = true
+		sims = append(sims, &sim)
+	}
+	return sims, nil
+}
+
+// Loads a RaidSimRequest, or the request from a versioned sim settings file.
+func loadBatchRequest(file string) (*proto.RaidSimRequest, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if _, ok := fields["request"]; ok {
+		settings, err := core.LoadSettingsFile(data)
+		if err != nil {
+			return nil, err
+		}
+		return settings.Request, nil
+	}
+
+	request := &proto.RaidSimRequest{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func runBatchSims(ctx context.Context, sims []*batchSim, workers int) {
+	jobs := make(chan *batchSim)
+	var wg sync.WaitGroup
+	for i := 0; i < max(workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sim := range jobs {
+				request, err := loadBatchRequest(sim.File)
+				if err != nil {
+					sim.Err = fmt.Errorf("failed to load %q: %w", sim.File, err)
+					continue
+				}
+				sim.Result = core.RunRaidSimWithContext(ctx, request)
+				if sim.Result.ErrorResult != "" {
+					sim.Err = fmt.Errorf("%s", sim.Result.ErrorResult)
+				}
+				if verbose {
+					fmt.Printf("Finished %s\n", sim.Name)
+				}
+			}
+		}()
+	}
+
+	for _, sim := range sims {
+		jobs <- sim
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// Writes one row per sim, in input order.
+func writeBatchSummary(out io.Writer, sims []*batchSim) error {
+	writer := csv.NewWriter(out)
+	writer.Write([]string{"name", "file", "dps", "dps_stdev", "hps", "hps_stdev", "avg_duration", "error"})
+
+	formatFloat := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', 2, 64)
+	}
+	for _, sim := range sims {
+		row := []string{sim.Name, sim.File, "", "", "", "", "", ""}
+		if sim.Err != nil {
+			row[7] = sim.Err.Error()
+		} else {
+			metrics := sim.Result.RaidMetrics
+			row[2] = formatFloat(metrics.GetDps().GetAvg())
+			row[3] = formatFloat(metrics.GetDps().GetStdev())
+			row[4] = formatFloat(metrics.GetHps().GetAvg())
+			row[5] = formatFloat(metrics.GetHps().GetStdev())
+			row[6] = formatFloat(sim.Result.AvgIterationDuration)
+		}
+		writer.Write(row)
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func writeManifest(t *testing.T, contents string) string {
+	manifest := filepath.Join(t.TempDir(), "manifest.csv")
+	if err := os.WriteFile(manifest, []byte(contents), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return manifest
+}
+
+func TestBatchSimsFromManifest(t *testing.T) {
+	manifest := writeManifest(t, "# Comment\nsims/mage.json\nwarrior, sims/fury.json\n/abs/rogue.json\n")
+	dir := filepath.Dir(manifest)
+
+	sims, err := batchSimsFromManifest(manifest)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []batchSim{
+		{Name: "mage", File: filepath.Join(dir, "sims/mage.json")},
+		{Name: "warrior", File: filepath.Join(dir, "sims/fury.json")},
+		{Name: "rogue", File: "/abs/rogue.json"},
+	}
+	if len(sims) != len(expected) {
+		t.Fatalf("Expected %d sims, got %d", len(expected), len(sims))
+	}
+	for i, sim := range sims {
+		if sim.Name != expected[i].Name || sim.File != expected[i].File {
+			t.Errorf("Sim %d: expected %s at %s, got %s at %s", i, expected[i].Name, expected[i].File, sim.Name, sim.File)
+		}
+	}
+}
+
+func TestBatchSimsFromManifestErrors(t *testing.T) {
+	for _, tc := range []struct {
+		manifest string
+		err      string
+	}{
+		{manifest: "a.json\nother/a.json\n", err: "duplicate sim name"},
+		{manifest: "a,a.json\na,b.json\n", err: "duplicate sim name"},
+		{manifest: "../x,a.json\n", err: "invalid sim name"},
+		{manifest: "sub/x,a.json\n", err: "invalid sim name"},
+		{manifest: `sub\x,a.json` + "\n", err: "invalid sim name"},
+		{manifest: "..,a.json\n", err: "invalid sim name"},
+		{manifest: "a,b,c.json\n", err: "invalid manifest line"},
+	} {
+		_, err := batchSimsFromManifest(writeManifest(t, tc.manifest))
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("Manifest %q: expected error containing %q, got %v", tc.manifest, tc.err, err)
+		}
+	}
+}
+
+func TestWriteBatchSummary(t *testing.T) {
+	sims := []*batchSim{
+		{
+			Name: "mage",
+			File: "mage.json",
+			Result: &proto.RaidSimResult{
+				RaidMetrics: &proto.RaidMetrics{
+					Dps: &proto.DistributionMetrics{Avg: 5000.123, Stdev: 12.5},
+					Hps: &proto.DistributionMetrics{},
+				},
+				AvgIterationDuration: 180,
+			},
+		},
+		{Name: "broken", File: "broken.json", Err: errors.New("failed to load")},
+	}
+
+	var out strings.Builder
+	if err := writeBatchSummary(&out, sims); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := "name,file,dps,dps_stdev,hps,hps_stdev,avg_duration,error\n" +
+		"mage,mage.json,5000.12,12.50,0.00,0.00,180.00,\n" +
+		"broken,broken.json,,,,,,failed to load\n"
+	if out.String() != expected {
+		t.Errorf("Expected summary:\n%s\nGot:\n%s", expected, out.String())
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+var (
+	checkpointFile     string
+	checkpointInterval int32
+	sessionTime        time.Duration
+)
+
+func addCheckpointFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&checkpointFile, "checkpoint", "", "if set, periodically saves progress to this file, and resumes from it if it exists (ignoring the input file)")
+	cmd.Flags().Int32Var(&checkpointInterval, "checkpoint-interval", 10000, "iterations between checkpoints")
+	cmd.Flags().DurationVar(&sessionTime, "session-time", 0, "stop after this long, so a checkpointed sim can be continued in another session (0 for no limit)")
+}
+
+// Returns false if the file doesn't exist yet.
+func loadCheckpoint(file string, checkpoint googleProto.Message) (bool, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if err := googleProto.Unmarshal(data, checkpoint); err != nil {
+		return false, fmt.Errorf("invalid checkpoint file %q: %w", file, err)
+	}
+	return true, nil
+}
+
+// Replaces the file atomically, so a crash while saving leaves the previous
+// checkpoint intact.
+func saveCheckpoint(file string, checkpoint googleProto.Message) error {
+	data, err := googleProto.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	tmpFile := file + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0666); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, file)
+}
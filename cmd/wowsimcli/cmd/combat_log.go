@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/wotlk/sim/core"
+)
+
+var combatLogCmd = &cobra.Command{
+	Use:   "combatlog",
+	Short: "convert sim debug logs to WoWCombatLog.txt format",
+	Long:  "convert sim debug logs to WoWCombatLog.txt format",
+	RunE:  combatLogMain,
+}
+
+func init() {
+	combatLogCmd.Flags().StringVar(&infile, "infile", "", "location of input file (sim debug logs)")
+	combatLogCmd.Flags().StringVar(&outfile, "outfile", "WoWCombatLog.txt", "location of output file")
+	combatLogCmd.MarkFlagRequired("infile")
+}
+
+func combatLogMain(cmd *cobra.Command, args []string) error {
+	in, err := os.Open(infile)
+	if err != nil {
+		return fmt.Errorf("failed to open debug logs %q: %w", infile, err)
+	}
+	defer in.Close()
+
+	combatLog, err := createCombatLog(outfile)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(combatLog.writer, in); err != nil {
+		combatLog.file.Close()
+		return fmt.Errorf("failed to read debug logs %q: %w", infile, err)
+	}
+	return combatLog.Close()
+}
+
+// A combat log file, written as the sim's debug logs come in.
+type combatLogOutput struct {
+	file   *os.File
+	writer *core.CombatLogWriter
+}
+
+func createCombatLog(path string) (*combatLogOutput, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create combat log %q: %w", path, err)
+	}
+	return &combatLogOutput{
+		file:   file,
+		writer: core.NewCombatLogWriter(file, core.CombatLogWriterOptions{}),
+	}, nil
+}
+
+func (output *combatLogOutput) Close() error {
+	if err := output.writer.Close(); err != nil {
+		output.file.Close()
+		return fmt.Errorf("failed to write combat log: %w", err)
+	}
+	return output.file.Close()
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var (
+	importPlayer     string
+	importReplayFile string
+)
+
+var importLogCmd = &cobra.Command{
+	Use:   "importlog",
+	Short: "build a rotation from a WoWCombatLog.txt, or replay it in the sim",
+	Long:  "build a best-fit APL rotation from a player's casts in a WoWCombatLog.txt, or replay the exact casts in a sim and compare damage against the log",
+	RunE:  importLogMain,
+}
+
+func init() {
+	importLogCmd.Flags().StringVar(&infile, "infile", "WoWCombatLog.txt", "location of input combat log")
+	importLogCmd.Flags().StringVar(&importPlayer, "player", "", "name of the player to import")
+	importLogCmd.Flags().StringVar(&importReplayFile, "replay", "", "if set, replays the observed casts using this RaidSimRequest (protojson) for the player's setup")
+	importLogCmd.MarkFlagRequired("player")
+}
+
+func importLogMain(cmd *cobra.Command, args []string) error {
+	in, err := os.Open(infile)
+	if err != nil {
+		return fmt.Errorf("failed to open combat log %q: %w", infile, err)
+	}
+	defer in.Close()
+
+	combatLog, err := core.ParseCombatLog(in, importPlayer)
+	if err != nil {
+		return fmt.Errorf("failed to parse combat log: %w", err)
+	}
+
+	if importReplayFile == "" {
+		output, err := protojson.MarshalOptions{Multiline: true}.Marshal(combatLog.BestFitAPL())
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	return replayCombatLog(combatLog)
+}
+
+func replayCombatLog(combatLog *core.ParsedCombatLog) error {
+	data, err := os.ReadFile(importReplayFile)
+	if err != nil {
+		return fmt.Errorf("failed to load replay input %q: %w", importReplayFile, err)
+	}
+	input := &proto.RaidSimRequest{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, input); err != nil {
+		return fmt.Errorf("failed to load replay input: %w", err)
+	}
+
+	partyIndex, playerIndex := -1, -1
+	for i, party := range input.Raid.Parties {
+		for j, player := range party.Players {
+			if player.Name == importPlayer {
+				partyIndex, playerIndex = i, j
+			}
+		}
+	}
+	if playerIndex == -1 {
+		return fmt.Errorf("no player named %s in replay input", importPlayer)
+	}
+
+	input.Raid.Parties[partyIndex].Players[playerIndex].Rotation = combatLog.ReplayAPL()
+	input.Encounter.Duration = combatLog.Duration.Seconds()
+	input.Encounter.DurationVariation = 0
+
+	result := core.RunRaidSim(input)
+	if result.ErrorResult != "" {
+		return fmt.Errorf("replay sim failed: %s", result.ErrorResult)
+	}
+
+	metrics := result.RaidMetrics.Parties[partyIndex].Players[playerIndex]
+	fmt.Printf("%-24s %10s %10s %8s\n", "Action", "Log Avg", "Sim Avg", "Diff")
+	for _, comparison := range combatLog.CompareToSim(metrics, input.SimOptions.GetIterations()) {
+		diff := "n/a"
+		if comparison.SimAvgHit != 0 {
+			diff = fmt.Sprintf("%+.1f%%", (comparison.SimAvgHit/comparison.LogAvgHit-1)*100)
+		}
+		fmt.Printf("%-24s %10.0f %10.0f %8s\n", comparison.ActionID, comparison.LogAvgHit, comparison.SimAvgHit, diff)
+	}
+	return nil
+}
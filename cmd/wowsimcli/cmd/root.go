@@ -16,8 +16,13 @@ var rootCmd = &cobra.Command{
 func Execute(version string) {
 	rootCmd.AddCommand(newVersionCommand(version))
 	rootCmd.AddCommand(simCmd)
+	rootCmd.AddCommand(statWeightsCmd)
 	rootCmd.AddCommand(bulkCmd)
+	rootCmd.AddCommand(batchCmd)
+	rootCmd.AddCommand(serveCmd)
 	rootCmd.AddCommand(decodeLinkCmd)
+	rootCmd.AddCommand(combatLogCmd)
+	rootCmd.AddCommand(importLogCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	serveHost          string
+	serveMaxActive     int
+	serveMaxQueue      int
+	serveMaxIterations int64
+	serveMaxSimTime    time.Duration
+	serveCluster       []string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "run a gRPC sim server",
+	Long:  "run a long-running gRPC server for the sim APIs (see proto/grpc/sim_service.proto), queueing requests and limiting each one so large sims can't starve everyone else",
+	RunE:  serveMain,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveHost, "host", "localhost:3334", "address to listen on")
+	serveCmd.Flags().IntVar(&serveMaxActive, "max-active", runtime.NumCPU(), "number of sims to run at once")
+	serveCmd.Flags().IntVar(&serveMaxQueue, "max-queue", 100, "number of requests which may wait for a sim slot, others are rejected")
+	serveCmd.Flags().Int64Var(&serveMaxIterations, "max-iterations", 100000, "reject requests with more iterations than this across all their sims (0 for no limit)")
+	serveCmd.Flags().DurationVar(&serveMaxSimTime, "max-sim-time", time.Minute, "cancel sims which run longer than this, returning partial results (0 for no limit)")
+	serveCmd.Flags().StringSliceVar(&serveCluster, "cluster", nil, "comma-separated addresses of other sim servers, to run raid sims and stat weights across as workers")
+	serveCmd.Flags().BoolVar(&verbose, "verbose", false, "print information during runtime")
+}
+
+func serveMain(cmd *cobra.Command, args []string) error {
+	listener, err := net.Listen("tcp", serveHost)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", serveHost, err)
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(recoverUnary),
+		grpc.StreamInterceptor(recoverStream),
+	)
+	server := newSimServer(serveMaxActive, serveMaxQueue, serveMaxIterations, serveMaxSimTime)
+	if len(serveCluster) > 0 {
+		server.cluster, err = dialSimCluster(serveCluster)
+		if err != nil {
+			return err
+		}
+	}
+	grpcServer.RegisterService(&simServiceDesc, server)
+
+	// Ctrl-C stops accepting requests, and exits once the running ones finish.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+	}()
+
+	if verbose {
+		fmt.Printf("Listening on %s\n", listener.Addr())
+	}
+	return grpcServer.Serve(listener)
+}
+
+// The Sim service from proto/grpc/sim_service.proto. It's written out here
+// rather than generated, so only the CLI depends on gRPC.
+var simServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.Sim",
+	// Handlers cast to *simServer themselves.
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod("RaidSim", (*simServer).RaidSim),
+		unaryMethod("RaidSimShard", (*simServer).RaidSimShard),
+		unaryMethod("StatWeights", (*simServer).StatWeights),
+		unaryMethod("BulkSim", (*simServer).BulkSim),
+		unaryMethod("ComputeStats", (*simServer).ComputeStats),
+		unaryMethod("ServerStatus", (*simServer).ServerStatus),
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "RaidSimStream",
+			ServerStreams: true,
+			Handler: func(srv any, stream grpc.ServerStream) error {
+				request := &proto.RaidSimRequest{}
+				if err := stream.RecvMsg(request); err != nil {
+					return err
+				}
+				return srv.(*simServer).RaidSimStream(request, stream)
+			},
+		},
+	},
+	Metadata: "sim_service.proto",
+}
+
+func unaryMethod[Req any, PReq interface{ *Req }, Resp any](name string, handle func(*simServer, context.Context, PReq) (Resp, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			request := PReq(new(Req))
+			if err := dec(request); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, request any) (any, error) {
+				return handle(srv.(*simServer), ctx, request.(PReq))
+			}
+			if interceptor == nil {
+				return handler(ctx, request)
+			}
+			return interceptor(ctx, request, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/proto.Sim/" + name}, handler)
+		},
+	}
+}
+
+type simServer struct {
+	// Holds a token for each running sim.
+	slots         chan struct{}
+	maxQueued     int32
+	maxIterations int64
+	maxSimTime    time.Duration
+	// Set when this server is a coordinator, running sims on other servers.
+	cluster *core.SimCluster
+
+	queued    atomic.Int32
+	completed atomic.Int64
+	rejected  atomic.Int64
+}
+
+func newSimServer(maxActive int, maxQueued int, maxIterations int64, maxSimTime time.Duration) *simServer {
+	return &simServer{
+		slots:         make(chan struct{}, max(maxActive, 1)),
+		maxQueued:     int32(maxQueued),
+		maxIterations: maxIterations,
+		maxSimTime:    maxSimTime,
+	}
+}
+
+// Waits for a free sim slot, in the order requests arrived. Iterations is the
+// total across all the sims the request runs. Returns the context to run the
+// sim with, and a func to call once it's done.
+func (s *simServer) startSim(ctx context.Context, iterations int64) (context.Context, func(), error) {
+	if s.maxIterations > 0 && iterations > s.maxIterations {
+		s.rejected.Add(1)
+		return nil, nil, status.Errorf(codes.ResourceExhausted, "%d iterations is more than this server's limit of %d", iterations, s.maxIterations)
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		if s.queued.Add(1) > s.maxQueued {
+			s.queued.Add(-1)
+			s.rejected.Add(1)
+			return nil, nil, status.Error(codes.ResourceExhausted, "sim queue is full, try again later")
+		}
+		select {
+		case s.slots <- struct{}{}:
+			s.queued.Add(-1)
+		case <-ctx.Done():
+			s.queued.Add(-1)
+			return nil, nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+
+	simCtx, cancel := ctx, context.CancelFunc(func() {})
+	if s.maxSimTime > 0 {
+		simCtx, cancel = context.WithTimeout(ctx, s.maxSimTime)
+	}
+	return simCtx, func() {
+		cancel()
+		<-s.slots
+		s.completed.Add(1)
+	}, nil
+}
+
+func (s *simServer) RaidSim(ctx context.Context, request *proto.RaidSimRequest) (*proto.RaidSimResult, error) {
+	simCtx, done, err := s.startSim(ctx, int64(request.GetSimOptions().GetIterations()))
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	if s.cluster != nil {
+		return s.cluster.RunRaidSim(simCtx, request, nil), nil
+	}
+	return core.RunRaidSimWithContext(simCtx, request), nil
+}
+
+// Only counts the shard's own iterations against the quota, so coordinators can
+// split sims larger than any one worker allows.
+func (s *simServer) RaidSimShard(ctx context.Context, request *proto.RaidSimShardRequest) (*proto.RaidSimShardResult, error) {
+	simCtx, done, err := s.startSim(ctx, int64(request.EndIteration-request.StartIteration))
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return core.RunRaidSimShard(simCtx, request), nil
+}
+
+func (s *simServer) RaidSimStream(request *proto.RaidSimRequest, stream grpc.ServerStream) error {
+	simCtx, done, err := s.startSim(stream.Context(), int64(request.GetSimOptions().GetIterations()))
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	reporter := make(chan *proto.ProgressMetrics, 100)
+	if s.cluster != nil {
+		go s.cluster.RunRaidSim(simCtx, request, reporter)
+	} else {
+		core.RunRaidSimAsync(simCtx, request, reporter)
+	}
+	for progMetric := range reporter {
+		if err := stream.SendMsg(progMetric); err != nil {
+			// The client went away, which also cancels the sim through the
+			// stream context. Keep draining until it finishes.
+			continue
+		}
+		if progMetric.FinalRaidResult != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// Stat weights run a sim above and below for every stat, which all count
+// against the quota.
+func (s *simServer) StatWeights(ctx context.Context, request *proto.StatWeightsRequest) (*proto.StatWeightsResult, error) {
+	simCtx, done, err := s.startSim(ctx, core.StatWeightsIterations(request))
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	if s.cluster != nil {
+		return s.cluster.StatWeights(simCtx, request, nil), nil
+	}
+	return core.StatWeightsWithContext(simCtx, request), nil
+}
+
+// Counts every combo's iterations against the quota, so the combos are
+// generated before waiting for a slot.
+func (s *simServer) BulkSim(ctx context.Context, request *proto.BulkSimRequest) (*proto.BulkSimResult, error) {
+	iterations, err := core.BulkSimIterations(request)
+	if err != nil {
+		return &proto.BulkSimResult{ErrorResult: err.Error()}, nil
+	}
+	simCtx, done, err := s.startSim(ctx, iterations)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return core.BulkSim(simCtx, request, nil), nil
+}
+
+func (s *simServer) ComputeStats(ctx context.Context, request *proto.ComputeStatsRequest) (*proto.ComputeStatsResult, error) {
+	return core.ComputeStats(request), nil
+}
+
+func (s *simServer) ServerStatus(ctx context.Context, request *proto.ServerStatusRequest) (*proto.ServerStatusResult, error) {
+	return &proto.ServerStatusResult{
+		ActiveSims:    int32(len(s.slots)),
+		QueueDepth:    s.queued.Load(),
+		MaxActiveSims: int32(cap(s.slots)),
+		MaxQueueDepth: s.maxQueued,
+		CompletedSims: s.completed.Load(),
+		RejectedSims:  s.rejected.Load(),
+	}, nil
+}
+
+// Runs shards on another sim server.
+type grpcShardWorker struct {
+	conn *grpc.ClientConn
+}
+
+func (w grpcShardWorker) RunRaidSimShard(ctx context.Context, request *proto.RaidSimShardRequest) (*proto.RaidSimShardResult, error) {
+	result := &proto.RaidSimShardResult{}
+	if err := w.conn.Invoke(ctx, "/proto.Sim/RaidSimShard", request, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func dialSimCluster(addresses []string) (*core.SimCluster, error) {
+	cluster := &core.SimCluster{}
+	for _, address := range addresses {
+		// Connections are made lazily, so workers which are down only fail
+		// their shards.
+		conn, err := grpc.Dial(address,
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			// Shard results include every iteration's values, which easily
+			// exceed the default limit of 4MB.
+			grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(math.MaxInt32)),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("invalid worker address %q: %w", address, err)
+		}
+		cluster.Workers = append(cluster.Workers, grpcShardWorker{conn: conn})
+	}
+	return cluster, nil
+}
+
+// Invalid requests can panic outside of the sim's own recovery, e.g. in
+// ComputeStats, which shouldn't take down the whole server.
+func recoverUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("Panic in %s: %v", info.FullMethod, p)
+			err = status.Errorf(codes.Internal, "%v", p)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func recoverStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("Panic in %s: %v", info.FullMethod, p)
+			err = status.Errorf(codes.Internal, "%v", p)
+		}
+	}()
+	return handler(srv, stream)
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Serves a simServer over an in-memory listener, returning a client for it.
+func newTestSimServer(t *testing.T, maxActive int, maxQueued int, maxIterations int64) (*simServer, *grpc.ClientConn) {
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(recoverUnary))
+	server := newSimServer(maxActive, maxQueued, maxIterations, 0)
+	grpcServer.RegisterService(&simServiceDesc, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial test server: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return server, conn
+}
+
+func serverStatus(t *testing.T, conn *grpc.ClientConn) *proto.ServerStatusResult {
+	result := &proto.ServerStatusResult{}
+	if err := conn.Invoke(context.Background(), "/proto.Sim/ServerStatus", &proto.ServerStatusRequest{}, result); err != nil {
+		t.Fatalf("ServerStatus failed: %s", err)
+	}
+	return result
+}
+
+// Runs quickly, since there's no raid to sim.
+var emptyRaidSimRequest = &proto.RaidSimRequest{SimOptions: &proto.SimOptions{Iterations: 1}}
+
+func TestSimServerQueueFull(t *testing.T) {
+	server, conn := newTestSimServer(t, 1, 0, 0)
+
+	// Hold the only slot, with no room to queue.
+	_, done, err := server.startSim(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Failed to start sim: %s", err)
+	}
+	err = conn.Invoke(context.Background(), "/proto.Sim/RaidSim", emptyRaidSimRequest, &proto.RaidSimResult{})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected the request to be rejected, got %v", err)
+	}
+
+	done()
+	if err := conn.Invoke(context.Background(), "/proto.Sim/RaidSim", emptyRaidSimRequest, &proto.RaidSimResult{}); err != nil {
+		t.Fatalf("Expected the request to run once the slot is free, got %v", err)
+	}
+	if result := serverStatus(t, conn); result.ActiveSims != 0 || result.CompletedSims != 2 || result.RejectedSims != 1 {
+		t.Errorf("Unexpected server status %v", result)
+	}
+}
+
+func TestSimServerReleasesSlots(t *testing.T) {
+	server, conn := newTestSimServer(t, 1, 1, 0)
+
+	_, done, err := server.startSim(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Failed to start sim: %s", err)
+	}
+
+	// Waits in the queue until the slot is released.
+	finished := make(chan error)
+	go func() {
+		finished <- conn.Invoke(context.Background(), "/proto.Sim/RaidSim", emptyRaidSimRequest, &proto.RaidSimResult{})
+	}()
+	for serverStatus(t, conn).QueueDepth != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if result := serverStatus(t, conn); result.ActiveSims != 1 {
+		t.Errorf("Expected 1 active sim, got %v", result)
+	}
+
+	done()
+	if err := <-finished; err != nil {
+		t.Fatalf("Queued request failed: %s", err)
+	}
+	if result := serverStatus(t, conn); result.ActiveSims != 0 || result.QueueDepth != 0 || result.CompletedSims != 2 {
+		t.Errorf("Expected every slot to be released, got %v", result)
+	}
+
+	// Cancelled requests leave the queue too.
+	_, done, _ = server.startSim(context.Background(), 1)
+	defer done()
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		finished <- conn.Invoke(ctx, "/proto.Sim/RaidSim", emptyRaidSimRequest, &proto.RaidSimResult{})
+	}()
+	for serverStatus(t, conn).QueueDepth != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-finished; status.Code(err) != codes.Canceled {
+		t.Fatalf("Expected the request to be cancelled, got %v", err)
+	}
+	for serverStatus(t, conn).QueueDepth != 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSimServerIterationQuota(t *testing.T) {
+	_, conn := newTestSimServer(t, 1, 0, 1000)
+
+	expectRejected := func(method string, request any, response any) {
+		t.Helper()
+		err := conn.Invoke(context.Background(), "/proto.Sim/"+method, request, response)
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("%s: expected the request to be rejected, got %v", method, err)
+		}
+	}
+
+	expectRejected("RaidSim", &proto.RaidSimRequest{SimOptions: &proto.SimOptions{Iterations: 1001}}, &proto.RaidSimResult{})
+
+	// Only 400 iterations, but once for the baseline and every stat.
+	expectRejected("StatWeights", &proto.StatWeightsRequest{
+		SimOptions:      &proto.SimOptions{Iterations: 400},
+		StatsToWeigh:    []proto.Stat{proto.Stat_StatSpellPower, proto.Stat_StatSpellHit},
+		EpReferenceStat: proto.Stat_StatSpellPower,
+	}, &proto.StatWeightsResult{})
+
+	// 600 iterations for each of the 3 pets.
+	equipment := &proto.EquipmentSpec{Items: make([]*proto.ItemSpec, len(proto.ItemSlot_name))}
+	for i := range equipment.Items {
+		equipment.Items[i] = &proto.ItemSpec{}
+	}
+	expectRejected("BulkSim", &proto.BulkSimRequest{
+		BaseSettings: &proto.RaidSimRequest{
+			Raid: &proto.Raid{Parties: []*proto.Party{{Players: []*proto.Player{{
+				Name:      "Player",
+				Equipment: equipment,
+				Spec: &proto.Player_Hunter{Hunter: &proto.Hunter{Options: &proto.Hunter_Options{
+					PetType: proto.Hunter_Options_Wolf,
+				}}},
+			}}}}},
+		},
+		BulkSettings: &proto.BulkSettings{
+			IterationsPerCombo: 600,
+			PetFamilySweep: &proto.PetFamilySweepSettings{Variants: []*proto.PetFamilyVariant{
+				{PetType: proto.Hunter_Options_Raptor},
+				{PetType: proto.Hunter_Options_Cat},
+			}},
+		},
+	}, &proto.BulkSimResult{})
+
+	if result := serverStatus(t, conn); result.RejectedSims != 3 {
+		t.Errorf("Expected 3 rejected sims, got %v", result)
+	}
+
+	if err := conn.Invoke(context.Background(), "/proto.Sim/RaidSim", emptyRaidSimRequest, &proto.RaidSimResult{}); err != nil {
+		t.Errorf("Expected a request within the quota to run, got %v", err)
+	}
+}
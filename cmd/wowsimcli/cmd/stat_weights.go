@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var statWeightsCmd = &cobra.Command{
+	Use:   "statweights",
+	Short: "calculate stat weights",
+	Long:  "calculate stat weights and EP values, optionally checkpointing so long runs can be resumed or split across sessions",
+	RunE:  statWeightsMain,
+}
+
+func init() {
+	statWeightsCmd.Flags().StringVar(&infile, "infile", "input.json", "location of input file (StatWeightsRequest in protojson format)")
+	statWeightsCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
+	statWeightsCmd.Flags().BoolVar(&verbose, "verbose", false, "print information during runtime")
+	addCheckpointFlags(statWeightsCmd)
+}
+
+func statWeightsMain(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(infile)
+	if err != nil {
+		return fmt.Errorf("failed to load input json file %q: %w", infile, err)
+	}
+	request := &proto.StatWeightsRequest{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, request); err != nil {
+		return fmt.Errorf("failed to load input json file: %w", err)
+	}
+	if request.SimOptions == nil {
+		request.SimOptions = &proto.SimOptions{}
+	}
+
+	// Ctrl-C stops early, with weights from the iterations completed so far.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if sessionTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sessionTime)
+		defer cancel()
+	}
+
+	reporter := make(chan *proto.ProgressMetrics, 100)
+	if checkpointFile != "" {
+		resume := &proto.StatWeightsCheckpoint{}
+		found, err := loadCheckpoint(checkpointFile, resume)
+		if err != nil {
+			return err
+		}
+		if !found {
+			resume = nil
+		} else if verbose {
+			fmt.Printf("Resuming from checkpoint with %d sims\n", len(resume.Sims))
+		}
+		save := func(checkpoint *proto.StatWeightsCheckpoint) error {
+			return saveCheckpoint(checkpointFile, checkpoint)
+		}
+		go func() {
+			result := core.StatWeightsWithCheckpoints(ctx, request, resume, checkpointInterval, save, reporter)
+			reporter <- &proto.ProgressMetrics{FinalWeightResult: result}
+		}()
+	} else {
+		core.StatWeightsAsync(ctx, request, reporter)
+	}
+
+	var result *proto.StatWeightsResult
+	for progress := range reporter {
+		if progress.FinalWeightResult != nil {
+			result = progress.FinalWeightResult
+			break
+		}
+		if verbose {
+			fmt.Printf("Stat Weights Progress: %d / %d sims, %d / %d iterations\n", progress.CompletedSims, progress.TotalSims, progress.CompletedIterations, progress.TotalIterations)
+		}
+	}
+
+	output, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal final results: %w", err)
+	}
+	if outfile == "" {
+		fmt.Print(string(output))
+		return nil
+	}
+	if err := os.WriteFile(outfile, output, 0666); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if verbose {
+		fmt.Printf("Wrote output file: `%s` successfully.\n", outfile)
+	}
+	return nil
+}
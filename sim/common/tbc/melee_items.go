@@ -70,6 +70,7 @@ func init() {
 			FlatThreatBonus:  63,
 
 			ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
+				numHits := min(numHits, sim.GetNumActiveTargets())
 				curTarget := target
 				for hitIndex := int32(0); hitIndex < numHits; hitIndex++ {
 					result := spell.CalcDamage(sim, curTarget, 0, spell.OutcomeMagicHit)
@@ -232,11 +232,9 @@ func init() {
 		character := agent.GetCharacter()
 
 		procAura := CreateBlackMagicProcAura(character)
-		icd := core.Cooldown{
-			Timer:    character.NewTimer(),
-			Duration: time.Second * 35,
-		}
-		procAura.Icd = &icd
+		icd := character.NewInternalCooldown(time.Second * 35)
+		procAura.Icd = &icd.Cooldown
+		icdEffect := icd.ForEffect(procAura.ActionID)
 
 		aura := character.GetOrRegisterAura(core.Aura{
 			Label:    "Black Magic",
@@ -252,8 +250,8 @@ func init() {
 					return
 				}
 
-				if icd.IsReady(sim) && sim.RandomFloat("Black Magic") < 0.35 {
-					icd.Use(sim)
+				if icdEffect.IsReady(sim, 0.35) && sim.RandomFloat("Black Magic") < 0.35 {
+					icdEffect.Use(sim)
 					procAura.Activate(sim)
 				}
 			},
@@ -268,79 +266,49 @@ func init() {
 		w.BaseDamageMax += 15
 	})
 
-	core.NewEnchantEffect(3603, func(agent core.Agent) {
-		character := agent.GetCharacter()
-		actionID := core.ActionID{SpellID: 54757}
-
-		spell := character.GetOrRegisterSpell(core.SpellConfig{
-			ActionID:    actionID,
-			SpellSchool: core.SpellSchoolFire,
-			ProcMask:    core.ProcMaskEmpty,
-			Flags:       core.SpellFlagNoOnCastComplete,
-
-			Cast: core.CastConfig{
-				CD: core.Cooldown{
-					Timer:    character.NewTimer(),
-					Duration: time.Second * 45,
-				},
-				SharedCD: core.Cooldown{
-					Timer:    character.GetOffensiveTrinketCD(),
-					Duration: time.Second * 10,
+	core.NewTinkerEffect(3603, core.TinkerConfig{
+		Cooldown:       time.Second * 45,
+		SharedCategory: core.SharedCooldownOffensiveTrinket,
+		SharedDuration: time.Second * 10,
+		Type:           core.CooldownTypeDPS,
+		Spell: func(character *core.Character) core.SpellConfig {
+			return core.SpellConfig{
+				ActionID:    core.ActionID{SpellID: 54757},
+				SpellSchool: core.SpellSchoolFire,
+				ProcMask:    core.ProcMaskEmpty,
+				Flags:       core.SpellFlagNoOnCastComplete,
+
+				DamageMultiplier: 1,
+				CritMultiplier:   character.DefaultSpellCritMultiplier(),
+				ThreatMultiplier: 1,
+
+				ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
+					spell.CalcAndDealDamage(sim, target, sim.Roll(1654, 2020), spell.OutcomeMagicCrit)
 				},
-			},
-
-			DamageMultiplier: 1,
-			CritMultiplier:   character.DefaultSpellCritMultiplier(),
-			ThreatMultiplier: 1,
-
-			ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
-				spell.CalcAndDealDamage(sim, target, sim.Roll(1654, 2020), spell.OutcomeMagicCrit)
-			},
-		})
-
-		character.AddMajorCooldown(core.MajorCooldown{
-			Spell:    spell,
-			Priority: core.CooldownPriorityLow, // Use low prio so other actives get used first.
-			Type:     core.CooldownTypeDPS,
-		})
+			}
+		},
 	})
 
-	core.NewEnchantEffect(3604, func(agent core.Agent) {
-		character := agent.GetCharacter()
-		actionID := core.ActionID{SpellID: 54758}
-
-		procAura := character.NewTemporaryStatsAura("Hyperspeed Acceleration", actionID, stats.Stats{stats.MeleeHaste: 340, stats.SpellHaste: 340}, time.Second*12)
-
-		spell := character.GetOrRegisterSpell(core.SpellConfig{
-			ActionID:    actionID,
-			SpellSchool: core.SpellSchoolPhysical | core.SpellSchoolMagic,
-			Flags:       core.SpellFlagNoOnCastComplete,
-
-			Cast: core.CastConfig{
-				CD: core.Cooldown{
-					Timer:    character.NewTimer(),
-					Duration: time.Second * 60,
+	core.NewTinkerEffect(3604, core.TinkerConfig{
+		Cooldown: time.Second * 60,
+		// Shared CD with Offensive trinkets has been removed.
+		// https://twitter.com/AggrendWoW/status/1579664462843633664
+		// Change possibly temporary, but developers have confirmed it was intended.
+		Type: core.CooldownTypeDPS,
+		Spell: func(character *core.Character) core.SpellConfig {
+			actionID := core.ActionID{SpellID: 54758}
+			procAura := character.NewTemporaryStatsAura("Hyperspeed Acceleration", actionID, stats.Stats{stats.MeleeHaste: 340, stats.SpellHaste: 340}, time.Second*12)
+
+			return core.SpellConfig{
+				ActionID:    actionID,
+				SpellSchool: core.SpellSchoolPhysical | core.SpellSchoolMagic,
+				Flags:       core.SpellFlagNoOnCastComplete,
+
+				ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
+					procAura.Activate(sim)
 				},
-				// Shared CD with Offensive trinkets has been removed.
-				// https://twitter.com/AggrendWoW/status/1579664462843633664
-				// Change possibly temporary, but developers have confirmed it was intended.
-
-				// SharedCD: core.Cooldown{
-				// 	Timer:    character.GetOffensiveTrinketCD(),
-				// 	Duration: time.Second * 12,
-				// },
-			},
-
-			ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
-				procAura.Activate(sim)
-			},
-		})
-
-		character.AddMajorCooldown(core.MajorCooldown{
-			Spell:    spell,
-			Priority: core.CooldownPriorityLow, // Use low prio so other actives get used first.
-			Type:     core.CooldownTypeDPS,
-		})
+			}
+		},
 	})
 
 	core.NewEnchantEffect(3722, func(agent core.Agent) {
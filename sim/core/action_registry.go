@@ -0,0 +1,81 @@
+package core
+
+import (
+	"strconv"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Names and other metadata of spells and items, from the generated database,
+// so that logs and exports can name actions without the UI's lookups. Like
+// ItemsByID, this is only loaded with the 'with_db' tag, so callers must
+// handle missing entries.
+type ActionMetadata struct {
+	Name string
+	Icon string
+
+	// Spells only.
+	Rank   int32       // 0 for spells without ranks.
+	School SpellSchool // SpellSchoolNone if the database doesn't know it.
+}
+
+var spellMetadataByID = map[int32]ActionMetadata{}
+var itemMetadataByID = map[int32]ActionMetadata{}
+
+func addActionMetadata(spellIcons []*proto.IconData, itemIcons []*proto.IconData) {
+	for _, icon := range spellIcons {
+		metadata := ActionMetadata{Name: icon.Name, Icon: icon.Icon, Rank: icon.Rank}
+		for _, school := range icon.Schools {
+			metadata.School |= SpellSchoolFromProto(school)
+		}
+		spellMetadataByID[icon.Id] = metadata
+	}
+	for _, icon := range itemIcons {
+		itemMetadataByID[icon.Id] = ActionMetadata{Name: icon.Name, Icon: icon.Icon}
+	}
+}
+
+// Returns the metadata of the action's spell or item, and whether it was found.
+func (actionID ActionID) Metadata() (ActionMetadata, bool) {
+	if actionID.SpellID != 0 {
+		metadata, ok := spellMetadataByID[actionID.SpellID]
+		return metadata, ok
+	}
+	if actionID.ItemID != 0 {
+		if metadata, ok := itemMetadataByID[actionID.ItemID]; ok {
+			return metadata, true
+		}
+		// Equippable items only have icons in the UI database.
+		if item, ok := ItemsByID[actionID.ItemID]; ok {
+			return ActionMetadata{Name: item.Name}, true
+		}
+	}
+	return ActionMetadata{}, false
+}
+
+// Returns a human-readable name for the action, e.g. "Fireball (Rank 16)", or
+// String() if it isn't in the database. Tags aren't included.
+func (actionID ActionID) Name() string {
+	metadata, ok := actionID.Metadata()
+	if !ok || metadata.Name == "" {
+		return actionID.String()
+	}
+	if metadata.Rank != 0 {
+		return metadata.Name + " (Rank " + strconv.Itoa(int(metadata.Rank)) + ")"
+	}
+	return metadata.Name
+}
+
+// Returns the IDs of spells registered more than once on this unit. GetSpell
+// only finds the first of these, so they usually need distinct Tags.
+func (unit *Unit) duplicateSpellIDs() []ActionID {
+	var duplicates []ActionID
+	seen := make(map[ActionID]int, len(unit.Spellbook))
+	for _, spell := range unit.Spellbook {
+		seen[spell.ActionID]++
+		if seen[spell.ActionID] == 2 {
+			duplicates = append(duplicates, spell.ActionID)
+		}
+	}
+	return duplicates
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func registerTestActionMetadata(t *testing.T) {
+	addActionMetadata(
+		[]*proto.IconData{
+			{Id: 999001, Name: "Frostfire Bolt", Rank: 2, Schools: []proto.SpellSchool{proto.SpellSchool_SpellSchoolFrost, proto.SpellSchool_SpellSchoolFire}},
+			{Id: 999002, Name: "Bloodlust"},
+		},
+		[]*proto.IconData{
+			{Id: 999003, Name: "Potion of Speed"},
+		})
+	t.Cleanup(func() {
+		delete(spellMetadataByID, 999001)
+		delete(spellMetadataByID, 999002)
+		delete(itemMetadataByID, 999003)
+	})
+}
+
+func TestActionIDName(t *testing.T) {
+	registerTestActionMetadata(t)
+
+	cases := []struct {
+		actionID ActionID
+		expected string
+	}{
+		{ActionID{SpellID: 999001}, "Frostfire Bolt (Rank 2)"},
+		{ActionID{SpellID: 999002, Tag: 1}, "Bloodlust"},
+		{ActionID{ItemID: 999003}, "Potion of Speed"},
+		{ActionID{SpellID: 999004}, ActionID{SpellID: 999004}.String()},
+		{ActionID{OtherID: proto.OtherAction_OtherActionAttack}, ActionID{OtherID: proto.OtherAction_OtherActionAttack}.String()},
+	}
+	for _, c := range cases {
+		if actual := c.actionID.Name(); actual != c.expected {
+			t.Errorf("Name of %s: expected %q, got %q", c.actionID, c.expected, actual)
+		}
+	}
+
+	if metadata, _ := (ActionID{SpellID: 999001}).Metadata(); metadata.School != SpellSchoolFire|SpellSchoolFrost {
+		t.Errorf("Expected Fire | Frost school, got %d", metadata.School)
+	}
+}
+
+func TestCombatLogSpellMetadata(t *testing.T) {
+	registerTestActionMetadata(t)
+
+	if spell := registrySpell(ActionID{SpellID: 999001}).String(); spell != `999001,"Frostfire Bolt",0x14` {
+		t.Errorf(`Expected 999001,"Frostfire Bolt",0x14, got %s`, spell)
+	}
+	if spell := registrySpell(ActionID{SpellID: 999004}).String(); spell != `999004,"Spell 999004",0x1` {
+		t.Errorf(`Expected 999004,"Spell 999004",0x1, got %s`, spell)
+	}
+}
+
+func TestDuplicateSpellIDs(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+
+	config := SpellConfig{ActionID: ActionID{SpellID: 999005}}
+	fa.RegisterSpell(config)
+	if duplicates := fa.duplicateSpellIDs(); len(duplicates) != 0 {
+		t.Fatalf("Expected no duplicates, got %v", duplicates)
+	}
+
+	fa.RegisterSpell(config)
+	if duplicates := fa.duplicateSpellIDs(); len(duplicates) != 1 || duplicates[0] != config.ActionID {
+		t.Fatalf("Expected %s to be a duplicate, got %v", config.ActionID, duplicates)
+	}
+}
@@ -0,0 +1,221 @@
+package core
+
+import (
+	"time"
+
+	goproto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+// An add from one of the encounter's add waves, see proto.AddWave.
+type encounterAdd struct {
+	target   *Target
+	spawnAt  time.Duration
+	lifetime time.Duration // 0 if the add stays until the end of the fight.
+	health   float64       // 0 if the add can't be killed.
+	mustDie  bool
+
+	alive         bool
+	damageTaken   float64
+	despawnAction *PendingAction
+}
+
+func numAddWaveTargets(encounter *proto.Encounter) int {
+	numAdds := 0
+	for _, wave := range encounter.AddWaves {
+		numAdds += int(max(wave.Count, 1))
+	}
+	return numAdds
+}
+
+// Returns a copy of the encounter with an extra target for each add in its add
+// waves, or the encounter itself if it has none.
+func expandAddWaves(encounter *proto.Encounter) *proto.Encounter {
+	if len(encounter.AddWaves) == 0 {
+		return encounter
+	}
+
+	expanded := goproto.Clone(encounter).(*proto.Encounter)
+	// Adds can't be the primary target, since they aren't always there.
+	if len(expanded.Targets) == 0 {
+		expanded.Targets = append(expanded.Targets, &proto.Target{})
+	}
+	for _, wave := range expanded.AddWaves {
+		for i := int32(0); i < max(wave.Count, 1); i++ {
+			add := &proto.Target{Level: CharacterLevel}
+			if wave.Target != nil {
+				add = goproto.Clone(wave.Target).(*proto.Target)
+			}
+			if len(add.Stats) < int(stats.Len) {
+				add.Stats = append(add.Stats, make([]float64, int(stats.Len)-len(add.Stats))...)
+			}
+			if wave.Health > 0 {
+				add.Stats[stats.Health] = wave.Health
+			}
+			if wave.Armor > 0 {
+				add.Stats[stats.Armor] = wave.Armor
+			}
+			expanded.Targets = append(expanded.Targets, add)
+		}
+	}
+	return expanded
+}
+
+// Marks the targets which were added by expandAddWaves().
+func (encounter *Encounter) setupAdds(options *proto.Encounter) {
+	index := len(encounter.Targets) - numAddWaveTargets(options)
+	encounter.addsByIndex = make([]*encounterAdd, len(encounter.Targets))
+	for _, wave := range options.AddWaves {
+		for i := int32(0); i < max(wave.Count, 1); i++ {
+			target := encounter.Targets[index]
+			add := &encounterAdd{
+				target:   target,
+				spawnAt:  DurationFromSeconds(wave.SpawnTime),
+				lifetime: DurationFromSeconds(wave.Lifetime),
+				health:   target.stats[stats.Health],
+				mustDie:  wave.MustDie,
+			}
+			encounter.adds = append(encounter.adds, add)
+			encounter.addsByIndex[index] = add
+			index++
+		}
+	}
+	encounter.activeTargetUnits = make([]*Unit, 0, len(encounter.TargetUnits))
+}
+
+// Despawns all adds, and schedules their waves to spawn.
+func (encounter *Encounter) resetAdds(sim *Simulation) {
+	if len(encounter.adds) == 0 {
+		return
+	}
+
+	for _, add := range encounter.adds {
+		add.alive = false
+		add.damageTaken = 0
+		add.despawnAction = nil
+		add.target.enabled = false
+		if add.target.gcdAction != nil {
+			add.target.CancelGCDTimer(sim)
+		}
+		StartDelayedAction(sim, DelayedActionOptions{
+			DoAt:     add.spawnAt,
+			OnAction: add.spawn,
+		})
+	}
+}
+
+func (add *encounterAdd) spawn(sim *Simulation) {
+	add.alive = true
+	add.target.enabled = true
+	sim.Encounter.TargetUnits = append(sim.Encounter.TargetUnits, &add.target.Unit)
+	sim.Encounter.updateAOECapMultiplier()
+	if sim.Log != nil {
+		add.target.Log(sim, "Spawned")
+	}
+
+	add.target.AutoAttacks.EnableAutoSwing(sim)
+	add.target.SetGCDTimer(sim, max(sim.CurrentTime, 0))
+
+	if add.lifetime > 0 && !add.mustDie {
+		add.despawnAction = StartDelayedAction(sim, DelayedActionOptions{
+			DoAt:     sim.CurrentTime + add.lifetime,
+			OnAction: add.despawn,
+		})
+	}
+}
+
+func (add *encounterAdd) despawn(sim *Simulation) {
+	if !add.alive {
+		return
+	}
+	add.alive = false
+	if add.despawnAction != nil {
+		add.despawnAction.Cancel(sim)
+		add.despawnAction = nil
+	}
+	if sim.Log != nil {
+		add.target.Log(sim, "Despawned")
+	}
+
+	sim.Encounter.removeTarget(sim, add.target)
+}
+
+// Kills adds once they've taken their health in damage. Returns whether the
+// target is an add.
+func (encounter *Encounter) damageAdd(sim *Simulation, target *Unit, damage float64) bool {
+	add := encounter.addsByIndex[target.Index]
+	if add == nil {
+		return false
+	}
+	if !add.alive || add.health <= 0 || add.damageTaken >= add.health {
+		return true
+	}
+
+	add.damageTaken += damage
+	if add.damageTaken >= add.health {
+		if sim.Log != nil {
+			add.target.Log(sim, "Killed")
+		}
+		// Despawning expires the add's auras, so wait until the current spell
+		// has finished with them.
+		StartDelayedAction(sim, DelayedActionOptions{
+			DoAt:     sim.CurrentTime,
+			OnAction: add.despawn,
+		})
+	}
+	return true
+}
+
+// Whether damage against the target counts as priority damage, i.e. it's one
+// of the regular targets or an add which must die.
+func (encounter *Encounter) isPriorityTarget(target *Unit) bool {
+	if encounter.addsByIndex == nil {
+		return true
+	}
+	add := encounter.addsByIndex[target.Index]
+	return add == nil || add.mustDie
+}
+
+// Splits a unit's damage between priority targets and other adds, for
+// encounters with add waves.
+type damageSplitMetrics struct {
+	// Damage in the current iteration.
+	priorityDamage float64
+	aoeDamage      float64
+
+	// DPS summed over all iterations.
+	priorityDpsSum float64
+	aoeDpsSum      float64
+}
+
+func (dsm *damageSplitMetrics) add(target *Unit, damage float64) {
+	if target.Env.Encounter.isPriorityTarget(target) {
+		dsm.priorityDamage += damage
+	} else {
+		dsm.aoeDamage += damage
+	}
+}
+
+func (dsm *damageSplitMetrics) reset() {
+	dsm.priorityDamage = 0
+	dsm.aoeDamage = 0
+}
+
+func (dsm *damageSplitMetrics) doneIteration(sim *Simulation) {
+	dsm.priorityDpsSum += dsm.priorityDamage / sim.Duration.Seconds()
+	dsm.aoeDpsSum += dsm.aoeDamage / sim.Duration.Seconds()
+}
+
+func (dsm *damageSplitMetrics) merge(other *damageSplitMetrics) {
+	dsm.priorityDpsSum += other.priorityDpsSum
+	dsm.aoeDpsSum += other.aoeDpsSum
+}
+
+func (dsm *damageSplitMetrics) ToProto(n float64) *proto.DamageSplitMetrics {
+	return &proto.DamageSplitMetrics{
+		PriorityDps: dsm.priorityDpsSum / n,
+		AoeDps:      dsm.aoeDpsSum / n,
+	}
+}
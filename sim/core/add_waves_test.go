@@ -0,0 +1,142 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func addWavesTestRequest() *proto.RaidSimRequest {
+	request := tankSimRequest(&proto.SimOptions{Iterations: 20, RandomSeed: 101})
+	request.Encounter.AddWaves = []*proto.AddWave{
+		{Count: 2, SpawnTime: 5, Lifetime: 10},
+		{SpawnTime: 10, Health: 100, Armor: 500, Lifetime: 1, MustDie: true},
+	}
+	return request
+}
+
+func TestAddWaveSpawns(t *testing.T) {
+	sim := NewSim(addWavesTestRequest())
+	encounter := &sim.Encounter
+
+	if len(encounter.AllTargetUnits) != 4 || len(encounter.adds) != 3 {
+		t.Fatalf("Expected 1 target and 3 adds, got %d targets and %d adds", len(encounter.AllTargetUnits), len(encounter.adds))
+	}
+	if armor := encounter.adds[2].target.GetStat(stats.Armor); armor != 500 {
+		t.Fatalf("Expected the wave's armor to replace the add's, got %f", armor)
+	}
+
+	numTargets := &APLValueNumberTargets{}
+	sim.reset()
+	if len(encounter.TargetUnits) != 1 || numTargets.GetInt(sim) != 1 {
+		t.Fatalf("Expected only the boss before any adds spawn, got %d targets", len(encounter.TargetUnits))
+	}
+	runSimUntil(sim, time.Second*5)
+	if numTargets.GetInt(sim) != 3 {
+		t.Fatalf("Expected 3 targets after the first wave, got %d", numTargets.GetInt(sim))
+	}
+	runSimUntil(sim, time.Second*12)
+	if numTargets.GetInt(sim) != 4 {
+		t.Fatalf("Expected the must die add to ignore its lifetime, got %d targets", numTargets.GetInt(sim))
+	}
+	runSimUntil(sim, time.Second*15)
+	if numTargets.GetInt(sim) != 2 || encounter.adds[0].alive || encounter.adds[1].alive {
+		t.Fatalf("Expected the first wave to despawn, got %d targets", numTargets.GetInt(sim))
+	}
+
+	// Killing an add takes it out of the fight, and doesn't count towards the
+	// encounter's health.
+	mustDie := encounter.adds[2]
+	damageTaken := encounter.DamageTaken
+	if !encounter.damageAdd(sim, &mustDie.target.Unit, 60) || encounter.damageAdd(sim, encounter.TargetUnits[0], 60) {
+		t.Fatalf("Expected only the add to be treated as an add")
+	}
+	encounter.damageAdd(sim, &mustDie.target.Unit, 60)
+	runSimUntil(sim, sim.CurrentTime)
+	if mustDie.alive || numTargets.GetInt(sim) != 1 {
+		t.Fatalf("Expected the must die add to be killed, got %d targets", numTargets.GetInt(sim))
+	}
+	if encounter.DamageTaken != damageTaken {
+		t.Fatalf("Expected damage to adds not to count towards the encounter's health")
+	}
+}
+
+func TestAddWaveNextTarget(t *testing.T) {
+	sim := NewSim(addWavesTestRequest())
+	encounter := &sim.Encounter
+	sim.reset()
+	boss := encounter.TargetUnits[0]
+	if next := sim.NextTargetUnit(boss); next != boss || sim.GetNumActiveTargets() != 1 {
+		t.Fatalf("Expected only the boss before any adds spawn, got %s", next.Label)
+	}
+
+	runSimUntil(sim, time.Second*12)
+	seen := map[*Unit]bool{}
+	for target := boss; !seen[target]; target = sim.NextTargetUnit(target) {
+		if !target.IsEnabled() {
+			t.Fatalf("Expected only targets in the fight, got %s", target.Label)
+		}
+		seen[target] = true
+	}
+	if len(seen) != 4 {
+		t.Fatalf("Expected to cycle through 4 targets, got %d", len(seen))
+	}
+
+	// The first wave despawns.
+	runSimUntil(sim, time.Second*15)
+	if next := sim.NextTargetUnit(boss); next != &encounter.adds[2].target.Unit {
+		t.Fatalf("Expected to skip the despawned adds, got %s", next.Label)
+	}
+}
+
+func TestAddWaveAOECap(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{Iterations: 1})
+	request.Encounter.AddWaves = []*proto.AddWave{{Count: 14, SpawnTime: 5, Lifetime: 5}}
+	sim := NewSim(request)
+	encounter := &sim.Encounter
+
+	sim.reset()
+	if multiplier := encounter.AOECapMultiplier(); multiplier != 1 {
+		t.Fatalf("Expected no AoE cap before the adds spawn, got %f", multiplier)
+	}
+	runSimUntil(sim, time.Second*5)
+	if multiplier := encounter.AOECapMultiplier(); !WithinToleranceFloat64(multiplier, 10.0/15, 1e-6) {
+		t.Fatalf("Expected AoE damage to be split over 15 targets, got %f", multiplier)
+	}
+	runSimUntil(sim, time.Second*10)
+	if multiplier := encounter.AOECapMultiplier(); multiplier != 1 {
+		t.Fatalf("Expected no AoE cap after the adds despawn, got %f", multiplier)
+	}
+}
+
+func TestAddWaveDamageSplit(t *testing.T) {
+	request := addWavesTestRequest()
+	request.Raid.Buffs = &proto.RaidBuffs{Thorns: proto.TristateEffect_TristateEffectRegular}
+	// Adds which attack the tank, so that Thorns hits them.
+	for _, wave := range request.Encounter.AddWaves {
+		wave.Target = &proto.Target{Level: 80, MinBaseDamage: 100, SwingSpeed: 1}
+	}
+
+	result := RunRaidSim(request)
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", result.ErrorResult)
+	}
+
+	tank := result.RaidMetrics.Parties[0].Players[0]
+	split := tank.DamageSplit
+	if split == nil {
+		t.Fatalf("Expected a damage split for encounters with add waves")
+	}
+	if split.PriorityDps <= 0 || split.AoeDps <= 0 {
+		t.Fatalf("Expected both priority and AoE damage, got %v", split)
+	}
+	if !WithinToleranceFloat64(tank.Dps.Avg, split.PriorityDps+split.AoeDps, 1e-6) {
+		t.Fatalf("Expected the split to add up to %f DPS, got %v", tank.Dps.Avg, split)
+	}
+
+	if noAdds := RunRaidSim(tankSimRequest(&proto.SimOptions{Iterations: 1})); noAdds.RaidMetrics.Parties[0].Players[0].DamageSplit != nil {
+		t.Fatalf("Expected no damage split without add waves")
+	}
+}
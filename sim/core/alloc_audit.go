@@ -0,0 +1,49 @@
+package core
+
+import (
+	"testing"
+)
+
+// Allocation auditing counts heap allocations per iteration in the hot paths
+// of the sim, so that the test suite can fail when they regress. Reading
+// allocation stats is slow, so it's only compiled in with the 'alloc_audit'
+// build tag:
+//
+//	go test --tags=with_db,alloc_audit ./sim/...
+//
+// Allocations are attributed to the innermost section being run, so e.g.
+// allocations by a proc aura activated from DealDamage count towards auras.
+type allocSection int8
+
+const (
+	allocSectionDamage     allocSection = iota // Calculating and dealing spell results.
+	allocSectionAuras                          // Aura activation, refreshes and stacks.
+	allocSectionScheduling                     // Creating and queueing pending actions.
+
+	numAllocSections
+)
+
+var allocSectionNames = [numAllocSections]string{"damage", "auras", "scheduling"}
+
+// Maximum average allocations per iteration in each section, for any test in
+// the suite. Set with some headroom over the worst spec (warrior for damage,
+// rogue for auras and mage for scheduling), lower these when they improve.
+var allocBudget = [numAllocSections]float64{
+	allocSectionDamage:     20,
+	allocSectionAuras:      200,
+	allocSectionScheduling: 300,
+}
+
+// Fails the test if any section exceeded its allocation budget since the last
+// call to takeAllocAudit(). Does nothing unless built with 'alloc_audit'.
+func checkAllocBudget(t *testing.T) {
+	if !allocAuditEnabled {
+		return
+	}
+	for section, allocs := range takeAllocAudit() {
+		if allocs > allocBudget[section] {
+			t.Logf("%0.1f %s allocations per iteration, budget is %0.1f", allocs, allocSectionNames[section], allocBudget[section])
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !alloc_audit
+
+package core
+
+const allocAuditEnabled = false
+
+// No-op, so the hooks in hot paths compile away.
+type allocAudit struct{}
+
+func (audit *allocAudit) begin(_ allocSection)   {}
+func (audit *allocAudit) end()                   {}
+func (audit *allocAudit) startIteration(_ int32) {}
+func (audit *allocAudit) doneIteration()         {}
+func (audit *allocAudit) record()                {}
+
+func takeAllocAudit() [numAllocSections]float64 {
+	return [numAllocSections]float64{}
+}
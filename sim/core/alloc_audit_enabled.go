@@ -0,0 +1,105 @@
+//go:build alloc_audit
+
+package core
+
+import (
+	"runtime"
+	"sync"
+)
+
+const allocAuditEnabled = true
+
+// Reading allocation stats is slow enough that auditing every iteration would
+// make the test suite impractically slow, so only every Nth iteration is
+// audited. The first iteration is skipped, since it includes one-time
+// allocations (e.g. growing slices) that later iterations don't repeat.
+const allocAuditSampleRate = 5
+
+type allocAudit struct {
+	// Whether the current iteration is being audited.
+	active bool
+
+	// Sections currently being run, innermost last.
+	stack []allocSection
+
+	// Total mallocs at the last section boundary.
+	mallocs  uint64
+	memStats runtime.MemStats
+
+	counts     [numAllocSections]uint64
+	iterations int64
+}
+
+// Attributes mallocs since the last section boundary to the current section.
+func (audit *allocAudit) flush() {
+	// Unlike runtime/metrics, this flushes per-P caches so the count is exact.
+	runtime.ReadMemStats(&audit.memStats)
+	if n := len(audit.stack); n > 0 {
+		audit.counts[audit.stack[n-1]] += audit.memStats.Mallocs - audit.mallocs
+	}
+	audit.mallocs = audit.memStats.Mallocs
+}
+
+func (audit *allocAudit) begin(section allocSection) {
+	if !audit.active {
+		return
+	}
+	if audit.stack == nil {
+		audit.stack = make([]allocSection, 0, 32)
+	}
+	audit.flush()
+	audit.stack = append(audit.stack, section)
+}
+
+func (audit *allocAudit) end() {
+	if !audit.active {
+		return
+	}
+	audit.flush()
+	audit.stack = audit.stack[:len(audit.stack)-1]
+}
+
+func (audit *allocAudit) startIteration(iteration int32) {
+	audit.active = iteration%allocAuditSampleRate == 1
+}
+
+func (audit *allocAudit) doneIteration() {
+	if audit.active {
+		audit.iterations++
+		audit.active = false
+	}
+}
+
+var allocAuditTotals struct {
+	sync.Mutex
+	counts     [numAllocSections]uint64
+	iterations int64
+}
+
+// Adds this sim's counts to the totals returned by takeAllocAudit().
+func (audit *allocAudit) record() {
+	allocAuditTotals.Lock()
+	defer allocAuditTotals.Unlock()
+	for section, count := range audit.counts {
+		allocAuditTotals.counts[section] += count
+	}
+	allocAuditTotals.iterations += audit.iterations
+	*audit = allocAudit{}
+}
+
+// Returns the average allocations per audited iteration in each section,
+// across all sims recorded since the last call.
+func takeAllocAudit() [numAllocSections]float64 {
+	allocAuditTotals.Lock()
+	defer allocAuditTotals.Unlock()
+
+	var perIteration [numAllocSections]float64
+	if allocAuditTotals.iterations > 0 {
+		for section, count := range allocAuditTotals.counts {
+			perIteration[section] = float64(count) / float64(allocAuditTotals.iterations)
+		}
+	}
+	allocAuditTotals.counts = [numAllocSections]uint64{}
+	allocAuditTotals.iterations = 0
+	return perIteration
+}
@@ -0,0 +1,37 @@
+//go:build alloc_audit
+
+package core
+
+import (
+	"testing"
+)
+
+var allocAuditSink []*SpellResult
+
+func TestAllocAuditSections(t *testing.T) {
+	takeAllocAudit()
+
+	audit := &allocAudit{}
+	for i := int32(0); i < 2*allocAuditSampleRate; i++ {
+		audit.startIteration(i)
+
+		audit.begin(allocSectionDamage)
+		allocAuditSink = append(allocAuditSink[:0], &SpellResult{})
+		audit.begin(allocSectionAuras)
+		allocAuditSink[0] = &SpellResult{}
+		allocAuditSink[0] = &SpellResult{}
+		audit.end()
+		audit.end()
+
+		// Outside of any section, so not counted.
+		allocAuditSink[0] = &SpellResult{}
+
+		audit.doneIteration()
+	}
+	audit.record()
+
+	perIteration := takeAllocAudit()
+	if perIteration[allocSectionDamage] != 1 || perIteration[allocSectionAuras] != 2 || perIteration[allocSectionScheduling] != 0 {
+		t.Fatalf("Unexpected allocations per iteration: %v", perIteration)
+	}
+}
@@ -25,23 +25,107 @@ func ComputeStats(csr *proto.ComputeStatsRequest) *proto.ComputeStatsResult {
 	}
 }
 
+/**
+ * Fills empty gem sockets in the given equipment, choosing gems by stat weights.
+ */
+func FillGems(request *proto.FillGemsRequest) *proto.FillGemsResult {
+	return fillGems(request)
+}
+
+/**
+ * Converts a character export from the addon or armory into a player.
+ */
+func ImportCharacter(request *proto.ImportCharacterRequest) *proto.ImportCharacterResult {
+	return importCharacter(request)
+}
+
+/**
+ * Returns the preset packs loaded by the server.
+ */
+func GetPresetPacks(_ *proto.PresetPacksRequest) *proto.PresetPacksResult {
+	return &proto.PresetPacksResult{Packs: PresetPacks()}
+}
+
+/**
+ * Converts a SimulationCraft profile into a player.
+ */
+func ImportSimc(request *proto.SimcImportRequest) *proto.SimcImportResult {
+	return importSimc(request)
+}
+
+/**
+ * Converts a player into a SimulationCraft profile.
+ */
+func ExportSimc(request *proto.SimcExportRequest) *proto.SimcExportResult {
+	return exportSimc(request)
+}
+
+/**
+ * Exports a player's major cooldown timings and opener from a single
+ * iteration, for in-game helper addons.
+ */
+func ExportCooldowns(request *proto.CooldownExportRequest) *proto.CooldownExportResult {
+	return exportCooldowns(request)
+}
+
 /**
  * Returns stat weights and EP values, with standard deviations, for all stats.
  */
 func StatWeights(request *proto.StatWeightsRequest) *proto.StatWeightsResult {
-	result := CalcStatWeight(request, stats.Stat(request.EpReferenceStat), nil)
+	result := CalcStatWeight(context.Background(), request, stats.Stat(request.EpReferenceStat), nil)
+	return result.ToProto()
+}
+
+func StatWeightsWithContext(ctx context.Context, request *proto.StatWeightsRequest) *proto.StatWeightsResult {
+	result := CalcStatWeight(ctx, request, stats.Stat(request.EpReferenceStat), nil)
 	return result.ToProto()
 }
 
-func StatWeightsAsync(request *proto.StatWeightsRequest, progress chan *proto.ProgressMetrics) {
+// Most iterations the sims of a stat weights request could run in total, for
+// servers limiting how much work a request can ask for.
+func StatWeightsIterations(request *proto.StatWeightsRequest) int64 {
+	return statWeightsIterations(request)
+}
+
+func StatWeightsAsync(ctx context.Context, request *proto.StatWeightsRequest, progress chan *proto.ProgressMetrics) {
 	go func() {
-		result := CalcStatWeight(request, stats.Stat(request.EpReferenceStat), progress)
+		result := CalcStatWeight(ctx, request, stats.Stat(request.EpReferenceStat), progress)
 		progress <- &proto.ProgressMetrics{
 			FinalWeightResult: result.ToProto(),
 		}
 	}()
 }
 
+// Estimates the memory needed to run a raid sim, e.g. to warn before running
+// sims which won't fit in a browser tab.
+func EstimateMemory(request *proto.EstimateMemoryRequest) *proto.EstimateMemoryResult {
+	return estimateMemory(request)
+}
+
+// Dumps the attack table of one unit against another, for verifying the table
+// math against known references.
+func DumpAttackTable(request *proto.AttackTableRequest) *proto.AttackTableResult {
+	return dumpAttackTable(request)
+}
+
+// Computes a player's stat sheet without running any iterations, for the UI's
+// stat panel.
+func ComputeStatSheet(request *proto.StatSheetRequest) *proto.StatSheetResult {
+	return computeStatSheet(request)
+}
+
+// Compares the per-player metrics of two completed raid sims, for the UI's
+// compare runs view.
+func DiffResults(request *proto.DiffResultsRequest) *proto.DiffResultsResult {
+	return diffResults(request)
+}
+
+// Like StatWeightsWithContext, but checkpoints each of its sims, see
+// RunRaidSimWithCheckpoints.
+func StatWeightsWithCheckpoints(ctx context.Context, request *proto.StatWeightsRequest, resume *proto.StatWeightsCheckpoint, interval int32, save StatWeightsCheckpointFunc, progress chan *proto.ProgressMetrics) *proto.StatWeightsResult {
+	return statWeightsWithCheckpoints(ctx, request, resume, interval, save, progress)
+}
+
 /**
  * Runs multiple iterations of the sim with a full raid.
  */
@@ -49,8 +133,39 @@ func RunRaidSim(request *proto.RaidSimRequest) *proto.RaidSimResult {
 	return RunSim(request, nil)
 }
 
-func RunRaidSimAsync(request *proto.RaidSimRequest, progress chan *proto.ProgressMetrics) {
-	go RunSim(request, progress)
+// Like RunRaidSim, but stops once ctx is done and returns the metrics of the
+// iterations completed so far, with RaidSimResult.cancelled set.
+func RunRaidSimWithContext(ctx context.Context, request *proto.RaidSimRequest) *proto.RaidSimResult {
+	return RunSimWithContext(ctx, request, nil)
+}
+
+func RunRaidSimAsync(ctx context.Context, request *proto.RaidSimRequest, progress chan *proto.ProgressMetrics) {
+	go RunSimWithContext(ctx, request, progress)
+}
+
+// Like RunRaidSimAsync, but passes debug logs to onLogEvent as they're logged,
+// instead of collecting them into RaidSimResult.logs. Logs are still only
+// enabled by SimOptions.debug or debug_first_iteration, and onLogEvent is
+// called from the sim's goroutine.
+func RunRaidSimWithLogEventsAsync(ctx context.Context, request *proto.RaidSimRequest, onLogEvent LogEventFunc, progress chan *proto.ProgressMetrics) {
+	go runSimWithLogEvents(ctx, request, progress, false, onLogEvent)
+}
+
+// Runs iterations [start_iteration, end_iteration) of a sim, for a SimCluster
+// running it across multiple worker processes.
+func RunRaidSimShard(ctx context.Context, request *proto.RaidSimShardRequest) *proto.RaidSimShardResult {
+	return runRaidSimShard(ctx, request)
+}
+
+// Like RunRaidSimWithContext, but saves a checkpoint every interval iterations,
+// and continues from resume if it's set. Sims stopped through ctx can be
+// resumed from their last checkpoint.
+func RunRaidSimWithCheckpoints(ctx context.Context, request *proto.RaidSimRequest, resume *proto.SimCheckpoint, interval int32, save SimCheckpointFunc) *proto.RaidSimResult {
+	return runCheckpointedSim(ctx, request, resume, interval, save, nil)
+}
+
+func RunRaidSimWithCheckpointsAsync(ctx context.Context, request *proto.RaidSimRequest, resume *proto.SimCheckpoint, interval int32, save SimCheckpointFunc, progress chan *proto.ProgressMetrics) {
+	go runCheckpointedSim(ctx, request, resume, interval, save, progress)
 }
 
 func RunBulkSim(request *proto.BulkSimRequest) *proto.BulkSimResult {
@@ -60,3 +175,46 @@ func RunBulkSim(request *proto.BulkSimRequest) *proto.BulkSimResult {
 func RunBulkSimAsync(ctx context.Context, request *proto.BulkSimRequest, progress chan *proto.ProgressMetrics) {
 	go BulkSim(ctx, request, progress)
 }
+
+// Most iterations a bulk sim could run across all its combos, like
+// StatWeightsIterations. Generates the combos without simming them.
+func BulkSimIterations(request *proto.BulkSimRequest) (int64, error) {
+	plan, err := (&bulkSimRunner{Request: request}).prepare(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	return plan.maxIterations(), nil
+}
+
+/**
+ * Runs a base raid sim plus one sim for each single change to it, and returns the DPS change from each.
+ */
+func RunWhatIf(request *proto.WhatIfRequest) *proto.WhatIfResult {
+	return WhatIf(context.Background(), request, nil)
+}
+
+func RunWhatIfAsync(ctx context.Context, request *proto.WhatIfRequest, progress chan *proto.ProgressMetrics) {
+	go WhatIf(ctx, request, progress)
+}
+
+/**
+ * Runs a player with two different specs over a range of fight lengths, and returns the DPS of each and where they cross.
+ */
+func RunSpecComparison(request *proto.SpecComparisonRequest) *proto.SpecComparisonResult {
+	return CompareSpecs(context.Background(), request, nil)
+}
+
+func RunSpecComparisonAsync(ctx context.Context, request *proto.SpecComparisonRequest, progress chan *proto.ProgressMetrics) {
+	go CompareSpecs(ctx, request, progress)
+}
+
+/**
+ * Runs a base raid sim plus one sim without each group of buffs, and returns the raid DPS each group contributes.
+ */
+func RunBuffContribution(request *proto.BuffContributionRequest) *proto.BuffContributionResult {
+	return BuffContributions(context.Background(), request, nil)
+}
+
+func RunBuffContributionAsync(ctx context.Context, request *proto.BuffContributionRequest, progress chan *proto.ProgressMetrics) {
+	go BuffContributions(ctx, request, progress)
+}
@@ -139,9 +139,7 @@ func (unit *Unit) newAPLRotation(config *proto.APLRotation) *APLRotation {
 				}
 			}
 			if !found {
-				unit.RegisterPrepullAction(-1*time.Second, func(sim *Simulation) {
-					prepotSpell.Cast(sim, nil)
-				})
+				unit.RegisterPrepullSpell(-1*time.Second, prepotSpell, nil)
 			}
 		}
 	})
@@ -179,7 +177,7 @@ func (rot *APLRotation) reset(sim *Simulation) {
 // and leverage the community's existing familiarity.
 // https://github.com/simulationcraft/simc/wiki/ActionLists
 func (apl *APLRotation) DoNextAction(sim *Simulation) {
-	if sim.CurrentTime < 0 {
+	if sim.CurrentTime < 0 || !apl.unit.IsEnabled() {
 		return
 	}
 
@@ -26,7 +26,7 @@ func (rot *APLRotation) newActionCastSpell(config *proto.APLActionCastSpell) APL
 	}
 }
 func (action *APLActionCastSpell) IsReady(sim *Simulation) bool {
-	return action.spell.CanCast(sim, action.target.Get()) && (!action.spell.Flags.Matches(SpellFlagMCD) || action.spell.Unit.GCD.IsReady(sim))
+	return action.spell.CanCast(sim, action.target.Get()) && (!action.spell.Flags.Matches(SpellFlagMCD) || action.spell.GCDCategory == GCDCategoryOffGCD || action.spell.Unit.GCD.IsReady(sim))
 }
 func (action *APLActionCastSpell) Execute(sim *Simulation) {
 	action.spell.Cast(sim, action.target.Get())
@@ -87,6 +87,39 @@ func (action *APLActionChannelSpell) String() string {
 	return fmt.Sprintf("Channel Spell(%s, interruptIf=%s)", action.spell.ActionID, action.interruptIf)
 }
 
+type APLActionCancelCast struct {
+	defaultAPLActionImpl
+	unit  *Unit
+	spell *Spell // Optional, cancels any cast if nil.
+}
+
+func (rot *APLRotation) newActionCancelCast(config *proto.APLActionCancelCast) APLActionImpl {
+	var spell *Spell
+	if config.SpellId != nil {
+		spell = rot.GetAPLSpell(config.SpellId)
+		if spell == nil {
+			return nil
+		}
+	}
+	return &APLActionCancelCast{
+		unit:  rot.unit,
+		spell: spell,
+	}
+}
+func (action *APLActionCancelCast) IsReady(sim *Simulation) bool {
+	casting := action.unit.CurrentCast(sim)
+	return casting != nil && (action.spell == nil || casting == action.spell)
+}
+func (action *APLActionCancelCast) Execute(sim *Simulation) {
+	action.unit.CancelCast(sim)
+}
+func (action *APLActionCancelCast) String() string {
+	if action.spell == nil {
+		return "Cancel Cast"
+	}
+	return fmt.Sprintf("Cancel Cast(%s)", action.spell.ActionID)
+}
+
 type APLActionMultidot struct {
 	defaultAPLActionImpl
 	spell      *Spell
@@ -110,7 +143,9 @@ func (rot *APLRotation) newActionMultidot(config *proto.APLActionMultidot) APLAc
 	}
 
 	maxDots := config.MaxDots
-	numTargets := unit.Env.GetNumTargets()
+	// Includes adds which haven't spawned yet. IsReady() only dots the targets
+	// which are in the fight.
+	numTargets := int32(len(unit.Env.Encounter.AllTargetUnits))
 	if spell.Flags.Matches(SpellFlagHelpful) {
 		numTargets = int32(len(unit.Env.Raid.AllPlayerUnits))
 	}
@@ -144,7 +179,7 @@ func (action *APLActionMultidot) IsReady(sim *Simulation) bool {
 			}
 		}
 	} else {
-		for i := int32(0); i < action.maxDots; i++ {
+		for i := int32(0); i < min(action.maxDots, int32(len(sim.Encounter.TargetUnits))); i++ {
 			target := sim.Encounter.TargetUnits[i]
 			dot := action.spell.Dot(target)
 			if (!dot.IsActive() || dot.RemainingDuration(sim) < maxOverlap) && action.spell.CanCast(sim, target) {
@@ -244,8 +279,9 @@ func (action *APLActionAutocastOtherCooldowns) IsReady(sim *Simulation) bool {
 
 	// Explicitly check for GCD because MCDs are usually desired to be cast immediately
 	// before the next spell, rather than immediately after the previous spell. This is
-	// true even for MCDs which do not require the GCD.
-	return action.nextReadyMCD != nil && action.character.GCD.IsReady(sim)
+	// true even for MCDs which do not require the GCD, unless they're declared off
+	// the GCD.
+	return action.nextReadyMCD != nil && (action.character.GCD.IsReady(sim) || action.nextReadyMCD.Spell.GCDCategory == GCDCategoryOffGCD)
 }
 func (action *APLActionAutocastOtherCooldowns) Execute(sim *Simulation) {
 	action.nextReadyMCD.tryActivateHelper(sim, action.character)
@@ -67,10 +67,7 @@ func (action *APLActionCancelAura) IsReady(sim *Simulation) bool {
 	return action.aura.IsActive()
 }
 func (action *APLActionCancelAura) Execute(sim *Simulation) {
-	if sim.Log != nil {
-		action.aura.Unit.Log(sim, "Cancelling aura %s", action.aura.ActionID)
-	}
-	action.aura.Deactivate(sim)
+	action.aura.Cancel(sim)
 }
 func (action *APLActionCancelAura) String() string {
 	return fmt.Sprintf("Cancel Aura(%s)", action.aura.ActionID)
@@ -93,6 +93,12 @@ func (rot *APLRotation) newAPLValue(config *proto.APLValue) APLValue {
 		return rot.newValueIsExecutePhase(config.GetIsExecutePhase())
 	case *proto.APLValue_NumberTargets:
 		return rot.newValueNumberTargets(config.GetNumberTargets())
+	case *proto.APLValue_TargetDamageWindowMultiplier:
+		return rot.newValueTargetDamageWindowMultiplier(config.GetTargetDamageWindowMultiplier())
+	case *proto.APLValue_TargetTimeToDamageWindow:
+		return rot.newValueTargetTimeToDamageWindow(config.GetTargetTimeToDamageWindow())
+	case *proto.APLValue_TargetDamageWindowRemainingTime:
+		return rot.newValueTargetDamageWindowRemainingTime(config.GetTargetDamageWindowRemainingTime())
 
 	// Resources
 	case *proto.APLValue_CurrentHealth:
@@ -107,6 +113,8 @@ func (rot *APLRotation) newAPLValue(config *proto.APLValue) APLValue {
 		return rot.newValueCurrentRage(config.GetCurrentRage())
 	case *proto.APLValue_CurrentEnergy:
 		return rot.newValueCurrentEnergy(config.GetCurrentEnergy())
+	case *proto.APLValue_EnergyTimeToNextTick:
+		return rot.newValueEnergyTimeToNextTick(config.GetEnergyTimeToNextTick())
 	case *proto.APLValue_CurrentComboPoints:
 		return rot.newValueCurrentComboPoints(config.GetCurrentComboPoints())
 	case *proto.APLValue_CurrentRunicPower:
@@ -149,6 +157,8 @@ func (rot *APLRotation) newAPLValue(config *proto.APLValue) APLValue {
 		return rot.newValueSpellIsReady(config.GetSpellIsReady())
 	case *proto.APLValue_SpellTimeToReady:
 		return rot.newValueSpellTimeToReady(config.GetSpellTimeToReady())
+	case *proto.APLValue_SpellCharges:
+		return rot.newValueSpellCharges(config.GetSpellCharges())
 	case *proto.APLValue_SpellCastTime:
 		return rot.newValueSpellCastTime(config.GetSpellCastTime())
 	case *proto.APLValue_SpellChannelTime:
@@ -86,7 +86,8 @@ func (value *APLValueNumberTargets) Type() proto.APLValueType {
 	return proto.APLValueType_ValueTypeInt
 }
 func (value *APLValueNumberTargets) GetInt(sim *Simulation) int32 {
-	return sim.GetNumTargets()
+	// Only counts adds while they're alive.
+	return int32(len(sim.Encounter.TargetUnits))
 }
 func (value *APLValueNumberTargets) String() string {
 	return "Num Targets"
@@ -122,3 +123,84 @@ func (value *APLValueIsExecutePhase) GetBool(sim *Simulation) bool {
 func (value *APLValueIsExecutePhase) String() string {
 	return "Is Execute Phase"
 }
+
+type APLValueTargetDamageWindowMultiplier struct {
+	DefaultAPLValueImpl
+	target UnitReference
+}
+
+func (rot *APLRotation) newValueTargetDamageWindowMultiplier(config *proto.APLValueTargetDamageWindowMultiplier) APLValue {
+	target := rot.GetTargetUnit(config.TargetUnit)
+	if target.Get() == nil {
+		return nil
+	}
+	return &APLValueTargetDamageWindowMultiplier{
+		target: target,
+	}
+}
+func (value *APLValueTargetDamageWindowMultiplier) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeFloat
+}
+func (value *APLValueTargetDamageWindowMultiplier) GetFloat(sim *Simulation) float64 {
+	if dw := value.target.Get().damageWindows; dw != nil {
+		return dw.damageTakenMultiplier(false)
+	}
+	return 1
+}
+func (value *APLValueTargetDamageWindowMultiplier) String() string {
+	return "Target Damage Window Multiplier"
+}
+
+type APLValueTargetTimeToDamageWindow struct {
+	DefaultAPLValueImpl
+	target UnitReference
+}
+
+func (rot *APLRotation) newValueTargetTimeToDamageWindow(config *proto.APLValueTargetTimeToDamageWindow) APLValue {
+	target := rot.GetTargetUnit(config.TargetUnit)
+	if target.Get() == nil {
+		return nil
+	}
+	return &APLValueTargetTimeToDamageWindow{
+		target: target,
+	}
+}
+func (value *APLValueTargetTimeToDamageWindow) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeDuration
+}
+func (value *APLValueTargetTimeToDamageWindow) GetDuration(sim *Simulation) time.Duration {
+	if dw := value.target.Get().damageWindows; dw != nil {
+		return dw.timeToNextWindow(sim)
+	}
+	return NeverExpires
+}
+func (value *APLValueTargetTimeToDamageWindow) String() string {
+	return "Target Time To Damage Window"
+}
+
+type APLValueTargetDamageWindowRemainingTime struct {
+	DefaultAPLValueImpl
+	target UnitReference
+}
+
+func (rot *APLRotation) newValueTargetDamageWindowRemainingTime(config *proto.APLValueTargetDamageWindowRemainingTime) APLValue {
+	target := rot.GetTargetUnit(config.TargetUnit)
+	if target.Get() == nil {
+		return nil
+	}
+	return &APLValueTargetDamageWindowRemainingTime{
+		target: target,
+	}
+}
+func (value *APLValueTargetDamageWindowRemainingTime) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeDuration
+}
+func (value *APLValueTargetDamageWindowRemainingTime) GetDuration(sim *Simulation) time.Duration {
+	if dw := value.target.Get().damageWindows; dw != nil {
+		return dw.activeRemainingTime(sim)
+	}
+	return 0
+}
+func (value *APLValueTargetDamageWindowRemainingTime) String() string {
+	return "Target Damage Window Remaining Time"
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/wowsims/wotlk/sim/core/proto"
 )
@@ -168,6 +169,31 @@ func (value *APLValueCurrentEnergy) String() string {
 	return "Current Energy"
 }
 
+type APLValueEnergyTimeToNextTick struct {
+	DefaultAPLValueImpl
+	unit *Unit
+}
+
+func (rot *APLRotation) newValueEnergyTimeToNextTick(config *proto.APLValueEnergyTimeToNextTick) APLValue {
+	unit := rot.unit
+	if !unit.HasEnergyBar() {
+		rot.ValidationWarning("%s does not use Energy", unit.Label)
+		return nil
+	}
+	return &APLValueEnergyTimeToNextTick{
+		unit: unit,
+	}
+}
+func (value *APLValueEnergyTimeToNextTick) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeDuration
+}
+func (value *APLValueEnergyTimeToNextTick) GetDuration(sim *Simulation) time.Duration {
+	return max(0, value.unit.NextEnergyTickAt()-sim.CurrentTime)
+}
+func (value *APLValueEnergyTimeToNextTick) String() string {
+	return "Energy Time To Next Tick"
+}
+
 type APLValueCurrentComboPoints struct {
 	DefaultAPLValueImpl
 	unit *Unit
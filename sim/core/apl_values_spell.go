@@ -79,6 +79,30 @@ func (value *APLValueSpellTimeToReady) String() string {
 	return fmt.Sprintf("Time To Ready(%s)", value.spell.ActionID)
 }
 
+type APLValueSpellCharges struct {
+	DefaultAPLValueImpl
+	spell *Spell
+}
+
+func (rot *APLRotation) newValueSpellCharges(config *proto.APLValueSpellCharges) APLValue {
+	spell := rot.GetAPLSpell(config.SpellId)
+	if spell == nil {
+		return nil
+	}
+	return &APLValueSpellCharges{
+		spell: spell,
+	}
+}
+func (value *APLValueSpellCharges) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeInt
+}
+func (value *APLValueSpellCharges) GetInt(sim *Simulation) int32 {
+	return value.spell.Charges(sim)
+}
+func (value *APLValueSpellCharges) String() string {
+	return fmt.Sprintf("Charges(%s)", value.spell.ActionID)
+}
+
 type APLValueSpellCastTime struct {
 	DefaultAPLValueImpl
 	spell *Spell
@@ -200,14 +200,6 @@ func (aa *AutoAttacks) RangedAuto() *Spell {
 	return aa.ranged.spell
 }
 
-func (aa *AutoAttacks) OffhandSwingAt() time.Duration {
-	return aa.oh.swingAt
-}
-
-func (aa *AutoAttacks) SetOffhandSwingAt(offhandSwingAt time.Duration) {
-	aa.oh.swingAt = offhandSwingAt
-}
-
 func (aa *AutoAttacks) SetReplaceMHSwing(replaceSwing ReplaceMHSwing) {
 	aa.mh.replaceSwing = replaceSwing
 }
@@ -234,6 +226,7 @@ type WeaponAttack struct {
 	spell  *Spell
 
 	replaceSwing ReplaceMHSwing
+	syncOffhand  func(sim *Simulation)
 
 	swingAt time.Duration
 
@@ -262,18 +255,26 @@ func (wa *WeaponAttack) trySwing(sim *Simulation) time.Duration {
 func (wa *WeaponAttack) swing(sim *Simulation) time.Duration {
 	attackSpell := wa.spell
 
-	if wa.replaceSwing != nil {
+	if wa.replaceSwing != nil || wa.syncOffhand != nil {
 		if wa.unit.IsUsingAPL {
 			// Need to check APL here to allow last-moment HS queue casts.
 			wa.unit.Rotation.DoNextAction(sim)
 		}
-		// Allow MH swing to be overridden for abilities like Heroic Strike.
-		attackSpell = wa.replaceSwing(sim, attackSpell)
+		if wa.syncOffhand != nil {
+			wa.syncOffhand(sim)
+		}
+		if wa.replaceSwing != nil {
+			// Allow MH swing to be overridden for abilities like Heroic Strike.
+			attackSpell = wa.replaceSwing(sim, attackSpell)
+		}
 	}
 
 	// Update swing timer BEFORE the cast, so that APL checks for TimeToNextAuto behave correctly
 	// if the attack causes APL evaluations (e.g. from rage gain).
 	wa.swingAt = sim.CurrentTime + wa.curSwingDuration
+	if tl := wa.unit.Metrics.timeline; tl != nil {
+		tl.recordSwing(sim, wa, attackSpell)
+	}
 	attackSpell.Cast(sim, wa.unit.CurrentTarget)
 
 	if !sim.Options.Interactive {
@@ -552,16 +553,6 @@ func (aa *AutoAttacks) EnableAutoSwing(sim *Simulation) {
 	}
 }
 
-// The amount of time between two MH swings.
-func (aa *AutoAttacks) MainhandSwingSpeed() time.Duration {
-	return aa.mh.curSwingDuration
-}
-
-// The amount of time between two OH swings.
-func (aa *AutoAttacks) OffhandSwingSpeed() time.Duration {
-	return aa.oh.curSwingDuration
-}
-
 // Optionally replaces the given swing spell with an Agent-specified MH Swing replacer.
 // This is for effects like Heroic Strike or Raptor Strike.
 func (aa *AutoAttacks) MaybeReplaceMHSwing(sim *Simulation, mhSwingSpell *Spell) *Spell {
@@ -573,88 +564,6 @@ func (aa *AutoAttacks) MaybeReplaceMHSwing(sim *Simulation, mhSwingSpell *Spell)
 	return aa.mh.replaceSwing(sim, mhSwingSpell)
 }
 
-func (aa *AutoAttacks) UpdateSwingTimers(sim *Simulation) {
-	if !aa.enabled {
-		return
-	}
-
-	if aa.AutoSwingRanged {
-		aa.ranged.updateSwingDuration(aa.ranged.unit.RangedSwingSpeed())
-		// ranged attack speed changes aren't applied mid-"swing"
-	}
-
-	if aa.AutoSwingMelee {
-		oldSwingSpeed := aa.mh.curSwingSpeed
-		aa.mh.updateSwingDuration(aa.mh.unit.SwingSpeed())
-		f := oldSwingSpeed / aa.mh.curSwingSpeed
-
-		if remainingSwingTime := aa.mh.swingAt - sim.CurrentTime; remainingSwingTime > 0 {
-			aa.mh.swingAt = sim.CurrentTime + time.Duration(float64(remainingSwingTime)*f)
-		}
-
-		sim.rescheduleWeaponAttack(aa.mh.swingAt)
-
-		if aa.IsDualWielding {
-			aa.oh.updateSwingDuration(aa.mh.curSwingSpeed)
-
-			if remainingSwingTime := aa.oh.swingAt - sim.CurrentTime; remainingSwingTime > 0 {
-				aa.oh.swingAt = sim.CurrentTime + time.Duration(float64(remainingSwingTime)*f)
-			}
-
-			sim.rescheduleWeaponAttack(aa.oh.swingAt)
-		}
-	}
-}
-
-// StopMeleeUntil should be used whenever a non-melee spell is cast. It stops melee, then restarts it
-// at end of cast, but with a reset swing timer (as if swings had just landed).
-func (aa *AutoAttacks) StopMeleeUntil(sim *Simulation, readyAt time.Duration, desyncOH bool) {
-	if !aa.AutoSwingMelee { // if not auto swinging, don't auto restart.
-		return
-	}
-
-	aa.mh.swingAt = readyAt + aa.mh.curSwingDuration
-	sim.rescheduleWeaponAttack(aa.mh.swingAt)
-
-	if aa.IsDualWielding {
-		aa.oh.swingAt = readyAt + aa.oh.curSwingDuration
-		if desyncOH {
-			// Used by warrior to desync offhand after unglyphed Shattering Throw.
-			aa.oh.swingAt += aa.oh.curSwingDuration / 2
-		}
-		sim.rescheduleWeaponAttack(aa.oh.swingAt)
-	}
-}
-
-// Delays all swing timers for the specified amount. Only used by Slam.
-func (aa *AutoAttacks) DelayMeleeBy(sim *Simulation, delay time.Duration) {
-	if delay <= 0 {
-		return
-	}
-
-	aa.mh.swingAt += delay
-	sim.rescheduleWeaponAttack(aa.mh.swingAt)
-
-	if aa.IsDualWielding {
-		aa.oh.swingAt += delay
-		sim.rescheduleWeaponAttack(aa.oh.swingAt)
-	}
-}
-
-func (aa *AutoAttacks) DelayRangedUntil(sim *Simulation, readyAt time.Duration) {
-	if readyAt <= aa.ranged.swingAt {
-		return
-	}
-
-	aa.ranged.swingAt = readyAt
-	sim.rescheduleWeaponAttack(aa.ranged.swingAt)
-}
-
-// Returns the time at which the next attack will occur.
-func (aa *AutoAttacks) NextAttackAt() time.Duration {
-	return min(aa.mh.swingAt, aa.oh.swingAt)
-}
-
 type PPMManager struct {
 	procMasks   []ProcMask
 	procChances []float64
@@ -664,6 +573,9 @@ type PPMManager struct {
 func (ppmm *PPMManager) Proc(sim *Simulation, procMask ProcMask, label string) bool {
 	for i, m := range ppmm.procMasks {
 		if m.Matches(procMask) {
+			if sim.expectedValue {
+				return sim.expectedProc(label, ppmm.procChances[i])
+			}
 			return sim.RandomFloat(label) < ppmm.procChances[i]
 		}
 	}
@@ -746,8 +658,9 @@ func (unit *Unit) applyParryHaste() {
 				return
 			}
 
-			remainingTime := aura.Unit.AutoAttacks.mh.swingAt - sim.CurrentTime
-			swingSpeed := aura.Unit.AutoAttacks.mh.curSwingDuration
+			aa := &aura.Unit.AutoAttacks
+			remainingTime := aa.MainhandSwingAt() - sim.CurrentTime
+			swingSpeed := aa.MainhandSwingSpeed()
 			minRemainingTime := time.Duration(float64(swingSpeed) * 0.2) // 20% of Swing Speed
 			defaultReduction := minRemainingTime * 2                     // 40% of Swing Speed
 
@@ -756,13 +669,12 @@ func (unit *Unit) applyParryHaste() {
 			}
 
 			parryHasteReduction := min(defaultReduction, remainingTime-minRemainingTime)
-			newReadyAt := aura.Unit.AutoAttacks.mh.swingAt - parryHasteReduction
+			newReadyAt := aa.MainhandSwingAt() - parryHasteReduction
 			if sim.Log != nil {
 				aura.Unit.Log(sim, "MH Swing reduced by %s due to parry haste, will now occur at %s", parryHasteReduction, newReadyAt)
 			}
 
-			aura.Unit.AutoAttacks.mh.swingAt = newReadyAt
-			sim.rescheduleWeaponAttack(newReadyAt)
+			aa.SetMainhandSwingAt(sim, newReadyAt)
 		},
 	})
 }
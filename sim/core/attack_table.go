@@ -0,0 +1,181 @@
+package core
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Physical attacks roll on one of two kinds of attack tables:
+//   - White melee hits use a single roll, which is compared against
+//     consecutive slices for miss, dodge, parry, glancing blow, block and crit,
+//     and hits otherwise. Slices are cut off once they fill the table, so crit
+//     is the first outcome to be pushed off it.
+//   - Special attacks roll for miss, dodge, parry and block first, and then
+//     roll for crit separately. Crit can't be pushed off these tables.
+//
+// Parries and blocks only happen while attacking from the front, see
+// PseudoStats.InFrontOfTarget. The OutcomeApplier functions in
+// spell_outcome.go build the tables from the chances below as they roll, and
+// AttackTableSlices builds the same tables without rolling.
+
+// Chance for a physical attack to miss. The dual wield penalty only applies to
+// white hits.
+func (spell *Spell) PhysicalMissChance(attackTable *AttackTable, dualWieldPenalty bool) float64 {
+	missChance := attackTable.BaseMissChance - spell.PhysicalHitChance(attackTable)
+	if dualWieldPenalty && spell.Unit.AutoAttacks.IsDualWielding && !spell.Unit.PseudoStats.DisableDWMissPenalty {
+		missChance += 0.19
+	}
+	return max(0, missChance)
+}
+
+func (spell *Spell) PhysicalDodgeChance(attackTable *AttackTable) float64 {
+	if spell.Flags.Matches(SpellFlagCannotBeDodged) {
+		return 0
+	}
+	return max(0, attackTable.BaseDodgeChance-spell.ExpertisePercentage()-spell.Unit.PseudoStats.DodgeReduction)
+}
+
+func (spell *Spell) PhysicalParryChance(attackTable *AttackTable) float64 {
+	return max(0, attackTable.BaseParryChance-spell.ExpertisePercentage())
+}
+
+type AttackTableSlice struct {
+	Outcome HitOutcome
+	Chance  float64
+}
+
+// Returns the slices of the first roll of the given table in roll order,
+// ending with hits, and the chance to crit on the second roll, if any.
+func (spell *Spell) AttackTableSlices(attackTable *AttackTable, tableType proto.AttackTableType) ([]AttackTableSlice, float64) {
+	var slices []AttackTableSlice
+	total := 0.0
+	addSlice := func(outcome HitOutcome, chance float64) {
+		chance = max(0, min(chance, 1-total))
+		total += chance
+		slices = append(slices, AttackTableSlice{Outcome: outcome, Chance: chance})
+	}
+	inFront := spell.Unit.PseudoStats.InFrontOfTarget
+
+	secondRollCrit := 0.0
+	switch tableType {
+	case proto.AttackTableType_AttackTableMeleeWhite:
+		addSlice(OutcomeMiss, spell.PhysicalMissChance(attackTable, true))
+		addSlice(OutcomeDodge, spell.PhysicalDodgeChance(attackTable))
+		if inFront {
+			addSlice(OutcomeParry, spell.PhysicalParryChance(attackTable))
+		}
+		addSlice(OutcomeGlance, attackTable.BaseGlanceChance)
+		if inFront {
+			addSlice(OutcomeBlock, attackTable.BaseBlockChance)
+		}
+		addSlice(OutcomeCrit, spell.PhysicalCritChance(attackTable))
+	case proto.AttackTableType_AttackTableMeleeSpecial:
+		addSlice(OutcomeMiss, spell.PhysicalMissChance(attackTable, false))
+		addSlice(OutcomeDodge, spell.PhysicalDodgeChance(attackTable))
+		if inFront {
+			addSlice(OutcomeParry, spell.PhysicalParryChance(attackTable))
+			addSlice(OutcomeBlock, attackTable.BaseBlockChance)
+		}
+		secondRollCrit = spell.PhysicalCritChance(attackTable)
+	case proto.AttackTableType_AttackTableRanged:
+		addSlice(OutcomeMiss, spell.PhysicalMissChance(attackTable, false))
+		if inFront {
+			addSlice(OutcomeBlock, attackTable.BaseBlockChance)
+		}
+		secondRollCrit = spell.PhysicalCritChance(attackTable)
+	case proto.AttackTableType_AttackTableSpell:
+		addSlice(OutcomeMiss, spell.SpellChanceToMiss(attackTable))
+		secondRollCrit = spell.SpellCritChance(attackTable.Defender)
+	}
+	addSlice(OutcomeHit, 1)
+
+	return slices, max(0, min(secondRollCrit, 1))
+}
+
+func attackTableSlicesToProto(slices []AttackTableSlice) []*proto.AttackTableSlice {
+	return MapSlice(slices, func(slice AttackTableSlice) *proto.AttackTableSlice {
+		return &proto.AttackTableSlice{Outcome: slice.Outcome.String(), Chance: slice.Chance}
+	})
+}
+
+// Dumps an attack table as it stands when the fight starts, with permanent
+// buffs and debuffs active.
+func dumpAttackTable(request *proto.AttackTableRequest) (result *proto.AttackTableResult) {
+	defer func() {
+		if err := recover(); err != nil {
+			result = &proto.AttackTableResult{
+				ErrorResult: fmt.Sprintf("%v\nStack Trace:\n%s", err, debug.Stack()),
+			}
+		}
+	}()
+
+	rsr := request.Request
+	if rsr.SimOptions == nil {
+		rsr.SimOptions = &proto.SimOptions{}
+	}
+	sim := NewSim(rsr)
+	sim.reset()
+
+	attacker := sim.GetUnit(request.Attacker, nil)
+	if attacker == nil {
+		panic("Invalid attacker")
+	}
+	defender := attacker.CurrentTarget
+	if request.Defender != nil {
+		defender = sim.GetUnit(request.Defender, attacker)
+	}
+	if defender == nil {
+		panic("Invalid defender")
+	}
+	attackTable := attacker.AttackTables[defender.UnitIndex]
+
+	var spell *Spell
+	if request.SpellId != nil {
+		spell = attacker.GetSpell(ProtoToActionID(request.SpellId))
+		if spell == nil {
+			panic("No spell with ID " + ProtoToActionID(request.SpellId).String())
+		}
+	} else if request.Type == proto.AttackTableType_AttackTableRanged && attacker.AutoAttacks.AutoSwingRanged {
+		spell = attacker.AutoAttacks.RangedAuto()
+	} else if attacker.AutoAttacks.AutoSwingMelee {
+		spell = attacker.AutoAttacks.MHAuto()
+	} else {
+		spell = &Spell{Unit: attacker}
+	}
+
+	firstRoll, secondRollCrit := spell.AttackTableSlices(attackTable, request.Type)
+	result = &proto.AttackTableResult{
+		FirstRoll:            attackTableSlicesToProto(firstRoll),
+		SecondRollCritChance: secondRollCrit,
+		CritSuppression:      attackTable.MeleeCritSuppression,
+	}
+	if request.Type == proto.AttackTableType_AttackTableSpell {
+		result.CritSuppression = attackTable.SpellCritSuppression
+	}
+
+	// Blocked special attacks can't crit, see
+	// OutcomeMeleeWeaponSpecialHitAndCrit.
+	var outcomes []AttackTableSlice
+	for _, slice := range firstRoll {
+		switch {
+		case slice.Outcome == OutcomeBlock && request.Type == proto.AttackTableType_AttackTableRanged:
+			// Ranged attacks roll for crit before block, so blocks can crit.
+			outcomes = append(outcomes,
+				AttackTableSlice{Outcome: OutcomeBlock | OutcomeCrit, Chance: slice.Chance * secondRollCrit},
+				AttackTableSlice{Outcome: OutcomeBlock, Chance: slice.Chance * (1 - secondRollCrit)})
+		case slice.Outcome == OutcomeHit:
+			outcomes = append(outcomes,
+				AttackTableSlice{Outcome: OutcomeCrit, Chance: slice.Chance * secondRollCrit},
+				AttackTableSlice{Outcome: OutcomeHit, Chance: slice.Chance * (1 - secondRollCrit)})
+		case slice.Outcome == OutcomeCrit:
+			result.CritPushedOff = max(0, spell.PhysicalCritChance(attackTable)-slice.Chance)
+			outcomes = append(outcomes, slice)
+		default:
+			outcomes = append(outcomes, slice)
+		}
+	}
+	result.Outcomes = attackTableSlicesToProto(outcomes)
+	return result
+}
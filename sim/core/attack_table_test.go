@@ -0,0 +1,140 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestDumpSpellAttackTable(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{})
+	request.Raid.Debuffs = &proto.Debuffs{Misery: true}
+
+	result := DumpAttackTable(&proto.AttackTableRequest{
+		Request:  request,
+		Attacker: &proto.UnitReference{Type: proto.UnitReference_Player, Index: 0},
+		Type:     proto.AttackTableType_AttackTableSpell,
+	})
+	if result.ErrorResult != "" {
+		t.Fatalf("Failed to dump attack table: %s", result.ErrorResult)
+	}
+
+	// 17% base miss chance against a level 83 target, minus 3% from Misery.
+	if len(result.FirstRoll) != 2 || result.FirstRoll[0].Outcome != "Miss" || !WithinToleranceFloat64(0.14, result.FirstRoll[0].Chance, 1e-9) {
+		t.Fatalf("Expected a 14%% miss chance, got %v", result.FirstRoll)
+	}
+	if result.CritSuppression != 0.021 {
+		t.Fatalf("Expected 2.1%% spell crit suppression, got %f", result.CritSuppression)
+	}
+}
+
+func TestDumpWhiteAttackTablePushesCritOff(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{})
+	request.Raid.Parties[0].Players[0].InFrontOfTarget = true
+	request.Raid.Parties[0].Players[0].BonusStats = &proto.UnitStats{Stats: make([]float64, stats.Len)}
+	request.Raid.Parties[0].Players[0].BonusStats.Stats[stats.MeleeCrit] = 100 * CritRatingPerCritChance
+
+	result := DumpAttackTable(&proto.AttackTableRequest{
+		Request:  request,
+		Attacker: &proto.UnitReference{Type: proto.UnitReference_Player, Index: 0},
+		Type:     proto.AttackTableType_AttackTableMeleeWhite,
+	})
+	if result.ErrorResult != "" {
+		t.Fatalf("Failed to dump attack table: %s", result.ErrorResult)
+	}
+
+	// Miss, dodge, parry, glance and block against a level 83 target, from the
+	// front. Crit fills the rest of the table.
+	expected := []float64{0.08, 0.065, 0.14, 0.24, 0.05, 1 - 0.575, 0}
+	if len(result.FirstRoll) != len(expected) {
+		t.Fatalf("Expected %d slices, got %v", len(expected), result.FirstRoll)
+	}
+	for i, slice := range result.FirstRoll {
+		if !WithinToleranceFloat64(expected[i], slice.Chance, 1e-9) {
+			t.Fatalf("Expected %s chance of %f, got %f", slice.Outcome, expected[i], slice.Chance)
+		}
+	}
+	if result.CritPushedOff <= 0 {
+		t.Fatalf("Expected crit to be pushed off the table, got %v", result)
+	}
+}
+
+// The slices should match the outcomes rolled by the outcome appliers.
+func TestAttackTableSlicesMatchRolls(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{RandomSeed: 1})
+	request.Raid.Parties[0].Players[0].InFrontOfTarget = true
+	sim := NewSim(request)
+	sim.reset()
+
+	attacker := sim.Raid.AllPlayerUnits[0]
+	target := sim.Encounter.TargetUnits[0]
+	attackTable := attacker.AttackTables[target.UnitIndex]
+	spell := &Spell{Unit: attacker, CritMultiplier: 2, SpellMetrics: make([]SpellMetrics, len(sim.AllUnits))}
+
+	for tableType, applier := range map[proto.AttackTableType]OutcomeApplier{
+		proto.AttackTableType_AttackTableMeleeWhite:   spell.OutcomeMeleeWhite,
+		proto.AttackTableType_AttackTableMeleeSpecial: spell.OutcomeMeleeWeaponSpecialHitAndCrit,
+	} {
+		const rolls = 100_000
+		counts := make(map[HitOutcome]int)
+		for i := 0; i < rolls; i++ {
+			result := &SpellResult{Target: target}
+			applier(sim, result, attackTable)
+			counts[result.Outcome]++
+		}
+
+		firstRoll, secondRollCrit := spell.AttackTableSlices(attackTable, tableType)
+		for _, slice := range firstRoll {
+			chance := slice.Chance
+			if slice.Outcome == OutcomeHit {
+				chance *= 1 - secondRollCrit
+			}
+			if actual := float64(counts[slice.Outcome]) / rolls; !WithinToleranceFloat64(chance, actual, 0.005) {
+				t.Errorf("%s: expected %s chance of %f, rolled %f", tableType, slice.Outcome, chance, actual)
+			}
+		}
+	}
+}
+
+func TestAttackTableLevelDelta(t *testing.T) {
+	player := &Unit{Type: PlayerUnit, Level: 80}
+
+	for _, tc := range []struct {
+		level           int32
+		spellMiss       float64
+		meleeMiss       float64
+		dodge           float64
+		glance          float64
+		critSuppression float64
+	}{
+		{83, 0.17, 0.08, 0.065, 0.24, 0.048},
+		{81, 0.05, 0.055, 0.055, 0.12, 0.01},
+		{80, 0.04, 0.05, 0.05, 0.06, 0},
+		// Lower level targets used to be treated like bosses.
+		{78, 0.02, 0.046, 0.046, 0, -0.004},
+		{70, 0.01, 0.03, 0.03, 0, -0.02},
+	} {
+		table := NewAttackTable(player, &Unit{Type: EnemyUnit, Level: tc.level})
+		for _, check := range []struct {
+			name     string
+			expected float64
+			actual   float64
+		}{
+			{"spell miss", tc.spellMiss, table.BaseSpellMissChance},
+			{"melee miss", tc.meleeMiss, table.BaseMissChance},
+			{"dodge", tc.dodge, table.BaseDodgeChance},
+			{"glance", tc.glance, table.BaseGlanceChance},
+			{"crit suppression", tc.critSuppression, table.MeleeCritSuppression},
+		} {
+			if !WithinToleranceFloat64(check.expected, check.actual, 1e-9) {
+				t.Errorf("Level %d: expected %s chance of %f, got %f", tc.level, check.name, check.expected, check.actual)
+			}
+		}
+	}
+
+	// Lower level mobs miss players more often.
+	if table := NewAttackTable(&Unit{Type: EnemyUnit, Level: 78}, player); !WithinToleranceFloat64(0.054, table.BaseMissChance, 1e-9) {
+		t.Errorf("Expected a level 78 mob to miss 5.4%% of the time, got %f", table.BaseMissChance)
+	}
+}
@@ -22,6 +22,11 @@ type OnExpire func(aura *Aura, sim *Simulation)
 type OnStacksChange func(aura *Aura, sim *Simulation, oldStacks int32, newStacks int32)
 type OnStatsChange func(aura *Aura, sim *Simulation, oldStats stats.Stats, newStats stats.Stats)
 
+// Callback for when the unit is ready to pick its next action with the GCD
+// available, before the rotation does. Use it for effects which need to act
+// ahead of the rotation, e.g. to preempt it.
+type OnGCDReady func(aura *Aura, sim *Simulation)
+
 // Callback for after a spell hits the target and after damage is calculated. Use it for proc effects
 // or anything that comes from the final result of the spell.
 type OnSpellHit func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult)
@@ -44,7 +49,8 @@ type Aura struct {
 	Label string
 
 	// For easily grouping auras.
-	Tag string
+	Tag      string
+	tagGroup *auraTagGroup // Shared by the auras with this Tag on this Unit.
 
 	ActionID        ActionID // If set, metrics will be tracked for this aura.
 	ActionIDForProc ActionID // If set, indicates that this aura is a trigger aura for the specified proc.
@@ -53,15 +59,26 @@ type Aura struct {
 
 	Duration time.Duration // Duration of aura, upon being applied.
 
+	// Stats granted while active, for auras made by NewTemporaryStatsAura.
+	tempStats stats.Stats
+
+	// Proc events seen by this aura, for auras made by ApplyProcTriggerCallback.
+	procTrigger *procTriggerEvents
+
 	startTime time.Duration // Time at which the aura was applied.
 	expires   time.Duration // Time at which aura will be removed.
 
+	stacksChangedAt time.Duration // Time of the last stacks change, for metrics.StackTime.
+
 	// The unit this aura is attached to.
 	Unit *Unit
 
 	active                     bool
 	activeIndex                int32 // Position of this aura's index in the activeAuras array.
+	onCastStartIndex           int32 // Position of this aura's index in the onCastStartAuras array.
 	onCastCompleteIndex        int32 // Position of this aura's index in the onCastCompleteAuras array.
+	onCastInterruptedIndex     int32 // Position of this aura's index in the onCastInterruptedAuras array.
+	onGCDReadyIndex            int32 // Position of this aura's index in the onGCDReadyAuras array.
 	onSpellHitDealtIndex       int32 // Position of this aura's index in the onSpellHitAuras array.
 	onSpellHitTakenIndex       int32 // Position of this aura's index in the onSpellHitAuras array.
 	onPeriodicDamageDealtIndex int32 // Position of this aura's index in the onPeriodicDamageAuras array.
@@ -87,15 +104,18 @@ type Aura struct {
 	OnStacksChange  OnStacksChange // Invoked when the number of stacks of this aura changes.
 	OnStatsChange   OnStatsChange  // Invoked when the stats of this aura owner changes.
 
-	OnCastComplete        OnCastComplete   // Invoked when a spell cast completes casting, before results are calculated.
-	OnSpellHitDealt       OnSpellHit       // Invoked when a spell hits and this unit is the caster.
-	OnSpellHitTaken       OnSpellHit       // Invoked when a spell hits and this unit is the target.
-	OnPeriodicDamageDealt OnPeriodicDamage // Invoked when a dot tick occurs and this unit is the caster.
-	OnPeriodicDamageTaken OnPeriodicDamage // Invoked when a dot tick occurs and this unit is the target.
-	OnHealDealt           OnSpellHit       // Invoked when a heal hits and this unit is the caster.
-	OnHealTaken           OnSpellHit       // Invoked when a heal hits and this unit is the target.
-	OnPeriodicHealDealt   OnPeriodicDamage // Invoked when a hot tick occurs and this unit is the caster.
-	OnPeriodicHealTaken   OnPeriodicDamage // Invoked when a hot tick occurs and this unit is the target.
+	OnCastStart           OnCastStart       // Invoked when a spell cast starts, before costs are paid.
+	OnCastComplete        OnCastComplete    // Invoked when a spell cast completes casting, before results are calculated.
+	OnCastInterrupted     OnCastInterrupted // Invoked when a hardcast or channel is stopped before it finishes.
+	OnGCDReady            OnGCDReady        // Invoked when this unit is ready to pick its next GCD action.
+	OnSpellHitDealt       OnSpellHit        // Invoked when a spell hits and this unit is the caster.
+	OnSpellHitTaken       OnSpellHit        // Invoked when a spell hits and this unit is the target.
+	OnPeriodicDamageDealt OnPeriodicDamage  // Invoked when a dot tick occurs and this unit is the caster.
+	OnPeriodicDamageTaken OnPeriodicDamage  // Invoked when a dot tick occurs and this unit is the target.
+	OnHealDealt           OnSpellHit        // Invoked when a heal hits and this unit is the caster.
+	OnHealTaken           OnSpellHit        // Invoked when a heal hits and this unit is the target.
+	OnPeriodicHealDealt   OnPeriodicDamage  // Invoked when a hot tick occurs and this unit is the caster.
+	OnPeriodicHealTaken   OnPeriodicDamage  // Invoked when a hot tick occurs and this unit is the target.
 
 	// If non-default, stat bonuses fron the OnGain callback of this aura will be
 	// included in Character Stats in the UI.
@@ -158,6 +178,12 @@ func (aura *Aura) IsActive() bool {
 }
 
 func (aura *Aura) Refresh(sim *Simulation) {
+	if aura.active && aura.expires != NeverExpires && aura.expires > sim.CurrentTime {
+		aura.metrics.RefreshWasted += aura.expires - sim.CurrentTime
+	}
+	aura.refresh(sim)
+}
+func (aura *Aura) refresh(sim *Simulation) {
 	if aura.Duration == NeverExpires {
 		aura.expires = NeverExpires
 	} else {
@@ -177,6 +203,11 @@ func (aura *Aura) GetStacks() int32 {
 }
 
 func (aura *Aura) SetStacks(sim *Simulation, newStacks int32) {
+	sim.allocAudit.begin(allocSectionAuras)
+	aura.setStacks(sim, newStacks)
+	sim.allocAudit.end()
+}
+func (aura *Aura) setStacks(sim *Simulation, newStacks int32) {
 	if !aura.IsActive() && newStacks != 0 {
 		panic("Trying to set non-zero stacks on inactive aura!")
 	}
@@ -194,9 +225,13 @@ func (aura *Aura) SetStacks(sim *Simulation, newStacks int32) {
 	}
 
 	if sim.Log != nil {
-		aura.Unit.Log(sim, "%s stacks: %d --> %d", aura.ActionID, oldStacks, newStacks)
+		sim.logEvent(LogEvent{Type: LogEventAuraStacks, Actor: aura.Unit, ActionID: aura.ActionID, Before: float64(oldStacks), After: float64(newStacks)})
 	}
+	aura.addStackTime(sim.CurrentTime)
 	aura.stacks = newStacks
+	if tl := aura.Unit.Metrics.timeline; tl != nil && aura.active {
+		tl.onAuraStacksChange(sim, aura)
+	}
 	if aura.OnStacksChange != nil {
 		aura.OnStacksChange(aura, sim, oldStacks, newStacks)
 	}
@@ -204,6 +239,15 @@ func (aura *Aura) SetStacks(sim *Simulation, newStacks int32) {
 		aura.Deactivate(sim)
 	}
 }
+
+// Adds the current stacks since the last change to the metrics, up to the given time.
+func (aura *Aura) addStackTime(until time.Duration) {
+	if start := max(aura.stacksChangedAt, 0); until > start {
+		aura.metrics.StackTime += time.Duration(aura.stacks) * (until - start)
+	}
+	aura.stacksChangedAt = until
+}
+
 func (aura *Aura) AddStack(sim *Simulation) {
 	aura.SetStacks(sim, aura.stacks+1)
 }
@@ -266,6 +310,19 @@ func (aura *Aura) ApplyOnExpire(newOnExpire OnExpire) {
 	}
 }
 
+// Adds a handler to be called OnStacksChange, in addition to any current handlers.
+func (aura *Aura) ApplyOnStacksChange(newOnStacksChange OnStacksChange) {
+	oldOnStacksChange := aura.OnStacksChange
+	if oldOnStacksChange == nil {
+		aura.OnStacksChange = newOnStacksChange
+	} else {
+		aura.OnStacksChange = func(aura *Aura, sim *Simulation, oldStacks int32, newStacks int32) {
+			oldOnStacksChange(aura, sim, oldStacks, newStacks)
+			newOnStacksChange(aura, sim, oldStacks, newStacks)
+		}
+	}
+}
+
 type AuraFactory func(*Simulation) *Aura
 
 // Callback for doing something on reset.
@@ -283,7 +340,8 @@ type auraTracker struct {
 	// All registered auras, both active and inactive.
 	auras []*Aura
 
-	aurasByTag map[string][]*Aura
+	aurasByLabel map[string]*Aura
+	aurasByTag   map[string]*auraTagGroup
 
 	// IDs of Auras that may expire and are currently active, in no particular order.
 	activeAuras []*Aura
@@ -292,7 +350,10 @@ type auraTracker struct {
 	minExpires time.Duration
 
 	// Auras that have a non-nil XXX function set and are currently active.
+	onCastStartAuras           []*Aura
 	onCastCompleteAuras        []*Aura
+	onCastInterruptedAuras     []*Aura
+	onGCDReadyAuras            []*Aura
 	onSpellHitDealtAuras       []*Aura
 	onSpellHitTakenAuras       []*Aura
 	onPeriodicDamageDealtAuras []*Aura
@@ -306,18 +367,21 @@ type auraTracker struct {
 func newAuraTracker() auraTracker {
 	return auraTracker{
 		resetEffects:           []ResetEffect{},
-		ExclusiveEffectManager: &ExclusiveEffectManager{},
-		aurasByTag:             make(map[string][]*Aura),
+		ExclusiveEffectManager: newExclusiveEffectManager(),
+		aurasByLabel:           make(map[string]*Aura),
+		aurasByTag:             make(map[string]*auraTagGroup),
 	}
 }
 
+// Auras with the same Tag on a Unit. Tracks how many are active, so checking
+// for an active aura with a tag doesn't need to look at each aura.
+type auraTagGroup struct {
+	auras     []*Aura
+	numActive int32
+}
+
 func (at *auraTracker) GetAura(label string) *Aura {
-	for _, aura := range at.auras {
-		if aura.Label == label {
-			return aura
-		}
-	}
-	return nil
+	return at.aurasByLabel[label]
 }
 func (at *auraTracker) GetAuras() []*Aura {
 	return at.auras
@@ -367,7 +431,10 @@ func (at *auraTracker) registerAura(unit *Unit, aura Aura) *Aura {
 	newAura.Icd = aura.Icd
 	newAura.metrics.ID = aura.ActionID
 	newAura.activeIndex = Inactive
+	newAura.onCastStartIndex = Inactive
 	newAura.onCastCompleteIndex = Inactive
+	newAura.onCastInterruptedIndex = Inactive
+	newAura.onGCDReadyIndex = Inactive
 	newAura.onSpellHitDealtIndex = Inactive
 	newAura.onSpellHitTakenIndex = Inactive
 	newAura.onPeriodicDamageDealtIndex = Inactive
@@ -378,8 +445,15 @@ func (at *auraTracker) registerAura(unit *Unit, aura Aura) *Aura {
 	newAura.onPeriodicHealTakenIndex = Inactive
 
 	at.auras = append(at.auras, newAura)
+	at.aurasByLabel[newAura.Label] = newAura
 	if newAura.Tag != "" {
-		at.aurasByTag[newAura.Tag] = append(at.aurasByTag[newAura.Tag], newAura)
+		group := at.aurasByTag[newAura.Tag]
+		if group == nil {
+			group = &auraTagGroup{}
+			at.aurasByTag[newAura.Tag] = group
+		}
+		group.auras = append(group.auras, newAura)
+		newAura.tagGroup = group
 	}
 
 	return newAura
@@ -394,7 +468,10 @@ func (unit *Unit) GetOrRegisterAura(aura Aura) *Aura {
 		return unit.RegisterAura(aura)
 	} else {
 		curAura.Icd = aura.Icd
+		curAura.OnCastStart = aura.OnCastStart
 		curAura.OnCastComplete = aura.OnCastComplete
+		curAura.OnCastInterrupted = aura.OnCastInterrupted
+		curAura.OnGCDReady = aura.OnGCDReady
 		curAura.OnSpellHitDealt = aura.OnSpellHitDealt
 		curAura.OnSpellHitTaken = aura.OnSpellHitTaken
 		curAura.OnPeriodicDamageDealt = aura.OnPeriodicDamageDealt
@@ -408,15 +485,23 @@ func (unit *Unit) GetOrRegisterAura(aura Aura) *Aura {
 }
 
 func (at *auraTracker) GetAurasWithTag(tag string) []*Aura {
-	return at.aurasByTag[tag]
+	if group := at.aurasByTag[tag]; group != nil {
+		return group.auras
+	}
+	return nil
 }
 
 func (at *auraTracker) HasAuraWithTag(tag string) bool {
-	return len(at.aurasByTag[tag]) > 0
+	return at.aurasByTag[tag] != nil
 }
 
+// Returns the first registered active aura with the tag, if any.
 func (at *auraTracker) GetActiveAuraWithTag(tag string) *Aura {
-	for _, aura := range at.aurasByTag[tag] {
+	group := at.aurasByTag[tag]
+	if group == nil || group.numActive == 0 {
+		return nil
+	}
+	for _, aura := range group.auras {
 		if aura.active {
 			return aura
 		}
@@ -424,29 +509,20 @@ func (at *auraTracker) GetActiveAuraWithTag(tag string) *Aura {
 	return nil
 }
 func (at *auraTracker) NumActiveAurasWithTag(tag string) int32 {
-	count := int32(0)
-	for _, aura := range at.aurasByTag[tag] {
-		if aura.active {
-			count++
-		}
+	if group := at.aurasByTag[tag]; group != nil {
+		return group.numActive
 	}
-	return count
+	return 0
 }
 func (at *auraTracker) HasActiveAuraWithTag(tag string) bool {
-	for _, aura := range at.aurasByTag[tag] {
-		if aura.active {
-			return true
-		}
-	}
-	return false
+	return at.NumActiveAurasWithTag(tag) > 0
 }
 func (at *auraTracker) HasActiveAuraWithTagExcludingAura(tag string, excludeAura *Aura) bool {
-	for _, aura := range at.aurasByTag[tag] {
-		if aura.active && aura != excludeAura {
-			return true
-		}
+	numActive := at.NumActiveAurasWithTag(tag)
+	if excludeAura.active && excludeAura.tagGroup != nil && excludeAura.tagGroup == at.aurasByTag[tag] {
+		numActive--
 	}
-	return false
+	return numActive > 0
 }
 
 // Registers a callback to this Character which will be invoked on
@@ -457,7 +533,10 @@ func (at *auraTracker) RegisterResetEffect(resetEffect ResetEffect) {
 
 func (at *auraTracker) reset(sim *Simulation) {
 	at.activeAuras = at.activeAuras[:0]
+	at.onCastStartAuras = at.onCastStartAuras[:0]
 	at.onCastCompleteAuras = at.onCastCompleteAuras[:0]
+	at.onCastInterruptedAuras = at.onCastInterruptedAuras[:0]
+	at.onGCDReadyAuras = at.onGCDReadyAuras[:0]
 	at.onSpellHitDealtAuras = at.onSpellHitDealtAuras[:0]
 	at.onSpellHitTakenAuras = at.onSpellHitTakenAuras[:0]
 	at.onPeriodicDamageDealtAuras = at.onPeriodicDamageDealtAuras[:0]
@@ -523,17 +602,22 @@ restart:
 	}
 
 	for _, aura := range at.auras {
-		aura.metrics.doneIteration()
+		aura.metrics.doneIteration(sim.Duration)
 	}
 }
 
 // Adds a new aura to the simulation. If an aura with the same ID already
 // exists it will be replaced with the new one.
 func (aura *Aura) Activate(sim *Simulation) {
+	sim.allocAudit.begin(allocSectionAuras)
+	aura.activate(sim)
+	sim.allocAudit.end()
+}
+func (aura *Aura) activate(sim *Simulation) {
 	aura.metrics.Procs++
 	if aura.IsActive() {
 		if sim.Log != nil && !aura.ActionID.IsEmptyAction() {
-			aura.Unit.Log(sim, "Aura refreshed: %s", aura.ActionID)
+			aura.logEvent(sim, LogEventAuraRefreshed)
 		}
 		aura.Refresh(sim)
 		return
@@ -559,19 +643,38 @@ func (aura *Aura) Activate(sim *Simulation) {
 	}
 
 	aura.active = true
+	if aura.tagGroup != nil {
+		aura.tagGroup.numActive++
+	}
+	aura.metrics.Applications++
 	aura.startTime = sim.CurrentTime
-	aura.Refresh(sim)
+	aura.refresh(sim)
 
 	if aura.Duration != NeverExpires {
 		aura.activeIndex = int32(len(aura.Unit.activeAuras))
 		aura.Unit.activeAuras = append(aura.Unit.activeAuras, aura)
 	}
 
+	if aura.OnCastStart != nil {
+		aura.onCastStartIndex = int32(len(aura.Unit.onCastStartAuras))
+		aura.Unit.onCastStartAuras = append(aura.Unit.onCastStartAuras, aura)
+	}
+
 	if aura.OnCastComplete != nil {
 		aura.onCastCompleteIndex = int32(len(aura.Unit.onCastCompleteAuras))
 		aura.Unit.onCastCompleteAuras = append(aura.Unit.onCastCompleteAuras, aura)
 	}
 
+	if aura.OnCastInterrupted != nil {
+		aura.onCastInterruptedIndex = int32(len(aura.Unit.onCastInterruptedAuras))
+		aura.Unit.onCastInterruptedAuras = append(aura.Unit.onCastInterruptedAuras, aura)
+	}
+
+	if aura.OnGCDReady != nil {
+		aura.onGCDReadyIndex = int32(len(aura.Unit.onGCDReadyAuras))
+		aura.Unit.onGCDReadyAuras = append(aura.Unit.onGCDReadyAuras, aura)
+	}
+
 	if aura.OnSpellHitDealt != nil {
 		aura.onSpellHitDealtIndex = int32(len(aura.Unit.onSpellHitDealtAuras))
 		aura.Unit.onSpellHitDealtAuras = append(aura.Unit.onSpellHitDealtAuras, aura)
@@ -613,7 +716,10 @@ func (aura *Aura) Activate(sim *Simulation) {
 	}
 
 	if sim.Log != nil && !aura.ActionID.IsEmptyAction() {
-		aura.Unit.Log(sim, "Aura gained: %s", aura.ActionID)
+		aura.logEvent(sim, LogEventAuraGained)
+	}
+	if tl := aura.Unit.Metrics.timeline; tl != nil {
+		tl.onAuraGain(sim, aura)
 	}
 
 	// don't invoke possible callbacks until the internal state is consistent
@@ -624,21 +730,35 @@ func (aura *Aura) Activate(sim *Simulation) {
 
 // Remove an aura by its ID
 func (aura *Aura) Deactivate(sim *Simulation) {
+	sim.allocAudit.begin(allocSectionAuras)
+	aura.deactivate(sim)
+	sim.allocAudit.end()
+}
+func (aura *Aura) deactivate(sim *Simulation) {
 	if !aura.active {
 		return
 	}
 	aura.active = false
+	if aura.tagGroup != nil {
+		aura.tagGroup.numActive--
+	}
 
 	if !aura.ActionID.IsEmptyAction() {
 		if sim.CurrentTime > aura.expires {
 			aura.metrics.Uptime += aura.expires - max(aura.startTime, 0)
+			aura.addStackTime(aura.expires)
 		} else {
 			aura.metrics.Uptime += sim.CurrentTime - max(aura.startTime, 0)
+			aura.addStackTime(sim.CurrentTime)
 		}
+		aura.stacksChangedAt = sim.CurrentTime
 	}
 
 	if sim.Log != nil && !aura.ActionID.IsEmptyAction() {
-		aura.Unit.Log(sim, "Aura faded: %s", aura.ActionID)
+		aura.logEvent(sim, LogEventAuraFaded)
+	}
+	if tl := aura.Unit.Metrics.timeline; tl != nil {
+		tl.onAuraExpire(sim, aura)
 	}
 
 	aura.expires = 0
@@ -651,6 +771,15 @@ func (aura *Aura) Deactivate(sim *Simulation) {
 		aura.activeIndex = Inactive
 	}
 
+	if aura.onCastStartIndex != Inactive {
+		removeOnCastStartIndex := aura.onCastStartIndex
+		aura.Unit.onCastStartAuras = removeBySwappingToBack(aura.Unit.onCastStartAuras, removeOnCastStartIndex)
+		if removeOnCastStartIndex < int32(len(aura.Unit.onCastStartAuras)) {
+			aura.Unit.onCastStartAuras[removeOnCastStartIndex].onCastStartIndex = removeOnCastStartIndex
+		}
+		aura.onCastStartIndex = Inactive
+	}
+
 	if aura.onCastCompleteIndex != Inactive {
 		removeOnCastCompleteIndex := aura.onCastCompleteIndex
 		aura.Unit.onCastCompleteAuras = removeBySwappingToBack(aura.Unit.onCastCompleteAuras, removeOnCastCompleteIndex)
@@ -660,6 +789,24 @@ func (aura *Aura) Deactivate(sim *Simulation) {
 		aura.onCastCompleteIndex = Inactive
 	}
 
+	if aura.onCastInterruptedIndex != Inactive {
+		removeOnCastInterruptedIndex := aura.onCastInterruptedIndex
+		aura.Unit.onCastInterruptedAuras = removeBySwappingToBack(aura.Unit.onCastInterruptedAuras, removeOnCastInterruptedIndex)
+		if removeOnCastInterruptedIndex < int32(len(aura.Unit.onCastInterruptedAuras)) {
+			aura.Unit.onCastInterruptedAuras[removeOnCastInterruptedIndex].onCastInterruptedIndex = removeOnCastInterruptedIndex
+		}
+		aura.onCastInterruptedIndex = Inactive
+	}
+
+	if aura.onGCDReadyIndex != Inactive {
+		removeOnGCDReadyIndex := aura.onGCDReadyIndex
+		aura.Unit.onGCDReadyAuras = removeBySwappingToBack(aura.Unit.onGCDReadyAuras, removeOnGCDReadyIndex)
+		if removeOnGCDReadyIndex < int32(len(aura.Unit.onGCDReadyAuras)) {
+			aura.Unit.onGCDReadyAuras[removeOnGCDReadyIndex].onGCDReadyIndex = removeOnGCDReadyIndex
+		}
+		aura.onGCDReadyIndex = Inactive
+	}
+
 	if aura.onSpellHitDealtIndex != Inactive {
 		removeOnSpellHitDealtIndex := aura.onSpellHitDealtIndex
 		aura.Unit.onSpellHitDealtAuras = removeBySwappingToBack(aura.Unit.onSpellHitDealtAuras, removeOnSpellHitDealtIndex)
@@ -753,6 +900,17 @@ func removeBySwappingToBack[T any, U constraints.Integer](arr []T, removeIdx U)
 	return arr[:len(arr)-1]
 }
 
+// Invokes the OnCastStart event for all tracked Auras.
+func (at *auraTracker) OnCastStart(sim *Simulation, spell *Spell) {
+	for _, aura := range at.onCastStartAuras {
+		// this check is to handle a case where auras are deactivated during iteration.
+		if !aura.active {
+			continue
+		}
+		aura.OnCastStart(aura, sim, spell)
+	}
+}
+
 // Invokes the OnCastComplete event for all tracked Auras.
 func (at *auraTracker) OnCastComplete(sim *Simulation, spell *Spell) {
 	for _, aura := range at.onCastCompleteAuras {
@@ -760,6 +918,27 @@ func (at *auraTracker) OnCastComplete(sim *Simulation, spell *Spell) {
 	}
 }
 
+// Invokes the OnCastInterrupted event for all tracked Auras.
+func (at *auraTracker) OnCastInterrupted(sim *Simulation, spell *Spell) {
+	for _, aura := range at.onCastInterruptedAuras {
+		if !aura.active {
+			continue
+		}
+		aura.OnCastInterrupted(aura, sim, spell)
+	}
+}
+
+// Invokes the OnGCDReady event for all tracked Auras. Unexported, so that it
+// can't be mistaken for Agent.OnGCDReady.
+func (at *auraTracker) onGCDReady(sim *Simulation) {
+	for _, aura := range at.onGCDReadyAuras {
+		if !aura.active {
+			continue
+		}
+		aura.OnGCDReady(aura, sim)
+	}
+}
+
 // Invokes the OnSpellHit event for all tracked Auras.
 func (at *auraTracker) OnSpellHitDealt(sim *Simulation, spell *Spell, result *SpellResult) {
 	for _, aura := range at.onSpellHitDealtAuras {
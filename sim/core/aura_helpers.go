@@ -23,6 +23,7 @@ const (
 	CallbackOnHealDealt
 	CallbackOnPeriodicHealDealt
 	CallbackOnCastComplete
+	CallbackOnCastStart
 )
 
 type ProcHandler func(sim *Simulation, spell *Spell, result *SpellResult)
@@ -40,17 +41,19 @@ type ProcTrigger struct {
 	ProcChance      float64
 	PPM             float64
 	ICD             time.Duration
+	SharedICD       *InternalCooldown // Used instead of ICD, for effects which share one.
 	Handler         ProcHandler
 }
 
 func ApplyProcTriggerCallback(unit *Unit, aura *Aura, config ProcTrigger) {
-	var icd Cooldown
-	if config.ICD != 0 {
-		icd = Cooldown{
-			Timer:    unit.NewTimer(),
-			Duration: config.ICD,
-		}
-		aura.Icd = &icd
+	var icd *InternalCooldownEffect
+	if config.SharedICD != nil {
+		icd = config.SharedICD.ForEffect(config.ActionID)
+	} else if config.ICD != 0 {
+		icd = unit.NewInternalCooldown(config.ICD).ForEffect(config.ActionID)
+	}
+	if icd != nil {
+		aura.Icd = &icd.icd.Cooldown
 	}
 
 	var ppmm PPMManager
@@ -58,6 +61,9 @@ func ApplyProcTriggerCallback(unit *Unit, aura *Aura, config ProcTrigger) {
 		ppmm = unit.AutoAttacks.NewPPMManager(config.PPM, config.ProcMask)
 	}
 
+	events := &procTriggerEvents{}
+	aura.procTrigger = events
+
 	handler := config.Handler
 	callback := func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
 		if config.SpellFlags != SpellFlagNone && !spell.Flags.Matches(config.SpellFlags) {
@@ -69,22 +75,34 @@ func ApplyProcTriggerCallback(unit *Unit, aura *Aura, config ProcTrigger) {
 		if config.ProcMask != ProcMaskUnknown && !spell.ProcMask.Matches(config.ProcMask) {
 			return
 		}
-		if config.Outcome != OutcomeEmpty && !result.Outcome.Matches(config.Outcome) {
-			return
+		outcomeChance := 1.0
+		if config.Outcome != OutcomeEmpty {
+			if outcomeChance = result.outcomeChance(config.Outcome); outcomeChance == 0 {
+				return
+			}
 		}
 		if config.Harmful && result.Damage == 0 {
 			return
 		}
-		if icd.Duration != 0 && !icd.IsReady(sim) {
+		chance := config.ProcChance
+		if config.PPM != 0 {
+			chance *= ppmm.Chance(spell.ProcMask)
+		}
+		events.add(chance)
+		if icd != nil && !icd.IsReady(sim, chance) {
 			return
 		}
-		if config.ProcChance != 1 && sim.RandomFloat(config.Name) > config.ProcChance {
+		if sim.expectedValue {
+			if !sim.expectedProc(events, chance*outcomeChance) {
+				return
+			}
+		} else if config.ProcChance != 1 && sim.RandomFloat(config.Name) > config.ProcChance {
 			return
 		} else if config.PPM != 0 && !ppmm.Proc(sim, spell.ProcMask, config.Name) {
 			return
 		}
 
-		if icd.Duration != 0 {
+		if icd != nil {
 			icd.Use(sim)
 		}
 		handler(sim, spell, result)
@@ -109,8 +127,8 @@ func ApplyProcTriggerCallback(unit *Unit, aura *Aura, config ProcTrigger) {
 	if config.Callback.Matches(CallbackOnPeriodicHealDealt) {
 		aura.OnPeriodicHealDealt = callback
 	}
-	if config.Callback.Matches(CallbackOnCastComplete) {
-		aura.OnCastComplete = func(aura *Aura, sim *Simulation, spell *Spell) {
+	if config.Callback.Matches(CallbackOnCastComplete | CallbackOnCastStart) {
+		castCallback := func(aura *Aura, sim *Simulation, spell *Spell) {
 			if config.SpellFlags != SpellFlagNone && !spell.Flags.Matches(config.SpellFlags) {
 				return
 			}
@@ -120,18 +138,29 @@ func ApplyProcTriggerCallback(unit *Unit, aura *Aura, config ProcTrigger) {
 			if config.ProcMaskExclude != ProcMaskUnknown && spell.ProcMask.Matches(config.ProcMaskExclude) {
 				return
 			}
-			if icd.Duration != 0 && !icd.IsReady(sim) {
+			events.add(config.ProcChance)
+			if icd != nil && !icd.IsReady(sim, config.ProcChance) {
 				return
 			}
-			if config.ProcChance != 1 && sim.RandomFloat(config.Name) > config.ProcChance {
+			if sim.expectedValue {
+				if !sim.expectedProc(events, config.ProcChance) {
+					return
+				}
+			} else if config.ProcChance != 1 && sim.RandomFloat(config.Name) > config.ProcChance {
 				return
 			}
 
-			if icd.Duration != 0 {
+			if icd != nil {
 				icd.Use(sim)
 			}
 			handler(sim, spell, nil)
 		}
+		if config.Callback.Matches(CallbackOnCastComplete) {
+			aura.OnCastComplete = castCallback
+		}
+		if config.Callback.Matches(CallbackOnCastStart) {
+			aura.OnCastStart = castCallback
+		}
 	}
 }
 
@@ -168,6 +197,10 @@ func MakeStackingAura(character *Character, config StackingStatAura) *Aura {
 
 // Returns the same Aura for chaining.
 func MakePermanent(aura *Aura) *Aura {
+	if assignment := aura.Unit.Env.getBuffAssignment(aura.ActionID); assignment != nil {
+		return applyBuffAssignment(aura, assignment)
+	}
+
 	aura.Duration = NeverExpires
 	if aura.OnReset == nil {
 		aura.OnReset = func(aura *Aura, sim *Simulation) {
@@ -208,6 +241,9 @@ func (character *Character) NewTemporaryStatsAuraWrapped(auraLabel string, actio
 		Label:    auraLabel,
 		ActionID: actionID,
 		Duration: duration,
+
+		tempStats: buffs,
+
 		OnGain: func(aura *Aura, sim *Simulation) {
 			if sim.Log != nil {
 				character.Log(sim, "Gained %s from %s.", buffs.FlatString(), actionID)
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestActiveAurasWithTag(t *testing.T) {
+	sim := &Simulation{}
+
+	unit := &Unit{
+		Type:        EnemyUnit,
+		auraTracker: newAuraTracker(),
+	}
+	aura1 := unit.RegisterAura(Aura{Label: "Aura 1", Tag: "Tag", Duration: time.Second * 10})
+	aura2 := unit.RegisterAura(Aura{Label: "Aura 2", Tag: "Tag", Duration: time.Second * 10})
+	unit.RegisterAura(Aura{Label: "Aura 3", Tag: "Other Tag", Duration: time.Second * 10}).Activate(sim)
+
+	if unit.GetAura("Aura 2") != aura2 {
+		t.Fatalf("GetAura returned the wrong aura")
+	}
+	if unit.HasActiveAuraWithTag("Tag") || unit.GetActiveAuraWithTag("Tag") != nil {
+		t.Fatalf("No auras with the tag should be active")
+	}
+
+	aura2.Activate(sim)
+	aura1.Activate(sim)
+	aura1.Activate(sim) // Refreshing shouldn't count twice.
+	if n := unit.NumActiveAurasWithTag("Tag"); n != 2 {
+		t.Fatalf("Expected 2 active auras with the tag, got %d", n)
+	}
+	if unit.GetActiveAuraWithTag("Tag") != aura1 {
+		t.Fatalf("Expected the first registered active aura")
+	}
+
+	aura1.Deactivate(sim)
+	if !unit.HasActiveAuraWithTag("Tag") || unit.HasActiveAuraWithTagExcludingAura("Tag", aura2) {
+		t.Fatalf("Only aura 2 should be active")
+	}
+	if !unit.HasActiveAuraWithTagExcludingAura("Tag", aura1) {
+		t.Fatalf("Excluding an inactive aura shouldn't change anything")
+	}
+
+	aura2.Deactivate(sim)
+	if n := unit.NumActiveAurasWithTag("Tag"); n != 0 {
+		t.Fatalf("Expected no active auras with the tag, got %d", n)
+	}
+}
+
+func TestAuraMetrics(t *testing.T) {
+	sim := &Simulation{}
+
+	unit := &Unit{
+		Type:        EnemyUnit,
+		auraTracker: newAuraTracker(),
+	}
+	aura := unit.RegisterAura(Aura{
+		Label:     "Stacking Aura",
+		ActionID:  ActionID{SpellID: 1},
+		Duration:  time.Second * 10,
+		MaxStacks: 5,
+	})
+
+	aura.Activate(sim)
+	aura.SetStacks(sim, 2)
+
+	sim.CurrentTime = time.Second * 4
+	aura.SetStacks(sim, 4)
+
+	sim.CurrentTime = time.Second * 6
+	aura.Activate(sim) // Wastes 4s of the first application.
+
+	sim.CurrentTime = time.Second * 8
+	aura.Deactivate(sim)
+
+	aura.metrics.doneIteration(time.Second * 10)
+	metrics := aura.metrics.ToProto()
+
+	expectFloat := func(name string, actual, expected float64) {
+		if math.Abs(actual-expected) > 1e-9 {
+			t.Errorf("Expected %s = %f, got %f", name, expected, actual)
+		}
+	}
+	expectFloat("uptime", metrics.UptimeSecondsAvg, 8)
+	expectFloat("uptime %", metrics.UptimePercentAvg, 80)
+	expectFloat("procs", metrics.ProcsAvg, 2)
+	expectFloat("applications", metrics.ApplicationsAvg, 1)
+	expectFloat("stacks", metrics.StacksAvg, (2*4+4*4)/8.0)
+	expectFloat("refresh wasted", metrics.RefreshWastedSecondsAvg, 4)
+}
+
+func TestAuraCastLifecycleCallbacks(t *testing.T) {
+	sim := &Simulation{}
+
+	unit := &Unit{
+		Type:        EnemyUnit,
+		auraTracker: newAuraTracker(),
+	}
+	var events []string
+	aura := unit.RegisterAura(Aura{
+		Label:    "Lifecycle Aura",
+		Duration: time.Second * 10,
+		OnCastStart: func(aura *Aura, sim *Simulation, spell *Spell) {
+			events = append(events, "start")
+		},
+		OnCastInterrupted: func(aura *Aura, sim *Simulation, spell *Spell) {
+			events = append(events, "interrupted")
+		},
+		OnGCDReady: func(aura *Aura, sim *Simulation) {
+			events = append(events, "gcd")
+		},
+	})
+	spell := &Spell{Unit: unit}
+
+	invokeAll := func() {
+		unit.OnCastStart(sim, spell)
+		unit.OnCastInterrupted(sim, spell)
+		unit.onGCDReady(sim)
+	}
+
+	invokeAll()
+	if len(events) != 0 {
+		t.Fatalf("Inactive auras shouldn't receive events, got %v", events)
+	}
+
+	aura.Activate(sim)
+	invokeAll()
+	if len(events) != 3 || events[0] != "start" || events[1] != "interrupted" || events[2] != "gcd" {
+		t.Fatalf("Expected each callback once, got %v", events)
+	}
+
+	aura.Deactivate(sim)
+	invokeAll()
+	if len(events) != 3 {
+		t.Fatalf("Deactivated auras shouldn't receive events, got %v", events)
+	}
+}
@@ -0,0 +1,33 @@
+package core
+
+// Blocks reduce the damage of an attack by the defender's block value, see
+// Unit.BlockValue(), but never below 0. The amount which was actually blocked
+// is kept on the result and in the attacking spell's metrics.
+func (result *SpellResult) applyBlockValue(spell *Spell, blockValue float64) {
+	blocked := min(result.Damage, blockValue)
+	result.Damage -= blocked
+	result.BlockedDamage += blocked
+	spell.SpellMetrics[result.Target.UnitIndex].BlockedDamage += blocked
+}
+
+// Gives blocks against this character a chance to block double the block
+// value, like the warrior Critical Block talent. The second block value is
+// subtracted as a damage taken modifier, so modifiers registered earlier
+// apply to the damage before it.
+func (character *Character) RegisterCriticalBlock(actionID ActionID, procChance float64) {
+	criticalBlockSpell := character.GetOrRegisterSpell(SpellConfig{
+		ActionID: actionID,
+		Flags:    SpellFlagMeleeMetrics | SpellFlagNoOnCastComplete,
+	})
+
+	character.AddDynamicDamageTakenModifier(func(sim *Simulation, spell *Spell, result *SpellResult) {
+		if !result.Outcome.Matches(OutcomeBlock) || result.Outcome.Matches(OutcomeMiss|OutcomeParry|OutcomeDodge) {
+			return
+		}
+		if sim.RandomFloat("Critical Block Roll") <= procChance {
+			result.applyBlockValue(spell, character.BlockValue())
+			spell.SpellMetrics[result.Target.UnitIndex].CriticalBlocks++
+			criticalBlockSpell.Cast(sim, spell.Unit)
+		}
+	})
+}
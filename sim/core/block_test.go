@@ -0,0 +1,64 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestBlockedDamage(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{RandomSeed: 1})
+	request.Raid.Parties[0].Players[0].BonusStats = &proto.UnitStats{Stats: make([]float64, stats.Len)}
+	request.Raid.Parties[0].Players[0].BonusStats.Stats[stats.Block] = 100 * BlockRatingPerBlockChance
+	request.Raid.Parties[0].Players[0].BonusStats.Stats[stats.BlockValue] = 300
+	sim := NewSim(request)
+	sim.reset()
+
+	tank := sim.Raid.AllPlayerUnits[0]
+	tank.PseudoStats.CanBlock = true
+	target := sim.Encounter.TargetUnits[0]
+	attackTable := target.AttackTables[tank.UnitIndex]
+	spell := &Spell{Unit: target, SpellMetrics: make([]SpellMetrics, len(sim.AllUnits))}
+
+	blockValue := tank.BlockValue()
+	if blockValue < 300 {
+		t.Fatalf("Expected at least 300 block value, got %f", blockValue)
+	}
+
+	var blocks int32
+	var blockedDamage float64
+	for i := 0; i < 1000; i++ {
+		// Hits smaller than the block value are blocked completely.
+		baseDamage := TernaryFloat64(i%2 == 0, 1000, blockValue/2)
+		result := &SpellResult{Target: tank, Damage: baseDamage}
+		spell.OutcomeEnemyMeleeWhite(sim, result, attackTable)
+		if !result.Outcome.Matches(OutcomeBlock) {
+			continue
+		}
+		blocks++
+		blockedDamage += result.BlockedDamage
+
+		expectedBlocked := min(baseDamage, blockValue)
+		if result.BlockedDamage != expectedBlocked || result.Damage != baseDamage-expectedBlocked {
+			t.Fatalf("Expected %f of %f damage to be blocked, got %f blocked and %f taken", expectedBlocked, baseDamage, result.BlockedDamage, result.Damage)
+		}
+	}
+
+	metrics := spell.SpellMetrics[tank.UnitIndex]
+	if blocks == 0 || metrics.Blocks != blocks {
+		t.Fatalf("Expected %d blocks in the metrics, got %d", blocks, metrics.Blocks)
+	}
+	if !WithinToleranceFloat64(blockedDamage, metrics.BlockedDamage, 1e-6) {
+		t.Fatalf("Expected %f blocked damage in the metrics, got %f", blockedDamage, metrics.BlockedDamage)
+	}
+
+	event := LogEvent{Type: LogEventDamage, Actor: target, Target: tank, Outcome: OutcomeBlock, Amount: 700, Blocked: 300}
+	if line := event.Line(); !strings.Contains(line, "for 700.000 damage (300.000 blocked)") {
+		t.Fatalf("Expected the blocked amount in the log, got %s", line)
+	}
+	if blocked := event.jsonFields()["blocked"]; blocked != 300.0 {
+		t.Fatalf("Expected the blocked amount in the JSON log, got %v", blocked)
+	}
+}
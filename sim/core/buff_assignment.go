@@ -0,0 +1,179 @@
+package core
+
+import (
+	"slices"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+const defaultBuffAssignmentMeanUptime = time.Second * 30
+
+// Returns the assignment configured for the given buff or debuff aura, if any.
+func (env *Environment) getBuffAssignment(actionID ActionID) *proto.BuffAssignment {
+	if env == nil || actionID.IsEmptyAction() {
+		return nil
+	}
+	for _, assignment := range env.buffAssignments {
+		if ProtoToActionID(assignment.Id) == actionID {
+			return assignment
+		}
+	}
+	return nil
+}
+
+// Replaces the default 'always active' behavior of a raid buff or debuff with
+// the behavior described by an assignment.
+func applyBuffAssignment(aura *Aura, assignment *proto.BuffAssignment) *Aura {
+	aura.Duration = NeverExpires
+
+	if aura.Unit.Type != EnemyUnit && len(assignment.PlayerIndices) > 0 && !slices.Contains(assignment.PlayerIndices, aura.Unit.Index) {
+		// Not assigned to this player, so the aura is never activated.
+		return aura
+	}
+
+	if len(assignment.Windows) > 0 {
+		applyBuffAssignmentWindows(aura, assignment.Windows)
+	} else if assignment.Uptime > 0 && assignment.Uptime < 1 {
+		applyBuffAssignmentUptime(aura, assignment)
+	} else {
+		// Not MakePermanent, which would look up this assignment again.
+		addOnReset(aura, func(aura *Aura, sim *Simulation) {
+			aura.Activate(sim)
+		})
+		applyBuffAssignmentDowntimes(aura, assignment.Downtimes)
+	}
+	return aura
+}
+
+// Adds the stats of a raid buff which would otherwise be static. If the buff
+// has an assignment, they are added through an aura instead, so that the buff
+// can drop and be reapplied during the fight.
+func addAssignableBuffStats(character *Character, label string, actionID ActionID, buffStats stats.Stats) {
+	if character.Env.getBuffAssignment(actionID) == nil {
+		character.AddStats(buffStats)
+		return
+	}
+	MakePermanent(character.NewTemporaryStatsAura(label, actionID, buffStats, NeverExpires))
+}
+
+// Activates the aura during each of the given windows.
+func applyBuffAssignmentWindows(aura *Aura, windows []*proto.UptimeWindow) {
+	addOnReset(aura, func(aura *Aura, sim *Simulation) {
+		for _, window := range windows {
+			start := DurationFromSeconds(window.StartSeconds)
+			end := DurationFromSeconds(window.EndSeconds)
+			if end <= start {
+				continue
+			}
+
+			StartDelayedAction(sim, DelayedActionOptions{
+				DoAt:     start,
+				Priority: ActionPriorityDOT,
+				OnAction: func(sim *Simulation) {
+					aura.Activate(sim)
+				},
+			})
+			StartDelayedAction(sim, DelayedActionOptions{
+				DoAt:     end,
+				Priority: ActionPriorityDOT,
+				OnAction: func(sim *Simulation) {
+					aura.Deactivate(sim)
+				},
+			})
+		}
+	})
+}
+
+// Deactivates the aura at the start of each downtime, and activates it again
+// once the downtime is over.
+func applyBuffAssignmentDowntimes(aura *Aura, downtimes []*proto.BuffDowntime) {
+	addOnReset(aura, func(aura *Aura, sim *Simulation) {
+		for _, downtime := range downtimes {
+			duration := DurationFromSeconds(downtime.DurationSeconds)
+			interval := DurationFromSeconds(downtime.IntervalSeconds)
+			if duration <= 0 {
+				continue
+			}
+
+			var drop func(sim *Simulation)
+			drop = func(sim *Simulation) {
+				aura.Deactivate(sim)
+				StartDelayedAction(sim, DelayedActionOptions{
+					DoAt:     sim.CurrentTime + duration,
+					Priority: ActionPriorityDOT,
+					OnAction: func(sim *Simulation) {
+						aura.Activate(sim)
+					},
+				})
+				if interval > 0 {
+					StartDelayedAction(sim, DelayedActionOptions{
+						DoAt:     sim.CurrentTime + interval,
+						Priority: ActionPriorityDOT,
+						OnAction: drop,
+					})
+				}
+			}
+			StartDelayedAction(sim, DelayedActionOptions{
+				DoAt:     DurationFromSeconds(downtime.StartSeconds),
+				Priority: ActionPriorityDOT,
+				OnAction: drop,
+			})
+		}
+	})
+}
+
+// Models the aura as an alternating renewal process, with exponentially
+// distributed up and down times chosen so the long-run uptime matches the
+// configured value.
+func applyBuffAssignmentUptime(aura *Aura, assignment *proto.BuffAssignment) {
+	meanUp := DurationFromSeconds(assignment.MeanUptimeSeconds)
+	if meanUp <= 0 {
+		meanUp = defaultBuffAssignmentMeanUptime
+	}
+	meanDown := time.Duration(float64(meanUp) * (1 - assignment.Uptime) / assignment.Uptime)
+
+	label := "Buff Assignment " + aura.Label
+
+	addOnReset(aura, func(aura *Aura, sim *Simulation) {
+		pa := &PendingAction{
+			Priority: ActionPriorityDOT,
+		}
+		pa.OnAction = func(sim *Simulation) {
+			var next time.Duration
+			if aura.IsActive() {
+				aura.Deactivate(sim)
+				next = time.Duration(float64(meanDown) * sim.RandomExpFloat(label))
+			} else {
+				aura.Activate(sim)
+				next = time.Duration(float64(meanUp) * sim.RandomExpFloat(label))
+			}
+			pa.NextActionAt = sim.CurrentTime + max(next, time.Millisecond)
+			sim.AddPendingAction(pa)
+		}
+
+		// Start in the steady state.
+		if sim.RandomFloat(label) < assignment.Uptime {
+			aura.Activate(sim)
+			pa.NextActionAt = time.Duration(float64(meanUp) * sim.RandomExpFloat(label))
+		} else {
+			pa.NextActionAt = time.Duration(float64(meanDown) * sim.RandomExpFloat(label))
+		}
+		sim.AddPendingAction(pa)
+	})
+}
+
+// Chains an additional OnReset callback after any existing one.
+func addOnReset(aura *Aura, onReset OnReset) {
+	if aura.OnReset == nil {
+		aura.OnReset = onReset
+		return
+	}
+
+	oldOnReset := aura.OnReset
+	aura.OnReset = func(aura *Aura, sim *Simulation) {
+		oldOnReset(aura, sim)
+		onReset(aura, sim)
+	}
+}
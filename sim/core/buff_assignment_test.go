@@ -0,0 +1,135 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func runBuffAssignmentSim(assignment *proto.BuffAssignment) *proto.RaidSimResult {
+	return RunRaidSim(&proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{
+			Iterations:  200,
+			RandomSeed:  101,
+			Interactive: true,
+		},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:      "Caster",
+							Class:     proto.Class_ClassShaman,
+							Consumes:  &proto.Consumes{},
+							Buffs:     &proto.IndividualBuffs{},
+							Spec:      &proto.Player_ElementalShaman{},
+							Equipment: &proto.EquipmentSpec{},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+			Buffs: &proto.RaidBuffs{
+				GiftOfTheWild: proto.TristateEffect_TristateEffectRegular,
+			},
+			Debuffs: &proto.Debuffs{
+				Mangle: true,
+			},
+			BuffAssignments: []*proto.BuffAssignment{assignment},
+		},
+		Encounter: &proto.Encounter{
+			Duration: 300,
+			Targets: []*proto.Target{
+				NewDefaultTarget(),
+			},
+		},
+	})
+}
+
+var mangleAuraID = ActionID{SpellID: 48566}
+
+func getMangleUptime(t *testing.T, result *proto.RaidSimResult) float64 {
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed with error: %s", result.ErrorResult)
+	}
+	for _, aura := range result.EncounterMetrics.Targets[0].Auras {
+		if ProtoToActionID(aura.Id) == mangleAuraID {
+			return aura.UptimeSecondsAvg / 300
+		}
+	}
+	return 0
+}
+
+func TestBuffAssignmentUptime(t *testing.T) {
+	uptime := getMangleUptime(t, runBuffAssignmentSim(&proto.BuffAssignment{
+		Id:     mangleAuraID.ToProto(),
+		Uptime: 0.8,
+	}))
+
+	if !WithinToleranceFloat64(0.8, uptime, 0.03) {
+		t.Fatalf("Expected Mangle uptime of 0.8, got %f", uptime)
+	}
+}
+
+func TestBuffAssignmentWindows(t *testing.T) {
+	uptime := getMangleUptime(t, runBuffAssignmentSim(&proto.BuffAssignment{
+		Id: mangleAuraID.ToProto(),
+		Windows: []*proto.UptimeWindow{
+			{StartSeconds: 0, EndSeconds: 60},
+			{StartSeconds: 150, EndSeconds: 240},
+		},
+	}))
+
+	if !WithinToleranceFloat64(0.5, uptime, 0.001) {
+		t.Fatalf("Expected Mangle uptime of 0.5, got %f", uptime)
+	}
+}
+
+func TestBuffAssignmentPermanent(t *testing.T) {
+	// Player indices don't apply to debuffs, so this is always active.
+	uptime := getMangleUptime(t, runBuffAssignmentSim(&proto.BuffAssignment{
+		Id:            mangleAuraID.ToProto(),
+		PlayerIndices: []int32{0},
+	}))
+
+	if !WithinToleranceFloat64(1, uptime, 0.001) {
+		t.Fatalf("Expected Mangle uptime of 1, got %f", uptime)
+	}
+}
+
+func TestBuffAssignmentDowntimes(t *testing.T) {
+	uptime := getMangleUptime(t, runBuffAssignmentSim(&proto.BuffAssignment{
+		Id: mangleAuraID.ToProto(),
+		Downtimes: []*proto.BuffDowntime{
+			{StartSeconds: 60, DurationSeconds: 30, IntervalSeconds: 100},
+		},
+	}))
+
+	// Dropped at 60s, 160s and 260s.
+	if !WithinToleranceFloat64(0.7, uptime, 0.001) {
+		t.Fatalf("Expected Mangle uptime of 0.7, got %f", uptime)
+	}
+}
+
+func TestBuffAssignmentStaticBuff(t *testing.T) {
+	gotwAuraID := ActionID{SpellID: 48470}
+	result := runBuffAssignmentSim(&proto.BuffAssignment{
+		Id: gotwAuraID.ToProto(),
+		Windows: []*proto.UptimeWindow{
+			{StartSeconds: 0, EndSeconds: 120},
+		},
+	})
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed with error: %s", result.ErrorResult)
+	}
+
+	var uptime float64
+	for _, aura := range result.RaidMetrics.Parties[0].Players[0].Auras {
+		if ProtoToActionID(aura.Id) == gotwAuraID {
+			uptime = aura.UptimeSecondsAvg / 300
+		}
+	}
+	if !WithinToleranceFloat64(0.4, uptime, 0.001) {
+		t.Fatalf("Expected Gift of the Wild uptime of 0.4, got %f", uptime)
+	}
+}
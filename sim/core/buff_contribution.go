@@ -0,0 +1,186 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	goproto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Simulates the base request once without each group of buffs, and returns how
+// much raid DPS each group is worth.
+func BuffContributions(ctx context.Context, request *proto.BuffContributionRequest, progress chan *proto.ProgressMetrics) *proto.BuffContributionResult {
+	result, err := runBuffContributions(ctx, request, progress)
+	if err != nil {
+		result = &proto.BuffContributionResult{
+			ErrorResult: err.Error(),
+		}
+	}
+
+	if progress != nil {
+		progress <- &proto.ProgressMetrics{
+			FinalBuffContributionResult: result,
+		}
+		close(progress)
+	}
+
+	return result
+}
+
+func runBuffContributions(ctx context.Context, request *proto.BuffContributionRequest, progress chan *proto.ProgressMetrics) (*proto.BuffContributionResult, error) {
+	if request.Base == nil {
+		return nil, errors.New("buff contribution request has no base")
+	}
+
+	base := pairedSimRequest(request.Base)
+	groups := request.Groups
+	if len(groups) == 0 {
+		groups = defaultBuffContributionGroups(base)
+	}
+
+	totalSims := int32(len(groups) + 1)
+	var completedSims int32
+	runOne := func(rsr *proto.RaidSimRequest) *proto.RaidSimResult {
+		result := RunSimWithContext(ctx, rsr, nil)
+		completedSims++
+		if progress != nil {
+			progress <- &proto.ProgressMetrics{
+				CompletedSims: completedSims,
+				TotalSims:     totalSims,
+			}
+		}
+		return result
+	}
+
+	baseline := runOne(base)
+	if baseline.ErrorResult != "" {
+		return nil, errors.New(baseline.ErrorResult)
+	}
+
+	result := &proto.BuffContributionResult{
+		BaseDps:       baseline.RaidMetrics.Dps.Avg,
+		Contributions: make([]*proto.BuffContribution, 0, len(groups)),
+	}
+	for _, group := range groups {
+		if ctx.Err() != nil {
+			break
+		}
+
+		contribution := &proto.BuffContribution{
+			Label: group.Label,
+			Paths: group.Paths,
+		}
+		if contribution.Label == "" {
+			contribution.Label = strings.Join(group.Paths, ", ")
+		}
+		result.Contributions = append(result.Contributions, contribution)
+
+		rsr := goproto.Clone(base).(*proto.RaidSimRequest)
+		if err := removeBuffs(rsr, group.Paths); err != nil {
+			contribution.ErrorResult = err.Error()
+			completedSims++
+			continue
+		}
+
+		simResult := runOne(rsr)
+		if simResult.ErrorResult != "" {
+			contribution.ErrorResult = simResult.ErrorResult
+			continue
+		}
+
+		// The base minus the changed sim, so buffs which help are positive.
+		delta, halfWidth := pairedDifference(simResult.RaidMetrics.Dps.AllValues, baseline.RaidMetrics.Dps.AllValues)
+		contribution.DpsContribution = delta
+		contribution.DpsContributionHalfWidth = halfWidth
+		contribution.Significant = halfWidth < math.Abs(delta)
+		contribution.Players = playerBuffContributions(baseline.RaidMetrics, simResult.RaidMetrics)
+	}
+
+	slices.SortStableFunc(result.Contributions, func(a, b *proto.BuffContribution) int {
+		if a.DpsContribution > b.DpsContribution {
+			return -1
+		} else if a.DpsContribution < b.DpsContribution {
+			return 1
+		}
+		return 0
+	})
+	return result, nil
+}
+
+// Clears each of the fields at the given paths.
+func removeBuffs(rsr *proto.RaidSimRequest, paths []string) error {
+	if len(paths) == 0 {
+		return errors.New("buff contribution group has no paths")
+	}
+	for _, path := range paths {
+		if err := setProtoField(rsr.ProtoReflect(), path, "null"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns one group for each buff and debuff which is set in the request.
+func defaultBuffContributionGroups(rsr *proto.RaidSimRequest) []*proto.BuffContributionGroup {
+	var groups []*proto.BuffContributionGroup
+	addSetFields := func(prefix string, msg protoreflect.Message) {
+		var paths []string
+		msg.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+			paths = append(paths, prefix+"."+string(fd.Name()))
+			return true
+		})
+		// Range doesn't visit fields in a fixed order.
+		slices.Sort(paths)
+		for _, path := range paths {
+			groups = append(groups, &proto.BuffContributionGroup{Paths: []string{path}})
+		}
+	}
+
+	raid := rsr.Raid
+	if raid.GetBuffs() != nil {
+		addSetFields("raid.buffs", raid.Buffs.ProtoReflect())
+	}
+	if raid.GetDebuffs() != nil {
+		addSetFields("raid.debuffs", raid.Debuffs.ProtoReflect())
+	}
+	for partyIdx, party := range raid.GetParties() {
+		if party.GetBuffs() != nil {
+			addSetFields(fmt.Sprintf("raid.parties.%d.buffs", partyIdx), party.Buffs.ProtoReflect())
+		}
+		for playerIdx, player := range party.GetPlayers() {
+			if player.GetBuffs() != nil {
+				addSetFields(fmt.Sprintf("raid.parties.%d.players.%d.buffs", partyIdx, playerIdx), player.Buffs.ProtoReflect())
+			}
+		}
+	}
+	return groups
+}
+
+// Returns the DPS lost by each player with metrics in both sims.
+func playerBuffContributions(base, changed *proto.RaidMetrics) []*proto.PlayerBuffContribution {
+	var players []*proto.PlayerBuffContribution
+	for partyIdx, party := range base.Parties {
+		if partyIdx >= len(changed.Parties) {
+			break
+		}
+		for playerIdx, player := range party.Players {
+			if playerIdx >= len(changed.Parties[partyIdx].Players) || player.Dps == nil {
+				continue
+			}
+			changedPlayer := changed.Parties[partyIdx].Players[playerIdx]
+			players = append(players, &proto.PlayerBuffContribution{
+				Name:            player.Name,
+				UnitIndex:       player.UnitIndex,
+				DpsContribution: player.Dps.Avg - changedPlayer.GetDps().GetAvg(),
+			})
+		}
+	}
+	return players
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestDefaultBuffContributionGroups(t *testing.T) {
+	rsr := whatIfTestRequest()
+	rsr.Raid.Buffs.GiftOfTheWild = proto.TristateEffect_TristateEffectImproved
+	rsr.Raid.Buffs.Bloodlust = true
+	rsr.Raid.Parties[0].Players[0].Buffs.BlessingOfKings = true
+
+	var paths []string
+	for _, group := range defaultBuffContributionGroups(rsr) {
+		paths = append(paths, group.Paths...)
+	}
+	expected := []string{
+		"raid.buffs.bloodlust",
+		"raid.buffs.gift_of_the_wild",
+		"raid.parties.0.players.0.buffs.blessing_of_kings",
+	}
+	if !slices.Equal(expected, paths) {
+		t.Fatalf("Expected groups %v, got %v", expected, paths)
+	}
+
+	if err := removeBuffs(rsr, paths); err != nil {
+		t.Fatalf("Failed to remove buffs: %s", err)
+	}
+	if len(defaultBuffContributionGroups(rsr)) != 0 {
+		t.Errorf("Expected every buff to be removed, got %v", rsr.Raid)
+	}
+}
+
+func TestBuffContributions(t *testing.T) {
+	base := whatIfTestRequest()
+	base.SimOptions.Interactive = false
+	base.Raid.Buffs.TotemOfWrath = true
+	// Spell power adds to the fake dot's damage.
+	base.Raid.Parties[0].Players[0].Rotation = fakeDotRotation()
+
+	result := BuffContributions(context.Background(), &proto.BuffContributionRequest{
+		Base: base,
+		Groups: []*proto.BuffContributionGroup{
+			{Label: "Not A Buff", Paths: []string{"raid.buffs.not_a_buff"}},
+			{Paths: []string{"raid.buffs.totem_of_wrath"}},
+			// Not set in the base, so removing it changes nothing.
+			{Label: "Kings", Paths: []string{"raid.parties.0.players.0.buffs.blessing_of_kings"}},
+		},
+	}, nil)
+
+	if result.ErrorResult != "" {
+		t.Fatalf("Buff contribution failed: %s", result.ErrorResult)
+	}
+	if len(result.Contributions) != 3 {
+		t.Fatalf("Expected 3 contributions, got %d", len(result.Contributions))
+	}
+
+	// Sorted by contribution, so Totem of Wrath comes first.
+	totem := result.Contributions[0]
+	if totem.Label != "raid.buffs.totem_of_wrath" || totem.ErrorResult != "" {
+		t.Fatalf("Expected Totem of Wrath first, got %v", totem)
+	}
+	if totem.DpsContribution <= 0 || !totem.Significant {
+		t.Errorf("Expected Totem of Wrath to add DPS, got %v", totem)
+	}
+	if len(totem.Players) != 1 || !WithinToleranceFloat64(totem.DpsContribution, totem.Players[0].DpsContribution, 1e-6) {
+		t.Errorf("Expected the only player to lose all of the DPS, got %v", totem.Players)
+	}
+
+	for _, contribution := range result.Contributions[1:] {
+		switch contribution.Label {
+		case "Kings":
+			if contribution.ErrorResult != "" || contribution.DpsContribution != 0 || contribution.Significant {
+				t.Errorf("Expected no change from an unset buff, got %v", contribution)
+			}
+		case "Not A Buff":
+			if contribution.ErrorResult == "" {
+				t.Errorf("Expected an error from an invalid path")
+			}
+		default:
+			t.Errorf("Unexpected contribution %v", contribution)
+		}
+	}
+}
+
+// Keeps the fake dot from dot_test.go up.
+func fakeDotRotation() *proto.APLRotation {
+	return &proto.APLRotation{
+		Type: proto.APLRotation_TypeAPL,
+		PriorityList: []*proto.APLListItem{{
+			Action: &proto.APLAction{
+				Condition: &proto.APLValue{Value: &proto.APLValue_Not{Not: &proto.APLValueNot{
+					Val: &proto.APLValue{Value: &proto.APLValue_DotIsActive{DotIsActive: &proto.APLValueDotIsActive{
+						SpellId: ActionID{SpellID: 42}.ToProto(),
+					}}},
+				}}},
+				Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{
+					SpellId: ActionID{SpellID: 42}.ToProto(),
+				}},
+			},
+		}},
+	}
+}
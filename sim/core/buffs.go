@@ -2,6 +2,7 @@ package core
 
 import (
 	"cmp"
+	"fmt"
 	"math"
 	"slices"
 	"strconv"
@@ -19,10 +20,12 @@ func applyBuffEffects(agent Agent, raidBuffs *proto.RaidBuffs, partyBuffs *proto
 
 	if raidBuffs.ArcaneBrilliance || raidBuffs.FelIntelligence > 0 {
 		val := GetTristateValueFloat(raidBuffs.FelIntelligence, 48.0, 48.0*1.1)
+		label, actionID := "Fel Intelligence", ActionID{SpellID: 57567}
 		if raidBuffs.ArcaneBrilliance {
 			val = 60.0
+			label, actionID = "Arcane Brilliance", ActionID{SpellID: 43002}
 		}
-		character.AddStat(stats.Intellect, val)
+		addAssignableBuffStats(character, label, actionID, stats.Stats{stats.Intellect: val})
 	} else if raidBuffs.ScrollOfIntellect {
 		character.AddStats(stats.Stats{
 			stats.Intellect: 48,
@@ -36,7 +39,7 @@ func applyBuffEffects(agent Agent, raidBuffs *proto.RaidBuffs, partyBuffs *proto
 	gotwArmorAmount := GetTristateValueFloat(raidBuffs.GiftOfTheWild, 750, 1050)
 	gotwResistAmount := GetTristateValueFloat(raidBuffs.GiftOfTheWild, 54, 75)
 	if gotwAmount > 0 {
-		character.AddStats(stats.Stats{
+		addAssignableBuffStats(character, "Gift of the Wild", ActionID{SpellID: 48470}, stats.Stats{
 			stats.Armor:            gotwArmorAmount,
 			stats.Stamina:          gotwAmount,
 			stats.Agility:          gotwAmount,
@@ -66,7 +69,11 @@ func applyBuffEffects(agent Agent, raidBuffs *proto.RaidBuffs, partyBuffs *proto
 	}
 
 	if raidBuffs.MoonkinAura > 0 || raidBuffs.ElementalOath {
-		character.AddStat(stats.SpellCrit, 5*CritRatingPerCritChance)
+		label, actionID := "Elemental Oath", ActionID{SpellID: 51470}
+		if raidBuffs.MoonkinAura > 0 {
+			label, actionID = "Moonkin Aura", ActionID{SpellID: 24907}
+		}
+		addAssignableBuffStats(character, label, actionID, stats.Stats{stats.SpellCrit: 5 * CritRatingPerCritChance})
 	}
 
 	if raidBuffs.MoonkinAura == proto.TristateEffect_TristateEffectImproved || raidBuffs.SwiftRetribution {
@@ -77,7 +84,11 @@ func applyBuffEffects(agent Agent, raidBuffs *proto.RaidBuffs, partyBuffs *proto
 	}
 
 	if raidBuffs.LeaderOfThePack > 0 || raidBuffs.Rampage {
-		character.AddStats(stats.Stats{
+		label, actionID := "Rampage", ActionID{SpellID: 29801}
+		if raidBuffs.LeaderOfThePack > 0 {
+			label, actionID = "Leader of the Pack", ActionID{SpellID: 17007}
+		}
+		addAssignableBuffStats(character, label, actionID, stats.Stats{
 			stats.MeleeCrit: 5 * CritRatingPerCritChance,
 		})
 		if raidBuffs.LeaderOfThePack == proto.TristateEffect_TristateEffectImproved {
@@ -96,7 +107,7 @@ func applyBuffEffects(agent Agent, raidBuffs *proto.RaidBuffs, partyBuffs *proto
 	}
 
 	if partyBuffs.HeroicPresence {
-		character.AddStats(stats.Stats{
+		addAssignableBuffStats(character, "Heroic Presence", ActionID{SpellID: 6562}, stats.Stats{
 			stats.MeleeHit: 1 * MeleeHitRatingPerHitChance,
 			stats.SpellHit: 1 * SpellHitRatingPerHitChance,
 		})
@@ -110,7 +121,7 @@ func applyBuffEffects(agent Agent, raidBuffs *proto.RaidBuffs, partyBuffs *proto
 	}
 
 	if raidBuffs.PowerWordFortitude != proto.TristateEffect_TristateEffectMissing {
-		character.AddStats(stats.Stats{
+		addAssignableBuffStats(character, "Power Word: Fortitude", ActionID{SpellID: 48161}, stats.Stats{
 			stats.Stamina: GetTristateValueFloat(raidBuffs.PowerWordFortitude, 165, 165*1.3),
 		})
 	} else if raidBuffs.ScrollOfStamina {
@@ -119,16 +130,19 @@ func applyBuffEffects(agent Agent, raidBuffs *proto.RaidBuffs, partyBuffs *proto
 		})
 	}
 	if raidBuffs.ShadowProtection {
-		character.AddStats(stats.Stats{
+		addAssignableBuffStats(character, "Shadow Protection", ActionID{SpellID: 48170}, stats.Stats{
 			stats.ShadowResistance: 130 - gotwResistAmount,
 		})
 	}
 	if raidBuffs.DivineSpirit || raidBuffs.FelIntelligence > 0 {
 		v := GetTristateValueFloat(raidBuffs.FelIntelligence, 64.0, 64.0*1.1)
+		// Fel Intelligence also grants intellect, above.
+		label, actionID := "Fel Intelligence Spirit", ActionID{SpellID: 57567}
 		if raidBuffs.DivineSpirit {
 			v = 80.0
+			label, actionID = "Divine Spirit", ActionID{SpellID: 48073}
 		}
-		character.AddStats(stats.Stats{
+		addAssignableBuffStats(character, label, actionID, stats.Stats{
 			stats.Spirit: v,
 		})
 	} else if raidBuffs.ScrollOfSpirit {
@@ -285,6 +299,7 @@ func applyBuffEffects(agent Agent, raidBuffs *proto.RaidBuffs, partyBuffs *proto
 	registerHandOfSacrificeCD(agent, individualBuffs.HandOfSacrifices)
 	registerPainSuppressionCD(agent, individualBuffs.PainSuppressions)
 	registerGuardianSpiritCD(agent, individualBuffs.GuardianSpirits)
+	registerScriptedExternals(agent, individualBuffs.ScriptedExternals)
 
 	character.AddStats(stats.Stats{
 		stats.SpellCrit: 28 * float64(partyBuffs.AtieshMage),
@@ -332,6 +347,7 @@ func applyPetBuffEffects(petAgent PetAgent, raidBuffs *proto.RaidBuffs, partyBuf
 	individualBuffs.TricksOfTheTrades = 0
 	individualBuffs.ShatteringThrows = 0
 	individualBuffs.FocusMagic = false
+	individualBuffs.ScriptedExternals = nil
 
 	if !petAgent.GetPet().enabledOnStart {
 		raidBuffs.ArcaneBrilliance = false
@@ -388,73 +404,34 @@ func ApplyInspiration(character *Character, uptime float64) {
 }
 
 func RetributionAura(character *Character, sanctifiedRetribution bool) *Aura {
-	actionID := ActionID{SpellID: 54043}
-
 	baseDamage := 112.0
 	if sanctifiedRetribution {
 		baseDamage *= 1.5
 	}
 
-	procSpell := character.RegisterSpell(SpellConfig{
-		ActionID:    actionID,
+	return MakePermanent(character.RegisterDamageShield(DamageShieldConfig{
+		Label:       "Retribution Aura",
+		ActionID:    ActionID{SpellID: 54043},
 		SpellSchool: SpellSchoolHoly,
-		ProcMask:    ProcMaskEmpty,
 		Flags:       SpellFlagBinary,
-
-		DamageMultiplier: 1,
-		ThreatMultiplier: 1,
-
-		ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {
-			spell.CalcAndDealDamage(sim, target, baseDamage, spell.OutcomeMagicHit)
-		},
-	})
-
-	return character.RegisterAura(Aura{
-		Label:    "Retribution Aura",
-		ActionID: actionID,
-		Duration: NeverExpires,
-		OnReset: func(aura *Aura, sim *Simulation) {
-			aura.Activate(sim)
+		BaseDamage: func(_ *Simulation) float64 {
+			return baseDamage
 		},
-		OnSpellHitTaken: func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
-			if result.Landed() && spell.SpellSchool == SpellSchoolPhysical {
-				procSpell.Cast(sim, spell.Unit)
-			}
-		},
-	})
+	}))
 }
 
 func ThornsAura(character *Character, points int32) *Aura {
-	actionID := ActionID{SpellID: 53307}
 	baseDamage := 73 * (1 + 0.25*float64(points))
 
-	procSpell := character.RegisterSpell(SpellConfig{
-		ActionID:    actionID,
+	return MakePermanent(character.RegisterDamageShield(DamageShieldConfig{
+		Label:       "Thorns",
+		ActionID:    ActionID{SpellID: 53307},
 		SpellSchool: SpellSchoolNature,
-		ProcMask:    ProcMaskEmpty,
 		Flags:       SpellFlagBinary,
-
-		DamageMultiplier: 1,
-		ThreatMultiplier: 1,
-
-		ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {
-			spell.CalcAndDealDamage(sim, target, baseDamage, spell.OutcomeMagicHit)
-		},
-	})
-
-	return character.RegisterAura(Aura{
-		Label:    "Thorns",
-		ActionID: actionID,
-		Duration: NeverExpires,
-		OnReset: func(aura *Aura, sim *Simulation) {
-			aura.Activate(sim)
-		},
-		OnSpellHitTaken: func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
-			if result.Landed() && spell.SpellSchool == SpellSchoolPhysical {
-				procSpell.Cast(sim, spell.Unit)
-			}
+		BaseDamage: func(_ *Simulation) float64 {
+			return baseDamage
 		},
-	})
+	}))
 }
 
 func BlessingOfSanctuaryAura(character *Character) {
@@ -558,6 +535,97 @@ func registerExternalConsecutiveCDApproximation(agent Agent, config externalCons
 	})
 }
 
+// Returns the aura of an external which can be scripted, and the cooldown of
+// the player casting it.
+func scriptedExternalAura(character *Character, actionID ActionID) (*Aura, time.Duration) {
+	// Reuse the aura if the player also receives the approximated external.
+	existing := character.GetAuraByID(actionID.WithTag(-1))
+
+	switch actionID.SpellID {
+	case PowerInfusionActionID.SpellID:
+		if existing == nil {
+			existing = PowerInfusionAura(&character.Unit, -1)
+		}
+		return existing, time.Duration(float64(PowerInfusionCD) * 0.8)
+	case 29166:
+		if existing == nil {
+			existing = InnervateAura(character, -1)
+		}
+		return existing, InnervateCD
+	case 57933:
+		if existing == nil {
+			existing = TricksOfTheTradeAura(&character.Unit, -1, true)
+		}
+		// The cooldown starts once the buff ends.
+		return existing, existing.Duration + time.Second*30
+	case 49016:
+		if existing == nil {
+			existing = UnholyFrenzyAura(&character.Unit, -1)
+		}
+		return existing, UnholyFrenzyCD
+	}
+	panic(fmt.Sprintf("Unsupported scripted external: %s", actionID))
+}
+
+// Casts each external at its exact times, and whenever its condition is met
+// once the caster's cooldown is ready.
+func registerScriptedExternals(agent Agent, scriptedExternals []*proto.ScriptedExternal) {
+	character := agent.GetCharacter()
+
+	for _, external := range scriptedExternals {
+		external := external
+		actionID := ProtoToActionID(external.Id)
+		aura, cooldown := scriptedExternalAura(character, actionID)
+		cd := Cooldown{
+			Timer:    character.NewTimer(),
+			Duration: cooldown,
+		}
+
+		if len(external.CastTimesSeconds) > 0 {
+			character.RegisterResetEffect(func(sim *Simulation) {
+				for _, castTime := range external.CastTimesSeconds {
+					StartDelayedAction(sim, DelayedActionOptions{
+						DoAt: DurationFromSeconds(castTime),
+						OnAction: func(sim *Simulation) {
+							cd.Use(sim)
+							aura.Activate(sim)
+						},
+					})
+				}
+			})
+		}
+
+		if external.BelowManaPercent <= 0 {
+			continue
+		}
+		spell := character.RegisterSpell(SpellConfig{
+			ActionID: actionID.WithTag(-1),
+			Flags:    SpellFlagNoOnCastComplete | SpellFlagNoMetrics | SpellFlagNoLogs,
+
+			Cast: CastConfig{
+				CD: cd,
+			},
+			ExtraCastCondition: func(sim *Simulation, target *Unit) bool {
+				return !aura.IsActive()
+			},
+
+			ApplyEffects: func(sim *Simulation, _ *Unit, _ *Spell) {
+				aura.Activate(sim)
+			},
+		})
+
+		character.AddMajorCooldown(MajorCooldown{
+			Spell:    spell,
+			Priority: CooldownPriorityDefault,
+			Type:     CooldownTypeMana,
+
+			ShouldActivate: func(sim *Simulation, character *Character) bool {
+				return character.HasManaBar() && character.CurrentManaPercent() < external.BelowManaPercent
+			},
+		})
+	}
+}
+
 var BloodlustActionID = ActionID{SpellID: 2825}
 
 const SatedAuraLabel = "Sated"
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestScriptedExternals(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{Iterations: 20, RandomSeed: 101})
+	request.Encounter.Duration = 300
+	request.Encounter.DurationVariation = 0
+	request.Raid.Parties[0].Players[0].Rotation = &proto.APLRotation{
+		Type: proto.APLRotation_TypeAPL,
+		PriorityList: []*proto.APLListItem{{
+			Action: &proto.APLAction{Action: &proto.APLAction_AutocastOtherCooldowns{
+				AutocastOtherCooldowns: &proto.APLActionAutocastOtherCooldowns{},
+			}},
+		}},
+	}
+	request.Raid.Parties[0].Players[0].Buffs.ScriptedExternals = []*proto.ScriptedExternal{
+		{
+			Id:               PowerInfusionActionID.ToProto(),
+			CastTimesSeconds: []float64{10, 130},
+		},
+		{
+			Id:               ActionID{SpellID: 57933}.ToProto(),
+			CastTimesSeconds: []float64{0},
+		},
+		{
+			// The test agent has no mana bar, so this is never cast.
+			Id:               ActionID{SpellID: 29166}.ToProto(),
+			BelowManaPercent: 0.6,
+		},
+	}
+
+	result := RunRaidSim(request)
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed with error: %s", result.ErrorResult)
+	}
+
+	uptimes := make(map[ActionID]float64)
+	for _, aura := range result.RaidMetrics.Parties[0].Players[0].Auras {
+		uptimes[ProtoToActionID(aura.Id)] = aura.UptimeSecondsAvg
+	}
+	if uptime := uptimes[PowerInfusionActionID.WithTag(-1)]; !WithinToleranceFloat64(2*PowerInfusionDuration.Seconds(), uptime, 0.001) {
+		t.Fatalf("Expected %f seconds of Power Infusion, got %f", 2*PowerInfusionDuration.Seconds(), uptime)
+	}
+	if uptime := uptimes[ActionID{SpellID: 57933, Tag: -1}]; !WithinToleranceFloat64(10, uptime, 0.001) {
+		t.Fatalf("Expected 10 seconds of Tricks of the Trade, got %f", uptime)
+	}
+	if uptime := uptimes[ActionID{SpellID: 29166, Tag: -1}]; uptime != 0 {
+		t.Fatalf("Expected no Innervate, got %f seconds", uptime)
+	}
+}
@@ -22,7 +22,7 @@ const (
 )
 
 // raidSimRunner runs a standard raid simulation.
-type raidSimRunner func(*proto.RaidSimRequest, chan *proto.ProgressMetrics, bool) *proto.RaidSimResult
+type raidSimRunner func(context.Context, *proto.RaidSimRequest, chan *proto.ProgressMetrics, bool) *proto.RaidSimResult
 
 // bulkSimRunner runs a bulk simulation.
 type bulkSimRunner struct {
@@ -34,7 +34,7 @@ type bulkSimRunner struct {
 
 func BulkSim(ctx context.Context, request *proto.BulkSimRequest, progress chan *proto.ProgressMetrics) *proto.BulkSimResult {
 	bulk := &bulkSimRunner{
-		SingleRaidSimRunner: runSim,
+		SingleRaidSimRunner: runSimWithContext,
 		Request:             request,
 	}
 
@@ -59,6 +59,8 @@ type singleBulkSim struct {
 	req *proto.RaidSimRequest
 	cl  *raidSimRequestChangeLog
 	eq  *equipmentSubstitution
+	cs  *proto.Consumes         // Only set for consumable sweep combos.
+	pf  *proto.PetFamilyVariant // Only set for pet family sweep combos.
 }
 
 func (b *bulkSimRunner) Run(pctx context.Context, progress chan *proto.ProgressMetrics) (result *proto.BulkSimResult, resultErr error) {
@@ -72,6 +74,175 @@ func (b *bulkSimRunner) Run(pctx context.Context, progress chan *proto.ProgressM
 		cancel()
 	}()
 
+	plan, err := b.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+	validCombos, iterations, sweep, petSweep, priceTable := plan.combos, plan.iterations, plan.sweep, plan.petSweep, plan.priceTable
+
+	// TODO(Riotdog-GehennasEU): Make this configurable?
+	maxResults := 30
+
+	var rankedResults []*itemSubstitutionSimResult
+	var baseResult *itemSubstitutionSimResult
+	newIters := int64(iterations)
+	// Fast mode drops the lowest DPS combos, which may still be the best value
+	// for a consumable sweep.
+	fastMode := b.Request.BulkSettings.FastMode && sweep == nil
+	if fastMode {
+		newIters /= 100
+
+		// In fast mode try to keep starting iterations between 50 and 1000.
+		if newIters < 50 {
+			newIters = 50
+		}
+		if newIters > 1000 {
+			newIters = 1000
+		}
+	}
+
+	maxIterations := newIters * int64(len(validCombos))
+	if maxIterations > math.MaxInt32 {
+		return nil, fmt.Errorf("number of total iterations %d too large", maxIterations)
+	}
+
+	for {
+		var tempBase *itemSubstitutionSimResult
+		var err error
+		// TODO: we could theoretically make getRankedResults accept a channel of validCombos that stream in to it and launches sims as it gets them...
+		rankedResults, tempBase, err = b.getRankedResults(ctx, validCombos, newIters, progress)
+
+		if err != nil {
+			return nil, err
+		}
+		// keep replacing the base result with more refined base until we don't have base in the ranked results anymore.
+		if tempBase != nil {
+			baseResult = tempBase
+		}
+
+		// If we aren't doing fast mode, or if halving our results will be less than the maxResults, be done.
+		if !fastMode || len(rankedResults) <= maxResults*2 {
+			break
+		}
+
+		// we have reached max accuracy now
+		if newIters >= int64(iterations) {
+			break
+		}
+
+		// Increase accuracy
+		newIters *= 2
+		newNumCombos := len(rankedResults) / 2
+		validCombos = validCombos[:newNumCombos]
+		rankedResults = rankedResults[:newNumCombos]
+		for i, comb := range rankedResults {
+			validCombos[i] = singleBulkSim{
+				req: comb.Request,
+				cl:  comb.ChangeLog,
+				eq:  comb.Substitution,
+				cs:  comb.Consumes,
+				pf:  comb.PetFamily,
+			}
+		}
+	}
+
+	if baseResult == nil {
+		return nil, fmt.Errorf("no base result for equipped gear found in bulk sim")
+	}
+
+	if sweep != nil {
+		equipped, sweepResults := rankConsumableSweep(priceTable, rankedResults, baseResult)
+		if len(sweepResults) > maxResults {
+			sweepResults = sweepResults[:maxResults]
+		}
+		result = &proto.BulkSimResult{
+			Results:            sweepResults,
+			EquippedGearResult: equipped,
+		}
+		if progress != nil {
+			progress <- &proto.ProgressMetrics{
+				FinalBulkResult: result,
+			}
+		}
+		return result, nil
+	}
+
+	if petSweep != nil {
+		equipped, sweepResults := rankPetFamilySweep(rankedResults, baseResult)
+		if len(sweepResults) > maxResults {
+			sweepResults = sweepResults[:maxResults]
+		}
+		result = &proto.BulkSimResult{
+			Results:            sweepResults,
+			EquippedGearResult: equipped,
+		}
+		if progress != nil {
+			progress <- &proto.ProgressMetrics{
+				FinalBulkResult: result,
+			}
+		}
+		return result, nil
+	}
+
+	if len(rankedResults) > maxResults {
+		rankedResults = rankedResults[:maxResults]
+	}
+
+	bum := baseResult.Result.GetRaidMetrics().GetParties()[0].GetPlayers()[0]
+	bum.Actions = nil
+	bum.Auras = nil
+	bum.Resources = nil
+	bum.Pets = nil
+
+	result = &proto.BulkSimResult{
+		EquippedGearResult: &proto.BulkComboResult{
+			UnitMetrics: bum,
+		},
+	}
+
+	for _, r := range rankedResults {
+		um := r.Result.GetRaidMetrics().GetParties()[0].GetPlayers()[0]
+		um.Actions = nil
+		um.Auras = nil
+		um.Resources = nil
+		um.Pets = nil
+
+		result.Results = append(result.Results, &proto.BulkComboResult{
+			ItemsAdded:  r.ChangeLog.AddedItems,
+			UnitMetrics: um,
+		})
+	}
+
+	if progress != nil {
+		progress <- &proto.ProgressMetrics{
+			FinalBulkResult: result,
+		}
+	}
+
+	return result, nil
+}
+
+// Combos of a bulk sim, and how many iterations each one gets.
+type bulkSimPlan struct {
+	combos     []singleBulkSim
+	iterations int32
+
+	sweep      *proto.ConsumableSweepSettings
+	petSweep   *proto.PetFamilySweepSettings
+	priceTable *consumablePriceTable
+}
+
+// Upper bound on the iterations the plan runs in total. Fast mode runs fewer,
+// since it starts with fewer iterations and drops combos as they increase.
+func (plan *bulkSimPlan) maxIterations() int64 {
+	return int64(plan.iterations) * int64(len(plan.combos))
+}
+
+// Cleans up the request and generates every combo to sim.
+func (b *bulkSimRunner) prepare(ctx context.Context) (*bulkSimPlan, error) {
+	// The request is trimmed and seeded below, which the caller shouldn't see.
+	b.Request = goproto.Clone(b.Request).(*proto.BulkSimRequest)
+
 	// Bulk simming is only supported for the single-player use (i.e. not whole raid-wide simming).
 	// Verify that we have exactly 1 player.
 	var playerCount int
@@ -96,6 +267,17 @@ func (b *bulkSimRunner) Run(pctx context.Context, progress chan *proto.ProgressM
 	// clean to reduce memory
 	player.Database = nil
 
+	if maxPhase := b.Request.BulkSettings.MaxPhase; maxPhase > 0 {
+		b.Request.BulkSettings.Items = filterItemsByPhase(b.Request.BulkSettings.Items, maxPhase)
+	}
+
+	if b.Request.BulkSettings.CommonRandomNumbers {
+		if b.Request.BaseSettings.SimOptions == nil {
+			b.Request.BaseSettings.SimOptions = &proto.SimOptions{}
+		}
+		useCommonRandomNumbers(b.Request.BaseSettings.SimOptions)
+	}
+
 	// Gemming for now can happen before slots are decided.
 	// We might have to add logic after slot decisions if we want to enforce keeping meta gem active.
 	if b.Request.BulkSettings.AutoGem {
@@ -148,6 +330,14 @@ func (b *bulkSimRunner) Run(pctx context.Context, progress chan *proto.ProgressM
 	}
 
 	items := b.Request.GetBulkSettings().GetItems()
+	sweep := b.Request.GetBulkSettings().GetConsumableSweep()
+	if sweep != nil && len(items) > 0 {
+		return nil, errors.New("bulksim: consumable sweeps can't also substitute items")
+	}
+	petSweep := b.Request.GetBulkSettings().GetPetFamilySweep()
+	if petSweep != nil && (sweep != nil || len(items) > 0) {
+		return nil, errors.New("bulksim: pet family sweeps can't also substitute items or consumables")
+	}
 	// numItems := len(items)
 	// if b.Request.BulkSettings.Combinations && numItems > maxItemCount {
 	// 	return nil, fmt.Errorf("too many items specified (%d > %d), not computationally feasible", numItems, maxItemCount)
@@ -188,107 +378,45 @@ func (b *bulkSimRunner) Run(pctx context.Context, progress chan *proto.ProgressM
 		}
 	}
 
-	// TODO(Riotdog-GehennasEU): Make this configurable?
-	maxResults := 30
-
-	var rankedResults []*itemSubstitutionSimResult
-	var baseResult *itemSubstitutionSimResult
-	newIters := int64(iterations)
-	if b.Request.BulkSettings.FastMode {
-		newIters /= 100
-
-		// In fast mode try to keep starting iterations between 50 and 1000.
-		if newIters < 50 {
-			newIters = 50
+	var priceTable *consumablePriceTable
+	if sweep != nil {
+		priceTable = newConsumablePriceTable(sweep)
+		consumeCombos, err := priceTable.combos(player.Consumes)
+		if err != nil {
+			return nil, err
 		}
-		if newIters > 1000 {
-			newIters = 1000
+		for _, consumes := range consumeCombos {
+			validCombos = append(validCombos, singleBulkSim{
+				req: createNewRequestWithConsumes(b.Request.BaseSettings, consumes),
+				cl:  &raidSimRequestChangeLog{},
+				eq:  &equipmentSubstitution{},
+				cs:  consumes,
+			})
 		}
 	}
 
-	maxIterations := newIters * int64(len(validCombos))
-	if maxIterations > math.MaxInt32 {
-		return nil, fmt.Errorf("number of total iterations %d too large", maxIterations)
-	}
-
-	for {
-		var tempBase *itemSubstitutionSimResult
-		var err error
-		// TODO: we could theoretically make getRankedResults accept a channel of validCombos that stream in to it and launches sims as it gets them...
-		rankedResults, tempBase, err = b.getRankedResults(ctx, validCombos, newIters, progress)
-
+	if petSweep != nil {
+		petCombos, err := petFamilyCombos(petSweep, player)
 		if err != nil {
 			return nil, err
 		}
-		// keep replacing the base result with more refined base until we don't have base in the ranked results anymore.
-		if tempBase != nil {
-			baseResult = tempBase
-		}
-
-		// If we aren't doing fast mode, or if halving our results will be less than the maxResults, be done.
-		if !b.Request.BulkSettings.FastMode || len(rankedResults) <= maxResults*2 {
-			break
-		}
-
-		// we have reached max accuracy now
-		if newIters >= int64(iterations) {
-			break
-		}
-
-		// Increase accuracy
-		newIters *= 2
-		newNumCombos := len(rankedResults) / 2
-		validCombos = validCombos[:newNumCombos]
-		rankedResults = rankedResults[:newNumCombos]
-		for i, comb := range rankedResults {
-			validCombos[i] = singleBulkSim{
-				req: comb.Request,
-				cl:  comb.ChangeLog,
-				eq:  comb.Substitution,
-			}
-		}
-	}
-
-	if baseResult == nil {
-		return nil, fmt.Errorf("no base result for equipped gear found in bulk sim")
-	}
-
-	if len(rankedResults) > maxResults {
-		rankedResults = rankedResults[:maxResults]
-	}
-
-	bum := baseResult.Result.GetRaidMetrics().GetParties()[0].GetPlayers()[0]
-	bum.Actions = nil
-	bum.Auras = nil
-	bum.Resources = nil
-	bum.Pets = nil
-
-	result = &proto.BulkSimResult{
-		EquippedGearResult: &proto.BulkComboResult{
-			UnitMetrics: bum,
-		},
-	}
-
-	for _, r := range rankedResults {
-		um := r.Result.GetRaidMetrics().GetParties()[0].GetPlayers()[0]
-		um.Actions = nil
-		um.Auras = nil
-		um.Resources = nil
-		um.Pets = nil
-
-		result.Results = append(result.Results, &proto.BulkComboResult{
-			ItemsAdded:  r.ChangeLog.AddedItems,
-			UnitMetrics: um,
-		})
-	}
-
-	if progress != nil {
-		progress <- &proto.ProgressMetrics{
-			FinalBulkResult: result,
+		for _, variant := range petCombos {
+			validCombos = append(validCombos, singleBulkSim{
+				req: createNewRequestWithPetFamily(b.Request.BaseSettings, variant),
+				cl:  &raidSimRequestChangeLog{},
+				eq:  &equipmentSubstitution{},
+				pf:  variant,
+			})
 		}
 	}
 
-	return result, nil
+	return &bulkSimPlan{
+		combos:     validCombos,
+		iterations: iterations,
+		sweep:      sweep,
+		petSweep:   petSweep,
+		priceTable: priceTable,
+	}, nil
 }
 
 func (b *bulkSimRunner) getRankedResults(pctx context.Context, validCombos []singleBulkSim, iterations int64, progress chan *proto.ProgressMetrics) ([]*itemSubstitutionSimResult, *itemSubstitutionSimResult, error) {
@@ -355,9 +483,11 @@ func (b *bulkSimRunner) getRankedResults(pctx context.Context, validCombos []sin
 				sub.req.SimOptions.Iterations = int32(iterations)
 				results <- &itemSubstitutionSimResult{
 					Request:      sub.req,
-					Result:       b.SingleRaidSimRunner(sub.req, singleSimProgress, false),
+					Result:       b.SingleRaidSimRunner(ctx, sub.req, singleSimProgress, false),
 					Substitution: sub.eq,
 					ChangeLog:    sub.cl,
+					Consumes:     sub.cs,
+					PetFamily:    sub.pf,
 				}
 				atomic.AddInt32(&totalCompletedSims, 1)
 				tickets <- struct{}{} // when done, allow for new sim to be launched.
@@ -374,7 +504,7 @@ func (b *bulkSimRunner) getRankedResults(pctx context.Context, validCombos []sin
 			cancel() // cancel reporter
 			return nil, nil, errors.New("simulation failed: " + result.Result.ErrorResult)
 		}
-		if !result.Substitution.HasItemReplacements() {
+		if !result.Substitution.HasItemReplacements() && result.Consumes == nil && result.PetFamily == nil {
 			baseResult = result
 		}
 		rankedResults[i] = result
@@ -395,6 +525,10 @@ type itemSubstitutionSimResult struct {
 	Result       *proto.RaidSimResult
 	Substitution *equipmentSubstitution
 	ChangeLog    *raidSimRequestChangeLog
+	// The swept consumes, or nil if this isn't a consumable sweep combo.
+	Consumes *proto.Consumes
+	// The swept pet, or nil if this isn't a pet family sweep combo.
+	PetFamily *proto.PetFamilyVariant
 }
 
 // Score used to rank results.
@@ -456,6 +590,14 @@ func (es *equipmentSubstitution) CanonicalHash() string {
 
 // isValidEquipment returns true if the specified equipment spec is valid. An equipment spec
 // is valid if it does not reference a two-hander and off-hand weapon combo.
+// Items missing from the database can't be filtered, so they're kept.
+func filterItemsByPhase(items []*proto.ItemSpec, maxPhase int32) []*proto.ItemSpec {
+	return FilterSlice(items, func(spec *proto.ItemSpec) bool {
+		item, ok := ItemsByID[spec.Id]
+		return !ok || item.Phase <= maxPhase
+	})
+}
+
 func isValidEquipment(equipment *proto.EquipmentSpec) bool {
 	var usesTwoHander, usesOffhand bool
 
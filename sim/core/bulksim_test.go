@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -73,6 +74,22 @@ func TestIsValidEquipment(t *testing.T) {
 	}
 }
 
+func TestFilterItemsByPhase(t *testing.T) {
+	addToDatabase(&proto.SimDatabase{
+		Items: []*proto.SimItem{
+			{Id: 990001, Phase: 1},
+			{Id: 990002, Phase: 2},
+			{Id: 990003, Phase: 3},
+		},
+	})
+
+	items := []*proto.ItemSpec{{Id: 990001}, {Id: 990002}, {Id: 990003}, {Id: 990004}}
+	got := MapSlice(filterItemsByPhase(items, 2), func(spec *proto.ItemSpec) int32 { return spec.Id })
+	if diff := cmp.Diff([]int32{990001, 990002, 990004}, got); diff != "" {
+		t.Fatalf("filterItemsByPhase() returned diff (-want +got):\n%s", diff)
+	}
+}
+
 func createEquipmentFromItems(items ...*itemWithSlot) *proto.EquipmentSpec {
 	spec := &proto.EquipmentSpec{
 		Items: make([]*proto.ItemSpec, len(proto.ItemSlot_name)),
@@ -91,7 +108,7 @@ func createEquipmentFromItems(items ...*itemWithSlot) *proto.EquipmentSpec {
 func TestBulkSim(t *testing.T) {
 	t.Skip("TODO: Implement")
 
-	fakeRunSim := func(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool) *proto.RaidSimResult {
+	fakeRunSim := func(ctx context.Context, rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool) *proto.RaidSimResult {
 		return &proto.RaidSimResult{}
 	}
 
@@ -380,3 +397,75 @@ func TestGenerateAllEquipmentSubstitutions(t *testing.T) {
 		})
 	}
 }
+
+func TestBulkSimCommonRandomNumbers(t *testing.T) {
+	var mu sync.Mutex
+	var simOptions []*proto.SimOptions
+	fakeRunSim := func(ctx context.Context, rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool) *proto.RaidSimResult {
+		mu.Lock()
+		simOptions = append(simOptions, rsr.SimOptions)
+		mu.Unlock()
+		result := &proto.RaidSimResult{RaidMetrics: &proto.RaidMetrics{Dps: &proto.DistributionMetrics{}}}
+		if progress != nil {
+			progress <- &proto.ProgressMetrics{FinalRaidResult: result}
+			close(progress)
+		}
+		return result
+	}
+
+	request := &proto.BulkSimRequest{
+		BaseSettings: &proto.RaidSimRequest{
+			Raid: &proto.Raid{Parties: []*proto.Party{{Players: []*proto.Player{{
+				Name:      "Player",
+				Equipment: createEquipmentFromItems(),
+			}}}}},
+			SimOptions: &proto.SimOptions{Iterations: 10},
+		},
+		BulkSettings: &proto.BulkSettings{CommonRandomNumbers: true},
+	}
+	bulk := &bulkSimRunner{
+		SingleRaidSimRunner: fakeRunSim,
+		Request:             request,
+	}
+	if _, err := bulk.Run(context.Background(), nil); err != nil {
+		t.Fatalf("BulkSim() returned error: %v", err)
+	}
+
+	if options := request.BaseSettings.SimOptions; options.RandomSeed != 0 || options.LabeledRngStreams {
+		t.Errorf("Expected the caller's request to be unchanged, got %v", options)
+	}
+	if len(simOptions) == 0 {
+		t.Fatalf("Expected at least one sim")
+	}
+	for _, options := range simOptions {
+		if options.RandomSeed == 0 || options.RandomSeed != simOptions[0].RandomSeed || !options.LabeledRngStreams {
+			t.Errorf("Expected every sim to use common random numbers, got %v", options)
+		}
+	}
+}
+
+func TestBulkSimIterations(t *testing.T) {
+	request := petFamilySweepTestRequest(&proto.BulkSettings{
+		IterationsPerCombo: 500,
+		PetFamilySweep: &proto.PetFamilySweepSettings{
+			Variants: []*proto.PetFamilyVariant{
+				{PetType: proto.Hunter_Options_Raptor},
+				{PetType: proto.Hunter_Options_Cat},
+			},
+		},
+	})
+
+	// The equipped pet, plus each variant.
+	iterations, err := BulkSimIterations(request)
+	if err != nil {
+		t.Fatalf("BulkSimIterations() returned error: %v", err)
+	}
+	if iterations != 1500 {
+		t.Errorf("Expected 1500 iterations, got %d", iterations)
+	}
+
+	request.BulkSettings.Items = []*proto.ItemSpec{{Id: 1}}
+	if _, err := BulkSimIterations(request); err == nil {
+		t.Errorf("Expected an error for an invalid bulk sim")
+	}
+}
@@ -9,14 +9,24 @@ import (
 // shown, and activates the GCD. Note that a cast can also be instant, i.e.
 // the effects are applied immediately even though the GCD is still activated.
 
+// Callback for when a cast starts, i.e. when the in-game castbar appears. For
+// instant casts, this is immediately followed by OnCastComplete.
+type OnCastStart func(aura *Aura, sim *Simulation, spell *Spell)
+
 // Callback for when a cast is finished, i.e. when the in-game castbar reaches full.
 type OnCastComplete func(aura *Aura, sim *Simulation, spell *Spell)
 
+// Callback for when a hardcast or channel is stopped before it finishes, e.g.
+// by an APL interrupt or by the caster dying.
+type OnCastInterrupted func(aura *Aura, sim *Simulation, spell *Spell)
+
 type Hardcast struct {
 	Expires    time.Duration
 	ActionID   ActionID
 	OnComplete func(*Simulation, *Unit)
 	Target     *Unit
+
+	spell *Spell // Only set for spell hardcasts, i.e. not channels.
 }
 
 // Input for constructing the CastSpell function for a spell.
@@ -70,7 +80,7 @@ func (spell *Spell) castFailureHelper(sim *Simulation, gracefulFailure bool, mes
 		spell.Unit.Rotation.ValidationWarning(fmt.Sprintf(spell.ActionID.String()+" failed to cast: "+message, vals...))
 	} else if gracefulFailure {
 		if sim.Log != nil && !spell.Flags.Matches(SpellFlagNoLogs) {
-			spell.Unit.Log(sim, fmt.Sprintf(spell.ActionID.String()+" failed to cast: "+message, vals...))
+			sim.logEvent(LogEvent{Type: LogEventCastFailed, Actor: spell.Unit, ActionID: spell.ActionID, format: message, args: vals})
 		}
 	} else {
 		panic(fmt.Sprintf(spell.ActionID.String()+" failed to cast: "+message, vals...))
@@ -104,8 +114,14 @@ func (spell *Spell) makeCastFunc(config CastConfig) CastSuccessFunc {
 			}
 		}
 
+		if spell.GCDCategory == GCDCategoryOffGCD && spell.CurCast.GCD != 0 {
+			return spell.castFailureHelper(sim, false, "off-GCD spell modified to have a GCD of %s", spell.CurCast.GCD)
+		}
+
 		if !config.IgnoreHaste {
-			spell.CurCast.GCD = spell.Unit.ApplyCastSpeed(spell.CurCast.GCD)
+			if spell.GCDCategory != GCDCategoryFixed {
+				spell.CurCast.GCD = spell.Unit.ApplyCastSpeed(spell.CurCast.GCD)
+			}
 			spell.CurCast.CastTime = spell.Unit.ApplyCastSpeedForSpell(spell.CurCast.CastTime, spell)
 			spell.CurCast.ChannelTime = spell.Unit.ApplyCastSpeedForSpell(spell.CurCast.ChannelTime, spell)
 		}
@@ -115,7 +131,7 @@ func (spell *Spell) makeCastFunc(config CastConfig) CastSuccessFunc {
 			if !spell.CD.IsReady(sim) {
 				return spell.castFailureHelper(sim, false, "still on cooldown for %s, curTime = %s", spell.CD.TimeToReady(sim), sim.CurrentTime)
 			}
-			spell.CD.Set(sim.CurrentTime + spell.CurCast.CastTime + spell.CD.Duration)
+			spell.CD.useAt(sim.CurrentTime+spell.CurCast.CastTime, spell.CD.castDuration(spell.Unit))
 		}
 
 		if config.SharedCD.Timer != nil {
@@ -123,7 +139,7 @@ func (spell *Spell) makeCastFunc(config CastConfig) CastSuccessFunc {
 			if !spell.SharedCD.IsReady(sim) {
 				return spell.castFailureHelper(sim, false, "still on shared cooldown for %s, curTime = %s", spell.SharedCD.TimeToReady(sim), sim.CurrentTime)
 			}
-			spell.SharedCD.Set(sim.CurrentTime + spell.CurCast.CastTime + spell.SharedCD.Duration)
+			spell.SharedCD.useAt(sim.CurrentTime+spell.CurCast.CastTime, spell.SharedCD.castDuration(spell.Unit))
 		}
 
 		// By panicking if spell is on CD, we force each sim to properly check for their own CDs.
@@ -135,16 +151,30 @@ func (spell *Spell) makeCastFunc(config CastConfig) CastSuccessFunc {
 			return spell.castFailureHelper(sim, false, "casting/channeling %v for %s, curTime = %s", hc.ActionID, hc.Expires-sim.CurrentTime, sim.CurrentTime)
 		}
 
+		if tl := spell.Unit.Metrics.timeline; tl != nil {
+			tl.recordCast(sim, spell)
+		}
+
+		if !spell.Flags.Matches(SpellFlagNoOnCastComplete) {
+			spell.Unit.OnCastStart(sim, spell)
+		}
+
 		if effectiveTime := spell.CurCast.EffectiveTime(); effectiveTime != 0 {
 			spell.SpellMetrics[target.UnitIndex].TotalCastTime += effectiveTime
 			spell.Unit.SetGCDTimer(sim, sim.CurrentTime+effectiveTime)
+			if ce := spell.Unit.Metrics.castEfficiency; ce != nil {
+				ce.addBusyTime(sim, effectiveTime)
+			}
+		}
+
+		if spell.Flags.Matches(SpellFlagResetsSwingTimer) {
+			spell.Unit.AutoAttacks.StopMeleeUntil(sim, sim.CurrentTime+spell.CurCast.CastTime, false)
 		}
 
 		// Hardcasts
 		if spell.CurCast.CastTime > 0 {
 			if sim.Log != nil && !spell.Flags.Matches(SpellFlagNoLogs) {
-				spell.Unit.Log(sim, "Casting %s (Cost = %0.03f, Cast Time = %s, Effective Time = %s)",
-					spell.ActionID, max(0, spell.CurCast.Cost), spell.CurCast.CastTime, spell.CurCast.EffectiveTime())
+				spell.logCast(sim, max(0, spell.CurCast.Cost), spell.CurCast.CastTime, spell.CurCast.EffectiveTime())
 			}
 
 			spell.Unit.Hardcast = Hardcast{
@@ -152,7 +182,7 @@ func (spell *Spell) makeCastFunc(config CastConfig) CastSuccessFunc {
 				ActionID: spell.ActionID,
 				OnComplete: func(sim *Simulation, target *Unit) {
 					if sim.Log != nil && !spell.Flags.Matches(SpellFlagNoLogs) {
-						spell.Unit.Log(sim, "Completed cast %s", spell.ActionID)
+						spell.logCastComplete(sim)
 					}
 
 					if spell.Cost != nil {
@@ -166,6 +196,8 @@ func (spell *Spell) makeCastFunc(config CastConfig) CastSuccessFunc {
 					}
 				},
 				Target: target,
+
+				spell: spell,
 			}
 
 			if spell.Unit.Hardcast.Expires != spell.Unit.NextGCDAt() {
@@ -181,9 +213,8 @@ func (spell *Spell) makeCastFunc(config CastConfig) CastSuccessFunc {
 		}
 
 		if sim.Log != nil && !spell.Flags.Matches(SpellFlagNoLogs) {
-			spell.Unit.Log(sim, "Casting %s (Cost = %0.03f, Cast Time = %s, Effective Time = %s)",
-				spell.ActionID, max(0, spell.CurCast.Cost), spell.CurCast.CastTime, spell.CurCast.EffectiveTime())
-			spell.Unit.Log(sim, "Completed cast %s", spell.ActionID)
+			spell.logCast(sim, max(0, spell.CurCast.Cost), spell.CurCast.CastTime, spell.CurCast.EffectiveTime())
+			spell.logCastComplete(sim)
 		}
 
 		if spell.Cost != nil {
@@ -214,7 +245,7 @@ func (spell *Spell) makeCastFuncSimple() CastSuccessFunc {
 				return spell.castFailureHelper(sim, false, "still on cooldown for %s, curTime = %s", spell.CD.TimeToReady(sim), sim.CurrentTime)
 			}
 
-			spell.CD.Set(sim.CurrentTime + spell.CD.Duration)
+			spell.CD.Use(sim)
 		}
 
 		if spell.SharedCD.Timer != nil {
@@ -223,13 +254,22 @@ func (spell *Spell) makeCastFuncSimple() CastSuccessFunc {
 				return spell.castFailureHelper(sim, false, "still on shared cooldown for %s, curTime = %s", spell.SharedCD.TimeToReady(sim), sim.CurrentTime)
 			}
 
-			spell.SharedCD.Set(sim.CurrentTime + spell.SharedCD.Duration)
+			spell.SharedCD.Use(sim)
+		}
+
+		// Also records off-GCD instants like racials and trinkets, which most
+		// cooldown plans are made of.
+		if tl := spell.Unit.Metrics.timeline; tl != nil {
+			tl.recordCast(sim, spell)
+		}
+
+		if !spell.Flags.Matches(SpellFlagNoOnCastComplete) {
+			spell.Unit.OnCastStart(sim, spell)
 		}
 
 		if sim.Log != nil && !spell.Flags.Matches(SpellFlagNoLogs) {
-			spell.Unit.Log(sim, "Casting %s (Cost = %0.03f, Cast Time = %s, Effective Time = %s)",
-				spell.ActionID, 0.0, "0s", "0s")
-			spell.Unit.Log(sim, "Completed cast %s", spell.ActionID)
+			spell.logCast(sim, 0, 0, 0)
+			spell.logCastComplete(sim)
 		}
 
 		spell.applyEffects(sim, target)
@@ -244,10 +284,13 @@ func (spell *Spell) makeCastFuncSimple() CastSuccessFunc {
 
 func (spell *Spell) makeCastFuncAutosOrProcs() CastSuccessFunc {
 	return func(sim *Simulation, target *Unit) bool {
+		if !spell.Flags.Matches(SpellFlagNoOnCastComplete) {
+			spell.Unit.OnCastStart(sim, spell)
+		}
+
 		if sim.Log != nil && !spell.Flags.Matches(SpellFlagNoLogs) {
-			spell.Unit.Log(sim, "Casting %s (Cost = %0.03f, Cast Time = %s, Effective Time = %s)",
-				spell.ActionID, 0.0, "0s", "0s")
-			spell.Unit.Log(sim, "Completed cast %s", spell.ActionID)
+			spell.logCast(sim, 0, 0, 0)
+			spell.logCastComplete(sim)
 		}
 
 		spell.applyEffects(sim, target)
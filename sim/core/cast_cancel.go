@@ -0,0 +1,82 @@
+package core
+
+// Deliberately stops the unit's current hardcast or channel, e.g. to clip a
+// channel and re-prioritize. Unlike being interrupted, the unit chose when to
+// stop, so:
+//   - A cancelled hardcast hasn't spent its cost yet, and its cooldowns are
+//     refunded since it never completed.
+//   - A cancelled channel already paid its cost, which isn't refunded, and its
+//     remaining ticks are lost.
+//   - Either way, the GCD which the cast triggered still has to finish.
+//
+// Returns whether there was anything to cancel.
+func (unit *Unit) CancelCast(sim *Simulation) bool {
+	if hc := unit.Hardcast; hc.spell != nil && hc.Expires > sim.CurrentTime {
+		spell := hc.spell
+		if unit.hardcastAction != nil {
+			unit.hardcastAction.Cancel(sim)
+		}
+		unit.Hardcast = Hardcast{Expires: startingCDTime}
+
+		castStart := hc.Expires - spell.CurCast.CastTime
+		for _, cd := range []*Cooldown{&spell.CD, &spell.SharedCD} {
+			if cd.Timer != nil {
+				cd.Set(min(cd.ReadyAt()-cd.castDuration(unit), sim.CurrentTime))
+			}
+		}
+
+		// The GCD was extended to the end of the cast, so give back the rest.
+		gcdReadyAt := sim.CurrentTime
+		if spell.CurCast.GCD != 0 {
+			gcdReadyAt = max(gcdReadyAt, castStart+max(GCDMin, spell.CurCast.GCD))
+		}
+		if unit.GCD.ReadyAt() > gcdReadyAt {
+			spell.SpellMetrics[hc.Target.UnitIndex].TotalCastTime -= unit.GCD.ReadyAt() - gcdReadyAt
+			unit.SetGCDTimer(sim, gcdReadyAt)
+		}
+
+		if sim.Log != nil && !spell.Flags.Matches(SpellFlagNoLogs) {
+			spell.logCastCancelled(sim)
+		}
+		unit.OnCastInterrupted(sim, spell)
+		return true
+	}
+
+	if channeledDot := unit.ChanneledDot; channeledDot != nil {
+		channeledDot.Cancel(sim)
+		if unit.Hardcast.Expires > sim.CurrentTime {
+			// Channels without an APL also block the unit with a hardcast.
+			unit.Hardcast = Hardcast{Expires: startingCDTime}
+		}
+		if sim.Log != nil && !channeledDot.Spell.Flags.Matches(SpellFlagNoLogs) {
+			channeledDot.Spell.logCastCancelled(sim)
+		}
+		unit.OnCastInterrupted(sim, channeledDot.Spell)
+		return true
+	}
+
+	return false
+}
+
+// Returns the spell the unit is hardcasting or channeling, if any.
+func (unit *Unit) CurrentCast(sim *Simulation) *Spell {
+	if unit.ChanneledDot != nil {
+		return unit.ChanneledDot.Spell
+	}
+	if unit.Hardcast.Expires > sim.CurrentTime {
+		return unit.Hardcast.spell
+	}
+	return nil
+}
+
+// Deliberately removes one of the unit's own auras, e.g. a buff which has
+// become harmful. Unlike the aura fading, this is logged as a cancel.
+func (aura *Aura) Cancel(sim *Simulation) {
+	if !aura.IsActive() {
+		return
+	}
+	if sim.Log != nil && !aura.ActionID.IsEmptyAction() {
+		aura.logEvent(sim, LogEventAuraCancelled)
+	}
+	aura.Deactivate(sim)
+}
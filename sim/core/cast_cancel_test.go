@@ -0,0 +1,91 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCancelCast(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	target := sim.GetTargetUnit(0)
+
+	completed := false
+	spell := fa.RegisterSpell(SpellConfig{
+		ActionID: ActionID{SpellID: 1},
+		Cast: CastConfig{
+			DefaultCast: Cast{
+				GCD:      GCDDefault,
+				CastTime: time.Second * 3,
+			},
+			CD: Cooldown{
+				Timer:    fa.NewTimer(),
+				Duration: time.Second * 30,
+			},
+		},
+		ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {
+			completed = true
+		},
+	})
+
+	spell.Cast(sim, target)
+	if fa.CurrentCast(sim) != spell || fa.GCD.ReadyAt() != time.Second*3 {
+		t.Fatalf("Expected to be casting until 3s")
+	}
+
+	// Cancelling within the GCD still waits for the GCD.
+	sim.CurrentTime = time.Second
+	if !fa.CancelCast(sim) {
+		t.Fatalf("Expected the cast to be cancelled")
+	}
+	if fa.CurrentCast(sim) != nil || fa.GCD.ReadyAt() != GCDDefault {
+		t.Fatalf("Expected no cast and the GCD to end at 1.5s, got %s", fa.GCD.ReadyAt())
+	}
+	if !spell.CD.IsReady(sim) {
+		t.Fatalf("Expected the cancelled cast's cooldown to be refunded")
+	}
+	if fa.CancelCast(sim) {
+		t.Fatalf("Expected nothing left to cancel")
+	}
+
+	// Cancelling after the GCD frees the unit immediately.
+	sim.CurrentTime = GCDDefault
+	spell.Cast(sim, target)
+	sim.CurrentTime += time.Second * 2
+	fa.CancelCast(sim)
+	if fa.GCD.ReadyAt() != sim.CurrentTime {
+		t.Fatalf("Expected the GCD to be ready at %s, got %s", sim.CurrentTime, fa.GCD.ReadyAt())
+	}
+	if completed {
+		t.Fatalf("Expected cancelled casts not to apply their effects")
+	}
+	if castTime := spell.SpellMetrics[target.UnitIndex].TotalCastTime; castTime != GCDDefault+time.Second*2 {
+		t.Fatalf("Expected 3.5s of cast time, got %s", castTime)
+	}
+}
+
+func TestCancelAura(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+
+	aura := fa.RegisterAura(Aura{
+		Label:    "Slow Fall",
+		ActionID: ActionID{SpellID: 130},
+		Duration: time.Second * 30,
+	})
+	aura.Activate(sim)
+
+	action := &APLActionCancelAura{aura: aura}
+	if !action.IsReady(sim) {
+		t.Fatalf("Expected an active aura to be cancellable")
+	}
+	action.Execute(sim)
+	if aura.IsActive() || action.IsReady(sim) {
+		t.Fatalf("Expected the aura to be cancelled")
+	}
+
+	event := LogEvent{Type: LogEventAuraCancelled, Actor: &fa.Unit, ActionID: aura.ActionID}
+	if line := event.Line(); line != "[Caster (#1)] Aura cancelled: {SpellID: 130}" {
+		t.Errorf("Unexpected log line: %s", line)
+	}
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+const notReady = time.Duration(-1)
+
+// Tracks how tightly a unit's rotation used its GCDs and cooldowns. Only
+// spells usable from an APL count, so procs and auto attacks don't inflate
+// the casts per minute.
+//
+// Cooldowns are observed whenever the unit casts one of these spells, so a
+// cooldown reset by a proc is only seen at the next cast. That's also the
+// first chance the rotation had to react to it.
+type castEfficiencyMetrics struct {
+	cooldownSpells []*Spell
+
+	// Values for the current iteration.
+	casts           int32
+	busyTime        time.Duration
+	busyUntil       time.Duration
+	lastObservation time.Duration
+	readySince      []time.Duration // When each cooldown spell was first seen ready, or notReady.
+	cooldownCasts   []int32
+	wastedTime      []time.Duration
+
+	// Aggregate values. These are updated after each iteration.
+	iterations        int32
+	castsPerMinuteSum float64
+	gcdUptimeSum      float64
+	idleSecondsSum    float64
+	cooldownCastsSum  []int32
+	wastedSecondsSum  []float64
+}
+
+func newCastEfficiencyMetrics(spellbook []*Spell) *castEfficiencyMetrics {
+	ce := &castEfficiencyMetrics{}
+	for _, spell := range spellbook {
+		if spell.Flags.Matches(SpellFlagAPL) && spell.CD.Timer != nil && spell.CD.Duration > 0 {
+			ce.cooldownSpells = append(ce.cooldownSpells, spell)
+		}
+	}
+	ce.readySince = make([]time.Duration, len(ce.cooldownSpells))
+	ce.cooldownCasts = make([]int32, len(ce.cooldownSpells))
+	ce.wastedTime = make([]time.Duration, len(ce.cooldownSpells))
+	ce.cooldownCastsSum = make([]int32, len(ce.cooldownSpells))
+	ce.wastedSecondsSum = make([]float64, len(ce.cooldownSpells))
+	return ce
+}
+
+func (ce *castEfficiencyMetrics) reset() {
+	ce.casts = 0
+	ce.busyTime = 0
+	ce.busyUntil = 0
+	ce.lastObservation = 0
+	for i := range ce.cooldownSpells {
+		ce.readySince[i] = notReady
+		ce.cooldownCasts[i] = 0
+		ce.wastedTime[i] = 0
+	}
+}
+
+// Adds time spent on the GCD or casting. Casts can't overlap, but the prepull
+// and anything past the end of the encounter don't count.
+func (ce *castEfficiencyMetrics) addBusyTime(sim *Simulation, effectiveTime time.Duration) {
+	start := max(sim.CurrentTime, ce.busyUntil, 0)
+	ce.busyUntil = max(ce.busyUntil, sim.CurrentTime+effectiveTime)
+	ce.busyTime += max(0, ce.busyUntil-start)
+}
+
+// Should be called after a successful cast of a spell usable from an APL,
+// with the time the spell was ready at before the cast.
+func (ce *castEfficiencyMetrics) onCast(sim *Simulation, spell *Spell, readyAt time.Duration) {
+	ce.casts++
+
+	now := max(sim.CurrentTime, 0)
+	for i, cdSpell := range ce.cooldownSpells {
+		if cdSpell == spell {
+			ce.cooldownCasts[i]++
+			if ce.readySince[i] == notReady {
+				ce.readySince[i] = max(readyAt, ce.lastObservation)
+			}
+		}
+	}
+	ce.observe(now)
+}
+
+// Updates which cooldown spells are ready, adding the wasted time of those
+// which went on cooldown since the last observation.
+func (ce *castEfficiencyMetrics) observe(now time.Duration) {
+	for i, spell := range ce.cooldownSpells {
+		if readyAt := spell.ReadyAt(); readyAt <= now {
+			if ce.readySince[i] == notReady {
+				ce.readySince[i] = max(readyAt, ce.lastObservation)
+			}
+		} else if ce.readySince[i] != notReady {
+			ce.wastedTime[i] += max(0, now-ce.readySince[i])
+			ce.readySince[i] = notReady
+		}
+	}
+	ce.lastObservation = now
+}
+
+// This should be called when a Sim iteration is complete.
+func (ce *castEfficiencyMetrics) doneIteration(sim *Simulation) {
+	end := max(sim.CurrentTime, 0)
+	ce.observe(end)
+	for i := range ce.cooldownSpells {
+		if ce.readySince[i] != notReady {
+			ce.wastedTime[i] += end - ce.readySince[i]
+		}
+		ce.cooldownCastsSum[i] += ce.cooldownCasts[i]
+		ce.wastedSecondsSum[i] += ce.wastedTime[i].Seconds()
+	}
+
+	busyTime := ce.busyTime - max(0, ce.busyUntil-end)
+	ce.iterations++
+	if end > 0 {
+		ce.castsPerMinuteSum += float64(ce.casts) / end.Minutes()
+		ce.gcdUptimeSum += 100 * busyTime.Seconds() / end.Seconds()
+	}
+	ce.idleSecondsSum += (end - busyTime).Seconds()
+}
+
+// Adds the aggregate values of other, for sims which are run in shards.
+func (ce *castEfficiencyMetrics) merge(other *castEfficiencyMetrics) {
+	ce.iterations += other.iterations
+	ce.castsPerMinuteSum += other.castsPerMinuteSum
+	ce.gcdUptimeSum += other.gcdUptimeSum
+	ce.idleSecondsSum += other.idleSecondsSum
+	for i := range ce.cooldownSpells {
+		ce.cooldownCastsSum[i] += other.cooldownCastsSum[i]
+		ce.wastedSecondsSum[i] += other.wastedSecondsSum[i]
+	}
+}
+
+func (ce *castEfficiencyMetrics) ToProto() *proto.CastEfficiencyMetrics {
+	n := float64(ce.iterations)
+	protoMetrics := &proto.CastEfficiencyMetrics{
+		CastsPerMinute:   ce.castsPerMinuteSum / n,
+		GcdUptimePercent: ce.gcdUptimeSum / n,
+		IdleSecondsAvg:   ce.idleSecondsSum / n,
+	}
+	for i, spell := range ce.cooldownSpells {
+		if ce.cooldownCastsSum[i] > 0 {
+			protoMetrics.Cooldowns = append(protoMetrics.Cooldowns, &proto.CooldownUsageMetrics{
+				Id:               spell.ActionID.ToProto(),
+				CastsAvg:         float64(ce.cooldownCastsSum[i]) / n,
+				WastedSecondsAvg: ce.wastedSecondsSum[i] / n,
+			})
+		}
+	}
+	return protoMetrics
+}
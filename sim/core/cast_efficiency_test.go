@@ -0,0 +1,64 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCastEfficiency(t *testing.T) {
+	sim := &Simulation{}
+	spell := &Spell{
+		ActionID: ActionID{SpellID: 42},
+		Flags:    SpellFlagAPL,
+		CD:       Cooldown{Timer: new(Timer), Duration: time.Second * 10},
+	}
+	unused := &Spell{
+		ActionID: ActionID{SpellID: 43},
+		Flags:    SpellFlagAPL,
+		CD:       Cooldown{Timer: new(Timer), Duration: time.Second * 10},
+	}
+	ce := newCastEfficiencyMetrics([]*Spell{spell, unused, {ActionID: ActionID{SpellID: 44}}})
+	if len(ce.cooldownSpells) != 2 {
+		t.Fatalf("Expected 2 cooldown spells, got %d", len(ce.cooldownSpells))
+	}
+
+	cast := func(at time.Duration, gcd time.Duration) {
+		sim.CurrentTime = at
+		readyAt := spell.ReadyAt()
+		spell.CD.Use(sim)
+		ce.addBusyTime(sim, gcd)
+		ce.onCast(sim, spell, readyAt)
+	}
+
+	for i := 0; i < 2; i++ {
+		spell.CD.Reset()
+		unused.CD.Reset()
+		ce.reset()
+
+		cast(-time.Second, time.Millisecond*1500) // Only 0.5s of the prepull GCD counts.
+		cast(time.Second*12, time.Millisecond*1500)
+		cast(time.Millisecond*29500, time.Millisecond*1500) // Only 0.5s before the end counts.
+		sim.CurrentTime = time.Second * 30
+		ce.doneIteration(sim)
+	}
+
+	metrics := ce.ToProto()
+	if !WithinToleranceFloat64(6, metrics.CastsPerMinute, 1e-9) {
+		t.Errorf("Expected 6 casts per minute, got %f", metrics.CastsPerMinute)
+	}
+	if !WithinToleranceFloat64(2.5/30*100, metrics.GcdUptimePercent, 1e-9) {
+		t.Errorf("Expected %f%% GCD uptime, got %f", 2.5/30*100, metrics.GcdUptimePercent)
+	}
+	if !WithinToleranceFloat64(27.5, metrics.IdleSecondsAvg, 1e-9) {
+		t.Errorf("Expected 27.5 idle seconds, got %f", metrics.IdleSecondsAvg)
+	}
+
+	// Ready from 9s to 12s and from 22s to 29.5s. The unused spell isn't
+	// reported, since it was never cast.
+	if len(metrics.Cooldowns) != 1 {
+		t.Fatalf("Expected 1 cooldown, got %d", len(metrics.Cooldowns))
+	}
+	if cd := metrics.Cooldowns[0]; cd.CastsAvg != 3 || !WithinToleranceFloat64(10.5, cd.WastedSecondsAvg, 1e-9) {
+		t.Errorf("Expected 3 casts and 10.5 wasted seconds, got %f and %f", cd.CastsAvg, cd.WastedSecondsAvg)
+	}
+}
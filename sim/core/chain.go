@@ -0,0 +1,182 @@
+package core
+
+// How chain spells pick the next target to bounce to. Targets are never hit
+// twice by the same cast.
+type ChainTargetRule byte
+
+const (
+	// Bounces to the next active enemy, in encounter order, e.g. Chain
+	// Lightning.
+	ChainTargetNextEnemy ChainTargetRule = iota
+
+	// Bounces to the enabled raid member or pet with the lowest health
+	// percentage, e.g. Chain Heal. Units without a health bar count as full.
+	ChainTargetMostInjuredAlly
+)
+
+type ChainConfig struct {
+	// Maximum number of targets hit by each cast, including the first one.
+	// Spells without NumHits aren't chain spells.
+	NumHits int32
+
+	// Multiplier for the damage or healing of each bounce, compounded over
+	// bounces, e.g. 0.7 for Chain Lightning.
+	BounceMultiplier float64
+
+	TargetRule ChainTargetRule
+}
+
+type spellChain struct {
+	ChainConfig
+
+	// Reused by each cast.
+	targets []*Unit
+	results []*SpellResult
+
+	// Totals for the current iteration, indexed by bounce.
+	bounces []ChainBounceMetrics
+}
+
+// Metric totals for one bounce of a chain spell, with the first target at
+// bounce index 0.
+type ChainBounceMetrics struct {
+	Hits    int32 // Number of times the spell bounced this far.
+	Damage  float64
+	Healing float64
+}
+
+func (cbm *ChainBounceMetrics) merge(other *ChainBounceMetrics) {
+	cbm.Hits += other.Hits
+	cbm.Damage += other.Damage
+	cbm.Healing += other.Healing
+}
+
+func (spell *Spell) createChain(config ChainConfig) {
+	if config.NumHits == 0 {
+		return
+	}
+	if config.BounceMultiplier == 0 {
+		config.BounceMultiplier = 1
+	}
+
+	spell.chain = &spellChain{
+		ChainConfig: config,
+		targets:     make([]*Unit, 0, config.NumHits),
+		results:     make([]*SpellResult, 0, config.NumHits),
+		bounces:     make([]ChainBounceMetrics, config.NumHits),
+	}
+}
+
+// Returns the targets hit by a cast of this chain spell at target, in order.
+// The returned slice is reused by the next cast.
+func (spell *Spell) ChainTargets(sim *Simulation, target *Unit) []*Unit {
+	chain := spell.chain
+	chain.targets = append(chain.targets[:0], target)
+
+	switch chain.TargetRule {
+	case ChainTargetNextEnemy:
+		targetUnits := sim.Encounter.TargetUnits
+		// Starts from the first target, if it's still active.
+		start := -1
+		for i, unit := range targetUnits {
+			if unit == target {
+				start = i
+				break
+			}
+		}
+		for i := 1; i <= len(targetUnits) && len(chain.targets) < int(chain.NumHits); i++ {
+			if unit := targetUnits[(start+i)%len(targetUnits)]; unit != target {
+				chain.targets = append(chain.targets, unit)
+			}
+		}
+	case ChainTargetMostInjuredAlly:
+		for len(chain.targets) < int(chain.NumHits) {
+			var next *Unit
+			nextHealth := 0.0
+			for _, unit := range sim.Raid.AllUnits {
+				if !unit.IsEnabled() || chainTargetsContain(chain.targets, unit) {
+					continue
+				}
+				health := 1.0
+				if unit.HasHealthBar() {
+					health = unit.CurrentHealthPercent()
+				}
+				if next == nil || health < nextHealth {
+					next, nextHealth = unit, health
+				}
+			}
+			if next == nil {
+				break
+			}
+			chain.targets = append(chain.targets, next)
+		}
+	}
+
+	return chain.targets
+}
+
+func chainTargetsContain(targets []*Unit, unit *Unit) bool {
+	for _, target := range targets {
+		if target == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// Calls effectFn for each target hit by a cast of this chain spell at target,
+// in order, with the multiplier for that bounce. effectFn is expected to
+// calculate and deal its own result, e.g. for spells with effects in between
+// each bounce. Use DealBounceDamage() or DealBounceHealing() so that the
+// bounce metrics are recorded.
+func (spell *Spell) ApplyChain(sim *Simulation, target *Unit, effectFn func(sim *Simulation, target *Unit, bounceIndex int32, bounceMultiplier float64)) {
+	bounceMultiplier := 1.0
+	for bounceIndex, bounceTarget := range spell.ChainTargets(sim, target) {
+		effectFn(sim, bounceTarget, int32(bounceIndex), bounceMultiplier)
+		bounceMultiplier *= spell.chain.BounceMultiplier
+	}
+}
+
+// Calculates the results of a cast of this chain spell at target, without
+// dealing them, like the results of other AoE spells. The returned slice is
+// indexed by bounce, and is reused by the next cast.
+func (spell *Spell) CalcChain(sim *Simulation, target *Unit, calcFn func(sim *Simulation, target *Unit, bounceIndex int32, bounceMultiplier float64) *SpellResult) []*SpellResult {
+	chain := spell.chain
+	chain.results = chain.results[:0]
+	bounceMultiplier := 1.0
+	for bounceIndex, bounceTarget := range spell.ChainTargets(sim, target) {
+		chain.results = append(chain.results, calcFn(sim, bounceTarget, int32(bounceIndex), bounceMultiplier))
+		bounceMultiplier *= chain.BounceMultiplier
+	}
+	return chain.results
+}
+
+// Deals the damage of a chain spell result for the given bounce.
+func (spell *Spell) DealBounceDamage(sim *Simulation, result *SpellResult, bounceIndex int32) {
+	bounce := &spell.chain.bounces[bounceIndex]
+	bounce.Hits++
+	bounce.Damage += result.Damage
+	spell.DealDamage(sim, result)
+}
+
+// Deals the healing of a chain spell result for the given bounce.
+func (spell *Spell) DealBounceHealing(sim *Simulation, result *SpellResult, bounceIndex int32) {
+	bounce := &spell.chain.bounces[bounceIndex]
+	bounce.Hits++
+	bounce.Healing += result.Damage
+	spell.DealHealing(sim, result)
+}
+
+// Deals the damage of all results from CalcChain(), in order.
+func (spell *Spell) DealChainDamage(sim *Simulation, results []*SpellResult) {
+	for bounceIndex, result := range results {
+		spell.DealBounceDamage(sim, result, int32(bounceIndex))
+	}
+}
+
+// Deals the healing of all results from CalcChain(), in order.
+func (spell *Spell) DealChainHealing(sim *Simulation, results []*SpellResult) {
+	for bounceIndex, result := range results {
+		spell.DealBounceHealing(sim, result, int32(bounceIndex))
+	}
+}
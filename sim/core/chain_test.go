@@ -0,0 +1,66 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestChainSpell(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{})
+	request.Encounter.Targets = append(request.Encounter.Targets, &proto.Target{Level: 83}, &proto.Target{Level: 83})
+	sim := NewSim(request)
+
+	character := sim.Raid.Parties[0].Players[0].GetCharacter()
+	spell := character.RegisterSpell(SpellConfig{
+		ActionID:    ActionID{SpellID: 43},
+		SpellSchool: SpellSchoolNature,
+		ProcMask:    ProcMaskSpellDamage,
+		Flags:       SpellFlagIgnoreResists,
+
+		DamageMultiplier: 1,
+		CritMultiplier:   1,
+		ThreatMultiplier: 1,
+
+		// More hits than targets, so each target is only hit once.
+		Chain: ChainConfig{
+			NumHits:          4,
+			BounceMultiplier: 0.5,
+		},
+
+		ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {
+			results := spell.CalcChain(sim, target, func(sim *Simulation, target *Unit, _ int32, bounceMultiplier float64) *SpellResult {
+				return spell.CalcDamage(sim, target, 100*bounceMultiplier, spell.OutcomeAlwaysHit)
+			})
+			spell.DealChainDamage(sim, results)
+		},
+	})
+	spell.finalize()
+	sim.reset()
+
+	targets := sim.Encounter.TargetUnits
+	spell.ApplyEffects(sim, targets[1], spell)
+
+	for i, expected := range []float64{25, 100, 50} {
+		if damage := spell.SpellMetrics[targets[i].UnitIndex].TotalDamage; !WithinToleranceFloat64(expected, damage, 1e-9) {
+			t.Fatalf("Expected %f damage against target %d, got %f", expected, i, damage)
+		}
+	}
+
+	spell.doneIteration()
+	bounces := character.Metrics.actions[spell.ActionID].Bounces
+	if len(bounces) != 4 {
+		t.Fatalf("Expected metrics for 4 bounces, got %d", len(bounces))
+	}
+	for i, expected := range []float64{100, 50, 25, 0} {
+		if hits := TernaryInt32(expected > 0, 1, 0); bounces[i].Hits != hits || !WithinToleranceFloat64(expected, bounces[i].Damage, 1e-9) {
+			t.Fatalf("Expected %d hits for %f damage on bounce %d, got %v", hits, expected, i, bounces[i])
+		}
+	}
+
+	// Allies are picked by health, and never hit twice.
+	spell.chain.TargetRule = ChainTargetMostInjuredAlly
+	if allies := spell.ChainTargets(sim, &character.Unit); len(allies) != 1 || allies[0] != &character.Unit {
+		t.Fatalf("Expected only the caster, got %v", allies)
+	}
+}
@@ -67,6 +67,14 @@ type Character struct {
 
 	professions [2]proto.Profession
 
+	// Corrections made to this Character's settings, reported with the results.
+	Warnings []string
+
+	// See recordStatCaps().
+	statCaps []*proto.StatCap
+
+	setBonusOverrides []setBonusOverride
+
 	glyphs            [6]int32
 	PrimaryTalentTree uint8
 
@@ -82,6 +90,16 @@ type Character struct {
 	defensiveTrinketCD *Timer
 	offensiveTrinketCD *Timer
 	conjuredCD         *Timer
+	tinkerCD           *Timer
+
+	// External healing applied to this character, from its HealingModel.
+	externalHealer *externalHealer
+
+	deathOptions           *proto.DeathOptions
+	dead                   bool
+	deathTime              time.Duration
+	battleResUsed          int32
+	battleResHealthMetrics *ResourceMetrics
 
 	Pets []*Pet // cached in AddPet, for advance()
 }
@@ -127,6 +145,9 @@ func NewCharacter(party *Party, partyIndex int, player *proto.Player) Character
 		majorCooldownManager: newMajorCooldownManager(player.Cooldowns),
 	}
 
+	character.validateProfessionGear(player.ProfessionValidation)
+	character.applySetBonusOverrides(player.SetBonusOverrides)
+
 	character.GCD = character.NewTimer()
 
 	character.Label = fmt.Sprintf("%s (#%d)", character.Name, character.Index+1)
@@ -427,10 +448,14 @@ func (character *Character) initialize(agent Agent) {
 				}
 			}
 
-			if sim.CurrentTime < 0 {
+			if sim.CurrentTime < 0 || !character.enabled {
 				return
 			}
 
+			if character.GCD.IsReady(sim) {
+				character.onGCDReady(sim)
+			}
+
 			if sim.Options.Interactive {
 				if character.GCD.IsReady(sim) {
 					sim.NeedsInput = true
@@ -483,6 +508,7 @@ func (character *Character) FillPlayerStats(playerStats *proto.PlayerStats) {
 	}
 	character.clearBuildPhaseAuras(CharacterBuildPhaseAll)
 	playerStats.Sets = character.GetActiveSetBonusNames()
+	playerStats.Warnings = character.Warnings
 
 	playerStats.Metadata = character.GetMetadata()
 	for _, pet := range character.Pets {
@@ -501,6 +527,7 @@ func (character *Character) reset(sim *Simulation, agent Agent) {
 	character.majorCooldownManager.reset(sim)
 	character.ItemSwap.reset(sim)
 	character.CurrentTarget = character.defaultTarget
+	character.resetDeath(sim)
 
 	agent.Reset(sim)
 
@@ -620,6 +647,7 @@ func (character *Character) doneIteration(sim *Simulation) {
 		character.Metrics.AddFinalPetMetrics(&pet.Metrics)
 	}
 
+	character.doneIterationDeath(sim)
 	character.Unit.doneIteration(sim)
 }
 
@@ -632,6 +660,10 @@ func (character *Character) GetPseudoStatsProto() []float64 {
 		// Base values are modified by Enemy attackTables, but we display for LVL 80 enemy as paperdoll default
 		proto.PseudoStat_PseudoStatDodge: character.PseudoStats.BaseDodge + character.GetDiminishedDodgeChance(),
 		proto.PseudoStat_PseudoStatParry: character.PseudoStats.BaseParry + character.GetDiminishedParryChance(),
+
+		proto.PseudoStat_PseudoStatMeleeHaste:  character.HastePercent(HasteTrackMelee),
+		proto.PseudoStat_PseudoStatRangedHaste: character.HastePercent(HasteTrackRanged),
+		proto.PseudoStat_PseudoStatSpellHaste:  character.HastePercent(HasteTrackSpell),
 	}
 }
 
@@ -640,6 +672,7 @@ func (character *Character) GetMetricsProto() *proto.UnitMetrics {
 	metrics.Name = character.Name
 	metrics.UnitIndex = character.UnitIndex
 	metrics.Auras = character.auraTracker.GetMetricsProto()
+	metrics.StatCaps = character.statCaps
 
 	metrics.Pets = make([]*proto.UnitMetrics, len(character.Pets))
 	for i, pet := range character.Pets {
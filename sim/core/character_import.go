@@ -0,0 +1,365 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Export format of the WowSimsExporter addon.
+type addonExport struct {
+	Name        string `json:"name"`
+	Class       string `json:"class"`
+	Race        string `json:"race"`
+	Talents     string `json:"talents"`
+	Professions []struct {
+		Name string `json:"name"`
+	} `json:"professions"`
+	Glyphs struct {
+		Major []json.RawMessage `json:"major"`
+		Minor []json.RawMessage `json:"minor"`
+	} `json:"glyphs"`
+	Gear *struct {
+		Items []*struct {
+			ID      int32   `json:"id"`
+			Enchant int32   `json:"enchant"`
+			Gems    []int32 `json:"gems"`
+		} `json:"items"`
+	} `json:"gear"`
+}
+
+// Character profile format of the armory API, with the equipment and
+// professions responses merged in.
+type armoryExport struct {
+	Name           string     `json:"name"`
+	CharacterClass armoryName `json:"character_class"`
+	Race           armoryName `json:"race"`
+	EquippedItems  []struct {
+		Item armoryID `json:"item"`
+		Slot struct {
+			Type string `json:"type"`
+		} `json:"slot"`
+		Enchantments []struct {
+			EnchantmentID   int32 `json:"enchantment_id"`
+			EnchantmentSlot struct {
+				ID int32 `json:"id"`
+			} `json:"enchantment_slot"`
+		} `json:"enchantments"`
+		Sockets []armorySocket `json:"sockets"`
+	} `json:"equipped_items"`
+	Primaries []struct {
+		Profession armoryName `json:"profession"`
+	} `json:"primaries"`
+}
+
+type armoryName struct {
+	Name string `json:"name"`
+}
+
+type armoryID struct {
+	ID int32 `json:"id"`
+}
+
+type armorySocket struct {
+	Item armoryID `json:"item"`
+}
+
+var armorySlots = map[string]proto.ItemSlot{
+	"HEAD":      proto.ItemSlot_ItemSlotHead,
+	"NECK":      proto.ItemSlot_ItemSlotNeck,
+	"SHOULDER":  proto.ItemSlot_ItemSlotShoulder,
+	"BACK":      proto.ItemSlot_ItemSlotBack,
+	"CHEST":     proto.ItemSlot_ItemSlotChest,
+	"WRIST":     proto.ItemSlot_ItemSlotWrist,
+	"HANDS":     proto.ItemSlot_ItemSlotHands,
+	"WAIST":     proto.ItemSlot_ItemSlotWaist,
+	"LEGS":      proto.ItemSlot_ItemSlotLegs,
+	"FEET":      proto.ItemSlot_ItemSlotFeet,
+	"FINGER_1":  proto.ItemSlot_ItemSlotFinger1,
+	"FINGER_2":  proto.ItemSlot_ItemSlotFinger2,
+	"TRINKET_1": proto.ItemSlot_ItemSlotTrinket1,
+	"TRINKET_2": proto.ItemSlot_ItemSlotTrinket2,
+	"MAIN_HAND": proto.ItemSlot_ItemSlotMainHand,
+	"OFF_HAND":  proto.ItemSlot_ItemSlotOffHand,
+	"RANGED":    proto.ItemSlot_ItemSlotRanged,
+}
+
+type characterImporter struct {
+	player *proto.Player
+	errors []*proto.ImportError
+}
+
+func (importer *characterImporter) addError(path string, id int32, message string, vals ...interface{}) {
+	importer.errors = append(importer.errors, &proto.ImportError{
+		Path:    path,
+		Id:      id,
+		Message: fmt.Sprintf(message, vals...),
+	})
+}
+
+// Converts a character export into a player. Unknown IDs and names are
+// reported as errors, and left out of the player.
+func importCharacter(request *proto.ImportCharacterRequest) *proto.ImportCharacterResult {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(request.Data), &fields); err != nil {
+		return &proto.ImportCharacterResult{ErrorResult: fmt.Sprintf("Export is not valid JSON: %s", err)}
+	}
+
+	importer := &characterImporter{
+		player: &proto.Player{},
+	}
+	var err error
+	if _, ok := fields["equipped_items"]; ok {
+		err = importer.importArmory(request.Data)
+	} else if _, ok := fields["gear"]; ok {
+		err = importer.importAddon(request.Data)
+	} else {
+		err = fmt.Errorf("unrecognized export format, expected addon or armory JSON")
+	}
+	if err != nil {
+		return &proto.ImportCharacterResult{ErrorResult: err.Error()}
+	}
+
+	return &proto.ImportCharacterResult{
+		Player: importer.player,
+		Errors: importer.errors,
+	}
+}
+
+func (importer *characterImporter) importAddon(data string) error {
+	var export addonExport
+	if err := json.Unmarshal([]byte(data), &export); err != nil {
+		return fmt.Errorf("failed to parse addon export: %s", err)
+	}
+
+	if err := importer.setClassAndRace(export.Class, export.Race); err != nil {
+		return err
+	}
+	importer.player.Name = export.Name
+	importer.player.TalentsString = export.Talents
+
+	var professions []string
+	for _, profession := range export.Professions {
+		professions = append(professions, profession.Name)
+	}
+	importer.setProfessions("professions", professions)
+
+	glyphs := &proto.Glyphs{}
+	majors := importer.glyphIDs("glyphs.major", export.Glyphs.Major, "MajorGlyph")
+	minors := importer.glyphIDs("glyphs.minor", export.Glyphs.Minor, "MinorGlyph")
+	glyphs.Major1, glyphs.Major2, glyphs.Major3 = majors[0], majors[1], majors[2]
+	glyphs.Minor1, glyphs.Minor2, glyphs.Minor3 = minors[0], minors[1], minors[2]
+	importer.player.Glyphs = glyphs
+
+	equipment := Equipment{}
+	if export.Gear != nil {
+		for i, exportItem := range export.Gear.Items {
+			if exportItem == nil || exportItem.ID == 0 {
+				continue
+			}
+			if item, ok := importer.newItem(fmt.Sprintf("gear.items.%d", i), exportItem.ID, exportItem.Enchant, exportItem.Gems); ok {
+				equipment.EquipItem(item)
+			}
+		}
+	}
+	importer.player.Equipment = equipment.ToEquipmentSpecProto()
+	return nil
+}
+
+func (importer *characterImporter) importArmory(data string) error {
+	var export armoryExport
+	if err := json.Unmarshal([]byte(data), &export); err != nil {
+		return fmt.Errorf("failed to parse armory export: %s", err)
+	}
+
+	if err := importer.setClassAndRace(export.CharacterClass.Name, export.Race.Name); err != nil {
+		return err
+	}
+	importer.player.Name = export.Name
+
+	var professions []string
+	for _, primary := range export.Primaries {
+		professions = append(professions, primary.Profession.Name)
+	}
+	importer.setProfessions("primaries", professions)
+
+	equipment := Equipment{}
+	for i, exportItem := range export.EquippedItems {
+		path := fmt.Sprintf("equipped_items.%d", i)
+		slot, ok := armorySlots[exportItem.Slot.Type]
+		if !ok {
+			// Shirts and tabards.
+			continue
+		}
+
+		var enchant int32
+		for _, enchantment := range exportItem.Enchantments {
+			// Slot 0 is the permanent enchant, the others are temporary
+			// enchants and socket contents.
+			if enchantment.EnchantmentSlot.ID == 0 {
+				enchant = enchantment.EnchantmentID
+			}
+		}
+		gems := MapSlice(exportItem.Sockets, func(socket armorySocket) int32 { return socket.Item.ID })
+
+		if item, ok := importer.newItem(path, exportItem.Item.ID, enchant, gems); ok {
+			equipment[slot] = item
+		}
+	}
+	importer.player.Equipment = equipment.ToEquipmentSpecProto()
+	return nil
+}
+
+// Returns the item with the given enchant and gems, leaving out any unknown
+// enchant or gems. Returns false if the item itself is unknown.
+func (importer *characterImporter) newItem(path string, itemID int32, enchantID int32, gemIDs []int32) (Item, bool) {
+	if _, ok := ItemsByID[itemID]; !ok {
+		importer.addError(path, itemID, "Unknown item %d.", itemID)
+		return Item{}, false
+	}
+
+	if enchantID != 0 {
+		if _, ok := EnchantsByEffectID[enchantID]; !ok {
+			importer.addError(path+".enchant", enchantID, "Unknown enchant %d.", enchantID)
+			enchantID = 0
+		}
+	}
+
+	gems := make([]int32, len(gemIDs))
+	for i, gemID := range gemIDs {
+		if gemID == 0 {
+			continue
+		}
+		if _, ok := GemsByID[gemID]; !ok {
+			importer.addError(fmt.Sprintf("%s.gems.%d", path, i), gemID, "Unknown gem %d.", gemID)
+			continue
+		}
+		gems[i] = gemID
+	}
+
+	return NewItem(ItemSpec{ID: itemID, Enchant: enchantID, Gems: gems}), true
+}
+
+func (importer *characterImporter) setClassAndRace(className string, raceName string) error {
+	class, ok := enumValueByName(proto.Class_value, "Class", className)
+	if !ok || class == int32(proto.Class_ClassUnknown) {
+		return fmt.Errorf("unknown class '%s'", className)
+	}
+	race, ok := enumValueByName(proto.Race_value, "Race", raceName)
+	if !ok || race == int32(proto.Race_RaceUnknown) {
+		return fmt.Errorf("unknown race '%s'", raceName)
+	}
+
+	importer.player.Class = proto.Class(class)
+	importer.player.Race = proto.Race(race)
+	return nil
+}
+
+func (importer *characterImporter) setProfessions(path string, names []string) {
+	var professions []proto.Profession
+	for i, name := range names {
+		profession, ok := enumValueByName(proto.Profession_value, "", name)
+		if !ok || profession == int32(proto.Profession_ProfessionUnknown) {
+			importer.addError(fmt.Sprintf("%s.%d", path, i), 0, "Unknown profession '%s'.", name)
+			continue
+		}
+		professions = append(professions, proto.Profession(profession))
+	}
+
+	if len(professions) > 0 {
+		importer.player.Profession1 = professions[0]
+	}
+	if len(professions) > 1 {
+		importer.player.Profession2 = professions[1]
+	}
+}
+
+// Returns the item IDs of up to 3 glyphs. Glyphs are exported either by name,
+// or as an object with the glyph's spell ID.
+func (importer *characterImporter) glyphIDs(path string, glyphs []json.RawMessage, enumSuffix string) [3]int32 {
+	var ids [3]int32
+	numGlyphs := 0
+	for i, rawGlyph := range glyphs {
+		glyphPath := fmt.Sprintf("%s.%d", path, i)
+		if numGlyphs == len(ids) {
+			importer.addError(glyphPath, 0, "Too many glyphs.")
+			break
+		}
+
+		var name string
+		var glyph struct {
+			Name    string `json:"name"`
+			SpellID int32  `json:"spellID"`
+		}
+		if err := json.Unmarshal(rawGlyph, &name); err != nil {
+			if err := json.Unmarshal(rawGlyph, &glyph); err != nil {
+				importer.addError(glyphPath, 0, "Failed to parse glyph: %s", err)
+				continue
+			}
+		}
+
+		if glyph.SpellID != 0 {
+			if itemID, ok := GlyphItemIDsBySpellID[glyph.SpellID]; ok {
+				ids[numGlyphs] = itemID
+				numGlyphs++
+				continue
+			}
+			name = glyph.Name
+		}
+		if name == "" {
+			if glyph.SpellID != 0 {
+				importer.addError(glyphPath, glyph.SpellID, "Unknown glyph spell %d.", glyph.SpellID)
+			}
+			continue
+		}
+
+		enumName := strings.TrimPrefix(importer.player.Class.String(), "Class") + enumSuffix
+		enumType, err := protoregistry.GlobalTypes.FindEnumByName(protoreflect.FullName("proto." + enumName))
+		if err != nil {
+			importer.addError(glyphPath, 0, "No glyphs for class %s.", importer.player.Class)
+			continue
+		}
+
+		var itemID int32
+		values := enumType.Descriptor().Values()
+		for j := 0; j < values.Len(); j++ {
+			if normalizeEnumName(string(values.Get(j).Name())) == normalizeEnumName(name) {
+				itemID = int32(values.Get(j).Number())
+			}
+		}
+		if itemID == 0 {
+			importer.addError(glyphPath, glyph.SpellID, "Unknown glyph '%s'.", name)
+			continue
+		}
+		ids[numGlyphs] = itemID
+		numGlyphs++
+	}
+	return ids
+}
+
+// Looks up an enum value by a human readable name, e.g. "Night Elf" for
+// RaceNightElf.
+func enumValueByName(values map[string]int32, prefix string, name string) (int32, bool) {
+	normalized := normalizeEnumName(name)
+	for enumName, value := range values {
+		if normalizeEnumName(strings.TrimPrefix(enumName, prefix)) == normalized {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+func normalizeEnumName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestImportCharacter(t *testing.T) {
+	addToDatabase(&proto.SimDatabase{
+		Items: []*proto.SimItem{
+			{Id: 920001, Type: proto.ItemType_ItemTypeHead, GemSockets: []proto.GemColor{proto.GemColor_GemColorMeta, proto.GemColor_GemColorRed}},
+			{Id: 920002, Type: proto.ItemType_ItemTypeFinger},
+		},
+		Enchants: []*proto.SimEnchant{{EffectId: 920101}},
+		Gems:     []*proto.SimGem{{Id: 920201, Color: proto.GemColor_GemColorRed}},
+	})
+	GlyphItemIDsBySpellID[58388] = int32(proto.WarriorMajorGlyph_GlyphOfHeroicStrike)
+
+	result := ImportCharacter(&proto.ImportCharacterRequest{Data: `{
+		"class": "warrior",
+		"race": "Night Elf",
+		"talents": "3022032023335100102012213231251-305-2033",
+		"professions": [{"name": "Blacksmithing", "level": 450}, {"name": "Basketweaving", "level": 1}],
+		"glyphs": {
+			"major": [{"name": "Glyph of Heroic Strike", "spellID": 58388}, "Glyph of Rending", {"spellID": 12345}],
+			"minor": ["Glyph of Battle"]
+		},
+		"gear": {"items": [
+			{"id": 920001, "enchant": 920101, "gems": [999999, 920201]},
+			null,
+			{"id": 999998},
+			{"id": 920002, "enchant": 999997}
+		]}
+	}`})
+	if result.ErrorResult != "" {
+		t.Fatalf("ImportCharacter() returned error: %s", result.ErrorResult)
+	}
+
+	player := result.Player
+	if player.Class != proto.Class_ClassWarrior || player.Race != proto.Race_RaceNightElf {
+		t.Errorf("Unexpected class and race: %s %s", player.Class, player.Race)
+	}
+	if player.Profession1 != proto.Profession_Blacksmithing || player.Profession2 != proto.Profession_ProfessionUnknown {
+		t.Errorf("Unexpected professions: %s %s", player.Profession1, player.Profession2)
+	}
+	if glyphs := player.Glyphs; glyphs.Major1 != int32(proto.WarriorMajorGlyph_GlyphOfHeroicStrike) ||
+		glyphs.Major2 != int32(proto.WarriorMajorGlyph_GlyphOfRending) || glyphs.Major3 != 0 ||
+		glyphs.Minor1 != int32(proto.WarriorMinorGlyph_GlyphOfBattle) {
+		t.Errorf("Unexpected glyphs: %v", glyphs)
+	}
+
+	head := player.Equipment.Items[proto.ItemSlot_ItemSlotHead]
+	if head.Id != 920001 || head.Enchant != 920101 || len(head.Gems) != 2 || head.Gems[0] != 0 || head.Gems[1] != 920201 {
+		t.Errorf("Unexpected head: %v", head)
+	}
+	if ring := player.Equipment.Items[proto.ItemSlot_ItemSlotFinger1]; ring.Id != 920002 || ring.Enchant != 0 {
+		t.Errorf("Unexpected ring: %v", ring)
+	}
+
+	expectedErrors := []*proto.ImportError{
+		{Path: "professions.1"},
+		{Path: "glyphs.major.2", Id: 12345},
+		{Path: "gear.items.0.gems.0", Id: 999999},
+		{Path: "gear.items.2", Id: 999998},
+		{Path: "gear.items.3.enchant", Id: 999997},
+	}
+	if len(result.Errors) != len(expectedErrors) {
+		t.Fatalf("Expected %d errors, got %v", len(expectedErrors), result.Errors)
+	}
+	for i, expected := range expectedErrors {
+		if actual := result.Errors[i]; actual.Path != expected.Path || actual.Id != expected.Id {
+			t.Errorf("Expected error at %s for %d, got %v", expected.Path, expected.Id, actual)
+		}
+	}
+}
+
+func TestImportCharacterArmory(t *testing.T) {
+	addToDatabase(&proto.SimDatabase{
+		Items: []*proto.SimItem{{Id: 920003, Type: proto.ItemType_ItemTypeFinger}},
+	})
+
+	result := ImportCharacter(&proto.ImportCharacterRequest{Data: `{
+		"name": "Tester",
+		"character_class": {"name": "Death Knight"},
+		"race": {"name": "Blood Elf"},
+		"equipped_items": [
+			{"item": {"id": 920003}, "slot": {"type": "FINGER_2"}},
+			{"item": {"id": 1}, "slot": {"type": "TABARD"}}
+		],
+		"primaries": [{"profession": {"name": "Jewelcrafting"}}]
+	}`})
+	if result.ErrorResult != "" || len(result.Errors) != 0 {
+		t.Fatalf("ImportCharacter() returned errors: %s %v", result.ErrorResult, result.Errors)
+	}
+
+	player := result.Player
+	if player.Name != "Tester" || player.Class != proto.Class_ClassDeathknight || player.Race != proto.Race_RaceBloodElf || player.Profession1 != proto.Profession_Jewelcrafting {
+		t.Errorf("Unexpected player: %v", player)
+	}
+	if ring := player.Equipment.Items[proto.ItemSlot_ItemSlotFinger2]; ring.Id != 920003 {
+		t.Errorf("Expected the ring in the second finger slot, got %v", ring)
+	}
+
+	if result := ImportCharacter(&proto.ImportCharacterRequest{Data: `{"class": "warrior"}`}); result.ErrorResult == "" {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
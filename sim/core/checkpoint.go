@@ -0,0 +1,233 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Iterations between checkpoints, if not given. Each checkpoint rebuilds the
+// sim, so this shouldn't be too small.
+const defaultCheckpointInterval = 10000
+
+// Saves a checkpoint, e.g. to a file. Checkpoints may be modified once this
+// returns, so they should be serialized right away.
+type SimCheckpointFunc func(checkpoint *proto.SimCheckpoint) error
+type StatWeightsCheckpointFunc func(checkpoint *proto.StatWeightsCheckpoint) error
+
+// Like RunSimWithContext, but runs iterations in chunks of interval, saving a
+// checkpoint after each one. If resume is set, continues from it instead of
+// starting over, ignoring rsr.
+//
+// When ctx is done, the current chunk stops early and its iterations are still
+// saved, so the sim can be resumed later.
+func runCheckpointedSim(ctx context.Context, rsr *proto.RaidSimRequest, resume *proto.SimCheckpoint, interval int32, save SimCheckpointFunc, progress chan *proto.ProgressMetrics) *proto.RaidSimResult {
+	if progress != nil {
+		defer close(progress)
+	}
+	var totalIterations int32
+	result := func() *proto.RaidSimResult {
+		checkpoint := resume
+		if checkpoint == nil {
+			// Every chunk has to use the same seed for its iterations to line up.
+			rsr = googleProto.Clone(rsr).(*proto.RaidSimRequest)
+			if rsr.SimOptions == nil {
+				rsr.SimOptions = &proto.SimOptions{}
+			}
+			if rsr.SimOptions.RandomSeed == 0 {
+				rsr.SimOptions.RandomSeed = time.Now().UnixNano()
+			}
+			checkpoint = &proto.SimCheckpoint{Request: rsr}
+		}
+		options := checkpoint.Request.GetSimOptions()
+		if options == nil {
+			return &proto.RaidSimResult{ErrorResult: "Checkpoint has no request."}
+		}
+		if !canShardIterations(options) || checkpoint.Request.ExternalsOptimizer.GetEnabled() {
+			return &proto.RaidSimResult{ErrorResult: "These sim options can't be checkpointed."}
+		}
+		if options.Iterations <= 0 {
+			return &proto.RaidSimResult{ErrorResult: "Checkpointed sims need at least 1 iteration."}
+		}
+		totalIterations = options.Iterations
+
+		if interval <= 0 {
+			interval = defaultCheckpointInterval
+		}
+		if options.AntitheticSampling && interval%2 == 1 {
+			// Antithetic pairs can't be split across chunks.
+			interval++
+		}
+
+		for checkpoint.CompletedIterations < options.Iterations {
+			start := checkpoint.CompletedIterations
+			chunk := runIterations(ctx, checkpoint.Request, start, min(start+interval, options.Iterations))
+			if chunk.Result.ErrorResult != "" {
+				return chunk.Result
+			}
+
+			merged := chunk.Result
+			if start > 0 {
+				merged = mergeShardResults([]*proto.RaidSimShardResult{
+					{Result: checkpoint.PartialResult, CompletedIterations: start},
+					chunk,
+				}, options)
+			}
+			checkpoint = &proto.SimCheckpoint{
+				Request:             checkpoint.Request,
+				CompletedIterations: start + chunk.CompletedIterations,
+				PartialResult:       merged,
+			}
+			if err := save(checkpoint); err != nil {
+				return &proto.RaidSimResult{ErrorResult: fmt.Sprintf("Failed to save checkpoint: %s", err)}
+			}
+
+			if progress != nil {
+				progress <- &proto.ProgressMetrics{
+					TotalIterations:     options.Iterations,
+					CompletedIterations: checkpoint.CompletedIterations,
+					Dps:                 merged.RaidMetrics.GetDps().GetAvg(),
+				}
+			}
+			// Chunks always run at least one iteration, so resuming still makes
+			// progress when ctx is already done.
+			if ctx.Err() != nil {
+				break
+			}
+		}
+
+		result := googleProto.Clone(checkpoint.PartialResult).(*proto.RaidSimResult)
+		result.Cancelled = checkpoint.CompletedIterations < options.Iterations
+		return result
+	}()
+
+	if progress != nil {
+		progress <- &proto.ProgressMetrics{
+			TotalIterations:     totalIterations,
+			CompletedIterations: completedIterations(result),
+			Dps:                 result.GetRaidMetrics().GetDps().GetAvg(),
+			FinalRaidResult:     result,
+		}
+	}
+	return result
+}
+
+// Runs iterations [start, end) of a sim, sharded across SimOptions.concurrency
+// workers like RunSimWithContext does.
+func runIterations(ctx context.Context, rsr *proto.RaidSimRequest, start int32, end int32) *proto.RaidSimShardResult {
+	options := rsr.SimOptions
+	numWorkers := int32(1)
+	if !lowMemoryMode {
+		numWorkers = max(1, min(options.Concurrency, (end-start)/minIterationsPerWorker))
+	}
+	bounds := shardIterations(end-start, numWorkers, options.AntitheticSampling)
+
+	shards := make([]*proto.RaidSimShardResult, numWorkers)
+	var wg sync.WaitGroup
+	for i := range shards {
+		// Each shard builds its own sim from the request, so gets its own copy.
+		shardRequest := &proto.RaidSimShardRequest{
+			Request:        googleProto.Clone(rsr).(*proto.RaidSimRequest),
+			StartIteration: start + bounds[i],
+			EndIteration:   start + bounds[i+1],
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			shards[i] = runRaidSimShard(ctx, shardRequest)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, shard := range shards {
+		if shard.Result.GetErrorResult() != "" {
+			return shard
+		}
+	}
+	// A cancelled shard may have stopped before those after it, which would
+	// leave a gap in the iterations, so only keep the ones before it.
+	for i, shard := range shards {
+		if shard.Result.Cancelled {
+			shards = shards[:i+1]
+			break
+		}
+	}
+
+	var completed int32
+	for _, shard := range shards {
+		completed += shard.CompletedIterations
+	}
+	return &proto.RaidSimShardResult{
+		Result:              mergeShardResults(shards, options),
+		CompletedIterations: completed,
+	}
+}
+
+// Number of iterations a result has metrics for.
+func completedIterations(result *proto.RaidSimResult) int32 {
+	var completed int32
+	for _, count := range result.GetRaidMetrics().GetDps().GetHist() {
+		completed += count
+	}
+	return completed
+}
+
+// Like StatWeightsWithContext, but checkpoints each of its raid sims, see
+// runCheckpointedSim. If resume is set, continues from it, ignoring request.
+func statWeightsWithCheckpoints(ctx context.Context, request *proto.StatWeightsRequest, resume *proto.StatWeightsCheckpoint, interval int32, save StatWeightsCheckpointFunc, progress chan *proto.ProgressMetrics) *proto.StatWeightsResult {
+	checkpoint := resume
+	if checkpoint == nil {
+		// Sims are matched up with their checkpoints by their request, so the
+		// seed has to be fixed up front.
+		request = googleProto.Clone(request).(*proto.StatWeightsRequest)
+		if request.SimOptions == nil {
+			request.SimOptions = &proto.SimOptions{}
+		}
+		if request.SimOptions.RandomSeed == 0 {
+			request.SimOptions.RandomSeed = time.Now().UnixNano()
+		}
+		checkpoint = &proto.StatWeightsCheckpoint{Request: request}
+	}
+	if checkpoint.Sims == nil {
+		checkpoint.Sims = make(map[string]*proto.SimCheckpoint)
+	}
+
+	var mu sync.Mutex
+	runSim := func(ctx context.Context, rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, _ bool) *proto.RaidSimResult {
+		key := checkpointKey(rsr)
+		mu.Lock()
+		resumeSim := checkpoint.Sims[key]
+		mu.Unlock()
+
+		return runCheckpointedSim(ctx, rsr, resumeSim, interval, func(simCheckpoint *proto.SimCheckpoint) error {
+			mu.Lock()
+			defer mu.Unlock()
+			checkpoint.Sims[key] = simCheckpoint
+			return save(checkpoint)
+		}, progress)
+	}
+
+	concurrency := (runtime.NumCPU() - 1) * 2
+	if concurrency <= 0 {
+		concurrency = 2
+	}
+	request = checkpoint.Request
+	return calcStatWeight(ctx, request, stats.Stat(request.EpReferenceStat), progress, runSim, concurrency).ToProto()
+}
+
+func checkpointKey(rsr *proto.RaidSimRequest) string {
+	data, err := googleProto.MarshalOptions{Deterministic: true}.Marshal(rsr)
+	if err != nil {
+		panic(err)
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+func TestResumeFromCheckpoint(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{Iterations: 400, RandomSeed: 101, Concurrency: 2})
+
+	var checkpoints []*proto.SimCheckpoint
+	full := RunRaidSimWithCheckpoints(context.Background(), request, nil, 100, func(checkpoint *proto.SimCheckpoint) error {
+		checkpoints = append(checkpoints, googleProto.Clone(checkpoint).(*proto.SimCheckpoint))
+		return nil
+	})
+	if full.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", full.ErrorResult)
+	}
+	if len(checkpoints) != 4 || checkpoints[1].CompletedIterations != 200 {
+		t.Fatalf("Expected a checkpoint every 100 iterations, got %d", len(checkpoints))
+	}
+
+	saves := 0
+	resumed := RunRaidSimWithCheckpoints(context.Background(), nil, checkpoints[1], 100, func(checkpoint *proto.SimCheckpoint) error {
+		saves++
+		return nil
+	})
+	if saves != 2 {
+		t.Fatalf("Expected 2 more checkpoints, got %d", saves)
+	}
+	if !googleProto.Equal(full, resumed) {
+		t.Fatalf("Resumed sim doesn't match the uninterrupted one")
+	}
+
+	local := RunRaidSim(request)
+	expected, actual := local.RaidMetrics.Parties[0].Players[0].Dtps, full.RaidMetrics.Parties[0].Players[0].Dtps
+	if math.Abs(expected.Avg-actual.Avg) > 1e-6*expected.Avg || math.Abs(expected.Stdev-actual.Stdev) > 1e-6*expected.Stdev {
+		t.Fatalf("Expected DTPS %v, got %v", expected, actual)
+	}
+}
+
+func TestCancelledCheckpointedSim(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var last *proto.SimCheckpoint
+	result := RunRaidSimWithCheckpoints(ctx, tankSimRequest(&proto.SimOptions{Iterations: 400}), nil, 100, func(checkpoint *proto.SimCheckpoint) error {
+		last = checkpoint
+		return nil
+	})
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", result.ErrorResult)
+	}
+
+	// The first iteration always runs, so there's still a checkpoint to resume.
+	if !result.Cancelled || last == nil || last.CompletedIterations != 1 || last.Request.SimOptions.RandomSeed == 0 {
+		t.Fatalf("Expected a cancelled result and a checkpoint after 1 iteration, got %v", last)
+	}
+}
+
+func TestCheckpointedSimWithoutSimOptions(t *testing.T) {
+	save := func(checkpoint *proto.SimCheckpoint) error {
+		t.Fatalf("Expected no checkpoints")
+		return nil
+	}
+	if result := RunRaidSimWithCheckpoints(context.Background(), tankSimRequest(nil), nil, 100, save); result.ErrorResult == "" {
+		t.Fatalf("Expected an error for a sim without iterations")
+	}
+
+	request := tankSimRequest(nil)
+	swr := &proto.StatWeightsRequest{
+		Player:       request.Raid.Parties[0].Players[0],
+		Encounter:    request.Encounter,
+		Tanks:        request.Raid.Tanks,
+		StatsToWeigh: []proto.Stat{proto.Stat_StatSpellPower},
+	}
+	StatWeightsWithCheckpoints(context.Background(), swr, nil, 100, func(checkpoint *proto.StatWeightsCheckpoint) error {
+		t.Fatalf("Expected no checkpoints")
+		return nil
+	}, nil)
+	if swr.SimOptions != nil {
+		t.Errorf("Expected the caller's request to be unchanged, got %v", swr.SimOptions)
+	}
+}
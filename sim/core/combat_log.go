@@ -0,0 +1,575 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Converts sim debug logs into the WotLK WoWCombatLog.txt format, so sim
+// output can be fed into log analyzers.
+//
+// Events are converted as they're logged, see WriteEvent and
+// RunRaidSimWithLogEventsAsync, so the full log never needs to be held in memory.
+// Spell names come from the action registry, falling back to the sim's aura
+// labels and then to the spell ID, and schools from the sim's spells.
+//
+// Text debug logs, e.g. RaidSimResult.logs, can also be converted through
+// Write. Those don't say which spell belongs to which unit, so names and
+// schools only come from the action registry there, and spells it doesn't
+// know are named by ID and written as physical.
+type CombatLogWriter struct {
+	out          *bufio.Writer
+	startTime    time.Time
+	iterationGap time.Duration
+
+	partial []byte
+
+	// Start of the current iteration, relative to startTime.
+	iterationOffset time.Duration
+	lastTimestamp   time.Duration
+	wroteEvents     bool
+
+	units  map[string]*combatLogUnit
+	spells map[ActionID]combatLogSpell
+	err    error
+}
+
+type CombatLogWriterOptions struct {
+	// Wall clock time of the start of the first iteration. Defaults to 8pm on
+	// January 1st.
+	StartTime time.Time
+
+	// Time between the end of one iteration and the start of the next.
+	// Defaults to 1 minute.
+	IterationGap time.Duration
+}
+
+type combatLogUnit struct {
+	guid  string
+	name  string
+	flags string
+	enemy bool
+}
+
+type combatLogSpell struct {
+	id     int32
+	name   string
+	school SpellSchool
+}
+
+const (
+	combatLogPlayerFlags = "0x514"
+	combatLogPetFlags    = "0x1114"
+	combatLogEnemyFlags  = "0xa48"
+	combatLogNilUnit     = "0x0000000000000000,nil,0x80000000"
+)
+
+var (
+	combatLogLineRegex     = regexp.MustCompile(`^\[(-?[\d.]+)\] (?:\[([^\]]+)\] )?(.*)$`)
+	combatLogResultRegex   = regexp.MustCompile(`^\[([^\]]+)\] (\{[^}]*\}) (tick )?([A-Za-z]+)(?: \((\d+)% Resist\))?(?: for ([\d.]+) (damage|healing))?(?: \(([\d.]+) blocked\))?\. \(Threat`)
+	combatLogCastRegex     = regexp.MustCompile(`^Casting (\{[^}]*\}) \(Cost = [-\d.]+, Cast Time = ([^,]+),`)
+	combatLogCompleteRegex = regexp.MustCompile(`^Completed cast (\{[^}]*\})$`)
+	combatLogAuraRegex     = regexp.MustCompile(`^Aura (gained|faded|refreshed|cancelled): (\{[^}]*\})$`)
+	combatLogStacksRegex   = regexp.MustCompile(`^(\{[^}]*\}) stacks: (\d+) --> (\d+)$`)
+	combatLogGainRegex     = regexp.MustCompile(`^Gained ([\d.]+) (mana|rage|focus|energy|runic power) from (\{[^}]*\})`)
+	combatLogActionRegex   = regexp.MustCompile(`^\{(SpellID|ItemID|OtherID): (\d+)(?:, Tag: (-?\d+))?\}$`)
+)
+
+var combatLogPowerTypes = map[proto.ResourceType]int{
+	proto.ResourceType_ResourceTypeMana:       0,
+	proto.ResourceType_ResourceTypeRage:       1,
+	proto.ResourceType_ResourceTypeFocus:      2,
+	proto.ResourceType_ResourceTypeEnergy:     3,
+	proto.ResourceType_ResourceTypeRunicPower: 6,
+}
+
+// Outcomes as written by HitOutcome.String(), without partial resists.
+var combatLogOutcomes = map[string]HitOutcome{
+	"Miss":          OutcomeMiss,
+	"Dodge":         OutcomeDodge,
+	"Parry":         OutcomeParry,
+	"Glance":        OutcomeGlance,
+	"Block":         OutcomeBlock,
+	"CriticalBlock": OutcomeBlock | OutcomeCrit,
+	"Crit":          OutcomeCrit,
+	"Hit":           OutcomeHit,
+	"Crush":         OutcomeCrush,
+	"Empty":         OutcomeEmpty,
+}
+
+var combatLogAuraEvents = map[LogEventType]string{
+	LogEventAuraGained:    "SPELL_AURA_APPLIED",
+	LogEventAuraRefreshed: "SPELL_AURA_REFRESH",
+	LogEventAuraFaded:     "SPELL_AURA_REMOVED",
+	LogEventAuraCancelled: "SPELL_AURA_REMOVED",
+}
+
+func NewCombatLogWriter(w io.Writer, options CombatLogWriterOptions) *CombatLogWriter {
+	if options.StartTime.IsZero() {
+		options.StartTime = time.Date(2010, time.January, 1, 20, 0, 0, 0, time.UTC)
+	}
+	if options.IterationGap == 0 {
+		options.IterationGap = time.Minute
+	}
+
+	return &CombatLogWriter{
+		out:          bufio.NewWriter(w),
+		startTime:    options.StartTime,
+		iterationGap: options.IterationGap,
+		units:        make(map[string]*combatLogUnit),
+		spells:       make(map[ActionID]combatLogSpell),
+	}
+}
+
+// Converts a complete text debug log, e.g. RaidSimResult.logs, from r into w.
+func ExportCombatLog(r io.Reader, w io.Writer, options CombatLogWriterOptions) error {
+	clw := NewCombatLogWriter(w, options)
+	if _, err := io.Copy(clw, r); err != nil {
+		return err
+	}
+	return clw.Close()
+}
+
+// WriteEvent converts a single structured log event, and can be passed to
+// RunRaidSimWithLogEventsAsync directly.
+func (clw *CombatLogWriter) WriteEvent(event *LogEvent) {
+	if clw.err != nil {
+		return
+	}
+
+	if event.Actor == nil {
+		if event.Type == LogEventMessage && event.format == "SIM RESET" {
+			clw.startIteration()
+		}
+		return
+	}
+	timestamp := event.Timestamp + clw.iterationOffset
+	unit := clw.getUnit(event.Actor.Label, event.Actor.Type)
+
+	switch event.Type {
+	case LogEventDamage, LogEventHealing:
+		target := clw.getUnit(event.Target.Label, event.Target.Type)
+		clw.writeResult(timestamp, unit, target, event.ActionID, clw.eventSpell(event), event.Periodic, event.Outcome, event.Amount, event.Blocked, event.Type == LogEventHealing)
+	case LogEventCastStart:
+		if event.CastTime > 0 {
+			clw.writeSpellEvent(timestamp, "SPELL_CAST_START", unit, nil, event.ActionID, clw.eventSpell(event))
+		}
+	case LogEventCastComplete:
+		clw.writeSpellEvent(timestamp, "SPELL_CAST_SUCCESS", unit, nil, event.ActionID, clw.eventSpell(event))
+	case LogEventAuraGained, LogEventAuraRefreshed, LogEventAuraFaded, LogEventAuraCancelled:
+		clw.writeSpellEvent(timestamp, combatLogAuraEvents[event.Type], unit, unit, event.ActionID, clw.eventSpell(event), unit.auraType())
+	case LogEventAuraStacks:
+		clw.writeStacks(timestamp, unit, event.ActionID, clw.eventSpell(event), int(event.Before), int(event.After))
+	case LogEventResourceGain:
+		if powerType, ok := combatLogPowerTypes[event.Resource]; ok {
+			clw.writeSpellEvent(timestamp, "SPELL_ENERGIZE", unit, unit, event.ActionID, clw.eventSpell(event), formatCombatLogAmount(event.Amount), strconv.Itoa(powerType))
+		}
+	case LogEventMessage:
+		if event.format == "Dead" {
+			clw.writeEvent(timestamp, "UNIT_DIED", combatLogNilUnit+","+unit.String())
+		}
+	}
+}
+
+// Write accepts text debug log lines, which may be split across calls.
+func (clw *CombatLogWriter) Write(p []byte) (int, error) {
+	if clw.err != nil {
+		return 0, clw.err
+	}
+
+	data := p
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i == -1 {
+			clw.partial = append(clw.partial, data...)
+			break
+		}
+
+		line := data[:i]
+		if len(clw.partial) > 0 {
+			line = append(clw.partial, line...)
+			clw.partial = clw.partial[:0]
+		}
+		clw.convertLine(string(line))
+		data = data[i+1:]
+	}
+	return len(p), clw.err
+}
+
+// Close converts any trailing partial line and flushes buffered output. It
+// does not close the underlying writer.
+func (clw *CombatLogWriter) Close() error {
+	if len(clw.partial) > 0 {
+		clw.convertLine(string(clw.partial))
+		clw.partial = nil
+	}
+	if clw.err == nil {
+		clw.err = clw.out.Flush()
+	}
+	return clw.err
+}
+
+func (clw *CombatLogWriter) startIteration() {
+	if clw.wroteEvents {
+		clw.iterationOffset = clw.lastTimestamp + clw.iterationGap
+		clw.wroteEvents = false
+	}
+}
+
+func (clw *CombatLogWriter) convertLine(line string) {
+	match := combatLogLineRegex.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return
+	}
+	seconds, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return
+	}
+	timestamp := DurationFromSeconds(seconds)
+	label, message := match[2], match[3]
+
+	if label == "" {
+		if message == "SIM RESET" {
+			clw.startIteration()
+		}
+		return
+	}
+	timestamp += clw.iterationOffset
+	unit := clw.getUnit(label, guessCombatLogUnitType(label))
+
+	if match := combatLogResultRegex.FindStringSubmatch(message); match != nil {
+		actionID, ok := parseCombatLogAction(match[2])
+		outcome, known := combatLogOutcomes[match[4]]
+		if !ok || !known {
+			return
+		}
+		if pct, err := strconv.Atoi(match[5]); err == nil {
+			outcome |= HitOutcome(pct/10) << OutcomePartialOffset
+		}
+		amount, _ := strconv.ParseFloat(match[6], 64)
+		blocked, _ := strconv.ParseFloat(match[8], 64)
+		target := clw.getUnit(match[1], guessCombatLogUnitType(match[1]))
+		clw.writeResult(timestamp, unit, target, actionID, registrySpell(actionID), match[3] != "", outcome, amount, blocked, match[7] == "healing")
+	} else if match := combatLogCastRegex.FindStringSubmatch(message); match != nil {
+		if castTime, err := time.ParseDuration(match[2]); err == nil && castTime > 0 {
+			if actionID, ok := parseCombatLogAction(match[1]); ok {
+				clw.writeSpellEvent(timestamp, "SPELL_CAST_START", unit, nil, actionID, registrySpell(actionID))
+			}
+		}
+	} else if match := combatLogCompleteRegex.FindStringSubmatch(message); match != nil {
+		if actionID, ok := parseCombatLogAction(match[1]); ok {
+			clw.writeSpellEvent(timestamp, "SPELL_CAST_SUCCESS", unit, nil, actionID, registrySpell(actionID))
+		}
+	} else if match := combatLogAuraRegex.FindStringSubmatch(message); match != nil {
+		event := map[string]string{
+			"gained":    "SPELL_AURA_APPLIED",
+			"faded":     "SPELL_AURA_REMOVED",
+			"refreshed": "SPELL_AURA_REFRESH",
+			"cancelled": "SPELL_AURA_REMOVED",
+		}[match[1]]
+		if actionID, ok := parseCombatLogAction(match[2]); ok {
+			clw.writeSpellEvent(timestamp, event, unit, unit, actionID, registrySpell(actionID), unit.auraType())
+		}
+	} else if match := combatLogStacksRegex.FindStringSubmatch(message); match != nil {
+		if actionID, ok := parseCombatLogAction(match[1]); ok {
+			oldStacks, _ := strconv.Atoi(match[2])
+			newStacks, _ := strconv.Atoi(match[3])
+			clw.writeStacks(timestamp, unit, actionID, registrySpell(actionID), oldStacks, newStacks)
+		}
+	} else if match := combatLogGainRegex.FindStringSubmatch(message); match != nil {
+		if actionID, ok := parseCombatLogAction(match[3]); ok {
+			amount, _ := strconv.ParseFloat(match[1], 64)
+			powerType := combatLogPowerTypes[resourceTypeFromLogName(match[2])]
+			clw.writeSpellEvent(timestamp, "SPELL_ENERGIZE", unit, unit, actionID, registrySpell(actionID), formatCombatLogAmount(amount), strconv.Itoa(powerType))
+		}
+	} else if message == "Dead" {
+		clw.writeEvent(timestamp, "UNIT_DIED", combatLogNilUnit+","+unit.String())
+	}
+}
+
+func (clw *CombatLogWriter) writeResult(timestamp time.Duration, source *combatLogUnit, target *combatLogUnit, actionID ActionID, spell combatLogSpell, isPeriodic bool, outcome HitOutcome, amount float64, blocked float64, isHealing bool) {
+	var prefix, suffix string
+	var params []string
+	school := SpellSchoolPhysical
+	switch {
+	case actionID.OtherID == proto.OtherAction_OtherActionAttack:
+		prefix = "SWING"
+	case actionID.OtherID == proto.OtherAction_OtherActionShoot:
+		prefix = "RANGE"
+		params = []string{"75", `"Auto Shot"`, "0x1"}
+	case actionID.SpellID != 0:
+		prefix = "SPELL"
+		if isPeriodic {
+			prefix = "SPELL_PERIODIC"
+		}
+		params = []string{spell.String()}
+		school = spell.school
+	default:
+		return
+	}
+
+	critical := combatLogBool(outcome.Matches(OutcomeCrit))
+
+	if isHealing {
+		suffix = "_HEAL"
+		params = append(params, formatCombatLogAmount(amount), "0", "0", critical)
+	} else if !outcome.Matches(OutcomeLanded) {
+		suffix = "_MISSED"
+		params = append(params, combatLogMissType(outcome))
+	} else {
+		resisted := 0.0
+		if pct := 10 * float64(outcome>>OutcomePartialOffset); pct > 0 && pct < 100 {
+			resisted = amount * pct / (100 - pct)
+		}
+		suffix = "_DAMAGE"
+		params = append(params,
+			formatCombatLogAmount(amount),
+			"0",
+			strconv.Itoa(combatLogSchoolMask(school)),
+			formatCombatLogAmount(resisted),
+			formatCombatLogAmount(blocked),
+			"0",
+			critical,
+			combatLogBool(outcome.Matches(OutcomeGlance)),
+			combatLogBool(outcome.Matches(OutcomeCrush)))
+	}
+
+	clw.writeEvent(timestamp, prefix+suffix, source.String()+","+target.String()+","+strings.Join(params, ","))
+}
+
+func (clw *CombatLogWriter) writeStacks(timestamp time.Duration, unit *combatLogUnit, actionID ActionID, spell combatLogSpell, oldStacks int, newStacks int) {
+	if oldStacks > 0 && newStacks > oldStacks {
+		clw.writeSpellEvent(timestamp, "SPELL_AURA_APPLIED_DOSE", unit, unit, actionID, spell, unit.auraType(), strconv.Itoa(newStacks))
+	} else if newStacks > 0 && newStacks < oldStacks {
+		clw.writeSpellEvent(timestamp, "SPELL_AURA_REMOVED_DOSE", unit, unit, actionID, spell, unit.auraType(), strconv.Itoa(newStacks))
+	}
+}
+
+func (clw *CombatLogWriter) writeSpellEvent(timestamp time.Duration, event string, source *combatLogUnit, target *combatLogUnit, actionID ActionID, spell combatLogSpell, suffixParams ...string) {
+	if actionID.SpellID == 0 {
+		return
+	}
+
+	targetStr := combatLogNilUnit
+	if target != nil {
+		targetStr = target.String()
+	}
+	params := append([]string{spell.String()}, suffixParams...)
+	clw.writeEvent(timestamp, event, source.String()+","+targetStr+","+strings.Join(params, ","))
+}
+
+func (clw *CombatLogWriter) writeEvent(timestamp time.Duration, event string, params string) {
+	if clw.err != nil {
+		return
+	}
+	clw.lastTimestamp = max(clw.lastTimestamp, timestamp)
+	clw.wroteEvents = true
+
+	t := clw.startTime.Add(timestamp)
+	_, clw.err = fmt.Fprintf(clw.out, "%d/%d %s  %s,%s\n", int(t.Month()), t.Day(), t.Format("15:04:05.000"), event, params)
+}
+
+// Labels are unique, so units are keyed by them in both structured and text
+// logs.
+func (clw *CombatLogWriter) getUnit(label string, unitType UnitType) *combatLogUnit {
+	if unit, ok := clw.units[label]; ok {
+		return unit
+	}
+
+	unit := &combatLogUnit{name: label}
+	index := len(clw.units) + 1
+	switch unitType {
+	case EnemyUnit:
+		unit.guid = fmt.Sprintf("0xF130%012X", index)
+		unit.flags = combatLogEnemyFlags
+		unit.enemy = true
+	case PetUnit:
+		if _, pet, ok := strings.Cut(label, " - "); ok {
+			unit.name = pet
+		}
+		unit.guid = fmt.Sprintf("0xF140%012X", index)
+		unit.flags = combatLogPetFlags
+	default:
+		if i := strings.LastIndex(label, " (#"); i != -1 {
+			unit.name = label[:i]
+		}
+		unit.guid = fmt.Sprintf("0x%016X", index)
+		unit.flags = combatLogPlayerFlags
+	}
+	clw.units[label] = unit
+	return unit
+}
+
+// Text logs only have labels, so units are told apart by their format, e.g.
+// "Target 1" or "Mage (#1) - Water Elemental".
+func guessCombatLogUnitType(label string) UnitType {
+	if strings.HasPrefix(label, "Target ") {
+		return EnemyUnit
+	} else if owner, _, ok := strings.Cut(label, " - "); ok && strings.HasSuffix(owner, ")") {
+		return PetUnit
+	}
+	return PlayerUnit
+}
+
+func (unit *combatLogUnit) String() string {
+	return unit.guid + "," + strconv.Quote(unit.name) + "," + unit.flags
+}
+
+func (unit *combatLogUnit) auraType() string {
+	if unit.enemy {
+		return "DEBUFF"
+	}
+	return "BUFF"
+}
+
+// Looks up the name and school of an event's spell. The school of the spell
+// the sim actually cast takes priority, since the registry's is only used for
+// tooltips. Debuffs are logged by the unit they're on rather than their caster,
+// so every unit is searched, starting with the one that logged the event.
+func (clw *CombatLogWriter) eventSpell(event *LogEvent) combatLogSpell {
+	if spell, ok := clw.spells[event.ActionID]; ok {
+		return spell
+	}
+
+	spell := registrySpell(event.ActionID)
+	units := append([]*Unit{event.Actor}, event.Actor.Env.AllUnits...)
+	var caster *Unit
+	for _, unit := range units {
+		if simSpell := unit.GetSpell(event.ActionID); simSpell != nil {
+			caster = unit
+			if simSpell.SpellSchool != SpellSchoolNone {
+				spell.school = simSpell.SpellSchool
+			}
+			break
+		}
+	}
+	if spell.name == "" {
+		// Tagged spells often share the untagged spell's auras.
+		spell.name = auraName(units, caster, event.ActionID)
+		if spell.name == "" && event.ActionID.Tag != 0 {
+			spell.name = auraName(units, caster, ActionID{SpellID: event.ActionID.SpellID})
+		}
+	}
+	clw.spells[event.ActionID] = spell
+	return spell
+}
+
+func auraName(units []*Unit, caster *Unit, actionID ActionID) string {
+	for _, unit := range units {
+		if aura := unit.GetAuraByID(actionID); aura != nil && aura.Label != "" {
+			if caster != nil {
+				// Dots are labeled by caster, see Spell.createDots().
+				return strings.TrimSuffix(aura.Label, "-"+strconv.Itoa(int(caster.UnitIndex)))
+			}
+			return aura.Label
+		}
+	}
+	return ""
+}
+
+func registrySpell(actionID ActionID) combatLogSpell {
+	spell := combatLogSpell{id: actionID.SpellID}
+	if metadata, ok := actionID.Metadata(); ok {
+		spell.name = metadata.Name
+		spell.school = metadata.School
+	}
+	return spell
+}
+
+func (spell combatLogSpell) String() string {
+	name := spell.name
+	if name == "" {
+		name = "Spell " + strconv.Itoa(int(spell.id))
+	}
+	return strconv.Itoa(int(spell.id)) + "," + strconv.Quote(name) + "," + fmt.Sprintf("0x%x", combatLogSchoolMask(spell.school))
+}
+
+func parseCombatLogAction(action string) (ActionID, bool) {
+	match := combatLogActionRegex.FindStringSubmatch(action)
+	if match == nil {
+		return ActionID{}, false
+	}
+	id, err := strconv.Atoi(match[2])
+	if err != nil {
+		return ActionID{}, false
+	}
+	tag, _ := strconv.Atoi(match[3])
+
+	actionID := ActionID{Tag: int32(tag)}
+	switch match[1] {
+	case "SpellID":
+		actionID.SpellID = int32(id)
+	case "ItemID":
+		actionID.ItemID = int32(id)
+	default:
+		actionID.OtherID = proto.OtherAction(id)
+	}
+	return actionID, true
+}
+
+func resourceTypeFromLogName(name string) proto.ResourceType {
+	for resourceType, logName := range resourceLogNames {
+		if logName == name {
+			return resourceType
+		}
+	}
+	return proto.ResourceType_ResourceTypeNone
+}
+
+// School bits as used by the game client.
+var combatLogSchoolMasks = []struct {
+	school SpellSchool
+	mask   int
+}{
+	{SpellSchoolPhysical, 0x1},
+	{SpellSchoolHoly, 0x2},
+	{SpellSchoolFire, 0x4},
+	{SpellSchoolNature, 0x8},
+	{SpellSchoolFrost, 0x10},
+	{SpellSchoolShadow, 0x20},
+	{SpellSchoolArcane, 0x40},
+}
+
+func combatLogSchoolMask(school SpellSchool) int {
+	mask := 0
+	for _, schoolMask := range combatLogSchoolMasks {
+		if school.Matches(schoolMask.school) {
+			mask |= schoolMask.mask
+		}
+	}
+	if mask == 0 {
+		mask = 0x1
+	}
+	return mask
+}
+
+func combatLogMissType(outcome HitOutcome) string {
+	if outcome.Matches(OutcomeMiss) {
+		return "MISS"
+	} else if outcome.Matches(OutcomeDodge) {
+		return "DODGE"
+	} else if outcome.Matches(OutcomeParry) {
+		return "PARRY"
+	}
+	return "RESIST"
+}
+
+func combatLogBool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "nil"
+}
+
+func formatCombatLogAmount(amount float64) string {
+	return strconv.Itoa(int(math.Round(amount)))
+}
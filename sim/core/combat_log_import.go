@@ -0,0 +1,433 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// A single player's activity, extracted from a real WotLK combat log.
+type ParsedCombatLog struct {
+	Player string
+
+	// Time from the player's first damage event to their last.
+	Duration time.Duration
+
+	// Observed casts, in order. Times are relative to the player's first damage
+	// event, so casts before the pull have negative times.
+	Casts []CombatLogCast
+
+	Auras   []*CombatLogAura
+	Actions []*CombatLogActionStats
+}
+
+type CombatLogCast struct {
+	Time     time.Duration
+	ActionID ActionID
+}
+
+// Buffs and procs which were active on the player.
+type CombatLogAura struct {
+	ActionID     ActionID
+	Applications int32
+	Uptime       time.Duration
+
+	// True if the player never cast this spell, i.e. the aura came from a proc
+	// or from another player.
+	IsProc bool
+
+	activeSince time.Duration
+	active      bool
+}
+
+// Damage and healing done by the player, per action.
+type CombatLogActionStats struct {
+	ActionID ActionID
+	Casts    int32
+	Hits     int32
+	Crits    int32
+	Misses   int32
+	Damage   float64
+	Healing  float64
+}
+
+func (stats *CombatLogActionStats) AvgHit() float64 {
+	if stats.Hits == 0 {
+		return 0
+	}
+	return (stats.Damage + stats.Healing) / float64(stats.Hits)
+}
+
+type combatLogParser struct {
+	result *ParsedCombatLog
+
+	auras   map[ActionID]*CombatLogAura
+	actions map[ActionID]*CombatLogActionStats
+
+	firstTimestamp time.Duration
+	hasTimestamp   bool
+	combatStart    time.Duration
+	combatEnd      time.Duration
+	inCombat       bool
+}
+
+// Parses the events performed by or on the named player from a
+// WoWCombatLog.txt file.
+func ParseCombatLog(r io.Reader, player string) (*ParsedCombatLog, error) {
+	parser := &combatLogParser{
+		result:  &ParsedCombatLog{Player: player},
+		auras:   make(map[ActionID]*CombatLogAura),
+		actions: make(map[ActionID]*CombatLogActionStats),
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		if err := parser.parseLine(scanner.Text()); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !parser.inCombat {
+		return nil, fmt.Errorf("no damage events found for player %s", player)
+	}
+
+	return parser.finish(), nil
+}
+
+func (parser *combatLogParser) parseLine(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+
+	dateStr, eventStr, ok := strings.Cut(line, "  ")
+	if !ok {
+		return fmt.Errorf("missing timestamp")
+	}
+	t, err := time.Parse("1/2 15:04:05.000", dateStr)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q: %w", dateStr, err)
+	}
+	timestamp := t.Sub(time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC))
+	if !parser.hasTimestamp {
+		parser.firstTimestamp = timestamp
+		parser.hasTimestamp = true
+	}
+	timestamp -= parser.firstTimestamp
+
+	fields := splitCombatLogFields(eventStr)
+	if len(fields) < 7 {
+		return nil
+	}
+	event := fields[0]
+	fromPlayer := fields[2] == parser.result.Player
+	toPlayer := fields[5] == parser.result.Player
+	if !fromPlayer && !toPlayer {
+		return nil
+	}
+
+	var actionID ActionID
+	var suffix []string
+	switch {
+	case strings.HasPrefix(event, "SWING_"):
+		actionID = ActionID{OtherID: proto.OtherAction_OtherActionAttack}
+		suffix = fields[7:]
+	case strings.HasPrefix(event, "SPELL_") || strings.HasPrefix(event, "RANGE_"):
+		if len(fields) < 10 {
+			return nil
+		}
+		spellID, err := strconv.Atoi(fields[7])
+		if err != nil {
+			return fmt.Errorf("invalid spell ID %q", fields[7])
+		}
+		actionID = ActionID{SpellID: int32(spellID)}
+		if spellID == 75 { // Auto Shot
+			actionID = ActionID{OtherID: proto.OtherAction_OtherActionShoot}
+		}
+		suffix = fields[10:]
+	default:
+		return nil
+	}
+
+	switch event {
+	case "SPELL_CAST_SUCCESS":
+		if fromPlayer {
+			parser.result.Casts = append(parser.result.Casts, CombatLogCast{Time: timestamp, ActionID: actionID})
+			parser.getAction(actionID).Casts++
+		}
+	case "SPELL_AURA_APPLIED", "SPELL_AURA_REFRESH":
+		if toPlayer {
+			aura := parser.getAura(actionID)
+			if !aura.active {
+				aura.active = true
+				aura.activeSince = timestamp
+				aura.Applications++
+			}
+		}
+	case "SPELL_AURA_REMOVED":
+		if aura, ok := parser.auras[actionID]; ok && toPlayer && aura.active {
+			aura.active = false
+			aura.Uptime += timestamp - aura.activeSince
+		}
+	case "SWING_DAMAGE", "RANGE_DAMAGE", "SPELL_DAMAGE", "SPELL_PERIODIC_DAMAGE":
+		if fromPlayer && len(suffix) >= 7 {
+			parser.onCombatEvent(timestamp)
+			stats := parser.getAction(actionID)
+			stats.Hits++
+			stats.Damage += parseCombatLogAmount(suffix[0])
+			if suffix[6] == "1" {
+				stats.Crits++
+			}
+		}
+	case "SWING_MISSED", "RANGE_MISSED", "SPELL_MISSED", "SPELL_PERIODIC_MISSED":
+		if fromPlayer {
+			parser.onCombatEvent(timestamp)
+			parser.getAction(actionID).Misses++
+		}
+	case "SPELL_HEAL", "SPELL_PERIODIC_HEAL":
+		if fromPlayer && len(suffix) >= 4 {
+			stats := parser.getAction(actionID)
+			stats.Hits++
+			stats.Healing += parseCombatLogAmount(suffix[0]) - parseCombatLogAmount(suffix[1])
+			if suffix[3] == "1" {
+				stats.Crits++
+			}
+		}
+	}
+	return nil
+}
+
+func (parser *combatLogParser) onCombatEvent(timestamp time.Duration) {
+	if !parser.inCombat {
+		parser.inCombat = true
+		parser.combatStart = timestamp
+	}
+	parser.combatEnd = timestamp
+}
+
+func (parser *combatLogParser) getAura(actionID ActionID) *CombatLogAura {
+	aura, ok := parser.auras[actionID]
+	if !ok {
+		aura = &CombatLogAura{ActionID: actionID}
+		parser.auras[actionID] = aura
+	}
+	return aura
+}
+
+func (parser *combatLogParser) getAction(actionID ActionID) *CombatLogActionStats {
+	stats, ok := parser.actions[actionID]
+	if !ok {
+		stats = &CombatLogActionStats{ActionID: actionID}
+		parser.actions[actionID] = stats
+	}
+	return stats
+}
+
+func (parser *combatLogParser) finish() *ParsedCombatLog {
+	result := parser.result
+	result.Duration = parser.combatEnd - parser.combatStart
+
+	for i := range result.Casts {
+		result.Casts[i].Time -= parser.combatStart
+	}
+
+	for _, aura := range parser.auras {
+		if aura.active {
+			aura.Uptime += parser.combatEnd - aura.activeSince
+			aura.active = false
+		}
+		aura.IsProc = !slices.ContainsFunc(result.Casts, func(cast CombatLogCast) bool { return cast.ActionID == aura.ActionID })
+		result.Auras = append(result.Auras, aura)
+	}
+	for _, stats := range parser.actions {
+		result.Actions = append(result.Actions, stats)
+	}
+
+	byActionID := func(a, b ActionID) int {
+		return strings.Compare(a.String(), b.String())
+	}
+	slices.SortFunc(result.Auras, func(a, b *CombatLogAura) int { return byActionID(a.ActionID, b.ActionID) })
+	slices.SortFunc(result.Actions, func(a, b *CombatLogActionStats) int { return byActionID(a.ActionID, b.ActionID) })
+	return result
+}
+
+// Splits the comma-separated fields of an event, respecting quoted names.
+func splitCombatLogFields(eventStr string) []string {
+	var fields []string
+	var sb strings.Builder
+	inQuotes := false
+	for _, c := range eventStr {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == ',' && !inQuotes:
+			fields = append(fields, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return append(fields, sb.String())
+}
+
+func parseCombatLogAmount(str string) float64 {
+	amount, _ := strconv.ParseFloat(str, 64)
+	return amount
+}
+
+// Builds a priority list which approximates the observed casts. Spells cast
+// rarely relative to their first use are assumed to be cooldown-gated and get
+// the highest priority, while the most frequently cast spell becomes the
+// filler at the bottom of the list.
+func (cl *ParsedCombatLog) BestFitAPL() *proto.APLRotation {
+	type candidate struct {
+		actionID    ActionID
+		medianDelay time.Duration
+		firstCast   time.Duration
+	}
+
+	castTimes := make(map[ActionID][]time.Duration)
+	var order []ActionID
+	for _, cast := range cl.Casts {
+		if cast.Time < 0 {
+			continue
+		}
+		if _, ok := castTimes[cast.ActionID]; !ok {
+			order = append(order, cast.ActionID)
+		}
+		castTimes[cast.ActionID] = append(castTimes[cast.ActionID], cast.Time)
+	}
+
+	candidates := MapSlice(order, func(actionID ActionID) candidate {
+		times := castTimes[actionID]
+		c := candidate{actionID: actionID, firstCast: times[0], medianDelay: cl.Duration}
+		if len(times) > 1 {
+			delays := make([]time.Duration, len(times)-1)
+			for i := range delays {
+				delays[i] = times[i+1] - times[i]
+			}
+			slices.Sort(delays)
+			c.medianDelay = delays[len(delays)/2]
+		}
+		return c
+	})
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		if a.medianDelay != b.medianDelay {
+			return int(b.medianDelay - a.medianDelay)
+		}
+		return int(a.firstCast - b.firstCast)
+	})
+
+	rotation := &proto.APLRotation{
+		Type:           proto.APLRotation_TypeAPL,
+		PrepullActions: cl.prepullActions(),
+	}
+	for _, c := range candidates {
+		rotation.PriorityList = append(rotation.PriorityList, &proto.APLListItem{
+			Action: newCombatLogCastAction(c.actionID),
+		})
+	}
+	return rotation
+}
+
+// Builds a rotation which repeats the exact observed cast sequence. Each cast
+// waits for both its observed time and for the spell to be ready in the sim.
+func (cl *ParsedCombatLog) ReplayAPL() *proto.APLRotation {
+	sequence := &proto.APLActionSequence{Name: "Combat Log Replay"}
+	for _, cast := range cl.Casts {
+		if cast.Time < 0 {
+			continue
+		}
+		action := newCombatLogCastAction(cast.ActionID)
+		action.Condition = &proto.APLValue{Value: &proto.APLValue_Cmp{Cmp: &proto.APLValueCompare{
+			Op:  proto.APLValueCompare_OpGe,
+			Lhs: &proto.APLValue{Value: &proto.APLValue_CurrentTime{CurrentTime: &proto.APLValueCurrentTime{}}},
+			Rhs: newCombatLogDurationValue(cast.Time),
+		}}}
+		sequence.Actions = append(sequence.Actions, action)
+	}
+
+	return &proto.APLRotation{
+		Type:           proto.APLRotation_TypeAPL,
+		PrepullActions: cl.prepullActions(),
+		PriorityList: []*proto.APLListItem{{
+			Action: &proto.APLAction{Action: &proto.APLAction_Sequence{Sequence: sequence}},
+		}},
+	}
+}
+
+func (cl *ParsedCombatLog) prepullActions() []*proto.APLPrepullAction {
+	var prepullActions []*proto.APLPrepullAction
+	for _, cast := range cl.Casts {
+		if cast.Time < 0 {
+			prepullActions = append(prepullActions, &proto.APLPrepullAction{
+				Action:    newCombatLogCastAction(cast.ActionID),
+				DoAtValue: newCombatLogDurationValue(cast.Time),
+			})
+		}
+	}
+	return prepullActions
+}
+
+func newCombatLogCastAction(actionID ActionID) *proto.APLAction {
+	return &proto.APLAction{Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{
+		SpellId: actionID.ToProto(),
+	}}}
+}
+
+func newCombatLogDurationValue(d time.Duration) *proto.APLValue {
+	return &proto.APLValue{Value: &proto.APLValue_Const{Const: &proto.APLValueConst{
+		Val: strconv.FormatFloat(d.Seconds(), 'f', 3, 64) + "s",
+	}}}
+}
+
+// Per-action comparison between a combat log and a replay sim of it.
+type CombatLogComparison struct {
+	ActionID  ActionID
+	LogAvgHit float64
+	SimAvgHit float64
+	LogHits   int32
+	SimHits   float64 // Average per iteration.
+}
+
+// Compares the average hit of each action in the log against a sim's metrics
+// for the same player, e.g. from a sim using ReplayAPL().
+func (cl *ParsedCombatLog) CompareToSim(metrics *proto.UnitMetrics, iterations int32) []CombatLogComparison {
+	var comparisons []CombatLogComparison
+	for _, stats := range cl.Actions {
+		if stats.Hits == 0 {
+			continue
+		}
+		comparison := CombatLogComparison{
+			ActionID:  stats.ActionID,
+			LogAvgHit: stats.AvgHit(),
+			LogHits:   stats.Hits,
+		}
+
+		var simHits int32
+		var simTotal float64
+		for _, action := range metrics.Actions {
+			if ProtoToActionID(action.Id).SameActionIgnoreTag(stats.ActionID) {
+				for _, target := range action.Targets {
+					simHits += target.Hits + target.Crits + target.Glances + target.Blocks
+					simTotal += target.Damage + target.Healing
+				}
+			}
+		}
+		if simHits > 0 {
+			comparison.SimAvgHit = simTotal / float64(simHits)
+			comparison.SimHits = float64(simHits) / float64(max(iterations, 1))
+		}
+		comparisons = append(comparisons, comparison)
+	}
+	return comparisons
+}
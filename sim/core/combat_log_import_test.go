@@ -0,0 +1,73 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testCombatLog = `1/1 19:59:58.500  SPELL_CAST_SUCCESS,0x0000000000000001,"Mage",0x514,0x0000000000000000,nil,0x80000000,55342,"Mirror Image",0x40
+1/1 19:59:58.500  SPELL_AURA_APPLIED,0x0000000000000001,"Mage",0x514,0x0000000000000001,"Mage",0x514,55342,"Mirror Image",0x40,BUFF
+1/1 20:00:00.000  SPELL_CAST_SUCCESS,0x0000000000000001,"Mage",0x514,0xF130000000000002,"Target, The Dummy",0xa48,42833,"Fireball",0x4
+1/1 20:00:00.000  SPELL_DAMAGE,0x0000000000000001,"Mage",0x514,0xF130000000000002,"Target, The Dummy",0xa48,42833,"Fireball",0x4,9000,0,4,0,0,0,1,nil,nil
+1/1 20:00:01.000  SPELL_AURA_APPLIED,0x0000000000000001,"Mage",0x514,0x0000000000000001,"Mage",0x514,48108,"Hot Streak",0x4,BUFF
+1/1 20:00:01.000  SPELL_CAST_SUCCESS,0x0000000000000001,"Mage",0x514,0xF130000000000002,"Target, The Dummy",0xa48,42891,"Pyroblast",0x4
+1/1 20:00:01.000  SPELL_AURA_REMOVED,0x0000000000000001,"Mage",0x514,0x0000000000000001,"Mage",0x514,48108,"Hot Streak",0x4,BUFF
+1/1 20:00:02.000  SPELL_CAST_SUCCESS,0x0000000000000001,"Mage",0x514,0xF130000000000002,"Target, The Dummy",0xa48,42833,"Fireball",0x4
+1/1 20:00:02.000  SPELL_DAMAGE,0x0000000000000001,"Mage",0x514,0xF130000000000002,"Target, The Dummy",0xa48,42891,"Pyroblast",0x4,12000,0,4,0,0,0,nil,nil,nil
+1/1 20:00:04.000  SPELL_CAST_SUCCESS,0x0000000000000001,"Mage",0x514,0xF130000000000002,"Target, The Dummy",0xa48,42833,"Fireball",0x4
+1/1 20:00:04.000  SPELL_MISSED,0x0000000000000001,"Mage",0x514,0xF130000000000002,"Target, The Dummy",0xa48,42833,"Fireball",0x4,RESIST
+1/1 20:00:10.000  SPELL_DAMAGE,0x0000000000000001,"Mage",0x514,0xF130000000000002,"Target, The Dummy",0xa48,42833,"Fireball",0x4,5000,0,4,0,0,0,nil,nil,nil`
+
+func TestParseCombatLog(t *testing.T) {
+	cl, err := ParseCombatLog(strings.NewReader(testCombatLog), "Mage")
+	if err != nil {
+		t.Fatalf("Failed to parse combat log: %s", err)
+	}
+
+	if cl.Duration != 10*time.Second {
+		t.Errorf("Expected duration 10s, got %s", cl.Duration)
+	}
+	if len(cl.Casts) != 5 || cl.Casts[0].Time != -1500*time.Millisecond {
+		t.Errorf("Unexpected casts: %v", cl.Casts)
+	}
+
+	fireball := ActionID{SpellID: 42833}
+	for _, stats := range cl.Actions {
+		if stats.ActionID == fireball {
+			if stats.Casts != 3 || stats.Hits != 2 || stats.Crits != 1 || stats.Misses != 1 || stats.AvgHit() != 7000 {
+				t.Errorf("Unexpected Fireball stats: %+v", stats)
+			}
+		}
+	}
+
+	for _, aura := range cl.Auras {
+		switch aura.ActionID.SpellID {
+		case 48108:
+			if !aura.IsProc || aura.Applications != 1 || aura.Uptime != 0 {
+				t.Errorf("Unexpected Hot Streak aura: %+v", aura)
+			}
+		case 55342:
+			if aura.IsProc || aura.Uptime != 11500*time.Millisecond {
+				t.Errorf("Unexpected Mirror Image aura: %+v", aura)
+			}
+		}
+	}
+
+	// Pyroblast is cast once so it is treated as a cooldown, above filler Fireball.
+	apl := cl.BestFitAPL()
+	if len(apl.PrepullActions) != 1 || len(apl.PriorityList) != 2 {
+		t.Fatalf("Unexpected best fit APL: %v", apl)
+	}
+	if id := apl.PriorityList[0].Action.GetCastSpell().SpellId.GetSpellId(); id != 42891 {
+		t.Errorf("Expected Pyroblast first in best fit APL, got %d", id)
+	}
+
+	replay := cl.ReplayAPL().PriorityList[0].Action.GetSequence()
+	if len(replay.Actions) != 4 {
+		t.Fatalf("Expected 4 replayed casts, got %d", len(replay.Actions))
+	}
+	if val := replay.Actions[3].Condition.GetCmp().Rhs.GetConst().Val; val != "4.000s" {
+		t.Errorf("Expected last replayed cast at 4.000s, got %s", val)
+	}
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestExportCombatLog(t *testing.T) {
+	debugLog := `[0.00] SIM RESET
+[0.00] ----------------------
+[-1.50] [Mage (#1)] Casting {SpellID: 999011} (Cost = 100.000, Cast Time = 1.5s, Effective Time = 1.5s)
+[0.00] [Mage (#1)] Completed cast {SpellID: 999011}
+[0.00] [Mage (#1)] [Target 1] {SpellID: 999011} Crit (10% Resist) for 9000.000 damage. (Threat: 9000.000)
+[0.00] [Target 1] Aura gained: {SpellID: 999012}
+[1.00] [Mage (#1)] Gained 500.000 mana from {SpellID: 999013} (100.000 --> 600.000).
+[1.00] [Mage (#1) - Water Elemental] [Target 1] {OtherID: 3} Dodge. (Threat: 0.000)
+[2.00] [Mage (#1)] [Target 1] {SpellID: 999012} tick Hit for 800.400 damage. (Threat: 800.400)
+[0.00] SIM RESET
+[0.00] [Mage (#1)] Dead`
+
+	var sb strings.Builder
+	if err := ExportCombatLog(strings.NewReader(debugLog), &sb, CombatLogWriterOptions{}); err != nil {
+		t.Fatalf("Export failed: %s", err)
+	}
+
+	mage := `0x0000000000000001,"Mage",0x514`
+	target := `0xF130000000000002,"Target 1",0xa48`
+	pet := `0xF140000000000003,"Water Elemental",0x1114`
+	expected := []string{
+		`1/1 19:59:58.500  SPELL_CAST_START,` + mage + `,0x0000000000000000,nil,0x80000000,999011,"Spell 999011",0x1`,
+		`1/1 20:00:00.000  SPELL_CAST_SUCCESS,` + mage + `,0x0000000000000000,nil,0x80000000,999011,"Spell 999011",0x1`,
+		`1/1 20:00:00.000  SPELL_DAMAGE,` + mage + `,` + target + `,999011,"Spell 999011",0x1,9000,0,1,1000,0,0,1,nil,nil`,
+		`1/1 20:00:00.000  SPELL_AURA_APPLIED,` + target + `,` + target + `,999012,"Spell 999012",0x1,DEBUFF`,
+		`1/1 20:00:01.000  SPELL_ENERGIZE,` + mage + `,` + mage + `,999013,"Spell 999013",0x1,500,0`,
+		`1/1 20:00:01.000  SWING_MISSED,` + pet + `,` + target + `,DODGE`,
+		`1/1 20:00:02.000  SPELL_PERIODIC_DAMAGE,` + mage + `,` + target + `,999012,"Spell 999012",0x1,800,0,1,0,0,0,nil,nil,nil`,
+		`1/1 20:01:02.000  UNIT_DIED,0x0000000000000000,nil,0x80000000,` + mage,
+	}
+	if actual := strings.Split(strings.TrimSpace(sb.String()), "\n"); strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Expected:\n%s\nGot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func TestCombatLogFromLogEvents(t *testing.T) {
+	rsr := whatIfTestRequest()
+	rsr.SimOptions = &proto.SimOptions{Iterations: 2, RandomSeed: 101, Debug: true}
+	rsr.Raid.Parties[0].Players[0].Rotation = fakeDotRotation()
+
+	var sb strings.Builder
+	clw := NewCombatLogWriter(&sb, CombatLogWriterOptions{})
+	result := runSimWithLogEvents(context.Background(), rsr, nil, false, clw.WriteEvent)
+	if err := clw.Close(); err != nil {
+		t.Fatalf("Export failed: %s", err)
+	}
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", result.ErrorResult)
+	}
+	if result.Logs != "" {
+		t.Errorf("Expected logs to only go to the combat log, got %d bytes", len(result.Logs))
+	}
+
+	// The database isn't loaded in tests, so the name comes from the dot's
+	// aura, and the school from the spell. The debuff is logged by the target,
+	// but still gets the caster's school.
+	shaman := `0x0000000000000001,"Shaman",0x514`
+	tick := `SPELL_PERIODIC_DAMAGE,` + shaman + `,0xF130000000000002,"Target 1",0xa48,42,"fakedot",0x20,`
+	var firstIteration, secondIteration bool
+	for _, line := range strings.Split(strings.TrimSpace(sb.String()), "\n") {
+		if !strings.Contains(line, tick) {
+			continue
+		}
+		// 20 second iterations, 1 minute apart.
+		if strings.HasPrefix(line, "1/1 20:00:") {
+			firstIteration = true
+		} else if strings.HasPrefix(line, "1/1 20:01:") {
+			secondIteration = true
+		}
+	}
+	debuff := `SPELL_AURA_APPLIED,0xF130000000000002,"Target 1",0xa48,0xF130000000000002,"Target 1",0xa48,42,"fakedot",0x20,DEBUFF`
+	if !strings.Contains(sb.String(), debuff) {
+		t.Errorf("Expected the fake dot's debuff, got:\n%s", sb.String())
+	}
+	if !firstIteration || !secondIteration {
+		t.Fatalf("Expected fake dot ticks in both iterations, got:\n%s", sb.String())
+	}
+}
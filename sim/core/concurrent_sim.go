@@ -0,0 +1,194 @@
+package core
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Below this many iterations per worker, creating another Simulation costs
+// more than it saves.
+const minIterationsPerWorker = 50
+
+// Number of workers to shard iterations across, see SimOptions.concurrency.
+func (sim *Simulation) numWorkers() int32 {
+	options := sim.Options
+	if !canShardIterations(options) || allocAuditEnabled || sim.lowMemory {
+		return 1
+	}
+	return max(1, min(options.Concurrency, options.Iterations/minIterationsPerWorker))
+}
+
+// Options which need every iteration to run in the same sim, in order.
+func canShardIterations(options *proto.SimOptions) bool {
+	return !(options.Debug || options.DebugFirstIteration || options.Interactive || options.ReplayIteration != nil ||
+		options.TargetConfidence != nil || options.RngDiagnostics || options.ProgressInterval > 0)
+}
+
+// Splits iterations into contiguous ranges of nearly equal size, returned as
+// numShards+1 bounds. Antithetic pairs are never split across shards.
+func shardIterations(iterations int32, numShards int32, antithetic bool) []int32 {
+	step := int32(1)
+	if antithetic {
+		step = 2
+	}
+	steps := (iterations + step - 1) / step
+
+	bounds := make([]int32, numShards+1)
+	for i := int32(1); i <= numShards; i++ {
+		bounds[i] = min(iterations, steps*i/numShards*step)
+	}
+	return bounds
+}
+
+type iterationShard struct {
+	firstIterationDuration time.Duration
+	totalDuration          time.Duration
+	completed              int32
+	cancelled              bool
+}
+
+// Runs iterations [start, end). Each iteration is seeded from its index, so
+// the results don't depend on which worker runs it. Like replays, this relies
+// on units not carrying state from one iteration to the next; the first
+// iteration of each shard starts from a fresh sim, so for units that do (e.g.
+// through accumulated floating point error) it can differ from a serial run.
+func (sim *Simulation) runShard(start int32, end int32, completed *atomic.Int32) iterationShard {
+	var shard iterationShard
+	for i := start; i < end; i++ {
+		if i > start && sim.ctx != nil && sim.ctx.Err() != nil {
+			shard.cancelled = true
+			break
+		}
+
+		// Like in run(), the first iteration uses the initial seed.
+		if i > 0 {
+			sim.reseedRands(int64(i))
+		}
+		sim.runOnce()
+
+		iterDuration := sim.Duration
+		if sim.Encounter.EndFightAtHealth != 0 {
+			iterDuration = sim.CurrentTime
+		}
+		if i == start {
+			shard.firstIterationDuration = iterDuration
+		}
+		shard.totalDuration += iterDuration
+		shard.completed++
+		completed.Add(1)
+	}
+	return shard
+}
+
+// Runs the iterations in shards across a bounded pool of workers, each with
+// its own Simulation and Environment, and merges their metrics into this one
+// once they're all done. This sim runs the first shard.
+func (sim *Simulation) runConcurrent(t0 time.Time) *proto.RaidSimResult {
+	numWorkers := sim.numWorkers()
+	bounds := shardIterations(sim.Options.Iterations, numWorkers, sim.Options.AntitheticSampling)
+
+	workers := make([]*Simulation, numWorkers)
+	shards := make([]iterationShard, numWorkers)
+	panics := make([]interface{}, numWorkers)
+	var completed atomic.Int32
+
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() {
+				if err := recover(); err != nil {
+					panics[i] = fmt.Sprintf("%v\nWorker %d Stack Trace:\n%s", err, i, debug.Stack())
+				}
+			}()
+
+			worker := sim
+			if i > 0 {
+				worker = sim.newWorker()
+				worker.rseed = sim.rseed
+				worker.rand.Seed(sim.rseed)
+			}
+			workers[i] = worker
+			shards[i] = worker.runShard(bounds[i], bounds[i+1], &completed)
+		}(i)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	ticker := time.NewTicker(time.Millisecond * 100)
+	defer ticker.Stop()
+	for waiting := true; waiting; {
+		select {
+		case <-done:
+			waiting = false
+		case <-ticker.C:
+			if sim.ProgressReport != nil {
+				sim.ProgressReport(&proto.ProgressMetrics{TotalIterations: sim.Options.Iterations, CompletedIterations: completed.Load()})
+			}
+		}
+	}
+
+	for _, err := range panics {
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// Merge in shard order, so that per-iteration values stay in order.
+	total := shards[0]
+	for i := 1; i < len(workers); i++ {
+		sim.Environment.mergeMetrics(workers[i].Environment)
+		if sim.outliers != nil {
+			sim.outliers.merge(workers[i].outliers)
+		}
+
+		total.totalDuration += shards[i].totalDuration
+		total.completed += shards[i].completed
+		total.cancelled = total.cancelled || shards[i].cancelled
+	}
+
+	result := sim.newResult("", total.firstIterationDuration, total.totalDuration, total.completed, total.cancelled)
+	sim.finishRun(result, total.completed, t0)
+	return result
+}
+
+// Adds the aggregate metrics of other, which must have been constructed from
+// the same request.
+func (env *Environment) mergeMetrics(other *Environment) {
+	env.Raid.dpsMetrics.merge(&other.Raid.dpsMetrics)
+	env.Raid.hpsMetrics.merge(&other.Raid.hpsMetrics)
+	for i, party := range env.Raid.Parties {
+		party.dpsMetrics.merge(&other.Raid.Parties[i].dpsMetrics)
+		party.hpsMetrics.merge(&other.Raid.Parties[i].hpsMetrics)
+	}
+
+	for i, unit := range env.AllUnits {
+		otherUnit := other.AllUnits[i]
+		unit.Metrics.merge(&otherUnit.Metrics)
+
+		// Auras are matched by ID rather than by index, in case some are only
+		// registered once they're first needed.
+		aurasByID := make(map[ActionID][]*Aura)
+		for _, aura := range unit.auras {
+			aurasByID[aura.metrics.ID] = append(aurasByID[aura.metrics.ID], aura)
+		}
+		for _, otherAura := range otherUnit.auras {
+			matches := aurasByID[otherAura.metrics.ID]
+			if len(matches) == 0 {
+				panic(fmt.Sprintf("Cannot merge metrics of %s, aura %s is missing from the main sim", unit.Label, otherAura.Label))
+			}
+			matches[0].metrics.merge(&otherAura.metrics)
+			aurasByID[otherAura.metrics.ID] = matches[1:]
+		}
+	}
+}
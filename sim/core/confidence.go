@@ -0,0 +1,66 @@
+package core
+
+import (
+	"math"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// z-score for a two-sided 95% confidence interval.
+const confidenceZ95 = 1.96
+
+const defaultMinConfidenceIterations = 100
+
+// Returns the mean and the half-width of its 95% confidence interval.
+func (x *aggregator) confidenceInterval() (float64, float64) {
+	if x.n < 2 {
+		return x.sum / float64(max(x.n, 1)), math.Inf(1)
+	}
+	mean, stdDev := x.meanAndStdDev()
+	// Bessel's correction, since the mean is estimated from the same samples.
+	stdDev *= math.Sqrt(float64(x.n) / float64(x.n-1))
+	return mean, confidenceZ95 * stdDev / math.Sqrt(float64(x.n))
+}
+
+// Whether the raid DPS estimate is precise enough to stop after the given
+// number of completed iterations, with SimOptions.target_confidence.
+func (sim *Simulation) reachedTargetConfidence(completed int32) bool {
+	target := sim.Options.TargetConfidence
+	if target == nil {
+		return false
+	}
+
+	minIterations := target.MinIterations
+	if minIterations <= 0 {
+		minIterations = defaultMinConfidenceIterations
+	}
+	if completed < minIterations {
+		return false
+	}
+
+	// Antithetic iterations only reduce variance as complete pairs. Treating
+	// them as independent overestimates the variance, which errs on the side
+	// of running more iterations.
+	if sim.Options.AntitheticSampling && completed%2 == 1 {
+		return false
+	}
+
+	mean, halfWidth := sim.Raid.dpsMetrics.confidenceInterval()
+	if target.MaxHalfWidth > 0 && halfWidth <= target.MaxHalfWidth {
+		return true
+	}
+	if target.MaxRelativeHalfWidth > 0 && halfWidth <= target.MaxRelativeHalfWidth*math.Abs(mean) {
+		return true
+	}
+	return false
+}
+
+func (sim *Simulation) dpsConfidenceProto(completed int32) *proto.ConfidenceInterval {
+	mean, halfWidth := sim.Raid.dpsMetrics.confidenceInterval()
+	return &proto.ConfidenceInterval{
+		Mean:       mean,
+		HalfWidth:  halfWidth,
+		Iterations: completed,
+		Converged:  sim.reachedTargetConfidence(completed),
+	}
+}
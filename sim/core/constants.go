@@ -18,6 +18,10 @@ const DefenseRatingToChanceReduction = (1.0 / DefenseRatingPerDefense) * MissDod
 
 const ResilienceRatingPerCritDamageReductionPercent = ResilienceRatingPerCritReductionChance / 2.2
 
+// Armor of PvP targets which don't set any, roughly that of a leather or mail
+// wearer in PvP gear.
+const DefaultPvPTargetArmor = 10000.0
+
 // Updated based on formulas supplied by InDebt on WoWSims Discord
 const EnemyAutoAttackAPCoefficient = 1.0 / (14.0 * 177.0)
 
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+
+	"golang.org/x/exp/constraints"
+	goproto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+const (
+	maxConsumableSweepCombos = 10000
+)
+
+// consumablePriceTable holds the price of each swept consumable, by kind.
+type consumablePriceTable struct {
+	flasks          map[proto.Flask]float64
+	battleElixirs   map[proto.BattleElixir]float64
+	guardianElixirs map[proto.GuardianElixir]float64
+	foods           map[proto.Food]float64
+	potions         map[proto.Potions]float64
+}
+
+func newConsumablePriceTable(settings *proto.ConsumableSweepSettings) *consumablePriceTable {
+	table := &consumablePriceTable{
+		flasks:          map[proto.Flask]float64{},
+		battleElixirs:   map[proto.BattleElixir]float64{},
+		guardianElixirs: map[proto.GuardianElixir]float64{},
+		foods:           map[proto.Food]float64{},
+		potions:         map[proto.Potions]float64{},
+	}
+	for _, price := range settings.GetPrices() {
+		switch consumable := price.Consumable.(type) {
+		case *proto.ConsumablePrice_Flask:
+			table.flasks[consumable.Flask] = price.Gold
+		case *proto.ConsumablePrice_BattleElixir:
+			table.battleElixirs[consumable.BattleElixir] = price.Gold
+		case *proto.ConsumablePrice_GuardianElixir:
+			table.guardianElixirs[consumable.GuardianElixir] = price.Gold
+		case *proto.ConsumablePrice_Food:
+			table.foods[consumable.Food] = price.Gold
+		case *proto.ConsumablePrice_Potion:
+			table.potions[consumable.Potion] = price.Gold
+		}
+	}
+	return table
+}
+
+// Returns the gold cost of the given consumes for a single fight.
+func (table *consumablePriceTable) cost(consumes *proto.Consumes) float64 {
+	return table.flasks[consumes.GetFlask()] +
+		table.battleElixirs[consumes.GetBattleElixir()] +
+		table.guardianElixirs[consumes.GetGuardianElixir()] +
+		table.foods[consumes.GetFood()] +
+		table.potions[consumes.GetDefaultPotion()] +
+		table.potions[consumes.GetPrepopPotion()]
+}
+
+// Returns every valid combination of the priced consumables, leaving other
+// consumes as they are in base. Combinations equal to base are skipped, since
+// base is simmed as the equipped result.
+func (table *consumablePriceTable) combos(base *proto.Consumes) ([]*proto.Consumes, error) {
+	if base == nil {
+		base = &proto.Consumes{}
+	}
+
+	var combos []*proto.Consumes
+	for _, flask := range sweepCandidates(proto.Flask_FlaskUnknown, table.flasks) {
+		for _, battleElixir := range sweepCandidates(proto.BattleElixir_BattleElixirUnknown, table.battleElixirs) {
+			for _, guardianElixir := range sweepCandidates(proto.GuardianElixir_GuardianElixirUnknown, table.guardianElixirs) {
+				// Flasks don't stack with elixirs.
+				if flask != proto.Flask_FlaskUnknown && (battleElixir != proto.BattleElixir_BattleElixirUnknown || guardianElixir != proto.GuardianElixir_GuardianElixirUnknown) {
+					continue
+				}
+				for _, food := range sweepCandidates(proto.Food_FoodUnknown, table.foods) {
+					for _, potion := range sweepCandidates(proto.Potions_UnknownPotion, table.potions) {
+						for _, prepopPotion := range sweepCandidates(proto.Potions_UnknownPotion, table.potions) {
+							consumes := goproto.Clone(base).(*proto.Consumes)
+							consumes.Flask = flask
+							consumes.BattleElixir = battleElixir
+							consumes.GuardianElixir = guardianElixir
+							consumes.Food = food
+							consumes.DefaultPotion = potion
+							consumes.PrepopPotion = prepopPotion
+							if goproto.Equal(consumes, base) {
+								continue
+							}
+
+							combos = append(combos, consumes)
+							if len(combos) > maxConsumableSweepCombos {
+								return nil, fmt.Errorf("too many consumable combinations (> %d), not computationally feasible", maxConsumableSweepCombos)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return combos, nil
+}
+
+// Returns none followed by each priced consumable, in a stable order.
+func sweepCandidates[T constraints.Integer](none T, prices map[T]float64) []T {
+	candidates := []T{none}
+	for consumable := range prices {
+		if consumable != none {
+			candidates = append(candidates, consumable)
+		}
+	}
+	sort.Slice(candidates[1:], func(i, j int) bool {
+		return candidates[i+1] < candidates[j+1]
+	})
+	return candidates
+}
+
+// Whether the consumes use none of the kinds which are swept.
+func hasNoSweptConsumables(consumes *proto.Consumes) bool {
+	return consumes.GetFlask() == proto.Flask_FlaskUnknown &&
+		consumes.GetBattleElixir() == proto.BattleElixir_BattleElixirUnknown &&
+		consumes.GetGuardianElixir() == proto.GuardianElixir_GuardianElixirUnknown &&
+		consumes.GetFood() == proto.Food_FoodUnknown &&
+		consumes.GetDefaultPotion() == proto.Potions_UnknownPotion &&
+		consumes.GetPrepopPotion() == proto.Potions_UnknownPotion
+}
+
+// createNewRequestWithConsumes creates a copy of the input RaidSimRequest with the player's
+// consumes replaced.
+func createNewRequestWithConsumes(readonlyInputRequest *proto.RaidSimRequest, consumes *proto.Consumes) *proto.RaidSimRequest {
+	request := goproto.Clone(readonlyInputRequest).(*proto.RaidSimRequest)
+	request.Raid.Parties[0].Players[0].Consumes = consumes
+	return request
+}
+
+// Converts ranked sweep results into combo results, ranked by DPS per gold
+// instead of by DPS. rankedResults includes the base result.
+func rankConsumableSweep(table *consumablePriceTable, rankedResults []*itemSubstitutionSimResult, baseResult *itemSubstitutionSimResult) (*proto.BulkComboResult, []*proto.BulkComboResult) {
+	noneDps := 0.0
+	for _, r := range rankedResults {
+		if hasNoSweptConsumables(r.Request.Raid.Parties[0].Players[0].Consumes) {
+			noneDps = r.Score()
+		}
+	}
+
+	toComboResult := func(r *itemSubstitutionSimResult) *proto.BulkComboResult {
+		um := r.Result.GetRaidMetrics().GetParties()[0].GetPlayers()[0]
+		um.Actions = nil
+		um.Auras = nil
+		um.Resources = nil
+		um.Pets = nil
+
+		consumes := r.Request.Raid.Parties[0].Players[0].Consumes
+		result := &proto.BulkComboResult{
+			UnitMetrics: um,
+			Consumes:    consumes,
+			GoldCost:    table.cost(consumes),
+			DpsGain:     r.Score() - baseResult.Score(),
+		}
+		if result.GoldCost > 0 {
+			result.DpsPerGold = (r.Score() - noneDps) / result.GoldCost
+		}
+		return result
+	}
+
+	equipped := toComboResult(baseResult)
+	results := make([]*proto.BulkComboResult, 0, len(rankedResults))
+	for _, r := range rankedResults {
+		if r.Consumes != nil {
+			results = append(results, toComboResult(r))
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].DpsPerGold != results[j].DpsPerGold {
+			return results[i].DpsPerGold > results[j].DpsPerGold
+		}
+		return results[i].DpsGain > results[j].DpsGain
+	})
+	return equipped, results
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestConsumableSweepCombos(t *testing.T) {
+	table := newConsumablePriceTable(&proto.ConsumableSweepSettings{
+		Prices: []*proto.ConsumablePrice{
+			{Consumable: &proto.ConsumablePrice_Flask{Flask: proto.Flask_FlaskOfTheFrostWyrm}, Gold: 40},
+			{Consumable: &proto.ConsumablePrice_BattleElixir{BattleElixir: proto.BattleElixir_SpellpowerElixir}, Gold: 10},
+			{Consumable: &proto.ConsumablePrice_GuardianElixir{GuardianElixir: proto.GuardianElixir_ElixirOfMightyThoughts}, Gold: 10},
+			{Consumable: &proto.ConsumablePrice_Food{Food: proto.Food_FoodFishFeast}, Gold: 5},
+		},
+	})
+
+	base := &proto.Consumes{Food: proto.Food_FoodFishFeast, PetFood: proto.PetFood_PetFoodKiblersBits}
+	combos, err := table.combos(base)
+	if err != nil {
+		t.Fatalf("Failed to generate combos: %s", err)
+	}
+
+	// (flask alone, or any of 4 elixir combinations) * 2 foods, minus the base.
+	if len(combos) != 9 {
+		t.Fatalf("Expected 9 combos, got %d", len(combos))
+	}
+	for _, consumes := range combos {
+		if consumes.Flask != proto.Flask_FlaskUnknown && consumes.BattleElixir != proto.BattleElixir_BattleElixirUnknown {
+			t.Errorf("Flask combined with an elixir: %v", consumes)
+		}
+		if consumes.PetFood != base.PetFood {
+			t.Errorf("Unswept consumes weren't kept: %v", consumes)
+		}
+	}
+
+	if cost := table.cost(&proto.Consumes{Flask: proto.Flask_FlaskOfTheFrostWyrm, Food: proto.Food_FoodFishFeast}); cost != 45 {
+		t.Errorf("Expected a cost of 45, got %f", cost)
+	}
+}
+
+func TestConsumableSweep(t *testing.T) {
+	// Each consumable adds a fixed amount of DPS, so the cheap food is the best
+	// value even though the flask gives more DPS.
+	fakeRunSim := func(ctx context.Context, rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool) *proto.RaidSimResult {
+		consumes := rsr.Raid.Parties[0].Players[0].Consumes
+		dps := 1000.0
+		if consumes.GetFlask() != proto.Flask_FlaskUnknown {
+			dps += 100
+		}
+		if consumes.GetFood() != proto.Food_FoodUnknown {
+			dps += 50
+		}
+		if progress != nil {
+			close(progress)
+		}
+		return &proto.RaidSimResult{
+			RaidMetrics: &proto.RaidMetrics{
+				Dps: &proto.DistributionMetrics{Avg: dps},
+				Parties: []*proto.PartyMetrics{{
+					Players: []*proto.UnitMetrics{{Dps: &proto.DistributionMetrics{Avg: dps}}},
+				}},
+			},
+		}
+	}
+
+	bulk := &bulkSimRunner{
+		SingleRaidSimRunner: fakeRunSim,
+		Request: &proto.BulkSimRequest{
+			BaseSettings: &proto.RaidSimRequest{
+				Raid: &proto.Raid{
+					Parties: []*proto.Party{{
+						Players: []*proto.Player{{
+							Name:      "Player",
+							Consumes:  &proto.Consumes{Flask: proto.Flask_FlaskOfTheFrostWyrm},
+							Equipment: createEquipmentFromItems(),
+						}},
+					}},
+				},
+				SimOptions: &proto.SimOptions{},
+			},
+			BulkSettings: &proto.BulkSettings{
+				IterationsPerCombo: 1,
+				ConsumableSweep: &proto.ConsumableSweepSettings{
+					Prices: []*proto.ConsumablePrice{
+						{Consumable: &proto.ConsumablePrice_Flask{Flask: proto.Flask_FlaskOfTheFrostWyrm}, Gold: 50},
+						{Consumable: &proto.ConsumablePrice_Food{Food: proto.Food_FoodFishFeast}, Gold: 5},
+					},
+				},
+			},
+		},
+	}
+
+	result, err := bulk.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("BulkSim() returned error: %v", err)
+	}
+
+	if equipped := result.EquippedGearResult; equipped.GoldCost != 50 || equipped.DpsPerGold != 2 {
+		t.Errorf("Unexpected equipped result: %v", equipped)
+	}
+	// Flask + food, food only, and none. Flask only is the base.
+	if len(result.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(result.Results))
+	}
+	if best := result.Results[0]; best.Consumes.Food != proto.Food_FoodFishFeast || best.Consumes.Flask != proto.Flask_FlaskUnknown || best.DpsPerGold != 10 || best.DpsGain != -50 {
+		t.Errorf("Expected food only to rank first, got %v", best)
+	}
+	if last := result.Results[2]; last.GoldCost != 0 || last.DpsPerGold != 0 || last.DpsGain != -100 {
+		t.Errorf("Expected no consumables to rank last, got %v", last)
+	}
+}
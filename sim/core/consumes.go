@@ -467,9 +467,7 @@ func registerPotionCD(agent Agent, consumes *proto.Consumes) {
 	if startingMCD.Spell != nil {
 		startingMCD.Spell.Flags |= SpellFlagPrepullPotion
 		if !character.IsUsingAPL {
-			character.RegisterPrepullAction(-1*time.Second, func(sim *Simulation) {
-				startingMCD.Spell.Cast(sim, nil)
-			})
+			character.RegisterPrepullSpell(-1*time.Second, startingMCD.Spell, nil)
 		}
 	}
 
@@ -511,7 +509,6 @@ func makePotionActivation(potionType proto.Potions, character *Character, potion
 				} else {
 					potionCD.Set(sim.CurrentTime + time.Minute)
 				}
-				character.UpdateMajorCooldowns()
 			}
 		}
 	}
@@ -697,9 +694,10 @@ func makePotionActivationInternal(potionType proto.Potions, character *Character
 				return true
 			},
 			Spell: character.GetOrRegisterSpell(SpellConfig{
-				ActionID: actionID,
-				Flags:    SpellFlagNoOnCastComplete,
-				Cast:     potionCast,
+				ActionID:      actionID,
+				Flags:         SpellFlagNoOnCastComplete,
+				Cast:          potionCast,
+				RandomStreams: []string{"Mighty Rage Potion"},
 				ApplyEffects: func(sim *Simulation, _ *Unit, _ *Spell) {
 					aura.Activate(sim)
 					if character.Class == proto.Class_ClassWarrior {
@@ -807,6 +805,7 @@ func registerConjuredCD(agent Agent, consumes *proto.Consumes) {
 					Duration: time.Minute * 15,
 				},
 			},
+			RandomStreams: []string{"dark rune"},
 			ApplyEffects: func(sim *Simulation, _ *Unit, _ *Spell) {
 				// Restores 900 to 1500 mana. (2 Min Cooldown)
 				manaGain := sim.RollWithLabel(900, 1500, "dark rune")
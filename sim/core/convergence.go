@@ -0,0 +1,143 @@
+package core
+
+import (
+	"math"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Batch sizes picked for SimOptions.convergence when it doesn't set one.
+const (
+	defaultConvergenceBatches      = 50
+	minDefaultConvergenceBatchSize = 10
+)
+
+func convergenceBatchSize(options *proto.SimOptions) int32 {
+	if options.Convergence == nil {
+		return 0
+	}
+	if options.Convergence.BatchSize > 0 {
+		return options.Convergence.BatchSize
+	}
+	return max(minDefaultConvergenceBatchSize, options.Iterations/defaultConvergenceBatches)
+}
+
+// Sums of a distribution's values over batches of consecutive iterations, for
+// SimOptions.convergence. Batches are indexed by iteration, so shards which
+// ran different iterations can be merged.
+type convergenceBatches struct {
+	size   int32
+	sums   []float64
+	counts []int32
+}
+
+func (cb *convergenceBatches) add(size int32, iteration int32, value float64) {
+	cb.size = size
+	batch := int(iteration / size)
+	for len(cb.sums) <= batch {
+		cb.sums = append(cb.sums, 0)
+		cb.counts = append(cb.counts, 0)
+	}
+	cb.sums[batch] += value
+	cb.counts[batch]++
+}
+
+func (cb *convergenceBatches) merge(other *convergenceBatches) {
+	if len(other.counts) == 0 {
+		return
+	}
+	cb.size = other.size
+	for len(cb.sums) < len(other.sums) {
+		cb.sums = append(cb.sums, 0)
+		cb.counts = append(cb.counts, 0)
+	}
+	for i := range other.sums {
+		cb.sums[i] += other.sums[i]
+		cb.counts[i] += other.counts[i]
+	}
+}
+
+func convergenceBatchesFromProto(diagnostics *proto.ConvergenceDiagnostics) convergenceBatches {
+	cb := convergenceBatches{
+		size:   diagnostics.GetBatchSize(),
+		sums:   make([]float64, len(diagnostics.GetBatchMeans())),
+		counts: make([]int32, len(diagnostics.GetBatchMeans())),
+	}
+	for i, mean := range diagnostics.GetBatchMeans() {
+		cb.counts[i] = diagnostics.BatchCounts[i]
+		cb.sums[i] = mean * float64(cb.counts[i])
+	}
+	return cb
+}
+
+// iterationStdDev is the standard deviation of single values, to compare the
+// spread of the batch means against.
+func (cb *convergenceBatches) ToProto(iterationStdDev float64) *proto.ConvergenceDiagnostics {
+	diagnostics := &proto.ConvergenceDiagnostics{
+		BatchSize:   cb.size,
+		BatchMeans:  make([]float64, len(cb.sums)),
+		BatchCounts: make([]int32, len(cb.counts)),
+		RunningMean: make([]float64, len(cb.sums)),
+		HalfWidth:   math.Inf(1),
+	}
+
+	var fullMeans []float64
+	var runningSum float64
+	var runningCount int32
+	for i, sum := range cb.sums {
+		count := cb.counts[i]
+		diagnostics.BatchCounts[i] = count
+		if count > 0 {
+			diagnostics.BatchMeans[i] = sum / float64(count)
+		}
+		if count == cb.size {
+			fullMeans = append(fullMeans, diagnostics.BatchMeans[i])
+		}
+		runningSum += sum
+		runningCount += count
+		if runningCount > 0 {
+			diagnostics.RunningMean[i] = runningSum / float64(runningCount)
+		}
+	}
+
+	k := float64(len(fullMeans))
+	if len(fullMeans) < 2 {
+		return diagnostics
+	}
+
+	var mean float64
+	for _, batchMean := range fullMeans {
+		mean += batchMean
+	}
+	mean /= k
+
+	var sumSq, lagSum float64
+	for i, batchMean := range fullMeans {
+		sumSq += (batchMean - mean) * (batchMean - mean)
+		if i > 0 {
+			lagSum += (batchMean - mean) * (fullMeans[i-1] - mean)
+		}
+	}
+
+	// Bessel's correction, since the mean is estimated from the same batches.
+	batchVariance := sumSq / (k - 1)
+	diagnostics.HalfWidth = confidenceZ95 * math.Sqrt(batchVariance/k)
+	if batchVariance > 0 {
+		diagnostics.EffectiveIterations = k * iterationStdDev * iterationStdDev / batchVariance
+		diagnostics.BatchAutocorrelation = lagSum / sumSq
+	} else {
+		diagnostics.EffectiveIterations = k * float64(cb.size)
+	}
+	if len(fullMeans) >= 3 {
+		diagnostics.Correlated = math.Abs(diagnostics.BatchAutocorrelation) > confidenceZ95/math.Sqrt(k)
+	}
+
+	final := diagnostics.RunningMean[len(diagnostics.RunningMean)-1]
+	diagnostics.Stable = true
+	for _, runningMean := range diagnostics.RunningMean[len(diagnostics.RunningMean)/2:] {
+		if math.Abs(runningMean-final) > diagnostics.HalfWidth {
+			diagnostics.Stable = false
+		}
+	}
+	return diagnostics
+}
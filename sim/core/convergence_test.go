@@ -0,0 +1,71 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestConvergenceDiagnostics(t *testing.T) {
+	rand := NewSplitMix(7)
+	var independent, randomWalk convergenceBatches
+	var independentValues, walkValues aggregator
+	walk := 0.0
+	for i := int32(0); i < 5000; i++ {
+		value := rand.NextFloat64()
+		independent.add(100, i, value)
+		independentValues.add(value)
+
+		walk += value - 0.5
+		randomWalk.add(100, i, walk)
+		walkValues.add(walk)
+	}
+
+	_, stdev := independentValues.meanAndStdDev()
+	diagnostics := independent.ToProto(stdev)
+	if len(diagnostics.RunningMean) != 50 || diagnostics.BatchCounts[49] != 100 {
+		t.Fatalf("Expected 50 full batches, got %v", diagnostics.BatchCounts)
+	}
+	if diagnostics.Correlated || !diagnostics.Stable {
+		t.Fatalf("Expected independent values to be uncorrelated and stable, got %v", diagnostics)
+	}
+	if diagnostics.EffectiveIterations < 2500 || diagnostics.EffectiveIterations > 10000 {
+		t.Fatalf("Expected ~5000 effective iterations, got %f", diagnostics.EffectiveIterations)
+	}
+	if _, halfWidth := independentValues.confidenceInterval(); math.Abs(diagnostics.HalfWidth-halfWidth) > halfWidth/2 {
+		t.Fatalf("Expected a half-width near %f, got %f", halfWidth, diagnostics.HalfWidth)
+	}
+
+	_, stdev = walkValues.meanAndStdDev()
+	diagnostics = randomWalk.ToProto(stdev)
+	if !diagnostics.Correlated || diagnostics.EffectiveIterations > 500 {
+		t.Fatalf("Expected a random walk to be correlated, got %v", diagnostics)
+	}
+}
+
+func TestConvergenceDiagnosticsMatchConcurrentSim(t *testing.T) {
+	request := func(concurrency int32) *proto.RaidSimRequest {
+		return tankSimRequest(&proto.SimOptions{
+			Iterations:  400,
+			RandomSeed:  101,
+			Concurrency: concurrency,
+			Convergence: &proto.ConvergenceOptions{BatchSize: 30},
+		})
+	}
+	serial := RunRaidSim(request(1)).RaidMetrics.Parties[0].Players[0].Dtps.Convergence
+	concurrent := RunRaidSim(request(4)).RaidMetrics.Parties[0].Players[0].Dtps.Convergence
+
+	if len(serial.BatchCounts) != 14 || serial.BatchCounts[13] != 10 {
+		t.Fatalf("Expected 13 full batches and a partial one, got %v", serial.BatchCounts)
+	}
+	for i, count := range serial.BatchCounts {
+		if concurrent.BatchCounts[i] != count || !WithinToleranceFloat64(serial.BatchMeans[i], concurrent.BatchMeans[i], 1e-6) {
+			t.Fatalf("Batch %d: expected %d values averaging %f, got %d averaging %f",
+				i, count, serial.BatchMeans[i], concurrent.BatchCounts[i], concurrent.BatchMeans[i])
+		}
+	}
+	if !WithinToleranceFloat64(serial.HalfWidth, concurrent.HalfWidth, 1e-6) {
+		t.Fatalf("Expected a half-width of %f, got %f", serial.HalfWidth, concurrent.HalfWidth)
+	}
+}
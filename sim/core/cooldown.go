@@ -15,6 +15,15 @@ type Cooldown struct {
 	// Default amount of time after activation before this CD can be used again.
 	// Note that some CDs won't use this, e.g. the GCD.
 	Duration time.Duration
+
+	// If more than 1, this many uses can be stored, which recharge one at a
+	// time. The Timer then holds the time at which the first charge is
+	// available, i.e. once all but one have recharged.
+	MaxCharges int32
+
+	// If set, the duration is reduced by cast speed, e.g. by spell haste, when
+	// the CD is started by a cast.
+	AffectedByCastSpeed bool
 }
 
 func (unit *Unit) NewTimer() *Timer {
@@ -53,9 +62,58 @@ func (timer *Timer) IsReady(sim *Simulation) bool {
 	return time.Duration(*timer) <= sim.CurrentTime
 }
 
-// Puts this CD on cooldown, using the default duration.
+// Puts this CD on cooldown, using the default duration. For CDs with charges,
+// this uses up one charge instead.
 func (cd *Cooldown) Use(sim *Simulation) {
-	*cd.Timer = Timer(sim.CurrentTime + cd.Duration)
+	cd.useAt(sim.CurrentTime, cd.Duration)
+}
+
+// Uses the CD, with a cooldown of the given duration starting at the given
+// time, e.g. after a cast.
+func (cd *Cooldown) useAt(startAt time.Duration, duration time.Duration) {
+	if cd.MaxCharges <= 1 {
+		cd.Set(startAt + duration)
+		return
+	}
+	// Charges which have already recharged beyond the maximum don't count.
+	storedCharges := time.Duration(cd.MaxCharges-1) * cd.Duration
+	cd.Set(max(cd.ReadyAt(), startAt-storedCharges) + duration)
+}
+
+// Duration of the cooldown started when the unit casts a spell with this CD.
+func (cd *Cooldown) castDuration(unit *Unit) time.Duration {
+	if cd.AffectedByCastSpeed {
+		return unit.ApplyCastSpeed(cd.Duration)
+	}
+	return cd.Duration
+}
+
+// Number of uses available right now. CDs without charges have 1 while ready.
+func (cd *Cooldown) Charges(sim *Simulation) int32 {
+	maxCharges := max(1, cd.MaxCharges)
+	if cd.Duration <= 0 {
+		return TernaryInt32(cd.IsReady(sim), maxCharges, 0)
+	}
+	timeToFull := cd.timeToFull(sim)
+	if timeToFull == 0 {
+		return maxCharges
+	}
+	recharging := int32((timeToFull + cd.Duration - 1) / cd.Duration)
+	return max(0, maxCharges-recharging)
+}
+
+// Time until the CD is ready with all of its charges.
+func (cd *Cooldown) timeToFull(sim *Simulation) time.Duration {
+	storedCharges := time.Duration(max(1, cd.MaxCharges)-1) * cd.Duration
+	return max(0, cd.ReadyAt()+storedCharges-sim.CurrentTime)
+}
+
+// Shortens the remaining cooldown by up to amount, and returns the time which
+// was actually saved.
+func (cd *Cooldown) reduce(sim *Simulation, amount time.Duration) time.Duration {
+	saved := max(0, min(amount, cd.timeToFull(sim)))
+	cd.Set(cd.ReadyAt() - saved)
+	return saved
 }
 
 func BothTimersReadyAt(t1 *Timer, t2 *Timer) time.Duration {
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	goproto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Bumped when the exported JSON or Lua layout changes, so addons can tell
+// which plans they understand.
+const cooldownExportVersion = 1
+
+const defaultOpenerSeconds = 10
+
+type cooldownExportAction struct {
+	SpellID int32  `json:"spellId,omitempty"`
+	ItemID  int32  `json:"itemId,omitempty"`
+	Name    string `json:"name"`
+}
+
+type cooldownExportCooldown struct {
+	cooldownExportAction
+	Timings []float64 `json:"timings"`
+}
+
+type cooldownExportCast struct {
+	cooldownExportAction
+	Time float64 `json:"time"`
+}
+
+type cooldownExportPlan struct {
+	Version   int                      `json:"version"`
+	Player    string                   `json:"player"`
+	Duration  float64                  `json:"duration"`
+	Cooldowns []cooldownExportCooldown `json:"cooldowns"`
+	Opener    []cooldownExportCast     `json:"opener"`
+}
+
+func newCooldownExportAction(actionID *proto.ActionID) cooldownExportAction {
+	id := ProtoToActionID(actionID)
+	return cooldownExportAction{
+		SpellID: id.SpellID,
+		ItemID:  id.ItemID,
+		Name:    id.Name(),
+	}
+}
+
+// Runs a single iteration with a timeline, and collects the given player's
+// major cooldown usages and opener from it.
+func exportCooldowns(request *proto.CooldownExportRequest) (result *proto.CooldownExportResult) {
+	defer func() {
+		if err := recover(); err != nil {
+			result = &proto.CooldownExportResult{
+				ErrorResult: fmt.Sprintf("%v\nStack Trace:\n%s", err, debug.Stack()),
+			}
+		}
+	}()
+
+	if request.Request == nil {
+		return &proto.CooldownExportResult{ErrorResult: "No sim request to export from."}
+	}
+	rsr := goproto.Clone(request.Request).(*proto.RaidSimRequest)
+	if rsr.SimOptions == nil {
+		rsr.SimOptions = &proto.SimOptions{}
+	}
+	rsr.SimOptions.Iterations = 1
+	rsr.SimOptions.Debug = false
+	rsr.SimOptions.Timeline = &proto.TimelineOptions{Iterations: 1}
+
+	sim := NewSim(rsr)
+	sim.usePresimDuration(sim.runPresims(rsr))
+	unit := sim.GetUnit(request.Player, nil)
+	if unit == nil || unit.Type != PlayerUnit {
+		return &proto.CooldownExportResult{ErrorResult: "Invalid player."}
+	}
+
+	sim.reseedRands(0)
+	sim.runOnce()
+	duration := sim.Duration
+	if sim.Encounter.EndFightAtHealth != 0 {
+		duration = sim.CurrentTime
+	}
+
+	events, err := DecodeTimeline(unit.Metrics.timeline.ToProto(), 0)
+	if err != nil {
+		panic(err)
+	}
+
+	openerSeconds := request.OpenerSeconds
+	if openerSeconds == 0 {
+		openerSeconds = defaultOpenerSeconds
+	}
+
+	result = &proto.CooldownExportResult{
+		Duration: duration.Seconds(),
+	}
+	cooldowns := make(map[ActionID]*proto.Cooldown)
+	for _, event := range events {
+		if event.Kind != proto.TimelineEventKind_TimelineEventCast {
+			continue
+		}
+		if event.Start < DurationFromSeconds(openerSeconds) {
+			result.Opener = append(result.Opener, &proto.OpenerCast{
+				Id:   event.ActionID.ToProto(),
+				Time: event.Start.Seconds(),
+			})
+		}
+		if spell := unit.GetSpell(event.ActionID); spell == nil || !spell.Flags.Matches(SpellFlagMCD) {
+			continue
+		}
+		cooldown, ok := cooldowns[event.ActionID]
+		if !ok {
+			cooldown = &proto.Cooldown{Id: event.ActionID.ToProto()}
+			cooldowns[event.ActionID] = cooldown
+			result.Cooldowns = append(result.Cooldowns, cooldown)
+		}
+		cooldown.Timings = append(cooldown.Timings, event.Start.Seconds())
+	}
+
+	plan := newCooldownExportPlan(sim.Raid.GetPlayerFromUnit(unit).GetCharacter().Name, result)
+	switch request.Format {
+	case proto.CooldownExportFormat_CooldownExportFormatLua:
+		result.Export = plan.lua()
+	default:
+		data, err := json.Marshal(plan)
+		if err != nil {
+			panic(err)
+		}
+		result.Export = string(data)
+	}
+	return result
+}
+
+func newCooldownExportPlan(player string, result *proto.CooldownExportResult) *cooldownExportPlan {
+	plan := &cooldownExportPlan{
+		Version:   cooldownExportVersion,
+		Player:    player,
+		Duration:  result.Duration,
+		Cooldowns: make([]cooldownExportCooldown, len(result.Cooldowns)),
+		Opener:    make([]cooldownExportCast, len(result.Opener)),
+	}
+	for i, cooldown := range result.Cooldowns {
+		plan.Cooldowns[i] = cooldownExportCooldown{newCooldownExportAction(cooldown.Id), cooldown.Timings}
+	}
+	for i, cast := range result.Opener {
+		plan.Opener[i] = cooldownExportCast{newCooldownExportAction(cast.Id), cast.Time}
+	}
+	return plan
+}
+
+// Formats the plan as a Lua table constructor, with the same keys as the JSON.
+func (plan *cooldownExportPlan) lua() string {
+	luaNumber := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	luaAction := func(action cooldownExportAction) string {
+		var sb strings.Builder
+		if action.SpellID != 0 {
+			sb.WriteString(fmt.Sprintf("spellId = %d, ", action.SpellID))
+		}
+		if action.ItemID != 0 {
+			sb.WriteString(fmt.Sprintf("itemId = %d, ", action.ItemID))
+		}
+		sb.WriteString("name = " + strconv.Quote(action.Name))
+		return sb.String()
+	}
+
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	sb.WriteString(fmt.Sprintf("  version = %d,\n", plan.Version))
+	sb.WriteString(fmt.Sprintf("  player = %s,\n", strconv.Quote(plan.Player)))
+	sb.WriteString(fmt.Sprintf("  duration = %s,\n", luaNumber(plan.Duration)))
+	sb.WriteString("  cooldowns = {\n")
+	for _, cooldown := range plan.Cooldowns {
+		timings := make([]string, len(cooldown.Timings))
+		for i, timing := range cooldown.Timings {
+			timings[i] = luaNumber(timing)
+		}
+		sb.WriteString(fmt.Sprintf("    { %s, timings = { %s } },\n", luaAction(cooldown.cooldownExportAction), strings.Join(timings, ", ")))
+	}
+	sb.WriteString("  },\n")
+	sb.WriteString("  opener = {\n")
+	for _, cast := range plan.Opener {
+		sb.WriteString(fmt.Sprintf("    { %s, time = %s },\n", luaAction(cast.cooldownExportAction), luaNumber(cast.Time)))
+	}
+	sb.WriteString("  },\n")
+	sb.WriteString("}")
+	return sb.String()
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func cooldownExportTestRequest() *proto.RaidSimRequest {
+	request := tankSimRequest(&proto.SimOptions{RandomSeed: 101})
+	request.Encounter.Duration = 300
+	request.Encounter.DurationVariation = 0
+
+	player := request.Raid.Parties[0].Players[0]
+	player.Name = "Orc"
+	player.Race = proto.Race_RaceOrc
+	player.Rotation = &proto.APLRotation{
+		Type: proto.APLRotation_TypeAPL,
+		PriorityList: []*proto.APLListItem{
+			{Action: &proto.APLAction{Action: &proto.APLAction_AutocastOtherCooldowns{AutocastOtherCooldowns: &proto.APLActionAutocastOtherCooldowns{}}}},
+		},
+	}
+	return request
+}
+
+func TestExportCooldowns(t *testing.T) {
+	result := ExportCooldowns(&proto.CooldownExportRequest{
+		Request: cooldownExportTestRequest(),
+		Player:  &proto.UnitReference{Type: proto.UnitReference_Player, Index: 0},
+	})
+	if result.ErrorResult != "" {
+		t.Fatalf("Failed to export cooldowns: %s", result.ErrorResult)
+	}
+
+	// Blood Fury has a 2 minute cooldown, so fits 3 times in 5 minutes.
+	if len(result.Cooldowns) != 1 {
+		t.Fatalf("Expected only Blood Fury, got %v", result.Cooldowns)
+	}
+	bloodFury := result.Cooldowns[0]
+	if bloodFury.Id.GetSpellId() != 33697 {
+		t.Fatalf("Expected Blood Fury, got %v", bloodFury.Id)
+	}
+	expectedTimings := []float64{0, 120, 240}
+	if len(bloodFury.Timings) != len(expectedTimings) {
+		t.Fatalf("Expected timings %v, got %v", expectedTimings, bloodFury.Timings)
+	}
+	for i, timing := range expectedTimings {
+		if !WithinToleranceFloat64(timing, bloodFury.Timings[i], 0.01) {
+			t.Fatalf("Expected timings %v, got %v", expectedTimings, bloodFury.Timings)
+		}
+	}
+	if len(result.Opener) != 1 || result.Opener[0].Id.GetSpellId() != 33697 {
+		t.Fatalf("Expected Blood Fury as the opener, got %v", result.Opener)
+	}
+
+	var plan struct {
+		Version   int
+		Player    string
+		Cooldowns []struct {
+			SpellID int32 `json:"spellId"`
+			Timings []float64
+		}
+	}
+	if err := json.Unmarshal([]byte(result.Export), &plan); err != nil {
+		t.Fatalf("Invalid JSON export: %s", err)
+	}
+	if plan.Version != cooldownExportVersion || plan.Player != "Orc" || len(plan.Cooldowns) != 1 || plan.Cooldowns[0].SpellID != 33697 || len(plan.Cooldowns[0].Timings) != 3 {
+		t.Fatalf("Unexpected JSON export: %s", result.Export)
+	}
+}
+
+func TestExportCooldownsLua(t *testing.T) {
+	result := ExportCooldowns(&proto.CooldownExportRequest{
+		Request:       cooldownExportTestRequest(),
+		Player:        &proto.UnitReference{Type: proto.UnitReference_Player, Index: 0},
+		OpenerSeconds: 1,
+		Format:        proto.CooldownExportFormat_CooldownExportFormatLua,
+	})
+	if result.ErrorResult != "" {
+		t.Fatalf("Failed to export cooldowns: %s", result.ErrorResult)
+	}
+	if !strings.HasPrefix(result.Export, "{\n  version = 1,\n  player = \"Orc\",") {
+		t.Fatalf("Unexpected Lua export: %s", result.Export)
+	}
+	if !strings.Contains(result.Export, "    { spellId = 33697, name = ") || !strings.Contains(result.Export, "timings = { 0, 120, 240 } },\n") {
+		t.Fatalf("Expected Blood Fury timings in Lua export: %s", result.Export)
+	}
+}
+
+func TestExportCooldownsInvalidPlayer(t *testing.T) {
+	result := ExportCooldowns(&proto.CooldownExportRequest{
+		Request: cooldownExportTestRequest(),
+		Player:  &proto.UnitReference{Type: proto.UnitReference_Player, Index: 3},
+	})
+	if result.ErrorResult == "" {
+		t.Fatalf("Expected an error for a missing player")
+	}
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestCooldownCharges(t *testing.T) {
+	sim := NewSim(tankSimRequest(&proto.SimOptions{}))
+	sim.reset()
+
+	cd := Cooldown{Timer: sim.Raid.AllPlayerUnits[0].NewTimer(), Duration: time.Second * 10, MaxCharges: 2}
+	cd.Reset()
+	expectCharges := func(at time.Duration, expected int32) {
+		sim.CurrentTime = at
+		if charges := cd.Charges(sim); charges != expected {
+			t.Fatalf("Expected %d charges at %s, got %d", expected, at, charges)
+		}
+	}
+
+	expectCharges(0, 2)
+	cd.Use(sim)
+	expectCharges(0, 1)
+	if !cd.IsReady(sim) {
+		t.Fatalf("Expected the cooldown to be ready with a charge left")
+	}
+	cd.Use(sim)
+	expectCharges(0, 0)
+	if cd.IsReady(sim) {
+		t.Fatalf("Expected the cooldown to be on cooldown without charges")
+	}
+
+	// Charges recharge one at a time.
+	expectCharges(time.Second*9, 0)
+	expectCharges(time.Second*10, 1)
+	cd.Use(sim)
+	expectCharges(time.Second*10, 0)
+	expectCharges(time.Second*20, 1)
+	expectCharges(time.Second*30, 2)
+	expectCharges(time.Second*60, 2)
+
+	// Recharging doesn't bank time while all charges are available.
+	cd.Use(sim)
+	expectCharges(time.Second*60, 1)
+	expectCharges(time.Second*69, 1)
+	expectCharges(time.Second*70, 2)
+}
+
+func TestCooldownWithoutCharges(t *testing.T) {
+	sim := NewSim(tankSimRequest(&proto.SimOptions{}))
+	sim.reset()
+
+	for _, maxCharges := range []int32{0, 1} {
+		cd := Cooldown{Timer: sim.Raid.AllPlayerUnits[0].NewTimer(), Duration: time.Second * 10, MaxCharges: maxCharges}
+		cd.Reset()
+		sim.CurrentTime = time.Second * 5
+		if cd.Charges(sim) != 1 {
+			t.Fatalf("Expected 1 charge when ready with MaxCharges %d", maxCharges)
+		}
+		cd.Use(sim)
+		if cd.ReadyAt() != time.Second*15 || cd.Charges(sim) != 0 {
+			t.Fatalf("Expected a single use cooldown with MaxCharges %d, ready at %s", maxCharges, cd.ReadyAt())
+		}
+	}
+}
+
+func TestCooldownReductionAndReset(t *testing.T) {
+	sim := NewSim(tankSimRequest(&proto.SimOptions{}))
+	sim.reset()
+
+	unit := sim.Raid.AllPlayerUnits[0]
+	spell := &Spell{Unit: unit, CD: Cooldown{Timer: unit.NewTimer(), Duration: time.Second * 30}}
+	spell.CD.Reset()
+	source := ActionID{SpellID: 42}
+
+	spell.CD.Use(sim)
+	spell.ReduceCooldown(sim, time.Second*10, source)
+	if spell.ReadyAt() != time.Second*20 {
+		t.Fatalf("Expected the cooldown to be ready at 20s, got %s", spell.ReadyAt())
+	}
+	// Only the remaining cooldown counts as saved.
+	sim.CurrentTime = time.Second * 15
+	spell.ReduceCooldown(sim, time.Second*10, source)
+	if !spell.IsReady(sim) {
+		t.Fatalf("Expected the cooldown to be ready")
+	}
+	spell.ReduceCooldown(sim, time.Second*10, source)
+
+	spell.CD.Use(sim)
+	sim.CurrentTime = time.Second * 20
+	spell.ResetCooldown(sim, ActionID{SpellID: 43})
+	if !spell.IsReady(sim) {
+		t.Fatalf("Expected the cooldown to be reset")
+	}
+
+	cdm := unit.Metrics.cooldowns[source]
+	if cdm == nil || cdm.events != 2 || cdm.saved != time.Second*15 {
+		t.Fatalf("Expected 2 reductions saving 15s, got %v", cdm)
+	}
+	cdm = unit.Metrics.cooldowns[ActionID{SpellID: 43}]
+	if cdm == nil || cdm.events != 1 || cdm.saved != time.Second*25 {
+		t.Fatalf("Expected 1 reset saving 25s, got %v", cdm)
+	}
+}
+
+func TestCooldownAffectedByCastSpeed(t *testing.T) {
+	sim := NewSim(tankSimRequest(&proto.SimOptions{}))
+	sim.reset()
+
+	unit := sim.Raid.AllPlayerUnits[0]
+	cd := Cooldown{Timer: unit.NewTimer(), Duration: time.Second * 6}
+	unit.MultiplyCastSpeed(1.5)
+	if duration := cd.castDuration(unit); duration != time.Second*6 {
+		t.Fatalf("Expected an unscaled cooldown of 6s, got %s", duration)
+	}
+	cd.AffectedByCastSpeed = true
+	if duration := cd.castDuration(unit); duration != time.Second*4 {
+		t.Fatalf("Expected a hasted cooldown of 4s, got %s", duration)
+	}
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Refunds part of the cost of spells which land with a given outcome, like
+// Master of Elements refunding mana on crits.
+type CostRefund struct {
+	Label    string
+	ActionID ActionID // Used for the aura, and the refunds' resource metrics.
+
+	// Spells which can trigger a refund. Spells which cost nothing, e.g.
+	// because of Clearcasting, never do.
+	ProcMask        ProcMask
+	ProcMaskExclude ProcMask
+	Outcome         HitOutcome
+	ProcChance      float64 // Defaults to always refunding.
+
+	// Fraction of the cost which is refunded. Negative fractions cost extra
+	// resources instead, like Burnout.
+	Fraction float64
+
+	// Refunds a fraction of the spell's base cost, instead of the cost which
+	// was actually paid.
+	FromDefaultCost bool
+}
+
+// Registers a permanent aura which refunds the costs of this character's
+// spells, see CostRefund. Mana, rage, energy and focus costs can be refunded.
+func (character *Character) RegisterCostRefund(config CostRefund) *Aura {
+	if config.ProcChance == 0 {
+		config.ProcChance = 1
+	}
+	metrics := make(map[proto.ResourceType]*ResourceMetrics)
+
+	return MakePermanent(character.RegisterAura(Aura{
+		Label:    config.Label,
+		ActionID: config.ActionID,
+		Duration: NeverExpires,
+		OnSpellHitDealt: func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
+			if config.ProcMask != ProcMaskUnknown && !spell.ProcMask.Matches(config.ProcMask) {
+				return
+			}
+			if spell.ProcMask.Matches(config.ProcMaskExclude) || !result.Outcome.Matches(config.Outcome) {
+				return
+			}
+			if spell.CurCast.Cost == 0 || !sim.Proc(config.ProcChance, config.Label) {
+				return
+			}
+
+			cost := TernaryFloat64(config.FromDefaultCost, spell.DefaultCast.Cost, spell.CurCast.Cost)
+			spell.refundCost(sim, cost*config.Fraction, config.ActionID, metrics)
+		},
+	}))
+}
+
+// Gives back resources of the type the spell costs, or spends more of them for
+// negative amounts.
+func (spell *Spell) refundCost(sim *Simulation, amount float64, actionID ActionID, metrics map[proto.ResourceType]*ResourceMetrics) {
+	var resourceType proto.ResourceType
+	switch spell.Cost.(type) {
+	case *ManaCost:
+		resourceType = proto.ResourceType_ResourceTypeMana
+	case *RageCost:
+		resourceType = proto.ResourceType_ResourceTypeRage
+	case *EnergyCost:
+		resourceType = proto.ResourceType_ResourceTypeEnergy
+	case *FocusCost:
+		resourceType = proto.ResourceType_ResourceTypeFocus
+	default:
+		return
+	}
+
+	resourceMetrics, ok := metrics[resourceType]
+	if !ok {
+		resourceMetrics = spell.Unit.Metrics.NewResourceMetrics(actionID, resourceType)
+		metrics[resourceType] = resourceMetrics
+	}
+
+	unit := spell.Unit
+	switch resourceType {
+	case proto.ResourceType_ResourceTypeMana:
+		if amount < 0 {
+			unit.SpendMana(sim, -amount, resourceMetrics)
+		} else {
+			unit.AddMana(sim, amount, resourceMetrics)
+		}
+	case proto.ResourceType_ResourceTypeRage:
+		if amount < 0 {
+			unit.SpendRage(sim, -amount, resourceMetrics)
+		} else {
+			unit.AddRage(sim, amount, resourceMetrics)
+		}
+	case proto.ResourceType_ResourceTypeEnergy:
+		if amount < 0 {
+			unit.SpendEnergy(sim, -amount, resourceMetrics)
+		} else {
+			unit.AddEnergy(sim, amount, resourceMetrics)
+		}
+	case proto.ResourceType_ResourceTypeFocus:
+		if amount < 0 {
+			unit.SpendFocus(sim, -amount, resourceMetrics)
+		} else {
+			unit.AddFocus(sim, amount, resourceMetrics)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestCostRefund(t *testing.T) {
+	sim := NewSim(tankSimRequest(&proto.SimOptions{}))
+	sim.reset()
+
+	character := sim.Raid.Parties[0].Players[0].GetCharacter()
+	aura := character.RegisterCostRefund(CostRefund{
+		Label:           "Test Refund",
+		ActionID:        ActionID{SpellID: 42},
+		ProcMaskExclude: ProcMaskMeleeOrRanged,
+		Outcome:         OutcomeCrit,
+		Fraction:        0.3,
+		FromDefaultCost: true,
+	})
+	spell := &Spell{Unit: &character.Unit, ProcMask: ProcMaskSpellDamage, Cost: &ManaCost{}}
+	spell.DefaultCast.Cost = 100
+	spell.CurCast.Cost = 50
+	character.SpendMana(sim, 1000, character.NewManaMetrics(ActionID{SpellID: 43}))
+
+	refund := func(spell *Spell, outcome HitOutcome) float64 {
+		before := character.CurrentMana()
+		aura.OnSpellHitDealt(aura, sim, spell, &SpellResult{Outcome: outcome})
+		return character.CurrentMana() - before
+	}
+
+	if gain := refund(spell, OutcomeHit); gain != 0 {
+		t.Fatalf("Expected no refund for a hit, got %f", gain)
+	}
+	if gain := refund(spell, OutcomeCrit); !WithinToleranceFloat64(30, gain, 1e-9) {
+		t.Fatalf("Expected a 30 mana refund from the base cost, got %f", gain)
+	}
+	melee := &Spell{Unit: &character.Unit, ProcMask: ProcMaskMeleeMHSpecial, Cost: &ManaCost{}}
+	melee.CurCast.Cost = 50
+	if gain := refund(melee, OutcomeCrit); gain != 0 {
+		t.Fatalf("Expected no refund for an excluded spell, got %f", gain)
+	}
+	spell.CurCast.Cost = 0
+	if gain := refund(spell, OutcomeCrit); gain != 0 {
+		t.Fatalf("Expected no refund for a free cast, got %f", gain)
+	}
+
+	var metrics *ResourceMetrics
+	for _, rm := range character.Metrics.resources {
+		if rm.ActionID == (ActionID{SpellID: 42}) {
+			metrics = rm
+		}
+	}
+	if metrics == nil || metrics.Events != 1 || !WithinToleranceFloat64(30, metrics.Gain, 1e-9) {
+		t.Fatalf("Expected 1 refund of 30 mana in the resource metrics, got %v", metrics)
+	}
+}
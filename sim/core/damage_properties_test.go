@@ -0,0 +1,275 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+// Each property registers a spell per case, and units are limited to 100.
+const damagePropertyCases = 80
+
+var damagePropertySchools = []SpellSchool{
+	SpellSchoolPhysical,
+	SpellSchoolArcane,
+	SpellSchoolFire,
+	SpellSchoolFrost,
+	SpellSchoolHoly,
+	SpellSchoolNature,
+	SpellSchoolShadow,
+}
+
+var damagePropertyFlags = []SpellFlag{
+	SpellFlagIgnoreResists,
+	SpellFlagBinary,
+	SpellFlagIgnoreAttackerModifiers,
+	SpellFlagIgnoreTargetModifiers,
+	SpellFlagApplyArmorReduction,
+}
+
+// A random damage calculation: the spell, the caster's stats and multipliers,
+// and the target's resistances.
+type damageCase struct {
+	seed int64
+
+	config     SpellConfig
+	baseDamage float64
+	isPeriodic bool
+
+	casterStats           stats.Stats
+	damageDealtMultiplier float64
+	targetResistance      float64
+	targetArmor           float64
+	damageTakenMultiplier float64
+}
+
+func (c *damageCase) String() string {
+	return fmt.Sprintf("seed %d: school %d, flags %d, damage multiplier %.3f, base damage %.1f, periodic %t, caster multiplier %.3f, target resistance %.0f, target armor %.0f, target multiplier %.3f",
+		c.seed, c.config.SpellSchool, c.config.Flags, c.config.DamageMultiplier, c.baseDamage, c.isPeriodic, c.damageDealtMultiplier, c.targetResistance, c.targetArmor, c.damageTakenMultiplier)
+}
+
+func randomDamageCase(seed int64, actionID ActionID) *damageCase {
+	r := rand.New(rand.NewSource(seed))
+	school := damagePropertySchools[r.Intn(len(damagePropertySchools))]
+	procMask := ProcMaskSpellDamage
+	if school == SpellSchoolPhysical {
+		procMask = ProcMaskMeleeMHSpecial
+	}
+	var flags SpellFlag
+	for _, flag := range damagePropertyFlags {
+		if r.Intn(4) == 0 {
+			flags |= flag
+		}
+	}
+
+	return &damageCase{
+		seed: seed,
+		config: SpellConfig{
+			ActionID:         actionID,
+			SpellSchool:      school,
+			ProcMask:         procMask,
+			Flags:            flags,
+			DamageMultiplier: 0.5 + 1.5*r.Float64(),
+			CritMultiplier:   1.5 + r.Float64(),
+			ThreatMultiplier: 1,
+		},
+		baseDamage: 5000 * r.Float64(),
+		isPeriodic: r.Intn(3) == 0,
+
+		casterStats: stats.Stats{
+			stats.MeleeHit:         r.Float64() * 10 * MeleeHitRatingPerHitChance,
+			stats.MeleeCrit:        r.Float64() * 50 * CritRatingPerCritChance,
+			stats.SpellHit:         r.Float64() * 17 * SpellHitRatingPerHitChance,
+			stats.SpellCrit:        r.Float64() * 50 * CritRatingPerCritChance,
+			stats.Expertise:        r.Float64() * 30 * ExpertisePerQuarterPercentReduction,
+			stats.ArmorPenetration: r.Float64() * 1400,
+			stats.SpellPenetration: r.Float64() * 200,
+		},
+		damageDealtMultiplier: 0.5 + r.Float64(),
+		targetResistance:      r.Float64() * 600,
+		targetArmor:           r.Float64() * 20000,
+		damageTakenMultiplier: 0.5 + r.Float64(),
+	}
+}
+
+// Sets up a sim with a caster and target. Cases are applied to it one after
+// another, see applyDamageCase.
+func newDamagePropertySim() (*Simulation, *Character, *Unit) {
+	sim := NewSim(tankSimRequest(&proto.SimOptions{RandomSeed: 101}))
+	sim.reset()
+	return sim, sim.Raid.Parties[0].Players[0].GetCharacter(), sim.Encounter.TargetUnits[0]
+}
+
+// Registers the case's spell, and applies its stats and multipliers. Returns a
+// function which restores the previous stats and multipliers.
+func applyDamageCase(character *Character, target *Unit, c *damageCase) (*Spell, func()) {
+	spell := character.RegisterSpell(c.config)
+
+	casterStats := character.stats
+	targetStats := target.stats
+	damageDealtMultiplier := character.PseudoStats.DamageDealtMultiplier
+	damageTakenMultiplier := target.PseudoStats.DamageTakenMultiplier
+
+	character.stats = character.stats.Add(c.casterStats)
+	if spell.SpellSchool != SpellSchoolPhysical {
+		target.stats[spell.SpellSchool.ResistanceStat()] = c.targetResistance
+	}
+	target.stats[stats.Armor] = c.targetArmor
+	character.PseudoStats.DamageDealtMultiplier = c.damageDealtMultiplier
+	target.PseudoStats.DamageTakenMultiplier = c.damageTakenMultiplier
+
+	return spell, func() {
+		character.stats = casterStats
+		target.stats = targetStats
+		character.PseudoStats.DamageDealtMultiplier = damageDealtMultiplier
+		target.PseudoStats.DamageTakenMultiplier = damageTakenMultiplier
+	}
+}
+
+func damageCaseOutcome(spell *Spell) OutcomeApplier {
+	if spell.SpellSchool == SpellSchoolPhysical {
+		return spell.OutcomeMeleeSpecialHitAndCrit
+	}
+	return spell.OutcomeMagicHitAndCrit
+}
+
+// Calculates the damage of the case's spell, with the rolls of the given
+// iteration, so that calculations can be compared with the same rolls.
+func calcDamageCase(sim *Simulation, spell *Spell, target *Unit, c *damageCase, iteration int64) SpellResult {
+	sim.reseedRands(iteration)
+	attackerMultiplier := spell.AttackerDamageMultiplier(spell.Unit.AttackTables[target.UnitIndex])
+	result := spell.calcDamageInternal(sim, target, c.baseDamage, attackerMultiplier, c.isPeriodic, damageCaseOutcome(spell))
+	copied := *result
+	spell.DisposeResult(result)
+	return copied
+}
+
+// Checks the property against random damage cases. Fails on the first case
+// which violates it, including its seed so that it can be reproduced.
+func checkDamageProperty(t *testing.T, property func(sim *Simulation, spell *Spell, target *Unit, c *damageCase) error) {
+	sim, character, target := newDamagePropertySim()
+	for i := 0; i < damagePropertyCases; i++ {
+		c := randomDamageCase(int64(i), ActionID{SpellID: int32(100000 + i)})
+		spell, restore := applyDamageCase(character, target, c)
+		err := property(sim, spell, target, c)
+		restore()
+		if err != nil {
+			t.Fatalf("%s\n%s", err, c)
+		}
+	}
+}
+
+func TestDamagePropertyNonNegative(t *testing.T) {
+	checkDamageProperty(t, func(sim *Simulation, spell *Spell, target *Unit, c *damageCase) error {
+		for iteration := int64(0); iteration < 20; iteration++ {
+			result := calcDamageCase(sim, spell, target, c, iteration)
+			if math.IsNaN(result.Damage) || math.IsInf(result.Damage, 0) || result.Damage < 0 {
+				return fmt.Errorf("invalid damage %f", result.Damage)
+			}
+			if !result.Landed() && result.Damage != 0 {
+				return fmt.Errorf("outcome %s dealt %f damage", result.Outcome, result.Damage)
+			}
+			if result.Threat < 0 {
+				return fmt.Errorf("negative threat %f", result.Threat)
+			}
+		}
+		return nil
+	})
+}
+
+func TestDamagePropertyMultiplierMonotonicity(t *testing.T) {
+	checkDamageProperty(t, func(sim *Simulation, spell *Spell, target *Unit, c *damageCase) error {
+		for iteration := int64(0); iteration < 20; iteration++ {
+			before := calcDamageCase(sim, spell, target, c, iteration)
+			spell.DamageMultiplier *= 1.25
+			after := calcDamageCase(sim, spell, target, c, iteration)
+			spell.DamageMultiplier /= 1.25
+
+			if after.Outcome != before.Outcome {
+				return fmt.Errorf("outcome changed from %s to %s with the same rolls", before.Outcome, after.Outcome)
+			}
+			if after.Damage < before.Damage {
+				return fmt.Errorf("damage decreased from %f to %f with a higher multiplier", before.Damage, after.Damage)
+			}
+			// Blocks subtract a flat amount, so only the other outcomes scale.
+			if !before.Outcome.Matches(OutcomeBlock) && !WithinToleranceFloat64(before.Damage*1.25, after.Damage, 1e-6) {
+				return fmt.Errorf("damage scaled from %f to %f, expected a factor of 1.25", before.Damage, after.Damage)
+			}
+		}
+		return nil
+	})
+}
+
+func TestDamagePropertyPartialResistBuckets(t *testing.T) {
+	checkDamageProperty(t, func(sim *Simulation, spell *Spell, target *Unit, c *damageCase) error {
+		if spell.SpellSchool == SpellSchoolPhysical || spell.Flags.Matches(SpellFlagIgnoreResists) {
+			return nil
+		}
+		averageResist := target.averageResist(spell.SpellSchool, spell.Unit)
+		thresholds := target.partialResistRollThresholds(averageResist)
+
+		for iteration := int64(0); iteration < 20; iteration++ {
+			result := calcDamageCase(sim, spell, target, c, iteration)
+			multiplier := result.ResistanceMultiplier
+			if averageResist == 0 {
+				if multiplier != 1 {
+					return fmt.Errorf("resisted %.2f with no average resist", 1-multiplier)
+				}
+				continue
+			}
+			if spell.Flags.Matches(SpellFlagBinary) {
+				if multiplier != 0 && multiplier != 1 {
+					return fmt.Errorf("binary spell partially resisted, multiplier %f", multiplier)
+				}
+				continue
+			}
+
+			bracket := int(math.Round((1 - multiplier) * 10))
+			if !WithinToleranceFloat64(1-0.1*float64(bracket), multiplier, 1e-9) {
+				return fmt.Errorf("resistance multiplier %f isn't a multiple of 10%%", multiplier)
+			}
+			found := false
+			for _, th := range thresholds {
+				if th.bracket == bracket && th.cumulativeChance > 0 {
+					found = true
+				}
+				if th.cumulativeChance >= 1 {
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("rolled a %d%% resist, which isn't among the thresholds %s", bracket*10, thresholds)
+			}
+		}
+		return nil
+	})
+}
+
+func TestDamagePropertyLoggedMatchesUnlogged(t *testing.T) {
+	checkDamageProperty(t, func(sim *Simulation, spell *Spell, target *Unit, c *damageCase) error {
+		// Bonus damage taken is added before armor, so also check that
+		// modifiers are applied in the same order.
+		spell.Flags |= SpellFlagIncludeTargetBonusDamage
+		target.PseudoStats.BonusPhysicalDamageTaken = 100
+		defer func() {
+			spell.Flags &^= SpellFlagIncludeTargetBonusDamage
+			target.PseudoStats.BonusPhysicalDamageTaken = 0
+		}()
+
+		for iteration := int64(0); iteration < 20; iteration++ {
+			unlogged := calcDamageCase(sim, spell, target, c, iteration)
+			sim.startLogging(&EventLog{})
+			logged := calcDamageCase(sim, spell, target, c, iteration)
+			sim.Log = nil
+
+			if logged.Outcome != unlogged.Outcome || logged.Damage != unlogged.Damage {
+				return fmt.Errorf("logged %s for %f damage, but unlogged %s for %f damage", logged.Outcome, logged.Damage, unlogged.Outcome, unlogged.Damage)
+			}
+		}
+		return nil
+	})
+}
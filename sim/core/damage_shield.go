@@ -0,0 +1,55 @@
+package core
+
+type DamageShieldConfig struct {
+	Label    string
+	ActionID ActionID
+
+	SpellSchool SpellSchool
+	Flags       SpellFlag
+
+	// Damage dealt to the attacker, before the owner's damage multipliers.
+	BaseDamage func(sim *Simulation) float64
+
+	// Damage shields roll to hit like spells, and can't crit unless this is
+	// set.
+	CritMultiplier float64
+	CannotMiss     bool
+}
+
+// Registers an aura which deals damage back to attackers whenever its owner is
+// hit by a physical attack, like Thorns or Retribution Aura. The damage is dealt
+// by the owner, so it counts towards the owner's damage and threat. The aura
+// needs to be activated by the caller, e.g. with MakePermanent().
+func (character *Character) RegisterDamageShield(config DamageShieldConfig) *Aura {
+	procSpell := character.RegisterSpell(SpellConfig{
+		ActionID:    config.ActionID,
+		SpellSchool: config.SpellSchool,
+		ProcMask:    ProcMaskEmpty,
+		Flags:       config.Flags,
+
+		DamageMultiplier: 1,
+		CritMultiplier:   config.CritMultiplier,
+		ThreatMultiplier: 1,
+
+		ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {
+			outcome := spell.OutcomeMagicHit
+			if config.CannotMiss {
+				outcome = spell.OutcomeAlwaysHit
+			} else if config.CritMultiplier != 0 {
+				outcome = spell.OutcomeMagicHitAndCrit
+			}
+			spell.CalcAndDealDamage(sim, target, config.BaseDamage(sim), outcome)
+		},
+	})
+
+	return character.RegisterAura(Aura{
+		Label:    config.Label,
+		ActionID: config.ActionID,
+		Duration: NeverExpires,
+		OnSpellHitTaken: func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
+			if result.Landed() && spell.SpellSchool == SpellSchoolPhysical {
+				procSpell.Cast(sim, spell.Unit)
+			}
+		},
+	})
+}
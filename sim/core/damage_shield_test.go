@@ -0,0 +1,44 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestDamageShield(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{Iterations: 10, RandomSeed: 1})
+	request.Raid.Buffs = &proto.RaidBuffs{Thorns: proto.TristateEffect_TristateEffectRegular}
+
+	result := RunRaidSim(request)
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", result.ErrorResult)
+	}
+
+	tank := result.RaidMetrics.Parties[0].Players[0]
+	var landed float64
+	for _, dtm := range tank.DamageTaken {
+		landed += dtm.HitsAvg
+	}
+
+	for _, action := range tank.Actions {
+		if action.Id.GetSpellId() != 53307 {
+			continue
+		}
+		thorns := action.Targets[0]
+		// Each landed physical hit on the tank triggers Thorns, which can miss
+		// but can't crit.
+		if !WithinToleranceFloat64(landed, float64(thorns.Casts)/10, 1e-9) {
+			t.Fatalf("Expected Thorns to trigger on each of %f hits, got %d casts", landed, thorns.Casts)
+		}
+		// Level 83 targets partially resist some of the damage.
+		if thorns.Crits != 0 || thorns.Hits == 0 || thorns.Damage > 73*float64(thorns.Hits) || thorns.Damage < 0.9*73*float64(thorns.Hits) {
+			t.Fatalf("Expected non-crit hits for up to 73 damage each, got %v", thorns)
+		}
+		if thorns.Threat <= 0 {
+			t.Fatalf("Expected Thorns threat to be attributed to the tank")
+		}
+		return
+	}
+	t.Fatalf("Expected Thorns in the tank's actions")
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Generates a resource or a buff from hits taken by a unit, like rage from
+// being hit, or Revenge becoming usable after a block, dodge or parry.
+type DamageTakenGain struct {
+	Label    string
+	ActionID ActionID // Used for the resource metrics.
+
+	// Hits with one of these outcomes trigger a gain. Defaults to every hit,
+	// including misses which did no damage.
+	Outcome    HitOutcome
+	ProcChance float64 // Defaults to always gaining.
+
+	// Bases gains on the damage the hit would have done before the unit's
+	// damage taken modifiers, resistances and avoidance, instead of the damage
+	// actually taken. Dodged or parried hits then still count.
+	PreMitigation bool
+
+	// Resource gained per hit, plus per point of damage. Rage is only gained
+	// while the unit's current power bar is rage, e.g. in bear form.
+	Resource       proto.ResourceType
+	Flat           float64
+	PerDamageTaken float64
+	Amount         func(damage float64) float64 // Optional, replaces Flat and PerDamageTaken.
+
+	// Called for each gain with the damage it was based on, e.g. to activate a
+	// buff.
+	OnGain func(sim *Simulation, spell *Spell, result *SpellResult, damage float64)
+}
+
+// Registers a permanent aura which applies the gain for hits taken by this
+// unit, see DamageTakenGain. Mana, rage, energy and runic power can be gained.
+func (unit *Unit) RegisterDamageTakenGain(config DamageTakenGain) *Aura {
+	if config.ProcChance == 0 {
+		config.ProcChance = 1
+	}
+
+	if config.Amount == nil {
+		config.Amount = func(damage float64) float64 {
+			return config.Flat + damage*config.PerDamageTaken
+		}
+	}
+
+	var metrics *ResourceMetrics
+	var addResource func(sim *Simulation, amount float64)
+	switch config.Resource {
+	case proto.ResourceType_ResourceTypeNone:
+	case proto.ResourceType_ResourceTypeMana:
+		metrics = unit.NewManaMetrics(config.ActionID)
+		addResource = func(sim *Simulation, amount float64) { unit.AddMana(sim, amount, metrics) }
+	case proto.ResourceType_ResourceTypeRage:
+		metrics = unit.NewRageMetrics(config.ActionID)
+		addResource = func(sim *Simulation, amount float64) {
+			if unit.GetCurrentPowerBar() == RageBar {
+				unit.AddRage(sim, amount, metrics)
+			}
+		}
+	case proto.ResourceType_ResourceTypeEnergy:
+		metrics = unit.NewEnergyMetrics(config.ActionID)
+		addResource = func(sim *Simulation, amount float64) { unit.AddEnergy(sim, amount, metrics) }
+	case proto.ResourceType_ResourceTypeRunicPower:
+		metrics = unit.NewRunicPowerMetrics(config.ActionID)
+		addResource = func(sim *Simulation, amount float64) { unit.AddRunicPower(sim, amount, metrics) }
+	default:
+		panic("Unsupported damage taken gain resource for " + config.Label)
+	}
+
+	return MakePermanent(unit.RegisterAura(Aura{
+		Label:    config.Label,
+		Duration: NeverExpires,
+		OnSpellHitTaken: func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
+			if config.Outcome != OutcomeEmpty && !result.Outcome.Matches(config.Outcome) {
+				return
+			}
+			if !sim.Proc(config.ProcChance, config.Label) {
+				return
+			}
+
+			damage := TernaryFloat64(config.PreMitigation, result.PreMitigationDamage, result.Damage)
+			if addResource != nil {
+				addResource(sim, config.Amount(damage))
+			}
+			if config.OnGain != nil {
+				config.OnGain(sim, spell, result, damage)
+			}
+		},
+	}))
+}
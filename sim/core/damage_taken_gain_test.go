@@ -0,0 +1,55 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestDamageTakenGain(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	attack := sim.GetTargetUnit(0).AutoAttacks.MHAuto()
+
+	var gainedFrom []float64
+	aura := fa.RegisterDamageTakenGain(DamageTakenGain{
+		Label:          "Test Gain",
+		ActionID:       ActionID{SpellID: 1},
+		Outcome:        OutcomeBlock | OutcomeDodge,
+		PreMitigation:  true,
+		Resource:       proto.ResourceType_ResourceTypeMana,
+		Flat:           10,
+		PerDamageTaken: 0.1,
+		OnGain: func(sim *Simulation, spell *Spell, result *SpellResult, damage float64) {
+			gainedFrom = append(gainedFrom, damage)
+		},
+	})
+	aura.Activate(sim)
+	metrics := fa.Metrics.resources[len(fa.Metrics.resources)-1]
+
+	// Dodged hits count for their unmitigated damage.
+	aura.OnSpellHitTaken(aura, sim, attack, &SpellResult{Outcome: OutcomeDodge, PreMitigationDamage: 1000})
+	// Only blocks and dodges count.
+	aura.OnSpellHitTaken(aura, sim, attack, &SpellResult{Outcome: OutcomeHit, Damage: 800, PreMitigationDamage: 1000})
+
+	if len(gainedFrom) != 1 || gainedFrom[0] != 1000 {
+		t.Fatalf("Expected a single gain from 1000 damage, got %v", gainedFrom)
+	}
+	if metrics.ActionID.SpellID != 1 || metrics.Events != 1 || metrics.Gain != 110 {
+		t.Fatalf("Expected 110 mana gained in 1 event, got %+v", metrics)
+	}
+}
+
+func TestPreMitigationDamage(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	target := sim.GetTargetUnit(0)
+	target.PseudoStats.DamageTakenMultiplier = 0.5
+
+	// The fake spell has a 1.5 damage multiplier, which is applied before
+	// mitigation.
+	result := fa.Spell.CalcDamage(sim, target, 100, fa.Spell.OutcomeAlwaysHit)
+	if result.PreMitigationDamage != 150 || result.Damage >= 75.0001 {
+		t.Fatalf("Expected 150 damage before mitigation and at most 75 after, got %0.3f and %0.3f", result.PreMitigationDamage, result.Damage)
+	}
+}
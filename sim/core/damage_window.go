@@ -0,0 +1,131 @@
+package core
+
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Scripted windows in which a target takes modified damage, see
+// proto.DamageWindow. Each window is an aura on the target, so it shows up in
+// the timeline and aura metrics.
+type damageWindows struct {
+	windows []*damageWindow // Sorted by start time.
+
+	// Products of the multipliers of the windows which are currently active.
+	multiplier         float64
+	periodicMultiplier float64
+}
+
+type damageWindow struct {
+	start          time.Duration
+	duration       time.Duration
+	multiplier     float64
+	dotsUnaffected bool
+
+	aura *Aura
+}
+
+func (target *Target) registerDamageWindows(configs []*proto.DamageWindow) {
+	if len(configs) == 0 {
+		return
+	}
+
+	dw := &damageWindows{
+		multiplier:         1,
+		periodicMultiplier: 1,
+	}
+	for _, config := range configs {
+		if config.Duration <= 0 {
+			continue
+		}
+		dw.windows = append(dw.windows, &damageWindow{
+			start:          max(DurationFromSeconds(config.StartTime), 0),
+			duration:       DurationFromSeconds(config.Duration),
+			multiplier:     max(config.DamageTakenMultiplier, 0),
+			dotsUnaffected: config.DotsUnaffected,
+		})
+	}
+	slices.SortStableFunc(dw.windows, func(a, b *damageWindow) int {
+		return int(a.start - b.start)
+	})
+
+	for i, window := range dw.windows {
+		window.aura = target.RegisterAura(Aura{
+			Label:    "Damage Window " + strconv.Itoa(i+1),
+			Duration: window.duration,
+			OnGain: func(aura *Aura, sim *Simulation) {
+				dw.update()
+			},
+			OnExpire: func(aura *Aura, sim *Simulation) {
+				dw.update()
+			},
+		})
+	}
+
+	target.damageWindows = dw
+}
+
+func (dw *damageWindows) update() {
+	dw.multiplier = 1
+	dw.periodicMultiplier = 1
+	for _, window := range dw.windows {
+		if !window.aura.IsActive() {
+			continue
+		}
+		dw.multiplier *= window.multiplier
+		if !window.dotsUnaffected {
+			dw.periodicMultiplier *= window.multiplier
+		}
+	}
+}
+
+func (dw *damageWindows) reset(sim *Simulation) {
+	dw.multiplier = 1
+	dw.periodicMultiplier = 1
+	for _, window := range dw.windows {
+		aura := window.aura
+		StartDelayedAction(sim, DelayedActionOptions{
+			DoAt: window.start,
+			OnAction: func(sim *Simulation) {
+				aura.Activate(sim)
+			},
+		})
+	}
+}
+
+// Returns the multiplier for damage taken from the active windows.
+func (dw *damageWindows) damageTakenMultiplier(isPeriodic bool) float64 {
+	if isPeriodic {
+		return dw.periodicMultiplier
+	}
+	return dw.multiplier
+}
+
+// Returns the time until the next window starts, 0 if one is active, or
+// NeverExpires if there are no more windows.
+func (dw *damageWindows) timeToNextWindow(sim *Simulation) time.Duration {
+	for _, window := range dw.windows {
+		if window.aura.IsActive() {
+			return 0
+		}
+		if window.start > sim.CurrentTime {
+			return window.start - sim.CurrentTime
+		}
+	}
+	return NeverExpires
+}
+
+// Returns the time until none of the active windows are active anymore, or 0
+// if there are none.
+func (dw *damageWindows) activeRemainingTime(sim *Simulation) time.Duration {
+	remaining := time.Duration(0)
+	for _, window := range dw.windows {
+		if window.aura.IsActive() {
+			remaining = max(remaining, window.aura.RemainingDuration(sim))
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestDamageWindows(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{})
+	request.Encounter.Targets[0].DamageWindows = []*proto.DamageWindow{
+		{StartTime: 8, Duration: 4, DamageTakenMultiplier: 2, DotsUnaffected: true},
+		{StartTime: 5, Duration: 5},
+	}
+	sim := NewSim(request)
+	sim.reset()
+
+	agent := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	target := sim.Encounter.TargetUnits[0]
+	attackTable := agent.AttackTables[target.UnitIndex]
+	targetRef := UnitReference{fixedUnit: target}
+	multiplier := &APLValueTargetDamageWindowMultiplier{target: targetRef}
+	timeToWindow := &APLValueTargetTimeToDamageWindow{target: targetRef}
+	remainingTime := &APLValueTargetDamageWindowRemainingTime{target: targetRef}
+
+	check := func(at time.Duration, direct float64, periodic float64, toWindow time.Duration, remaining time.Duration) {
+		runSimUntil(sim, at)
+
+		if actual := agent.Spell.TargetDamageMultiplier(attackTable, false); actual != direct || multiplier.GetFloat(sim) != direct {
+			t.Fatalf("Expected a direct damage multiplier of %f at %s, got %f", direct, at, actual)
+		}
+		if actual := agent.Spell.TargetDamageMultiplier(attackTable, true); actual != periodic {
+			t.Fatalf("Expected a periodic damage multiplier of %f at %s, got %f", periodic, at, actual)
+		}
+		if actual := timeToWindow.GetDuration(sim); actual != toWindow {
+			t.Fatalf("Expected the next window in %s at %s, got %s", toWindow, at, actual)
+		}
+		if actual := remainingTime.GetDuration(sim); actual != remaining {
+			t.Fatalf("Expected %s of windows remaining at %s, got %s", remaining, at, actual)
+		}
+	}
+
+	check(time.Second*2, 1, 1, time.Second*3, 0)
+	// Immune, including DoTs.
+	check(time.Second*6, 0, 0, 0, time.Second*4)
+	// Overlapping windows stack.
+	check(time.Second*9, 0, 0, 0, time.Second*3)
+	// Vulnerable, but DoTs tick normally.
+	check(time.Second*11, 2, 1, 0, time.Second)
+	check(time.Second*13, 1, 1, NeverExpires, 0)
+}
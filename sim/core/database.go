@@ -13,6 +13,9 @@ var ItemsByID = map[int32]Item{}
 var GemsByID = map[int32]Gem{}
 var EnchantsByEffectID = map[int32]Enchant{}
 
+// Glyph item IDs, keyed by the ID of the spell the glyph teaches.
+var GlyphItemIDsBySpellID = map[int32]int32{}
+
 func addToDatabase(newDB *proto.SimDatabase) {
 	for _, v := range newDB.Items {
 		if _, ok := ItemsByID[v.Id]; !ok {
@@ -33,6 +36,60 @@ func addToDatabase(newDB *proto.SimDatabase) {
 	}
 }
 
+// Converts the UI's database into the subset of it needed by the sim.
+func SimDatabaseFromUI(db *proto.UIDatabase) *proto.SimDatabase {
+	simDB := &proto.SimDatabase{
+		Items:    make([]*proto.SimItem, len(db.Items)),
+		Enchants: make([]*proto.SimEnchant, len(db.Enchants)),
+		Gems:     make([]*proto.SimGem, len(db.Gems)),
+	}
+
+	for i, item := range db.Items {
+		simDB.Items[i] = &proto.SimItem{
+			Id:               item.Id,
+			Name:             item.Name,
+			Type:             item.Type,
+			ArmorType:        item.ArmorType,
+			WeaponType:       item.WeaponType,
+			HandType:         item.HandType,
+			RangedWeaponType: item.RangedWeaponType,
+			Stats:            item.Stats,
+			GemSockets:       item.GemSockets,
+			SocketBonus:      item.SocketBonus,
+			WeaponDamageMin:  item.WeaponDamageMin,
+			WeaponDamageMax:  item.WeaponDamageMax,
+			WeaponSpeed:      item.WeaponSpeed,
+			SetName:          item.SetName,
+
+			RequiredProfession: item.RequiredProfession,
+			Ilvl:               item.Ilvl,
+			Quality:            item.Quality,
+			Heroic:             item.Heroic,
+			Phase:              item.Phase,
+		}
+	}
+
+	for i, enchant := range db.Enchants {
+		simDB.Enchants[i] = &proto.SimEnchant{
+			EffectId:           enchant.EffectId,
+			Stats:              enchant.Stats,
+			RequiredProfession: enchant.RequiredProfession,
+		}
+	}
+
+	for i, gem := range db.Gems {
+		simDB.Gems[i] = &proto.SimGem{
+			Id:                 gem.Id,
+			Name:               gem.Name,
+			Color:              gem.Color,
+			Stats:              gem.Stats,
+			Unique:             gem.Unique,
+			RequiredProfession: gem.RequiredProfession,
+		}
+	}
+	return simDB
+}
+
 type Item struct {
 	ID        int32
 	Type      proto.ItemType
@@ -48,8 +105,13 @@ type Item struct {
 	Name    string
 	Stats   stats.Stats // Stats applied to wearer
 	Quality proto.ItemQuality
+	Ilvl    int32
+	Heroic  bool
+	Phase   int32
 	SetName string // Empty string if not part of a set.
 
+	RequiredProfession proto.Profession
+
 	GemSockets  []proto.GemColor
 	SocketBonus stats.Stats
 
@@ -77,26 +139,39 @@ func ItemFromProto(pData *proto.SimItem) Item {
 		GemSockets:       pData.GemSockets,
 		SocketBonus:      stats.FromFloatArray(pData.SocketBonus),
 		SetName:          pData.SetName,
+		Ilvl:             pData.Ilvl,
+		Quality:          pData.Quality,
+		Heroic:           pData.Heroic,
+		Phase:            pData.Phase,
+
+		RequiredProfession: pData.RequiredProfession,
 	}
 }
 
 func (item *Item) ToItemSpecProto() *proto.ItemSpec {
-	return &proto.ItemSpec{
+	spec := &proto.ItemSpec{
 		Id:      item.ID,
 		Enchant: item.Enchant.EffectID,
 		Gems:    MapSlice(item.Gems, func(gem Gem) int32 { return gem.ID }),
 	}
+	if dbItem, ok := ItemsByID[item.ID]; ok && dbItem.Ilvl != item.Ilvl {
+		spec.Ilvl = item.Ilvl
+	}
+	return spec
 }
 
 type Enchant struct {
 	EffectID int32 // Used by UI to apply effect to tooltip
 	Stats    stats.Stats
+
+	RequiredProfession proto.Profession
 }
 
 func EnchantFromProto(pData *proto.SimEnchant) Enchant {
 	return Enchant{
-		EffectID: pData.EffectId,
-		Stats:    stats.FromFloatArray(pData.Stats),
+		EffectID:           pData.EffectId,
+		Stats:              stats.FromFloatArray(pData.Stats),
+		RequiredProfession: pData.RequiredProfession,
 	}
 }
 
@@ -105,14 +180,19 @@ type Gem struct {
 	Name  string
 	Stats stats.Stats
 	Color proto.GemColor
+
+	Unique             bool
+	RequiredProfession proto.Profession
 }
 
 func GemFromProto(pData *proto.SimGem) Gem {
 	return Gem{
-		ID:    pData.Id,
-		Name:  pData.Name,
-		Stats: stats.FromFloatArray(pData.Stats),
-		Color: pData.Color,
+		ID:                 pData.Id,
+		Name:               pData.Name,
+		Stats:              stats.FromFloatArray(pData.Stats),
+		Color:              pData.Color,
+		Unique:             pData.Unique,
+		RequiredProfession: pData.RequiredProfession,
 	}
 }
 
@@ -120,6 +200,7 @@ type ItemSpec struct {
 	ID      int32
 	Enchant int32
 	Gems    []int32
+	Ilvl    int32 // Optional, see ScaleItem.
 }
 
 type Equipment [proto.ItemSlot_ItemSlotRanged + 1]Item
@@ -214,6 +295,7 @@ func ProtoToEquipmentSpec(es *proto.EquipmentSpec) EquipmentSpec {
 			ID:      item.Id,
 			Enchant: item.Enchant,
 			Gems:    item.Gems,
+			Ilvl:    item.Ilvl,
 		}
 	}
 	return coreEquip
@@ -227,6 +309,10 @@ func NewItem(itemSpec ItemSpec) Item {
 		panic(fmt.Sprintf("No item with id: %d", itemSpec.ID))
 	}
 
+	if itemSpec.Ilvl != 0 && itemSpec.Ilvl != item.Ilvl {
+		item = ScaleItem(item, itemSpec.Ilvl)
+	}
+
 	if itemSpec.Enchant != 0 {
 		if enchant, ok := EnchantsByEffectID[itemSpec.Enchant]; ok {
 			item.Enchant = enchant
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+var databaseEntityNames = map[proto.DatabaseDiscrepancy_Entity]string{
+	proto.DatabaseDiscrepancy_EntityItem:    "item",
+	proto.DatabaseDiscrepancy_EntityEnchant: "enchant",
+	proto.DatabaseDiscrepancy_EntityGem:     "gem",
+}
+
+// Cross-checks every item, enchant and gem in the equipment against the
+// database. Entries in providedDB, e.g. a database sent along by the UI, count
+// as present, but are reported if their stats differ from the database's.
+// Source is included in each discrepancy, to say where the reference came from.
+func ValidateDatabaseReferences(source string, equipment *proto.EquipmentSpec, providedDB *proto.SimDatabase) []*proto.DatabaseDiscrepancy {
+	providedItems := make(map[int32]*proto.SimItem)
+	providedEnchants := make(map[int32]*proto.SimEnchant)
+	providedGems := make(map[int32]*proto.SimGem)
+	if providedDB != nil {
+		for _, item := range providedDB.Items {
+			providedItems[item.Id] = item
+		}
+		for _, enchant := range providedDB.Enchants {
+			providedEnchants[enchant.EffectId] = enchant
+		}
+		for _, gem := range providedDB.Gems {
+			providedGems[gem.Id] = gem
+		}
+	}
+
+	var discrepancies []*proto.DatabaseDiscrepancy
+	check := func(entity proto.DatabaseDiscrepancy_Entity, id int32, entrySource string, inDB bool, dbStats stats.Stats, provided []float64, isProvided bool) {
+		name := databaseEntityNames[entity]
+		if !inDB && !isProvided {
+			discrepancies = append(discrepancies, &proto.DatabaseDiscrepancy{
+				Kind:    proto.DatabaseDiscrepancy_KindMissing,
+				Entity:  entity,
+				Id:      id,
+				Source:  entrySource,
+				Message: fmt.Sprintf("No %s with id %d.", name, id),
+			})
+		} else if inDB && isProvided && stats.FromFloatArray(provided) != dbStats {
+			discrepancies = append(discrepancies, &proto.DatabaseDiscrepancy{
+				Kind:    proto.DatabaseDiscrepancy_KindStatMismatch,
+				Entity:  entity,
+				Id:      id,
+				Source:  entrySource,
+				Message: fmt.Sprintf("Stats of %s %d are %v, but %v in the database.", name, id, stats.FromFloatArray(provided), dbStats),
+			})
+		}
+	}
+
+	for i, itemSpec := range equipment.GetItems() {
+		if itemSpec == nil || itemSpec.Id == 0 {
+			continue
+		}
+		itemSource := fmt.Sprintf("%s %s", source, proto.ItemSlot(i))
+
+		item, inDB := ItemsByID[itemSpec.Id]
+		providedItem, isProvided := providedItems[itemSpec.Id]
+		check(proto.DatabaseDiscrepancy_EntityItem, itemSpec.Id, itemSource, inDB, item.Stats, providedItem.GetStats(), isProvided)
+
+		if itemSpec.Enchant != 0 {
+			enchant, inDB := EnchantsByEffectID[itemSpec.Enchant]
+			providedEnchant, isProvided := providedEnchants[itemSpec.Enchant]
+			check(proto.DatabaseDiscrepancy_EntityEnchant, itemSpec.Enchant, itemSource+" enchant", inDB, enchant.Stats, providedEnchant.GetStats(), isProvided)
+		}
+
+		for j, gemID := range itemSpec.Gems {
+			if gemID == 0 {
+				continue
+			}
+			gem, inDB := GemsByID[gemID]
+			providedGem, isProvided := providedGems[gemID]
+			check(proto.DatabaseDiscrepancy_EntityGem, gemID, fmt.Sprintf("%s gem %d", itemSource, j), inDB, gem.Stats, providedGem.GetStats(), isProvided)
+		}
+	}
+	return discrepancies
+}
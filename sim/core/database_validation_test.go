@@ -0,0 +1,46 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestValidateDatabaseReferences(t *testing.T) {
+	addToDatabase(&proto.SimDatabase{
+		Items: []*proto.SimItem{{Id: 991001}},
+		Gems:  []*proto.SimGem{{Id: 991002, Stats: stats.Stats{stats.SpellPower: 23}.ToFloatArray()}},
+	})
+
+	equipment := &proto.EquipmentSpec{Items: []*proto.ItemSpec{
+		{Id: 991001, Enchant: 991003, Gems: []int32{991002, 0, 991004}},
+		{},
+		{Id: 991005},
+	}}
+	providedDB := &proto.SimDatabase{
+		Items: []*proto.SimItem{{Id: 991005}},
+		Gems:  []*proto.SimGem{{Id: 991002, Stats: stats.Stats{stats.SpellPower: 19}.ToFloatArray()}},
+	}
+
+	expected := []struct {
+		kind   proto.DatabaseDiscrepancy_Kind
+		entity proto.DatabaseDiscrepancy_Entity
+		id     int32
+		source string
+	}{
+		{proto.DatabaseDiscrepancy_KindMissing, proto.DatabaseDiscrepancy_EntityEnchant, 991003, "test ItemSlotHead enchant"},
+		{proto.DatabaseDiscrepancy_KindStatMismatch, proto.DatabaseDiscrepancy_EntityGem, 991002, "test ItemSlotHead gem 0"},
+		{proto.DatabaseDiscrepancy_KindMissing, proto.DatabaseDiscrepancy_EntityGem, 991004, "test ItemSlotHead gem 2"},
+	}
+
+	actual := ValidateDatabaseReferences("test", equipment, providedDB)
+	if len(actual) != len(expected) {
+		t.Fatalf("Expected %d discrepancies, got %v", len(expected), actual)
+	}
+	for i, e := range expected {
+		if a := actual[i]; a.Kind != e.kind || a.Entity != e.entity || a.Id != e.id || a.Source != e.source {
+			t.Errorf("Expected %v %v %d from %q, got %v", e.kind, e.entity, e.id, e.source, a)
+		}
+	}
+}
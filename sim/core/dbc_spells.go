@@ -0,0 +1,126 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// Spell values from the game client's DBC files, generated into
+// dbc_spells_gen.go by:
+//
+//	go run ./tools/database/gen_db -outDir=assets -gen=dbc-spells
+//
+// Only spells the sim registers are included. Damage formulas are hand-coded
+// in each spell's ApplyEffects, so these are a reference for implementing
+// spells and for catching hand-coded values which drift from the client.
+type DBCSpellData struct {
+	Rank         int32         // 0 for spells without ranks.
+	MissileSpeed float64       // Yards per second, 0 for instant spells.
+	Duration     time.Duration // 0 if the spell has no duration.
+	Effects      []DBCSpellEffect
+}
+
+type DBCSpellEffect struct {
+	Index  int32
+	Effect int32 // SPELL_EFFECT_* in the client, e.g. 2 for school damage.
+	Aura   int32 // SPELL_AURA_* for aura effects, e.g. 3 for periodic damage.
+
+	// Base damage (or healing, etc) before any coefficients.
+	BasePointsMin float64
+	BasePointsMax float64
+
+	BonusCoefficient float64 // Spell power coefficient.
+	APCoefficient    float64 // Attack power coefficient.
+
+	Period time.Duration // Tick length of periodic auras.
+}
+
+// Returns the DBC values for a spell, if it was generated.
+func DBCSpell(spellID int32) (DBCSpellData, bool) {
+	data, ok := dbcSpellData[spellID]
+	return data, ok
+}
+
+// Returns the effect with the given index, e.g. to look up the base damage
+// and coefficients of a specific rank.
+func (data DBCSpellData) Effect(index int32) (DBCSpellEffect, bool) {
+	for _, effect := range data.Effects {
+		if effect.Index == index {
+			return effect, true
+		}
+	}
+	return DBCSpellEffect{}, false
+}
+
+// Spells whose values intentionally differ from the DBC, e.g. because talents
+// or glyphs are folded into the spell's config, with the reason why.
+var dbcDriftExceptions = map[int32]string{}
+
+// Returns a description of each hand-coded spell value on the unit which
+// doesn't match the DBC. Only values set on the unit are compared, so spells
+// which ignore e.g. travel time don't count as drift.
+func (unit *Unit) dbcSpellDrift() []string {
+	var drift []string
+	addDrift := func(actionID ActionID, what string, actual any, expected any) {
+		drift = append(drift, fmt.Sprintf("%s %s (%s) is %v, but %v in the DBC.", unit.Label, actionID.Name(), what, actual, expected))
+	}
+
+	for _, spell := range unit.Spellbook {
+		data, ok := dbcSpellData[spell.SpellID]
+		if !ok {
+			continue
+		}
+		if _, ok := dbcDriftExceptions[spell.SpellID]; ok {
+			continue
+		}
+
+		if spell.MissileSpeed != 0 && data.MissileSpeed != 0 && spell.MissileSpeed != data.MissileSpeed {
+			addDrift(spell.ActionID, "missile speed", spell.MissileSpeed, data.MissileSpeed)
+		}
+
+		dot := spell.aoeDot
+		for _, targetDot := range spell.dots {
+			if dot != nil {
+				break
+			}
+			dot = targetDot
+		}
+		if dot == nil {
+			continue
+		}
+		if period := data.periodicEffectPeriod(); period != 0 && dot.TickLength != period {
+			addDrift(spell.ActionID, "tick length", dot.TickLength, period)
+		}
+		if duration := time.Duration(dot.NumberOfTicks) * dot.TickLength; data.Duration != 0 && duration != data.Duration {
+			addDrift(spell.ActionID, "dot duration", duration, data.Duration)
+		}
+	}
+
+	for _, aura := range unit.auras {
+		data, ok := dbcSpellData[aura.ActionID.SpellID]
+		if !ok || data.Duration == 0 {
+			continue
+		}
+		if _, ok := dbcDriftExceptions[aura.ActionID.SpellID]; ok {
+			continue
+		}
+		// Dots compute their own duration when applied.
+		if aura.Duration == 0 || aura.Duration == NeverExpires {
+			continue
+		}
+		if aura.Duration != data.Duration {
+			addDrift(aura.ActionID, "aura duration", aura.Duration, data.Duration)
+		}
+	}
+
+	return drift
+}
+
+func (data DBCSpellData) periodicEffectPeriod() time.Duration {
+	for _, effect := range data.Effects {
+		if effect.Period != 0 {
+			return effect.Period
+		}
+	}
+	return 0
+}
@@ -0,0 +1,35 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDBCSpellDrift(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+
+	dbcSpellData[42] = DBCSpellData{
+		Duration: time.Second * 18,
+		Effects:  []DBCSpellEffect{{Effect: 6, Aura: 3, Period: time.Second * 3}},
+	}
+	t.Cleanup(func() { delete(dbcSpellData, 42) })
+
+	if drift := fa.dbcSpellDrift(); len(drift) != 0 {
+		t.Fatalf("Expected no drift, got %v", drift)
+	}
+
+	dbcSpellData[42] = DBCSpellData{
+		Duration: time.Second * 15,
+		Effects:  []DBCSpellEffect{{Effect: 6, Aura: 3, Period: time.Second * 3}},
+	}
+	if drift := fa.dbcSpellDrift(); len(drift) != 1 {
+		t.Fatalf("Expected the dot duration to drift, got %v", drift)
+	}
+
+	dbcDriftExceptions[42] = "Test"
+	t.Cleanup(func() { delete(dbcDriftExceptions, 42) })
+	if drift := fa.dbcSpellDrift(); len(drift) != 0 {
+		t.Fatalf("Expected exceptions to be skipped, got %v", drift)
+	}
+}
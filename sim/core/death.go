@@ -0,0 +1,219 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+const defaultBattleResHealthPercent = 0.2
+
+// Handles a character's health reaching 0. Deaths are always recorded in
+// metrics, but the character only actually stops acting if deaths are enabled
+// in its DeathOptions.
+func (character *Character) die(sim *Simulation) {
+	if character.dead {
+		return
+	}
+	character.dead = true
+	character.deathTime = sim.CurrentTime
+	character.Metrics.Died = true
+	character.Metrics.Deaths++
+
+	if sim.Log != nil {
+		character.Log(sim, "Dead")
+	}
+
+	options := character.deathOptions
+	if options == nil || !options.Enabled {
+		return
+	}
+
+	character.enabled = false
+	character.CancelGCDTimer(sim)
+	character.AutoAttacks.CancelAutoSwing(sim)
+	character.interruptCasts(sim, false)
+	character.cancelDots(sim)
+
+	// Permanent auras are modeled as passives, so only drop temporary effects.
+	character.dropTemporaryAuras(sim)
+	character.ClearThreat(sim)
+
+	for _, pet := range character.Pets {
+		if pet.IsEnabled() {
+			pet.Disable(sim)
+		}
+	}
+
+	if options.BattleResDelay > 0 && (options.MaxBattleRes == 0 || character.battleResUsed < options.MaxBattleRes) {
+		character.battleResUsed++
+		StartDelayedAction(sim, DelayedActionOptions{
+			DoAt: sim.CurrentTime + DurationFromSeconds(options.BattleResDelay),
+			OnAction: func(sim *Simulation) {
+				character.resurrect(sim)
+			},
+		})
+	}
+}
+
+// Stops the character's DoTs and HoTs on every unit.
+func (character *Character) cancelDots(sim *Simulation) {
+	for _, spell := range character.Spellbook {
+		for _, dot := range spell.dots {
+			if dot != nil {
+				dot.Cancel(sim)
+			}
+		}
+		if spell.aoeDot != nil {
+			spell.aoeDot.Cancel(sim)
+		}
+	}
+}
+
+func (character *Character) dropTemporaryAuras(sim *Simulation) {
+restart:
+	for _, aura := range character.activeAuras {
+		if aura.Duration != NeverExpires {
+			aura.Deactivate(sim)
+			goto restart // activeAuras have changed
+		}
+	}
+}
+
+// Brings a dead character back into the fight.
+func (character *Character) resurrect(sim *Simulation) {
+	if !character.dead {
+		return
+	}
+	character.dead = false
+	character.Metrics.TimeDead += sim.CurrentTime - character.deathTime
+
+	healthPercent := character.deathOptions.BattleResHealthPercent
+	if healthPercent == 0 {
+		healthPercent = defaultBattleResHealthPercent
+	}
+	character.GainHealth(sim, character.MaxHealth()*healthPercent, character.battleResHealthMetrics)
+
+	if sim.Log != nil {
+		character.Log(sim, "Resurrected")
+	}
+
+	character.enabled = true
+	character.SetGCDTimer(sim, sim.CurrentTime)
+	character.AutoAttacks.EnableAutoSwing(sim)
+
+	// Permanent pets are resummoned along with their owner.
+	for i, pet := range character.Pets {
+		if pet.enabledOnStart && !pet.IsEnabled() {
+			pet.Enable(sim, character.PetAgents[i])
+		}
+	}
+}
+
+func (character *Character) IsDead() bool {
+	return character.dead
+}
+
+// Must be called after trackChanceOfDeath, which already tracks health for
+// tanks with a healing model.
+func (character *Character) enableDeath(options *proto.DeathOptions) {
+	character.deathOptions = options
+	if options == nil || !options.Enabled {
+		return
+	}
+	if options.BattleResDelay > 0 {
+		character.battleResHealthMetrics = character.NewHealthMetrics(ActionID{SpellID: 48477}) // Rebirth
+	}
+	if character.HasAura(ChanceOfDeathAuraLabel) {
+		return
+	}
+
+	onDamageTaken := func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
+		if result.Damage > 0 && character.IsEnabled() {
+			character.RemoveHealth(sim, result.Damage)
+			if character.CurrentHealth() <= 0 {
+				character.die(sim)
+			}
+		}
+	}
+
+	character.RegisterAura(Aura{
+		Label:    "Death Tracker",
+		Duration: NeverExpires,
+		OnReset: func(aura *Aura, sim *Simulation) {
+			aura.Activate(sim)
+		},
+		OnSpellHitTaken:       onDamageTaken,
+		OnPeriodicDamageTaken: onDamageTaken,
+	})
+}
+
+func (character *Character) resetDeath(sim *Simulation) {
+	character.dead = false
+	character.deathTime = 0
+	character.battleResUsed = 0
+}
+
+// This should be called when a Sim iteration is complete, before metrics are
+// aggregated.
+func (character *Character) doneIterationDeath(sim *Simulation) {
+	if character.dead && character.deathOptions != nil && character.deathOptions.Enabled {
+		character.Metrics.TimeDead += max(0, sim.CurrentTime-character.deathTime)
+	}
+}
+
+// Per-iteration death statistics, aggregated across iterations.
+type deathMetrics struct {
+	deathsSum    int32
+	timeDeadSum  time.Duration
+	dpsWithDeath aggregator
+	dpsNoDeath   aggregator
+}
+
+func (dm *deathMetrics) doneIteration(unitMetrics *UnitMetrics, dps float64) {
+	dm.deathsSum += unitMetrics.Deaths
+	dm.timeDeadSum += unitMetrics.TimeDead
+	if unitMetrics.Died {
+		dm.dpsWithDeath.add(dps)
+	} else {
+		dm.dpsNoDeath.add(dps)
+	}
+}
+
+func (dm *deathMetrics) merge(other *deathMetrics) {
+	dm.deathsSum += other.deathsSum
+	dm.timeDeadSum += other.timeDeadSum
+	dm.dpsWithDeath = *dm.dpsWithDeath.merge(&other.dpsWithDeath)
+	dm.dpsNoDeath = *dm.dpsNoDeath.merge(&other.dpsNoDeath)
+}
+
+func (dm *deathMetrics) ToProto() *proto.DeathMetrics {
+	n := float64(dm.dpsWithDeath.n + dm.dpsNoDeath.n)
+	if n == 0 {
+		return &proto.DeathMetrics{}
+	}
+
+	var withDeathAvg, noDeathAvg float64
+	if dm.dpsWithDeath.n > 0 {
+		withDeathAvg, _ = dm.dpsWithDeath.meanAndStdDev()
+	}
+	if dm.dpsNoDeath.n > 0 {
+		noDeathAvg, _ = dm.dpsNoDeath.meanAndStdDev()
+	}
+
+	// Between-group term from the law of total variance, i.e. the part of the
+	// DPS variance explained by whether or not the unit died.
+	p := float64(dm.dpsWithDeath.n) / n
+	varianceFromDeaths := 0.0
+	if dm.dpsWithDeath.n > 0 && dm.dpsNoDeath.n > 0 {
+		varianceFromDeaths = p * (1 - p) * (withDeathAvg - noDeathAvg) * (withDeathAvg - noDeathAvg)
+	}
+
+	return &proto.DeathMetrics{
+		DeathsAvg:             float64(dm.deathsSum) / n,
+		SecondsDeadAvg:        dm.timeDeadSum.Seconds() / n,
+		DpsWithDeathAvg:       withDeathAvg,
+		DpsWithoutDeathAvg:    noDeathAvg,
+		DpsVarianceFromDeaths: varianceFromDeaths,
+	}
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func deathTestSim(request *proto.RaidSimRequest, options *proto.DeathOptions) (*Simulation, *FakeAgent) {
+	player := request.Raid.Parties[0].Players[0]
+	player.DeathOptions = options
+	player.BonusStats = &proto.UnitStats{Stats: make([]float64, stats.Len)}
+	player.BonusStats.Stats[stats.Health] = 10000
+	sim := NewSim(request)
+	sim.reset()
+	sim.PrePull()
+	return sim, sim.Raid.Parties[0].Players[0].(*FakeAgent)
+}
+
+func TestNonTankDeath(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{RandomSeed: 101})
+	request.Raid.Tanks = nil
+	request.Raid.Parties[0].Players[0].HealingModel = nil
+	sim, fa := deathTestSim(request, &proto.DeathOptions{Enabled: true})
+	target := sim.GetTargetUnit(0)
+
+	fa.Dot.Apply(sim)
+	hit := func(damage float64) {
+		fa.OnSpellHitTaken(sim, target.AutoAttacks.MHAuto(), &SpellResult{Target: &fa.Unit, Outcome: OutcomeHit, Damage: damage})
+	}
+
+	hit(fa.MaxHealth() / 2)
+	if fa.IsDead() || fa.CurrentHealth() != fa.MaxHealth()/2 {
+		t.Fatalf("Expected half health left, got %0.0f of %0.0f", fa.CurrentHealth(), fa.MaxHealth())
+	}
+
+	hit(fa.MaxHealth())
+	if !fa.IsDead() || fa.IsEnabled() || fa.Metrics.Deaths != 1 {
+		t.Fatalf("Expected the player to die from encounter damage")
+	}
+	if fa.Dot.IsActive() {
+		t.Errorf("Expected the dead player's DoTs to stop")
+	}
+
+	// Dead players don't take more damage.
+	hit(fa.MaxHealth())
+	if fa.Metrics.Deaths != 1 {
+		t.Errorf("Expected 1 death, got %d", fa.Metrics.Deaths)
+	}
+}
+
+func TestNonTankWithoutDeathOptions(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{RandomSeed: 101})
+	request.Raid.Tanks = nil
+	request.Raid.Parties[0].Players[0].HealingModel = nil
+	sim, fa := deathTestSim(request, nil)
+
+	fa.OnSpellHitTaken(sim, sim.GetTargetUnit(0).AutoAttacks.MHAuto(), &SpellResult{Target: &fa.Unit, Outcome: OutcomeHit, Damage: 2 * fa.MaxHealth()})
+	if fa.IsDead() || fa.CurrentHealth() != fa.MaxHealth() {
+		t.Fatalf("Expected damage to be ignored without death options")
+	}
+}
+
+func TestTankDeath(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{RandomSeed: 101})
+	request.Encounter.Targets[0].MinBaseDamage = 20000
+	request.Raid.Parties[0].Players[0].HealingModel.Hps = 1
+	sim, fa := deathTestSim(request, &proto.DeathOptions{Enabled: true})
+
+	runSimUntil(sim, 20*time.Second)
+	if !fa.IsDead() || fa.IsEnabled() || fa.Metrics.Deaths != 1 {
+		t.Fatalf("Expected the tank to die")
+	}
+	if !fa.HasAura(ChanceOfDeathAuraLabel) || fa.HasAura("Death Tracker") {
+		t.Errorf("Expected tank health to only be tracked by the Chance of Death aura")
+	}
+}
+
+func TestBattleRes(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{RandomSeed: 101})
+	request.Raid.Tanks = nil
+	request.Raid.Parties[0].Players[0].HealingModel = nil
+	sim, fa := deathTestSim(request, &proto.DeathOptions{Enabled: true, BattleResDelay: 5, MaxBattleRes: 1, BattleResHealthPercent: 0.5})
+	kill := func() {
+		fa.OnSpellHitTaken(sim, sim.GetTargetUnit(0).AutoAttacks.MHAuto(), &SpellResult{Target: &fa.Unit, Outcome: OutcomeHit, Damage: 2 * fa.MaxHealth()})
+	}
+
+	kill()
+	diedAt := sim.CurrentTime
+	runSimUntil(sim, diedAt+4*time.Second)
+	if !fa.IsDead() {
+		t.Fatalf("Expected the player to stay dead until the battle res")
+	}
+	runSimUntil(sim, diedAt+5*time.Second)
+	if fa.IsDead() || !fa.IsEnabled() || fa.CurrentHealth() != fa.MaxHealth()*0.5 {
+		t.Fatalf("Expected the player to be resurrected with half health, got %0.0f of %0.0f", fa.CurrentHealth(), fa.MaxHealth())
+	}
+	if fa.Metrics.TimeDead != 5*time.Second {
+		t.Errorf("Expected 5s dead, got %s", fa.Metrics.TimeDead)
+	}
+
+	// Only 1 battle res per iteration.
+	kill()
+	runSimUntil(sim, sim.CurrentTime+10*time.Second)
+	if !fa.IsDead() || fa.Metrics.Deaths != 2 {
+		t.Fatalf("Expected the player to stay dead after the last battle res")
+	}
+}
@@ -0,0 +1,250 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Runs ranges of a sim's iterations, usually in another process.
+type SimShardWorker interface {
+	// Errors are for failures to reach the worker, which are retried. Sim
+	// errors are returned in the result, and are not.
+	RunRaidSimShard(ctx context.Context, request *proto.RaidSimShardRequest) (*proto.RaidSimShardResult, error)
+}
+
+// Runs sims with their iterations sharded across many workers, merging the
+// metrics of all shards into a single result. Shards which fail are retried on
+// the other workers.
+type SimCluster struct {
+	Workers []SimShardWorker
+
+	// Times each shard is tried before the sim fails. Defaults to 3.
+	MaxAttempts int
+}
+
+// Shards per worker, so that faster workers end up running more of the sim.
+const shardsPerWorker = 4
+
+// Like RunSimWithContext, but runs the sim on the cluster's workers. Progress is
+// reported as shards complete.
+func (cluster *SimCluster) RunRaidSim(ctx context.Context, rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics) *proto.RaidSimResult {
+	if progress != nil {
+		defer close(progress)
+	}
+
+	// Every shard has to use the same seed for its iterations to line up.
+	rsr = googleProto.Clone(rsr).(*proto.RaidSimRequest)
+	if rsr.SimOptions == nil {
+		rsr.SimOptions = &proto.SimOptions{}
+	}
+	options := rsr.SimOptions
+	if options.RandomSeed == 0 {
+		options.RandomSeed = time.Now().UnixNano()
+	}
+
+	numShards := int32(1)
+	if canShardIterations(options) && (rsr.ExternalsOptimizer == nil || !rsr.ExternalsOptimizer.Enabled) {
+		numShards = max(1, min(int32(len(cluster.Workers)*shardsPerWorker), options.Iterations/minIterationsPerWorker))
+	}
+	bounds := shardIterations(options.Iterations, numShards, options.AntitheticSampling)
+
+	var result *proto.RaidSimResult
+	var completed int32
+	shards, err := cluster.runShards(ctx, rsr, bounds, progress)
+	if err != nil {
+		result = &proto.RaidSimResult{ErrorResult: err.Error()}
+	} else {
+		for _, shard := range shards {
+			completed += shard.CompletedIterations
+		}
+		result = mergeShardResults(shards, options)
+		// Shards which never started don't report being cancelled.
+		result.Cancelled = result.Cancelled || completed < options.Iterations
+	}
+
+	if progress != nil {
+		progress <- &proto.ProgressMetrics{
+			TotalIterations:     options.Iterations,
+			CompletedIterations: completed,
+			Dps:                 result.GetRaidMetrics().GetDps().GetAvg(),
+			FinalRaidResult:     result,
+		}
+	}
+	return result
+}
+
+// Runs shards [bounds[i], bounds[i+1]), each on the next free worker. Returns the
+// results of the shards which completed any iterations, in order.
+func (cluster *SimCluster) runShards(ctx context.Context, rsr *proto.RaidSimRequest, bounds []int32, progress chan *proto.ProgressMetrics) ([]*proto.RaidSimShardResult, error) {
+	maxAttempts := cluster.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	numShards := len(bounds) - 1
+	if len(cluster.Workers) == 0 {
+		return nil, errors.New("sim cluster has no workers")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]*proto.RaidSimShardResult, numShards)
+	attempts := make([]int, numShards)
+	// Each shard is queued at most once at a time, so this never blocks.
+	pending := make(chan int, numShards)
+	for i := 0; i < numShards; i++ {
+		pending <- i
+	}
+	done := make(chan struct{})
+
+	var mu sync.Mutex
+	remaining := numShards
+	var failure, lastWorkerErr error
+	var completed atomic.Int32
+
+	var wg sync.WaitGroup
+	for _, worker := range cluster.Workers {
+		wg.Add(1)
+		go func(worker SimShardWorker) {
+			defer wg.Done()
+			for {
+				var i int
+				select {
+				case i = <-pending:
+				case <-done:
+					return
+				case <-ctx.Done():
+					return
+				}
+
+				result, err := worker.RunRaidSimShard(ctx, &proto.RaidSimShardRequest{
+					Request:        rsr,
+					StartIteration: bounds[i],
+					EndIteration:   bounds[i+1],
+				})
+
+				mu.Lock()
+				if err != nil {
+					if ctx.Err() == nil {
+						lastWorkerErr = err
+						attempts[i]++
+						if attempts[i] < maxAttempts {
+							pending <- i
+						} else {
+							failure = fmt.Errorf("shard %d failed %d times, last error: %w", i, attempts[i], err)
+							cancel()
+						}
+					}
+					mu.Unlock()
+					// The worker is likely down, so leave the remaining shards to the others.
+					return
+				}
+				if result.Result.GetErrorResult() != "" {
+					failure = errors.New(result.Result.ErrorResult)
+					cancel()
+				}
+				results[i] = result
+				remaining--
+				if remaining == 0 {
+					close(done)
+				}
+				mu.Unlock()
+
+				if progress != nil {
+					progress <- &proto.ProgressMetrics{
+						TotalIterations:     rsr.SimOptions.Iterations,
+						CompletedIterations: completed.Add(result.CompletedIterations),
+					}
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	if failure != nil {
+		return nil, failure
+	}
+	if remaining > 0 && ctx.Err() == nil {
+		return nil, fmt.Errorf("all workers failed, last error: %w", lastWorkerErr)
+	}
+
+	var shards []*proto.RaidSimShardResult
+	for _, result := range results {
+		if result != nil && result.CompletedIterations > 0 {
+			shards = append(shards, result)
+		}
+	}
+	if len(shards) == 0 {
+		return nil, errors.New("sim was cancelled before any iterations completed")
+	}
+	return shards, nil
+}
+
+// Like StatWeightsWithContext, but runs each sim on the cluster's workers.
+func (cluster *SimCluster) StatWeights(ctx context.Context, request *proto.StatWeightsRequest, progress chan *proto.ProgressMetrics) *proto.StatWeightsResult {
+	// Each sim is already spread across every worker, this just keeps them busy
+	// in between sims.
+	concurrency := 2
+	runSim := func(ctx context.Context, rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, _ bool) *proto.RaidSimResult {
+		return cluster.RunRaidSim(ctx, rsr, progress)
+	}
+	return calcStatWeight(ctx, request, stats.Stat(request.EpReferenceStat), progress, runSim, concurrency).ToProto()
+}
+
+// Runs one shard of a sim, for a SimCluster. Requests which can't be sharded
+// must cover all iterations.
+func runRaidSimShard(ctx context.Context, request *proto.RaidSimShardRequest) (result *proto.RaidSimShardResult) {
+	rsr := request.Request
+	if rsr.GetSimOptions() == nil {
+		return &proto.RaidSimShardResult{Result: &proto.RaidSimResult{ErrorResult: "Shard request has no sim options."}}
+	}
+	options := rsr.SimOptions
+	start, end := request.StartIteration, request.EndIteration
+	if start < 0 || end > options.Iterations || start >= end {
+		return &proto.RaidSimShardResult{Result: &proto.RaidSimResult{ErrorResult: fmt.Sprintf("Invalid shard [%d, %d) of %d iterations.", start, end, options.Iterations)}}
+	}
+
+	if start == 0 && end == options.Iterations {
+		simResult := RunSimWithContext(ctx, rsr, nil)
+		return &proto.RaidSimShardResult{Result: simResult, CompletedIterations: completedIterations(simResult)}
+	}
+	if !canShardIterations(options) || (rsr.ExternalsOptimizer != nil && rsr.ExternalsOptimizer.Enabled) {
+		return &proto.RaidSimShardResult{Result: &proto.RaidSimResult{ErrorResult: "These sim options can't be sharded."}}
+	}
+	if options.RandomSeed == 0 {
+		return &proto.RaidSimShardResult{Result: &proto.RaidSimResult{ErrorResult: "Shard request has no random seed."}}
+	}
+
+	defer func() {
+		if err := recover(); err != nil {
+			result = &proto.RaidSimShardResult{Result: &proto.RaidSimResult{
+				ErrorResult: fmt.Sprintf("%v\nStack Trace:\n%s", err, debug.Stack()),
+			}}
+		}
+	}()
+
+	sim := NewSim(rsr)
+	sim.ctx = ctx
+	// Presims use a fixed seed, so every shard ends up with the same settings.
+	presimResult := sim.runPresims(rsr)
+	if presimResult != nil && presimResult.ErrorResult != "" {
+		return &proto.RaidSimShardResult{Result: presimResult}
+	}
+	sim.usePresimDuration(presimResult)
+
+	var completed atomic.Int32
+	shard := sim.runShard(start, end, &completed)
+	return &proto.RaidSimShardResult{
+		Result:              sim.newResult("", shard.firstIterationDuration, shard.totalDuration, shard.completed, shard.cancelled),
+		CompletedIterations: shard.completed,
+	}
+}
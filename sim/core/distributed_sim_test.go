@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Runs shards in this process, failing the first failures calls.
+type fakeShardWorker struct {
+	failures atomic.Int32
+	shards   atomic.Int32
+}
+
+func (w *fakeShardWorker) RunRaidSimShard(ctx context.Context, request *proto.RaidSimShardRequest) (*proto.RaidSimShardResult, error) {
+	if w.failures.Add(-1) >= 0 {
+		return nil, errors.New("connection refused")
+	}
+	w.shards.Add(1)
+	return RunRaidSimShard(ctx, request), nil
+}
+
+func TestSimClusterMatchesLocalSim(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{Iterations: 400, RandomSeed: 101})
+
+	local := RunRaidSim(request)
+	if local.ErrorResult != "" {
+		t.Fatalf("Local sim failed: %s", local.ErrorResult)
+	}
+
+	broken := &fakeShardWorker{}
+	broken.failures.Store(math.MaxInt32)
+	flaky := &fakeShardWorker{}
+	flaky.failures.Store(1)
+	healthy := &fakeShardWorker{}
+	cluster := &SimCluster{Workers: []SimShardWorker{broken, flaky, healthy}}
+
+	distributed := cluster.RunRaidSim(context.Background(), request, nil)
+	if distributed.ErrorResult != "" {
+		t.Fatalf("Distributed sim failed: %s", distributed.ErrorResult)
+	}
+	if distributed.Cancelled {
+		t.Fatalf("Expected all shards to complete")
+	}
+	if shards := flaky.shards.Load() + healthy.shards.Load(); shards != 8 {
+		t.Fatalf("Expected 8 shards to run, got %d", shards)
+	}
+
+	near := func(a, b float64) bool {
+		return math.Abs(a-b) <= 1e-6*math.Max(1, math.Abs(a))
+	}
+	expected, actual := local.RaidMetrics.Parties[0].Players[0], distributed.RaidMetrics.Parties[0].Players[0]
+	if expected.Dtps.Avg == 0 {
+		t.Fatalf("Expected the tank to take damage")
+	}
+	for name, dists := range map[string][2]*proto.DistributionMetrics{
+		"DTPS":     {expected.Dtps, actual.Dtps},
+		"Raid DPS": {local.RaidMetrics.Dps, distributed.RaidMetrics.Dps},
+	} {
+		if !near(dists[0].Avg, dists[1].Avg) || !near(dists[0].Stdev, dists[1].Stdev) || !near(dists[0].Max, dists[1].Max) {
+			t.Errorf("%s: expected %v, got %v", name, dists[0], dists[1])
+		}
+	}
+	if len(expected.DamageTaken) == 0 || len(expected.DamageTaken) != len(actual.DamageTaken) {
+		t.Errorf("Expected %d damage taken sources, got %d", len(expected.DamageTaken), len(actual.DamageTaken))
+	}
+	for _, dtm := range actual.DamageTaken {
+		idx := slices.IndexFunc(expected.DamageTaken, func(e *proto.DamageTakenMetrics) bool {
+			return ProtoToActionID(e.Id) == ProtoToActionID(dtm.Id) && e.SourceUnitIndex == dtm.SourceUnitIndex
+		})
+		if idx == -1 || !near(expected.DamageTaken[idx].DamageAvg, dtm.DamageAvg) || !near(expected.DamageTaken[idx].HitsAvg, dtm.HitsAvg) {
+			t.Errorf("Damage taken from %v doesn't match the local sim", dtm.Id)
+		}
+	}
+	if !near(local.AvgIterationDuration, distributed.AvgIterationDuration) {
+		t.Errorf("Expected average duration %f, got %f", local.AvgIterationDuration, distributed.AvgIterationDuration)
+	}
+}
+
+func TestSimClusterFailsWithoutWorkers(t *testing.T) {
+	broken := &fakeShardWorker{}
+	broken.failures.Store(math.MaxInt32)
+	cluster := &SimCluster{Workers: []SimShardWorker{broken}}
+
+	result := cluster.RunRaidSim(context.Background(), &proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{Iterations: 100, RandomSeed: 101},
+	}, nil)
+	if result.ErrorResult == "" {
+		t.Fatalf("Expected an error when every worker fails")
+	}
+}
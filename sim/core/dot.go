@@ -49,11 +49,19 @@ type Dot struct {
 	tickAction *PendingAction
 	tickPeriod time.Duration
 
+	// All tick actions this dot has created, to reuse instead of allocating.
+	tickActions []*periodicAction
+
+	// Built once in newDot(), so that re-applying doesn't allocate new closures.
+	periodicOptions PeriodicActionOptions
+
 	// Number of ticks since last call to Apply().
 	TickCount int32
 
 	lastTickTime time.Duration
 	isChanneled  bool
+
+	metrics *dotMetrics
 }
 
 // TickPeriod is how fast the snapshot dot ticks.
@@ -104,9 +112,7 @@ func (dot *Dot) Rollover(sim *Simulation) {
 	dot.tickAction.Cancel(sim) // remove old PA ticker
 
 	// recreate with new period, resetting the next tick.
-	periodicOptions := dot.basePeriodicOptions()
-	periodicOptions.Period = dot.tickPeriod
-	dot.tickAction = NewPeriodicAction(sim, periodicOptions)
+	dot.tickAction = dot.newTickAction(sim)
 	dot.tickAction.NextActionAt = oldNextTick
 	sim.AddPendingAction(dot.tickAction)
 }
@@ -117,14 +123,15 @@ func (dot *Dot) RescheduleNextTick(sim *Simulation) {
 	dot.tickAction.Cancel(sim) // remove old PA ticker
 
 	// recreate with new period, resetting the next tick.
-	periodicOptions := dot.basePeriodicOptions()
-	periodicOptions.Period = dot.tickPeriod
-	dot.tickAction = NewPeriodicAction(sim, periodicOptions)
+	dot.tickAction = dot.newTickAction(sim)
 	dot.tickAction.NextActionAt = dot.lastTickTime + dot.tickPeriod
 	sim.AddPendingAction(dot.tickAction)
 }
 
 func (dot *Dot) Apply(sim *Simulation) {
+	if dot.metrics != nil {
+		dot.metrics.onApply(sim)
+	}
 	dot.TakeSnapshot(sim, false)
 
 	dot.Cancel(sim)
@@ -142,6 +149,9 @@ func (dot *Dot) ApplyOrReset(sim *Simulation) {
 		return
 	}
 
+	if dot.metrics != nil {
+		dot.metrics.onApply(sim)
+	}
 	dot.TakeSnapshot(sim, true)
 
 	dot.RecomputeAuraDuration() // recalculate haste
@@ -154,14 +164,15 @@ func (dot *Dot) ApplyOrReset(sim *Simulation) {
 	oldTickAction.Cancel(sim) // remove old PA ticker
 
 	// recreate with new period, resetting the next tick.
-	periodicOptions := dot.basePeriodicOptions()
-	periodicOptions.Period = dot.tickPeriod
-	dot.tickAction = NewPeriodicAction(sim, periodicOptions)
+	dot.tickAction = dot.newTickAction(sim)
 	sim.AddPendingAction(dot.tickAction)
 }
 
 // Like Apply(), but does not reset the tick timer.
 func (dot *Dot) ApplyOrRefresh(sim *Simulation) {
+	if dot.metrics != nil {
+		dot.metrics.onApply(sim)
+	}
 	dot.TakeSnapshot(sim, false)
 
 	dot.TickCount = 0
@@ -202,6 +213,9 @@ func (dot *Dot) TakeSnapshot(sim *Simulation, doRollover bool) {
 // the tick is simply an extra tick.
 func (dot *Dot) TickOnce(sim *Simulation) {
 	dot.lastTickTime = sim.CurrentTime
+	if dot.metrics != nil {
+		dot.metrics.ticks++
+	}
 	dot.OnTick(sim, dot.Unit, dot)
 
 	if dot.isChanneled && dot.Spell.Unit.IsUsingAPL {
@@ -212,6 +226,7 @@ func (dot *Dot) TickOnce(sim *Simulation) {
 			}
 		} else if dot.Spell.Unit.Rotation.shouldInterruptChannel(sim) {
 			dot.Cancel(sim)
+			dot.Spell.Unit.OnCastInterrupted(sim, dot.Spell)
 			if dot.Spell.Unit.GCD.IsReady(sim) {
 				dot.Spell.Unit.WaitUntil(sim, sim.CurrentTime+dot.Spell.Unit.ChannelClipDelay)
 			}
@@ -232,7 +247,25 @@ func (dot *Dot) ManualTick(sim *Simulation) {
 	}
 }
 
-func (dot *Dot) basePeriodicOptions() PeriodicActionOptions {
+// Returns a new tick action, reusing a previous one if possible. The current
+// tickAction must be replaced with the result.
+func (dot *Dot) newTickAction(sim *Simulation) *PendingAction {
+	options := dot.periodicOptions
+	options.Period = dot.tickPeriod
+
+	for _, pa := range dot.tickActions {
+		if pa.reusable() && &pa.PendingAction != dot.tickAction {
+			pa.start(sim, options)
+			return &pa.PendingAction
+		}
+	}
+
+	pa := newPeriodicAction(sim, options)
+	dot.tickActions = append(dot.tickActions, pa)
+	return &pa.PendingAction
+}
+
+func (dot *Dot) newPeriodicOptions() PeriodicActionOptions {
 	return PeriodicActionOptions{
 		//Priority: ActionPriorityDOT,
 		OnAction: func(sim *Simulation) {
@@ -260,18 +293,26 @@ func newDot(config Dot) *Dot {
 
 	dot.tickPeriod = dot.TickLength
 	dot.Aura.Duration = dot.TickLength * time.Duration(dot.NumberOfTicks)
+	dot.periodicOptions = dot.newPeriodicOptions()
+	if !dot.isChanneled {
+		dot.metrics = dot.Spell.Unit.Metrics.newDotMetrics(dot)
+	}
 
 	dot.Aura.ApplyOnGain(func(aura *Aura, sim *Simulation) {
+		if dot.metrics != nil {
+			dot.metrics.onGain(sim)
+		}
 		dot.lastTickTime = sim.CurrentTime
-		periodicOptions := dot.basePeriodicOptions()
-		periodicOptions.Period = dot.tickPeriod
-		dot.tickAction = NewPeriodicAction(sim, periodicOptions)
+		dot.tickAction = dot.newTickAction(sim)
 		sim.AddPendingAction(dot.tickAction)
 		if dot.isChanneled {
 			dot.Spell.Unit.ChanneledDot = dot
 		}
 	})
 	dot.Aura.ApplyOnExpire(func(aura *Aura, sim *Simulation) {
+		if dot.metrics != nil {
+			dot.metrics.onExpire(sim)
+		}
 		if dot.tickAction != nil {
 			dot.tickAction.Cancel(sim)
 			dot.tickAction = nil
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"slices"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Tracks how well a single dot was maintained on its target, for rotation
+// tuning. Channeled dots don't have these, since they aren't maintained.
+type dotMetrics struct {
+	dot *Dot
+
+	// Values for the current iteration.
+	activeSince  time.Duration
+	expired      bool
+	lastExpired  time.Duration
+	uptime       time.Duration
+	applications int32
+	refreshes    int32
+	ticks        int32
+	clippedTicks float64
+	gapTicks     float64
+
+	// Aggregate values. These are updated after each iteration.
+	iterations       int32
+	uptimePercentSum float64
+	applicationsSum  int32
+	refreshesSum     int32
+	ticksSum         int32
+	clippedTicksSum  float64
+	gapTicksSum      float64
+	refreshHistogram []int32 // Refreshes by whole seconds remaining.
+}
+
+func (unitMetrics *UnitMetrics) newDotMetrics(dot *Dot) *dotMetrics {
+	dm := &dotMetrics{dot: dot}
+	unitMetrics.dots = append(unitMetrics.dots, dm)
+	return dm
+}
+
+func (dm *dotMetrics) reset() {
+	dm.activeSince = 0
+	dm.expired = false
+	dm.lastExpired = 0
+	dm.uptime = 0
+	dm.applications = 0
+	dm.refreshes = 0
+	dm.ticks = 0
+	dm.clippedTicks = 0
+	dm.gapTicks = 0
+}
+
+// Should be called before the dot is (re)applied by its spell. Refreshes from
+// other spells, see Dot.Rollover(), aren't the rotation's choice and don't
+// count.
+func (dm *dotMetrics) onApply(sim *Simulation) {
+	dot := dm.dot
+	if !dot.IsActive() {
+		dm.applications++
+		if dm.expired {
+			dm.gapTicks += float64(sim.CurrentTime-dm.lastExpired) / float64(dot.tickPeriod)
+		}
+		return
+	}
+
+	dm.refreshes++
+	remaining := max(0, dot.RemainingDuration(sim))
+	dm.clippedTicks += float64(remaining) / float64(dot.tickPeriod)
+
+	bucket := int(remaining / time.Second)
+	for len(dm.refreshHistogram) <= bucket {
+		dm.refreshHistogram = append(dm.refreshHistogram, 0)
+	}
+	dm.refreshHistogram[bucket]++
+}
+
+func (dm *dotMetrics) onGain(sim *Simulation) {
+	dm.activeSince = sim.CurrentTime
+}
+
+func (dm *dotMetrics) onExpire(sim *Simulation) {
+	dm.uptime += max(0, sim.CurrentTime-max(dm.activeSince, 0))
+	dm.expired = true
+	dm.lastExpired = sim.CurrentTime
+}
+
+// This should be called when a Sim iteration is complete, after all auras
+// have expired.
+func (dm *dotMetrics) doneIteration(sim *Simulation) {
+	dm.iterations++
+	dm.uptimePercentSum += 100 * dm.uptime.Seconds() / sim.Duration.Seconds()
+	dm.applicationsSum += dm.applications
+	dm.refreshesSum += dm.refreshes
+	dm.ticksSum += dm.ticks
+	dm.clippedTicksSum += dm.clippedTicks
+	dm.gapTicksSum += dm.gapTicks
+}
+
+// Adds the aggregate values of other, for sims which are run in shards.
+func (dm *dotMetrics) merge(other *dotMetrics) {
+	dm.iterations += other.iterations
+	dm.uptimePercentSum += other.uptimePercentSum
+	dm.applicationsSum += other.applicationsSum
+	dm.refreshesSum += other.refreshesSum
+	dm.ticksSum += other.ticksSum
+	dm.clippedTicksSum += other.clippedTicksSum
+	dm.gapTicksSum += other.gapTicksSum
+
+	for len(dm.refreshHistogram) < len(other.refreshHistogram) {
+		dm.refreshHistogram = append(dm.refreshHistogram, 0)
+	}
+	for i, count := range other.refreshHistogram {
+		dm.refreshHistogram[i] += count
+	}
+}
+
+func (dm *dotMetrics) ToProto() *proto.DotMetrics {
+	n := float64(dm.iterations)
+	return &proto.DotMetrics{
+		Id:               dm.dot.Spell.ActionID.ToProto(),
+		TargetUnitIndex:  dm.dot.Unit.UnitIndex,
+		UptimePercentAvg: dm.uptimePercentSum / n,
+		ApplicationsAvg:  float64(dm.applicationsSum) / n,
+		RefreshesAvg:     float64(dm.refreshesSum) / n,
+		TicksAvg:         float64(dm.ticksSum) / n,
+		ClippedTicksAvg:  dm.clippedTicksSum / n,
+		GapTicksAvg:      dm.gapTicksSum / n,
+		RefreshHistogram: slices.Clone(dm.refreshHistogram),
+	}
+}
@@ -178,3 +178,39 @@ func TestDotSnapshotSpellMultiplier(t *testing.T) {
 	fa.Dot.Rollover(sim)
 	expectDotTickDamage(t, sim, fa.Dot, 300) // (100) * 1.5 * 2
 }
+
+func TestDotMetrics(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+
+	fa.Dot.Apply(sim)
+	fa.Dot.TickOnce(sim)
+
+	// Refreshing with 6s remaining clips 2 ticks.
+	sim.CurrentTime = time.Second * 12
+	fa.Dot.Apply(sim)
+	sim.CurrentTime = time.Second * 30
+	fa.Dot.Deactivate(sim)
+
+	// Reapplying 6s after it expired loses 2 ticks.
+	sim.CurrentTime = time.Second * 36
+	fa.Dot.Apply(sim)
+	fa.Dot.TickOnce(sim)
+	sim.CurrentTime = time.Second * 40
+	fa.Dot.Deactivate(sim)
+
+	fa.Dot.metrics.doneIteration(sim)
+	metrics := fa.Dot.metrics.ToProto()
+	if metrics.ApplicationsAvg != 2 || metrics.RefreshesAvg != 1 || metrics.TicksAvg != 2 {
+		t.Fatalf("Expected 2 applications, 1 refresh and 2 ticks, got %v", metrics)
+	}
+	if !WithinToleranceFloat64(34.0/180*100, metrics.UptimePercentAvg, 1e-9) {
+		t.Errorf("Expected %f%% uptime, got %f", 34.0/180*100, metrics.UptimePercentAvg)
+	}
+	if !WithinToleranceFloat64(2, metrics.ClippedTicksAvg, 1e-9) || !WithinToleranceFloat64(2, metrics.GapTicksAvg, 1e-9) {
+		t.Errorf("Expected 2 clipped and 2 gap ticks, got %f and %f", metrics.ClippedTicksAvg, metrics.GapTicksAvg)
+	}
+	if len(metrics.RefreshHistogram) != 7 || metrics.RefreshHistogram[6] != 1 {
+		t.Errorf("Expected a refresh with 6s remaining, got %v", metrics.RefreshHistogram)
+	}
+}
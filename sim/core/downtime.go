@@ -0,0 +1,189 @@
+package core
+
+import (
+	"slices"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Mana restored per second while drinking during downtime, from a Conjured
+// Mana Strudel.
+const DowntimeDrinkManaPerSecond = 19200.0 / 30
+
+// Scripted windows in which the targets can't be attacked, see
+// proto.DowntimeWindow.
+type downtimeWindow struct {
+	start    time.Duration
+	duration time.Duration
+	drink    bool
+}
+
+// Players and pets are out of combat during downtime windows. Each one gets
+// an aura, so it shows up in the timeline and aura metrics.
+type outOfCombat struct {
+	aura *Aura
+
+	drinkMetrics *ResourceMetrics // Only set for units with mana.
+	drinking     bool
+	drinkAction  *PendingAction
+}
+
+func (env *Environment) setupDowntimeWindows(raidProto *proto.Raid, configs []*proto.DowntimeWindow) {
+	for _, config := range configs {
+		if config.Duration <= 0 {
+			continue
+		}
+		env.Encounter.downtimeWindows = append(env.Encounter.downtimeWindows, &downtimeWindow{
+			start:    max(DurationFromSeconds(config.StartTime), 0),
+			duration: DurationFromSeconds(config.Duration),
+			drink:    config.Drink,
+		})
+	}
+	if len(env.Encounter.downtimeWindows) == 0 {
+		return
+	}
+	slices.SortStableFunc(env.Encounter.downtimeWindows, func(a, b *downtimeWindow) int {
+		return int(a.start - b.start)
+	})
+
+	for partyIdx, party := range env.Raid.Parties {
+		for playerIdx, player := range party.Players {
+			// Skips target dummies.
+			if playerIdx >= len(raidProto.Parties[partyIdx].Players) {
+				continue
+			}
+			character := player.GetCharacter()
+			activeDps := NewDistributionMetrics()
+			character.Metrics.activeDps = &activeDps
+			env.Encounter.outOfCombatUnits = append(env.Encounter.outOfCombatUnits, &character.Unit)
+			for _, pet := range character.Pets {
+				env.Encounter.outOfCombatUnits = append(env.Encounter.outOfCombatUnits, &pet.Unit)
+			}
+		}
+	}
+	for _, unit := range env.Encounter.outOfCombatUnits {
+		unit.registerOutOfCombat()
+	}
+}
+
+func (unit *Unit) registerOutOfCombat() {
+	ooc := &outOfCombat{}
+	if unit.HasManaBar() {
+		ooc.drinkMetrics = unit.NewManaMetrics(ActionID{ItemID: 43523})
+	}
+
+	ooc.aura = unit.RegisterAura(Aura{
+		Label:    "Out of Combat",
+		ActionID: ActionID{OtherID: proto.OtherAction_OtherActionOutOfCombat},
+		OnGain: func(aura *Aura, sim *Simulation) {
+			aura.Unit.interruptCasts(sim, false)
+			aura.Unit.AutoAttacks.CancelAutoSwing(sim)
+
+			if ooc.drinking && ooc.drinkMetrics != nil {
+				ooc.drinkAction = StartPeriodicAction(sim, PeriodicActionOptions{
+					Period: time.Second * 2,
+					OnAction: func(sim *Simulation) {
+						aura.Unit.AddMana(sim, DowntimeDrinkManaPerSecond*2, ooc.drinkMetrics)
+					},
+				})
+			}
+		},
+		OnExpire: func(aura *Aura, sim *Simulation) {
+			if ooc.drinkAction != nil {
+				ooc.drinkAction.Cancel(sim)
+				ooc.drinkAction = nil
+			}
+			if !aura.Unit.IsEnabled() {
+				return
+			}
+			if lc := aura.Unit.lossOfControl; lc == nil || !lc.effects[proto.LossOfControlType_LossOfControlStun].aura.IsActive() {
+				aura.Unit.AutoAttacks.EnableAutoSwing(sim)
+			}
+		},
+	})
+
+	unit.outOfCombat = ooc
+}
+
+// Whether the unit is out of combat, and can't cast anything.
+func (unit *Unit) IsOutOfCombat() bool {
+	return unit.outOfCombat != nil && unit.outOfCombat.aura.IsActive()
+}
+
+func (ooc *outOfCombat) apply(sim *Simulation, window *downtimeWindow) {
+	unit := ooc.aura.Unit
+	if !unit.IsEnabled() {
+		return
+	}
+
+	end := sim.CurrentTime + window.duration
+	if ooc.aura.IsActive() {
+		if ooc.aura.ExpiresAt() < end {
+			ooc.aura.UpdateExpires(end)
+		}
+	} else {
+		ooc.drinking = window.drink
+		ooc.aura.Duration = window.duration
+		ooc.aura.Activate(sim)
+	}
+
+	// Resumes the rotation once the window ends, which also makes sure the
+	// aura expires on time.
+	unit.SetGCDTimer(sim, max(unit.GCD.ReadyAt(), ooc.aura.ExpiresAt()))
+}
+
+func (encounter *Encounter) resetDowntimeWindows(sim *Simulation) {
+	for _, window := range encounter.downtimeWindows {
+		window := window
+		StartDelayedAction(sim, DelayedActionOptions{
+			DoAt: window.start,
+			OnAction: func(sim *Simulation) {
+				for _, target := range encounter.TargetUnits {
+					target.AutoAttacks.CancelAutoSwing(sim)
+				}
+				for _, unit := range encounter.outOfCombatUnits {
+					unit.outOfCombat.apply(sim, window)
+				}
+			},
+		})
+		StartDelayedAction(sim, DelayedActionOptions{
+			DoAt: window.start + window.duration,
+			OnAction: func(sim *Simulation) {
+				if encounter.activeDowntimeWindow(sim.CurrentTime) {
+					return
+				}
+				for _, target := range encounter.TargetUnits {
+					target.AutoAttacks.EnableAutoSwing(sim)
+				}
+			},
+		})
+	}
+}
+
+// Whether a downtime window is active at the given time.
+func (encounter *Encounter) activeDowntimeWindow(at time.Duration) bool {
+	for _, window := range encounter.downtimeWindows {
+		if window.start <= at && at < window.start+window.duration {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the part of the first `duration` of the fight which isn't covered
+// by a downtime window.
+func (encounter *Encounter) activeTime(duration time.Duration) time.Duration {
+	active := duration
+	coveredUntil := time.Duration(0)
+	// Windows are sorted by start time, so overlaps are only counted once.
+	for _, window := range encounter.downtimeWindows {
+		start := max(window.start, coveredUntil)
+		end := min(window.start+window.duration, duration)
+		if end > start {
+			active -= end - start
+		}
+		coveredUntil = max(coveredUntil, end)
+	}
+	return active
+}
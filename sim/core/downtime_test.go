@@ -0,0 +1,56 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestDowntimeWindows(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{})
+	request.Encounter.DowntimeWindows = []*proto.DowntimeWindow{
+		{StartTime: 5, Duration: 5, Drink: true},
+	}
+	sim := NewSim(request)
+
+	agent := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	target := sim.Encounter.TargetUnits[0]
+	sim.reset()
+
+	runSimUntil(sim, time.Second*6)
+	if !agent.IsOutOfCombat() || agent.Spell.CanCast(sim, target) {
+		t.Fatalf("Expected players to be out of combat during the window")
+	}
+	if target.AutoAttacks.enabled || agent.NextGCDAt() != time.Second*10 {
+		t.Fatalf("Expected the target to stop attacking and the GCD to wait for the end of the window, got %s", agent.NextGCDAt())
+	}
+
+	runSimUntil(sim, time.Millisecond*10100)
+	if agent.IsOutOfCombat() || !agent.Spell.CanCast(sim, target) || !target.AutoAttacks.enabled {
+		t.Fatalf("Expected combat to resume once the window ends")
+	}
+
+	request.SimOptions.Iterations = 10
+	result := RunRaidSim(request)
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", result.ErrorResult)
+	}
+	if result.RaidMetrics.Parties[0].Players[0].ActiveDps == nil {
+		t.Fatalf("Expected active DPS to be reported")
+	}
+}
+
+func TestDowntimeActiveTime(t *testing.T) {
+	encounter := &Encounter{downtimeWindows: []*downtimeWindow{
+		{start: time.Second * 10, duration: time.Second * 10},
+		{start: time.Second * 15, duration: time.Second * 10},
+		{start: time.Second * 50, duration: time.Second * 20},
+	}}
+
+	// Overlapping windows only count once, and windows past the end of the
+	// fight don't count.
+	if activeTime := encounter.activeTime(time.Minute); activeTime != time.Second*35 {
+		t.Fatalf("Expected 35s of active time, got %s", activeTime)
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"github.com/wowsims/wotlk/sim/core/proto"
 )
 
-// Time between energy ticks.
+// Base energy regen rate. Ticks are every EnergyTickDuration unless
+// configured otherwise in SimOptions.resource_ticks, with the same regen per
+// second for any tick duration.
 const EnergyTickDuration = time.Millisecond * 100
 const EnergyPerTick = 1.0
 
@@ -35,6 +37,7 @@ type energyBar struct {
 
 	onEnergyGain func(*Simulation, bool)
 
+	tickDuration   time.Duration
 	nextEnergyTick time.Duration
 
 	// Multiplies energy regen from ticks.
@@ -42,6 +45,9 @@ type energyBar struct {
 
 	regenMetrics        *ResourceMetrics
 	EnergyRefundMetrics *ResourceMetrics
+	tickMetrics         *energyTickMetrics
+
+	timeline *resourceTimeline
 }
 
 func (unit *Unit) EnableEnergyBar(maxEnergy float64, onEnergyGain OnEnergyGain) {
@@ -68,6 +74,7 @@ func (unit *Unit) EnableEnergyBar(maxEnergy float64, onEnergyGain OnEnergyGain)
 		EnergyTickMultiplier: 1,
 		regenMetrics:         unit.NewEnergyMetrics(ActionID{OtherID: proto.OtherAction_OtherActionEnergyRegen}),
 		EnergyRefundMetrics:  unit.NewEnergyMetrics(ActionID{OtherID: proto.OtherAction_OtherActionRefund}),
+		tickMetrics:          unit.Metrics.newEnergyTickMetrics(),
 	}
 }
 
@@ -153,6 +160,11 @@ func (eb *energyBar) NextEnergyTickAt() time.Duration {
 	return eb.nextEnergyTick
 }
 
+// Energy gained per regular tick.
+func (eb *energyBar) energyPerTick() float64 {
+	return EnergyPerTick * eb.EnergyTickMultiplier * float64(eb.tickDuration) / float64(EnergyTickDuration)
+}
+
 func (eb *energyBar) addEnergyInternal(sim *Simulation, amount float64, metrics *ResourceMetrics) bool {
 	if amount < 0 {
 		panic("Trying to add negative energy!")
@@ -162,11 +174,14 @@ func (eb *energyBar) addEnergyInternal(sim *Simulation, amount float64, metrics
 	metrics.AddEvent(amount, newEnergy-eb.currentEnergy)
 
 	if sim.Log != nil {
-		eb.unit.Log(sim, "Gained %0.3f energy from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, eb.currentEnergy, newEnergy)
+		eb.unit.logResource(sim, LogEventResourceGain, proto.ResourceType_ResourceTypeEnergy, amount, metrics, eb.currentEnergy, newEnergy)
 	}
 
 	crossedThreshold := eb.cumulativeEnergyDecisionThresholds == nil || eb.cumulativeEnergyDecisionThresholds[int(eb.currentEnergy)] != eb.cumulativeEnergyDecisionThresholds[int(newEnergy)]
 	eb.currentEnergy = newEnergy
+	if eb.timeline != nil {
+		eb.timeline.update(sim, newEnergy, eb.maxEnergy)
+	}
 
 	return crossedThreshold
 }
@@ -182,12 +197,16 @@ func (eb *energyBar) SpendEnergy(sim *Simulation, amount float64, metrics *Resou
 
 	newEnergy := eb.currentEnergy - amount
 	metrics.AddEvent(-amount, -amount)
+	eb.tickMetrics.onSpend(sim, eb)
 
 	if sim.Log != nil {
-		eb.unit.Log(sim, "Spent %0.3f energy from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, eb.currentEnergy, newEnergy)
+		eb.unit.logResource(sim, LogEventResourceSpend, proto.ResourceType_ResourceTypeEnergy, amount, metrics, eb.currentEnergy, newEnergy)
 	}
 
 	eb.currentEnergy = newEnergy
+	if eb.timeline != nil {
+		eb.timeline.update(sim, newEnergy, eb.maxEnergy)
+	}
 }
 
 func (eb *energyBar) ComboPoints() int32 {
@@ -196,13 +215,13 @@ func (eb *energyBar) ComboPoints() int32 {
 
 // Gives an immediate partial energy tick and restarts the tick timer.
 func (eb *energyBar) ResetEnergyTick(sim *Simulation) {
-	timeSinceLastTick := sim.CurrentTime - (eb.NextEnergyTickAt() - EnergyTickDuration)
-	partialTickAmount := (EnergyPerTick * eb.EnergyTickMultiplier) * (float64(timeSinceLastTick) / float64(EnergyTickDuration))
+	timeSinceLastTick := sim.CurrentTime - (eb.NextEnergyTickAt() - eb.tickDuration)
+	partialTickAmount := eb.energyPerTick() * (float64(timeSinceLastTick) / float64(eb.tickDuration))
 
 	crossedThreshold := eb.addEnergyInternal(sim, partialTickAmount, eb.regenMetrics)
 	eb.onEnergyGain(sim, crossedThreshold)
 
-	eb.nextEnergyTick = sim.CurrentTime + EnergyTickDuration
+	eb.nextEnergyTick = sim.CurrentTime + eb.tickDuration
 	sim.RescheduleTask(eb.nextEnergyTick)
 }
 
@@ -230,10 +249,11 @@ func (eb *energyBar) RunTask(sim *Simulation) time.Duration {
 		return eb.nextEnergyTick
 	}
 
-	crossedThreshold := eb.addEnergyInternal(sim, EnergyPerTick*eb.EnergyTickMultiplier, eb.regenMetrics)
+	eb.tickMetrics.onTick(sim, eb)
+	crossedThreshold := eb.addEnergyInternal(sim, eb.energyPerTick(), eb.regenMetrics)
 	eb.onEnergyGain(sim, crossedThreshold)
 
-	eb.nextEnergyTick = sim.CurrentTime + EnergyTickDuration
+	eb.nextEnergyTick = sim.CurrentTime + eb.tickDuration
 	return eb.nextEnergyTick
 }
 
@@ -243,6 +263,9 @@ func (eb *energyBar) reset(sim *Simulation) {
 	}
 
 	eb.currentEnergy = eb.maxEnergy
+	if eb.timeline != nil {
+		eb.timeline.update(sim, eb.currentEnergy, eb.maxEnergy)
+	}
 	eb.comboPoints = 0
 
 	if eb.unit.Type != PetUnit {
@@ -252,7 +275,13 @@ func (eb *energyBar) reset(sim *Simulation) {
 
 func (eb *energyBar) enable(sim *Simulation, startAt time.Duration) {
 	sim.AddTask(eb)
-	eb.nextEnergyTick = startAt + time.Duration(sim.RandomFloat("Energy Tick")*float64(EnergyTickDuration))
+	eb.tickDuration = sim.energyTickDuration()
+	eb.tickMetrics.tickDuration = eb.tickDuration
+	if firstTick, ok := sim.alignedResourceTick(startAt, eb.tickDuration); ok {
+		eb.nextEnergyTick = firstTick
+	} else {
+		eb.nextEnergyTick = startAt + time.Duration(sim.RandomFloat("Energy Tick")*float64(eb.tickDuration))
+	}
 	sim.RescheduleTask(eb.nextEnergyTick)
 
 	if eb.cumulativeEnergyDecisionThresholds != nil && sim.Log != nil {
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Tracks how a unit's energy spends line up with its energy ticks. With long
+// ticks, spending right after a tick rather than right before one delays
+// everything after it.
+type energyTickMetrics struct {
+	tickDuration time.Duration
+
+	// Values for the current iteration.
+	ticks         int32
+	cappedTicks   int32
+	spends        int32
+	spendPhaseSum float64
+
+	// Aggregate values. These are updated after each iteration.
+	iterations       int32
+	ticksSum         int32
+	cappedTicksSum   int32
+	spendsSum        int32
+	spendPhaseSumSum float64
+}
+
+func (unitMetrics *UnitMetrics) newEnergyTickMetrics() *energyTickMetrics {
+	unitMetrics.energyTicks = &energyTickMetrics{}
+	return unitMetrics.energyTicks
+}
+
+func (etm *energyTickMetrics) reset() {
+	etm.ticks = 0
+	etm.cappedTicks = 0
+	etm.spends = 0
+	etm.spendPhaseSum = 0
+}
+
+// Should be called before the tick's energy is added. Ticks during the prepull
+// don't count.
+func (etm *energyTickMetrics) onTick(sim *Simulation, eb *energyBar) {
+	if sim.CurrentTime < 0 {
+		return
+	}
+	etm.ticks++
+	if eb.currentEnergy >= eb.maxEnergy {
+		etm.cappedTicks++
+	}
+}
+
+func (etm *energyTickMetrics) onSpend(sim *Simulation, eb *energyBar) {
+	if sim.CurrentTime < 0 || eb.tickDuration == 0 {
+		return
+	}
+	sinceLastTick := sim.CurrentTime - (eb.nextEnergyTick - eb.tickDuration)
+	etm.spends++
+	etm.spendPhaseSum += min(max(float64(sinceLastTick)/float64(eb.tickDuration), 0), 1)
+}
+
+func (etm *energyTickMetrics) doneIteration() {
+	etm.iterations++
+	etm.ticksSum += etm.ticks
+	etm.cappedTicksSum += etm.cappedTicks
+	etm.spendsSum += etm.spends
+	etm.spendPhaseSumSum += etm.spendPhaseSum
+}
+
+func (etm *energyTickMetrics) merge(other *energyTickMetrics) {
+	etm.iterations += other.iterations
+	etm.ticksSum += other.ticksSum
+	etm.cappedTicksSum += other.cappedTicksSum
+	etm.spendsSum += other.spendsSum
+	etm.spendPhaseSumSum += other.spendPhaseSumSum
+}
+
+func (etm *energyTickMetrics) ToProto() *proto.EnergyTickMetrics {
+	n := float64(max(etm.iterations, 1))
+	protoMetrics := &proto.EnergyTickMetrics{
+		TickSeconds:    etm.tickDuration.Seconds(),
+		TicksAvg:       float64(etm.ticksSum) / n,
+		CappedTicksAvg: float64(etm.cappedTicksSum) / n,
+		SpendsAvg:      float64(etm.spendsSum) / n,
+	}
+	if etm.spendsSum > 0 {
+		protoMetrics.SpendTickPhaseAvg = etm.spendPhaseSumSum / float64(etm.spendsSum)
+	}
+	return protoMetrics
+}
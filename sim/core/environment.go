@@ -23,6 +23,10 @@ type PostFinalizeEffect func()
 type PrepullAction struct {
 	DoAt   time.Duration
 	Action func(*Simulation)
+
+	// Set for actions which use a spell or aura, so that they're logged.
+	Unit     *Unit
+	ActionID ActionID
 }
 
 type Environment struct {
@@ -44,6 +48,15 @@ type Environment struct {
 	postFinalizeEffects []PostFinalizeEffect
 
 	prepullActions []PrepullAction
+
+	// Raid buffs/debuffs which are not up for the whole encounter.
+	buffAssignments []*proto.BuffAssignment
+
+	// Only set if the encounter tracks threat.
+	threat *threatTracker
+
+	// Spell which declared each spell-specific random stream.
+	randomStreamOwners map[string]ActionID
 }
 
 func NewEnvironment(raidProto *proto.Raid, encounterProto *proto.Encounter, runFakePrepull bool) (*Environment, *proto.RaidStats, *proto.EncounterStats) {
@@ -51,6 +64,7 @@ func NewEnvironment(raidProto *proto.Raid, encounterProto *proto.Encounter, runF
 		State: Created,
 	}
 
+	encounterProto = expandAddWaves(encounterProto)
 	env.construct(raidProto, encounterProto)
 	raidStats := env.initialize(raidProto, encounterProto)
 	env.finalize(raidProto, encounterProto, raidStats, runFakePrepull)
@@ -67,6 +81,7 @@ func NewEnvironment(raidProto *proto.Raid, encounterProto *proto.Encounter, runF
 
 // The construction phase.
 func (env *Environment) construct(raidProto *proto.Raid, encounterProto *proto.Encounter) {
+	env.buffAssignments = raidProto.BuffAssignments
 	env.Encounter = NewEncounter(encounterProto)
 	env.BaseDuration = env.Encounter.Duration
 	env.DurationVariation = env.Encounter.DurationVariation
@@ -105,12 +120,27 @@ func (env *Environment) construct(raidProto *proto.Raid, encounterProto *proto.E
 					}
 				}
 			}
+
+			if targetProto.TankSwapDebuff != nil {
+				target.enableTankSwap(targetProto.TankSwapDebuff, env.getTanks(raidProto))
+			}
 		}
 	}
 
 	env.State = Constructed
 }
 
+// Returns the units in Raid.tanks, skipping any which don't exist.
+func (env *Environment) getTanks(raidProto *proto.Raid) []*Unit {
+	var tanks []*Unit
+	for _, tankRef := range raidProto.Tanks {
+		if tank := env.GetUnit(tankRef, nil); tank != nil && !slices.Contains(tanks, tank) {
+			tanks = append(tanks, tank)
+		}
+	}
+	return tanks
+}
+
 // The initialization phase.
 func (env *Environment) initialize(raidProto *proto.Raid, encounterProto *proto.Encounter) *proto.RaidStats {
 	for _, target := range env.Encounter.Targets {
@@ -128,6 +158,8 @@ func (env *Environment) initialize(raidProto *proto.Raid, encounterProto *proto.
 	}
 
 	raidStats := env.Raid.applyCharacterEffects(raidProto)
+	env.setupLossOfControlEvents(raidProto, encounterProto.LossOfControlEvents)
+	env.setupDowntimeWindows(raidProto, encounterProto.DowntimeWindows)
 
 	for _, party := range env.Raid.Parties {
 		for _, playerOrPet := range party.PlayersAndPets {
@@ -140,7 +172,7 @@ func (env *Environment) initialize(raidProto *proto.Raid, encounterProto *proto.
 }
 
 // The finalization phase.
-func (env *Environment) finalize(raidProto *proto.Raid, _ *proto.Encounter, raidStats *proto.RaidStats, runFakePrepull bool) {
+func (env *Environment) finalize(raidProto *proto.Raid, encounterProto *proto.Encounter, raidStats *proto.RaidStats, runFakePrepull bool) {
 	for _, finalizeEffect := range env.preFinalizeEffects {
 		finalizeEffect()
 	}
@@ -170,6 +202,11 @@ func (env *Environment) finalize(raidProto *proto.Raid, _ *proto.Encounter, raid
 			playerProto := partyProto.Players[playerIdx]
 			char := player.GetCharacter()
 			char.Rotation = char.newAPLRotation(playerProto.Rotation)
+			for _, pet := range char.Pets {
+				if petRotation, ok := playerProto.PetRotations[pet.Name]; ok {
+					pet.Rotation = pet.newAPLRotation(petRotation)
+				}
+			}
 		}
 	}
 
@@ -184,6 +221,10 @@ func (env *Environment) finalize(raidProto *proto.Raid, _ *proto.Encounter, raid
 
 	env.setupAttackTables()
 
+	if encounterProto.TrackThreat {
+		env.enableThreatTracking()
+	}
+
 	env.State = Finalized
 
 	if runFakePrepull {
@@ -226,6 +267,13 @@ func (env *Environment) IsFinalized() bool {
 func (env *Environment) reset(sim *Simulation) {
 	// Reset primary targets damage taken for tracking health fights.
 	env.Encounter.DamageTaken = 0
+	env.Encounter.resetTargetUnits()
+	env.Encounter.updateAOECapMultiplier()
+	env.Encounter.resetHealthPools()
+
+	if env.threat != nil {
+		env.threat.reset()
+	}
 
 	// Targets need to be reset before the raid, so that players can check for
 	// the presence of permanent target auras in their Reset handlers.
@@ -234,28 +282,54 @@ func (env *Environment) reset(sim *Simulation) {
 	}
 
 	env.Raid.reset(sim)
+	env.Encounter.resetAdds(sim)
+	env.Encounter.resetLossOfControlEvents(sim)
+	env.Encounter.resetDowntimeWindows(sim)
 }
 
 // The maximum possible duration for any iteration.
 func (env *Environment) GetMaxDuration() time.Duration {
+	if env.Encounter.DurationDistribution == proto.DurationDistribution_DurationDistributionNormal {
+		return env.BaseDuration + normalDurationCutoff*env.DurationVariation
+	}
 	return env.BaseDuration + env.DurationVariation
 }
 
+// The number of targets in the encounter, including adds which aren't in the
+// fight. Use GetNumActiveTargets() for the targets which can be attacked.
 func (env *Environment) GetNumTargets() int32 {
 	return int32(len(env.Encounter.Targets))
 }
 
+// The number of targets which are currently in the fight.
+func (env *Environment) GetNumActiveTargets() int32 {
+	return int32(len(env.Encounter.TargetUnits))
+}
+
 func (env *Environment) GetTarget(index int32) *Target {
 	return env.Encounter.Targets[index]
 }
 func (env *Environment) GetTargetUnit(index int32) *Unit {
 	return &env.Encounter.Targets[index].Unit
 }
+
+// The target after the given one which is in the fight, wrapping around to the
+// first.
 func (env *Environment) NextTarget(target *Unit) *Target {
-	return env.Encounter.Targets[target.Index].NextTarget()
+	return env.Encounter.Targets[env.NextTargetUnit(target).Index]
 }
 func (env *Environment) NextTargetUnit(target *Unit) *Unit {
-	return &env.NextTarget(target).Unit
+	targetUnits := env.Encounter.TargetUnits
+	if env.Encounter.activeTargetUnits == nil {
+		// All targets are always in the fight, in order.
+		return targetUnits[(int(target.Index)+1)%len(targetUnits)]
+	}
+	for i, unit := range targetUnits {
+		if unit == target {
+			return targetUnits[(i+1)%len(targetUnits)]
+		}
+	}
+	return targetUnits[0]
 }
 
 func (env *Environment) GetUnit(ref *proto.UnitReference, contextUnit *Unit) *Unit {
@@ -289,8 +363,8 @@ func (env *Environment) GetUnit(ref *proto.UnitReference, contextUnit *Unit) *Un
 			return nil
 		}
 	case proto.UnitReference_Target:
-		if int(ref.Index) < len(env.Encounter.TargetUnits) {
-			return env.Encounter.TargetUnits[ref.Index]
+		if int(ref.Index) < len(env.Encounter.AllTargetUnits) {
+			return env.Encounter.AllTargetUnits[ref.Index]
 		} else {
 			return nil
 		}
@@ -329,20 +403,79 @@ func (env *Environment) RegisterPostFinalizeEffect(postFinalizeEffect PostFinali
 // Registers a callback to this Unit which will be invoked on the prepull at the specified
 // negative time.
 func (unit *Unit) RegisterPrepullAction(doAt time.Duration, action func(*Simulation)) {
+	unit.registerPrepullAction(PrepullAction{
+		DoAt:   doAt,
+		Action: action,
+	})
+}
+
+func (unit *Unit) registerPrepullAction(ppa PrepullAction) {
 	env := unit.Env
 	if env.IsFinalized() {
 		panic("Prepull actions may not be added once finalized!")
 	}
-	if doAt > 0 {
+	if ppa.DoAt > 0 {
 		panic("Prepull DoAt must not be positive!")
 	}
 
-	env.prepullActions = append(env.prepullActions, PrepullAction{
-		DoAt:   doAt,
-		Action: action,
+	env.prepullActions = append(env.prepullActions, ppa)
+}
+
+// Registers a prepull cast of spell at target, or this Unit's current target if
+// nil, e.g. a potion at -1s. Cooldowns and resources used by the cast carry
+// over into the fight.
+func (unit *Unit) RegisterPrepullSpell(doAt time.Duration, spell *Spell, target *Unit) {
+	unit.registerPrepullAction(PrepullAction{
+		DoAt:     doAt,
+		Unit:     unit,
+		ActionID: spell.ActionID,
+		Action: func(sim *Simulation) {
+			spell.Cast(sim, target)
+		},
+	})
+}
+
+// Like RegisterPrepullSpell, but applies the spell's effects without casting
+// it, for buffs which are put up long enough before the pull that their cost
+// and cooldown don't matter.
+func (unit *Unit) RegisterPrepullEffects(doAt time.Duration, spell *Spell, target *Unit) {
+	unit.registerPrepullAction(PrepullAction{
+		DoAt:     doAt,
+		Unit:     unit,
+		ActionID: spell.ActionID,
+		Action: func(sim *Simulation) {
+			if target == nil {
+				spell.SkipCastAndApplyEffects(sim, unit.CurrentTarget)
+			} else {
+				spell.SkipCastAndApplyEffects(sim, target)
+			}
+		},
 	})
 }
 
+// Registers a prepull activation of aura, for effects which are up at the pull
+// without a spell to cast, e.g. a proc from before the pull.
+func (unit *Unit) RegisterPrepullAura(doAt time.Duration, aura *Aura) {
+	unit.registerPrepullAction(PrepullAction{
+		DoAt:     doAt,
+		Unit:     unit,
+		ActionID: aura.ActionID,
+		Action:   aura.Activate,
+	})
+}
+
+// Registers a prepull cast of spell at this Unit's current target, started so
+// that the cast finishes at landAt, e.g. 0 for a hardcast which lands on pull.
+// Returns the time at which the cast starts.
+//
+// Cast times depend on final stats, so this should be called from a
+// post-finalize effect.
+func (unit *Unit) RegisterPrepullCast(spell *Spell, landAt time.Duration) time.Duration {
+	castAt := landAt - unit.ApplyCastSpeedForSpell(spell.DefaultCast.CastTime, spell)
+	unit.RegisterPrepullSpell(castAt, spell, nil)
+	return castAt
+}
+
 func (env *Environment) PrepullStartTime() time.Duration {
 	if !env.IsFinalized() {
 		panic("Env not yet finalized")
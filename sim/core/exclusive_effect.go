@@ -19,6 +19,8 @@ type ExclusiveEffect struct {
 
 	Category  *ExclusiveCategory
 	isEnabled bool
+
+	metrics *exclusiveEffectMetrics
 }
 
 func (ee *ExclusiveEffect) IsActive() bool {
@@ -31,6 +33,9 @@ type ExclusiveCategory struct {
 	effects    []*ExclusiveEffect
 
 	activeEffect *ExclusiveEffect
+
+	// Time up to which the effects' metrics have been updated.
+	metricsUpdatedAt time.Duration
 }
 
 func (ec *ExclusiveCategory) AnyActive() bool {
@@ -73,21 +78,25 @@ func (ec *ExclusiveCategory) SetActive(sim *Simulation, newActiveEffect *Exclusi
 }
 
 type ExclusiveEffectManager struct {
-	categories []*ExclusiveCategory
+	categories map[string]*ExclusiveCategory
+}
+
+func newExclusiveEffectManager() *ExclusiveEffectManager {
+	return &ExclusiveEffectManager{
+		categories: make(map[string]*ExclusiveCategory),
+	}
 }
 
 // Returns a category with the given name. Creates a new category if one doesn't already exist.
 func (eem *ExclusiveEffectManager) GetExclusiveEffectCategory(categoryName string) *ExclusiveCategory {
-	for i := 0; i < len(eem.categories); i++ {
-		if eem.categories[i].Name == categoryName {
-			return eem.categories[i]
-		}
+	if category, ok := eem.categories[categoryName]; ok {
+		return category
 	}
 
 	newCategory := &ExclusiveCategory{
 		Name: categoryName,
 	}
-	eem.categories = append(eem.categories, newCategory)
+	eem.categories[categoryName] = newCategory
 	return newCategory
 }
 
@@ -118,6 +127,7 @@ func (aura *Aura) NewExclusiveEffect(categoryName string, singleAura bool, confi
 	*newEffect = config
 	newEffect.Aura = aura
 	newEffect.Category = category
+	newEffect.metrics = aura.Unit.Metrics.newExclusiveEffectMetrics(newEffect)
 
 	category.effects = append(category.effects, newEffect)
 	aura.ExclusiveEffects = append(aura.ExclusiveEffects, newEffect)
@@ -131,8 +141,10 @@ func (ee *ExclusiveEffect) Activate(sim *Simulation) bool {
 	}
 
 	if ee.Category.SingleAura && ee.Category.activeEffect != nil && ee.Category.activeEffect != ee && (ee.Category.activeEffect.Priority > ee.Priority || (ee.Priority == ee.Category.activeEffect.Priority && ee.Category.activeEffect.Aura.RemainingDuration(sim) > ee.Aura.Duration)) {
+		ee.metrics.blocked++
 		return false
 	}
+	ee.Category.updateMetrics(sim)
 
 	ee.isEnabled = true
 
@@ -151,6 +163,7 @@ func (ee *ExclusiveEffect) Deactivate(sim *Simulation) {
 	if !ee.isEnabled {
 		return
 	}
+	ee.Category.updateMetrics(sim)
 	ee.isEnabled = false
 
 	if ee.Category.activeEffect == ee {
@@ -164,6 +177,7 @@ func (ee *ExclusiveEffect) SetPriority(sim *Simulation, newPrio float64) {
 		return
 	}
 
+	ee.Category.updateMetrics(sim)
 	curActiveEffect := ee.Category.activeEffect
 
 	oldPrio := ee.Priority
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Tracks how long an exclusive effect was active, and how long it was applied
+// but suppressed by a stronger effect in its category, so that redundant buffs
+// and debuffs show up in the results.
+type exclusiveEffectMetrics struct {
+	effect *ExclusiveEffect
+
+	// Values for the current iteration.
+	active     time.Duration
+	suppressed time.Duration
+	blocked    int32 // Applications prevented by a stronger aura, in SingleAura categories.
+
+	// Aggregate values. These are updated after each iteration.
+	iterations           int32
+	activePercentSum     float64
+	suppressedPercentSum float64
+	blockedSum           int32
+}
+
+func (unitMetrics *UnitMetrics) newExclusiveEffectMetrics(effect *ExclusiveEffect) *exclusiveEffectMetrics {
+	eem := &exclusiveEffectMetrics{effect: effect}
+	unitMetrics.exclusiveEffects = append(unitMetrics.exclusiveEffects, eem)
+	return eem
+}
+
+// Adds the time since the last update to each of the category's effects.
+// Should be called before any of them changes state.
+func (ec *ExclusiveCategory) updateMetrics(sim *Simulation) {
+	elapsed := sim.CurrentTime - ec.metricsUpdatedAt
+	ec.metricsUpdatedAt = sim.CurrentTime
+	if elapsed <= 0 {
+		// Also the case on the first change of each iteration, since all
+		// effects are disabled between iterations.
+		return
+	}
+	for _, ee := range ec.effects {
+		if ee.IsActive() {
+			ee.metrics.active += elapsed
+		} else if ee.isEnabled {
+			ee.metrics.suppressed += elapsed
+		}
+	}
+}
+
+// This should be called when a Sim iteration is complete, after all auras
+// have expired.
+func (eem *exclusiveEffectMetrics) doneIteration(sim *Simulation) {
+	eem.iterations++
+	eem.activePercentSum += 100 * eem.active.Seconds() / sim.Duration.Seconds()
+	eem.suppressedPercentSum += 100 * eem.suppressed.Seconds() / sim.Duration.Seconds()
+	eem.blockedSum += eem.blocked
+
+	eem.active = 0
+	eem.suppressed = 0
+	eem.blocked = 0
+}
+
+// Adds the aggregate values of other, for sims which are run in shards.
+func (eem *exclusiveEffectMetrics) merge(other *exclusiveEffectMetrics) {
+	eem.iterations += other.iterations
+	eem.activePercentSum += other.activePercentSum
+	eem.suppressedPercentSum += other.suppressedPercentSum
+	eem.blockedSum += other.blockedSum
+}
+
+// Whether the effect's aura was applied, or its application blocked, in any
+// iteration.
+func (eem *exclusiveEffectMetrics) wasApplied() bool {
+	return eem.activePercentSum > 0 || eem.suppressedPercentSum > 0 || eem.blockedSum > 0
+}
+
+func (eem *exclusiveEffectMetrics) ToProto() *proto.ExclusiveEffectMetrics {
+	n := float64(eem.iterations)
+	return &proto.ExclusiveEffectMetrics{
+		Id:                   eem.effect.Aura.ActionID.ToProto(),
+		Category:             eem.effect.Category.Name,
+		ActivePercentAvg:     eem.activePercentSum / n,
+		SuppressedPercentAvg: eem.suppressedPercentSum / n,
+		BlockedAvg:           float64(eem.blockedSum) / n,
+	}
+}
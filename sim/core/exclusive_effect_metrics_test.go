@@ -0,0 +1,38 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestExclusiveEffectMetrics(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{Iterations: 5, RandomSeed: 101})
+	request.Raid.Buffs = &proto.RaidBuffs{BattleShout: proto.TristateEffect_TristateEffectImproved}
+	request.Raid.Parties[0].Players[0].Buffs = &proto.IndividualBuffs{BlessingOfMight: proto.TristateEffect_TristateEffectRegular}
+
+	result := RunRaidSim(request)
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", result.ErrorResult)
+	}
+
+	byID := make(map[ActionID]*proto.ExclusiveEffectMetrics)
+	for _, eem := range result.RaidMetrics.Parties[0].Players[0].ExclusiveEffects {
+		byID[ProtoToActionID(eem.Id)] = eem
+	}
+	// Improved Battle Shout is stronger than regular Blessing of Might, so it
+	// suppresses it for the whole fight.
+	battleShout, blessingOfMight := byID[ActionID{SpellID: 47436}], byID[ActionID{SpellID: 48932}]
+	if battleShout == nil || blessingOfMight == nil {
+		t.Fatalf("Expected metrics for both attack power buffs, got %v", byID)
+	}
+	if battleShout.Category != "AttackPowerBonus" {
+		t.Fatalf("Expected the AttackPowerBonus category, got %s", battleShout.Category)
+	}
+	if !WithinToleranceFloat64(100, battleShout.ActivePercentAvg, 1e-6) || battleShout.SuppressedPercentAvg != 0 {
+		t.Fatalf("Expected Battle Shout to be active the whole fight, got %v", battleShout)
+	}
+	if blessingOfMight.ActivePercentAvg != 0 || !WithinToleranceFloat64(100, blessingOfMight.SuppressedPercentAvg, 1e-6) {
+		t.Fatalf("Expected Blessing of Might to be suppressed the whole fight, got %v", blessingOfMight)
+	}
+}
@@ -0,0 +1,148 @@
+package core
+
+// Chances of each outcome of a result, for SimOptions.expected_value. Instead
+// of rolling an outcome, the outcome appliers add up these chances and scale
+// the damage by its average over all of them. Attack table outcomes are
+// exclusive, but crits from a separate roll can also be blocked.
+type expectedOutcome struct {
+	active bool
+
+	// Cumulative chance of the attack table outcomes so far.
+	tableChance float64
+
+	miss   float64
+	dodge  float64
+	parry  float64
+	glance float64
+	block  float64
+	crit   float64
+
+	glanceMultiplier float64
+	critMultiplier   float64
+	blockValue       float64
+}
+
+func (result *SpellResult) startExpectedOutcome() {
+	result.expected = expectedOutcome{active: true}
+}
+
+// Rolls the attack table, or in expected value mode starts adding up the
+// chance of each outcome instead.
+func (result *SpellResult) rollAttackTable(sim *Simulation, label string) float64 {
+	if sim.expectedValue {
+		result.startExpectedOutcome()
+		return 0
+	}
+	return sim.RandomFloat(label)
+}
+
+// Returns the chance of the next attack table outcome, given the cumulative
+// chance of it and all the outcomes before it.
+func (eo *expectedOutcome) fromTable(chance float64) float64 {
+	before := clampChance(eo.tableChance)
+	eo.tableChance = chance
+	return max(0, clampChance(chance)-before)
+}
+
+// Returns the chance of a crit from a separate roll, which only happens if
+// the attack table outcomes so far didn't.
+func (eo *expectedOutcome) fromSeparateRoll(chance float64) float64 {
+	return (1 - clampChance(eo.tableChance)) * clampChance(chance)
+}
+
+func (eo *expectedOutcome) landed() float64 {
+	return max(0, 1-eo.miss-eo.dodge-eo.parry)
+}
+
+// Scales the damage by its average over the expected outcomes. The result
+// counts as a hit if it can land at all, or as a miss otherwise.
+func (result *SpellResult) applyExpectedOutcome(spell *Spell, countMetrics bool) {
+	eo := &result.expected
+	landed := eo.landed()
+	if landed == 0 {
+		result.Outcome = OutcomeMiss
+		result.Damage = 0
+		if countMetrics {
+			spell.SpellMetrics[result.Target.UnitIndex].Misses++
+		}
+		return
+	}
+
+	result.Damage *= landed + eo.glance*(eo.glanceMultiplier-1) + eo.crit*(eo.critMultiplier-1)
+	if eo.block > 0 {
+		// Block value is subtracted from each landed hit, not from the average.
+		blocked := eo.block * min(result.Damage/landed, eo.blockValue)
+		result.Damage -= blocked
+		result.BlockedDamage += blocked
+		spell.SpellMetrics[result.Target.UnitIndex].BlockedDamage += blocked
+	}
+
+	result.Outcome = OutcomeHit
+	if countMetrics {
+		spell.SpellMetrics[result.Target.UnitIndex].Hits++
+	}
+}
+
+// Applies the expected outcome of a hit roll followed by a crit roll.
+func (result *SpellResult) applyExpectedHitAndCrit(spell *Spell, missChance float64, critChance float64, countMetrics bool) {
+	result.startExpectedOutcome()
+	result.expected.miss = clampChance(missChance)
+	result.expected.crit = result.expected.landed() * clampChance(critChance)
+	result.expected.critMultiplier = spell.CritMultiplier
+	result.applyExpectedOutcome(spell, countMetrics)
+}
+
+// Returns the chance that the result has one of the given outcomes, which is
+// either 0 or 1 unless its outcome is an expected value.
+func (result *SpellResult) outcomeChance(outcome HitOutcome) float64 {
+	eo := &result.expected
+	if !eo.active {
+		if result.Outcome.Matches(outcome) {
+			return 1
+		}
+		return 0
+	}
+
+	hit := max(0, eo.landed()-eo.glance-eo.block-eo.crit)
+	chance := 0.0
+	for _, oc := range []struct {
+		outcome HitOutcome
+		chance  float64
+	}{
+		{OutcomeMiss, eo.miss},
+		{OutcomeDodge, eo.dodge},
+		{OutcomeParry, eo.parry},
+		{OutcomeGlance, eo.glance},
+		{OutcomeBlock, eo.block},
+		{OutcomeCrit, eo.crit},
+		{OutcomeHit, hit},
+	} {
+		if outcome.Matches(oc.outcome) {
+			chance += oc.chance
+		}
+	}
+	return min(chance, 1)
+}
+
+// Returns whether an effect with the given chance procs, in expected value
+// mode. Rather than rolling, each key adds up its expected number of procs,
+// and procs whenever that reaches another whole proc. The count starts at half
+// a proc, so procs happen on average when they would at random.
+func (sim *Simulation) expectedProc(key any, chance float64) bool {
+	expected, ok := sim.expectedProcs[key]
+	if !ok {
+		expected = 0.5
+	}
+	expected += clampChance(chance)
+
+	procced := expected >= 1
+	if procced {
+		expected--
+	}
+	sim.expectedProcs[key] = expected
+	return procced
+}
+
+func clampChance(chance float64) float64 {
+	return min(1, max(0, chance))
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"testing"
+)
+
+func setupExpectedValueSim() (*Simulation, *Spell, *Unit) {
+	sim := SetupFakeSim()
+	sim.expectedValue = true
+	sim.expectedProcs = make(map[any]float64)
+
+	spell := sim.Raid.Parties[0].Players[0].(*FakeAgent).Spell
+	spell.CritMultiplier = 2
+	return sim, spell, sim.GetTargetUnit(0)
+}
+
+func TestExpectedValueMagicOutcome(t *testing.T) {
+	sim, spell, target := setupExpectedValueSim()
+	attackTable := spell.Unit.AttackTables[target.UnitIndex]
+
+	hit := spell.CalcDamage(sim, target, 1000, spell.OutcomeAlwaysHit).Damage
+	result := spell.CalcDamage(sim, target, 1000, spell.OutcomeMagicHitAndCrit)
+
+	landed := 1 - spell.SpellChanceToMiss(attackTable)
+	crit := landed * spell.SpellCritChance(target)
+	if expected := hit * (landed + crit); !WithinToleranceFloat64(expected, result.Damage, 0.0001) {
+		t.Fatalf("Expected damage %f, got %f", expected, result.Damage)
+	}
+	if result.Outcome != OutcomeHit {
+		t.Fatalf("Expected the result to count as a hit, got %s", result.Outcome)
+	}
+	if chance := result.outcomeChance(OutcomeCrit); !WithinToleranceFloat64(crit, chance, 0.0001) {
+		t.Fatalf("Expected crit chance %f, got %f", crit, chance)
+	}
+	if chance := result.outcomeChance(OutcomeLanded); !WithinToleranceFloat64(landed, chance, 0.0001) {
+		t.Fatalf("Expected landed chance %f, got %f", landed, chance)
+	}
+}
+
+func TestExpectedValueMeleeOutcome(t *testing.T) {
+	sim, spell, target := setupExpectedValueSim()
+	attackTable := spell.Unit.AttackTables[target.UnitIndex]
+	spell.BonusCritRating = 20 * CritRatingPerCritChance
+
+	hit := spell.CalcDamage(sim, target, 1000, spell.OutcomeAlwaysHit).Damage
+	result := spell.CalcDamage(sim, target, 1000, spell.OutcomeMeleeSpecialHitAndCrit)
+
+	// Not in front of the target, so no parries or blocks.
+	landed := 1 - spell.PhysicalMissChance(attackTable, false) - spell.PhysicalDodgeChance(attackTable)
+	crit := landed * spell.PhysicalCritChance(attackTable)
+	if expected := hit * (landed + crit); !WithinToleranceFloat64(expected, result.Damage, 0.0001) {
+		t.Fatalf("Expected damage %f, got %f", expected, result.Damage)
+	}
+	if chance := result.outcomeChance(OutcomeParry | OutcomeBlock); chance != 0 {
+		t.Fatalf("Expected no chance to be parried or blocked, got %f", chance)
+	}
+}
+
+func TestExpectedProc(t *testing.T) {
+	sim, _, _ := setupExpectedValueSim()
+
+	var procs []int
+	for i := 1; i <= 8; i++ {
+		if sim.expectedProc("Proc", 0.25) {
+			procs = append(procs, i)
+		}
+	}
+	// Half a proc is counted up front, so the first one comes half way
+	// between where it would with no head start and with a whole proc.
+	if len(procs) != 2 || procs[0] != 2 || procs[1] != 6 {
+		t.Fatalf("Expected procs on events 2 and 6, got %v", procs)
+	}
+
+	sim.Reset()
+	if sim.expectedProc("Proc", 0.25) {
+		t.Fatalf("Expected reset to clear the expected procs")
+	}
+}
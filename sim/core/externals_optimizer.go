@@ -0,0 +1,299 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	goproto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+const (
+	defaultExternalsOptimizerIterations = 1000
+	defaultHeroismTimingStep            = 15.0
+)
+
+// Externals which are assigned through a UnitReference field in the provider's
+// spec options or rotation.
+var targetedExternals = []struct {
+	actionID    ActionID
+	fieldName   protoreflect.Name
+	canSelfCast bool
+}{
+	{ActionID{SpellID: 29166}, "innervate_target", true},
+	{ActionID{SpellID: 10060}, "power_infusion_target", true},
+	{ActionID{SpellID: 49016}, "unholy_frenzy_target", false},
+	{ActionID{SpellID: 54648}, "focus_magic_target", false},
+}
+
+// A single external which can be reassigned by the optimizer.
+type raidExternal struct {
+	partyIndex  int
+	playerIndex int
+
+	// The current assignment, and the alternatives to try.
+	current    *proto.ExternalAssignment
+	candidates []*proto.ExternalAssignment
+
+	apply func(player *proto.Player, assignment *proto.ExternalAssignment)
+}
+
+// Finds the assignment of raid externals which maximizes raid DPS, using
+// coordinate descent: each external is optimized in turn while the others are
+// held fixed. Returns the updated request along with the chosen assignments.
+func optimizeExternals(rsr *proto.RaidSimRequest) (*proto.RaidSimRequest, []*proto.ExternalAssignment, error) {
+	settings := rsr.ExternalsOptimizer
+
+	base := goproto.Clone(rsr).(*proto.RaidSimRequest)
+	base.ExternalsOptimizer = nil
+	base.SimOptions.Debug = false
+	base.SimOptions.DebugFirstIteration = false
+	base.SimOptions.ReplayIteration = nil
+	base.SimOptions.OutlierIterations = 0
+	base.SimOptions.Timeline = nil
+	base.SimOptions.DamageBuckets = nil
+	base.SimOptions.ResourceTimelines = false
+	base.SimOptions.Iterations = settings.Iterations
+	if base.SimOptions.Iterations <= 0 {
+		base.SimOptions.Iterations = defaultExternalsOptimizerIterations
+	}
+	// Use the same seed for every candidate, so they're compared on equal terms.
+	if base.SimOptions.RandomSeed == 0 {
+		base.SimOptions.RandomSeed = time.Now().UnixNano()
+	}
+
+	baseline := runSim(base, nil, false)
+	if baseline.ErrorResult != "" {
+		return nil, nil, errors.New(baseline.ErrorResult)
+	}
+	bestDps := baseline.RaidMetrics.Dps.Avg
+
+	externals := findRaidExternals(base, baseline, settings)
+	assignments := make([]*proto.ExternalAssignment, 0, len(externals))
+	for _, external := range externals {
+		chosen, dps, err := external.chooseCandidate(bestDps, func(candidate *proto.ExternalAssignment) (float64, error) {
+			request := goproto.Clone(base).(*proto.RaidSimRequest)
+			external.apply(request.Raid.Parties[external.partyIndex].Players[external.playerIndex], candidate)
+
+			result := runSim(request, nil, false)
+			if result.ErrorResult != "" {
+				return 0, errors.New(result.ErrorResult)
+			}
+			return result.RaidMetrics.Dps.Avg, nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		bestDps = dps
+
+		external.apply(base.Raid.Parties[external.partyIndex].Players[external.playerIndex], chosen)
+		assignments = append(assignments, chosen)
+	}
+
+	optimized := goproto.Clone(base).(*proto.RaidSimRequest)
+	optimized.SimOptions = rsr.SimOptions
+	return optimized, assignments, nil
+}
+
+// Returns the candidate with the highest DPS, or the current assignment if
+// none beat bestDps, along with its DPS. The chosen candidate's DpsGain is
+// measured against bestDps, i.e. the DPS before this external was optimized.
+func (external *raidExternal) chooseCandidate(bestDps float64, candidateDps func(candidate *proto.ExternalAssignment) (float64, error)) (*proto.ExternalAssignment, float64, error) {
+	prevDps := bestDps
+	chosen := external.current
+	for _, candidate := range external.candidates {
+		dps, err := candidateDps(candidate)
+		if err != nil {
+			return nil, 0, err
+		}
+		if dps > bestDps {
+			bestDps = dps
+			chosen = candidate
+		}
+	}
+	if chosen != external.current {
+		chosen.DpsGain = bestDps - prevDps
+	}
+	return chosen, bestDps, nil
+}
+
+func findRaidExternals(rsr *proto.RaidSimRequest, baseline *proto.RaidSimResult, settings *proto.ExternalsOptimizerSettings) []*raidExternal {
+	var targets []*proto.UnitReference
+	forEachRaidPlayer(rsr.Raid, baseline, func(partyIndex int, playerIndex int, _ *proto.UnitMetrics, player *proto.Player) {
+		targets = append(targets, playerUnitReference(partyIndex, playerIndex))
+	})
+
+	var externals []*raidExternal
+	forEachRaidPlayer(rsr.Raid, baseline, func(partyIndex int, playerIndex int, playerMetrics *proto.UnitMetrics, player *proto.Player) {
+		source := playerUnitReference(partyIndex, playerIndex)
+
+		for _, te := range targetedExternals {
+			fieldName := te.fieldName
+			msg, fd := findExternalTargetField(player, fieldName)
+			if msg == nil {
+				continue
+			}
+
+			external := &raidExternal{
+				partyIndex:  partyIndex,
+				playerIndex: playerIndex,
+				current: &proto.ExternalAssignment{
+					Source: source,
+					Id:     te.actionID.ToProto(),
+				},
+				apply: func(player *proto.Player, assignment *proto.ExternalAssignment) {
+					msg, fd := findExternalTargetField(player, fieldName)
+					if assignment.Target == nil {
+						msg.Clear(fd)
+					} else {
+						msg.Set(fd, protoreflect.ValueOfMessage(goproto.Clone(assignment.Target).ProtoReflect()))
+					}
+				},
+			}
+			if msg.Has(fd) {
+				external.current.Target = goproto.Clone(msg.Get(fd).Message().Interface()).(*proto.UnitReference)
+			}
+			for _, target := range targets {
+				if !te.canSelfCast && target.Index == source.Index {
+					continue
+				}
+				external.candidates = append(external.candidates, &proto.ExternalAssignment{
+					Source: source,
+					Id:     te.actionID.ToProto(),
+					Target: target,
+				})
+			}
+			externals = append(externals, external)
+		}
+
+		// Only optimize Heroism timings for players which actually cast it. APL
+		// rotations ignore the cooldown timings, so those are left alone.
+		if player.Class == proto.Class_ClassShaman && player.Rotation.GetType() != proto.APLRotation_TypeAPL {
+			heroismID := ActionID{SpellID: BloodlustActionID.SpellID, Tag: int32(partyIndex*5 + playerIndex)}
+			if hasActionMetrics(playerMetrics, heroismID) {
+				externals = append(externals, newHeroismExternal(rsr, partyIndex, playerIndex, heroismID, settings))
+			}
+		}
+	})
+
+	return externals
+}
+
+func newHeroismExternal(rsr *proto.RaidSimRequest, partyIndex int, playerIndex int, heroismID ActionID, settings *proto.ExternalsOptimizerSettings) *raidExternal {
+	source := playerUnitReference(partyIndex, playerIndex)
+	player := rsr.Raid.Parties[partyIndex].Players[playerIndex]
+
+	external := &raidExternal{
+		partyIndex:  partyIndex,
+		playerIndex: playerIndex,
+		current: &proto.ExternalAssignment{
+			Source: source,
+			Id:     heroismID.ToProto(),
+		},
+		apply: func(player *proto.Player, assignment *proto.ExternalAssignment) {
+			if player.Cooldowns == nil {
+				player.Cooldowns = &proto.Cooldowns{}
+			}
+			for _, cd := range player.Cooldowns.Cooldowns {
+				if ProtoToActionID(cd.Id).SameAction(heroismID) {
+					cd.Timings = assignment.Timings
+					return
+				}
+			}
+			player.Cooldowns.Cooldowns = append(player.Cooldowns.Cooldowns, &proto.Cooldown{
+				Id:      heroismID.ToProto(),
+				Timings: assignment.Timings,
+			})
+		},
+	}
+	for _, cd := range player.GetCooldowns().GetCooldowns() {
+		if ProtoToActionID(cd.Id).SameAction(heroismID) {
+			external.current.Timings = cd.Timings
+		}
+	}
+
+	step := settings.HeroismTimingStep
+	if step <= 0 {
+		step = defaultHeroismTimingStep
+	}
+	for timing := 0.0; timing < rsr.Encounter.Duration; timing += step {
+		external.candidates = append(external.candidates, &proto.ExternalAssignment{
+			Source:  source,
+			Id:      heroismID.ToProto(),
+			Timings: []float64{timing},
+		})
+	}
+
+	return external
+}
+
+// Calls f for each player in the raid, along with the player's metrics from
+// the given result.
+func forEachRaidPlayer(raid *proto.Raid, result *proto.RaidSimResult, f func(partyIndex int, playerIndex int, playerMetrics *proto.UnitMetrics, player *proto.Player)) {
+	// Mirrors the way NewRaid skips inactive parties and empty slots.
+	numParties := int(raid.NumActiveParties)
+	if numParties == 0 {
+		numParties = len(raid.Parties)
+	}
+
+	partyMetricsIndex := 0
+	for partyIndex, party := range raid.Parties {
+		if party == nil || partyIndex >= numParties {
+			continue
+		}
+		partyMetrics := result.RaidMetrics.Parties[partyMetricsIndex]
+		partyMetricsIndex++
+
+		playerMetricsIndex := 0
+		for playerIndex, player := range party.Players {
+			if player == nil || player.Class == proto.Class_ClassUnknown {
+				continue
+			}
+			f(partyIndex, playerIndex, partyMetrics.Players[playerMetricsIndex], player)
+			playerMetricsIndex++
+		}
+	}
+}
+
+func playerUnitReference(partyIndex int, playerIndex int) *proto.UnitReference {
+	return &proto.UnitReference{
+		Type:  proto.UnitReference_Player,
+		Index: int32(partyIndex*5 + playerIndex),
+	}
+}
+
+func hasActionMetrics(unitMetrics *proto.UnitMetrics, actionID ActionID) bool {
+	for _, action := range unitMetrics.Actions {
+		if ProtoToActionID(action.Id).SameAction(actionID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the message and field holding the given external's target in the
+// player's spec options or rotation, or nil if the player's spec doesn't
+// provide that external.
+func findExternalTargetField(player *proto.Player, fieldName protoreflect.Name) (protoreflect.Message, protoreflect.FieldDescriptor) {
+	playerMsg := player.ProtoReflect()
+	specField := playerMsg.WhichOneof(playerMsg.Descriptor().Oneofs().ByName("spec"))
+	if specField == nil || specField.Message() == nil {
+		return nil, nil
+	}
+	specMsg := playerMsg.Mutable(specField).Message()
+
+	unitReferenceName := (&proto.UnitReference{}).ProtoReflect().Descriptor().FullName()
+	for _, name := range []protoreflect.Name{"options", "rotation"} {
+		fd := specMsg.Descriptor().Fields().ByName(name)
+		if fd == nil || fd.Message() == nil {
+			continue
+		}
+		targetFd := fd.Message().Fields().ByName(fieldName)
+		if targetFd != nil && targetFd.Message() != nil && targetFd.Message().FullName() == unitReferenceName {
+			return specMsg.Mutable(fd).Message(), targetFd
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestFindExternalTargetField(t *testing.T) {
+	druid := &proto.Player{
+		Spec: &proto.Player_BalanceDruid{
+			BalanceDruid: &proto.BalanceDruid{},
+		},
+	}
+	msg, fd := findExternalTargetField(druid, "innervate_target")
+	if msg == nil {
+		t.Fatalf("Expected to find innervate_target for Balance Druid")
+	}
+	msg.Set(fd, msg.NewField(fd))
+	if druid.GetBalanceDruid().GetOptions().GetInnervateTarget() == nil {
+		t.Fatalf("Expected innervate_target to be set on the player's options")
+	}
+
+	if msg, _ := findExternalTargetField(druid, "power_infusion_target"); msg != nil {
+		t.Fatalf("Did not expect to find power_infusion_target for Balance Druid")
+	}
+
+	dk := &proto.Player{
+		Spec: &proto.Player_Deathknight{
+			Deathknight: &proto.Deathknight{},
+		},
+	}
+	if msg, _ := findExternalTargetField(dk, "unholy_frenzy_target"); msg == nil {
+		t.Fatalf("Expected to find unholy_frenzy_target for Deathknight")
+	}
+}
+
+func TestFindRaidExternals(t *testing.T) {
+	rsr := &proto.RaidSimRequest{
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{{
+				Players: []*proto.Player{
+					{
+						Class: proto.Class_ClassMage,
+						Spec:  &proto.Player_Mage{Mage: &proto.Mage{}},
+					},
+					{
+						Class:    proto.Class_ClassShaman,
+						Spec:     &proto.Player_ElementalShaman{ElementalShaman: &proto.ElementalShaman{}},
+						Rotation: &proto.APLRotation{Type: proto.APLRotation_TypeAPL},
+					},
+				},
+			}},
+		},
+		Encounter: &proto.Encounter{Duration: 60},
+	}
+	heroismID := ActionID{SpellID: BloodlustActionID.SpellID, Tag: 1}
+	baseline := &proto.RaidSimResult{
+		RaidMetrics: &proto.RaidMetrics{
+			Parties: []*proto.PartyMetrics{{
+				Players: []*proto.UnitMetrics{
+					{},
+					{Actions: []*proto.ActionMetrics{{Id: heroismID.ToProto()}}},
+				},
+			}},
+		},
+	}
+
+	externals := findRaidExternals(rsr, baseline, &proto.ExternalsOptimizerSettings{})
+	if len(externals) != 1 {
+		t.Fatalf("Expected only Focus Magic to be optimized, got %d externals", len(externals))
+	}
+	// Focus Magic can't be cast on the mage themself.
+	if candidates := externals[0].candidates; len(candidates) != 1 || candidates[0].Target.Index != 1 {
+		t.Fatalf("Expected Focus Magic to only target the other player, got %v", candidates)
+	}
+
+	// Heroism timings only apply to players using the old rotations.
+	rsr.Raid.Parties[0].Players[1].Rotation = nil
+	externals = findRaidExternals(rsr, baseline, &proto.ExternalsOptimizerSettings{})
+	if len(externals) != 2 || !ProtoToActionID(externals[1].current.Id).SameAction(heroismID) {
+		t.Fatalf("Expected Heroism timings to be optimized for a non-APL shaman")
+	}
+}
+
+func TestChooseExternalCandidate(t *testing.T) {
+	weak := &proto.ExternalAssignment{Target: playerUnitReference(0, 1)}
+	strong := &proto.ExternalAssignment{Target: playerUnitReference(0, 2)}
+	worse := &proto.ExternalAssignment{Target: playerUnitReference(0, 3)}
+	external := &raidExternal{
+		current:    &proto.ExternalAssignment{},
+		candidates: []*proto.ExternalAssignment{weak, strong, worse},
+	}
+	candidateDps := map[*proto.ExternalAssignment]float64{weak: 110, strong: 120, worse: 90}
+
+	chosen, dps, err := external.chooseCandidate(100, func(candidate *proto.ExternalAssignment) (float64, error) {
+		return candidateDps[candidate], nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if chosen != strong || dps != 120 {
+		t.Fatalf("Expected the strongest candidate with 120 DPS, got %v with %f DPS", chosen, dps)
+	}
+	// Measured against the DPS before this external, not the earlier candidate.
+	if chosen.DpsGain != 20 {
+		t.Fatalf("Expected a DPS gain of 20, got %f", chosen.DpsGain)
+	}
+
+	chosen, _, _ = external.chooseCandidate(200, func(candidate *proto.ExternalAssignment) (float64, error) {
+		return candidateDps[candidate], nil
+	})
+	if chosen != external.current {
+		t.Fatalf("Expected to keep the current assignment when no candidate is better")
+	}
+}
@@ -197,6 +197,7 @@ const (
 	SpellFlagPotion                                         // Indicates this spell is a potion spell.
 	SpellFlagPrepullPotion                                  // Indicates this spell is the prepull potion.
 	SpellFlagCombatPotion                                   // Indicates this spell is the combat potion.
+	SpellFlagResetsSwingTimer                               // Stops melee swings while casting, restarting them with a full swing timer once the cast completes.
 
 	// Used to let agents categorize their spells.
 	SpellFlagAgentReserved1
@@ -57,7 +57,7 @@ func (fb *focusBar) AddFocus(sim *Simulation, amount float64, metrics *ResourceM
 	metrics.AddEvent(amount, newFocus-fb.currentFocus)
 
 	if sim.Log != nil {
-		fb.unit.Log(sim, "Gained %0.3f focus from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, fb.currentFocus, newFocus)
+		fb.unit.logResource(sim, LogEventResourceGain, proto.ResourceType_ResourceTypeFocus, amount, metrics, fb.currentFocus, newFocus)
 	}
 
 	fb.currentFocus = newFocus
@@ -76,7 +76,7 @@ func (fb *focusBar) SpendFocus(sim *Simulation, amount float64, metrics *Resourc
 	metrics.AddEvent(-amount, -amount)
 
 	if sim.Log != nil {
-		fb.unit.Log(sim, "Spent %0.3f focus from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, fb.currentFocus, newFocus)
+		fb.unit.logResource(sim, LogEventResourceSpend, proto.ResourceType_ResourceTypeFocus, amount, metrics, fb.currentFocus, newFocus)
 	}
 
 	fb.currentFocus = newFocus
@@ -96,7 +96,11 @@ func (fb *focusBar) reset(sim *Simulation) {
 
 func (fb *focusBar) enable(sim *Simulation) {
 	sim.AddTask(fb)
-	fb.nextFocusTick = sim.CurrentTime + tickDuration
+	if firstTick, ok := sim.alignedResourceTick(sim.CurrentTime, tickDuration); ok {
+		fb.nextFocusTick = firstTick
+	} else {
+		fb.nextFocusTick = sim.CurrentTime + tickDuration
+	}
 	sim.RescheduleTask(fb.nextFocusTick)
 }
 
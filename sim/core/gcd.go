@@ -5,6 +5,41 @@ import (
 	"time"
 )
 
+// Which global cooldown a spell triggers.
+type GCDCategory uint8
+
+const (
+	// Triggers the spell's default GCD, reduced by haste unless the cast
+	// ignores haste. Spells without a GCD are off the GCD, but like any other
+	// major cooldown, the APL still waits for the GCD to use them.
+	GCDCategoryDefault GCDCategory = iota
+
+	// Triggers its GCD reduced by haste, but never below GCDMin.
+	GCDCategoryNormal
+
+	// Triggers its GCD unaffected by haste, e.g. the 1s GCD of energy abilities
+	// and totems.
+	GCDCategoryFixed
+
+	// Neither triggers nor waits for the GCD, e.g. most trinkets and racials.
+	// The APL can weave these between casts, even if they're major cooldowns.
+	GCDCategoryOffGCD
+)
+
+func (config *SpellConfig) validateGCDCategory() {
+	gcd := config.Cast.DefaultCast.GCD
+	switch config.GCDCategory {
+	case GCDCategoryNormal, GCDCategoryFixed:
+		if gcd == 0 {
+			panic(fmt.Sprintf("Spell %s on the GCD has no GCD", config.ActionID))
+		}
+	case GCDCategoryOffGCD:
+		if gcd != 0 {
+			panic(fmt.Sprintf("Off-GCD spell %s has a GCD of %s", config.ActionID, gcd))
+		}
+	}
+}
+
 // Note that this is only used when the hardcast and GCD actions happen at different times.
 func (unit *Unit) newHardcastAction(sim *Simulation) {
 	if unit.hardcastAction != nil && !unit.hardcastAction.consumed {
@@ -50,11 +85,11 @@ func (unit *Unit) SetGCDTimer(sim *Simulation, gcdReadyAt time.Duration) {
 	} else {
 		unit.gcdAction.Cancel(sim)
 		oldAction := unit.gcdAction.OnAction
-		unit.gcdAction = &PendingAction{
+		unit.gcdAction = unit.gcdActions.get(sim, PendingAction{
 			NextActionAt: gcdReadyAt,
 			Priority:     ActionPriorityGCD,
 			OnAction:     oldAction,
-		}
+		})
 	}
 	sim.AddPendingAction(unit.gcdAction)
 }
@@ -127,6 +162,7 @@ func (unit *Unit) HardcastWaitUntil(sim *Simulation, readyTime time.Duration, on
 
 	unit.Hardcast.Expires = readyTime
 	unit.Hardcast.OnComplete = onComplete
+	unit.Hardcast.spell = nil
 	unit.newHardcastAction(sim)
 }
 
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGCDCategories(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	target := sim.GetTargetUnit(0)
+
+	registerSpell := func(spellID int32, category GCDCategory, gcd time.Duration, flags SpellFlag) *Spell {
+		return fa.RegisterSpell(SpellConfig{
+			ActionID:    ActionID{SpellID: spellID},
+			Flags:       flags,
+			GCDCategory: category,
+			Cast: CastConfig{
+				DefaultCast: Cast{GCD: gcd},
+				CD: Cooldown{
+					Timer:    fa.NewTimer(),
+					Duration: time.Minute,
+				},
+			},
+		})
+	}
+	normal := registerSpell(1, GCDCategoryNormal, GCDDefault, 0)
+	fixed := registerSpell(2, GCDCategoryFixed, time.Second, 0)
+	offGCD := registerSpell(3, GCDCategoryOffGCD, 0, SpellFlagMCD)
+	legacyMCD := registerSpell(4, GCDCategoryDefault, 0, SpellFlagMCD)
+
+	fa.MultiplyCastSpeed(1.25)
+
+	normal.Cast(sim, target)
+	if readyAt := fa.GCD.ReadyAt(); readyAt != time.Millisecond*1200 {
+		t.Fatalf("Expected the normal GCD to be hasted to 1.2s, got %s", readyAt)
+	}
+
+	// Off-GCD spells can be woven by the APL while the GCD is running, unlike
+	// other major cooldowns.
+	castOffGCD := &APLActionCastSpell{spell: offGCD, target: UnitReference{fixedUnit: target}}
+	castLegacyMCD := &APLActionCastSpell{spell: legacyMCD, target: UnitReference{fixedUnit: target}}
+	if !castOffGCD.IsReady(sim) || castLegacyMCD.IsReady(sim) {
+		t.Fatalf("Expected only the off-GCD spell to be ready during the GCD")
+	}
+	offGCD.Cast(sim, target)
+	if readyAt := fa.GCD.ReadyAt(); readyAt != time.Millisecond*1200 {
+		t.Fatalf("Expected the off-GCD spell not to affect the GCD, got %s", readyAt)
+	}
+
+	sim.CurrentTime = fa.GCD.ReadyAt()
+	fixed.Cast(sim, target)
+	if readyAt := fa.GCD.ReadyAt(); readyAt != sim.CurrentTime+time.Second {
+		t.Fatalf("Expected the fixed GCD to ignore haste, got %s", readyAt-sim.CurrentTime)
+	}
+}
+
+func TestGCDCategoryValidation(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+
+	testCases := []struct {
+		name     string
+		category GCDCategory
+		gcd      time.Duration
+	}{
+		{"OffGCDWithGCD", GCDCategoryOffGCD, GCDDefault},
+		{"NormalWithoutGCD", GCDCategoryNormal, 0},
+		{"FixedWithoutGCD", GCDCategoryFixed, 0},
+	}
+
+	for i, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("Expected RegisterSpell to panic")
+				}
+			}()
+			fa.RegisterSpell(SpellConfig{
+				ActionID:    ActionID{SpellID: int32(100 + i)},
+				GCDCategory: testCase.category,
+				Cast:        CastConfig{DefaultCast: Cast{GCD: testCase.gcd}},
+			})
+		})
+	}
+}
@@ -0,0 +1,445 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	goproto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+// The colors a gem counts as for socket bonuses and meta gem requirements.
+type gemColorMask uint8
+
+const (
+	gemColorMaskRed gemColorMask = 1 << iota
+	gemColorMaskYellow
+	gemColorMaskBlue
+)
+
+func gemColorToMask(color proto.GemColor) gemColorMask {
+	switch color {
+	case proto.GemColor_GemColorRed:
+		return gemColorMaskRed
+	case proto.GemColor_GemColorYellow:
+		return gemColorMaskYellow
+	case proto.GemColor_GemColorBlue:
+		return gemColorMaskBlue
+	case proto.GemColor_GemColorOrange:
+		return gemColorMaskRed | gemColorMaskYellow
+	case proto.GemColor_GemColorGreen:
+		return gemColorMaskYellow | gemColorMaskBlue
+	case proto.GemColor_GemColorPurple:
+		return gemColorMaskRed | gemColorMaskBlue
+	case proto.GemColor_GemColorPrismatic:
+		return gemColorMaskRed | gemColorMaskYellow | gemColorMaskBlue
+	}
+	return 0
+}
+
+// Number of red, yellow and blue gems.
+type gemColorCounts [3]int
+
+func (counts gemColorCounts) add(mask gemColorMask) gemColorCounts {
+	for i := range counts {
+		if mask&(1<<i) != 0 {
+			counts[i]++
+		}
+	}
+	return counts
+}
+
+func (counts gemColorCounts) capped(caps gemColorCounts) gemColorCounts {
+	for i := range counts {
+		counts[i] = min(counts[i], caps[i])
+	}
+	return counts
+}
+
+// The empty sockets of a single item.
+type gemFillItem struct {
+	slot proto.ItemSlot
+	item Item
+
+	emptySockets []int
+
+	// Whether all sockets which already have a gem match their color.
+	filledSocketsMatch bool
+}
+
+type gemSocketRef struct {
+	slot        proto.ItemSlot
+	socketIndex int
+}
+
+// The best way to fill an item's empty sockets, for some change in color counts.
+type gemFillOption struct {
+	value float64
+	masks []gemColorMask // Per empty socket.
+}
+
+type gemFiller struct {
+	weights stats.Stats
+
+	// Best gem without equip restrictions for each exact color mask.
+	bestGems map[gemColorMask]Gem
+
+	// Unique gems and gems requiring a profession, best first.
+	restrictedGems []Gem
+
+	metaGems []Gem
+
+	professions []proto.Profession
+}
+
+// Fills the empty sockets of the given equipment to maximize the expected DPS
+// from the stat weights. Socket bonuses are taken when worthwhile, and the meta
+// gem is kept active whenever its requirement can be met.
+func fillGems(request *proto.FillGemsRequest) (result *proto.FillGemsResult) {
+	defer func() {
+		if err := recover(); err != nil {
+			result = &proto.FillGemsResult{
+				ErrorResult: fmt.Sprintf("%v", err),
+			}
+		}
+	}()
+
+	filler := newGemFiller(request)
+
+	equipment := goproto.Clone(request.Equipment).(*proto.EquipmentSpec)
+	var items []*gemFillItem
+	var metaSocket *gemSocketRef
+	var metaGem Gem
+	var counts gemColorCounts
+	usedGems := make(map[int32]int)
+	for i, itemSpec := range equipment.Items {
+		if itemSpec.GetId() == 0 {
+			continue
+		}
+		item := NewItem(ItemSpec{ID: itemSpec.Id, Gems: itemSpec.Gems, Ilvl: itemSpec.Ilvl})
+
+		fillItem := &gemFillItem{
+			slot:               proto.ItemSlot(i),
+			item:               item,
+			filledSocketsMatch: true,
+		}
+		for socketIdx, socketColor := range item.GemSockets {
+			var gem Gem
+			if socketIdx < len(item.Gems) {
+				gem = item.Gems[socketIdx]
+			}
+
+			if socketColor == proto.GemColor_GemColorMeta {
+				if gem.ID == 0 {
+					metaSocket = &gemSocketRef{slot: fillItem.slot, socketIndex: socketIdx}
+				} else {
+					metaGem = gem
+				}
+			} else if gem.ID == 0 {
+				fillItem.emptySockets = append(fillItem.emptySockets, socketIdx)
+			} else {
+				counts = counts.add(gemColorToMask(gem.Color))
+				fillItem.filledSocketsMatch = fillItem.filledSocketsMatch && ColorIntersects(socketColor, gem.Color)
+			}
+		}
+		for _, gem := range item.Gems {
+			if gem.ID != 0 {
+				usedGems[gem.ID]++
+			}
+		}
+
+		if len(fillItem.emptySockets) > 0 {
+			items = append(items, fillItem)
+		}
+	}
+
+	// Try each meta gem which could fill the socket, keeping the one with the
+	// best total value.
+	metaCandidates := []Gem{metaGem}
+	if metaSocket != nil {
+		metaCandidates = filler.metaGems
+		if len(metaCandidates) == 0 {
+			metaCandidates = []Gem{{}}
+		}
+	}
+
+	var bestAssignment [][]gemColorMask
+	var bestMeta Gem
+	var bestValue float64
+	for _, candidate := range metaCandidates {
+		condition := MetaGemConditions[candidate.ID]
+		assignment, value, ok := filler.solve(items, counts, condition)
+		if !ok {
+			continue
+		}
+		if metaSocket != nil {
+			value += filler.value(candidate)
+		}
+		if bestAssignment == nil || value > bestValue {
+			bestAssignment, bestMeta, bestValue = assignment, candidate, value
+		}
+	}
+	if bestAssignment == nil {
+		// The meta requirement can't be met, so just maximize stats.
+		bestMeta = metaCandidates[0]
+		bestAssignment, bestValue, _ = filler.solve(items, counts, MetaGemCondition{})
+		if metaSocket != nil {
+			bestValue += filler.value(bestMeta)
+		}
+	}
+
+	// Assign gems, then upgrade to restricted gems wherever they keep the same
+	// colors.
+	chosen := make([][]Gem, len(items))
+	for i, masks := range bestAssignment {
+		chosen[i] = MapSlice(masks, func(mask gemColorMask) Gem { return filler.bestGems[mask] })
+	}
+	bestValue += filler.placeRestrictedGems(items, bestAssignment, chosen, usedGems)
+
+	result = &proto.FillGemsResult{
+		Equipment: equipment,
+		DpsGain:   bestValue,
+	}
+	setGem := func(socket gemSocketRef, gem Gem) {
+		if gem.ID == 0 {
+			return
+		}
+		itemSpec := equipment.Items[socket.slot]
+		for len(itemSpec.Gems) <= socket.socketIndex {
+			itemSpec.Gems = append(itemSpec.Gems, 0)
+		}
+		itemSpec.Gems[socket.socketIndex] = gem.ID
+		result.Choices = append(result.Choices, &proto.GemChoice{
+			Slot:        socket.slot,
+			SocketIndex: int32(socket.socketIndex),
+			GemId:       gem.ID,
+		})
+	}
+	if metaSocket != nil {
+		setGem(*metaSocket, bestMeta)
+	}
+	for i, fillItem := range items {
+		for j, socketIdx := range fillItem.emptySockets {
+			counts = counts.add(gemColorToMask(chosen[i][j].Color))
+			setGem(gemSocketRef{slot: fillItem.slot, socketIndex: socketIdx}, chosen[i][j])
+		}
+	}
+
+	if bestMeta.ID != 0 {
+		result.MetaGemActive = MetaGemConditions[bestMeta.ID].IsMet(counts[0], counts[1], counts[2])
+	}
+	return result
+}
+
+func newGemFiller(request *proto.FillGemsRequest) *gemFiller {
+	filler := &gemFiller{
+		weights:     stats.FromFloatArray(request.GetStatWeights().GetStats()),
+		bestGems:    make(map[gemColorMask]Gem),
+		professions: request.Professions,
+	}
+
+	if request.MetaGemId != 0 {
+		gem, ok := GemsByID[request.MetaGemId]
+		if !ok {
+			panic(fmt.Sprintf("No gem with id: %d", request.MetaGemId))
+		}
+		filler.metaGems = append(filler.metaGems, gem)
+	}
+
+	for _, gemID := range request.GemIds {
+		gem, ok := GemsByID[gemID]
+		if !ok {
+			panic(fmt.Sprintf("No gem with id: %d", gemID))
+		}
+
+		if gem.RequiredProfession != proto.Profession_ProfessionUnknown && !slices.Contains(filler.professions, gem.RequiredProfession) {
+			continue
+		}
+
+		if gem.Color == proto.GemColor_GemColorMeta {
+			if request.MetaGemId == 0 {
+				filler.metaGems = append(filler.metaGems, gem)
+			}
+		} else if gem.Unique || gem.RequiredProfession != proto.Profession_ProfessionUnknown {
+			filler.restrictedGems = append(filler.restrictedGems, gem)
+		} else if mask := gemColorToMask(gem.Color); mask != 0 {
+			if best, ok := filler.bestGems[mask]; !ok || filler.value(gem) > filler.value(best) {
+				filler.bestGems[mask] = gem
+			}
+		}
+	}
+
+	sort.SliceStable(filler.restrictedGems, func(i, j int) bool {
+		return filler.value(filler.restrictedGems[i]) > filler.value(filler.restrictedGems[j])
+	})
+	return filler
+}
+
+func (filler *gemFiller) value(gem Gem) float64 {
+	value := 0.0
+	for i, weight := range filler.weights {
+		value += weight * gem.Stats[i]
+	}
+	return value
+}
+
+// Returns the best fill for each possible change in color counts from filling
+// the item's empty sockets.
+func (filler *gemFiller) itemOptions(fillItem *gemFillItem) map[gemColorCounts]gemFillOption {
+	masks := make([]gemColorMask, 0, len(filler.bestGems))
+	for mask := range filler.bestGems {
+		masks = append(masks, mask)
+	}
+	sort.Slice(masks, func(i, j int) bool { return masks[i] < masks[j] })
+
+	options := make(map[gemColorCounts]gemFillOption)
+	if len(masks) == 0 {
+		options[gemColorCounts{}] = gemFillOption{masks: make([]gemColorMask, len(fillItem.emptySockets))}
+		return options
+	}
+
+	assignment := make([]gemColorMask, len(fillItem.emptySockets))
+	var enumerate func(socket int)
+	enumerate = func(socket int) {
+		if socket < len(assignment) {
+			for _, mask := range masks {
+				assignment[socket] = mask
+				enumerate(socket + 1)
+			}
+			return
+		}
+
+		var delta gemColorCounts
+		value := 0.0
+		bonusActive := fillItem.filledSocketsMatch
+		for i, mask := range assignment {
+			gem := filler.bestGems[mask]
+			delta = delta.add(mask)
+			value += filler.value(gem)
+			bonusActive = bonusActive && ColorIntersects(fillItem.item.GemSockets[fillItem.emptySockets[i]], gem.Color)
+		}
+		if bonusActive {
+			value += filler.value(Gem{Stats: fillItem.item.SocketBonus})
+		}
+
+		if best, ok := options[delta]; !ok || value > best.value {
+			options[delta] = gemFillOption{value: value, masks: append([]gemColorMask(nil), assignment...)}
+		}
+	}
+	enumerate(0)
+	return options
+}
+
+// Chooses a color mask for each empty socket, maximizing value while meeting
+// the meta gem condition. Color counts are capped at what the condition needs,
+// which keeps the number of states small.
+func (filler *gemFiller) solve(items []*gemFillItem, counts gemColorCounts, condition MetaGemCondition) ([][]gemColorMask, float64, bool) {
+	caps := gemColorCounts{condition.MinRed, condition.MinYellow, condition.MinBlue}
+	if condition.CompareGreater != proto.GemColor_GemColorUnknown {
+		numSockets := counts[0] + counts[1] + counts[2]
+		for _, fillItem := range items {
+			numSockets += len(fillItem.emptySockets)
+		}
+		caps = gemColorCounts{numSockets, numSockets, numSockets}
+	}
+
+	type state struct {
+		value float64
+		prev  gemColorCounts
+		masks []gemColorMask
+	}
+	stages := make([]map[gemColorCounts]state, len(items)+1)
+	stages[0] = map[gemColorCounts]state{counts.capped(caps): {}}
+	for i, fillItem := range items {
+		options := filler.itemOptions(fillItem)
+		stages[i+1] = make(map[gemColorCounts]state)
+		for prevCounts, prevState := range stages[i] {
+			for delta, option := range options {
+				newCounts := prevCounts
+				for c := range newCounts {
+					newCounts[c] += delta[c]
+				}
+				newCounts = newCounts.capped(caps)
+
+				value := prevState.value + option.value
+				if cur, ok := stages[i+1][newCounts]; !ok || value > cur.value {
+					stages[i+1][newCounts] = state{value: value, prev: prevCounts, masks: option.masks}
+				}
+			}
+		}
+	}
+
+	var finalCounts gemColorCounts
+	found := false
+	bestValue := 0.0
+	for finalState, s := range stages[len(items)] {
+		if condition.IsMet(finalState[0], finalState[1], finalState[2]) && (!found || s.value > bestValue) {
+			finalCounts, bestValue, found = finalState, s.value, true
+		}
+	}
+	if !found {
+		return nil, 0, false
+	}
+
+	assignment := make([][]gemColorMask, len(items))
+	for i := len(items); i > 0; i-- {
+		s := stages[i][finalCounts]
+		assignment[i-1] = s.masks
+		finalCounts = s.prev
+	}
+	return assignment, bestValue, true
+}
+
+// Replaces chosen gems with unique or profession gems, where the replacement
+// counts as at least the same colors and is worth more. Returns the value gained.
+func (filler *gemFiller) placeRestrictedGems(items []*gemFillItem, assignment [][]gemColorMask, chosen [][]Gem, usedGems map[int32]int) float64 {
+	numJewelcraftingGems := 0
+	for gemID, count := range usedGems {
+		if GemsByID[gemID].RequiredProfession == proto.Profession_Jewelcrafting {
+			numJewelcraftingGems += count
+		}
+	}
+
+	gained := 0.0
+	for _, gem := range filler.restrictedGems {
+		mask := gemColorToMask(gem.Color)
+		for {
+			if gem.Unique && usedGems[gem.ID] > 0 {
+				break
+			}
+			if gem.RequiredProfession == proto.Profession_Jewelcrafting && numJewelcraftingGems >= MaxJewelcraftingGems {
+				break
+			}
+
+			bestGain := 0.0
+			bestItem, bestSocket := -1, -1
+			for i := range items {
+				for j, socketMask := range assignment[i] {
+					if chosen[i][j].Unique || chosen[i][j].RequiredProfession != proto.Profession_ProfessionUnknown {
+						continue
+					}
+					if mask&socketMask != socketMask {
+						continue
+					}
+					if gain := filler.value(gem) - filler.value(chosen[i][j]); gain > bestGain {
+						bestGain, bestItem, bestSocket = gain, i, j
+					}
+				}
+			}
+			if bestItem == -1 {
+				break
+			}
+
+			chosen[bestItem][bestSocket] = gem
+			usedGems[gem.ID]++
+			if gem.RequiredProfession == proto.Profession_Jewelcrafting {
+				numJewelcraftingGems++
+			}
+			gained += bestGain
+		}
+	}
+	return gained
+}
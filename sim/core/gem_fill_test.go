@@ -0,0 +1,91 @@
+package core
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestFillGems(t *testing.T) {
+	const (
+		redGem         = 910001
+		yellowGem      = 910002
+		blueGem        = 910003
+		purpleGem      = 910004
+		dragonsEye     = 910005
+		relentlessMeta = 910006 // Requires 1 red, 1 yellow and 1 blue gem.
+	)
+	// Fixture IDs, so the real DB's gems can't shadow these when built with_db.
+	MetaGemConditions[relentlessMeta] = MetaGemCondition{MinRed: 1, MinYellow: 1, MinBlue: 1}
+	t.Cleanup(func() {
+		delete(MetaGemConditions, relentlessMeta)
+		delete(ItemsByID, 910101)
+		delete(ItemsByID, 910102)
+		for _, id := range []int32{redGem, yellowGem, blueGem, purpleGem, dragonsEye, relentlessMeta} {
+			delete(GemsByID, id)
+		}
+	})
+	addToDatabase(&proto.SimDatabase{
+		Items: []*proto.SimItem{
+			{Id: 910101, Type: proto.ItemType_ItemTypeHead, GemSockets: []proto.GemColor{proto.GemColor_GemColorMeta, proto.GemColor_GemColorRed},
+				SocketBonus: stats.Stats{stats.Strength: 4}.ToFloatArray()},
+			{Id: 910102, Type: proto.ItemType_ItemTypeChest, GemSockets: []proto.GemColor{proto.GemColor_GemColorYellow, proto.GemColor_GemColorBlue},
+				SocketBonus: stats.Stats{stats.Strength: 8}.ToFloatArray()},
+		},
+		Gems: []*proto.SimGem{
+			{Id: redGem, Color: proto.GemColor_GemColorRed, Stats: stats.Stats{stats.Strength: 20}.ToFloatArray()},
+			{Id: yellowGem, Color: proto.GemColor_GemColorYellow, Stats: stats.Stats{stats.MeleeHit: 20}.ToFloatArray()},
+			{Id: blueGem, Color: proto.GemColor_GemColorBlue, Stats: stats.Stats{stats.Stamina: 30}.ToFloatArray()},
+			{Id: purpleGem, Color: proto.GemColor_GemColorPurple, Stats: stats.Stats{stats.Strength: 10, stats.Stamina: 15}.ToFloatArray()},
+			{Id: dragonsEye, Color: proto.GemColor_GemColorRed, Stats: stats.Stats{stats.Strength: 34}.ToFloatArray(), RequiredProfession: proto.Profession_Jewelcrafting},
+			{Id: relentlessMeta, Color: proto.GemColor_GemColorMeta, Stats: stats.Stats{stats.Agility: 30}.ToFloatArray()},
+		},
+	})
+
+	request := &proto.FillGemsRequest{
+		Equipment: &proto.EquipmentSpec{Items: []*proto.ItemSpec{
+			proto.ItemSlot_ItemSlotHead:  {Id: 910101},
+			proto.ItemSlot_ItemSlotNeck:  {},
+			proto.ItemSlot_ItemSlotChest: {Id: 910102},
+		}},
+		StatWeights: &proto.UnitStats{
+			Stats: stats.Stats{stats.Strength: 1, stats.Agility: 1, stats.MeleeHit: 0.5, stats.Stamina: 0.1}.ToFloatArray(),
+		},
+		GemIds: []int32{redGem, yellowGem, blueGem, purpleGem, dragonsEye, relentlessMeta},
+	}
+
+	// Red gems everywhere would be worth more without the meta gem, but the
+	// chest needs a yellow and a blue gem to activate it.
+	result := FillGems(request)
+	if result.ErrorResult != "" {
+		t.Fatalf("FillGems() returned error: %s", result.ErrorResult)
+	}
+	if !result.MetaGemActive {
+		t.Errorf("Expected the meta gem to be active")
+	}
+	if head := result.Equipment.Items[proto.ItemSlot_ItemSlotHead].Gems; !slices.Equal(head, []int32{relentlessMeta, redGem}) {
+		t.Errorf("Unexpected head gems %v", head)
+	}
+	if chest := result.Equipment.Items[proto.ItemSlot_ItemSlotChest].Gems; !slices.Equal(chest, []int32{yellowGem, purpleGem}) {
+		t.Errorf("Unexpected chest gems %v", chest)
+	}
+	// 30 meta + 20 red + 4 bonus + 10 yellow + 11.5 purple + 8 bonus.
+	if result.DpsGain != 83.5 {
+		t.Errorf("Expected a gain of 83.5, got %f", result.DpsGain)
+	}
+	if len(result.Choices) != 4 {
+		t.Errorf("Expected 4 choices, got %v", result.Choices)
+	}
+
+	// Jewelcrafters get the Dragon's Eye in the red socket.
+	request.Professions = []proto.Profession{proto.Profession_Jewelcrafting}
+	result = FillGems(request)
+	if head := result.Equipment.Items[proto.ItemSlot_ItemSlotHead].Gems; !slices.Equal(head, []int32{relentlessMeta, dragonsEye}) {
+		t.Errorf("Unexpected head gems %v", head)
+	}
+	if result.DpsGain != 97.5 {
+		t.Errorf("Expected a gain of 97.5, got %f", result.DpsGain)
+	}
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+// Haste applies separately to melee swings, ranged shots and spell casts. Haste
+// rating is shared by melee and ranged attacks (stats.MeleeHaste), while spells
+// use their own (stats.SpellHaste). Multiplicative effects like Bloodlust or
+// Berserking can apply to any combination of tracks.
+type HasteTrack byte
+
+const (
+	HasteTrackMelee HasteTrack = 1 << iota
+	HasteTrackRanged
+	HasteTrackSpell
+
+	HasteTrackPhysical = HasteTrackMelee | HasteTrackRanged
+	HasteTrackAll      = HasteTrackPhysical | HasteTrackSpell
+)
+
+// Returns whether there is any overlap between the given tracks.
+func (ht HasteTrack) Matches(other HasteTrack) bool {
+	return (ht & other) != 0
+}
+
+// Multiplies the haste of the given tracks, e.g. 1.2 for 20% haste. Swings in
+// progress are updated immediately.
+func (unit *Unit) MultiplyHaste(sim *Simulation, tracks HasteTrack, amount float64) {
+	if tracks.Matches(HasteTrackSpell) {
+		unit.MultiplyCastSpeed(amount)
+	}
+	switch tracks & HasteTrackPhysical {
+	case HasteTrackPhysical:
+		unit.MultiplyAttackSpeed(sim, amount)
+	case HasteTrackMelee:
+		unit.MultiplyMeleeSpeed(sim, amount)
+	case HasteTrackRanged:
+		unit.MultiplyRangedSpeed(sim, amount)
+	}
+}
+
+// Adds haste rating to the given tracks, for effects which only grant melee or
+// spell haste rating. Melee and ranged attacks share their rating, so either
+// one adds it to both.
+func (unit *Unit) AddHasteRatingDynamic(sim *Simulation, tracks HasteTrack, rating float64) {
+	if tracks.Matches(HasteTrackPhysical) {
+		unit.AddStatDynamic(sim, stats.MeleeHaste, rating)
+	}
+	if tracks.Matches(HasteTrackSpell) {
+		unit.AddStatDynamic(sim, stats.SpellHaste, rating)
+	}
+}
+
+// Returns the unit's current haste on a single track, including rating and
+// all multiplicative effects, e.g. 0.25 for 25% haste.
+func (unit *Unit) HastePercent(track HasteTrack) float64 {
+	switch track {
+	case HasteTrackMelee:
+		return unit.SwingSpeed() - 1
+	case HasteTrackRanged:
+		return unit.RangedSwingSpeed() - 1
+	case HasteTrackSpell:
+		return unit.PseudoStats.CastSpeedMultiplier*(1+unit.stats[stats.SpellHaste]/(HasteRatingPerHastePercent*100)) - 1
+	default:
+		panic("HastePercent needs a single track")
+	}
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestHasteTracks(t *testing.T) {
+	sim := NewSim(tankSimRequest(&proto.SimOptions{}))
+	sim.reset()
+	unit := &sim.Raid.Parties[0].Players[0].(*FakeAgent).Unit
+
+	check := func(melee, ranged, spell float64) {
+		t.Helper()
+		for _, c := range []struct {
+			track    HasteTrack
+			expected float64
+		}{
+			{HasteTrackMelee, melee},
+			{HasteTrackRanged, ranged},
+			{HasteTrackSpell, spell},
+		} {
+			if actual := unit.HastePercent(c.track); !WithinToleranceFloat64(c.expected, actual, 1e-9) {
+				t.Fatalf("Expected %f haste on track %d, got %f", c.expected, c.track, actual)
+			}
+		}
+	}
+
+	check(0, 0, 0)
+
+	// Effects which only grant melee haste leave spells alone.
+	unit.MultiplyHaste(sim, HasteTrackMelee, 1.2)
+	check(0.2, 0, 0)
+
+	unit.MultiplyHaste(sim, HasteTrackAll, 1.3)
+	check(0.56, 0.3, 0.3)
+	if !WithinToleranceFloat64(1/1.3, unit.CastSpeed, 1e-9) {
+		t.Fatalf("Expected cast speed to follow spell haste, got %f", unit.CastSpeed)
+	}
+
+	// Spell haste rating doesn't apply to attacks.
+	unit.AddHasteRatingDynamic(sim, HasteTrackSpell, HasteRatingPerHastePercent*10)
+	check(0.56, 0.3, 1.3*1.1-1)
+
+	unit.MultiplyHaste(sim, HasteTrackAll, 1/1.3)
+	unit.MultiplyHaste(sim, HasteTrackMelee, 1/1.2)
+	check(0, 0, 0.1)
+}
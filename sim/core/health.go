@@ -1,6 +1,7 @@
 package core
 
 import (
+	"slices"
 	"time"
 
 	"github.com/wowsims/wotlk/sim/core/proto"
@@ -55,7 +56,7 @@ func (hb *healthBar) GainHealth(sim *Simulation, amount float64, metrics *Resour
 	metrics.AddEvent(amount, newHealth-oldHealth)
 
 	if sim.Log != nil {
-		hb.unit.Log(sim, "Gained %0.3f health from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, oldHealth, newHealth)
+		hb.unit.logResource(sim, LogEventResourceGain, proto.ResourceType_ResourceTypeHealth, amount, metrics, oldHealth, newHealth)
 	}
 
 	hb.currentHealth = newHealth
@@ -73,15 +74,19 @@ func (hb *healthBar) RemoveHealth(sim *Simulation, amount float64) {
 
 	// TMI calculations need timestamps and Max HP information for each damage taken event
 	if hb.unit.Metrics.isTanking {
-		entry := tmiListItem{
-			Timestamp:      sim.CurrentTime,
-			WeightedDamage: amount / hb.MaxHealth(),
+		if sim.lowMemory {
+			hb.unit.Metrics.addTMIDamage(sim.CurrentTime, amount/hb.MaxHealth())
+		} else {
+			entry := tmiListItem{
+				Timestamp:      sim.CurrentTime,
+				WeightedDamage: amount / hb.MaxHealth(),
+			}
+			hb.unit.Metrics.tmiList = append(hb.unit.Metrics.tmiList, entry)
 		}
-		hb.unit.Metrics.tmiList = append(hb.unit.Metrics.tmiList, entry)
 	}
 
 	if sim.Log != nil {
-		hb.unit.Log(sim, "Spent %0.3f health from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, oldHealth, newHealth)
+		hb.unit.logResource(sim, LogEventResourceSpend, proto.ResourceType_ResourceTypeHealth, amount, metrics, oldHealth, newHealth)
 	}
 
 	hb.currentHealth = newHealth
@@ -91,9 +96,11 @@ var ChanceOfDeathAuraLabel = "Chance of Death"
 
 func (character *Character) trackChanceOfDeath(healingModel *proto.HealingModel) {
 	character.Unit.Metrics.isTanking = false
-	for _, target := range character.Env.Encounter.TargetUnits {
-		if target.CurrentTarget == &character.Unit {
+	var tankedTargets []*Unit
+	for _, target := range character.Env.Encounter.Targets {
+		if target.CurrentTarget == &character.Unit || (target.tankSwap != nil && slices.Contains(target.tankSwap.tanks, &character.Unit)) {
 			character.Unit.Metrics.isTanking = true
+			tankedTargets = append(tankedTargets, &target.Unit)
 		}
 	}
 	if !character.Unit.Metrics.isTanking {
@@ -105,44 +112,134 @@ func (character *Character) trackChanceOfDeath(healingModel *proto.HealingModel)
 	}
 
 	character.Unit.Metrics.tmiBin = healingModel.BurstWindow
+	character.Unit.Metrics.tank = newTankMetrics(healingModel.BurstWindow)
+
+	onDamageTaken := func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
+		if result.Damage > 0 && aura.Unit.IsEnabled() {
+			aura.Unit.RemoveHealth(sim, result.Damage)
+			aura.Unit.Metrics.tank.addDamageTaken(sim, result.Damage)
+			if character.externalHealer != nil {
+				character.externalHealer.onDamageTaken(sim)
+			}
+
+			if aura.Unit.CurrentHealth() <= 0 {
+				character.die(sim)
+			}
+		}
+	}
 
 	character.RegisterAura(Aura{
 		Label:    ChanceOfDeathAuraLabel,
 		Duration: NeverExpires,
 		OnReset: func(aura *Aura, sim *Simulation) {
 			aura.Activate(sim)
+			aura.Unit.Metrics.tank.effectiveHealth = aura.Unit.worstCaseEffectiveHealth(tankedTargets)
 		},
-		OnSpellHitTaken: func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
-			if result.Damage > 0 {
-				aura.Unit.RemoveHealth(sim, result.Damage)
-
-				if aura.Unit.CurrentHealth() <= 0 && !aura.Unit.Metrics.Died {
-					aura.Unit.Metrics.Died = true
-					if sim.Log != nil {
-						character.Log(sim, "Dead")
-					}
-				}
-			}
-		},
-		OnPeriodicDamageTaken: func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
-			if result.Damage > 0 {
-				aura.Unit.RemoveHealth(sim, result.Damage)
-
-				if aura.Unit.CurrentHealth() <= 0 && !aura.Unit.Metrics.Died {
-					aura.Unit.Metrics.Died = true
-					if sim.Log != nil {
-						character.Log(sim, "Dead")
-					}
-				}
-			}
-		},
+		OnSpellHitTaken:       onDamageTaken,
+		OnPeriodicDamageTaken: onDamageTaken,
 	})
 
-	if healingModel.Hps != 0 {
+	// Without HPS, a model with a cadence gets its HPS from a presim, which
+	// applies it then. Otherwise it only heals reactively.
+	if !usesPresimHps(healingModel) && (healingModel.Hps != 0 || healingModel.ReactiveHealAmount > 0) {
 		character.applyHealingModel(healingModel)
 	}
 }
 
+// Whether the model's HPS is set from a presim, because it has a cadence but
+// no HPS.
+func usesPresimHps(healingModel *proto.HealingModel) bool {
+	return healingModel.Hps == 0 && healingModel.CadenceSeconds != 0
+}
+
+// Runtime state for the external healers modeled by a HealingModel.
+type externalHealer struct {
+	character *Character
+	model     *proto.HealingModel
+	metrics   *ResourceMetrics
+
+	latency           time.Duration
+	reactiveCastTime  time.Duration
+	reactiveThreshold float64
+
+	// Token bucket for the HPS cap: refills at HpsCap per second, holding at
+	// most 1s worth of healing, so idle time can't be banked into a huge spike.
+	healBudget          float64
+	healBudgetUpdatedAt time.Duration
+
+	// Reactive heal currently being cast, if any.
+	reactiveHeal *PendingAction
+
+	willOfTheNecropolisAura *Aura
+}
+
+// Lands a heal on the character, respecting the HPS cap.
+func (eh *externalHealer) heal(sim *Simulation, amount float64) {
+	if eh.model.HpsCap > 0 {
+		eh.refillHealBudget(sim)
+		amount = min(amount, eh.healBudget)
+	}
+	if amount <= 0 || !eh.character.IsEnabled() {
+		return
+	}
+	if eh.model.HpsCap > 0 {
+		eh.healBudget -= amount
+	}
+
+	eh.character.GainHealth(sim, amount*eh.character.PseudoStats.HealingTakenMultiplier, eh.metrics)
+
+	// Might use this again in the future to track "absorb" metrics but currently disabled
+	//if ardentDefenderAura != nil && character.CurrentHealthPercent() >= 0.35 {
+	//	ardentDefenderAura.Deactivate(sim)
+	//}
+
+	if eh.willOfTheNecropolisAura != nil && eh.character.CurrentHealthPercent() > 0.35 {
+		eh.willOfTheNecropolisAura.Deactivate(sim)
+	}
+}
+
+func (eh *externalHealer) refillHealBudget(sim *Simulation) {
+	elapsed := sim.CurrentTime - eh.healBudgetUpdatedAt
+	eh.healBudget = min(eh.model.HpsCap, eh.healBudget+eh.model.HpsCap*elapsed.Seconds())
+	eh.healBudgetUpdatedAt = sim.CurrentTime
+}
+
+// Schedules a heal to land after the healer latency.
+func (eh *externalHealer) healAfterLatency(sim *Simulation, amount float64) {
+	if eh.latency == 0 {
+		eh.heal(sim, amount)
+		return
+	}
+
+	StartDelayedAction(sim, DelayedActionOptions{
+		DoAt: sim.CurrentTime + eh.latency,
+		OnAction: func(sim *Simulation) {
+			eh.heal(sim, amount)
+		},
+	})
+}
+
+// Called whenever the character takes damage, to start a reactive heal if needed.
+func (eh *externalHealer) onDamageTaken(sim *Simulation) {
+	if eh.reactiveThreshold == 0 || eh.reactiveHeal != nil {
+		return
+	}
+	if eh.character.CurrentHealthPercent() >= eh.reactiveThreshold {
+		return
+	}
+
+	eh.reactiveHeal = StartDelayedAction(sim, DelayedActionOptions{
+		DoAt: sim.CurrentTime + eh.latency + eh.reactiveCastTime,
+		OnAction: func(sim *Simulation) {
+			eh.reactiveHeal = nil
+			eh.heal(sim, eh.model.ReactiveHealAmount)
+		},
+		CleanUp: func(sim *Simulation) {
+			eh.reactiveHeal = nil
+		},
+	})
+}
+
 func (character *Character) applyHealingModel(healingModel *proto.HealingModel) {
 	// Store variance parameters for healing cadence. Note that low rolls on
 	// cadence are special cased here so that the model is still well-behaved
@@ -154,12 +251,30 @@ func (character *Character) applyHealingModel(healingModel *proto.HealingModel)
 	minCadence := max(0.0, medianCadence-healingModel.CadenceVariation)
 	cadenceVariationLow := medianCadence - minCadence
 
-	healthMetrics := character.NewHealthMetrics(ActionID{OtherID: proto.OtherAction_OtherActionHealingModel})
+	eh := &externalHealer{
+		character:        character,
+		model:            healingModel,
+		metrics:          character.NewHealthMetrics(ActionID{OtherID: proto.OtherAction_OtherActionHealingModel}),
+		latency:          max(0, DurationFromSeconds(healingModel.Latency)),
+		reactiveCastTime: max(0, DurationFromSeconds(healingModel.ReactiveCastTime)),
+	}
+	if healingModel.ReactiveHealAmount > 0 {
+		eh.reactiveThreshold = healingModel.ReactiveThreshold
+	}
+	character.externalHealer = eh
 
 	character.RegisterResetEffect(func(sim *Simulation) {
 		// Hack since we don't have OnHealingReceived aura handlers yet.
 		//ardentDefenderAura := character.GetAura("Ardent Defender")
-		willOfTheNecropolisAura := character.GetAura("Will of The Necropolis")
+		eh.willOfTheNecropolisAura = character.GetAura("Will of The Necropolis")
+		eh.healBudget = healingModel.HpsCap
+		eh.healBudgetUpdatedAt = 0
+		eh.reactiveHeal = nil
+
+		// Reactive heals don't need the periodic ticks.
+		if healingModel.Hps == 0 {
+			return
+		}
 
 		// Initialize randomized cadence model
 		timeToNextHeal := DurationFromSeconds(0.0)
@@ -173,16 +288,7 @@ func (character *Character) applyHealingModel(healingModel *proto.HealingModel)
 			healPerTick = healingModel.Hps * (float64(timeToNextHeal) / float64(time.Second))
 
 			// Execute the heal
-			character.GainHealth(sim, healPerTick*character.PseudoStats.HealingTakenMultiplier, healthMetrics)
-
-			// Might use this again in the future to track "absorb" metrics but currently disabled
-			//if ardentDefenderAura != nil && character.CurrentHealthPercent() >= 0.35 {
-			//	ardentDefenderAura.Deactivate(sim)
-			//}
-
-			if willOfTheNecropolisAura != nil && character.CurrentHealthPercent() > 0.35 {
-				willOfTheNecropolisAura.Deactivate(sim)
-			}
+			eh.healAfterLatency(sim, healPerTick)
 
 			// Random roll for time to next heal. In the case where CadenceVariation exceeds CadenceSeconds, then
 			// CadenceSeconds is treated as the median, with two separate uniform distributions to the left and right
@@ -207,7 +313,7 @@ func (character *Character) applyHealingModel(healingModel *proto.HealingModel)
 
 func (character *Character) GetPresimOptions(playerConfig *proto.Player) *PresimOptions {
 	healingModel := playerConfig.HealingModel
-	if healingModel == nil || healingModel.Hps != 0 || healingModel.CadenceSeconds == 0 {
+	if healingModel == nil || !usesPresimHps(healingModel) {
 		// If Hps is not 0, then we don't need to run the presim.
 		// Tank sims should always have nonzero Cadence set, even if disabled
 		return nil
@@ -218,8 +324,13 @@ func (character *Character) GetPresimOptions(playerConfig *proto.Player) *Presim
 		},
 		OnPresimResult: func(presimResult *proto.UnitMetrics, iterations int32, duration time.Duration) bool {
 			character.applyHealingModel(&proto.HealingModel{
-				Hps:            presimResult.Dtps.Avg * 1.50,
-				CadenceSeconds: healingModel.CadenceSeconds,
+				Hps:                presimResult.Dtps.Avg * 1.50,
+				CadenceSeconds:     healingModel.CadenceSeconds,
+				Latency:            healingModel.Latency,
+				ReactiveThreshold:  healingModel.ReactiveThreshold,
+				ReactiveHealAmount: healingModel.ReactiveHealAmount,
+				ReactiveCastTime:   healingModel.ReactiveCastTime,
+				HpsCap:             healingModel.HpsCap,
 			})
 			return true
 		},
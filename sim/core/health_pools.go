@@ -0,0 +1,140 @@
+package core
+
+import (
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+// Health of a single target, for health fights where the targets don't share
+// a health pool, see proto.Encounter.separate_health_pools.
+type targetHealthPool struct {
+	target *Target
+	health float64
+
+	alive       bool
+	damageTaken float64
+}
+
+func (encounter *Encounter) setupHealthPools(numTargets int) {
+	encounter.healthPools = make([]*targetHealthPool, len(encounter.Targets))
+	for _, target := range encounter.Targets[:numTargets] {
+		if health := target.stats[stats.Health]; health > 0 {
+			encounter.healthPools[target.Index] = &targetHealthPool{
+				target: target,
+				health: health,
+			}
+		}
+	}
+	if encounter.activeTargetUnits == nil {
+		encounter.activeTargetUnits = make([]*Unit, 0, len(encounter.TargetUnits))
+	}
+}
+
+func (encounter *Encounter) resetHealthPools() {
+	encounter.numHealthPoolsAlive = 0
+	for _, pool := range encounter.healthPools {
+		if pool != nil {
+			pool.alive = true
+			pool.damageTaken = 0
+			encounter.numHealthPoolsAlive++
+		}
+	}
+}
+
+// Counts damage against the target towards the encounter's health. With
+// separate health pools, only damage up to the target's remaining health
+// counts, and the target dies once it has none left.
+func (encounter *Encounter) damageTarget(sim *Simulation, target *Unit, damage float64) {
+	if encounter.healthPools == nil {
+		encounter.DamageTaken += damage
+		return
+	}
+
+	pool := encounter.healthPools[target.Index]
+	if pool == nil || !pool.alive {
+		return
+	}
+	damage = min(damage, pool.health-pool.damageTaken)
+	pool.damageTaken += damage
+	encounter.DamageTaken += damage
+	if pool.damageTaken >= pool.health {
+		pool.kill(sim)
+	}
+}
+
+// Spreads damage evenly over the targets which are still alive, e.g. for the
+// unsimulated raid DPS.
+func (encounter *Encounter) damageHealthPools(sim *Simulation, damage float64) {
+	if encounter.numHealthPoolsAlive == 0 {
+		return
+	}
+	damage /= float64(encounter.numHealthPoolsAlive)
+	for _, pool := range encounter.healthPools {
+		if pool != nil && pool.alive {
+			encounter.damageTarget(sim, &pool.target.Unit, damage)
+		}
+	}
+}
+
+func (pool *targetHealthPool) kill(sim *Simulation) {
+	pool.alive = false
+	if sim.Log != nil {
+		pool.target.Log(sim, "Killed")
+	}
+
+	encounter := &sim.Encounter
+	encounter.numHealthPoolsAlive--
+	if encounter.numHealthPoolsAlive == 0 {
+		// Damage past each target's health is wasted, so DamageTaken never
+		// exceeds EndFightAtHealth on its own.
+		sim.endOfCombatDamage = 0
+		return
+	}
+
+	// Removing the target expires its auras, so wait until the current spell
+	// has finished with them.
+	StartDelayedAction(sim, DelayedActionOptions{
+		DoAt: sim.CurrentTime,
+		OnAction: func(sim *Simulation) {
+			encounter.removeTarget(sim, pool.target)
+		},
+	})
+}
+
+// Compares the DPS taken by a target with the DPS it needs to take, see
+// proto.Target.required_dps.
+type dpsRequirementMetrics struct {
+	requiredDps float64
+
+	// Damage taken in the current iteration.
+	damage float64
+
+	// Totals over all iterations.
+	dpsSum float64
+	numMet int32
+}
+
+func (drm *dpsRequirementMetrics) reset() {
+	drm.damage = 0
+}
+
+func (drm *dpsRequirementMetrics) doneIteration(sim *Simulation) {
+	dps := drm.damage / sim.Duration.Seconds()
+	drm.dpsSum += dps
+	if dps >= drm.requiredDps {
+		drm.numMet++
+	}
+}
+
+func (drm *dpsRequirementMetrics) merge(other *dpsRequirementMetrics) {
+	drm.dpsSum += other.dpsSum
+	drm.numMet += other.numMet
+}
+
+func (drm *dpsRequirementMetrics) ToProto(n float64) *proto.DpsRequirementMetrics {
+	return &proto.DpsRequirementMetrics{
+		RequiredDps: drm.requiredDps,
+		Dps:         drm.dpsSum / n,
+		SuccessRate: float64(drm.numMet) / n,
+	}
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func healthPoolsTestRequest(options *proto.SimOptions) *proto.RaidSimRequest {
+	request := tankSimRequest(options)
+	request.Encounter.UseHealth = true
+	request.Encounter.SeparateHealthPools = true
+	request.Encounter.Targets[0].Stats = stats.Stats{stats.Health: 1000}.ToFloatArray()
+	request.Encounter.Targets = append(request.Encounter.Targets, &proto.Target{
+		Level: 83,
+		Stats: stats.Stats{stats.Health: 500}.ToFloatArray(),
+	})
+	return request
+}
+
+func TestSeparateHealthPools(t *testing.T) {
+	sim := NewSim(healthPoolsTestRequest(&proto.SimOptions{}))
+	sim.reset()
+	encounter := &sim.Encounter
+	boss, council := encounter.TargetUnits[0], encounter.TargetUnits[1]
+
+	// Overkill is wasted, and killed targets leave the fight.
+	encounter.damageTarget(sim, council, 600)
+	runSimUntil(sim, sim.CurrentTime)
+	if encounter.DamageTaken != 500 {
+		t.Fatalf("Expected only the target's health to count, got %f", encounter.DamageTaken)
+	}
+	if len(encounter.TargetUnits) != 1 || encounter.TargetUnits[0] != boss || council.IsEnabled() {
+		t.Fatalf("Expected the killed target to leave the fight, got %d targets", len(encounter.TargetUnits))
+	}
+	encounter.damageTarget(sim, council, 600)
+	if encounter.DamageTaken != 500 {
+		t.Fatalf("Expected no damage to count against a dead target, got %f", encounter.DamageTaken)
+	}
+
+	encounter.damageTarget(sim, boss, 400)
+	if sim.Step() {
+		t.Fatalf("Expected the fight to continue while a target is alive")
+	}
+	encounter.damageTarget(sim, boss, 700)
+	if encounter.DamageTaken != encounter.EndFightAtHealth || !sim.Step() {
+		t.Fatalf("Expected the fight to end once all targets are dead")
+	}
+
+	// Every target comes back for the next iteration.
+	sim.Cleanup()
+	sim.reset()
+	if len(encounter.TargetUnits) != 2 || encounter.numHealthPoolsAlive != 2 || !council.IsEnabled() {
+		t.Fatalf("Expected both targets to be alive after a reset, got %d targets", len(encounter.TargetUnits))
+	}
+}
+
+func TestRequiredDps(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{Iterations: 20, RandomSeed: 101})
+	request.Raid.Buffs = &proto.RaidBuffs{Thorns: proto.TristateEffect_TristateEffectRegular}
+	request.Encounter.Targets[0].RequiredDps = 1
+	request.Encounter.Targets = append(request.Encounter.Targets, &proto.Target{Level: 83, RequiredDps: 1})
+
+	result := RunRaidSim(request)
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", result.ErrorResult)
+	}
+
+	// Only the first target attacks the tank, and takes damage from Thorns.
+	attacked := result.EncounterMetrics.Targets[0].DpsRequirement
+	if attacked == nil || attacked.RequiredDps != 1 || attacked.Dps <= 1 || attacked.SuccessRate != 1 {
+		t.Fatalf("Expected the attacking target to meet its requirement, got %v", attacked)
+	}
+	idle := result.EncounterMetrics.Targets[1].DpsRequirement
+	if idle == nil || idle.Dps != 0 || idle.SuccessRate != 0 {
+		t.Fatalf("Expected the idle target to miss its requirement, got %v", idle)
+	}
+
+	if metrics := RunRaidSim(tankSimRequest(&proto.SimOptions{Iterations: 1})); metrics.EncounterMetrics.Targets[0].DpsRequirement != nil {
+		t.Fatalf("Expected no requirement metrics for targets without a required DPS")
+	}
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestHealthReturn(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{})
+	request.Raid.Parties[0].Players[0].BonusStats = &proto.UnitStats{Stats: make([]float64, stats.Len)}
+	request.Raid.Parties[0].Players[0].BonusStats.Stats[stats.Health] = 10000
+	sim := NewSim(request)
+	sim.reset()
+
+	caster := sim.Raid.AllPlayerUnits[0]
+	target := sim.Encounter.TargetUnits[0]
+	spell := &Spell{Unit: caster, ActionID: ActionID{SpellID: 42}}
+	caster.RemoveHealth(sim, 1000)
+	startHealth := caster.CurrentHealth()
+
+	// Drains only heal when they land.
+	missed := &SpellResult{Target: target, Outcome: OutcomeMiss, HealthReturnMultiplier: 0.5, HealthReturn: 100}
+	missed.applyHealthReturn(sim, spell)
+	if caster.CurrentHealth() != startHealth {
+		t.Fatalf("Expected no health from a miss, got %f", caster.CurrentHealth()-startHealth)
+	}
+
+	caster.PseudoStats.HealingTakenMultiplier = 2
+	result := &SpellResult{Target: target, Outcome: OutcomeHit, Damage: 200, HealthReturnMultiplier: 0.5, HealthReturn: 100}
+	result.applyHealthReturn(sim, spell)
+	if gain := caster.CurrentHealth() - startHealth; gain != 400 {
+		t.Fatalf("Expected 400 health from the drain, got %f", gain)
+	}
+
+	metrics := spell.HealthMetrics(caster)
+	if metrics.ActionID != spell.ActionID || metrics.Events != 1 || metrics.ActualGain != 400 {
+		t.Fatalf("Expected 1 health event for 400 attributed to the spell, got %v", metrics)
+	}
+}
+
+func TestHealingModelHpsCapAfterIdle(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{})
+	player := request.Raid.Parties[0].Players[0]
+	player.HealingModel.HpsCap = 1000
+	player.BonusStats = &proto.UnitStats{Stats: make([]float64, stats.Len)}
+	player.BonusStats.Stats[stats.Health] = 100000
+	sim := NewSim(request)
+	sim.reset()
+
+	character := sim.Raid.Parties[0].Players[0].GetCharacter()
+	healer := character.externalHealer
+	character.RemoveHealth(sim, 50000)
+
+	healFor := func(at time.Duration, amount float64) float64 {
+		sim.CurrentTime = at
+		startHealth := character.CurrentHealth()
+		healer.heal(sim, amount)
+		return character.CurrentHealth() - startHealth
+	}
+
+	// A long idle period only banks 1s worth of healing.
+	if gain := healFor(20*time.Second, 30000); gain != 1000 {
+		t.Fatalf("Expected a spike after idle time to be capped at 1000, got %f", gain)
+	}
+	if gain := healFor(20*time.Second, 30000); gain != 0 {
+		t.Fatalf("Expected no healing with an empty budget, got %f", gain)
+	}
+	if gain := healFor(20500*time.Millisecond, 30000); gain != 500 {
+		t.Fatalf("Expected the budget to refill at the HPS cap, got %f", gain)
+	}
+}
+
+func TestHealingModelReactiveOnly(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{})
+	player := request.Raid.Parties[0].Players[0]
+	player.HealingModel = &proto.HealingModel{ReactiveThreshold: 0.5, ReactiveHealAmount: 20000, BurstWindow: 6}
+	player.BonusStats = &proto.UnitStats{Stats: make([]float64, stats.Len)}
+	player.BonusStats.Stats[stats.Health] = 100000
+	sim := NewSim(request)
+
+	character := sim.Raid.Parties[0].Players[0].GetCharacter()
+	healer := character.externalHealer
+	if healer == nil {
+		t.Fatalf("Expected a reactive only healing model to be applied")
+	}
+	if character.GetPresimOptions(player) != nil {
+		t.Fatalf("Expected no presim for a reactive only healing model")
+	}
+
+	sim.reset()
+	sim.PrePull()
+	character.RemoveHealth(sim, 60000)
+	healer.onDamageTaken(sim)
+	runSimUntil(sim, time.Second*10)
+	// Only the reactive heal, since there's no HPS to tick.
+	if healer.metrics.Events != 1 || healer.metrics.ActualGain != 20000 {
+		t.Fatalf("Expected a single reactive heal for 20000, got %d heals for %f", healer.metrics.Events, healer.metrics.ActualGain)
+	}
+}
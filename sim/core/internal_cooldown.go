@@ -0,0 +1,134 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// An internal cooldown (ICD) which limits how often proc effects can trigger.
+// Most effects have their own, but some share one, e.g. so that only one of
+// them can be active at a time. Events which could have triggered an effect
+// during the cooldown are tracked for each effect, see
+// InternalCooldownEffect.
+type InternalCooldown struct {
+	Cooldown
+
+	// Only set for cooldowns which are shared by several effects.
+	SharedID ActionID
+
+	unit *Unit
+}
+
+func (unit *Unit) NewInternalCooldown(duration time.Duration) *InternalCooldown {
+	return &InternalCooldown{
+		Cooldown: Cooldown{
+			Timer:    unit.NewTimer(),
+			Duration: duration,
+		},
+		unit: unit,
+	}
+}
+
+// Like NewInternalCooldown, for cooldowns which several effects share. The
+// ID identifies the cooldown in metrics.
+func (unit *Unit) NewSharedInternalCooldown(sharedID ActionID, duration time.Duration) *InternalCooldown {
+	icd := unit.NewInternalCooldown(duration)
+	icd.SharedID = sharedID
+	return icd
+}
+
+// A single effect limited by an internal cooldown.
+type InternalCooldownEffect struct {
+	icd     *InternalCooldown
+	metrics *internalCooldownMetrics
+}
+
+// Returns the handle through which the effect with the given ID checks and
+// uses this cooldown.
+func (icd *InternalCooldown) ForEffect(actionID ActionID) *InternalCooldownEffect {
+	return &InternalCooldownEffect{
+		icd:     icd,
+		metrics: icd.unit.Metrics.newInternalCooldownMetrics(actionID, icd.SharedID),
+	}
+}
+
+// Returns whether the effect can proc now. If not, the event is recorded as
+// suppressed, along with the chance it had to proc. This is checked before
+// rolling for the proc, so suppressed events don't use any random rolls.
+func (effect *InternalCooldownEffect) IsReady(sim *Simulation, procChance float64) bool {
+	if effect.icd.IsReady(sim) {
+		return true
+	}
+	effect.metrics.suppressed++
+	effect.metrics.wastedProcs += procChance
+	if sim.Log != nil {
+		effect.icd.unit.Log(sim, "Proc of %s suppressed by internal cooldown, %s remaining.", effect.metrics.actionID, effect.icd.TimeToReady(sim))
+	}
+	return false
+}
+
+// Starts the cooldown, when the effect procs.
+func (effect *InternalCooldownEffect) Use(sim *Simulation) {
+	effect.metrics.procs++
+	effect.icd.Use(sim)
+}
+
+// How often a single effect procced and was suppressed by its internal
+// cooldown, see InternalCooldownEffect.
+type internalCooldownMetrics struct {
+	actionID ActionID
+	sharedID ActionID
+
+	// Values for the current iteration.
+	procs       int32
+	suppressed  int32
+	wastedProcs float64
+
+	// Aggregate values. These are updated after each iteration.
+	iterations     int32
+	procsSum       int32
+	suppressedSum  int32
+	wastedProcsSum float64
+}
+
+func (unitMetrics *UnitMetrics) newInternalCooldownMetrics(actionID ActionID, sharedID ActionID) *internalCooldownMetrics {
+	icdm := &internalCooldownMetrics{actionID: actionID, sharedID: sharedID}
+	unitMetrics.internalCooldowns = append(unitMetrics.internalCooldowns, icdm)
+	return icdm
+}
+
+func (icdm *internalCooldownMetrics) reset() {
+	icdm.procs = 0
+	icdm.suppressed = 0
+	icdm.wastedProcs = 0
+}
+
+func (icdm *internalCooldownMetrics) doneIteration() {
+	icdm.iterations++
+	icdm.procsSum += icdm.procs
+	icdm.suppressedSum += icdm.suppressed
+	icdm.wastedProcsSum += icdm.wastedProcs
+}
+
+// Adds the aggregate values of other, for sims which are run in shards.
+func (icdm *internalCooldownMetrics) merge(other *internalCooldownMetrics) {
+	icdm.iterations += other.iterations
+	icdm.procsSum += other.procsSum
+	icdm.suppressedSum += other.suppressedSum
+	icdm.wastedProcsSum += other.wastedProcsSum
+}
+
+func (icdm *internalCooldownMetrics) ToProto() *proto.InternalCooldownMetrics {
+	n := float64(icdm.iterations)
+	protoMetrics := &proto.InternalCooldownMetrics{
+		Id:             icdm.actionID.ToProto(),
+		ProcsAvg:       float64(icdm.procsSum) / n,
+		SuppressedAvg:  float64(icdm.suppressedSum) / n,
+		WastedProcsAvg: icdm.wastedProcsSum / n,
+	}
+	if !icdm.sharedID.IsEmptyAction() {
+		protoMetrics.SharedId = icdm.sharedID.ToProto()
+	}
+	return protoMetrics
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestSharedInternalCooldownMetrics(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{RandomSeed: 101})
+	request.Encounter.DurationVariation = 0
+	sim := NewSim(request)
+	character := sim.Raid.Parties[0].Players[0].GetCharacter()
+
+	icd := character.NewSharedInternalCooldown(ActionID{SpellID: 3}, time.Second*10)
+	for i, chance := range []float64{1, 0.5} {
+		MakeProcTriggerAura(&character.Unit, ProcTrigger{
+			Name:       "Test Trigger " + string(rune('A'+i)),
+			ActionID:   ActionID{SpellID: int32(i + 1)},
+			Callback:   CallbackOnSpellHitTaken,
+			ProcChance: chance,
+			SharedICD:  icd,
+			Handler:    func(sim *Simulation, _ *Spell, _ *SpellResult) {},
+		})
+	}
+
+	for i := int64(0); i < 20; i++ {
+		sim.reseedRands(i)
+		sim.runOnce()
+	}
+
+	metrics := character.GetMetricsProto().InternalCooldowns
+	if len(metrics) != 2 {
+		t.Fatalf("Expected metrics for both effects, got %d", len(metrics))
+	}
+	totalProcs := 0.0
+	for i, icdm := range metrics {
+		if ProtoToActionID(icdm.SharedId) != (ActionID{SpellID: 3}) {
+			t.Fatalf("Expected the shared cooldown's ID")
+		}
+		if icdm.SuppressedAvg == 0 {
+			t.Fatalf("Expected suppressed procs for effect %d", i)
+		}
+		totalProcs += icdm.ProcsAvg
+	}
+	// Attacks every 2s from the pull, with a proc on the first hit after each
+	// 10s cooldown.
+	if totalProcs < 3 || totalProcs > 4 {
+		t.Fatalf("Expected 3 to 4 procs per 30s fight between both effects, got %f", totalProcs)
+	}
+	// The guaranteed effect wastes a whole proc for every suppressed event.
+	if !WithinToleranceFloat64(metrics[0].SuppressedAvg, metrics[0].WastedProcsAvg, 1e-9) {
+		t.Fatalf("Expected %f wasted procs, got %f", metrics[0].SuppressedAvg, metrics[0].WastedProcsAvg)
+	}
+	if !WithinToleranceFloat64(metrics[1].SuppressedAvg*0.5, metrics[1].WastedProcsAvg, 1e-9) {
+		t.Fatalf("Expected %f wasted procs, got %f", metrics[1].SuppressedAvg*0.5, metrics[1].WastedProcsAvg)
+	}
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+// Returns the stat budget of an item at the given item level, before slot
+// modifiers. Slot modifiers and stat costs are the same for every item level,
+// so the ratio of two budgets is enough to scale an item.
+func itemStatBudget(ilvl int32, quality proto.ItemQuality) float64 {
+	switch quality {
+	case proto.ItemQuality_ItemQualityUncommon:
+		return (float64(ilvl) - 4) / 2
+	case proto.ItemQuality_ItemQualityRare:
+		return (float64(ilvl) - 1.84) / 1.6
+	case proto.ItemQuality_ItemQualityEpic, proto.ItemQuality_ItemQualityLegendary:
+		return (float64(ilvl) - 1.3) / 1.3
+	}
+	return 0
+}
+
+// Returns all items sharing a name and type with the given item, e.g. the
+// normal and heroic versions of a raid drop, including the item itself.
+func ItemVariants(item Item) []Item {
+	var variants []Item
+	for _, other := range ItemsByID {
+		if other.Name == item.Name && other.Type == item.Type {
+			variants = append(variants, other)
+		}
+	}
+	return variants
+}
+
+// Returns the variant of the item with the given item level, if one exists.
+func FindItemVariant(item Item, ilvl int32) (Item, bool) {
+	for _, variant := range ItemVariants(item) {
+		if variant.Ilvl == ilvl {
+			return variant, true
+		}
+	}
+	return Item{}, false
+}
+
+// Returns the item at the given item level. If the database has a variant at
+// that level, it is used as is. Otherwise the item's stats are scaled by the
+// stat budget, keeping the item's ID so its effects still apply. Effects
+// themselves are not scaled.
+func ScaleItem(item Item, ilvl int32) Item {
+	if variant, ok := FindItemVariant(item, ilvl); ok {
+		return variant
+	}
+
+	oldBudget := itemStatBudget(item.Ilvl, item.Quality)
+	newBudget := itemStatBudget(ilvl, item.Quality)
+	if oldBudget <= 0 || newBudget <= 0 {
+		panic(fmt.Sprintf("Item %d (%s) can't be scaled from ilvl %d to %d.", item.ID, item.Name, item.Ilvl, ilvl))
+	}
+	budgetRatio := newBudget / oldBudget
+	// Base armor and weapon damage grow with item level directly, instead of
+	// sharing the stat budget.
+	ilvlRatio := float64(ilvl) / float64(item.Ilvl)
+
+	var scaledStats stats.Stats
+	for stat, value := range item.Stats {
+		ratio := budgetRatio
+		if stat == int(stats.Armor) {
+			ratio = ilvlRatio
+		}
+		scaledStats[stat] = math.Round(value * ratio)
+	}
+
+	item.Stats = scaledStats
+	item.WeaponDamageMin = math.Round(item.WeaponDamageMin * ilvlRatio)
+	item.WeaponDamageMax = math.Round(item.WeaponDamageMax * ilvlRatio)
+	item.Ilvl = ilvl
+	return item
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestScaleItem(t *testing.T) {
+	addToDatabase(&proto.SimDatabase{
+		Items: []*proto.SimItem{
+			{Id: 900001, Name: "Scaling Blade", Type: proto.ItemType_ItemTypeWeapon, Ilvl: 200, Quality: proto.ItemQuality_ItemQualityEpic,
+				Stats: stats.Stats{stats.Strength: 40, stats.Armor: 100}.ToFloatArray(), WeaponDamageMin: 200, WeaponDamageMax: 300},
+			{Id: 900002, Name: "Scaling Blade", Type: proto.ItemType_ItemTypeWeapon, Ilvl: 213, Quality: proto.ItemQuality_ItemQualityEpic, Heroic: true,
+				Stats: stats.Stats{stats.Strength: 45}.ToFloatArray()},
+		},
+	})
+	item := ItemsByID[900001]
+
+	// A heroic variant exists at 213, so it is used directly.
+	if heroic := NewItem(ItemSpec{ID: 900001, Ilvl: 213}); heroic.ID != 900002 || !heroic.Heroic {
+		t.Errorf("Expected the heroic variant, got %v", heroic)
+	}
+
+	scaled := ScaleItem(item, 226)
+	if scaled.ID != item.ID || scaled.Ilvl != 226 {
+		t.Errorf("Expected the scaled item to keep its ID, got %v", scaled)
+	}
+	// (226 - 1.3) / (200 - 1.3) = 1.1308...
+	if scaled.Stats[stats.Strength] != 45 {
+		t.Errorf("Expected 45 strength, got %f", scaled.Stats[stats.Strength])
+	}
+	if scaled.Stats[stats.Armor] != 113 || scaled.WeaponDamageMin != 226 || scaled.WeaponDamageMax != 339 {
+		t.Errorf("Expected armor and weapon damage to scale with ilvl, got %v", scaled)
+	}
+	if item.Stats[stats.Strength] != 40 {
+		t.Errorf("Scaling modified the database item")
+	}
+
+	if spec := scaled.ToItemSpecProto(); spec.Ilvl != 226 {
+		t.Errorf("Expected the item spec to keep the scaled ilvl, got %v", spec)
+	}
+}
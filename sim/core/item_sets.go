@@ -3,6 +3,10 @@ package core
 import (
 	"fmt"
 	"slices"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
 )
 
 type ItemSet struct {
@@ -14,6 +18,93 @@ type ItemSet struct {
 	//
 	// The function should apply any benefits provided by the set bonus.
 	Bonuses map[int32]ApplyEffect
+
+	// Maps set piece requirement to a set bonus described by data, for bonuses
+	// which don't need any custom code. May be combined with Bonuses for the
+	// same piece requirement.
+	Records map[int32]SetBonusRecord
+}
+
+// A set bonus described by data. Records are resolved into effects when the
+// set bonus is applied.
+type SetBonusRecord struct {
+	// Flat stats gained from the bonus.
+	Stats stats.Stats
+
+	// Multipliers for all damage dealt, and for damage dealt by spell school.
+	DamageMultiplier        float64
+	SchoolDamageMultipliers map[stats.SchoolIndex]float64
+
+	// An optional proc granting temporary stats.
+	Proc *SetBonusStatProc
+}
+
+type SetBonusStatProc struct {
+	Name     string
+	ActionID ActionID
+	Bonus    stats.Stats
+	Duration time.Duration
+
+	Callback   AuraCallback
+	ProcMask   ProcMask
+	Outcome    HitOutcome
+	Harmful    bool
+	ProcChance float64
+	PPM        float64
+	ICD        time.Duration
+}
+
+func (record SetBonusRecord) apply(agent Agent) {
+	character := agent.GetCharacter()
+	character.AddStats(record.Stats)
+
+	if record.DamageMultiplier != 0 {
+		character.PseudoStats.DamageDealtMultiplier *= record.DamageMultiplier
+	}
+	for school, multiplier := range record.SchoolDamageMultipliers {
+		character.PseudoStats.SchoolDamageDealtMultiplier[school] *= multiplier
+	}
+
+	if proc := record.Proc; proc != nil {
+		procAura := character.NewTemporaryStatsAura(proc.Name+" Proc", proc.ActionID, proc.Bonus, proc.Duration)
+		MakeProcTriggerAura(&character.Unit, ProcTrigger{
+			Name:       proc.Name,
+			Callback:   proc.Callback,
+			ProcMask:   proc.ProcMask,
+			Outcome:    proc.Outcome,
+			Harmful:    proc.Harmful,
+			ProcChance: proc.ProcChance,
+			PPM:        proc.PPM,
+			ICD:        proc.ICD,
+			Handler: func(sim *Simulation, _ *Spell, _ *SpellResult) {
+				procAura.Activate(sim)
+			},
+		})
+	}
+}
+
+func (set *ItemSet) hasBonus(numPieces int32) bool {
+	_, hasEffect := set.Bonuses[numPieces]
+	_, hasRecord := set.Records[numPieces]
+	return hasEffect || hasRecord
+}
+
+// Returns the combined effect of the code and data bonuses for the given
+// number of pieces.
+func (set *ItemSet) bonusEffect(numPieces int32) ApplyEffect {
+	effect, hasEffect := set.Bonuses[numPieces]
+	record, hasRecord := set.Records[numPieces]
+	switch {
+	case hasEffect && hasRecord:
+		return func(agent Agent) {
+			record.apply(agent)
+			effect(agent)
+		}
+	case hasRecord:
+		return record.apply
+	default:
+		return effect
+	}
 }
 
 func (set ItemSet) Items() []Item {
@@ -68,10 +159,14 @@ func (character *Character) HasSetBonus(set *ItemSet, numItems int32) bool {
 		panic("HasSetBonus is very slow and should never be called after finalization. Try caching the value during construction instead!")
 	}
 
-	if _, ok := set.Bonuses[numItems]; !ok {
+	if !set.hasBonus(numItems) {
 		panic(fmt.Sprintf("Item set %s does not have a bonus with %d pieces.", set.Name, numItems))
 	}
 
+	if enabled, ok := character.setBonusOverride(set, numItems); ok {
+		return enabled
+	}
+
 	var count int32
 	for _, item := range character.Equipment {
 		if item.SetName == "" {
@@ -111,11 +206,15 @@ func (character *Character) GetActiveSetBonuses() []ActiveSetBonus {
 		for _, set := range sets {
 			if set.Name == item.SetName || set.AlternativeName == item.SetName {
 				setItemCount[set]++
-				if bonusEffect, ok := set.Bonuses[setItemCount[set]]; ok {
+				numPieces := setItemCount[set]
+				if enabled, ok := character.setBonusOverride(set, numPieces); ok && !enabled {
+					break
+				}
+				if set.hasBonus(numPieces) {
 					activeBonuses = append(activeBonuses, ActiveSetBonus{
 						Name:        set.Name,
-						NumPieces:   setItemCount[set],
-						BonusEffect: bonusEffect,
+						NumPieces:   numPieces,
+						BonusEffect: set.bonusEffect(numPieces),
 					})
 				}
 				break
@@ -123,9 +222,63 @@ func (character *Character) GetActiveSetBonuses() []ActiveSetBonus {
 		}
 	}
 
+	// Bonuses enabled by overrides, without enough pieces equipped.
+	for _, override := range character.setBonusOverrides {
+		if override.enabled && override.numPieces > setItemCount[override.set] {
+			activeBonuses = append(activeBonuses, ActiveSetBonus{
+				Name:        override.set.Name,
+				NumPieces:   override.numPieces,
+				BonusEffect: override.set.bonusEffect(override.numPieces),
+			})
+		}
+	}
+
 	return activeBonuses
 }
 
+type setBonusOverride struct {
+	set       *ItemSet
+	numPieces int32
+	enabled   bool
+}
+
+func (character *Character) setBonusOverride(set *ItemSet, numPieces int32) (enabled bool, ok bool) {
+	for _, override := range character.setBonusOverrides {
+		if override.set == set && override.numPieces == numPieces {
+			return override.enabled, true
+		}
+	}
+	return false, false
+}
+
+// Force set bonuses on or off, regardless of the equipped pieces.
+func (character *Character) applySetBonusOverrides(overrides []*proto.SetBonusOverride) {
+	for _, override := range overrides {
+		set := findItemSet(override.SetName)
+		if set == nil || !set.hasBonus(override.NumPieces) {
+			character.Warnings = append(character.Warnings, fmt.Sprintf("Ignored override for unknown set bonus %s (%dpc).", override.SetName, override.NumPieces))
+			continue
+		}
+		character.setBonusOverrides = slices.DeleteFunc(character.setBonusOverrides, func(o setBonusOverride) bool {
+			return o.set == set && o.numPieces == override.NumPieces
+		})
+		character.setBonusOverrides = append(character.setBonusOverrides, setBonusOverride{
+			set:       set,
+			numPieces: override.NumPieces,
+			enabled:   override.Enabled,
+		})
+	}
+}
+
+func findItemSet(name string) *ItemSet {
+	for _, set := range sets {
+		if set.Name == name || (set.AlternativeName != "" && set.AlternativeName == name) {
+			return set
+		}
+	}
+	return nil
+}
+
 // Apply effects from item set bonuses.
 func (character *Character) applyItemSetBonusEffects(agent Agent) {
 	activeSetBonuses := character.GetActiveSetBonuses()
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestSetBonusOverrides(t *testing.T) {
+	// NewItemSet needs items from the set in the DB when built with_db.
+	addToDatabase(&proto.SimDatabase{Items: []*proto.SimItem{{Id: 930001, SetName: "Test Override Regalia"}}})
+	set := NewItemSet(ItemSet{
+		Name: "Test Override Regalia",
+		Records: map[int32]SetBonusRecord{
+			2: {Stats: stats.Stats{stats.SpellPower: 10}},
+			4: {DamageMultiplier: 1.05},
+		},
+	})
+	t.Cleanup(func() {
+		delete(ItemsByID, 930001)
+		sets = slices.DeleteFunc(sets, func(s *ItemSet) bool { return s == set })
+	})
+
+	newCharacter := func(numPieces int, overrides ...*proto.SetBonusOverride) *Character {
+		character := &Character{Name: "Test"}
+		for i := 0; i < numPieces; i++ {
+			character.Equipment[i] = Item{ID: int32(i + 1), SetName: set.Name}
+		}
+		character.applySetBonusOverrides(overrides)
+		return character
+	}
+
+	character := newCharacter(2)
+	if !character.HasSetBonus(set, 2) || character.HasSetBonus(set, 4) {
+		t.Errorf("Expected only the 2pc bonus without overrides")
+	}
+
+	character = newCharacter(2,
+		&proto.SetBonusOverride{SetName: set.Name, NumPieces: 2, Enabled: false},
+		&proto.SetBonusOverride{SetName: set.Name, NumPieces: 4, Enabled: true},
+		&proto.SetBonusOverride{SetName: "Missing Set", NumPieces: 2, Enabled: true})
+	if character.HasSetBonus(set, 2) || !character.HasSetBonus(set, 4) {
+		t.Errorf("Expected overrides to swap the 2pc bonus for the 4pc bonus")
+	}
+	if names := character.GetActiveSetBonusNames(); len(names) != 1 || names[0] != "Test Override Regalia (4pc)" {
+		t.Errorf("Unexpected active set bonuses %v", names)
+	}
+	if len(character.Warnings) != 1 {
+		t.Errorf("Expected a warning for the unknown set, got %v", character.Warnings)
+	}
+
+	// Later overrides for the same bonus replace earlier ones.
+	character = newCharacter(0,
+		&proto.SetBonusOverride{SetName: set.Name, NumPieces: 2, Enabled: true},
+		&proto.SetBonusOverride{SetName: set.Name, NumPieces: 2, Enabled: false})
+	if character.HasSetBonus(set, 2) || len(character.GetActiveSetBonuses()) != 0 {
+		t.Errorf("Expected the last override to win")
+	}
+}
@@ -0,0 +1,403 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+type LogEventType int32
+
+const (
+	// Free-form text, e.g. from Unit.Log().
+	LogEventMessage LogEventType = iota
+	LogEventDamage
+	LogEventHealing
+	LogEventShield
+	LogEventCastStart
+	LogEventCastComplete
+	LogEventCastFailed
+	LogEventAuraGained
+	LogEventAuraRefreshed
+	LogEventAuraFaded
+	LogEventAuraStacks
+	LogEventResourceGain
+	LogEventResourceSpend
+	LogEventCastCancelled
+	LogEventAuraCancelled
+)
+
+var logEventTypeNames = []string{
+	LogEventMessage:       "message",
+	LogEventDamage:        "damage",
+	LogEventHealing:       "healing",
+	LogEventShield:        "shield",
+	LogEventCastStart:     "castStart",
+	LogEventCastComplete:  "castComplete",
+	LogEventCastFailed:    "castFailed",
+	LogEventAuraGained:    "auraGained",
+	LogEventAuraRefreshed: "auraRefreshed",
+	LogEventAuraFaded:     "auraFaded",
+	LogEventAuraStacks:    "auraStacks",
+	LogEventResourceGain:  "resourceGain",
+	LogEventResourceSpend: "resourceSpend",
+	LogEventCastCancelled: "castCancelled",
+	LogEventAuraCancelled: "auraCancelled",
+}
+
+func (eventType LogEventType) String() string {
+	return logEventTypeNames[eventType]
+}
+
+// A single debug log entry. Events are only turned into text or JSON once the
+// sim is done, so logging costs little more than appending to a slice.
+type LogEvent struct {
+	Timestamp time.Duration
+	Type      LogEventType
+
+	Actor    *Unit // Unit which logged the event, nil for sim-wide messages.
+	Target   *Unit
+	ActionID ActionID
+
+	// Damage, healing and shielding done, resources gained or spent, or the
+	// cost of a cast.
+	Amount float64
+	Threat float64
+
+	// Damage and healing only.
+	Outcome  HitOutcome
+	Periodic bool
+	Blocked  float64 // Damage prevented by block value.
+
+	// Resource values, or aura stacks, before and after the event.
+	Resource proto.ResourceType
+	Before   float64
+	After    float64
+
+	// Cast start only.
+	CastTime      time.Duration
+	EffectiveTime time.Duration
+
+	// Messages and cast failures only, formatted lazily.
+	format string
+	args   []interface{}
+}
+
+// Debug logs of a sim, see SimOptions.debug. Events are kept in fixed size
+// blocks, so long logs never need to be copied to grow.
+type EventLog struct {
+	blocks    [][]LogEvent
+	numEvents int
+}
+
+const logEventBlockSize = 4096
+
+func (eventLog *EventLog) add(event LogEvent) {
+	if n := len(eventLog.blocks); n == 0 || len(eventLog.blocks[n-1]) == logEventBlockSize {
+		eventLog.blocks = append(eventLog.blocks, make([]LogEvent, 0, logEventBlockSize))
+	}
+	last := &eventLog.blocks[len(eventLog.blocks)-1]
+	*last = append(*last, event)
+	eventLog.numEvents++
+}
+
+func (eventLog *EventLog) Len() int {
+	return eventLog.numEvents
+}
+
+// Calls f on each event, in order.
+func (eventLog *EventLog) Each(f func(event *LogEvent)) {
+	for _, block := range eventLog.blocks {
+		for i := range block {
+			f(&block[i])
+		}
+	}
+}
+
+// Receives debug log events as they're logged, see RunRaidSimWithLogEventsAsync.
+// The event is only valid during the call.
+type LogEventFunc func(event *LogEvent)
+
+// Records a structured log event. Like other logging, callers should check
+// that sim.Log is set first.
+func (sim *Simulation) logEvent(event LogEvent) {
+	event.Timestamp = sim.CurrentTime
+	if sim.eventLog != nil {
+		sim.eventLog.add(event)
+	} else if sim.onLogEvent != nil {
+		sim.onLogEvent(&event)
+	} else {
+		// sim.Log was replaced by a custom logger, which only takes text.
+		sim.Log("%s", event.Line())
+	}
+}
+
+// Starts recording debug logs into eventLog, or passing them to
+// sim.onLogEvent if eventLog is nil.
+func (sim *Simulation) startLogging(eventLog *EventLog) {
+	sim.eventLog = eventLog
+	sim.Log = func(message string, vals ...interface{}) {
+		sim.logEvent(newLogMessage(LogEventMessage, nil, message, vals))
+	}
+}
+
+// Returns the log to record debug logs into, or nil if they're passed to
+// sim.onLogEvent instead.
+func (sim *Simulation) newEventLog() *EventLog {
+	if sim.onLogEvent != nil {
+		return nil
+	}
+	return &EventLog{}
+}
+
+func newLogMessage(eventType LogEventType, actor *Unit, format string, args []interface{}) LogEvent {
+	event := LogEvent{Type: eventType, Actor: actor, format: format, args: args}
+	for _, arg := range args {
+		if !isImmutableLogArg(arg) {
+			// Might change before the log is formatted, e.g. a slice.
+			event.format, event.args = "%s", []interface{}{fmt.Sprintf(format, args...)}
+			break
+		}
+	}
+	return event
+}
+
+func isImmutableLogArg(arg interface{}) bool {
+	switch arg.(type) {
+	case string, bool, int, int32, int64, uint, uint32, uint64, float32, float64,
+		time.Duration, ActionID, HitOutcome, stats.Stats, proto.Spec, proto.Class:
+		return true
+	}
+	return false
+}
+
+// Text of the event as it appears in the logs, without the timestamp.
+func (event *LogEvent) Line() string {
+	var sb strings.Builder
+	event.writeLine(&sb)
+	return sb.String()
+}
+
+func (event *LogEvent) writeLine(sb *strings.Builder) {
+	if event.Actor != nil {
+		writeLogLabel(sb, event.Actor)
+		sb.WriteByte(' ')
+	}
+
+	switch event.Type {
+	case LogEventDamage, LogEventHealing:
+		writeLogLabel(sb, event.Target)
+		fmt.Fprintf(sb, " %s ", event.ActionID)
+		if event.Periodic {
+			sb.WriteString("tick ")
+		}
+		sb.WriteString(event.Outcome.String())
+		if event.Type == LogEventHealing {
+			fmt.Fprintf(sb, " for %0.3f healing", event.Amount)
+		} else if event.Outcome.Matches(OutcomeLanded) {
+			fmt.Fprintf(sb, " for %0.3f damage", event.Amount)
+			if event.Outcome.Matches(OutcomeBlock) {
+				fmt.Fprintf(sb, " (%0.3f blocked)", event.Blocked)
+			}
+		}
+		fmt.Fprintf(sb, ". (Threat: %0.3f)", event.Threat)
+	case LogEventShield:
+		writeLogLabel(sb, event.Target)
+		fmt.Fprintf(sb, " %s Hit for %0.3f shielding. (Threat: %0.3f)", event.ActionID, event.Amount, event.Threat)
+	case LogEventCastStart:
+		fmt.Fprintf(sb, "Casting %s (Cost = %0.03f, Cast Time = %s, Effective Time = %s)", event.ActionID, event.Amount, event.CastTime, event.EffectiveTime)
+	case LogEventCastComplete:
+		fmt.Fprintf(sb, "Completed cast %s", event.ActionID)
+	case LogEventCastCancelled:
+		fmt.Fprintf(sb, "Cancelled cast %s", event.ActionID)
+	case LogEventCastFailed:
+		sb.WriteString(event.ActionID.String())
+		sb.WriteString(" failed to cast: ")
+		fmt.Fprintf(sb, event.format, event.args...)
+	case LogEventAuraGained:
+		fmt.Fprintf(sb, "Aura gained: %s", event.ActionID)
+	case LogEventAuraRefreshed:
+		fmt.Fprintf(sb, "Aura refreshed: %s", event.ActionID)
+	case LogEventAuraFaded:
+		fmt.Fprintf(sb, "Aura faded: %s", event.ActionID)
+	case LogEventAuraCancelled:
+		fmt.Fprintf(sb, "Aura cancelled: %s", event.ActionID)
+	case LogEventAuraStacks:
+		fmt.Fprintf(sb, "%s stacks: %d --> %d", event.ActionID, int32(event.Before), int32(event.After))
+	case LogEventResourceGain, LogEventResourceSpend:
+		verb := "Gained"
+		if event.Type == LogEventResourceSpend {
+			verb = "Spent"
+		}
+		fmt.Fprintf(sb, "%s %0.3f %s from %s (%0.3f --> %0.3f).", verb, event.Amount, resourceLogNames[event.Resource], event.ActionID, event.Before, event.After)
+	default:
+		fmt.Fprintf(sb, event.format, event.args...)
+	}
+}
+
+// Same as Unit.LogLabel(), without building a string.
+func writeLogLabel(sb *strings.Builder, unit *Unit) {
+	sb.WriteByte('[')
+	sb.WriteString(unit.Label)
+	sb.WriteByte(']')
+}
+
+var resourceLogNames = map[proto.ResourceType]string{
+	proto.ResourceType_ResourceTypeMana:       "mana",
+	proto.ResourceType_ResourceTypeEnergy:     "energy",
+	proto.ResourceType_ResourceTypeRage:       "rage",
+	proto.ResourceType_ResourceTypeFocus:      "focus",
+	proto.ResourceType_ResourceTypeHealth:     "health",
+	proto.ResourceType_ResourceTypeRunicPower: "runic power",
+}
+
+// Formats the logs, see SimOptions.log_format.
+func (eventLog *EventLog) Format(format proto.LogFormat) string {
+	if eventLog == nil {
+		return ""
+	}
+	if format == proto.LogFormat_LogFormatJson {
+		return eventLog.JSON()
+	}
+	return eventLog.Text()
+}
+
+// The classic text logs, one line per event.
+func (eventLog *EventLog) Text() string {
+	var sb strings.Builder
+	sb.Grow(eventLog.numEvents * 80)
+	var timestamp []byte
+	eventLog.Each(func(event *LogEvent) {
+		timestamp = append(strconv.AppendFloat(append(timestamp[:0], '['), event.Timestamp.Seconds(), 'f', 2, 64), "] "...)
+		sb.Write(timestamp)
+		event.writeLine(&sb)
+		sb.WriteByte('\n')
+	})
+	return sb.String()
+}
+
+// One JSON object per line and event, with the fields relevant to its type.
+func (eventLog *EventLog) JSON() string {
+	var sb strings.Builder
+	encoder := json.NewEncoder(&sb)
+	encoder.SetEscapeHTML(false)
+	eventLog.Each(func(event *LogEvent) {
+		// Encode() ends each object with a newline.
+		if err := encoder.Encode(event.jsonFields()); err != nil {
+			panic(err)
+		}
+	})
+	return sb.String()
+}
+
+func (event *LogEvent) jsonFields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"time":    event.Timestamp.Seconds(),
+		"type":    event.Type.String(),
+		"message": event.Line(),
+	}
+	if event.Actor != nil {
+		fields["actor"] = event.Actor.Label
+	}
+	if event.Target != nil {
+		fields["target"] = event.Target.Label
+	}
+	if !event.ActionID.IsEmptyAction() {
+		actionID := map[string]interface{}{}
+		if event.ActionID.SpellID != 0 {
+			actionID["spellId"] = event.ActionID.SpellID
+		} else if event.ActionID.ItemID != 0 {
+			actionID["itemId"] = event.ActionID.ItemID
+		} else {
+			actionID["otherId"] = event.ActionID.OtherID.String()
+		}
+		if event.ActionID.Tag != 0 {
+			actionID["tag"] = event.ActionID.Tag
+		}
+		if metadata, ok := event.ActionID.Metadata(); ok {
+			actionID["name"] = metadata.Name
+		}
+		fields["actionId"] = actionID
+	}
+
+	switch event.Type {
+	case LogEventDamage, LogEventHealing:
+		fields["outcome"] = event.Outcome.String()
+		fields["amount"] = event.Amount
+		fields["threat"] = event.Threat
+		fields["periodic"] = event.Periodic
+		if event.Outcome.Matches(OutcomeBlock) {
+			fields["blocked"] = event.Blocked
+		}
+	case LogEventShield:
+		fields["amount"] = event.Amount
+		fields["threat"] = event.Threat
+	case LogEventCastStart:
+		fields["cost"] = event.Amount
+		fields["castTime"] = event.CastTime.Seconds()
+		fields["effectiveTime"] = event.EffectiveTime.Seconds()
+	case LogEventAuraStacks:
+		fields["before"] = event.Before
+		fields["after"] = event.After
+	case LogEventResourceGain, LogEventResourceSpend:
+		fields["resource"] = resourceLogNames[event.Resource]
+		fields["amount"] = event.Amount
+		fields["before"] = event.Before
+		fields["after"] = event.After
+	}
+	return fields
+}
+
+func (spell *Spell) logResult(sim *Simulation, eventType LogEventType, result *SpellResult, isPeriodic bool) {
+	sim.logEvent(LogEvent{
+		Type:     eventType,
+		Actor:    spell.Unit,
+		Target:   result.Target,
+		ActionID: spell.ActionID,
+		Amount:   result.Damage,
+		Threat:   result.Threat,
+		Outcome:  result.Outcome,
+		Periodic: isPeriodic,
+		Blocked:  result.BlockedDamage,
+	})
+}
+
+func (spell *Spell) logCast(sim *Simulation, cost float64, castTime time.Duration, effectiveTime time.Duration) {
+	sim.logEvent(LogEvent{
+		Type:          LogEventCastStart,
+		Actor:         spell.Unit,
+		ActionID:      spell.ActionID,
+		Amount:        cost,
+		CastTime:      castTime,
+		EffectiveTime: effectiveTime,
+	})
+}
+
+func (spell *Spell) logCastComplete(sim *Simulation) {
+	sim.logEvent(LogEvent{Type: LogEventCastComplete, Actor: spell.Unit, ActionID: spell.ActionID})
+}
+
+func (spell *Spell) logCastCancelled(sim *Simulation) {
+	sim.logEvent(LogEvent{Type: LogEventCastCancelled, Actor: spell.Unit, ActionID: spell.ActionID})
+}
+
+func (aura *Aura) logEvent(sim *Simulation, eventType LogEventType) {
+	sim.logEvent(LogEvent{Type: eventType, Actor: aura.Unit, ActionID: aura.ActionID})
+}
+
+func (unit *Unit) logResource(sim *Simulation, eventType LogEventType, resource proto.ResourceType, amount float64, metrics *ResourceMetrics, before float64, after float64) {
+	sim.logEvent(LogEvent{
+		Type:     eventType,
+		Actor:    unit,
+		ActionID: metrics.ActionID,
+		Amount:   amount,
+		Resource: resource,
+		Before:   before,
+		After:    after,
+	})
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestJSONLogsMatchText(t *testing.T) {
+	request := func(format proto.LogFormat) *proto.RaidSimRequest {
+		return tankSimRequest(&proto.SimOptions{Iterations: 2, RandomSeed: 101, Debug: true, LogFormat: format})
+	}
+	text := RunRaidSim(request(proto.LogFormat_LogFormatText)).Logs
+	jsonLogs := RunRaidSim(request(proto.LogFormat_LogFormatJson)).Logs
+
+	textLines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	jsonLines := strings.Split(strings.TrimSuffix(jsonLogs, "\n"), "\n")
+	if len(textLines) != len(jsonLines) {
+		t.Fatalf("Expected %d JSON events, got %d", len(textLines), len(jsonLines))
+	}
+
+	types := make(map[string]int)
+	for i, line := range jsonLines {
+		var event struct {
+			Time    float64
+			Type    string
+			Actor   string
+			Target  string
+			Amount  float64
+			Message string
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Invalid JSON event %q: %s", line, err)
+		}
+		if _, message, _ := strings.Cut(textLines[i], "] "); message != event.Message {
+			t.Fatalf("Expected message %q, got %q", message, event.Message)
+		}
+		if event.Type == "damage" && (event.Actor != "Target 1" || event.Target != "Tank (#1)") {
+			t.Fatalf("Expected damage from the target to the tank, got %s", line)
+		}
+		types[event.Type]++
+	}
+	for _, eventType := range []string{"damage", "castStart", "castComplete", "resourceSpend", "message"} {
+		if types[eventType] == 0 {
+			t.Errorf("Expected %s events, got %v", eventType, types)
+		}
+	}
+}
+
+func TestMutableLogArgs(t *testing.T) {
+	sim := &Simulation{}
+	eventLog := &EventLog{}
+	sim.startLogging(eventLog)
+
+	thresholds := []float64{1, 2}
+	sim.Log("Thresholds: %v, %s", thresholds, "ok")
+	thresholds[0] = 3
+
+	if logs := eventLog.Text(); logs != "[0.00] Thresholds: [1 2], ok\n" {
+		t.Fatalf("Expected logs to keep the values at the time of logging, got %q", logs)
+	}
+}
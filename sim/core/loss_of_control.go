@@ -0,0 +1,272 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Time after a loss of control effect ends before its diminishing returns
+// reset.
+const DiminishingReturnsReset = time.Second * 18
+
+// Duration multipliers for consecutive applications of the same type of
+// effect. Any further applications are resisted.
+var diminishingReturnsMultipliers = []float64{1, 0.5, 0.25}
+
+// Stuns and silences on a player, see proto.LossOfControlEvent.
+type lossOfControl struct {
+	unit *Unit
+
+	// Indexed by proto.LossOfControlType.
+	effects [2]*lossOfControlEffect
+
+	// Only set if the player has a PvP trinket.
+	pvpTrinket *Spell
+
+	// When the GCD would have been ready if not for the current stun.
+	gcdReadyAt time.Duration
+}
+
+type lossOfControlEffect struct {
+	aura *Aura
+
+	drLevel   int
+	drResetAt time.Duration
+}
+
+func (unit *Unit) getLossOfControl() *lossOfControl {
+	if unit.lossOfControl != nil {
+		return unit.lossOfControl
+	}
+
+	lc := &lossOfControl{unit: unit}
+	lc.effects[proto.LossOfControlType_LossOfControlStun] = &lossOfControlEffect{
+		aura: unit.RegisterAura(Aura{
+			Label:    "Stunned",
+			ActionID: ActionID{OtherID: proto.OtherAction_OtherActionStun},
+			OnGain: func(aura *Aura, sim *Simulation) {
+				aura.Unit.interruptCasts(sim, false)
+				aura.Unit.AutoAttacks.CancelAutoSwing(sim)
+				lc.gcdReadyAt = aura.Unit.GCD.ReadyAt()
+			},
+			OnExpire: func(aura *Aura, sim *Simulation) {
+				if !aura.Unit.IsEnabled() {
+					return
+				}
+				// Stuns can be broken before the GCD they pushed back.
+				aura.Unit.SetGCDTimer(sim, max(sim.CurrentTime, lc.gcdReadyAt))
+				if !aura.Unit.IsOutOfCombat() {
+					aura.Unit.AutoAttacks.EnableAutoSwing(sim)
+				}
+			},
+		}),
+	}
+	lc.effects[proto.LossOfControlType_LossOfControlSilence] = &lossOfControlEffect{
+		aura: unit.RegisterAura(Aura{
+			Label:    "Silenced",
+			ActionID: ActionID{OtherID: proto.OtherAction_OtherActionSilence},
+			OnGain: func(aura *Aura, sim *Simulation) {
+				aura.Unit.interruptCasts(sim, true)
+			},
+		}),
+	}
+
+	unit.lossOfControl = lc
+	return lc
+}
+
+func (lc *lossOfControl) reset() {
+	for _, effect := range lc.effects {
+		effect.drLevel = 0
+		effect.drResetAt = 0
+	}
+}
+
+// Whether the effects currently on the unit stop it from casting the spell.
+func (lc *lossOfControl) prevents(spell *Spell) bool {
+	if spell == lc.pvpTrinket {
+		return false
+	}
+	if lc.effects[proto.LossOfControlType_LossOfControlStun].aura.IsActive() {
+		return true
+	}
+	return lc.effects[proto.LossOfControlType_LossOfControlSilence].aura.IsActive() && spell.SpellSchool.Matches(SpellSchoolMagic)
+}
+
+func (lc *lossOfControl) apply(sim *Simulation, effectType proto.LossOfControlType, duration time.Duration, diminishingReturns bool) {
+	if !lc.unit.IsEnabled() {
+		return
+	}
+
+	effect := lc.effects[effectType]
+	if diminishingReturns {
+		if sim.CurrentTime >= effect.drResetAt {
+			effect.drLevel = 0
+		}
+		if effect.drLevel >= len(diminishingReturnsMultipliers) {
+			if sim.Log != nil {
+				lc.unit.Log(sim, "Resisted %s due to diminishing returns", effect.aura.Label)
+			}
+			return
+		}
+		duration = time.Duration(float64(duration) * diminishingReturnsMultipliers[effect.drLevel])
+		effect.drLevel++
+	}
+
+	aura := effect.aura
+	if !aura.IsActive() {
+		aura.Duration = duration
+		aura.Activate(sim)
+	} else if aura.RemainingDuration(sim) < duration {
+		aura.UpdateExpires(sim.CurrentTime + duration)
+	}
+	if diminishingReturns {
+		effect.drResetAt = aura.ExpiresAt() + DiminishingReturnsReset
+	}
+	if effectType == proto.LossOfControlType_LossOfControlStun {
+		// Also makes sure the stun expires on time, since auras only expire
+		// when the sim advances.
+		lc.unit.SetGCDTimer(sim, aura.ExpiresAt())
+	}
+
+	if lc.pvpTrinket != nil {
+		StartDelayedAction(sim, DelayedActionOptions{
+			DoAt:     sim.CurrentTime + lc.unit.ReactionTime,
+			OnAction: lc.usePvPTrinket,
+		})
+	}
+}
+
+func (lc *lossOfControl) usePvPTrinket(sim *Simulation) {
+	if !lc.pvpTrinket.IsReady(sim) || !lc.unit.IsEnabled() {
+		return
+	}
+	for _, effect := range lc.effects {
+		if effect.aura.IsActive() {
+			lc.pvpTrinket.Cast(sim, lc.unit)
+			return
+		}
+	}
+}
+
+func (character *Character) enablePvPTrinket() {
+	lc := character.getLossOfControl()
+	lc.pvpTrinket = character.RegisterSpell(SpellConfig{
+		ActionID: ActionID{SpellID: 42292},
+		Flags:    SpellFlagNoOnCastComplete,
+
+		Cast: CastConfig{
+			CD: Cooldown{
+				Timer:    character.NewTimer(),
+				Duration: time.Minute * 2,
+			},
+		},
+
+		ApplyEffects: func(sim *Simulation, _ *Unit, _ *Spell) {
+			for _, effect := range lc.effects {
+				effect.aura.Deactivate(sim)
+			}
+		},
+	})
+}
+
+// Stops the unit's current hardcast and channel, e.g. when it dies or is
+// stunned. If magicOnly is set, physical casts like Steady Shot continue.
+func (unit *Unit) interruptCasts(sim *Simulation, magicOnly bool) {
+	interrupts := func(spell *Spell) bool {
+		return !magicOnly || (spell != nil && spell.SpellSchool.Matches(SpellSchoolMagic))
+	}
+
+	if interrupts(unit.Hardcast.spell) {
+		if unit.hardcastAction != nil {
+			unit.hardcastAction.Cancel(sim)
+		}
+		var interruptedCast *Spell
+		if unit.Hardcast.Expires > sim.CurrentTime {
+			interruptedCast = unit.Hardcast.spell
+		}
+		unit.Hardcast = Hardcast{Expires: startingCDTime}
+		if interruptedCast != nil {
+			unit.OnCastInterrupted(sim, interruptedCast)
+		}
+	}
+	if channeledDot := unit.ChanneledDot; channeledDot != nil && interrupts(channeledDot.Spell) {
+		channeledDot.Cancel(sim)
+		unit.OnCastInterrupted(sim, channeledDot.Spell)
+	}
+}
+
+// An encounter event which stuns or silences players, see
+// proto.LossOfControlEvent.
+type lossOfControlEvent struct {
+	effectType         proto.LossOfControlType
+	startAt            time.Duration
+	interval           time.Duration
+	duration           time.Duration
+	diminishingReturns bool
+
+	units []*Unit
+}
+
+func (env *Environment) setupLossOfControlEvents(raidProto *proto.Raid, configs []*proto.LossOfControlEvent) {
+	var allPlayers []*Unit
+	for partyIdx, party := range env.Raid.Parties {
+		for playerIdx, player := range party.Players {
+			// Skips target dummies.
+			if playerIdx < len(raidProto.Parties[partyIdx].Players) {
+				allPlayers = append(allPlayers, &player.GetCharacter().Unit)
+			}
+		}
+	}
+
+	for _, config := range configs {
+		if config.Duration <= 0 {
+			continue
+		}
+		event := &lossOfControlEvent{
+			effectType:         config.Type,
+			startAt:            DurationFromSeconds(config.StartTime),
+			interval:           DurationFromSeconds(config.Interval),
+			duration:           DurationFromSeconds(config.Duration),
+			diminishingReturns: config.DiminishingReturns,
+			units:              allPlayers,
+		}
+		if len(config.Players) > 0 {
+			event.units = nil
+			for _, ref := range config.Players {
+				if unit := env.GetUnit(ref, nil); unit != nil && unit.Type == PlayerUnit {
+					event.units = append(event.units, unit)
+				}
+			}
+		}
+		for _, unit := range event.units {
+			unit.getLossOfControl()
+		}
+		env.Encounter.lossOfControlEvents = append(env.Encounter.lossOfControlEvents, event)
+	}
+}
+
+func (encounter *Encounter) resetLossOfControlEvents(sim *Simulation) {
+	for _, event := range encounter.lossOfControlEvents {
+		event := event
+		StartDelayedAction(sim, DelayedActionOptions{
+			DoAt: event.startAt,
+			OnAction: func(sim *Simulation) {
+				event.apply(sim)
+				if event.interval > 0 {
+					StartPeriodicAction(sim, PeriodicActionOptions{
+						Period:   event.interval,
+						OnAction: event.apply,
+					})
+				}
+			},
+		})
+	}
+}
+
+func (event *lossOfControlEvent) apply(sim *Simulation) {
+	for _, unit := range event.units {
+		unit.lossOfControl.apply(sim, event.effectType, event.duration, event.diminishingReturns)
+	}
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestLossOfControl(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{})
+	request.Encounter.LossOfControlEvents = []*proto.LossOfControlEvent{
+		{Type: proto.LossOfControlType_LossOfControlStun, StartTime: 1, Interval: 5, Duration: 4, DiminishingReturns: true},
+		{Type: proto.LossOfControlType_LossOfControlSilence, StartTime: 20, Duration: 2},
+	}
+	sim := NewSim(request)
+
+	agent := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	target := sim.Encounter.TargetUnits[0]
+	landed := false
+	steadyShot := agent.RegisterSpell(SpellConfig{
+		ActionID:    ActionID{SpellID: 43},
+		SpellSchool: SpellSchoolPhysical,
+		ProcMask:    ProcMaskRangedSpecial,
+		Cast: CastConfig{
+			DefaultCast: Cast{CastTime: time.Second * 2},
+		},
+		ApplyEffects: func(sim *Simulation, _ *Unit, _ *Spell) {
+			landed = true
+		},
+	})
+	steadyShot.finalize()
+	sim.reset()
+
+	stunned := agent.lossOfControl.effects[proto.LossOfControlType_LossOfControlStun].aura
+	// Stuns interrupt casts, including physical ones.
+	steadyShot.Cast(sim, target)
+	runSimUntil(sim, time.Second*3)
+	if !stunned.IsActive() || landed || agent.Hardcast.Expires > sim.CurrentTime {
+		t.Fatalf("Expected the stun to interrupt the cast")
+	}
+	if agent.Spell.CanCast(sim, target) || agent.NextGCDAt() != time.Second*5 {
+		t.Fatalf("Expected the GCD to be pushed back until the end of the stun, got %s", agent.NextGCDAt())
+	}
+	runSimUntil(sim, time.Millisecond*5500)
+	if stunned.IsActive() || !agent.Spell.CanCast(sim, target) {
+		t.Fatalf("Expected to be able to act once the stun ends")
+	}
+
+	// Diminishing returns halve each stun, and the 4th one is resisted.
+	for _, check := range []struct {
+		at     time.Duration
+		active bool
+	}{
+		{time.Millisecond * 7900, true},
+		{time.Millisecond * 8100, false},
+		{time.Millisecond * 11900, true},
+		{time.Millisecond * 12100, false},
+		{time.Millisecond * 16100, false},
+	} {
+		runSimUntil(sim, check.at)
+		if stunned.IsActive() != check.active {
+			t.Fatalf("Expected the stun to be active = %t at %s", check.active, check.at)
+		}
+	}
+
+	// Silences only stop spells.
+	runSimUntil(sim, time.Second*21)
+	if agent.Spell.CanCast(sim, target) || !steadyShot.CanCast(sim, target) {
+		t.Fatalf("Expected silences to only stop magic spells")
+	}
+}
+
+func TestPvPTrinket(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{Iterations: 20, RandomSeed: 101})
+	request.Raid.Parties[0].Players[0].PvpTrinket = true
+	request.Raid.Parties[0].Players[0].ReactionTimeMs = 200
+	request.Encounter.LossOfControlEvents = []*proto.LossOfControlEvent{
+		{Type: proto.LossOfControlType_LossOfControlStun, StartTime: 1, Interval: 5, Duration: 10},
+	}
+
+	sim := NewSim(request)
+	sim.reset()
+	agent := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	stunned := agent.lossOfControl.effects[proto.LossOfControlType_LossOfControlStun].aura
+	runSimUntil(sim, time.Millisecond*1100)
+	if !stunned.IsActive() {
+		t.Fatalf("Expected to be stunned before reacting")
+	}
+	runSimUntil(sim, time.Millisecond*1300)
+	if stunned.IsActive() {
+		t.Fatalf("Expected the trinket to break the stun")
+	}
+	runSimUntil(sim, time.Second*10)
+	if !stunned.IsActive() {
+		t.Fatalf("Expected the trinket to be on cooldown for the second stun")
+	}
+
+	if result := RunRaidSim(request); result.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", result.ErrorResult)
+	}
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Low memory mode is compiled into the wasm build, so that large raid sims fit
+// in a browser tab. It trades detail which is rarely looked at for a bounded
+// memory footprint:
+//   - Iterations aren't sharded across workers, each of which would hold a
+//     copy of the whole sim.
+//   - At most lowMemoryMaxSamples values are kept for SimOptions.save_all_values,
+//     and lowMemoryMaxTimelineIterations for timelines.
+//   - TMI is aggregated into per-second bins as damage is taken, reusing the
+//     same bins each iteration, rather than keeping a list of every hit.
+const (
+	lowMemoryMaxSamples            = 10000
+	lowMemoryMaxTimelineIterations = 5
+)
+
+// Number of per-iteration values kept for each distribution with
+// SimOptions.save_all_values.
+func (sim *Simulation) retainedSamples() int {
+	if !sim.Options.SaveAllValues {
+		return 0
+	}
+	if sim.lowMemory {
+		return min(int(sim.Options.Iterations), lowMemoryMaxSamples)
+	}
+	return int(sim.Options.Iterations)
+}
+
+func timelineIterations(options *proto.TimelineOptions, lowMemory bool) int32 {
+	if lowMemory {
+		return min(options.Iterations, lowMemoryMaxTimelineIterations)
+	}
+	return options.Iterations
+}
+
+// Adds damage taken to the current second's TMI bin.
+func (unitMetrics *UnitMetrics) addTMIDamage(timestamp time.Duration, weightedDamage float64) {
+	// Damage before the fight starts is never inside a TMI window.
+	if timestamp < 0 {
+		return
+	}
+	second := int(timestamp / time.Second)
+	for len(unitMetrics.tmiSeconds) <= second {
+		unitMetrics.tmiSeconds = append(unitMetrics.tmiSeconds, 0)
+	}
+	unitMetrics.tmiSeconds[second] += weightedDamage
+	unitMetrics.tmiEvents = true
+}
+
+// Like calculateTMI, but from the per-second bins. Windows start on whole
+// seconds, so they're made up of whole bins and the result is the same, up to
+// rounding.
+func (unitMetrics *UnitMetrics) calculateStreamingTMI(sim *Simulation) float64 {
+	if !unitMetrics.tmiEvents || unitMetrics.tmiBin == 0 {
+		return 0
+	}
+
+	bin := int(unitMetrics.tmiBin)
+	// Windows after the last damage taken are left out, like in calculateTMI.
+	lastSecond := len(unitMetrics.tmiSeconds) - 1
+	numBuckets := 0
+	sum := 0.0
+	for tStep := 0; float64(tStep) < sim.Duration.Seconds()-float64(bin) && tStep <= lastSecond; tStep++ {
+		bucket := 0.0
+		for s := tStep; s < min(tStep+bin, lastSecond+1); s++ {
+			bucket += unitMetrics.tmiSeconds[s]
+		}
+		sum += math.Exp(bucket * 10)
+		numBuckets++
+	}
+	if numBuckets == 0 {
+		return 0
+	}
+	return 10 * math.Log(sum/float64(numBuckets))
+}
+
+// Estimates the peak memory used by running a sim, so UIs can warn about sims
+// which would likely run out of memory before starting them.
+func estimateMemory(request *proto.EstimateMemoryRequest) (result *proto.EstimateMemoryResult) {
+	defer func() {
+		if err := recover(); err != nil {
+			result = &proto.EstimateMemoryResult{
+				ErrorResult: fmt.Sprintf("%v\nStack Trace:\n%s", err, debug.Stack()),
+			}
+		}
+	}()
+
+	// Measures the heap retained by the sim itself, after construction garbage
+	// is collected. Other goroutines allocating at the same time make this less
+	// accurate, but it's only an estimate.
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	sim := NewSim(request.Request)
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	simBytes := max(int64(after.HeapAlloc)-int64(before.HeapAlloc), 0)
+
+	options := sim.Options
+	numUnits := int64(len(sim.AllUnits))
+	fightSeconds := int64(sim.BaseDuration.Seconds()) + 1
+
+	// Per unit distributions, plus the raid's DPS and HPS.
+	numDistributions := numUnits*7 + 2
+	metricsBytes := numDistributions * int64(sim.retainedSamples()) * 8
+	if timeline := options.Timeline; timeline != nil && timeline.Iterations > 0 {
+		metricsBytes += numUnits * int64(min(timelineIterations(timeline, sim.lowMemory), options.Iterations)) * fightSeconds * timelineBytesPerUnitSecond
+	}
+
+	logIterations := int64(0)
+	if options.Debug {
+		logIterations = int64(options.Iterations)
+	} else if options.DebugFirstIteration {
+		logIterations = 1
+	}
+	logBytes := logIterations * numUnits * fightSeconds * logBytesPerUnitSecond
+
+	workers := int64(sim.numWorkers())
+	runtime.KeepAlive(sim)
+
+	return &proto.EstimateMemoryResult{
+		TotalBytes:   simBytes*workers + metricsBytes + logBytes,
+		SimBytes:     simBytes,
+		MetricsBytes: metricsBytes,
+		LogBytes:     logBytes,
+		Workers:      int32(workers),
+		LowMemory:    sim.lowMemory,
+	}
+}
+
+// Rough guesses, which only need to be in the right ballpark.
+const (
+	timelineBytesPerUnitSecond = 64
+	logBytesPerUnitSecond      = 1500
+)
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestLowMemorySim(t *testing.T) {
+	request := func() *proto.RaidSimRequest {
+		return tankSimRequest(&proto.SimOptions{Iterations: lowMemoryMaxSamples + 100, RandomSeed: 101, SaveAllValues: true, Concurrency: 4})
+	}
+
+	expected := RunRaidSim(request())
+	if expected.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", expected.ErrorResult)
+	}
+
+	sim := NewSim(request())
+	sim.lowMemory = true
+	if workers := sim.numWorkers(); workers != 1 {
+		t.Fatalf("Expected low memory sims to use 1 worker, got %d", workers)
+	}
+	actual := sim.run()
+
+	expectedTank, actualTank := expected.RaidMetrics.Parties[0].Players[0], actual.RaidMetrics.Parties[0].Players[0]
+	if len(actualTank.Dps.AllValues) != lowMemoryMaxSamples {
+		t.Errorf("Expected %d values, got %d", lowMemoryMaxSamples, len(actualTank.Dps.AllValues))
+	}
+	if expectedTank.Tmi.Avg == 0 || math.Abs(expectedTank.Tmi.Avg-actualTank.Tmi.Avg) > 1e-9*math.Abs(expectedTank.Tmi.Avg) {
+		t.Errorf("Expected streaming TMI to match, got %f instead of %f", actualTank.Tmi.Avg, expectedTank.Tmi.Avg)
+	}
+}
+
+func TestEstimateMemory(t *testing.T) {
+	request := &proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{Iterations: 1000, SaveAllValues: true},
+		Raid:       &proto.Raid{Parties: []*proto.Party{{Buffs: &proto.PartyBuffs{}}}},
+		Encounter: &proto.Encounter{
+			Duration: 60,
+			Targets:  []*proto.Target{{Level: 83}},
+		},
+	}
+
+	result := EstimateMemory(&proto.EstimateMemoryRequest{Request: request})
+	if result.ErrorResult != "" {
+		t.Fatalf("Estimate failed: %s", result.ErrorResult)
+	}
+	// 1 target, 7 distributions each plus 2 for the raid.
+	if result.MetricsBytes != 9*1000*8 || result.Workers != 1 || result.TotalBytes < result.MetricsBytes {
+		t.Fatalf("Unexpected estimate %v", result)
+	}
+
+	if result := EstimateMemory(&proto.EstimateMemoryRequest{}); result.ErrorResult == "" {
+		t.Fatalf("Expected an error for an empty request")
+	}
+}
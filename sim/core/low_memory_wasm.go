@@ -0,0 +1,7 @@
+//go:build wasm
+
+package core
+
+// Browser tabs have a small, hard memory limit, and the wasm build only has a
+// single thread to run workers on anyway.
+const lowMemoryMode = true
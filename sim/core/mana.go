@@ -27,6 +27,8 @@ type manaBar struct {
 	JowiseManaMetrics     *ResourceMetrics
 
 	ReplenishmentAura *Aura
+
+	timeline *resourceTimeline
 }
 
 // EnableManaBar will setup caster stat dependencies (int->mana and int->spellcrit)
@@ -99,10 +101,13 @@ func (unit *Unit) AddMana(sim *Simulation, amount float64, metrics *ResourceMetr
 	metrics.AddEvent(amount, newMana-oldMana)
 
 	if sim.Log != nil {
-		unit.Log(sim, "Gained %0.3f mana from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, oldMana, newMana)
+		unit.logResource(sim, LogEventResourceGain, proto.ResourceType_ResourceTypeMana, amount, metrics, oldMana, newMana)
 	}
 
 	unit.currentMana = newMana
+	if unit.manaBar.timeline != nil {
+		unit.manaBar.timeline.update(sim, newMana, unit.MaxMana())
+	}
 	unit.Metrics.ManaGained += newMana - oldMana
 }
 
@@ -115,10 +120,13 @@ func (unit *Unit) SpendMana(sim *Simulation, amount float64, metrics *ResourceMe
 	metrics.AddEvent(-amount, -amount)
 
 	if sim.Log != nil {
-		unit.Log(sim, "Spent %0.3f mana from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, unit.CurrentMana(), newMana)
+		unit.logResource(sim, LogEventResourceSpend, proto.ResourceType_ResourceTypeMana, amount, metrics, unit.CurrentMana(), newMana)
 	}
 
 	unit.currentMana = newMana
+	if unit.manaBar.timeline != nil {
+		unit.manaBar.timeline.update(sim, newMana, unit.MaxMana())
+	}
 	unit.Metrics.ManaSpent += amount
 }
 
@@ -292,12 +300,15 @@ func (sim *Simulation) initManaTickAction() {
 	sim.AddPendingAction(pa)
 }
 
-func (mb *manaBar) reset() {
+func (mb *manaBar) reset(sim *Simulation) {
 	if mb.unit == nil {
 		return
 	}
 
 	mb.currentMana = mb.unit.MaxMana()
+	if mb.timeline != nil {
+		mb.timeline.update(sim, mb.currentMana, mb.currentMana)
+	}
 }
 
 type ManaCostOptions struct {
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// The gem color requirement for activating a meta gem.
+type MetaGemCondition struct {
+	MinRed    int
+	MinYellow int
+	MinBlue   int
+
+	// If set, requires more gems of the greater color than the lesser color.
+	CompareGreater proto.GemColor
+	CompareLesser  proto.GemColor
+}
+
+// Whether the condition is met by the given number of gems of each color.
+// Hybrid gems count towards both of their colors.
+func (condition MetaGemCondition) IsMet(numRed, numYellow, numBlue int) bool {
+	if numRed < condition.MinRed || numYellow < condition.MinYellow || numBlue < condition.MinBlue {
+		return false
+	}
+	if condition.CompareGreater == proto.GemColor_GemColorUnknown {
+		return true
+	}
+
+	counts := map[proto.GemColor]int{
+		proto.GemColor_GemColorRed:    numRed,
+		proto.GemColor_GemColorYellow: numYellow,
+		proto.GemColor_GemColorBlue:   numBlue,
+	}
+	return counts[condition.CompareGreater] > counts[condition.CompareLesser]
+}
+
+// Keep in sync with ui/core/proto_utils/gems.ts.
+var MetaGemConditions = map[int32]MetaGemCondition{
+	41285: {MinRed: 0, MinYellow: 0, MinBlue: 2}, // Chaotic Skyflare Diamond
+	41307: {MinRed: 1, MinYellow: 1, MinBlue: 1}, // Destructive Skyflare Diamond
+	41333: {MinRed: 3, MinYellow: 0, MinBlue: 0}, // Ember Skyflare Diamond
+	41335: {MinRed: 2, MinYellow: 1, MinBlue: 0}, // Enigmatic Skyflare Diamond
+	41377: {MinRed: 1, MinYellow: 0, MinBlue: 2}, // Effulgent Skyflare Diamond
+	41339: {MinRed: 1, MinYellow: 2, MinBlue: 0}, // Swift Skyflare Diamond
+	41375: {MinRed: 1, MinYellow: 1, MinBlue: 1}, // Tireless Skyflare Diamond
+	41376: {MinRed: 2, MinYellow: 0, MinBlue: 0}, // Revitalizing Skyflare Diamond
+	41378: {MinRed: 0, MinYellow: 2, MinBlue: 1}, // Forlorn Skyflare Diamond
+	41379: {MinRed: 2, MinYellow: 0, MinBlue: 1}, // Impassive Skyflare Diamond
+	41380: {MinRed: 1, MinYellow: 0, MinBlue: 2}, // Austere Earthsiege Diamond
+	41381: {MinRed: 0, MinYellow: 2, MinBlue: 1}, // Persistent Earthsiege Diamond
+	41382: {MinRed: 1, MinYellow: 1, MinBlue: 1}, // Trenchant Earthsiege Diamond
+	41385: {MinRed: 1, MinYellow: 0, MinBlue: 2}, // Invigorating Earthsiege Diamond
+	41389: {MinRed: 2, MinYellow: 1, MinBlue: 0}, // Beaming Earthsiege Diamond
+	41395: {MinRed: 2, MinYellow: 0, MinBlue: 1}, // Bracing Earthsiege Diamond
+	41396: {MinRed: 2, MinYellow: 0, MinBlue: 1}, // Eternal Earthsiege Diamond
+	41397: {MinRed: 0, MinYellow: 0, MinBlue: 3}, // Powerful Earthsiege Diamond
+	41398: {MinRed: 1, MinYellow: 1, MinBlue: 1}, // Relentless Earthsiege Diamond
+	41400: {MinRed: 1, MinYellow: 1, MinBlue: 1}, // Thundering Skyflare Diamond
+	41401: {MinRed: 1, MinYellow: 1, MinBlue: 1}, // Insightful Earthsiege Diamond
+	44076: {MinRed: 1, MinYellow: 2, MinBlue: 0}, // Swift Starflare Diamond
+	44078: {MinRed: 1, MinYellow: 1, MinBlue: 1}, // Tireless Starflare Diamond
+	44081: {MinRed: 2, MinYellow: 0, MinBlue: 1}, // Enigmatic Starflare Diamond
+	44082: {MinRed: 1, MinYellow: 0, MinBlue: 2}, // Impassive Starflare Diamond
+	44084: {MinRed: 0, MinYellow: 2, MinBlue: 1}, // Forlorn Starflare Diamond
+	44087: {MinRed: 0, MinYellow: 0, MinBlue: 3}, // Persistent Earthshatter Diamond
+	44088: {MinRed: 0, MinYellow: 1, MinBlue: 2}, // Powerful Earthshatter Diamond
+	44089: {MinRed: 1, MinYellow: 1, MinBlue: 1}, // Trenchant Earthshatter Diamond
+
+	// TBC gems
+	25899: {MinRed: 2, MinYellow: 2, MinBlue: 2}, // Brutal Earthstorm Diamond
+	34220: {MinRed: 0, MinYellow: 0, MinBlue: 2}, // Chaotic Skyfire Diamond
+	25890: {MinRed: 2, MinYellow: 2, MinBlue: 2}, // Destructive Skyfire Diamond
+	35503: {MinRed: 3, MinYellow: 0, MinBlue: 0}, // Ember Skyfire Diamond
+	35501: {MinRed: 0, MinYellow: 1, MinBlue: 2}, // Eternal Earthstorm Diamond
+	32641: {MinRed: 0, MinYellow: 3, MinBlue: 0}, // Imbued Unstable Diamond
+	25901: {MinRed: 2, MinYellow: 2, MinBlue: 2}, // Insightful Earthstorm Diamond
+	25896: {MinRed: 0, MinYellow: 0, MinBlue: 3}, // Powerful Earthstorm Diamond
+	32409: {MinRed: 2, MinYellow: 2, MinBlue: 2}, // Relentless Earthstorm Diamond
+	25894: {MinRed: 1, MinYellow: 2, MinBlue: 0}, // Swift Skyfire Diamond
+	28557: {MinRed: 1, MinYellow: 2, MinBlue: 0}, // Swift Starfire Diamond
+	28556: {MinRed: 1, MinYellow: 2, MinBlue: 0}, // Swift Windfire Diamond
+	25898: {MinRed: 0, MinYellow: 0, MinBlue: 5}, // Tenacious Earthstorm Diamond
+	32410: {MinRed: 2, MinYellow: 2, MinBlue: 2}, // Thundering Skyfire Diamond
+
+	25897: {CompareGreater: proto.GemColor_GemColorRed, CompareLesser: proto.GemColor_GemColorBlue},    // Bracing Earthstorm Diamond
+	25895: {CompareGreater: proto.GemColor_GemColorRed, CompareLesser: proto.GemColor_GemColorYellow},  // Enigmatic Skyfire Diamond
+	25893: {CompareGreater: proto.GemColor_GemColorBlue, CompareLesser: proto.GemColor_GemColorYellow}, // Mystical Skyfire Diamond
+	32640: {CompareGreater: proto.GemColor_GemColorBlue, CompareLesser: proto.GemColor_GemColorYellow}, // Potent Unstable Diamond
+}
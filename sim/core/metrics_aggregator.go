@@ -2,6 +2,7 @@ package core
 
 import (
 	"math"
+	"slices"
 	"time"
 
 	"github.com/wowsims/wotlk/sim/core/proto"
@@ -22,8 +23,16 @@ type DistributionMetrics struct {
 	min     float64
 	maxSeed int64
 	minSeed int64
+	maxIter int32
+	minIter int32
 	hist    map[int32]int32 // rounded DPS to count
 	sample  []float64
+	batches convergenceBatches
+
+	// Sums for a linear fit of the per-iteration values against fight length.
+	durationSum        float64
+	durationSumSq      float64
+	durationProductSum float64
 }
 
 func (distMetrics *DistributionMetrics) reset() {
@@ -35,39 +44,129 @@ func (distMetrics *DistributionMetrics) doneIteration(sim *Simulation) {
 	dps := distMetrics.Total / sim.Duration.Seconds()
 	distMetrics.add(dps)
 
-	if sim.Options.SaveAllValues {
-		if cap(distMetrics.sample) < int(sim.Options.Iterations) {
-			distMetrics.sample = make([]float64, 0, sim.Options.Iterations)
+	duration := sim.Duration.Seconds()
+	distMetrics.durationSum += duration
+	distMetrics.durationSumSq += duration * duration
+	distMetrics.durationProductSum += duration * dps
+
+	if retained := sim.retainedSamples(); len(distMetrics.sample) < retained {
+		if cap(distMetrics.sample) < retained {
+			distMetrics.sample = make([]float64, 0, retained)
 		}
 		distMetrics.sample = append(distMetrics.sample, dps)
 	}
+	if sim.convergenceBatchSize > 0 {
+		distMetrics.batches.add(sim.convergenceBatchSize, sim.iteration, dps)
+	}
 
 	if dps > distMetrics.max {
 		distMetrics.max = dps
 		distMetrics.maxSeed = sim.rand.GetSeed()
+		distMetrics.maxIter = sim.iteration
 	}
 	if dps <= distMetrics.min || distMetrics.min < 0 {
 		distMetrics.min = dps
 		distMetrics.minSeed = sim.rand.GetSeed()
+		distMetrics.minIter = sim.iteration
 	}
 
 	dpsRounded := int32(math.Round(dps/10) * 10)
 	distMetrics.hist[dpsRounded]++
 }
 
+// Adds the aggregate values of other, for sims which are run in shards. other
+// should hold the later iterations, so that all_values stays in order.
+func (distMetrics *DistributionMetrics) merge(other *DistributionMetrics) {
+	if other.n == 0 {
+		return
+	}
+
+	if other.max > distMetrics.max || distMetrics.n == 0 {
+		distMetrics.max = other.max
+		distMetrics.maxSeed = other.maxSeed
+		distMetrics.maxIter = other.maxIter
+	}
+	if other.min < distMetrics.min || distMetrics.n == 0 {
+		distMetrics.min = other.min
+		distMetrics.minSeed = other.minSeed
+		distMetrics.minIter = other.minIter
+	}
+
+	distMetrics.aggregator = *distMetrics.aggregator.merge(&other.aggregator)
+	for bucket, count := range other.hist {
+		distMetrics.hist[bucket] += count
+	}
+	distMetrics.sample = append(distMetrics.sample, other.sample...)
+	distMetrics.batches.merge(&other.batches)
+	distMetrics.durationSum += other.durationSum
+	distMetrics.durationSumSq += other.durationSumSq
+	distMetrics.durationProductSum += other.durationProductSum
+}
+
+// Returns the least squares slope of the per-iteration values against fight
+// length in seconds, or 0 if every iteration was the same length.
+func (distMetrics *DistributionMetrics) durationSlope() float64 {
+	n := float64(distMetrics.n)
+	variance := n*distMetrics.durationSumSq - distMetrics.durationSum*distMetrics.durationSum
+	if variance <= 1e-9*n*distMetrics.durationSumSq {
+		return 0
+	}
+	return (n*distMetrics.durationProductSum - distMetrics.durationSum*distMetrics.sum) / variance
+}
+
 func (distMetrics *DistributionMetrics) ToProto() *proto.DistributionMetrics {
 	mean, stdev := distMetrics.meanAndStdDev()
+	percentiles := distMetrics.percentiles(0.05, 0.25, 0.5, 0.75, 0.95)
+
+	var convergence *proto.ConvergenceDiagnostics
+	if len(distMetrics.batches.counts) > 0 {
+		convergence = distMetrics.batches.ToProto(stdev)
+	}
 
 	return &proto.DistributionMetrics{
-		Avg:       mean,
-		Stdev:     stdev,
-		Max:       distMetrics.max,
-		Min:       distMetrics.min,
-		MaxSeed:   distMetrics.maxSeed,
-		MinSeed:   distMetrics.minSeed,
-		Hist:      distMetrics.hist,
-		AllValues: distMetrics.sample,
+		Avg:           mean,
+		Stdev:         stdev,
+		Max:           distMetrics.max,
+		Min:           distMetrics.min,
+		MaxSeed:       distMetrics.maxSeed,
+		MinSeed:       distMetrics.minSeed,
+		MaxIteration:  distMetrics.maxIter,
+		MinIteration:  distMetrics.minIter,
+		Hist:          distMetrics.hist,
+		AllValues:     distMetrics.sample,
+		P5:            percentiles[0],
+		P25:           percentiles[1],
+		P50:           percentiles[2],
+		P75:           percentiles[3],
+		P95:           percentiles[4],
+		Convergence:   convergence,
+		DurationSlope: distMetrics.durationSlope(),
+	}
+}
+
+// Estimates the given percentiles (as fractions, in increasing order) from
+// the histogram, so they're only accurate to the histogram's bucket size.
+func (distMetrics *DistributionMetrics) percentiles(fractions ...float64) []float64 {
+	results := make([]float64, len(fractions))
+	if distMetrics.n == 0 {
+		return results
+	}
+
+	buckets := make([]int32, 0, len(distMetrics.hist))
+	for bucket := range distMetrics.hist {
+		buckets = append(buckets, bucket)
+	}
+	slices.Sort(buckets)
+
+	count := int32(0)
+	i := 0
+	for _, bucket := range buckets {
+		count += distMetrics.hist[bucket]
+		for ; i < len(fractions) && float64(count) >= fractions[i]*float64(distMetrics.n); i++ {
+			results[i] = min(max(float64(bucket), distMetrics.min), distMetrics.max)
+		}
 	}
+	return results
 }
 
 func NewDistributionMetrics() DistributionMetrics {
@@ -86,10 +185,42 @@ type UnitMetrics struct {
 	hps    DistributionMetrics
 	tto    DistributionMetrics
 
+	// Damage dealt while the targets are in execute range (<= 35%), only
+	// recorded for iterations which reach it.
+	executeDps DistributionMetrics
+
+	// Only set for players when the encounter has downtime windows.
+	activeDps *DistributionMetrics
+
+	// Only set for players and pets when the encounter has add waves.
+	damageSplit *damageSplitMetrics
+
+	// Only set for targets with a required DPS.
+	dpsRequirement *dpsRequirementMetrics
+
 	tmiList   []tmiListItem
 	isTanking bool
 	tmiBin    int32
 
+	// Used instead of tmiList in low memory mode, see addTMIDamage().
+	tmiSeconds []float64
+	tmiEvents  bool
+
+	tank       *tankMetrics
+	deaths     deathMetrics
+	threatRace *threatRaceMetrics
+	tankSwap   *tankSwapMetrics
+	resists    *resistMetrics
+	timeline   *unitTimeline
+
+	castEfficiency    *castEfficiencyMetrics
+	dots              []*dotMetrics
+	internalCooldowns []*internalCooldownMetrics
+	exclusiveEffects  []*exclusiveEffectMetrics
+	resourceTimelines []*resourceTimeline
+	runes             *runeMetrics
+	energyTicks       *energyTickMetrics
+
 	CharacterIterationMetrics
 
 	// Aggregate values. These are updated after each iteration.
@@ -97,6 +228,8 @@ type UnitMetrics struct {
 	oomTimeSum   float64
 	actions      map[ActionID]*ActionMetrics
 	resources    []*ResourceMetrics
+	damageTaken  map[damageTakenKey]*damageTakenMetrics
+	cooldowns    map[ActionID]*cooldownMetrics
 }
 
 // Metrics for the current iteration, for 1 agent. Keep this as a separate
@@ -105,6 +238,9 @@ type CharacterIterationMetrics struct {
 	Died    bool // Whether this unit died in the current iteration.
 	WentOOM bool // Whether the agent has hit OOM at least once in this iteration.
 
+	Deaths   int32         // Number of times this unit died in the current iteration.
+	TimeDead time.Duration // Total time spent dead in the current iteration.
+
 	ManaSpent  float64
 	ManaGained float64
 
@@ -116,8 +252,22 @@ type CharacterIterationMetrics struct {
 type ActionMetrics struct {
 	IsMelee bool // True if melee action, false if spell action.
 
+	RankOf ActionID // The max rank's ID, for lower ranks of a spell.
+
 	// Metrics for this action, for each possible target.
 	Targets []TargetedActionMetrics
+
+	// Only set for chain spells, indexed by bounce.
+	Bounces []ChainBounceMetrics
+}
+
+func (actionMetrics *ActionMetrics) addChainBounces(bounces []ChainBounceMetrics) {
+	if len(actionMetrics.Bounces) < len(bounces) {
+		actionMetrics.Bounces = append(actionMetrics.Bounces, make([]ChainBounceMetrics, len(bounces)-len(actionMetrics.Bounces))...)
+	}
+	for i := range bounces {
+		actionMetrics.Bounces[i].merge(&bounces[i])
+	}
 }
 
 type tmiListItem struct {
@@ -131,11 +281,25 @@ func (actionMetrics *ActionMetrics) ToProto(actionID ActionID) *proto.ActionMetr
 		targetMetrics = append(targetMetrics, tam.ToProto())
 	}
 
-	return &proto.ActionMetrics{
+	var bounceMetrics []*proto.ChainBounceMetrics
+	for _, bounce := range actionMetrics.Bounces {
+		bounceMetrics = append(bounceMetrics, &proto.ChainBounceMetrics{
+			Hits:    bounce.Hits,
+			Damage:  bounce.Damage,
+			Healing: bounce.Healing,
+		})
+	}
+
+	protoMetrics := &proto.ActionMetrics{
 		Id:      actionID.ToProto(),
 		IsMelee: actionMetrics.IsMelee,
 		Targets: targetMetrics,
+		Bounces: bounceMetrics,
 	}
+	if !actionMetrics.RankOf.IsEmptyAction() {
+		protoMetrics.RankOf = actionMetrics.RankOf.ToProto()
+	}
+	return protoMetrics
 }
 
 // Metric totals for a spell against a specific target, for the current iteration.
@@ -150,6 +314,9 @@ type SpellMetrics struct {
 	Parries int32
 	Blocks  int32
 
+	BlockedDamage  float64 // Damage prevented by the target's block value.
+	CriticalBlocks int32   // Blocks which blocked double, see Character.RegisterCriticalBlock.
+
 	// Partial or full resists aren't tracked, at the moment, cp. applyResistances()
 
 	TotalDamage    float64 // Damage done by all casts of this spell.
@@ -157,6 +324,20 @@ type SpellMetrics struct {
 	TotalHealing   float64 // Healing done by all casts of this spell.
 	TotalShielding float64 // Shielding done by all casts of this spell.
 	TotalCastTime  time.Duration
+
+	DamageBuckets []float64 // Damage done in each SimOptions.damage_buckets bucket.
+}
+
+// Adds damage to the bucket for the current time, with SimOptions.damage_buckets.
+func (spellMetrics *SpellMetrics) addBucketDamage(sim *Simulation, damage float64) {
+	if sim.damageBucketsFirstOnly && sim.iteration != 0 {
+		return
+	}
+	bucket := max(int(sim.CurrentTime/sim.damageBucketWidth), 0)
+	for len(spellMetrics.DamageBuckets) <= bucket {
+		spellMetrics.DamageBuckets = append(spellMetrics.DamageBuckets, 0)
+	}
+	spellMetrics.DamageBuckets[bucket] += damage
 }
 
 type TargetedActionMetrics struct {
@@ -171,11 +352,45 @@ type TargetedActionMetrics struct {
 	Blocks  int32
 	Glances int32
 
+	BlockedDamage  float64
+	CriticalBlocks int32
+
 	Damage    float64
 	Threat    float64
 	Healing   float64
 	Shielding float64
 	CastTime  time.Duration
+
+	DamageBuckets []float64
+}
+
+func (tam *TargetedActionMetrics) addDamageBuckets(damageBuckets []float64) {
+	for len(tam.DamageBuckets) < len(damageBuckets) {
+		tam.DamageBuckets = append(tam.DamageBuckets, 0)
+	}
+	for i, damage := range damageBuckets {
+		tam.DamageBuckets[i] += damage
+	}
+}
+
+func (tam *TargetedActionMetrics) merge(other *TargetedActionMetrics) {
+	tam.UnitIndex = other.UnitIndex
+	tam.Casts += other.Casts
+	tam.Hits += other.Hits
+	tam.Crits += other.Crits
+	tam.Misses += other.Misses
+	tam.Dodges += other.Dodges
+	tam.Parries += other.Parries
+	tam.Blocks += other.Blocks
+	tam.Glances += other.Glances
+	tam.BlockedDamage += other.BlockedDamage
+	tam.CriticalBlocks += other.CriticalBlocks
+	tam.Damage += other.Damage
+	tam.Threat += other.Threat
+	tam.Healing += other.Healing
+	tam.Shielding += other.Shielding
+	tam.CastTime += other.CastTime
+	tam.addDamageBuckets(other.DamageBuckets)
 }
 
 func (tam *TargetedActionMetrics) ToProto() *proto.TargetedActionMetrics {
@@ -195,22 +410,131 @@ func (tam *TargetedActionMetrics) ToProto() *proto.TargetedActionMetrics {
 		Healing:    tam.Healing,
 		Shielding:  tam.Shielding,
 		CastTimeMs: float64(tam.CastTime.Milliseconds()),
+
+		BlockedDamage:  tam.BlockedDamage,
+		CriticalBlocks: tam.CriticalBlocks,
+
+		DamageBuckets: tam.DamageBuckets,
 	}
 }
 
 func NewUnitMetrics() UnitMetrics {
 	return UnitMetrics{
-		dps:     NewDistributionMetrics(),
-		dpasp:   NewDistributionMetrics(),
-		threat:  NewDistributionMetrics(),
-		dtps:    NewDistributionMetrics(),
-		tmi:     NewDistributionMetrics(),
-		hps:     NewDistributionMetrics(),
-		tto:     NewDistributionMetrics(),
-		actions: make(map[ActionID]*ActionMetrics),
+		dps:         NewDistributionMetrics(),
+		dpasp:       NewDistributionMetrics(),
+		threat:      NewDistributionMetrics(),
+		dtps:        NewDistributionMetrics(),
+		tmi:         NewDistributionMetrics(),
+		hps:         NewDistributionMetrics(),
+		tto:         NewDistributionMetrics(),
+		executeDps:  NewDistributionMetrics(),
+		actions:     make(map[ActionID]*ActionMetrics),
+		damageTaken: make(map[damageTakenKey]*damageTakenMetrics),
+		cooldowns:   make(map[ActionID]*cooldownMetrics),
+	}
+}
+
+type damageTakenKey struct {
+	ActionID        ActionID
+	SourceUnitIndex int32
+}
+
+// Totals for damage taken from a single ability of a single attacker, over all
+// iterations.
+type damageTakenMetrics struct {
+	damage  float64
+	hits    int32 // Landed hits, including ticks, crits, crushes, glances and blocks.
+	crits   int32
+	crushes int32
+	blocks  int32
+	misses  int32
+	dodges  int32
+	parries int32
+
+	blockedDamage  float64
+	criticalBlocks int32
+}
+
+func (dtm *damageTakenMetrics) add(spellMetrics *SpellMetrics) {
+	dtm.damage += spellMetrics.TotalDamage
+	dtm.hits += spellMetrics.Hits + spellMetrics.Crits + spellMetrics.Crushes + spellMetrics.Glances + spellMetrics.Blocks
+	dtm.crits += spellMetrics.Crits
+	dtm.crushes += spellMetrics.Crushes
+	dtm.blocks += spellMetrics.Blocks
+	dtm.misses += spellMetrics.Misses
+	dtm.dodges += spellMetrics.Dodges
+	dtm.parries += spellMetrics.Parries
+	dtm.blockedDamage += spellMetrics.BlockedDamage
+	dtm.criticalBlocks += spellMetrics.CriticalBlocks
+}
+
+func (dtm *damageTakenMetrics) merge(other *damageTakenMetrics) {
+	dtm.damage += other.damage
+	dtm.hits += other.hits
+	dtm.crits += other.crits
+	dtm.crushes += other.crushes
+	dtm.blocks += other.blocks
+	dtm.misses += other.misses
+	dtm.dodges += other.dodges
+	dtm.parries += other.parries
+	dtm.blockedDamage += other.blockedDamage
+	dtm.criticalBlocks += other.criticalBlocks
+}
+
+func (dtm *damageTakenMetrics) ToProto(key damageTakenKey, n float64) *proto.DamageTakenMetrics {
+	return &proto.DamageTakenMetrics{
+		Id:              key.ActionID.ToProto(),
+		SourceUnitIndex: key.SourceUnitIndex,
+		DamageAvg:       dtm.damage / n,
+		HitsAvg:         float64(dtm.hits) / n,
+		CritsAvg:        float64(dtm.crits) / n,
+		CrushesAvg:      float64(dtm.crushes) / n,
+		BlocksAvg:       float64(dtm.blocks) / n,
+		MissesAvg:       float64(dtm.misses) / n,
+		DodgesAvg:       float64(dtm.dodges) / n,
+		ParriesAvg:      float64(dtm.parries) / n,
+
+		BlockedDamageAvg:  dtm.blockedDamage / n,
+		CriticalBlocksAvg: float64(dtm.criticalBlocks) / n,
+	}
+}
+
+func (unitMetrics *UnitMetrics) getDamageTaken(key damageTakenKey) *damageTakenMetrics {
+	dtm, ok := unitMetrics.damageTaken[key]
+	if !ok {
+		dtm = &damageTakenMetrics{}
+		unitMetrics.damageTaken[key] = dtm
+	}
+	return dtm
+}
+
+type cooldownMetrics struct {
+	events int32
+	saved  time.Duration
+}
+
+func (cdm *cooldownMetrics) merge(other *cooldownMetrics) {
+	cdm.events += other.events
+	cdm.saved += other.saved
+}
+
+func (cdm *cooldownMetrics) ToProto(source ActionID, n float64) *proto.CooldownMetrics {
+	return &proto.CooldownMetrics{
+		Id:              source.ToProto(),
+		EventsAvg:       float64(cdm.events) / n,
+		SecondsSavedAvg: cdm.saved.Seconds() / n,
 	}
 }
 
+func (unitMetrics *UnitMetrics) getCooldown(source ActionID) *cooldownMetrics {
+	cdm, ok := unitMetrics.cooldowns[source]
+	if !ok {
+		cdm = &cooldownMetrics{}
+		unitMetrics.cooldowns[source] = cdm
+	}
+	return cdm
+}
+
 type ResourceMetrics struct {
 	ActionID ActionID
 	Type     proto.ResourceType
@@ -234,6 +558,12 @@ func (resourceMetrics *ResourceMetrics) ToProto() *proto.ResourceMetrics {
 	}
 }
 
+func (resourceMetrics *ResourceMetrics) merge(other *ResourceMetrics) {
+	resourceMetrics.Events += other.Events
+	resourceMetrics.Gain += other.Gain
+	resourceMetrics.ActualGain += other.ActualGain
+}
+
 func (resourceMetrics *ResourceMetrics) reset() {
 	resourceMetrics.EventsFromPreviousIterations = resourceMetrics.Events
 	resourceMetrics.ActualGainFromPreviousIterations = resourceMetrics.ActualGain
@@ -301,6 +631,9 @@ func (unitMetrics *UnitMetrics) addSpellMetrics(spell *Spell, actionID ActionID,
 
 	if !ok {
 		actionMetrics = &ActionMetrics{IsMelee: spell.Flags.Matches(SpellFlagMeleeMetrics)}
+		if spell.RankOf != nil {
+			actionMetrics.RankOf = spell.RankOf.ActionID
+		}
 		unitMetrics.actions[actionID] = actionMetrics
 	}
 
@@ -322,11 +655,14 @@ func (unitMetrics *UnitMetrics) addSpellMetrics(spell *Spell, actionID ActionID,
 		tam.Parries += spellTargetMetrics.Parries
 		tam.Blocks += spellTargetMetrics.Blocks
 		tam.Glances += spellTargetMetrics.Glances
+		tam.BlockedDamage += spellTargetMetrics.BlockedDamage
+		tam.CriticalBlocks += spellTargetMetrics.CriticalBlocks
 		tam.Damage += spellTargetMetrics.TotalDamage
 		tam.Threat += spellTargetMetrics.TotalThreat
 		tam.Healing += spellTargetMetrics.TotalHealing
 		tam.Shielding += spellTargetMetrics.TotalShielding
 		tam.CastTime += spellTargetMetrics.TotalCastTime
+		tam.addDamageBuckets(spellTargetMetrics.DamageBuckets)
 
 		target := spell.Unit.AttackTables[i].Defender
 		target.Metrics.dtps.Total += spellTargetMetrics.TotalDamage
@@ -334,6 +670,13 @@ func (unitMetrics *UnitMetrics) addSpellMetrics(spell *Spell, actionID ActionID,
 		if spell.Unit.IsOpponent(target) {
 			unitMetrics.dps.Total += spellTargetMetrics.TotalDamage
 			unitMetrics.threat.Total += spellTargetMetrics.TotalThreat
+			if unitMetrics.damageSplit != nil {
+				unitMetrics.damageSplit.add(target, spellTargetMetrics.TotalDamage)
+			}
+
+			if target.Type != EnemyUnit && (spellTargetMetrics.TotalDamage > 0 || spellTargetMetrics.Misses+spellTargetMetrics.Dodges+spellTargetMetrics.Parries > 0) {
+				target.Metrics.getDamageTaken(damageTakenKey{actionID, spell.Unit.UnitIndex}).add(&spellTargetMetrics)
+			}
 		} else {
 			unitMetrics.hps.Total += spellTargetMetrics.TotalHealing + spellTargetMetrics.TotalShielding
 		}
@@ -342,9 +685,15 @@ func (unitMetrics *UnitMetrics) addSpellMetrics(spell *Spell, actionID ActionID,
 
 // This should be called at the end of each iteration, to include metrics from Pets in
 // those of their owner.
-// Assumes that doneIteration() has already been called on the pet metrics.
+// Assumes that doneIteration() has already been called on the pet metrics,
+// which leaves their totals unscaled.
 func (unitMetrics *UnitMetrics) AddFinalPetMetrics(petMetrics *UnitMetrics) {
 	unitMetrics.dps.Total += petMetrics.dps.Total
+	unitMetrics.executeDps.Total += petMetrics.executeDps.Total
+	if unitMetrics.damageSplit != nil && petMetrics.damageSplit != nil {
+		unitMetrics.damageSplit.priorityDamage += petMetrics.damageSplit.priorityDamage
+		unitMetrics.damageSplit.aoeDamage += petMetrics.damageSplit.aoeDamage
+	}
 }
 
 func (unitMetrics *UnitMetrics) AddOOMTime(sim *Simulation, dur time.Duration) {
@@ -370,8 +719,47 @@ func (unitMetrics *UnitMetrics) reset() {
 	unitMetrics.dtps.reset()
 	unitMetrics.tmi.reset()
 	unitMetrics.tmiList = nil
+	unitMetrics.tmiSeconds = unitMetrics.tmiSeconds[:0]
+	unitMetrics.tmiEvents = false
 	unitMetrics.hps.reset()
 	unitMetrics.tto.reset()
+	unitMetrics.executeDps.reset()
+	if unitMetrics.activeDps != nil {
+		unitMetrics.activeDps.reset()
+	}
+	if unitMetrics.damageSplit != nil {
+		unitMetrics.damageSplit.reset()
+	}
+	if unitMetrics.dpsRequirement != nil {
+		unitMetrics.dpsRequirement.reset()
+	}
+	if unitMetrics.tank != nil {
+		unitMetrics.tank.reset()
+	}
+	if unitMetrics.threatRace != nil {
+		unitMetrics.threatRace.reset()
+	}
+	if unitMetrics.timeline != nil {
+		unitMetrics.timeline.reset()
+	}
+	if unitMetrics.castEfficiency != nil {
+		unitMetrics.castEfficiency.reset()
+	}
+	for _, dm := range unitMetrics.dots {
+		dm.reset()
+	}
+	for _, icdm := range unitMetrics.internalCooldowns {
+		icdm.reset()
+	}
+	for _, rt := range unitMetrics.resourceTimelines {
+		rt.reset()
+	}
+	if unitMetrics.runes != nil {
+		unitMetrics.runes.reset()
+	}
+	if unitMetrics.energyTicks != nil {
+		unitMetrics.energyTicks.reset()
+	}
 	unitMetrics.CharacterIterationMetrics = CharacterIterationMetrics{}
 
 	for _, resourceMetrics := range unitMetrics.resources {
@@ -403,12 +791,49 @@ func (unitMetrics *UnitMetrics) doneIteration(unit *Unit, sim *Simulation) {
 	}
 
 	if unitMetrics.isTanking {
-		unitMetrics.tmi.Total = unitMetrics.calculateTMI(unit, sim)
+		if sim.lowMemory {
+			unitMetrics.tmi.Total = unitMetrics.calculateStreamingTMI(sim)
+		} else {
+			unitMetrics.tmi.Total = unitMetrics.calculateTMI(unit, sim)
+		}
 
 		// Hack because of the way DistributionMetrics does its calculations.
 		unitMetrics.tmi.Total *= sim.Duration.Seconds()
 	}
 
+	if unitMetrics.tank != nil {
+		unitMetrics.tank.doneIteration(unitMetrics, sim)
+	}
+	if unitMetrics.threatRace != nil {
+		unitMetrics.threatRace.doneIteration(unit)
+	}
+	if unitMetrics.timeline != nil {
+		unitMetrics.timeline.doneIteration(sim)
+	}
+	if unitMetrics.castEfficiency != nil {
+		unitMetrics.castEfficiency.doneIteration(sim)
+	}
+	for _, dm := range unitMetrics.dots {
+		dm.doneIteration(sim)
+	}
+	for _, icdm := range unitMetrics.internalCooldowns {
+		icdm.doneIteration()
+	}
+	for _, eem := range unitMetrics.exclusiveEffects {
+		eem.doneIteration(sim)
+	}
+	for _, rt := range unitMetrics.resourceTimelines {
+		rt.doneIteration(sim)
+	}
+	if unitMetrics.runes != nil {
+		unitMetrics.runes.doneIteration(sim, &unit.runicPowerBar)
+	}
+	if unitMetrics.energyTicks != nil {
+		unitMetrics.energyTicks.doneIteration()
+	}
+
+	unitMetrics.deaths.doneIteration(unitMetrics, unitMetrics.dps.Total/sim.Duration.Seconds())
+
 	unitMetrics.dps.doneIteration(sim)
 	unitMetrics.dpasp.doneIteration(sim)
 	unitMetrics.threat.doneIteration(sim)
@@ -417,6 +842,29 @@ func (unitMetrics *UnitMetrics) doneIteration(unit *Unit, sim *Simulation) {
 	unitMetrics.hps.doneIteration(sim)
 	unitMetrics.tto.doneIteration(sim)
 
+	if sim.execute35Start < sim.Duration {
+		// Hack because of the way DistributionMetrics does its calculations.
+		// The unscaled damage is kept, so that owners scale their pets' execute
+		// damage only once.
+		executeDamage := unitMetrics.executeDps.Total
+		unitMetrics.executeDps.Total *= sim.Duration.Seconds() / (sim.Duration - max(sim.execute35Start, 0)).Seconds()
+		unitMetrics.executeDps.doneIteration(sim)
+		unitMetrics.executeDps.Total = executeDamage
+	}
+	if unitMetrics.activeDps != nil {
+		if activeTime := sim.Encounter.activeTime(sim.Duration); activeTime > 0 {
+			// Same hack as for execute DPS.
+			unitMetrics.activeDps.Total = unitMetrics.dps.Total * sim.Duration.Seconds() / activeTime.Seconds()
+			unitMetrics.activeDps.doneIteration(sim)
+		}
+	}
+	if unitMetrics.damageSplit != nil {
+		unitMetrics.damageSplit.doneIteration(sim)
+	}
+	if unitMetrics.dpsRequirement != nil {
+		unitMetrics.dpsRequirement.doneIteration(sim)
+	}
+
 	unitMetrics.oomTimeSum += unitMetrics.OOMTime.Seconds()
 	if unitMetrics.Died {
 		unitMetrics.numItersDead++
@@ -495,6 +943,111 @@ func (unitMetrics *UnitMetrics) calculateTMI(unit *Unit, sim *Simulation) float6
 
 }
 
+// Adds the aggregate values of other, which must belong to the same unit of an
+// identically constructed Environment.
+func (unitMetrics *UnitMetrics) merge(other *UnitMetrics) {
+	unitMetrics.dps.merge(&other.dps)
+	unitMetrics.dpasp.merge(&other.dpasp)
+	unitMetrics.threat.merge(&other.threat)
+	unitMetrics.dtps.merge(&other.dtps)
+	unitMetrics.tmi.merge(&other.tmi)
+	unitMetrics.hps.merge(&other.hps)
+	unitMetrics.tto.merge(&other.tto)
+	unitMetrics.executeDps.merge(&other.executeDps)
+	if unitMetrics.activeDps != nil {
+		unitMetrics.activeDps.merge(other.activeDps)
+	}
+	if unitMetrics.damageSplit != nil {
+		unitMetrics.damageSplit.merge(other.damageSplit)
+	}
+	if unitMetrics.dpsRequirement != nil {
+		unitMetrics.dpsRequirement.merge(other.dpsRequirement)
+	}
+
+	if unitMetrics.tank != nil {
+		unitMetrics.tank.merge(other.tank)
+	}
+	if unitMetrics.threatRace != nil {
+		unitMetrics.threatRace.merge(other.threatRace)
+	}
+	if unitMetrics.tankSwap != nil {
+		unitMetrics.tankSwap.merge(other.tankSwap)
+	}
+	if unitMetrics.resists != nil {
+		unitMetrics.resists.merge(other.resists)
+	}
+	if unitMetrics.castEfficiency != nil {
+		unitMetrics.castEfficiency.merge(other.castEfficiency)
+	}
+	for i, dm := range unitMetrics.dots {
+		dm.merge(other.dots[i])
+	}
+	for i, icdm := range unitMetrics.internalCooldowns {
+		icdm.merge(other.internalCooldowns[i])
+	}
+	for i, eem := range unitMetrics.exclusiveEffects {
+		eem.merge(other.exclusiveEffects[i])
+	}
+	unitMetrics.deaths.merge(&other.deaths)
+	for i, rt := range unitMetrics.resourceTimelines {
+		rt.merge(other.resourceTimelines[i])
+	}
+	if unitMetrics.runes != nil {
+		unitMetrics.runes.merge(other.runes)
+	}
+	if unitMetrics.energyTicks != nil {
+		unitMetrics.energyTicks.merge(other.energyTicks)
+	}
+	// Timelines only record the first iterations, which are all in the first
+	// shard, so they don't need merging.
+
+	unitMetrics.numItersDead += other.numItersDead
+	unitMetrics.oomTimeSum += other.oomTimeSum
+
+	for actionID, otherAction := range other.actions {
+		action, ok := unitMetrics.actions[actionID]
+		if !ok {
+			action = &ActionMetrics{
+				IsMelee: otherAction.IsMelee,
+				RankOf:  otherAction.RankOf,
+				Targets: make([]TargetedActionMetrics, len(otherAction.Targets)),
+			}
+			unitMetrics.actions[actionID] = action
+		}
+		if len(action.Targets) == 0 {
+			action.Targets = make([]TargetedActionMetrics, len(otherAction.Targets))
+		}
+		for i := range otherAction.Targets {
+			action.Targets[i].merge(&otherAction.Targets[i])
+		}
+		action.addChainBounces(otherAction.Bounces)
+	}
+
+	// Some resource metrics are only created once they're first needed, so
+	// they're matched by key rather than by index.
+	resourcesByKey := make(map[ResourceKey][]*ResourceMetrics)
+	for _, resource := range unitMetrics.resources {
+		key := ResourceKey{resource.ActionID, resource.Type}
+		resourcesByKey[key] = append(resourcesByKey[key], resource)
+	}
+	for _, otherResource := range other.resources {
+		key := ResourceKey{otherResource.ActionID, otherResource.Type}
+		if matches := resourcesByKey[key]; len(matches) > 0 {
+			matches[0].merge(otherResource)
+			resourcesByKey[key] = matches[1:]
+		} else {
+			unitMetrics.NewResourceMetrics(otherResource.ActionID, otherResource.Type).merge(otherResource)
+		}
+	}
+
+	for key, otherDtm := range other.damageTaken {
+		unitMetrics.getDamageTaken(key).merge(otherDtm)
+	}
+	for source, otherCdm := range other.cooldowns {
+		unitMetrics.getCooldown(source).merge(otherCdm)
+	}
+}
+
 func (unitMetrics *UnitMetrics) ToProto() *proto.UnitMetrics {
 	n := float64(unitMetrics.dps.n)
 	protoMetrics := &proto.UnitMetrics{
@@ -509,11 +1062,70 @@ func (unitMetrics *UnitMetrics) ToProto() *proto.UnitMetrics {
 		ChanceOfDeath: float64(unitMetrics.numItersDead) / n,
 	}
 
+	if unitMetrics.executeDps.n > 0 {
+		protoMetrics.ExecuteDps = unitMetrics.executeDps.ToProto()
+	}
+	if unitMetrics.activeDps != nil && unitMetrics.activeDps.n > 0 {
+		protoMetrics.ActiveDps = unitMetrics.activeDps.ToProto()
+	}
+	if unitMetrics.damageSplit != nil {
+		protoMetrics.DamageSplit = unitMetrics.damageSplit.ToProto(n)
+	}
+	if unitMetrics.dpsRequirement != nil {
+		protoMetrics.DpsRequirement = unitMetrics.dpsRequirement.ToProto(n)
+	}
+	if unitMetrics.tank != nil {
+		protoMetrics.Tank = unitMetrics.tank.ToProto()
+	}
+	if unitMetrics.threatRace != nil {
+		protoMetrics.ThreatRace = unitMetrics.threatRace.ToProto()
+	}
+	if unitMetrics.tankSwap != nil {
+		protoMetrics.TankSwap = unitMetrics.tankSwap.ToProto(n)
+	}
+	if unitMetrics.resists != nil {
+		protoMetrics.Resists = unitMetrics.resists.ToProto(n)
+	}
+	if unitMetrics.timeline != nil {
+		protoMetrics.Timeline = unitMetrics.timeline.ToProto()
+	}
+	if unitMetrics.deaths.deathsSum > 0 {
+		protoMetrics.Deaths = unitMetrics.deaths.ToProto()
+	}
+	if ce := unitMetrics.castEfficiency; ce != nil && ce.castsPerMinuteSum > 0 {
+		protoMetrics.CastEfficiency = ce.ToProto()
+	}
+	for _, dm := range unitMetrics.dots {
+		if dm.applicationsSum > 0 {
+			protoMetrics.Dots = append(protoMetrics.Dots, dm.ToProto())
+		}
+	}
+	for _, icdm := range unitMetrics.internalCooldowns {
+		if !icdm.actionID.IsEmptyAction() && (icdm.procsSum > 0 || icdm.suppressedSum > 0) {
+			protoMetrics.InternalCooldowns = append(protoMetrics.InternalCooldowns, icdm.ToProto())
+		}
+	}
+	for _, eem := range unitMetrics.exclusiveEffects {
+		if !eem.effect.Aura.ActionID.IsEmptyAction() && eem.wasApplied() {
+			protoMetrics.ExclusiveEffects = append(protoMetrics.ExclusiveEffects, eem.ToProto())
+		}
+	}
+
 	protoMetrics.Actions = make([]*proto.ActionMetrics, 0, len(unitMetrics.actions))
 	for actionID, action := range unitMetrics.actions {
 		protoMetrics.Actions = append(protoMetrics.Actions, action.ToProto(actionID))
 	}
 
+	for _, rt := range unitMetrics.resourceTimelines {
+		protoMetrics.ResourceTimelines = append(protoMetrics.ResourceTimelines, rt.ToProto(unitMetrics.resources))
+	}
+	if unitMetrics.runes != nil {
+		protoMetrics.Runes = unitMetrics.runes.ToProto()
+	}
+	if unitMetrics.energyTicks != nil {
+		protoMetrics.EnergyTicks = unitMetrics.energyTicks.ToProto()
+	}
+
 	protoMetrics.Resources = make([]*proto.ResourceMetrics, 0, len(unitMetrics.resources))
 	for _, resource := range unitMetrics.resources {
 		if resource.Events > 0 {
@@ -521,6 +1133,13 @@ func (unitMetrics *UnitMetrics) ToProto() *proto.UnitMetrics {
 		}
 	}
 
+	for key, dtm := range unitMetrics.damageTaken {
+		protoMetrics.DamageTaken = append(protoMetrics.DamageTaken, dtm.ToProto(key, n))
+	}
+	for source, cdm := range unitMetrics.cooldowns {
+		protoMetrics.Cooldowns = append(protoMetrics.Cooldowns, cdm.ToProto(source, n))
+	}
+
 	return protoMetrics
 }
 
@@ -528,33 +1147,68 @@ type AuraMetrics struct {
 	ID ActionID
 
 	// Metrics for the current iteration.
-	Uptime time.Duration
-	Procs  int32
+	Uptime        time.Duration
+	Procs         int32
+	Applications  int32
+	StackTime     time.Duration // Sum of stacks multiplied by how long they were active.
+	RefreshWasted time.Duration
 
 	// Aggregate values. These are updated after each iteration.
 	aggregator
-	procsSum int32
+	procsSum         int32
+	applicationsSum  int32
+	uptimePercentSum float64
+	stackSecondsSum  float64
+	refreshWastedSum float64
 }
 
 func (auraMetrics *AuraMetrics) reset() {
 	auraMetrics.Uptime = 0
 	auraMetrics.Procs = 0
+	auraMetrics.Applications = 0
+	auraMetrics.StackTime = 0
+	auraMetrics.RefreshWasted = 0
 }
 
 // This should be called when a Sim iteration is complete.
-func (auraMetrics *AuraMetrics) doneIteration() {
+func (auraMetrics *AuraMetrics) doneIteration(duration time.Duration) {
 	auraMetrics.add(auraMetrics.Uptime.Seconds())
 	auraMetrics.procsSum += auraMetrics.Procs
+	auraMetrics.applicationsSum += auraMetrics.Applications
+	if duration > 0 {
+		auraMetrics.uptimePercentSum += auraMetrics.Uptime.Seconds() / duration.Seconds() * 100
+	}
+	auraMetrics.stackSecondsSum += auraMetrics.StackTime.Seconds()
+	auraMetrics.refreshWastedSum += auraMetrics.RefreshWasted.Seconds()
+}
+
+func (auraMetrics *AuraMetrics) merge(other *AuraMetrics) {
+	auraMetrics.aggregator = *auraMetrics.aggregator.merge(&other.aggregator)
+	auraMetrics.procsSum += other.procsSum
+	auraMetrics.applicationsSum += other.applicationsSum
+	auraMetrics.uptimePercentSum += other.uptimePercentSum
+	auraMetrics.stackSecondsSum += other.stackSecondsSum
+	auraMetrics.refreshWastedSum += other.refreshWastedSum
 }
 
 func (auraMetrics *AuraMetrics) ToProto() *proto.AuraMetrics {
 	mean, stdev := auraMetrics.meanAndStdDev()
+	n := float64(auraMetrics.n)
+
+	var stacksAvg float64
+	if auraMetrics.sum > 0 {
+		stacksAvg = auraMetrics.stackSecondsSum / auraMetrics.sum
+	}
 
 	return &proto.AuraMetrics{
 		Id: auraMetrics.ID.ToProto(),
 
-		UptimeSecondsAvg:   mean,
-		UptimeSecondsStdev: stdev,
-		ProcsAvg:           float64(auraMetrics.procsSum) / float64(auraMetrics.n),
+		UptimeSecondsAvg:        mean,
+		UptimeSecondsStdev:      stdev,
+		UptimePercentAvg:        auraMetrics.uptimePercentSum / n,
+		ProcsAvg:                float64(auraMetrics.procsSum) / n,
+		ApplicationsAvg:         float64(auraMetrics.applicationsSum) / n,
+		StacksAvg:               stacksAvg,
+		RefreshWastedSecondsAvg: auraMetrics.refreshWastedSum / n,
 	}
 }
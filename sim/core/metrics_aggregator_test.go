@@ -0,0 +1,137 @@
+package core
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestDistributionPercentiles(t *testing.T) {
+	distMetrics := NewDistributionMetrics()
+	sim := &Simulation{Options: &proto.SimOptions{}, Duration: time.Second, rand: NewSplitMix(1)}
+
+	// 1000, 1010, ..., 1990 DPS.
+	for i := 0; i < 100; i++ {
+		sim.iteration = int32(i)
+		distMetrics.Total = 1000 + 10*float64(i)
+		distMetrics.doneIteration(sim)
+	}
+
+	expected := []float64{1040, 1240, 1490, 1740, 1940}
+	if actual := distMetrics.percentiles(0.05, 0.25, 0.5, 0.75, 0.95); !slices.Equal(actual, expected) {
+		t.Fatalf("Expected percentiles %v, got %v", expected, actual)
+	}
+	if distMetrics.minIter != 0 || distMetrics.maxIter != 99 {
+		t.Fatalf("Expected min/max iterations 0/99, got %d/%d", distMetrics.minIter, distMetrics.maxIter)
+	}
+}
+
+func TestDamageBuckets(t *testing.T) {
+	sim := &Simulation{damageBucketWidth: time.Second * 5, damageBucketsFirstOnly: true}
+
+	var tam TargetedActionMetrics
+	for iteration := int32(0); iteration < 2; iteration++ {
+		sim.iteration = iteration
+		spellMetrics := SpellMetrics{}
+
+		sim.CurrentTime = -time.Second // Prepull damage goes in the first bucket.
+		spellMetrics.addBucketDamage(sim, 100)
+		sim.CurrentTime = time.Second * 4
+		spellMetrics.addBucketDamage(sim, 200)
+		sim.CurrentTime = time.Second * 12
+		spellMetrics.addBucketDamage(sim, 300)
+
+		tam.addDamageBuckets(spellMetrics.DamageBuckets)
+	}
+
+	if expected := []float64{300, 0, 300}; !slices.Equal(tam.DamageBuckets, expected) {
+		t.Errorf("Expected buckets %v, got %v", expected, tam.DamageBuckets)
+	}
+}
+
+func TestDamageTakenBySource(t *testing.T) {
+	result := RunRaidSim(tankSimRequest(&proto.SimOptions{Iterations: 100, RandomSeed: 101, Concurrency: 2}))
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", result.ErrorResult)
+	}
+
+	tank := result.RaidMetrics.Parties[0].Players[0]
+	if tank.Tank == nil || len(tank.DamageTaken) == 0 {
+		t.Fatalf("Expected damage taken metrics for the tank")
+	}
+	byID := make(map[ActionID]*proto.DamageTakenMetrics)
+	for _, dtm := range tank.DamageTaken {
+		if dtm.SourceUnitIndex != 0 {
+			t.Fatalf("Expected all damage from the target, got source %d", dtm.SourceUnitIndex)
+		}
+		byID[ProtoToActionID(dtm.Id)] = dtm
+	}
+
+	for _, action := range result.EncounterMetrics.Targets[0].Actions {
+		for _, tam := range action.Targets {
+			if tam.UnitIndex != tank.UnitIndex || tam.Damage == 0 {
+				continue
+			}
+			dtm := byID[ProtoToActionID(action.Id)]
+			if dtm == nil {
+				t.Fatalf("Expected damage taken from %v", action.Id)
+			}
+			if !WithinToleranceFloat64(tam.Damage/100, dtm.DamageAvg, 1e-6) {
+				t.Errorf("Expected %f average damage from %v, got %f", tam.Damage/100, action.Id, dtm.DamageAvg)
+			}
+			if expected := float64(tam.Dodges) / 100; !WithinToleranceFloat64(expected, dtm.DodgesAvg, 1e-9) {
+				t.Errorf("Expected %f average dodges of %v, got %f", expected, action.Id, dtm.DodgesAvg)
+			}
+		}
+	}
+}
+
+func TestDistributionDurationSlope(t *testing.T) {
+	distMetrics := NewDistributionMetrics()
+	sim := &Simulation{Options: &proto.SimOptions{}, rand: NewSplitMix(1)}
+
+	// DPS goes up by 2 for each second of fight length.
+	for i := 0; i < 20; i++ {
+		seconds := 100 + float64(i)
+		sim.iteration = int32(i)
+		sim.Duration = DurationFromSeconds(seconds)
+		distMetrics.Total = (1000 + 2*seconds) * seconds
+		distMetrics.doneIteration(sim)
+	}
+	if slope := distMetrics.ToProto().DurationSlope; !WithinToleranceFloat64(2, slope, 1e-6) {
+		t.Fatalf("Expected a slope of 2 DPS per second, got %f", slope)
+	}
+
+	fixed := NewDistributionMetrics()
+	sim.Duration = time.Minute
+	for i := 0; i < 5; i++ {
+		fixed.Total = 1000 * float64(i+1)
+		fixed.doneIteration(sim)
+	}
+	if slope := fixed.ToProto().DurationSlope; slope != 0 {
+		t.Fatalf("Expected no slope for a fixed fight length, got %f", slope)
+	}
+}
+
+func TestExecuteDpsWithPet(t *testing.T) {
+	sim := &Simulation{Options: &proto.SimOptions{}, Duration: 100 * time.Second, execute35Start: 50 * time.Second, rand: NewSplitMix(1)}
+	owner := &Unit{Metrics: NewUnitMetrics()}
+	pet := &Unit{Metrics: NewUnitMetrics()}
+
+	// 100 execute DPS each, over the last 50 seconds.
+	owner.Metrics.executeDps.Total = 5000
+	pet.Metrics.executeDps.Total = 5000
+
+	pet.Metrics.doneIteration(pet, sim)
+	owner.Metrics.AddFinalPetMetrics(&pet.Metrics)
+	owner.Metrics.doneIteration(owner, sim)
+
+	if actual := pet.Metrics.executeDps.ToProto().Avg; actual != 100 {
+		t.Errorf("Expected 100 pet execute DPS, got %0.2f", actual)
+	}
+	if actual := owner.Metrics.executeDps.ToProto().Avg; actual != 200 {
+		t.Errorf("Expected 200 owner execute DPS, got %0.2f", actual)
+	}
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+type outlierIteration struct {
+	iteration int32
+	dps       float64
+}
+
+// Tracks the iterations with the highest and lowest raid DPS, see
+// SimOptions.outlier_iterations. Only indices are kept while the sim runs, and
+// the logs come from replaying them at the end.
+type outlierTracker struct {
+	count   int
+	highest []outlierIteration // Highest DPS first.
+	lowest  []outlierIteration // Lowest DPS first.
+}
+
+func newOutlierTracker(count int32) *outlierTracker {
+	return &outlierTracker{
+		count:   int(count),
+		highest: make([]outlierIteration, 0, count+1),
+		lowest:  make([]outlierIteration, 0, count+1),
+	}
+}
+
+func (ot *outlierTracker) add(outlier outlierIteration) {
+	ot.highest = insertOutlier(ot.highest, outlier, ot.count, func(a, b float64) bool { return a > b })
+	ot.lowest = insertOutlier(ot.lowest, outlier, ot.count, func(a, b float64) bool { return a < b })
+}
+
+// Adds the outliers of a worker which ran later iterations, see runConcurrent.
+func (ot *outlierTracker) merge(other *outlierTracker) {
+	for _, outlier := range other.highest {
+		ot.add(outlier)
+	}
+	for _, outlier := range other.lowest {
+		ot.add(outlier)
+	}
+}
+
+// Inserts outlier into list, sorted by better and capped at count entries. Ties
+// go to the earlier iteration, which is added first.
+func insertOutlier(list []outlierIteration, outlier outlierIteration, count int, better func(a, b float64) bool) []outlierIteration {
+	i := sort.Search(len(list), func(i int) bool { return better(outlier.dps, list[i].dps) })
+	if i >= count || slices.Contains(list, outlier) {
+		return list
+	}
+	list = slices.Insert(list, i, outlier)
+	if len(list) > count {
+		list = list[:count]
+	}
+	return list
+}
+
+// Replays the outlier iterations with debug logs. This has to run after the
+// metrics are read, since replays add to them like any other iteration.
+func (sim *Simulation) replayOutliers() (highest []*proto.OutlierIteration, lowest []*proto.OutlierIteration) {
+	tracker, log, eventLog := sim.outliers, sim.Log, sim.eventLog
+	sim.outliers = nil // Replays shouldn't count as outliers themselves.
+	defer func() {
+		sim.outliers, sim.Log, sim.eventLog = tracker, log, eventLog
+	}()
+
+	// With few iterations, some can be among both the highest and lowest.
+	logs := make(map[int32]string)
+	replay := func(outlier outlierIteration) *proto.OutlierIteration {
+		if _, ok := logs[outlier.iteration]; !ok {
+			replayLog := &EventLog{}
+			sim.startLogging(replayLog)
+			sim.reseedRands(int64(outlier.iteration))
+			sim.runOnce()
+			logs[outlier.iteration] = replayLog.Format(sim.Options.LogFormat)
+		}
+		return &proto.OutlierIteration{
+			Iteration: outlier.iteration,
+			Dps:       outlier.dps,
+			Logs:      logs[outlier.iteration],
+		}
+	}
+	return MapSlice(tracker.highest, replay), MapSlice(tracker.lowest, replay)
+}
+
+// Keeps the count highest or lowest iterations of all shards, see
+// mergeShardResults.
+func mergeOutliers(lists [][]*proto.OutlierIteration, count int32, highest bool) []*proto.OutlierIteration {
+	var merged []*proto.OutlierIteration
+	for _, list := range lists {
+		merged = append(merged, list...)
+	}
+	// Stable, so that ties still go to the earlier iteration.
+	sort.SliceStable(merged, func(i, j int) bool {
+		if highest {
+			return merged[i].Dps > merged[j].Dps
+		}
+		return merged[i].Dps < merged[j].Dps
+	})
+	if len(merged) > int(count) {
+		merged = merged[:count]
+	}
+	return merged
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestOutlierTracker(t *testing.T) {
+	iterations := func(outliers []outlierIteration) []int32 {
+		return MapSlice(outliers, func(outlier outlierIteration) int32 { return outlier.iteration })
+	}
+
+	first, second := newOutlierTracker(2), newOutlierTracker(2)
+	for i, dps := range []float64{5, 3, 8, 3} {
+		first.add(outlierIteration{iteration: int32(i), dps: dps})
+	}
+	for i, dps := range []float64{8, 1, 6} {
+		second.add(outlierIteration{iteration: int32(i + 4), dps: dps})
+	}
+	if highest, lowest := iterations(first.highest), iterations(first.lowest); !slices.Equal(highest, []int32{2, 0}) || !slices.Equal(lowest, []int32{1, 3}) {
+		t.Fatalf("Expected highest [2 0] and lowest [1 3], got %v and %v", highest, lowest)
+	}
+
+	first.merge(second)
+	if highest, lowest := iterations(first.highest), iterations(first.lowest); !slices.Equal(highest, []int32{2, 4}) || !slices.Equal(lowest, []int32{5, 1}) {
+		t.Fatalf("Expected highest [2 4] and lowest [5 1] after merging, got %v and %v", highest, lowest)
+	}
+}
+
+func TestOutlierIterationLogs(t *testing.T) {
+	full := RunRaidSim(tankSimRequest(&proto.SimOptions{Iterations: 4, RandomSeed: 101, Debug: true}))
+	if full.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", full.ErrorResult)
+	}
+	iterationLogs := splitIterationLogs(full.Logs)
+
+	result := RunRaidSim(tankSimRequest(&proto.SimOptions{Iterations: 4, RandomSeed: 101, OutlierIterations: 3}))
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", result.ErrorResult)
+	}
+	if len(result.HighestIterations) != 3 || len(result.LowestIterations) != 3 {
+		t.Fatalf("Expected 3 highest and lowest iterations, got %d and %d", len(result.HighestIterations), len(result.LowestIterations))
+	}
+	if result.Logs != "" {
+		t.Errorf("Expected no logs outside of the outliers")
+	}
+	for _, outlier := range append(result.HighestIterations, result.LowestIterations...) {
+		if actual := splitIterationLogs(outlier.Logs); len(actual) != 1 || actual[0] != iterationLogs[outlier.Iteration] {
+			t.Errorf("Logs of outlier iteration %d differ from the full sim.\nExpected:\n%s\nGot:\n%s", outlier.Iteration, iterationLogs[outlier.Iteration], outlier.Logs)
+		}
+	}
+}
+
+func TestConcurrentOutlierIterations(t *testing.T) {
+	request := func(concurrency int32) *proto.RaidSimRequest {
+		return tankSimRequest(&proto.SimOptions{Iterations: 200, RandomSeed: 101, OutlierIterations: 2, Concurrency: concurrency})
+	}
+
+	serial := RunRaidSim(request(1))
+	expected := append(serial.HighestIterations, serial.LowestIterations...)
+	for name, result := range map[string]*proto.RaidSimResult{
+		"concurrent":  RunRaidSim(request(4)),
+		"distributed": (&SimCluster{Workers: []SimShardWorker{&fakeShardWorker{}}}).RunRaidSim(context.Background(), request(1), nil),
+	} {
+		if result.ErrorResult != "" {
+			t.Fatalf("%s sim failed: %s", name, result.ErrorResult)
+		}
+		actual := append(result.HighestIterations, result.LowestIterations...)
+		if len(actual) != len(expected) {
+			t.Fatalf("%s: expected %d outliers, got %d", name, len(expected), len(actual))
+		}
+		for i, outlier := range actual {
+			if outlier.Iteration != expected[i].Iteration || outlier.Dps != expected[i].Dps || !slices.Equal(splitIterationLogs(outlier.Logs), splitIterationLogs(expected[i].Logs)) {
+				t.Errorf("%s outlier %d: expected iteration %d, got %d", name, i, expected[i].Iteration, outlier.Iteration)
+			}
+		}
+	}
+}
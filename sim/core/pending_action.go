@@ -35,6 +35,7 @@ type PendingAction struct {
 
 	cancelled bool
 	consumed  bool
+	queued    bool // Whether this is in sim.pendingActions, or popped but not yet run.
 }
 
 func (pa *PendingAction) Cancel(sim *Simulation) {
@@ -49,3 +50,276 @@ func (pa *PendingAction) Cancel(sim *Simulation) {
 
 	pa.cancelled = true
 }
+
+// Pending actions for something that's frequently rescheduled, reused once
+// they're no longer queued so that rescheduling doesn't allocate.
+type pendingActionPool struct {
+	actions []*PendingAction
+}
+
+// Returns a copy of action that isn't queued, reusing a previous one if possible.
+func (pool *pendingActionPool) get(sim *Simulation, action PendingAction) *PendingAction {
+	for _, pa := range pool.actions {
+		if !pa.queued {
+			*pa = action
+			return pa
+		}
+	}
+
+	sim.allocAudit.begin(allocSectionScheduling)
+	pa := &PendingAction{}
+	pool.actions = append(pool.actions, pa)
+	sim.allocAudit.end()
+
+	*pa = action
+	return pa
+}
+
+type queuedAction struct {
+	pa *PendingAction
+
+	// Copied from pa when it's queued, so the heap stays valid if pa is
+	// changed while it's queued, and so sifting doesn't dereference pa.
+	at       time.Duration
+	priority ActionPriority
+	seq      uint64
+}
+
+// Earliest time first, then highest priority, then first queued.
+func (qa *queuedAction) before(other *queuedAction) bool {
+	if qa.at != other.at {
+		return qa.at < other.at
+	}
+	if qa.priority != other.priority {
+		return qa.priority > other.priority
+	}
+	return qa.seq < other.seq
+}
+
+// Queue of pending actions, ordered by queuedAction.before. Uses a 4-ary heap
+// by default, or a timing wheel with SimOptions.pending_action_queue.
+type pendingActionQueue struct {
+	heap  pendingActionHeap
+	wheel *pendingActionWheel
+
+	nextSeq uint64
+}
+
+func (queue *pendingActionQueue) reset() {
+	if queue.wheel != nil {
+		queue.wheel.reset()
+	} else {
+		queue.heap.reset()
+	}
+	queue.nextSeq = 0
+}
+
+func (queue *pendingActionQueue) push(pa *PendingAction) {
+	qa := queuedAction{
+		pa:       pa,
+		at:       pa.NextActionAt,
+		priority: pa.Priority,
+		seq:      queue.nextSeq,
+	}
+	queue.nextSeq++
+	if queue.wheel != nil {
+		queue.wheel.push(qa)
+	} else {
+		queue.heap.push(qa)
+	}
+}
+
+// Returns the next action to run, without removing it.
+func (queue *pendingActionQueue) peek() *PendingAction {
+	if queue.wheel != nil {
+		return queue.wheel.peek()
+	}
+	return queue.heap.actions[0].pa
+}
+
+// Removes and returns the next action to run.
+func (queue *pendingActionQueue) pop() *PendingAction {
+	if queue.wheel != nil {
+		return queue.wheel.pop()
+	}
+	return queue.heap.pop()
+}
+
+// Calls f for each queued action, in no particular order.
+func (queue *pendingActionQueue) forEach(f func(pa *PendingAction)) {
+	if queue.wheel == nil {
+		queue.heap.forEach(f)
+		return
+	}
+	for i := range queue.wheel.slots {
+		queue.wheel.slots[i].forEach(f)
+	}
+	queue.wheel.overflow.forEach(f)
+}
+
+// 4-ary min-heap of queued actions, so adding and popping actions is O(log n)
+// even with many DoTs ticking on many targets. The extra children per node
+// make it shallower than a binary heap, and sifting down compares children
+// that are next to each other in memory.
+type pendingActionHeap struct {
+	actions []queuedAction
+}
+
+const pendingActionHeapArity = 4
+
+func (heap *pendingActionHeap) reset() {
+	for _, qa := range heap.actions {
+		if qa.pa.queued { // Never true for the sentinel, which is shared by all sims.
+			qa.pa.queued = false
+		}
+	}
+	heap.actions = heap.actions[:0]
+}
+
+func (heap *pendingActionHeap) forEach(f func(pa *PendingAction)) {
+	for _, qa := range heap.actions {
+		f(qa.pa)
+	}
+}
+
+func (heap *pendingActionHeap) push(qa queuedAction) {
+	heap.actions = append(heap.actions, qa)
+	heap.siftUp(len(heap.actions) - 1)
+}
+
+func (heap *pendingActionHeap) pop() *PendingAction {
+	pa := heap.actions[0].pa
+	last := len(heap.actions) - 1
+	heap.actions[0] = heap.actions[last]
+	heap.actions[last] = queuedAction{}
+	heap.actions = heap.actions[:last]
+	if last > 0 {
+		heap.siftDown(0)
+	}
+	return pa
+}
+
+func (heap *pendingActionHeap) siftUp(i int) {
+	qa := heap.actions[i]
+	for i > 0 {
+		parent := (i - 1) / pendingActionHeapArity
+		if !qa.before(&heap.actions[parent]) {
+			break
+		}
+		heap.actions[i] = heap.actions[parent]
+		i = parent
+	}
+	heap.actions[i] = qa
+}
+
+func (heap *pendingActionHeap) siftDown(i int) {
+	n := len(heap.actions)
+	qa := heap.actions[i]
+	for {
+		first := i*pendingActionHeapArity + 1
+		if first >= n {
+			break
+		}
+		best := first
+		for child := first + 1; child < min(first+pendingActionHeapArity, n); child++ {
+			if heap.actions[child].before(&heap.actions[best]) {
+				best = child
+			}
+		}
+		if !heap.actions[best].before(&qa) {
+			break
+		}
+		heap.actions[i] = heap.actions[best]
+		i = best
+	}
+	heap.actions[i] = qa
+}
+
+// Timing wheel of pending actions: a ring of slots, each holding the actions
+// due within one tick of the wheel. Scheduling an action only sorts it among
+// the few actions due in the same tick, instead of among every queued action.
+// Actions beyond the wheel's horizon wait in an overflow heap until the wheel
+// turns far enough to reach them.
+type pendingActionWheel struct {
+	slots []pendingActionHeap
+
+	current int           // Slot of the earliest tick still covered by the wheel.
+	start   time.Duration // Start of the current slot's tick.
+	count   int           // Number of actions in slots, not counting overflow.
+
+	overflow pendingActionHeap
+}
+
+const (
+	pendingActionWheelTick  = 10 * time.Millisecond
+	pendingActionWheelSlots = 512 // Covers a bit over 5s, longer than most DoT ticks and swings.
+
+	pendingActionWheelHorizon = pendingActionWheelTick * pendingActionWheelSlots
+)
+
+func newPendingActionWheel() *pendingActionWheel {
+	return &pendingActionWheel{
+		slots: make([]pendingActionHeap, pendingActionWheelSlots),
+	}
+}
+
+func (wheel *pendingActionWheel) reset() {
+	for i := range wheel.slots {
+		wheel.slots[i].reset()
+	}
+	wheel.overflow.reset()
+	wheel.current = 0
+	wheel.start = 0
+	wheel.count = 0
+}
+
+func (wheel *pendingActionWheel) push(qa queuedAction) {
+	// Subtracting first, so actions at NeverExpires don't overflow.
+	offset := qa.at - wheel.start
+	if offset >= pendingActionWheelHorizon {
+		wheel.overflow.push(qa)
+		return
+	}
+
+	// Actions in the past run next, so they go in the current slot.
+	slot := wheel.current + int(max(offset, 0)/pendingActionWheelTick)
+	if slot >= pendingActionWheelSlots {
+		slot -= pendingActionWheelSlots
+	}
+	wheel.slots[slot].push(qa)
+	wheel.count++
+}
+
+func (wheel *pendingActionWheel) peek() *PendingAction {
+	wheel.turn()
+	return wheel.slots[wheel.current].actions[0].pa
+}
+
+func (wheel *pendingActionWheel) pop() *PendingAction {
+	wheel.turn()
+	wheel.count--
+	return wheel.slots[wheel.current].pop()
+}
+
+// Turns the wheel until the current slot has the next action to run.
+func (wheel *pendingActionWheel) turn() {
+	for len(wheel.slots[wheel.current].actions) == 0 {
+		if wheel.count == 0 {
+			// Skip straight to the next overflow action, instead of turning
+			// through all the empty ticks before it.
+			wheel.start = wheel.overflow.actions[0].at
+		} else {
+			wheel.current++
+			if wheel.current == pendingActionWheelSlots {
+				wheel.current = 0
+			}
+			wheel.start += pendingActionWheelTick
+		}
+
+		for len(wheel.overflow.actions) > 0 && wheel.overflow.actions[0].at-wheel.start < pendingActionWheelHorizon {
+			qa := wheel.overflow.actions[0]
+			wheel.overflow.pop()
+			wheel.push(qa)
+		}
+	}
+}
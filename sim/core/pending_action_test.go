@@ -0,0 +1,152 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func testQueues() map[string]func() *pendingActionQueue {
+	return map[string]func() *pendingActionQueue{
+		"Heap": func() *pendingActionQueue { return &pendingActionQueue{} },
+		"TimeWheel": func() *pendingActionQueue {
+			return &pendingActionQueue{wheel: newPendingActionWheel()}
+		},
+	}
+}
+
+func TestPendingActionQueueOrder(t *testing.T) {
+	for name, newQueue := range testQueues() {
+		t.Run(name, func(t *testing.T) {
+			testPendingActionQueueOrder(t, newQueue())
+		})
+	}
+}
+
+func testPendingActionQueueOrder(t *testing.T, queue *pendingActionQueue) {
+	rand := NewSplitMix(1234)
+
+	// Few distinct times and priorities, so there are lots of ties. Times go
+	// past the time wheel's horizon, so some actions start in its overflow.
+	var actions []*PendingAction
+	for i := 0; i < 1000; i++ {
+		pa := &PendingAction{
+			NextActionAt: time.Duration(rand.Next()%20) * time.Second,
+			Priority:     ActionPriority(rand.Next()%5) - 1,
+		}
+		actions = append(actions, pa)
+		queue.push(pa)
+	}
+
+	slices.SortStableFunc(actions, func(a, b *PendingAction) int {
+		if a.NextActionAt != b.NextActionAt {
+			return int(a.NextActionAt - b.NextActionAt)
+		}
+		return int(b.Priority - a.Priority)
+	})
+
+	for i, expected := range actions {
+		if pa := queue.pop(); pa != expected {
+			t.Fatalf("Action %d: expected %+v, got %+v", i, *expected, *pa)
+		}
+	}
+	var remaining int
+	queue.forEach(func(pa *PendingAction) { remaining++ })
+	if remaining != 0 {
+		t.Fatalf("Expected empty queue, got %d actions", remaining)
+	}
+}
+
+// Like the sim, pushes actions relative to the last popped one while popping.
+func TestPendingActionQueueInterleaved(t *testing.T) {
+	heap, wheel := testQueues()["Heap"](), testQueues()["TimeWheel"]()
+	rand := NewSplitMix(1234)
+
+	var now time.Duration
+	for i := 0; i < 10000; i++ {
+		// Mostly soon, sometimes far past the wheel's horizon, and sometimes in
+		// the past, which runs next.
+		var delay time.Duration
+		switch roll := rand.Next() % 10; {
+		case roll == 0:
+			delay = time.Duration(rand.Next()%60) * time.Second
+		case roll == 1:
+			delay = -time.Second
+		default:
+			delay = time.Duration(rand.Next()%3000) * time.Millisecond
+		}
+		pa := &PendingAction{
+			NextActionAt: max(now+delay, 0),
+			Priority:     ActionPriority(rand.Next()%5) - 1,
+		}
+		heap.push(pa)
+		wheel.push(pa)
+
+		if rand.Next()%2 == 0 {
+			if wheel.peek() != heap.peek() {
+				t.Fatalf("Step %d: peeked %+v, expected %+v", i, *wheel.peek(), *heap.peek())
+			}
+			expected := heap.pop()
+			if pa := wheel.pop(); pa != expected {
+				t.Fatalf("Step %d: popped %+v, expected %+v", i, *pa, *expected)
+			}
+			now = max(now, expected.NextActionAt)
+		}
+	}
+}
+
+func TestPendingActionTimeWheelSim(t *testing.T) {
+	rsr := whatIfTestRequest()
+	rsr.SimOptions = &proto.SimOptions{Iterations: 3, RandomSeed: 101, Debug: true}
+	rsr.Raid.Parties[0].Players[0].Rotation = fakeDotRotation()
+	heap := RunRaidSim(rsr)
+
+	rsr.SimOptions.PendingActionQueue = proto.PendingActionQueue_PendingActionQueueTimeWheel
+	wheel := RunRaidSim(rsr)
+
+	if heap.ErrorResult != "" || wheel.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s%s", heap.ErrorResult, wheel.ErrorResult)
+	}
+	if heap.Logs == "" || wheel.Logs != heap.Logs {
+		t.Fatalf("Expected the time wheel to run the same events, got:\n%s\nExpected:\n%s", wheel.Logs, heap.Logs)
+	}
+	if wheel.RaidMetrics.Dps.Avg != heap.RaidMetrics.Dps.Avg {
+		t.Errorf("Expected %0.3f DPS, got %0.3f", heap.RaidMetrics.Dps.Avg, wheel.RaidMetrics.Dps.Avg)
+	}
+}
+
+// Like a DoT-heavy sim, pops the next action and requeues it one period later.
+func BenchmarkPendingActionQueue(b *testing.B) {
+	for _, name := range []string{"Heap", "TimeWheel"} {
+		for _, numActions := range []int{16, 128, 1024} {
+			b.Run(fmt.Sprintf("%s/%dActions", name, numActions), func(b *testing.B) {
+				benchmarkPendingActionQueue(b, testQueues()[name](), numActions)
+			})
+		}
+	}
+}
+
+func benchmarkPendingActionQueue(b *testing.B, queue *pendingActionQueue, numActions int) {
+	rand := NewSplitMix(1234)
+	for i := 0; i < numActions; i++ {
+		pa := &PendingAction{
+			NextActionAt: time.Duration(rand.Next()%3000) * time.Millisecond,
+			Priority:     ActionPriorityDOT,
+		}
+		period := time.Duration(1000+rand.Next()%2000) * time.Millisecond
+		pa.OnAction = func(sim *Simulation) {
+			pa.NextActionAt += period
+		}
+		queue.push(pa)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pa := queue.pop()
+		pa.OnAction(nil)
+		queue.push(pa)
+	}
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"google.golang.org/protobuf/encoding/prototext"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Performance gating times the Average test of each test suite, which runs
+// the spec's representative rotation, so that the suite can fail when the sim
+// gets much slower. Timing is slow and noisy, so it's only compiled in with
+// the 'perf_gate' build tag:
+//
+//	go test --tags=with_db,perf_gate ./sim/...
+//
+// Iteration times are divided by the time of a reference workload measured
+// alongside them, so that results are comparable across machines. They're
+// written to <suite>.perf.tmp, and compared to <suite>.perf if it exists.
+
+// Tests fail if their relative cost grows by more than this factor.
+const perfRegressionThreshold = 1.5
+
+const perfGateIterations = 500
+
+// Each measurement is repeated, and the fastest one kept, to reduce noise.
+const perfGateRuns = 3
+
+// Times a fixed amount of work on the pending action queue, the innermost
+// loop of the sim.
+func timePerfReference() time.Duration {
+	rand := NewSplitMix(1234)
+	var queue pendingActionQueue
+	for i := 0; i < 128; i++ {
+		pa := &PendingAction{
+			NextActionAt: time.Duration(rand.Next()%3000) * time.Millisecond,
+			Priority:     ActionPriorityDOT,
+		}
+		period := time.Duration(1000+rand.Next()%2000) * time.Millisecond
+		pa.OnAction = func(sim *Simulation) {
+			pa.NextActionAt += period
+		}
+		queue.push(pa)
+	}
+
+	start := time.Now()
+	for i := 0; i < 1_000_000; i++ {
+		pa := queue.pop()
+		pa.OnAction(nil)
+		queue.push(pa)
+	}
+	return time.Since(start)
+}
+
+// Returns the sim's iterations per second, and its time per iteration relative
+// to the reference workload.
+func measurePerf(rsr *proto.RaidSimRequest) (float64, float64, error) {
+	request := googleProto.Clone(rsr).(*proto.RaidSimRequest)
+	request.SimOptions.IsTest = false
+	request.SimOptions.Iterations = perfGateIterations
+	request.SimOptions.Concurrency = 1
+
+	var fastest, reference time.Duration
+	for i := 0; i < perfGateRuns; i++ {
+		start := time.Now()
+		result := RunRaidSim(request)
+		elapsed := time.Since(start)
+		if result.ErrorResult != "" {
+			return 0, 0, errors.New(result.ErrorResult)
+		}
+		if i == 0 || elapsed < fastest {
+			fastest = elapsed
+		}
+		if elapsed := timePerfReference(); i == 0 || elapsed < reference {
+			reference = elapsed
+		}
+	}
+
+	perIteration := fastest.Seconds() / perfGateIterations
+	return 1 / perIteration, perIteration / reference.Seconds(), nil
+}
+
+// Measures the test's relative cost, and fails it if that regressed past
+// perfRegressionThreshold. Does nothing unless built with 'perf_gate'.
+func (testSuite *IndividualTestSuite) checkPerf(t *testing.T, testName string, rsr *proto.RaidSimRequest, expected *proto.PerfTestResult) {
+	if !perfGateEnabled {
+		return
+	}
+
+	iterationsPerSecond, relativeCost, err := measurePerf(rsr)
+	if err != nil {
+		panic("simulation failed to run: " + err.Error())
+	}
+	testSuite.perfResults.RelativeCosts[testName] = toFixed(relativeCost, storagePrecision)
+	t.Logf("%s: %0.0f iterations/s, relative cost %0.5f", testName, iterationsPerSecond, relativeCost)
+
+	if expectedCost, ok := expected.RelativeCosts[testName]; ok && relativeCost > expectedCost*perfRegressionThreshold {
+		t.Logf("Relative cost regressed from %0.5f to %0.5f, more than %0.0f%% slower!", expectedCost, relativeCost, (perfRegressionThreshold-1)*100)
+		t.Fail()
+	}
+}
+
+func (testSuite *IndividualTestSuite) writePerfToFile() {
+	if len(testSuite.perfResults.RelativeCosts) == 0 {
+		return
+	}
+	str := strings.ReplaceAll(prototext.Format(testSuite.perfResults), "  ", " ")
+	if err := os.WriteFile(testSuite.Name+".perf.tmp", []byte(str), 0644); err != nil {
+		panic(err)
+	}
+}
+
+func (testSuite *IndividualTestSuite) readExpectedPerf() (*proto.PerfTestResult, error) {
+	results := newPerfTestResult()
+	data, err := os.ReadFile(testSuite.Name + ".perf")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return results, nil
+		}
+		return nil, err
+	}
+	if err = prototext.Unmarshal(data, results); err != nil {
+		return nil, fmt.Errorf("invalid perf results: %w", err)
+	}
+	return results, nil
+}
+
+func newPerfTestResult() *proto.PerfTestResult {
+	return &proto.PerfTestResult{
+		RelativeCosts: make(map[string]float64),
+	}
+}
@@ -0,0 +1,5 @@
+//go:build perf_gate
+
+package core
+
+const perfGateEnabled = true
@@ -19,12 +19,15 @@ func NewDelayedAction(sim *Simulation, options DelayedActionOptions) *PendingAct
 		panic("NewDelayedAction: OnAction must not be nil")
 	}
 
-	return &PendingAction{
+	sim.allocAudit.begin(allocSectionScheduling)
+	pa := &PendingAction{
 		NextActionAt: options.DoAt,
 		Priority:     options.Priority,
 		OnAction:     options.OnAction,
 		CleanUp:      options.CleanUp,
 	}
+	sim.allocAudit.end()
+	return pa
 }
 
 // Convenience for immediately creating and starting a delayed action.
@@ -52,31 +55,50 @@ type PeriodicActionOptions struct {
 	CleanUp  func(*Simulation)
 }
 
-func NewPeriodicAction(sim *Simulation, options PeriodicActionOptions) *PendingAction {
+// Everything a periodic action needs, so that creating one is a single
+// allocation plus its OnAction method value.
+type periodicAction struct {
+	PendingAction
+	options   PeriodicActionOptions
+	tickIndex int
+	running   bool // Whether options.OnAction is being called.
+}
+
+func (pa *periodicAction) tick(sim *Simulation) {
+	pa.running = true
+	pa.options.OnAction(sim)
+	pa.running = false
+	pa.tickIndex++
+
+	if pa.options.NumTicks == 0 || pa.tickIndex < pa.options.NumTicks {
+		// Refresh action.
+		pa.NextActionAt = sim.CurrentTime + pa.options.Period
+		sim.AddPendingAction(&pa.PendingAction)
+	} else {
+		pa.Cancel(sim)
+	}
+}
+
+// Whether this can be restarted without affecting anything else. Whoever
+// holds the action must also no longer need it.
+func (pa *periodicAction) reusable() bool {
+	return !pa.queued && !pa.running
+}
+
+// Resets the action to the state of a new one with the given options.
+func (pa *periodicAction) start(sim *Simulation, options PeriodicActionOptions) {
 	if options.OnAction == nil {
 		panic("NewPeriodicAction: OnAction must not be nil")
 	}
 
-	pa := &PendingAction{
+	pa.PendingAction = PendingAction{
 		NextActionAt: sim.CurrentTime + options.Period,
 		Priority:     options.Priority,
+		OnAction:     pa.OnAction,
 		CleanUp:      options.CleanUp,
 	}
-
-	tickIndex := 0
-
-	pa.OnAction = func(sim *Simulation) {
-		options.OnAction(sim)
-		tickIndex++
-
-		if options.NumTicks == 0 || tickIndex < options.NumTicks {
-			// Refresh action.
-			pa.NextActionAt = sim.CurrentTime + options.Period
-			sim.AddPendingAction(pa)
-		} else {
-			pa.Cancel(sim)
-		}
-	}
+	pa.options = options
+	pa.tickIndex = 0
 
 	if options.TickImmediately {
 		// t = 0 might be during reset, so put it in the actions queue instead of
@@ -84,17 +106,31 @@ func NewPeriodicAction(sim *Simulation, options PeriodicActionOptions) *PendingA
 		if sim.CurrentTime == 0 {
 			pa.NextActionAt = 0
 		} else {
+			pa.running = true
 			options.OnAction(sim)
-			tickIndex++
+			pa.running = false
+			pa.tickIndex++
 			if options.NumTicks == 1 {
 				pa.Cancel(sim)
 			}
 		}
 	}
+}
+
+func newPeriodicAction(sim *Simulation, options PeriodicActionOptions) *periodicAction {
+	sim.allocAudit.begin(allocSectionScheduling)
+	pa := &periodicAction{}
+	pa.OnAction = pa.tick
+	sim.allocAudit.end()
 
+	pa.start(sim, options)
 	return pa
 }
 
+func NewPeriodicAction(sim *Simulation, options PeriodicActionOptions) *PendingAction {
+	return &newPeriodicAction(sim, options).PendingAction
+}
+
 // Convenience for immediately creating and starting a periodic action.
 func StartPeriodicAction(sim *Simulation, options PeriodicActionOptions) *PendingAction {
 	pa := NewPeriodicAction(sim, options)
@@ -23,6 +23,34 @@ type OnPetDisable func(sim *Simulation)
 type PetStatInheritance func(ownerStats stats.Stats) stats.Stats
 type PetMeleeSpeedInheritance func(amount float64)
 
+// A pet stat inherited as a fixed fraction of one of its owner's stats.
+type PetStatRatio struct {
+	OwnerStat stats.Stat
+	PetStat   stats.Stat
+	Ratio     float64
+}
+
+// Creates a PetStatInheritance from a list of fixed ratios. Pets with more
+// complicated scaling, e.g. hit caps, should write their own inheritance.
+func NewPetStatInheritance(ratios ...PetStatRatio) PetStatInheritance {
+	return func(ownerStats stats.Stats) stats.Stats {
+		var inheritedStats stats.Stats
+		for _, ratio := range ratios {
+			inheritedStats[ratio.PetStat] += ownerStats[ratio.OwnerStat] * ratio.Ratio
+		}
+		return inheritedStats
+	}
+}
+
+// Attack power and crit scaling from primary stats, which varies by pet family.
+type PetMeleeScaling struct {
+	AttackPowerPerStrength float64
+	AttackPowerPerAgility  float64
+
+	// Agility needed for 1% melee crit.
+	AgilityPerCritPercent float64
+}
+
 // Pet is an extension of Character, for any entity created by a player that can
 // take actions on its own.
 type Pet struct {
@@ -48,7 +76,13 @@ type Pet struct {
 
 	// Some pets expire after a certain duration. This is the pending action that disables
 	// the pet on expiration.
-	timeoutAction *PendingAction
+	timeoutAction  *PendingAction
+	timeoutActions pendingActionPool
+	onTimeout      func(sim *Simulation)
+
+	// Only set if the owner has deaths enabled.
+	deathTrackerAura *Aura
+	resummonDelay    time.Duration
 }
 
 func NewPet(name string, owner *Character, baseStats stats.Stats, statInheritance PetStatInheritance, enabledOnStart bool, isGuardian bool) Pet {
@@ -101,6 +135,7 @@ func (pet *Pet) reset(sim *Simulation, agent PetAgent) {
 	pet.isReset = true
 
 	pet.Character.reset(sim, agent)
+	pet.inheritedStats = stats.Stats{}
 
 	pet.CancelGCDTimer(sim)
 	pet.AutoAttacks.CancelAutoSwing(sim)
@@ -134,6 +169,11 @@ func (pet *Pet) Enable(sim *Simulation, petAgent PetAgent) {
 		pet.reset(sim, petAgent)
 	}
 
+	// Permanent pets keep their inherited stats while dismissed, so replace them
+	// when resummoned.
+	if pet.inheritedStats != (stats.Stats{}) {
+		pet.AddStatsDynamic(sim, pet.inheritedStats.Invert())
+	}
 	pet.inheritedStats = pet.statInheritance(pet.Owner.GetStats())
 	pet.AddStatsDynamic(sim, pet.inheritedStats)
 
@@ -143,7 +183,7 @@ func (pet *Pet) Enable(sim *Simulation, petAgent PetAgent) {
 	}
 
 	//reset current mana after applying stats
-	pet.manaBar.reset()
+	pet.manaBar.reset(sim)
 
 	// Call onEnable callbacks before enabling auto swing
 	// to not have to reorder PAs multiple times
@@ -174,18 +214,26 @@ func (pet *Pet) Enable(sim *Simulation, petAgent PetAgent) {
 	if pet.HasFocusBar() {
 		pet.focusBar.enable(sim)
 	}
+
+	if pet.deathTrackerAura != nil {
+		pet.currentHealth = pet.MaxHealth()
+		pet.deathTrackerAura.Activate(sim)
+	}
 }
 
 // Helper for enabling a pet that will expire after a certain duration.
 func (pet *Pet) EnableWithTimeout(sim *Simulation, petAgent PetAgent, petDuration time.Duration) {
 	pet.Enable(sim, petAgent)
 
-	pet.timeoutAction = &PendingAction{
-		NextActionAt: sim.CurrentTime + petDuration,
-		OnAction: func(sim *Simulation) {
+	if pet.onTimeout == nil {
+		pet.onTimeout = func(sim *Simulation) {
 			pet.Disable(sim)
-		},
+		}
 	}
+	pet.timeoutAction = pet.timeoutActions.get(sim, PendingAction{
+		NextActionAt: sim.CurrentTime + petDuration,
+		OnAction:     pet.onTimeout,
+	})
 	sim.AddPendingAction(pet.timeoutAction)
 }
 
@@ -261,6 +309,103 @@ func (pet *Pet) Disable(sim *Simulation) {
 	}
 }
 
+// Adds the stat dependencies for a melee pet's attack power and crit.
+func (pet *Pet) AddMeleeStatDependencies(scaling PetMeleeScaling) {
+	if scaling.AttackPowerPerStrength != 0 {
+		pet.AddStatDependency(stats.Strength, stats.AttackPower, scaling.AttackPowerPerStrength)
+	}
+	if scaling.AttackPowerPerAgility != 0 {
+		pet.AddStatDependency(stats.Agility, stats.AttackPower, scaling.AttackPowerPerAgility)
+	}
+	if scaling.AgilityPerCritPercent != 0 {
+		pet.AddStatDependency(stats.Agility, stats.MeleeCrit, CritRatingPerCritChance/scaling.AgilityPerCritPercent)
+	}
+}
+
+// Returns an OnFocusGain callback which lets the pet act as soon as it has
+// enough focus, using its APL if it has one.
+func (pet *Pet) ResumeOnFocusGain(petAgent PetAgent) OnFocusGain {
+	return func(sim *Simulation) {
+		if !pet.GCD.IsReady(sim) {
+			return
+		}
+		if pet.Rotation != nil {
+			pet.Rotation.DoNextAction(sim)
+		} else {
+			petAgent.OnGCDReady(sim)
+		}
+	}
+}
+
+// Registers an aura on the pet which is active whenever the given owner aura
+// is, for owner procs which also buff the pet.
+func (pet *Pet) RegisterOwnerLinkedAura(ownerAura *Aura, config Aura) *Aura {
+	config.Duration = NeverExpires
+	petAura := pet.RegisterAura(config)
+
+	ownerAura.ApplyOnGain(func(_ *Aura, sim *Simulation) {
+		if pet.IsEnabled() {
+			petAura.Activate(sim)
+		}
+	})
+	ownerAura.ApplyOnExpire(func(_ *Aura, sim *Simulation) {
+		petAura.Deactivate(sim)
+	})
+
+	return petAura
+}
+
+// Lets the pet take damage and die, optionally being resummoned by its owner
+// after a delay.
+func (pet *Pet) enableDeath(options *proto.DeathOptions) {
+	if options == nil || !options.Enabled {
+		return
+	}
+	pet.resummonDelay = DurationFromSeconds(options.PetResummonDelay)
+
+	onDamageTaken := func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
+		if result.Damage > 0 && pet.IsEnabled() {
+			pet.RemoveHealth(sim, result.Damage)
+			if pet.CurrentHealth() <= 0 {
+				pet.die(sim)
+			}
+		}
+	}
+
+	// Activated in Enable, since all pet auras are dropped when it is dismissed.
+	pet.deathTrackerAura = pet.RegisterAura(Aura{
+		Label:                 "Pet Death Tracker",
+		Duration:              NeverExpires,
+		OnSpellHitTaken:       onDamageTaken,
+		OnPeriodicDamageTaken: onDamageTaken,
+	})
+}
+
+func (pet *Pet) die(sim *Simulation) {
+	pet.Metrics.Died = true
+	pet.Metrics.Deaths++
+	if sim.Log != nil {
+		pet.Log(sim, "Dead")
+	}
+
+	pet.Disable(sim)
+
+	if pet.resummonDelay > 0 && pet.Owner.IsEnabled() {
+		StartDelayedAction(sim, DelayedActionOptions{
+			DoAt: sim.CurrentTime + pet.resummonDelay,
+			OnAction: func(sim *Simulation) {
+				if pet.Owner.IsEnabled() {
+					pet.Enable(sim, pet.getAgent())
+				}
+			},
+		})
+	}
+}
+
+func (pet *Pet) getAgent() PetAgent {
+	return pet.Owner.PetAgents[slices.Index(pet.Owner.Pets, pet)]
+}
+
 // Default implementations for some Agent functions which most Pets don't need.
 func (pet *Pet) GetCharacter() *Character {
 	return &pet.Character
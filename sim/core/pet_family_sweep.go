@@ -0,0 +1,92 @@
+package core
+
+import (
+	"errors"
+	"sort"
+
+	goproto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Returns the pet family variants to sim, with talents filled in from the
+// player's. Variants equal to the equipped pet are skipped, since it is simmed
+// as the equipped result.
+func petFamilyCombos(settings *proto.PetFamilySweepSettings, player *proto.Player) ([]*proto.PetFamilyVariant, error) {
+	options := player.GetHunter().GetOptions()
+	if options == nil {
+		return nil, errors.New("bulksim: pet family sweeps require a hunter")
+	}
+
+	variants := settings.GetVariants()
+	if len(variants) == 0 {
+		for petType := range proto.Hunter_Options_PetType_name {
+			if petType != int32(proto.Hunter_Options_PetNone) {
+				variants = append(variants, &proto.PetFamilyVariant{PetType: proto.Hunter_Options_PetType(petType)})
+			}
+		}
+		sort.Slice(variants, func(i, j int) bool {
+			return variants[i].PetType < variants[j].PetType
+		})
+	}
+
+	equipped := equippedPetFamily(player)
+	var combos []*proto.PetFamilyVariant
+	for _, variant := range variants {
+		variant = goproto.Clone(variant).(*proto.PetFamilyVariant)
+		if variant.PetTalents == nil {
+			variant.PetTalents = equipped.PetTalents
+		}
+		if goproto.Equal(variant, equipped) {
+			continue
+		}
+		combos = append(combos, variant)
+	}
+	return combos, nil
+}
+
+// The player's current pet, as a variant.
+func equippedPetFamily(player *proto.Player) *proto.PetFamilyVariant {
+	options := player.GetHunter().GetOptions()
+	return &proto.PetFamilyVariant{
+		PetType:    options.GetPetType(),
+		PetTalents: options.GetPetTalents(),
+	}
+}
+
+// createNewRequestWithPetFamily creates a copy of the input RaidSimRequest with the player's
+// pet family and pet talents replaced.
+func createNewRequestWithPetFamily(readonlyInputRequest *proto.RaidSimRequest, variant *proto.PetFamilyVariant) *proto.RaidSimRequest {
+	request := goproto.Clone(readonlyInputRequest).(*proto.RaidSimRequest)
+	options := request.Raid.Parties[0].Players[0].GetHunter().Options
+	options.PetType = variant.PetType
+	options.PetTalents = variant.PetTalents
+	return request
+}
+
+// Converts ranked sweep results into combo results, labeled with their pet
+// family. rankedResults includes the base result.
+func rankPetFamilySweep(rankedResults []*itemSubstitutionSimResult, baseResult *itemSubstitutionSimResult) (*proto.BulkComboResult, []*proto.BulkComboResult) {
+	toComboResult := func(r *itemSubstitutionSimResult, variant *proto.PetFamilyVariant) *proto.BulkComboResult {
+		um := r.Result.GetRaidMetrics().GetParties()[0].GetPlayers()[0]
+		um.Actions = nil
+		um.Auras = nil
+		um.Resources = nil
+		um.Pets = nil
+
+		return &proto.BulkComboResult{
+			UnitMetrics: um,
+			PetFamily:   variant,
+			DpsGain:     r.Score() - baseResult.Score(),
+		}
+	}
+
+	equipped := toComboResult(baseResult, equippedPetFamily(baseResult.Request.Raid.Parties[0].Players[0]))
+	results := make([]*proto.BulkComboResult, 0, len(rankedResults))
+	for _, r := range rankedResults {
+		if r.PetFamily != nil {
+			results = append(results, toComboResult(r, r.PetFamily))
+		}
+	}
+	return equipped, results
+}
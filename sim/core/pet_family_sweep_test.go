@@ -0,0 +1,152 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func petFamilySweepTestRequest(settings *proto.BulkSettings) *proto.BulkSimRequest {
+	return &proto.BulkSimRequest{
+		BaseSettings: &proto.RaidSimRequest{
+			Raid: &proto.Raid{
+				Parties: []*proto.Party{{
+					Players: []*proto.Player{{
+						Name:      "Player",
+						Equipment: createEquipmentFromItems(),
+						Spec: &proto.Player_Hunter{Hunter: &proto.Hunter{Options: &proto.Hunter_Options{
+							PetType:    proto.Hunter_Options_Wolf,
+							PetTalents: &proto.HunterPetTalents{CobraReflexes: 2},
+						}}},
+					}},
+				}},
+			},
+			SimOptions: &proto.SimOptions{},
+		},
+		BulkSettings: settings,
+	}
+}
+
+func TestPetFamilyCombos(t *testing.T) {
+	player := petFamilySweepTestRequest(nil).BaseSettings.Raid.Parties[0].Players[0]
+
+	combos, err := petFamilyCombos(&proto.PetFamilySweepSettings{}, player)
+	if err != nil {
+		t.Fatalf("Failed to generate combos: %s", err)
+	}
+	// Every family except none and the equipped wolf.
+	if len(combos) != len(proto.Hunter_Options_PetType_name)-2 {
+		t.Fatalf("Expected %d combos, got %d", len(proto.Hunter_Options_PetType_name)-2, len(combos))
+	}
+	for _, variant := range combos {
+		if variant.PetType == proto.Hunter_Options_Wolf || variant.PetType == proto.Hunter_Options_PetNone {
+			t.Errorf("Unexpected pet family: %v", variant.PetType)
+		}
+		if variant.PetTalents.GetCobraReflexes() != 2 {
+			t.Errorf("Player's pet talents weren't kept: %v", variant)
+		}
+	}
+
+	// A wolf with other talents is a different variant.
+	combos, err = petFamilyCombos(&proto.PetFamilySweepSettings{
+		Variants: []*proto.PetFamilyVariant{
+			{PetType: proto.Hunter_Options_Wolf},
+			{PetType: proto.Hunter_Options_Wolf, PetTalents: &proto.HunterPetTalents{SpikedCollar: 3}},
+		},
+	}, player)
+	if err != nil {
+		t.Fatalf("Failed to generate combos: %s", err)
+	}
+	if len(combos) != 1 || combos[0].PetTalents.GetSpikedCollar() != 3 {
+		t.Fatalf("Expected only the wolf with other talents, got %v", combos)
+	}
+
+	request := createNewRequestWithPetFamily(petFamilySweepTestRequest(nil).BaseSettings, &proto.PetFamilyVariant{PetType: proto.Hunter_Options_Cat})
+	if options := request.Raid.Parties[0].Players[0].GetHunter().Options; options.PetType != proto.Hunter_Options_Cat {
+		t.Errorf("Pet family wasn't replaced: %v", options)
+	}
+}
+
+func TestPetFamilySweep(t *testing.T) {
+	// Cats do the most damage, and wolves the least.
+	fakeRunSim := func(ctx context.Context, rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool) *proto.RaidSimResult {
+		dps := 1000.0
+		switch rsr.Raid.Parties[0].Players[0].GetHunter().Options.PetType {
+		case proto.Hunter_Options_Cat:
+			dps += 100
+		case proto.Hunter_Options_Wolf:
+			dps -= 50
+		}
+		if progress != nil {
+			close(progress)
+		}
+		return &proto.RaidSimResult{
+			RaidMetrics: &proto.RaidMetrics{
+				Dps: &proto.DistributionMetrics{Avg: dps},
+				Parties: []*proto.PartyMetrics{{
+					Players: []*proto.UnitMetrics{{Dps: &proto.DistributionMetrics{Avg: dps}}},
+				}},
+			},
+		}
+	}
+
+	bulk := &bulkSimRunner{
+		SingleRaidSimRunner: fakeRunSim,
+		Request: petFamilySweepTestRequest(&proto.BulkSettings{
+			IterationsPerCombo: 1,
+			PetFamilySweep: &proto.PetFamilySweepSettings{
+				Variants: []*proto.PetFamilyVariant{
+					{PetType: proto.Hunter_Options_Raptor},
+					{PetType: proto.Hunter_Options_Cat},
+				},
+			},
+		}),
+	}
+
+	result, err := bulk.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("BulkSim() returned error: %v", err)
+	}
+
+	if equipped := result.EquippedGearResult; equipped.PetFamily.GetPetType() != proto.Hunter_Options_Wolf {
+		t.Errorf("Unexpected equipped result: %v", equipped)
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(result.Results))
+	}
+	if best := result.Results[0]; best.PetFamily.PetType != proto.Hunter_Options_Cat || best.DpsGain != 150 {
+		t.Errorf("Expected cat to rank first, got %v", best)
+	}
+	if last := result.Results[1]; last.PetFamily.PetType != proto.Hunter_Options_Raptor || last.DpsGain != 50 {
+		t.Errorf("Expected raptor to rank last, got %v", last)
+	}
+}
+
+func TestPetFamilySweepErrors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		settings  *proto.BulkSettings
+		notHunter bool
+		errSubstr string
+	}{
+		{"WithItems", &proto.BulkSettings{PetFamilySweep: &proto.PetFamilySweepSettings{}, Items: []*proto.ItemSpec{{Id: 1}}}, false, "can't also substitute"},
+		{"WithConsumableSweep", &proto.BulkSettings{PetFamilySweep: &proto.PetFamilySweepSettings{}, ConsumableSweep: &proto.ConsumableSweepSettings{}}, false, "can't also substitute"},
+		{"NotHunter", &proto.BulkSettings{PetFamilySweep: &proto.PetFamilySweepSettings{}}, true, "require a hunter"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := petFamilySweepTestRequest(testCase.settings)
+			if testCase.notHunter {
+				request.BaseSettings.Raid.Parties[0].Players[0].Spec = nil
+			}
+			bulk := &bulkSimRunner{Request: request}
+			_, err := bulk.Run(context.Background(), nil)
+			if err == nil || !strings.Contains(err.Error(), testCase.errSubstr) {
+				t.Fatalf("Expected error containing %q, got %v", testCase.errSubstr, err)
+			}
+		})
+	}
+}
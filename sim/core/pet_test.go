@@ -0,0 +1,28 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestNewPetStatInheritance(t *testing.T) {
+	inheritance := NewPetStatInheritance(
+		PetStatRatio{OwnerStat: stats.Stamina, PetStat: stats.Stamina, Ratio: 0.3},
+		PetStatRatio{OwnerStat: stats.RangedAttackPower, PetStat: stats.AttackPower, Ratio: 0.22},
+		PetStatRatio{OwnerStat: stats.Stamina, PetStat: stats.AttackPower, Ratio: 0.1},
+	)
+
+	ownerStats := stats.Stats{
+		stats.Stamina:           1000,
+		stats.RangedAttackPower: 5000,
+	}
+	expected := stats.Stats{
+		stats.Stamina:     300,
+		stats.AttackPower: 1200,
+	}
+
+	if actual := inheritance(ownerStats); !actual.EqualsWithTolerance(expected, 0.0001) {
+		t.Fatalf("Expected inherited stats %s, got %s", expected, actual)
+	}
+}
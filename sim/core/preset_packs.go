@@ -0,0 +1,142 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	googleProto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// The pack format version this sim understands. Unlike settings files, packs
+// are written for the current release, so there are no migrations; bump this
+// when a change to the pack protos would break older sims.
+const CurrentPresetPackVersion = 1
+
+var presetPacks []*proto.PresetPack
+
+// Returns all registered preset packs, in registration order.
+func PresetPacks() []*proto.PresetPack {
+	return presetPacks
+}
+
+// Parses and validates a preset pack in JSON or binary proto format. Unknown
+// JSON fields are an error, so typos don't silently drop presets.
+func LoadPresetPack(data []byte) (*proto.PresetPack, error) {
+	pack := &proto.PresetPack{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := protojson.Unmarshal(trimmed, pack); err != nil {
+			return nil, fmt.Errorf("invalid preset pack JSON: %w", err)
+		}
+	} else if err := googleProto.Unmarshal(data, pack); err != nil {
+		return nil, fmt.Errorf("invalid preset pack: %w", err)
+	}
+
+	if pack.Version < 1 {
+		return nil, fmt.Errorf("preset pack %q has no version", pack.Name)
+	}
+	if pack.Version > CurrentPresetPackVersion {
+		return nil, fmt.Errorf("preset pack %q has version %d, which is newer than this sim supports (%d), please update", pack.Name, pack.Version, CurrentPresetPackVersion)
+	}
+	if err := validatePresetPack(pack); err != nil {
+		return nil, fmt.Errorf("preset pack %q: %w", pack.Name, err)
+	}
+	return pack, nil
+}
+
+func validatePresetPack(pack *proto.PresetPack) error {
+	if pack.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+
+	gearSetNames := make(map[string]bool)
+	for _, gearSet := range pack.GearSets {
+		key := gearSet.Spec.String() + "/" + gearSet.Name
+		if gearSet.Name == "" || gearSetNames[key] {
+			return fmt.Errorf("gear set %q for %s must have a unique name", gearSet.Name, gearSet.Spec)
+		}
+		gearSetNames[key] = true
+
+		if discrepancies := ValidateDatabaseReferences(gearSet.Name, gearSet.Gear, nil); len(discrepancies) > 0 {
+			return fmt.Errorf("gear set %s: %s", discrepancies[0].Source, discrepancies[0].Message)
+		}
+	}
+
+	rotationNames := make(map[string]bool)
+	for _, rotation := range pack.Rotations {
+		key := rotation.Spec.String() + "/" + rotation.Name
+		if rotation.Name == "" || rotationNames[key] {
+			return fmt.Errorf("rotation %q for %s must have a unique name", rotation.Name, rotation.Spec)
+		}
+		rotationNames[key] = true
+
+		if rotation.Rotation.GetType() != proto.APLRotation_TypeAPL || len(rotation.Rotation.GetPriorityList()) == 0 {
+			return fmt.Errorf("rotation %q must be an APL with at least one action", rotation.Name)
+		}
+	}
+
+	encounterPaths := make(map[string]bool)
+	for _, encounter := range pack.Encounters {
+		if encounter.Path == "" || len(encounter.Targets) == 0 || encounterPaths[encounter.Path] {
+			return fmt.Errorf("encounter %q must have a unique path and targets", encounter.Path)
+		}
+		encounterPaths[encounter.Path] = true
+		for _, target := range encounter.Targets {
+			if target.Target == nil {
+				return fmt.Errorf("encounter %q has a target without stats", encounter.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// Adds a pack's encounters to PresetEncounters and makes the pack available
+// through PresetPacks(). Packs can't replace built-in or other packs' presets.
+func RegisterPresetPack(pack *proto.PresetPack) error {
+	for _, registered := range presetPacks {
+		if registered.Name == pack.Name {
+			return fmt.Errorf("preset pack %q is already registered", pack.Name)
+		}
+	}
+	for _, encounter := range pack.Encounters {
+		for _, preset := range PresetEncounters {
+			if preset.Path == encounter.Path {
+				return fmt.Errorf("preset pack %q: encounter %s already exists", pack.Name, encounter.Path)
+			}
+		}
+	}
+
+	PresetEncounters = append(PresetEncounters, pack.Encounters...)
+	presetPacks = append(presetPacks, pack)
+	return nil
+}
+
+// Loads and registers every .json and .binpb pack file in the root of fsys,
+// which can be a directory on disk or packs embedded in the release.
+func LoadPresetPacks(fsys fs.FS) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if ext := path.Ext(entry.Name()); entry.IsDir() || (ext != ".json" && ext != ".binpb") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return err
+		}
+		pack, err := LoadPresetPack(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		if err := RegisterPresetPack(pack); err != nil {
+			return fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+const testPresetPackJSON = `{
+	"version": 1,
+	"name": "Test Pack",
+	"packVersion": "2023.10.1",
+	"gearSets": [
+		{"name": "P2", "spec": "SpecMage", "phase": 2, "gear": {"items": [{"id": 992001}]}}
+	],
+	"rotations": [
+		{"name": "Default", "spec": "SpecMage", "rotation": {"type": "TypeAPL", "priorityList": [{"action": {"castSpell": {"spellId": {"spellId": 42833}}}}]}}
+	],
+	"encounters": [
+		{"path": "Test Pack/Dummy", "targets": [{"path": "Test Pack/Dummy", "target": {"level": 83}}]}
+	]
+}`
+
+func resetPresetPacks(t *testing.T) {
+	addToDatabase(&proto.SimDatabase{Items: []*proto.SimItem{{Id: 992001}}})
+
+	encounters, packs := PresetEncounters, presetPacks
+	t.Cleanup(func() {
+		PresetEncounters, presetPacks = encounters, packs
+	})
+}
+
+func TestLoadPresetPack(t *testing.T) {
+	resetPresetPacks(t)
+
+	pack, err := LoadPresetPack([]byte(testPresetPackJSON))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if pack.Name != "Test Pack" || len(pack.GearSets) != 1 || len(pack.Rotations) != 1 || len(pack.Encounters) != 1 {
+		t.Fatalf("Pack not parsed correctly: %v", pack)
+	}
+
+	if err := RegisterPresetPack(pack); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if packs := PresetPacks(); len(packs) == 0 || packs[len(packs)-1] != pack {
+		t.Fatalf("Pack not registered")
+	}
+	if PresetEncounters[len(PresetEncounters)-1].Path != "Test Pack/Dummy" {
+		t.Fatalf("Pack encounter not added to presets")
+	}
+	if err := RegisterPresetPack(pack); err == nil {
+		t.Fatalf("Expected error when registering a pack twice")
+	}
+}
+
+func TestLoadPresetPackErrors(t *testing.T) {
+	resetPresetPacks(t)
+
+	testCases := []struct {
+		name      string
+		old       string
+		new       string
+		errSubstr string
+	}{
+		{"NoVersion", `"version": 1,`, ``, "has no version"},
+		{"NewerVersion", `"version": 1,`, `"version": 2,`, "newer than this sim supports"},
+		{"UnknownField", `"packVersion"`, `"packVersoin"`, "invalid preset pack JSON"},
+		{"MissingItem", `992001`, `992002`, "No item with id 992002"},
+		{"DuplicateGearSet", `{"name": "P2", "spec": "SpecMage", "phase": 2,`, `{"name": "P2", "spec": "SpecMage"}, {"name": "P2", "spec": "SpecMage", "phase": 2,`, "must have a unique name"},
+		{"LegacyRotation", `"TypeAPL"`, `"TypeLegacy"`, "must be an APL"},
+		{"EncounterWithoutTargets", `"targets": [{"path": "Test Pack/Dummy", "target": {"level": 83}}]`, `"targets": []`, "must have a unique path and targets"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := LoadPresetPack([]byte(strings.Replace(testPresetPackJSON, testCase.old, testCase.new, 1)))
+			if err == nil || !strings.Contains(err.Error(), testCase.errSubstr) {
+				t.Fatalf("Expected error containing %q, got %v", testCase.errSubstr, err)
+			}
+		})
+	}
+}
+
+func TestLoadPresetPacks(t *testing.T) {
+	resetPresetPacks(t)
+	numPacks := len(PresetPacks())
+
+	fsys := fstest.MapFS{
+		"test.json":  {Data: []byte(testPresetPackJSON)},
+		"README.md":  {Data: []byte("Not a pack.")},
+		"old/x.json": {Data: []byte("{}")},
+	}
+	if err := LoadPresetPacks(fsys); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(PresetPacks()) != numPacks+1 {
+		t.Fatalf("Expected 1 pack to be loaded, got %d", len(PresetPacks())-numPacks)
+	}
+
+	// Same encounter path as the already loaded pack.
+	fsys = fstest.MapFS{
+		"other.json": {Data: []byte(strings.Replace(testPresetPackJSON, `"name": "Test Pack"`, `"name": "Other Pack"`, 1))},
+	}
+	if err := LoadPresetPacks(fsys); err == nil || !strings.Contains(err.Error(), "other.json") {
+		t.Fatalf("Expected error for other.json, got %v", err)
+	}
+}
@@ -69,6 +69,10 @@ func (sim *Simulation) runPresims(request *proto.RaidSimRequest) *proto.RaidSimR
 	presimRequest.SimOptions.RandomSeed = 1
 	presimRequest.SimOptions.Debug = false
 	presimRequest.SimOptions.DebugFirstIteration = false
+	presimRequest.SimOptions.ReplayIteration = nil
+	presimRequest.SimOptions.OutlierIterations = 0
+	presimRequest.SimOptions.Timeline = nil
+	presimRequest.SimOptions.DamageBuckets = nil
 	presimRequest.SimOptions.Iterations = numPresimIterations
 	duration := DurationFromSeconds(presimRequest.Encounter.Duration)
 
@@ -94,7 +98,7 @@ func (sim *Simulation) runPresims(request *proto.RaidSimRequest) *proto.RaidSimR
 		}
 
 		// Run the presim.
-		presimResult := runSim(presimRequest, nil, true)
+		presimResult := runSimWithContext(sim.ctx, presimRequest, nil, true)
 		lastResult = presimResult
 
 		if presimResult.ErrorResult != "" {
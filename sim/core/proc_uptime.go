@@ -0,0 +1,100 @@
+package core
+
+import (
+	"math"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Events which could have triggered a proc, see ApplyProcTriggerCallback.
+// Counted across iterations, including those during the internal cooldown.
+type procTriggerEvents struct {
+	count     int
+	chanceSum float64
+}
+
+func (events *procTriggerEvents) add(chance float64) {
+	events.count++
+	events.chanceSum += chance
+}
+
+// Estimates the fraction of time a proc aura is active, if its trigger events
+// happen at a steady rate and each procs with the given chance whenever the
+// internal cooldown is ready.
+func estimateProcUptime(eventsPerSecond float64, procChance float64, icd time.Duration, duration time.Duration) float64 {
+	procsPerSecond := eventsPerSecond * procChance
+	if procsPerSecond <= 0 {
+		return 0
+	}
+	if icd <= 0 {
+		// Each proc refreshes the aura, so it only fades once there has been
+		// no proc for its whole duration.
+		return 1 - math.Exp(-procsPerSecond*duration.Seconds())
+	}
+	// Once the cooldown is ready, the next event comes half an interval later
+	// on average, and each event after that is another roll.
+	wait := 0.5/eventsPerSecond + (1/procChance-1)/eventsPerSecond
+	return min(1, duration.Seconds()/(icd.Seconds()+wait))
+}
+
+// Returns the aura granted by a proc trigger aura. Trigger auras don't keep a
+// reference to it, so it is matched by its shared internal cooldown, or by
+// action ID.
+func (character *Character) getProcAura(trigger *Aura) *Aura {
+	for _, aura := range character.auras {
+		if aura == trigger {
+			continue
+		}
+		if trigger.Icd != nil && aura.Icd == trigger.Icd {
+			return aura
+		}
+	}
+	if trigger.ActionIDForProc.IsEmptyAction() {
+		return nil
+	}
+	return character.GetAuraByID(trigger.ActionIDForProc)
+}
+
+// Estimates the uptime of each of the character's proc auras, from the trigger
+// events counted during the given total duration of calibration iterations.
+// Also returns the estimates by proc aura label.
+func (character *Character) procUptimes(totalDuration time.Duration) ([]*proto.ProcUptime, map[string]float64) {
+	var procUptimes []*proto.ProcUptime
+	uptimes := make(map[string]float64)
+	for _, trigger := range character.auras {
+		events := trigger.procTrigger
+		if events == nil || events.count == 0 {
+			continue
+		}
+		procAura := character.getProcAura(trigger)
+		if procAura == nil || procAura.Duration <= 0 || procAura.Duration == NeverExpires {
+			continue
+		}
+
+		var icd time.Duration
+		if trigger.Icd != nil {
+			icd = trigger.Icd.Duration
+		}
+		eventsPerSecond := float64(events.count) / totalDuration.Seconds()
+		procChance := events.chanceSum / float64(events.count)
+		uptime := estimateProcUptime(eventsPerSecond, procChance, icd, procAura.Duration)
+		uptimes[procAura.Label] = uptime
+
+		measured := procAura.metrics.ToProto()
+		procUptime := &proto.ProcUptime{
+			Id:               procAura.ActionID.ToProto(),
+			EventsPerSecond:  eventsPerSecond,
+			ProcChance:       procChance,
+			InternalCooldown: icd.Seconds(),
+			Duration:         procAura.Duration.Seconds(),
+			EstimatedUptime:  uptime,
+			MeasuredUptime:   measured.UptimePercentAvg / 100,
+		}
+		if procAura.MaxStacks > 1 {
+			procUptime.StacksAvg = measured.StacksAvg
+		}
+		procUptimes = append(procUptimes, procUptime)
+	}
+	return procUptimes, uptimes
+}
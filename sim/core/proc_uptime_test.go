@@ -0,0 +1,62 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestEstimateProcUptime(t *testing.T) {
+	// With a 45s cooldown, 1 event per second and a 1/5.5 chance, it takes 5s
+	// on average to proc again once ready. A 10s proc is up 10s out of every
+	// 50s.
+	if uptime := estimateProcUptime(1, 1/5.5, time.Second*45, time.Second*10); !WithinToleranceFloat64(0.2, uptime, 1e-9) {
+		t.Fatalf("Expected 20%% uptime, got %f", uptime)
+	}
+	if uptime := estimateProcUptime(1, 1, 0, time.Second*10); !WithinToleranceFloat64(1, uptime, 1e-4) {
+		t.Fatalf("Expected full uptime for a frequent proc without a cooldown, got %f", uptime)
+	}
+}
+
+func TestProcUptimesMatchCalibration(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{RandomSeed: 101})
+	// Long fights, so that the proc being ready on pull barely matters.
+	request.Encounter.Duration = 300
+	request.Encounter.DurationVariation = 0
+	sim := NewSim(request)
+	character := sim.Raid.Parties[0].Players[0].GetCharacter()
+
+	procAura := character.NewTemporaryStatsAura("Test Proc", ActionID{SpellID: 1}, stats.Stats{stats.Armor: 1000}, time.Second*5)
+	triggerAura := MakeProcTriggerAura(&character.Unit, ProcTrigger{
+		Name:       "Test Proc Trigger",
+		ActionID:   ActionID{SpellID: 1},
+		Callback:   CallbackOnSpellHitTaken,
+		ProcChance: 0.2,
+		ICD:        time.Second * 10,
+		Handler: func(sim *Simulation, _ *Spell, _ *SpellResult) {
+			procAura.Activate(sim)
+		},
+	})
+	procAura.Icd = triggerAura.Icd
+
+	var totalDuration time.Duration
+	for i := int64(0); i < 100; i++ {
+		sim.reseedRands(i)
+		sim.runOnce()
+		totalDuration += sim.Duration
+	}
+
+	procUptimes, uptimes := character.procUptimes(totalDuration)
+	if len(procUptimes) != 1 {
+		t.Fatalf("Expected 1 proc uptime, got %d", len(procUptimes))
+	}
+	procUptime := procUptimes[0]
+	if !WithinToleranceFloat64(procUptime.MeasuredUptime, procUptime.EstimatedUptime, 0.02) {
+		t.Fatalf("Expected the estimated uptime to match the measured uptime %f, got %f", procUptime.MeasuredUptime, procUptime.EstimatedUptime)
+	}
+	if uptimes[procAura.Label] != procUptime.EstimatedUptime {
+		t.Fatalf("Expected the uptime to be keyed by the proc aura")
+	}
+}
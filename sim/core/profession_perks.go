@@ -0,0 +1,155 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Max number of gems requiring Jewelcrafting which may be equipped at once.
+const MaxJewelcraftingGems = 3
+
+// Categories of on-use effects which share a cooldown with each other.
+type SharedCooldownCategory int32
+
+const (
+	SharedCooldownNone SharedCooldownCategory = iota
+	SharedCooldownOffensiveTrinket
+	SharedCooldownDefensiveTrinket
+	SharedCooldownTinker
+)
+
+func (character *Character) GetSharedCooldownTimer(category SharedCooldownCategory) *Timer {
+	switch category {
+	case SharedCooldownOffensiveTrinket:
+		return character.GetOffensiveTrinketCD()
+	case SharedCooldownDefensiveTrinket:
+		return character.GetDefensiveTrinketCD()
+	case SharedCooldownTinker:
+		return character.GetOrInitTimer(&character.tinkerCD)
+	}
+	return nil
+}
+
+// An on-use engineering tinker, e.g. Hyperspeed Accelerators.
+type TinkerConfig struct {
+	Cooldown time.Duration
+
+	// Optional cooldown triggered on other effects of the same category.
+	SharedCategory SharedCooldownCategory
+	SharedDuration time.Duration
+
+	Type CooldownType
+
+	// Returns the config for the tinker's spell. The cast cooldowns are filled
+	// in from the above.
+	Spell func(character *Character) SpellConfig
+}
+
+// Registers a tinker enchant as a major cooldown.
+func NewTinkerEffect(effectID int32, config TinkerConfig) {
+	NewEnchantEffect(effectID, func(agent Agent) {
+		character := agent.GetCharacter()
+
+		spellConfig := config.Spell(character)
+		spellConfig.Cast.CD = Cooldown{
+			Timer:    character.NewTimer(),
+			Duration: config.Cooldown,
+		}
+		if config.SharedCategory != SharedCooldownNone {
+			spellConfig.Cast.SharedCD = Cooldown{
+				Timer:    character.GetSharedCooldownTimer(config.SharedCategory),
+				Duration: config.SharedDuration,
+			}
+		}
+
+		character.AddMajorCooldown(MajorCooldown{
+			Spell:    character.GetOrRegisterSpell(spellConfig),
+			Priority: CooldownPriorityLow, // Use low prio so other actives get used first.
+			Type:     config.Type,
+		})
+	})
+}
+
+// Removes gear which requires a profession the character doesn't have, and
+// profession gems over their equip limits. Each removal adds a warning, or
+// fails the sim with ProfessionValidationStrict. Gear is used as given with
+// ProfessionValidationNone, the default, since presets don't set professions.
+func (character *Character) validateProfessionGear(validation proto.ProfessionValidation) {
+	if validation == proto.ProfessionValidation_ProfessionValidationNone {
+		return
+	}
+	warn := func(message string, vals ...interface{}) {
+		warning := fmt.Sprintf(message, vals...)
+		if validation == proto.ProfessionValidation_ProfessionValidationStrict {
+			panic(fmt.Sprintf("%s: %s", character.Name, warning))
+		}
+		character.Warnings = append(character.Warnings, warning)
+	}
+	missingProfession := func(profession proto.Profession) bool {
+		return profession != proto.Profession_ProfessionUnknown && !character.HasProfession(profession)
+	}
+
+	numJewelcraftingGems := 0
+	uniqueGems := make(map[int32]struct{})
+	for slot := range character.Equipment {
+		item := &character.Equipment[slot]
+		if item.ID == 0 {
+			continue
+		}
+
+		if missingProfession(item.RequiredProfession) {
+			warn("Removed %s, which requires %s.", item.Name, item.RequiredProfession)
+			*item = Item{}
+			continue
+		}
+
+		if missingProfession(item.Enchant.RequiredProfession) {
+			warn("Removed enchant %d from %s, which requires %s.", item.Enchant.EffectID, item.Name, item.Enchant.RequiredProfession)
+			item.Enchant = Enchant{}
+		}
+
+		// Blacksmiths can add a socket to their wrists and hands. The waist
+		// socket comes from a belt buckle, which anyone can use.
+		if numSockets := len(item.GemSockets); len(item.Gems) > numSockets && (slot == int(proto.ItemSlot_ItemSlotWrist) || slot == int(proto.ItemSlot_ItemSlotHands)) && !character.HasProfession(proto.Profession_Blacksmithing) {
+			for _, gem := range item.Gems[numSockets:] {
+				if gem.ID != 0 {
+					warn("Removed %s from the extra socket on %s, which requires %s.", gem.Name, item.Name, proto.Profession_Blacksmithing)
+				}
+			}
+			item.Gems = item.Gems[:numSockets]
+		}
+
+		for i := range item.Gems {
+			gem := &item.Gems[i]
+			if gem.ID == 0 {
+				continue
+			}
+
+			if missingProfession(gem.RequiredProfession) {
+				warn("Removed %s from %s, which requires %s.", gem.Name, item.Name, gem.RequiredProfession)
+				*gem = Gem{}
+				continue
+			}
+
+			if gem.RequiredProfession == proto.Profession_Jewelcrafting {
+				numJewelcraftingGems++
+				if numJewelcraftingGems > MaxJewelcraftingGems {
+					warn("Removed %s from %s, since only %d gems requiring %s may be equipped.", gem.Name, item.Name, MaxJewelcraftingGems, proto.Profession_Jewelcrafting)
+					*gem = Gem{}
+					continue
+				}
+			}
+
+			if gem.Unique {
+				if _, ok := uniqueGems[gem.ID]; ok {
+					warn("Removed %s from %s, since it is unique-equipped.", gem.Name, item.Name)
+					*gem = Gem{}
+					continue
+				}
+				uniqueGems[gem.ID] = struct{}{}
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestValidateProfessionGear(t *testing.T) {
+	dragonsEye := Gem{ID: 1, Name: "Dragon's Eye", RequiredProfession: proto.Profession_Jewelcrafting}
+	uniqueGem := Gem{ID: 2, Name: "Unique Gem", Unique: true}
+	ringEnchant := Enchant{EffectID: 3, RequiredProfession: proto.Profession_Enchanting}
+
+	newCharacter := func(professions ...proto.Profession) *Character {
+		character := &Character{Name: "Test"}
+		copy(character.professions[:], professions)
+		character.Equipment[proto.ItemSlot_ItemSlotHead] = Item{ID: 10, Name: "Goggles", RequiredProfession: proto.Profession_Engineering}
+		character.Equipment[proto.ItemSlot_ItemSlotFinger1] = Item{ID: 11, Name: "Ring", Enchant: ringEnchant}
+		character.Equipment[proto.ItemSlot_ItemSlotWrist] = Item{ID: 12, Name: "Bracers", GemSockets: []proto.GemColor{proto.GemColor_GemColorRed}, Gems: []Gem{dragonsEye, dragonsEye}}
+		character.Equipment[proto.ItemSlot_ItemSlotChest] = Item{ID: 13, Name: "Chest", Gems: []Gem{dragonsEye, dragonsEye, uniqueGem, uniqueGem}}
+		return character
+	}
+
+	character := newCharacter(proto.Profession_Jewelcrafting, proto.Profession_Blacksmithing)
+	character.validateProfessionGear(proto.ProfessionValidation_ProfessionValidationCorrect)
+	if character.Equipment[proto.ItemSlot_ItemSlotHead].ID != 0 {
+		t.Errorf("Expected the engineering helm to be removed")
+	}
+	if character.Equipment[proto.ItemSlot_ItemSlotFinger1].Enchant.EffectID != 0 {
+		t.Errorf("Expected the ring enchant to be removed")
+	}
+	// 4 Dragon's Eyes, so the last one is removed, along with the second unique gem.
+	if gems := character.Equipment[proto.ItemSlot_ItemSlotChest].Gems; gems[0].ID != dragonsEye.ID || gems[1].ID != dragonsEye.ID || gems[2].ID != uniqueGem.ID || gems[3].ID != 0 {
+		t.Errorf("Unexpected chest gems %v", gems)
+	}
+	if gems := character.Equipment[proto.ItemSlot_ItemSlotWrist].Gems; len(gems) != 2 || gems[0].ID != dragonsEye.ID || gems[1].ID != 0 {
+		t.Errorf("Expected blacksmiths to keep their extra socket, got %v", gems)
+	}
+	if len(character.Warnings) != 4 {
+		t.Errorf("Expected 4 warnings, got %v", character.Warnings)
+	}
+
+	character = newCharacter()
+	character.validateProfessionGear(proto.ProfessionValidation_ProfessionValidationCorrect)
+	if gems := character.Equipment[proto.ItemSlot_ItemSlotWrist].Gems; len(gems) != 1 || gems[0].ID != 0 {
+		t.Errorf("Expected the extra socket and Dragon's Eye to be removed, got %v", gems)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected strict validation to fail")
+		}
+	}()
+	newCharacter(proto.Profession_Engineering).validateProfessionGear(proto.ProfessionValidation_ProfessionValidationStrict)
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"math"
+	"time"
+)
+
+// Area effects which periodically refresh a buff on each party member in range
+// of their source, like totems, paladin auras or Tree of Life. Each pulse's
+// buff lingers after the pulse, so a unit keeps it for a while after leaving
+// range or after its pulsing aura is replaced, which is what makes totem
+// twisting possible.
+type PulsingAuraConfig struct {
+	Label    string
+	ActionID ActionID
+
+	// Pulsing auras in the same slot replace each other when dropped, e.g.
+	// totems of the same element or a paladin's auras.
+	Slot string
+
+	// How long the pulsing aura lasts once dropped, e.g. 5 minutes for most
+	// totems. NeverExpires for auras which last until replaced.
+	Duration time.Duration
+
+	// Time between pulses. The first pulse happens when dropped.
+	Period time.Duration
+
+	// How long the buff lasts after each pulse. Should be at least Period, so
+	// the buff doesn't fall off between pulses.
+	BuffDuration time.Duration
+
+	// Party members further than this from the source, in yards, aren't
+	// pulsed. 0 affects the whole party regardless of distance.
+	Radius float64
+
+	// Registers the buff for a party member. Its duration is replaced by
+	// BuffDuration, and its uptime is tracked in that member's aura metrics.
+	MakeBuff func(unit *Unit) *Aura
+}
+
+type PulsingAura struct {
+	config PulsingAuraConfig
+
+	// Active on the source while the pulsing aura is dropped.
+	Aura *Aura
+
+	units  []*Unit
+	buffs  []*Aura
+	pulses *PendingAction
+}
+
+// Registers a pulsing aura sourced from this character, with a buff on each
+// player in its party.
+func (character *Character) RegisterPulsingAura(config PulsingAuraConfig) *PulsingAura {
+	if config.Period <= 0 || config.BuffDuration < config.Period {
+		panic("Pulsing aura " + config.Label + " needs a period, and a buff duration of at least its period")
+	}
+
+	pa := &PulsingAura{config: config}
+	for _, agent := range character.Party.Players {
+		unit := &agent.GetCharacter().Unit
+		buff := config.MakeBuff(unit)
+		buff.Duration = config.BuffDuration
+		pa.units = append(pa.units, unit)
+		pa.buffs = append(pa.buffs, buff)
+	}
+
+	pa.Aura = character.RegisterAura(Aura{
+		Label:    config.Label,
+		ActionID: config.ActionID,
+		Duration: config.Duration,
+		OnGain: func(aura *Aura, sim *Simulation) {
+			pa.pulses = StartPeriodicAction(sim, PeriodicActionOptions{
+				Period:          config.Period,
+				TickImmediately: true,
+				OnAction:        pa.pulse,
+			})
+		},
+		OnExpire: func(aura *Aura, sim *Simulation) {
+			pa.pulses.Cancel(sim)
+			pa.pulses = nil
+		},
+	})
+
+	character.pulsingAuras = append(character.pulsingAuras, pa)
+	return pa
+}
+
+// Drops the pulsing aura, replacing any other in its slot. Dropping an aura
+// which is already active restarts it. Buffs from a replaced aura aren't
+// removed, and expire BuffDuration after its last pulse.
+func (pa *PulsingAura) Drop(sim *Simulation) {
+	for _, other := range pa.Aura.Unit.pulsingAuras {
+		if other.config.Slot == pa.config.Slot {
+			other.Aura.Deactivate(sim)
+		}
+	}
+	pa.Aura.Activate(sim)
+}
+
+func (pa *PulsingAura) IsActive() bool {
+	return pa.Aura.IsActive()
+}
+
+// Whether the unit is close enough to the source to be pulsed. Units are only
+// positioned by their distance from the target, so this assumes the raid is
+// lined up on one side of it.
+func (pa *PulsingAura) InRange(unit *Unit) bool {
+	return pa.config.Radius == 0 || math.Abs(unit.DistanceFromTarget-pa.Aura.Unit.DistanceFromTarget) <= pa.config.Radius
+}
+
+// Returns the buff on the given party member, or nil if they aren't affected.
+func (pa *PulsingAura) Buff(unit *Unit) *Aura {
+	for i, affected := range pa.units {
+		if affected == unit {
+			return pa.buffs[i]
+		}
+	}
+	return nil
+}
+
+func (pa *PulsingAura) pulse(sim *Simulation) {
+	for i, unit := range pa.units {
+		if !unit.IsEnabled() || !pa.InRange(unit) {
+			continue
+		}
+		if buff := pa.buffs[i]; buff.IsActive() {
+			// Pulses aren't refreshes of the buff, so don't count them in its
+			// metrics.
+			buff.refresh(sim)
+		} else {
+			buff.Activate(sim)
+		}
+	}
+}
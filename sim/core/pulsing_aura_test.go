@@ -0,0 +1,96 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func setupPulsingAuraSim() *Simulation {
+	player := func(name string, distance float64) *proto.Player {
+		return &proto.Player{
+			Name:               name,
+			Class:              proto.Class_ClassShaman,
+			Consumes:           &proto.Consumes{},
+			Buffs:              &proto.IndividualBuffs{},
+			Spec:               &proto.Player_ElementalShaman{},
+			Equipment:          &proto.EquipmentSpec{},
+			DistanceFromTarget: distance,
+		}
+	}
+
+	sim := NewSim(&proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{RandomSeed: 100},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{{
+				Players: []*proto.Player{player("Caster", 30), player("Melee", 5)},
+				Buffs:   &proto.PartyBuffs{},
+			}},
+		},
+		Encounter: &proto.Encounter{
+			Targets:  []*proto.Target{{Name: "target", Level: 83}},
+			Duration: 180,
+		},
+	})
+	return sim
+}
+
+func TestPulsingAuras(t *testing.T) {
+	sim := setupPulsingAuraSim()
+	caster := sim.Raid.Parties[0].Players[0].GetCharacter()
+	melee := sim.Raid.Parties[0].Players[1].GetCharacter()
+
+	newTotem := func(label string, spellID int32) *PulsingAura {
+		return caster.RegisterPulsingAura(PulsingAuraConfig{
+			Label:        label,
+			ActionID:     ActionID{SpellID: spellID},
+			Slot:         "Air",
+			Duration:     time.Minute * 5,
+			Period:       time.Second,
+			BuffDuration: time.Second * 10,
+			Radius:       20,
+			MakeBuff: func(unit *Unit) *Aura {
+				return unit.RegisterAura(Aura{
+					Label:    label + " Buff",
+					ActionID: ActionID{SpellID: spellID},
+				})
+			},
+		})
+	}
+	totemA := newTotem("Totem A", 1)
+	totemB := newTotem("Totem B", 2)
+	sim.Reset()
+
+	buffA := totemA.Buff(&caster.Unit)
+	buffB := totemB.Buff(&caster.Unit)
+
+	totemA.Drop(sim)
+	runSimUntil(sim, time.Millisecond*4500)
+	if !totemA.IsActive() || !buffA.IsActive() {
+		t.Fatalf("Expected totem A to be buffing the caster")
+	}
+	if totemA.Buff(&melee.Unit).IsActive() {
+		t.Fatalf("Expected the melee to be out of range")
+	}
+
+	// Totem A's buff lingers for 10s after its last pulse at 4s.
+	totemB.Drop(sim)
+	if totemA.IsActive() || !buffA.IsActive() || !buffB.IsActive() {
+		t.Fatalf("Expected totem B to replace totem A, with both buffs active")
+	}
+	runSimUntil(sim, time.Millisecond*13900)
+	if !buffA.IsActive() {
+		t.Fatalf("Expected totem A's buff to still linger")
+	}
+	runSimUntil(sim, time.Millisecond*14100)
+	if buffA.IsActive() || !buffB.IsActive() {
+		t.Fatalf("Expected totem A's buff to expire, and totem B's to remain")
+	}
+	if uptime := buffA.metrics.Uptime; uptime != time.Second*14 {
+		t.Fatalf("Expected 14s of totem A buff uptime, got %s", uptime)
+	}
+	if procs := buffA.metrics.Procs; procs != 1 {
+		t.Fatalf("Expected pulses not to count as procs, got %d", procs)
+	}
+}
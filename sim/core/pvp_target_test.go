@@ -0,0 +1,48 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestPvPTarget(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{})
+	target := request.Encounter.Targets[0]
+	target.Pvp = true
+	target.Stats = make([]float64, stats.Len)
+	target.Stats[stats.Resilience] = 10 * ResilienceRatingPerCritReductionChance
+	sim := NewSim(request)
+	sim.reset()
+
+	attacker := sim.Raid.AllPlayerUnits[0]
+	defender := sim.Encounter.TargetUnits[0]
+	if defender.Level != CharacterLevel || defender.GetStat(stats.Armor) != DefaultPvPTargetArmor {
+		t.Fatalf("Expected a level %d target with %f armor, got level %d with %f", CharacterLevel, DefaultPvPTargetArmor, defender.Level, defender.GetStat(stats.Armor))
+	}
+
+	attackTable := attacker.AttackTables[defender.UnitIndex]
+	if attackTable.BaseGlanceChance != 0 || attackTable.MeleeCritSuppression != 0 || attackTable.SpellCritSuppression != 0 {
+		t.Fatalf("Expected no glancing blows or crit suppression, got %v", attackTable)
+	}
+
+	// 10% less chance to be crit.
+	spell := &Spell{Unit: attacker, BonusCritRating: 30 * CritRatingPerCritChance}
+	baseCritChance := (attacker.GetStat(stats.MeleeCrit) + spell.BonusCritRating) / (CritRatingPerCritChance * 100)
+	if critChance := spell.PhysicalCritChance(attackTable); !WithinToleranceFloat64(baseCritChance-0.1, critChance, 1e-9) {
+		t.Fatalf("Expected %f crit chance, got %f", baseCritChance-0.1, critChance)
+	}
+
+	// And 22% less crit damage, but only for crits.
+	result := &SpellResult{Target: defender, Damage: 1000, Outcome: OutcomeCrit}
+	result.applyResilienceCritDamage(attackTable)
+	if !WithinToleranceFloat64(780, result.Damage, 1e-9) {
+		t.Fatalf("Expected 780 crit damage, got %f", result.Damage)
+	}
+	result = &SpellResult{Target: defender, Damage: 1000, Outcome: OutcomeHit}
+	result.applyResilienceCritDamage(attackTable)
+	if result.Damage != 1000 {
+		t.Fatalf("Expected 1000 hit damage, got %f", result.Damage)
+	}
+}
@@ -7,7 +7,6 @@ import (
 )
 
 const MaxRage = 100.0
-const RageFactor = 453.3
 const ThreatPerRageGained = 5
 
 // OnRageGain is called any time rage is increased.
@@ -22,18 +21,17 @@ type rageBar struct {
 	onRageGain OnRageGain
 
 	RageRefundMetrics *ResourceMetrics
+
+	timeline *resourceTimeline
 }
 
 type RageBarOptions struct {
-	StartingRage   float64
-	RageMultiplier float64
-	MHSwingSpeed   float64
-	OHSwingSpeed   float64
+	StartingRage float64
+	MHSwingSpeed float64
+	OHSwingSpeed float64
 }
 
 func (unit *Unit) EnableRageBar(options RageBarOptions, onRageGain OnRageGain) {
-	rageFromDamageTakenMetrics := unit.NewRageMetrics(ActionID{OtherID: proto.OtherAction_OtherActionDamageTaken})
-
 	unit.SetCurrentPowerBar(RageBar)
 	unit.RegisterAura(Aura{
 		Label:    "RageBar",
@@ -49,21 +47,11 @@ func (unit *Unit) EnableRageBar(options RageBarOptions, onRageGain OnRageGain) {
 				return
 			}
 
-			var hitFactor float64
-			var speed float64
-			if spell.ProcMask == ProcMaskMeleeMHAuto {
-				hitFactor = 3.5
-				speed = options.MHSwingSpeed
-			} else if spell.ProcMask == ProcMaskMeleeOHAuto {
-				hitFactor = 1.75
-				speed = options.OHSwingSpeed
-			} else {
+			hitFactor := RageHitFactor(spell, result)
+			if hitFactor == 0 {
 				return
 			}
-
-			if result.Outcome.Matches(OutcomeCrit) {
-				hitFactor *= 2
-			}
+			speed := TernaryFloat64(spell.ProcMask == ProcMaskMeleeMHAuto, options.MHSwingSpeed, options.OHSwingSpeed)
 
 			damage := result.Damage
 			if result.Outcome.Matches(OutcomeDodge | OutcomeParry) {
@@ -71,10 +59,7 @@ func (unit *Unit) EnableRageBar(options RageBarOptions, onRageGain OnRageGain) {
 				damage = result.PreOutcomeDamage
 			}
 
-			// generatedRage is capped for very low damage swings
-			generatedRage := min((damage*7.5/RageFactor+hitFactor*speed)/2, damage*15/RageFactor)
-
-			generatedRage *= options.RageMultiplier
+			generatedRage := RageFromDamageDealt(damage, hitFactor, speed) * unit.PseudoStats.RageFromDamageDealtMultiplier
 
 			var metrics *ResourceMetrics
 			if spell.Cost != nil {
@@ -87,12 +72,13 @@ func (unit *Unit) EnableRageBar(options RageBarOptions, onRageGain OnRageGain) {
 			}
 			unit.AddRage(sim, generatedRage, metrics)
 		},
-		OnSpellHitTaken: func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
-			if unit.GetCurrentPowerBar() != RageBar {
-				return
-			}
-			generatedRage := result.Damage * 2.5 / RageFactor
-			unit.AddRage(sim, generatedRage, rageFromDamageTakenMetrics)
+	})
+	unit.RegisterDamageTakenGain(DamageTakenGain{
+		Label:    "Rage From Damage Taken",
+		ActionID: ActionID{OtherID: proto.OtherAction_OtherActionDamageTaken},
+		Resource: proto.ResourceType_ResourceTypeRage,
+		Amount: func(damage float64) float64 {
+			return RageFromDamageTaken(damage) * unit.PseudoStats.RageFromDamageTakenMultiplier
 		},
 	})
 
@@ -127,10 +113,13 @@ func (rb *rageBar) AddRage(sim *Simulation, amount float64, metrics *ResourceMet
 	metrics.AddEvent(amount, newRage-rb.currentRage)
 
 	if sim.Log != nil {
-		rb.unit.Log(sim, "Gained %0.3f rage from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, rb.currentRage, newRage)
+		rb.unit.logResource(sim, LogEventResourceGain, proto.ResourceType_ResourceTypeRage, amount, metrics, rb.currentRage, newRage)
 	}
 
 	rb.currentRage = newRage
+	if rb.timeline != nil {
+		rb.timeline.update(sim, newRage, MaxRage)
+	}
 	if !sim.Options.Interactive {
 		if rb.unit.IsUsingAPL {
 			rb.unit.Rotation.DoNextAction(sim)
@@ -149,18 +138,24 @@ func (rb *rageBar) SpendRage(sim *Simulation, amount float64, metrics *ResourceM
 	metrics.AddEvent(-amount, -amount)
 
 	if sim.Log != nil {
-		rb.unit.Log(sim, "Spent %0.3f rage from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, rb.currentRage, newRage)
+		rb.unit.logResource(sim, LogEventResourceSpend, proto.ResourceType_ResourceTypeRage, amount, metrics, rb.currentRage, newRage)
 	}
 
 	rb.currentRage = newRage
+	if rb.timeline != nil {
+		rb.timeline.update(sim, newRage, MaxRage)
+	}
 }
 
-func (rb *rageBar) reset(_ *Simulation) {
+func (rb *rageBar) reset(sim *Simulation) {
 	if rb.unit == nil {
 		return
 	}
 
 	rb.currentRage = rb.startingRage
+	if rb.timeline != nil {
+		rb.timeline.update(sim, rb.currentRage, MaxRage)
+	}
 }
 
 func (rb *rageBar) doneIteration() {
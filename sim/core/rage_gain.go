@@ -0,0 +1,49 @@
+package core
+
+// Rage conversion value at level 80, which scales damage into rage.
+const RageFactor = 453.3
+
+// Hit factors for rage from white hits, doubled for crits.
+const (
+	RageHitFactorMainHand = 3.5
+	RageHitFactorOffHand  = 1.75
+)
+
+// Rage per RageFactor damage dealt by white hits or taken from any hit.
+const (
+	RagePerDamageDealt = 7.5
+	RagePerDamageTaken = 2.5
+)
+
+// Returns the rage generated by a white hit for the given damage, using the
+// base swing speed of the weapon, i.e. ignoring haste. Dodged and parried hits
+// generate rage based on the damage they would have done.
+func RageFromDamageDealt(damage float64, hitFactor float64, swingSpeed float64) float64 {
+	fromDamage := damage * RagePerDamageDealt / RageFactor
+	// Capped for very low damage swings.
+	return min((fromDamage+hitFactor*swingSpeed)/2, fromDamage*2)
+}
+
+// Returns the hit factor of a white hit, or 0 if the spell doesn't generate
+// rage when dealing damage.
+func RageHitFactor(spell *Spell, result *SpellResult) float64 {
+	var hitFactor float64
+	switch spell.ProcMask {
+	case ProcMaskMeleeMHAuto:
+		hitFactor = RageHitFactorMainHand
+	case ProcMaskMeleeOHAuto:
+		hitFactor = RageHitFactorOffHand
+	default:
+		return 0
+	}
+
+	if result.Outcome.Matches(OutcomeCrit) {
+		hitFactor *= 2
+	}
+	return hitFactor
+}
+
+// Returns the rage generated by taking the given damage after mitigation.
+func RageFromDamageTaken(damage float64) float64 {
+	return damage * RagePerDamageTaken / RageFactor
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestRageFromDamageDealt(t *testing.T) {
+	mhAuto := &Spell{ProcMask: ProcMaskMeleeMHAuto}
+	ohAuto := &Spell{ProcMask: ProcMaskMeleeOHAuto}
+	special := &Spell{ProcMask: ProcMaskMeleeMHSpecial}
+
+	testCases := []struct {
+		name     string
+		spell    *Spell
+		outcome  HitOutcome
+		damage   float64
+		expected float64
+	}{
+		{"MainHandHit", mhAuto, OutcomeHit, 1000, (1000*7.5/453.3 + 3.5*2.6) / 2},
+		{"MainHandCrit", mhAuto, OutcomeCrit, 1000, (1000*7.5/453.3 + 7*2.6) / 2},
+		{"OffHandHit", ohAuto, OutcomeHit, 1000, (1000*7.5/453.3 + 1.75*2.6) / 2},
+		{"LowDamageCap", mhAuto, OutcomeHit, 10, 10 * 15 / 453.3},
+		{"Special", special, OutcomeHit, 1000, 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			hitFactor := RageHitFactor(testCase.spell, &SpellResult{Outcome: testCase.outcome})
+			var rage float64
+			if hitFactor != 0 {
+				rage = RageFromDamageDealt(testCase.damage, hitFactor, 2.6)
+			}
+			if !WithinToleranceFloat64(testCase.expected, rage, 0.0001) {
+				t.Fatalf("Expected %0.4f rage, got %0.4f", testCase.expected, rage)
+			}
+		})
+	}
+}
+
+func TestRageFromDamageTaken(t *testing.T) {
+	if rage := RageFromDamageTaken(453.3); !WithinToleranceFloat64(2.5, rage, 0.0001) {
+		t.Fatalf("Expected 2.5 rage, got %0.4f", rage)
+	}
+}
@@ -291,11 +291,16 @@ func (raid *Raid) applyCharacterEffects(raidConfig *proto.Raid) *proto.RaidStats
 
 			char := player.GetCharacter()
 			char.EnableHealthBar()
+			if playerConfig.PvpTrinket {
+				char.enablePvPTrinket()
+			}
 			char.trackChanceOfDeath(playerConfig.HealingModel)
+			char.enableDeath(playerConfig.DeathOptions)
 			partyStats.Players[char.PartyIndex] = char.applyAllEffects(player, raidBuffs, partyBuffs, individualBuffs)
 
 			for _, pet := range char.Pets {
 				pet.EnableHealthBar()
+				pet.enableDeath(playerConfig.DeathOptions)
 			}
 		}
 
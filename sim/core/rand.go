@@ -71,3 +71,24 @@ func (sm *SplitMix64) Int63() int64 {
 func (sm *SplitMix64) Uint64() uint64 {
 	return sm.Next()
 }
+
+// Mirrors every value of the wrapped Rand, for antithetic iterations.
+type antitheticRand struct {
+	Rand
+}
+
+func (ar antitheticRand) Next() uint64 {
+	return ^ar.Rand.Next()
+}
+
+func (ar antitheticRand) NextFloat64() float64 {
+	return 1 - 0x1p-53 - ar.Rand.NextFloat64()
+}
+
+func (ar antitheticRand) Int63() int64 {
+	return int64(ar.Next() & math.MaxInt64)
+}
+
+func (ar antitheticRand) Uint64() uint64 {
+	return ar.Next()
+}
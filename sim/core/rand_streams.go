@@ -0,0 +1,119 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Labels for Simulation.RandomFloat() which are intentionally shared by many
+// spells, e.g. attack table rolls. All other labels should belong to a single
+// spell or effect, so that its rolls are independent of everything else.
+var sharedRandomStreams = map[string]bool{}
+
+func RegisterSharedRandomStreams(labels ...string) {
+	for _, label := range labels {
+		sharedRandomStreams[label] = true
+	}
+}
+
+func init() {
+	RegisterSharedRandomStreams(
+		"White Hit Table",
+		"Enemy White Hit Table",
+		"Physical Crit Roll",
+		"Magical Crit Roll",
+		"Healing Crit Roll",
+		"Fixed Crit Roll",
+		"Snapshot Crit Roll",
+		"Binary Resist",
+		"Partial Resist",
+		"Damage Roll",
+		"Weapon Base Damage",
+		"Enemy Weapon Damage",
+		"Energy Tick",
+		"FixedAura",
+		"FixedAuraDur",
+		"SwingResetWeapon",
+		"SwingResetDelay",
+		"Healing Cadence Variation Sign",
+		"Healing Cadence Variation Magnitude",
+		"sim duration",
+	)
+}
+
+// Records which spell owns each declared random stream, so that two different
+// spells can't accidentally roll on the same stream.
+func (env *Environment) registerRandomStreams(spell *Spell) {
+	for _, label := range spell.RandomStreams {
+		if sharedRandomStreams[label] {
+			panic(fmt.Sprintf("%s declares shared random stream %q, only spell-specific streams should be declared", spell.ActionID, label))
+		}
+
+		if env.randomStreamOwners == nil {
+			env.randomStreamOwners = make(map[string]ActionID)
+		}
+		if owner, ok := env.randomStreamOwners[label]; ok && !owner.SameActionIgnoreTag(spell.ActionID) {
+			panic(fmt.Sprintf("Random stream %q is used by both %s and %s", label, owner, spell.ActionID))
+		}
+		env.randomStreamOwners[label] = spell.ActionID
+	}
+}
+
+const randomStreamBuckets = 10
+
+// Critical value of the chi-square distribution with randomStreamBuckets-1
+// degrees of freedom, at p = 0.001.
+const randomStreamChiSquareLimit = 27.877
+
+// Per-label roll statistics, only collected with SimOptions.rng_diagnostics.
+type randomStreamStats struct {
+	rolls   int64
+	buckets [randomStreamBuckets]int64
+}
+
+func (sim *Simulation) recordRandomRoll(label string, roll float64) {
+	stats, ok := sim.randomStreamStats[label]
+	if !ok {
+		stats = &randomStreamStats{}
+		sim.randomStreamStats[label] = stats
+	}
+	stats.rolls++
+	stats.buckets[min(int(roll*randomStreamBuckets), randomStreamBuckets-1)]++
+}
+
+func (stats *randomStreamStats) chiSquare() float64 {
+	expected := float64(stats.rolls) / randomStreamBuckets
+	chiSquare := 0.0
+	for _, observed := range stats.buckets {
+		diff := float64(observed) - expected
+		chiSquare += diff * diff / expected
+	}
+	return chiSquare
+}
+
+func (sim *Simulation) randomStreamsProto() []*proto.RandomStreamStats {
+	labels := make([]string, 0, len(sim.randomStreamStats))
+	for label := range sim.randomStreamStats {
+		labels = append(labels, label)
+	}
+	slices.Sort(labels)
+
+	return MapSlice(labels, func(label string) *proto.RandomStreamStats {
+		stats := sim.randomStreamStats[label]
+		owner, isOwned := sim.randomStreamOwners[label]
+
+		protoStats := &proto.RandomStreamStats{
+			Label:      label,
+			Rolls:      stats.rolls,
+			ChiSquare:  stats.chiSquare(),
+			Registered: isOwned || sharedRandomStreams[label],
+		}
+		protoStats.Uniform = protoStats.ChiSquare < randomStreamChiSquareLimit
+		if isOwned {
+			protoStats.Owner = owner.ToProto()
+		}
+		return protoStats
+	})
+}
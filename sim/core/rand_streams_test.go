@@ -0,0 +1,49 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestRandomStreamRegistry(t *testing.T) {
+	env := &Environment{}
+	env.registerRandomStreams(&Spell{ActionID: ActionID{SpellID: 1}, RandomStreams: []string{"Proc A"}})
+	env.registerRandomStreams(&Spell{ActionID: ActionID{SpellID: 1, Tag: 2}, RandomStreams: []string{"Proc A"}})
+
+	expectPanic := func(spell *Spell) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected panic when registering %s", spell.ActionID)
+			}
+		}()
+		env.registerRandomStreams(spell)
+	}
+	expectPanic(&Spell{ActionID: ActionID{SpellID: 2}, RandomStreams: []string{"Proc A"}})
+	expectPanic(&Spell{ActionID: ActionID{SpellID: 3}, RandomStreams: []string{"White Hit Table"}})
+}
+
+func TestRandomStreamDiagnostics(t *testing.T) {
+	sim := newSimWithEnv(&Environment{}, &proto.SimOptions{RandomSeed: 1, RngDiagnostics: true})
+	sim.randomStreamOwners = map[string]ActionID{"Uniform": {SpellID: 1}}
+
+	for i := 0; i < 10000; i++ {
+		sim.RandomFloat("Uniform")
+	}
+	// Simulates a bug where a stream keeps getting reseeded to the same value.
+	for i := 0; i < 1000; i++ {
+		sim.recordRandomRoll("Correlated", 0.42)
+	}
+
+	streams := sim.randomStreamsProto()
+	if len(streams) != 2 {
+		t.Fatalf("Expected 2 streams, got %d", len(streams))
+	}
+	correlated, uniform := streams[0], streams[1]
+	if !uniform.Uniform || !uniform.Registered || uniform.Rolls != 10000 || uniform.Owner.GetSpellId() != 1 {
+		t.Errorf("Unexpected stats for uniform stream: %v", uniform)
+	}
+	if correlated.Uniform || correlated.Registered {
+		t.Errorf("Unexpected stats for correlated stream: %v", correlated)
+	}
+}
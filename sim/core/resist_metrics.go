@@ -0,0 +1,68 @@
+package core
+
+import (
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+// Resists against magic damage taken by a player, per school, aggregated
+// across iterations.
+type resistMetrics struct {
+	// Indexed by stats.SchoolIndex.
+	hits        [stats.SchoolLen]int
+	resistedSum [stats.SchoolLen]float64
+	fullResists [stats.SchoolLen]int
+}
+
+func enableResistMetrics(unit *Unit) {
+	if unit.Metrics.resists != nil {
+		return
+	}
+	unit.Metrics.resists = &resistMetrics{}
+
+	onDamageTaken := func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
+		if !result.Landed() || !spell.SpellSchool.Matches(SpellSchoolMagic) || spell.Flags.Matches(SpellFlagIgnoreResists) {
+			return
+		}
+		aura.Unit.Metrics.resists.addHit(spell.SchoolIndex, result.ResistanceMultiplier)
+	}
+
+	MakePermanent(unit.RegisterAura(Aura{
+		Label:                 "Resist Tracker",
+		OnSpellHitTaken:       onDamageTaken,
+		OnPeriodicDamageTaken: onDamageTaken,
+	}))
+}
+
+func (rm *resistMetrics) addHit(school stats.SchoolIndex, resistanceMultiplier float64) {
+	rm.hits[school]++
+	rm.resistedSum[school] += 1 - resistanceMultiplier
+	if resistanceMultiplier == 0 {
+		rm.fullResists[school]++
+	}
+}
+
+func (rm *resistMetrics) merge(other *resistMetrics) {
+	for i := range rm.hits {
+		rm.hits[i] += other.hits[i]
+		rm.resistedSum[i] += other.resistedSum[i]
+		rm.fullResists[i] += other.fullResists[i]
+	}
+}
+
+func (rm *resistMetrics) ToProto(numIterations float64) []*proto.ResistMetrics {
+	var protoMetrics []*proto.ResistMetrics
+	for i, hits := range rm.hits {
+		if hits == 0 {
+			continue
+		}
+		protoMetrics = append(protoMetrics, &proto.ResistMetrics{
+			// Schools are in the same order, but SchoolIndex starts at None.
+			School:         proto.SpellSchool(i - int(stats.SchoolIndexPhysical)),
+			HitsAvg:        float64(hits) / numIterations,
+			ResistRate:     rm.resistedSum[i] / float64(hits),
+			FullResistRate: float64(rm.fullResists[i]) / float64(hits),
+		})
+	}
+	return protoMetrics
+}
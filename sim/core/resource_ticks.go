@@ -0,0 +1,42 @@
+package core
+
+import (
+	"math"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func (sim *Simulation) resourceTickAlignment() proto.ResourceTickAlignment {
+	if sim.Options.ResourceTicks == nil {
+		return proto.ResourceTickAlignment_ResourceTickAlignmentDefault
+	}
+	return sim.Options.ResourceTicks.Alignment
+}
+
+func (sim *Simulation) energyTickDuration() time.Duration {
+	if ticks := sim.Options.ResourceTicks; ticks != nil && ticks.EnergyTickMs > 0 {
+		return time.Duration(ticks.EnergyTickMs) * time.Millisecond
+	}
+	return EnergyTickDuration
+}
+
+// Returns the first regen tick after startAt, for resources which tick every
+// tickDuration. Returns false if ticks aren't aligned, in which case each
+// resource picks its own first tick.
+func (sim *Simulation) alignedResourceTick(startAt time.Duration, tickDuration time.Duration) (time.Duration, bool) {
+	var phase time.Duration
+	switch sim.resourceTickAlignment() {
+	case proto.ResourceTickAlignment_ResourceTickAlignmentShared:
+		phase = time.Duration(sim.resourceTickPhase * float64(tickDuration))
+	case proto.ResourceTickAlignment_ResourceTickAlignmentPull:
+		phase = 0
+	default:
+		return 0, false
+	}
+
+	// Ticks land on phase + k*tickDuration, and startAt can be negative during
+	// the prepull.
+	ticksBefore := math.Floor(float64(startAt-phase) / float64(tickDuration))
+	return phase + time.Duration(ticksBefore+1)*tickDuration, true
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestAlignedResourceTick(t *testing.T) {
+	sim := SetupFakeSim()
+
+	if _, ok := sim.alignedResourceTick(0, time.Second*2); ok {
+		t.Fatalf("Expected unaligned ticks by default")
+	}
+
+	sim.Options.ResourceTicks = &proto.ResourceTickOptions{Alignment: proto.ResourceTickAlignment_ResourceTickAlignmentPull}
+	for _, testCase := range []struct{ startAt, expected time.Duration }{
+		{-time.Second * 3, -time.Second * 2},
+		{0, time.Second * 2},
+		{time.Second, time.Second * 2},
+	} {
+		if firstTick, _ := sim.alignedResourceTick(testCase.startAt, time.Second*2); firstTick != testCase.expected {
+			t.Errorf("Expected the first tick after %s at %s, got %s", testCase.startAt, testCase.expected, firstTick)
+		}
+	}
+
+	sim.Options.ResourceTicks.Alignment = proto.ResourceTickAlignment_ResourceTickAlignmentShared
+	sim.resourceTickPhase = 0.25
+	if firstTick, _ := sim.alignedResourceTick(0, time.Second*2); firstTick != time.Millisecond*500 {
+		t.Errorf("Expected the first shared tick at 0.5s, got %s", firstTick)
+	}
+}
+
+func TestServerEnergyTicks(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	sim.Options.ResourceTicks = &proto.ResourceTickOptions{
+		EnergyTickMs: 2000,
+		Alignment:    proto.ResourceTickAlignment_ResourceTickAlignmentPull,
+	}
+
+	fa.EnableEnergyBar(100, func(sim *Simulation) {})
+	fa.energyBar.enable(sim, 0)
+	if fa.NextEnergyTickAt() != time.Second*2 || fa.energyPerTick() != 20 {
+		t.Fatalf("Expected 20 energy at 2s, got %0.1f at %s", fa.energyPerTick(), fa.NextEnergyTickAt())
+	}
+
+	value := &APLValueEnergyTimeToNextTick{unit: &fa.Unit}
+	sim.CurrentTime = time.Millisecond * 500
+	if timeToTick := value.GetDuration(sim); timeToTick != time.Millisecond*1500 {
+		t.Fatalf("Expected the next tick in 1.5s, got %s", timeToTick)
+	}
+
+	fa.SpendEnergy(sim, 10, fa.energyBar.regenMetrics)
+	if phase := fa.energyBar.tickMetrics.spendPhaseSum; phase != 0.25 {
+		t.Fatalf("Expected a spend a quarter of the way through the tick, got %0.2f", phase)
+	}
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Tracks the level of a unit's mana, energy, rage or runic power over time,
+// for diagnosing capping and starvation. The bar calls update after every
+// change, so the level is exact between updates rather than sampled.
+type resourceTimeline struct {
+	resourceType proto.ResourceType
+
+	// Values for the current iteration.
+	level      float64
+	capped     bool
+	lastUpdate time.Duration
+	cappedTime time.Duration
+
+	// Aggregate values. These are updated after each iteration.
+	iterations    int32
+	cappedSeconds float64
+	levelSeconds  []float64 // Level integrated over each second of the encounter.
+	seconds       []float64 // Time recorded in each second, which is less than the number of iterations near the end of varying length fights.
+}
+
+// Records a timeline for each resource bar of each unit in the raid, see
+// SimOptions.resource_timelines. Bars skip recording when they have none.
+func (env *Environment) enableResourceTimelines() {
+	for _, unit := range env.Raid.AllUnits {
+		if unit.HasManaBar() {
+			unit.manaBar.timeline = unit.Metrics.newResourceTimeline(proto.ResourceType_ResourceTypeMana)
+		}
+		if unit.HasEnergyBar() {
+			unit.energyBar.timeline = unit.Metrics.newResourceTimeline(proto.ResourceType_ResourceTypeEnergy)
+		}
+		if unit.HasRageBar() {
+			unit.rageBar.timeline = unit.Metrics.newResourceTimeline(proto.ResourceType_ResourceTypeRage)
+		}
+		if unit.HasRunicPowerBar() {
+			unit.runicPowerBar.timeline = unit.Metrics.newResourceTimeline(proto.ResourceType_ResourceTypeRunicPower)
+		}
+	}
+}
+
+func (unitMetrics *UnitMetrics) newResourceTimeline(resourceType proto.ResourceType) *resourceTimeline {
+	rt := &resourceTimeline{
+		resourceType: resourceType,
+	}
+	unitMetrics.resourceTimelines = append(unitMetrics.resourceTimelines, rt)
+	return rt
+}
+
+// Should be called before the bar sets its starting level.
+func (rt *resourceTimeline) reset() {
+	rt.lastUpdate = 0
+	rt.cappedTime = 0
+}
+
+// Records the level since the last update, then sets the new level. Changes
+// during the prepull only set the level at the start of the encounter.
+func (rt *resourceTimeline) update(sim *Simulation, level float64, maxLevel float64) {
+	rt.advance(sim.CurrentTime)
+	rt.level = level
+	rt.capped = level >= maxLevel
+}
+
+func (rt *resourceTimeline) advance(until time.Duration) {
+	from := max(rt.lastUpdate, 0)
+	rt.lastUpdate = until
+	if until <= from {
+		return
+	}
+
+	if rt.capped {
+		rt.cappedTime += until - from
+	}
+
+	for from < until {
+		bucket := int(from / time.Second)
+		end := min(until, time.Duration(bucket+1)*time.Second)
+		for len(rt.seconds) <= bucket {
+			rt.levelSeconds = append(rt.levelSeconds, 0)
+			rt.seconds = append(rt.seconds, 0)
+		}
+
+		seconds := (end - from).Seconds()
+		rt.levelSeconds[bucket] += rt.level * seconds
+		rt.seconds[bucket] += seconds
+		from = end
+	}
+}
+
+func (rt *resourceTimeline) doneIteration(sim *Simulation) {
+	rt.advance(sim.CurrentTime)
+	rt.iterations++
+	rt.cappedSeconds += rt.cappedTime.Seconds()
+}
+
+func (rt *resourceTimeline) merge(other *resourceTimeline) {
+	rt.iterations += other.iterations
+	rt.cappedSeconds += other.cappedSeconds
+	for len(rt.seconds) < len(other.seconds) {
+		rt.levelSeconds = append(rt.levelSeconds, 0)
+		rt.seconds = append(rt.seconds, 0)
+	}
+	for i := range other.seconds {
+		rt.levelSeconds[i] += other.levelSeconds[i]
+		rt.seconds[i] += other.seconds[i]
+	}
+}
+
+// Wasted resources are summed from the unit's ResourceMetrics of the same type.
+func (rt *resourceTimeline) ToProto(resources []*ResourceMetrics) *proto.ResourceTimelineMetrics {
+	n := float64(max(rt.iterations, 1))
+
+	protoMetrics := &proto.ResourceTimelineMetrics{
+		Type:             rt.resourceType,
+		CappedSecondsAvg: rt.cappedSeconds / n,
+		Timeline:         make([]float64, len(rt.seconds)),
+	}
+
+	var levelSeconds, seconds float64
+	for i := range rt.seconds {
+		if rt.seconds[i] > 0 {
+			protoMetrics.Timeline[i] = rt.levelSeconds[i] / rt.seconds[i]
+		}
+		levelSeconds += rt.levelSeconds[i]
+		seconds += rt.seconds[i]
+	}
+	if seconds > 0 {
+		protoMetrics.LevelAvg = levelSeconds / seconds
+	}
+
+	for _, resource := range resources {
+		if resource.Type == rt.resourceType {
+			protoMetrics.WastedAvg += (resource.Gain - resource.ActualGain) / n
+		}
+	}
+
+	return protoMetrics
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestResourceTimeline(t *testing.T) {
+	sim := &Simulation{}
+	rt := &resourceTimeline{resourceType: proto.ResourceType_ResourceTypeEnergy}
+
+	// Capped from the prepull until 0.5s, then 50 energy until 2s.
+	rt.reset()
+	sim.CurrentTime = -time.Second
+	rt.update(sim, 100, 100)
+	sim.CurrentTime = time.Millisecond * 500
+	rt.update(sim, 50, 100)
+	sim.CurrentTime = time.Second * 2
+	rt.doneIteration(sim)
+
+	// A shorter iteration at 20 energy.
+	rt.reset()
+	sim.CurrentTime = 0
+	rt.update(sim, 20, 100)
+	sim.CurrentTime = time.Second
+	rt.doneIteration(sim)
+
+	resources := []*ResourceMetrics{
+		{Type: proto.ResourceType_ResourceTypeEnergy, Gain: 30, ActualGain: 10},
+		{Type: proto.ResourceType_ResourceTypeRage, Gain: 30, ActualGain: 0},
+	}
+	metrics := rt.ToProto(resources)
+
+	if expected := []float64{(75 + 20) / 2.0, 50}; !slices.Equal(metrics.Timeline, expected) {
+		t.Errorf("Expected timeline %v, got %v", expected, metrics.Timeline)
+	}
+	if expected := (75 + 50 + 20) / 3.0; metrics.LevelAvg != expected {
+		t.Errorf("Expected level avg %f, got %f", expected, metrics.LevelAvg)
+	}
+	if metrics.CappedSecondsAvg != 0.25 {
+		t.Errorf("Expected capped seconds avg 0.25, got %f", metrics.CappedSecondsAvg)
+	}
+	if metrics.WastedAvg != 10 {
+		t.Errorf("Expected wasted avg 10, got %f", metrics.WastedAvg)
+	}
+}
+
+func TestEnableResourceTimelines(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+
+	// Off by default, so the bar doesn't record anything.
+	fa.EnableEnergyBar(100, func(sim *Simulation) {})
+	if fa.energyBar.timeline != nil || len(fa.Metrics.resourceTimelines) != 0 {
+		t.Fatalf("Expected no resource timelines by default")
+	}
+
+	sim.enableResourceTimelines()
+	if fa.energyBar.timeline == nil || len(fa.Metrics.resourceTimelines) != 1 {
+		t.Fatalf("Expected an energy timeline, got %d timelines", len(fa.Metrics.resourceTimelines))
+	}
+	if resourceType := fa.Metrics.resourceTimelines[0].resourceType; resourceType != proto.ResourceType_ResourceTypeEnergy {
+		t.Errorf("Expected an energy timeline, got %s", resourceType)
+	}
+}
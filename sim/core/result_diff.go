@@ -0,0 +1,258 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"runtime/debug"
+	"slices"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Compares the players of two completed raid sims, see proto.DiffResultsRequest.
+func diffResults(request *proto.DiffResultsRequest) (result *proto.DiffResultsResult) {
+	defer func() {
+		if err := recover(); err != nil {
+			result = &proto.DiffResultsResult{
+				ErrorResult: fmt.Sprintf("%v\nStack Trace:\n%s", err, debug.Stack()),
+			}
+		}
+	}()
+
+	base, compare := request.Base, request.Compare
+	if base.GetErrorResult() != "" {
+		return &proto.DiffResultsResult{ErrorResult: "Base result has an error: " + base.ErrorResult}
+	}
+	if compare.GetErrorResult() != "" {
+		return &proto.DiffResultsResult{ErrorResult: "Compare result has an error: " + compare.ErrorResult}
+	}
+	if base.GetRaidMetrics() == nil || compare.GetRaidMetrics() == nil {
+		return &proto.DiffResultsResult{ErrorResult: "Both results are required"}
+	}
+
+	baseRun := newDiffRun(base)
+	compareRun := newDiffRun(compare)
+
+	result = &proto.DiffResultsResult{}
+	for partyIdx, party := range base.RaidMetrics.Parties {
+		if partyIdx >= len(compare.RaidMetrics.Parties) {
+			break
+		}
+		compareParty := compare.RaidMetrics.Parties[partyIdx]
+		for playerIdx, player := range party.Players {
+			if playerIdx >= len(compareParty.Players) {
+				break
+			}
+			comparePlayer := compareParty.Players[playerIdx]
+			// Empty slots have no metrics in either sim.
+			if player.Dps == nil && comparePlayer.Dps == nil {
+				continue
+			}
+			result.Players = append(result.Players, diffUnits(baseRun, compareRun, player, comparePlayer))
+		}
+	}
+	return result
+}
+
+// Converts the totals of one sim into per-iteration values.
+type diffRun struct {
+	iterations float64
+	duration   float64
+}
+
+func newDiffRun(result *proto.RaidSimResult) diffRun {
+	// The raid DPS is recorded every iteration, so its histogram counts them.
+	var n int32
+	if dps := result.RaidMetrics.Dps; dps != nil {
+		for _, count := range dps.Hist {
+			n += count
+		}
+	}
+	return diffRun{
+		iterations: float64(max(n, 1)),
+		duration:   result.AvgIterationDuration,
+	}
+}
+
+func (run diffRun) perSecond(total float64) float64 {
+	if run.duration <= 0 {
+		return 0
+	}
+	return total / run.iterations / run.duration
+}
+
+func diffUnits(baseRun, compareRun diffRun, base, compare *proto.UnitMetrics) *proto.UnitDiff {
+	diff := &proto.UnitDiff{
+		Name:       compare.Name,
+		UnitIndex:  compare.UnitIndex,
+		BaseDps:    base.GetDps().GetAvg(),
+		CompareDps: compare.GetDps().GetAvg(),
+		Actions:    diffActions(baseRun, compareRun, base.Actions, compare.Actions),
+		Auras:      diffAuras(base.Auras, compare.Auras),
+		Resources:  diffResources(baseRun, compareRun, base.Resources, compare.Resources),
+	}
+	if diff.Name == "" {
+		diff.Name = base.Name
+	}
+
+	// Pets are matched by name, since they're only listed once enabled.
+	for _, basePet := range base.Pets {
+		idx := slices.IndexFunc(compare.Pets, func(pet *proto.UnitMetrics) bool { return pet.Name == basePet.Name })
+		if idx == -1 {
+			diff.Pets = append(diff.Pets, diffUnits(baseRun, compareRun, basePet, &proto.UnitMetrics{Name: basePet.Name}))
+		} else {
+			diff.Pets = append(diff.Pets, diffUnits(baseRun, compareRun, basePet, compare.Pets[idx]))
+		}
+	}
+	for _, comparePet := range compare.Pets {
+		if !slices.ContainsFunc(base.Pets, func(pet *proto.UnitMetrics) bool { return pet.Name == comparePet.Name }) {
+			diff.Pets = append(diff.Pets, diffUnits(baseRun, compareRun, &proto.UnitMetrics{}, comparePet))
+		}
+	}
+	return diff
+}
+
+type actionTotals struct {
+	damage float64
+	casts  float64
+}
+
+func sumActions(actions []*proto.ActionMetrics) (map[string]actionTotals, float64) {
+	totals := make(map[string]actionTotals, len(actions))
+	var totalDamage float64
+	for _, action := range actions {
+		key := actionIDKey(action.Id)
+		t := totals[key]
+		for _, target := range action.Targets {
+			t.damage += target.Damage
+			t.casts += float64(target.Casts)
+		}
+		totals[key] = t
+		totalDamage += t.damage
+	}
+	return totals, totalDamage
+}
+
+func diffActions(baseRun, compareRun diffRun, base, compare []*proto.ActionMetrics) []*proto.ActionDiff {
+	baseTotals, baseDamage := sumActions(base)
+	compareTotals, compareDamage := sumActions(compare)
+
+	share := func(damage, total float64) float64 {
+		if total <= 0 {
+			return 0
+		}
+		return damage / total
+	}
+
+	var diffs []*proto.ActionDiff
+	for _, id := range unionActionIDs(base, compare, func(action *proto.ActionMetrics) *proto.ActionID { return action.Id }) {
+		key := actionIDKey(id)
+		b, c := baseTotals[key], compareTotals[key]
+		diffs = append(diffs, &proto.ActionDiff{
+			Id:                 id,
+			BaseDamageShare:    share(b.damage, baseDamage),
+			CompareDamageShare: share(c.damage, compareDamage),
+			BaseDps:            baseRun.perSecond(b.damage),
+			CompareDps:         compareRun.perSecond(c.damage),
+			BaseCastsAvg:       b.casts / baseRun.iterations,
+			CompareCastsAvg:    c.casts / compareRun.iterations,
+		})
+	}
+	sortByChange(diffs, func(diff *proto.ActionDiff) float64 { return diff.CompareDps - diff.BaseDps })
+	return diffs
+}
+
+func diffAuras(base, compare []*proto.AuraMetrics) []*proto.AuraDiff {
+	uptimes := func(auras []*proto.AuraMetrics) map[string]float64 {
+		m := make(map[string]float64, len(auras))
+		for _, aura := range auras {
+			m[actionIDKey(aura.Id)] = aura.UptimePercentAvg
+		}
+		return m
+	}
+	baseUptimes, compareUptimes := uptimes(base), uptimes(compare)
+
+	var diffs []*proto.AuraDiff
+	for _, id := range unionActionIDs(base, compare, func(aura *proto.AuraMetrics) *proto.ActionID { return aura.Id }) {
+		key := actionIDKey(id)
+		diffs = append(diffs, &proto.AuraDiff{
+			Id:                   id,
+			BaseUptimePercent:    baseUptimes[key],
+			CompareUptimePercent: compareUptimes[key],
+		})
+	}
+	sortByChange(diffs, func(diff *proto.AuraDiff) float64 { return diff.CompareUptimePercent - diff.BaseUptimePercent })
+	return diffs
+}
+
+func diffResources(baseRun, compareRun diffRun, base, compare []*proto.ResourceMetrics) []*proto.ResourceDiff {
+	// The same action can gain several resources, e.g. Rune Strike.
+	resourceKey := func(id *proto.ActionID, resourceType proto.ResourceType) string {
+		return fmt.Sprintf("%s-%d", actionIDKey(id), resourceType)
+	}
+	gains := func(resources []*proto.ResourceMetrics) map[string]float64 {
+		m := make(map[string]float64, len(resources))
+		for _, resource := range resources {
+			m[resourceKey(resource.Id, resource.Type)] += resource.ActualGain
+		}
+		return m
+	}
+	baseGains, compareGains := gains(base), gains(compare)
+
+	var diffs []*proto.ResourceDiff
+	seen := make(map[string]bool)
+	for _, resource := range append(slices.Clone(base), compare...) {
+		key := resourceKey(resource.Id, resource.Type)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		diffs = append(diffs, &proto.ResourceDiff{
+			Id:             resource.Id,
+			Type:           resource.Type,
+			BaseGainAvg:    baseGains[key] / baseRun.iterations,
+			CompareGainAvg: compareGains[key] / compareRun.iterations,
+		})
+	}
+	sortByChange(diffs, func(diff *proto.ResourceDiff) float64 { return diff.CompareGainAvg - diff.BaseGainAvg })
+	return diffs
+}
+
+// Returns the IDs from both lists, without duplicates, in order of first
+// appearance.
+func unionActionIDs[T any](base, compare []T, getID func(T) *proto.ActionID) []*proto.ActionID {
+	var ids []*proto.ActionID
+	seen := make(map[string]bool)
+	for _, entry := range append(slices.Clone(base), compare...) {
+		id := getID(entry)
+		key := actionIDKey(id)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func actionIDKey(id *proto.ActionID) string {
+	if id == nil {
+		return ""
+	}
+	bytes, _ := googleProto.MarshalOptions{Deterministic: true}.Marshal(id)
+	return string(bytes)
+}
+
+// Sorts by the size of the change, largest first. Ties keep their order.
+func sortByChange[T any](diffs []T, change func(T) float64) {
+	slices.SortStableFunc(diffs, func(a, b T) int {
+		ca, cb := math.Abs(change(a)), math.Abs(change(b))
+		if ca > cb {
+			return -1
+		} else if ca < cb {
+			return 1
+		}
+		return 0
+	})
+}
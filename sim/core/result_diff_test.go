@@ -0,0 +1,99 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func diffTestResult(iterations int32, actions []*proto.ActionMetrics, auras []*proto.AuraMetrics, resources []*proto.ResourceMetrics) *proto.RaidSimResult {
+	return &proto.RaidSimResult{
+		RaidMetrics: &proto.RaidMetrics{
+			Dps: &proto.DistributionMetrics{Hist: map[int32]int32{1000: iterations}},
+			Parties: []*proto.PartyMetrics{{
+				Players: []*proto.UnitMetrics{{
+					Name:      "Player",
+					Dps:       &proto.DistributionMetrics{Avg: 1000},
+					Actions:   actions,
+					Auras:     auras,
+					Resources: resources,
+				}},
+			}},
+		},
+		AvgIterationDuration: 100,
+	}
+}
+
+func TestDiffResults(t *testing.T) {
+	fireball := &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: 1}}
+	frostbolt := &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: 2}}
+	buff := &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: 3}}
+
+	// Totals are summed over iterations: 10 iterations in the base, 20 in the
+	// compare.
+	base := diffTestResult(10,
+		[]*proto.ActionMetrics{{Id: fireball, Targets: []*proto.TargetedActionMetrics{{Casts: 100, Damage: 1000000}}}},
+		[]*proto.AuraMetrics{{Id: buff, UptimePercentAvg: 50}},
+		[]*proto.ResourceMetrics{{Id: fireball, Type: proto.ResourceType_ResourceTypeMana, ActualGain: -50000}})
+	compare := diffTestResult(20,
+		[]*proto.ActionMetrics{
+			{Id: fireball, Targets: []*proto.TargetedActionMetrics{{Casts: 100, Damage: 1500000}}},
+			{Id: frostbolt, Targets: []*proto.TargetedActionMetrics{{Casts: 50, Damage: 500000}, {Casts: 50, Damage: 500000}}},
+		},
+		[]*proto.AuraMetrics{{Id: buff, UptimePercentAvg: 60}},
+		nil)
+
+	result := DiffResults(&proto.DiffResultsRequest{Base: base, Compare: compare})
+	if result.ErrorResult != "" {
+		t.Fatalf("Failed to diff results: %s", result.ErrorResult)
+	}
+	if len(result.Players) != 1 {
+		t.Fatalf("Expected 1 player, got %d", len(result.Players))
+	}
+	player := result.Players[0]
+
+	// Frostbolt is new, so it's the largest change and comes first.
+	if len(player.Actions) != 2 || player.Actions[0].Id.GetSpellId() != 2 {
+		t.Fatalf("Expected Frostbolt then Fireball, got %v", player.Actions)
+	}
+	frostboltDiff, fireballDiff := player.Actions[0], player.Actions[1]
+	expectFloat := func(label string, expected, actual float64) {
+		t.Helper()
+		if !WithinToleranceFloat64(expected, actual, 1e-9) {
+			t.Errorf("Expected %s of %f, got %f", label, expected, actual)
+		}
+	}
+	expectFloat("base frostbolt share", 0, frostboltDiff.BaseDamageShare)
+	expectFloat("compare frostbolt share", 0.4, frostboltDiff.CompareDamageShare)
+	expectFloat("compare frostbolt dps", 500, frostboltDiff.CompareDps)
+	expectFloat("compare frostbolt casts", 5, frostboltDiff.CompareCastsAvg)
+	expectFloat("base fireball share", 1, fireballDiff.BaseDamageShare)
+	expectFloat("base fireball dps", 1000, fireballDiff.BaseDps)
+	expectFloat("compare fireball share", 0.6, fireballDiff.CompareDamageShare)
+	expectFloat("compare fireball dps", 750, fireballDiff.CompareDps)
+	expectFloat("base fireball casts", 10, fireballDiff.BaseCastsAvg)
+	expectFloat("compare fireball casts", 5, fireballDiff.CompareCastsAvg)
+
+	if len(player.Auras) != 1 {
+		t.Fatalf("Expected 1 aura, got %d", len(player.Auras))
+	}
+	expectFloat("base uptime", 50, player.Auras[0].BaseUptimePercent)
+	expectFloat("compare uptime", 60, player.Auras[0].CompareUptimePercent)
+
+	if len(player.Resources) != 1 {
+		t.Fatalf("Expected 1 resource, got %d", len(player.Resources))
+	}
+	expectFloat("base mana", -5000, player.Resources[0].BaseGainAvg)
+	expectFloat("compare mana", 0, player.Resources[0].CompareGainAvg)
+}
+
+func TestDiffResultsError(t *testing.T) {
+	base := diffTestResult(1, nil, nil, nil)
+	compare := &proto.RaidSimResult{ErrorResult: "boom"}
+	if result := DiffResults(&proto.DiffResultsRequest{Base: base, Compare: compare}); result.ErrorResult != "Compare result has an error: boom" {
+		t.Fatalf("Expected an error for a failed sim, got %q", result.ErrorResult)
+	}
+	if result := DiffResults(&proto.DiffResultsRequest{Base: base}); result.ErrorResult == "" {
+		t.Fatalf("Expected an error for a missing result")
+	}
+}
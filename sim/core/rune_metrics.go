@@ -0,0 +1,95 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Tracks how well a unit kept its runes regenerating. A rune only regenerates
+// while spent, so time it sits ready is lost, except for up to RuneGracePeriod
+// which is taken off of its next cooldown.
+type runeMetrics struct {
+	// Values for the current iteration.
+	wastedTime  time.Duration
+	graceTime   time.Duration
+	spends      [6]int32
+	deathSpends [6]int32
+
+	// Aggregate values. These are updated after each iteration.
+	iterations       int32
+	wastedSecondsSum float64
+	graceSecondsSum  float64
+	spendsSum        [6]int32
+	deathSpendsSum   [6]int32
+}
+
+func (unitMetrics *UnitMetrics) newRuneMetrics() *runeMetrics {
+	unitMetrics.runes = &runeMetrics{}
+	return unitMetrics.runes
+}
+
+func (rm *runeMetrics) reset() {
+	rm.wastedTime = 0
+	rm.graceTime = 0
+	rm.spends = [6]int32{}
+	rm.deathSpends = [6]int32{}
+}
+
+// Should be called when a rune is spent, before its regen is launched. Runes
+// are ready from the start of the encounter, and spends during the prepull
+// don't count.
+func (rm *runeMetrics) onSpend(sim *Simulation, rp *runicPowerBar, slot int8, death bool) {
+	if sim.CurrentTime <= 0 {
+		return
+	}
+	grace := rp.RuneGraceAt(slot, sim.CurrentTime)
+	rm.graceTime += grace
+	rm.wastedTime += sim.CurrentTime - max(rp.runeMeta[slot].lastRegenTime, 0) - grace
+	rm.spends[slot]++
+	if death {
+		rm.deathSpends[slot]++
+	}
+}
+
+func (rm *runeMetrics) doneIteration(sim *Simulation, rp *runicPowerBar) {
+	// Runes which are still ready at the end of the encounter.
+	for slot := range rp.runeMeta {
+		if rp.RuneIsActive(int8(slot)) {
+			rm.wastedTime += max(0, sim.CurrentTime-max(rp.runeMeta[slot].lastRegenTime, 0)-rp.RuneGraceAt(int8(slot), sim.CurrentTime))
+		}
+	}
+
+	rm.iterations++
+	rm.wastedSecondsSum += rm.wastedTime.Seconds()
+	rm.graceSecondsSum += rm.graceTime.Seconds()
+	for slot := range rm.spends {
+		rm.spendsSum[slot] += rm.spends[slot]
+		rm.deathSpendsSum[slot] += rm.deathSpends[slot]
+	}
+}
+
+func (rm *runeMetrics) merge(other *runeMetrics) {
+	rm.iterations += other.iterations
+	rm.wastedSecondsSum += other.wastedSecondsSum
+	rm.graceSecondsSum += other.graceSecondsSum
+	for slot := range rm.spendsSum {
+		rm.spendsSum[slot] += other.spendsSum[slot]
+		rm.deathSpendsSum[slot] += other.deathSpendsSum[slot]
+	}
+}
+
+func (rm *runeMetrics) ToProto() *proto.RuneMetrics {
+	n := float64(max(rm.iterations, 1))
+	protoMetrics := &proto.RuneMetrics{
+		WastedSecondsAvg: rm.wastedSecondsSum / n,
+		GraceSecondsAvg:  rm.graceSecondsSum / n,
+		SpendsAvg:        make([]float64, len(rm.spendsSum)),
+		DeathSpendsAvg:   make([]float64, len(rm.deathSpendsSum)),
+	}
+	for slot := range rm.spendsSum {
+		protoMetrics.SpendsAvg[slot] = float64(rm.spendsSum[slot]) / n
+		protoMetrics.DeathSpendsAvg[slot] = float64(rm.deathSpendsSum[slot]) / n
+	}
+	return protoMetrics
+}
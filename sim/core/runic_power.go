@@ -8,6 +8,10 @@ import (
 	"github.com/wowsims/wotlk/sim/core/proto"
 )
 
+// A rune which is spent within this long of becoming ready regenerates that
+// much sooner, so runes don't lose regen time to small delays.
+const RuneGracePeriod = time.Millisecond * 2500
+
 type RuneChangeType int32
 
 const (
@@ -54,6 +58,9 @@ type runicPowerBar struct {
 	onRunicPowerGain OnRunicPowerGain
 
 	pa *PendingAction
+
+	timeline *resourceTimeline
+	metrics  *runeMetrics
 }
 
 // Constants for finding runes
@@ -108,6 +115,9 @@ func (rp *runicPowerBar) reset(sim *Simulation) {
 	}
 
 	rp.runeStates = baseRuneState
+	if rp.timeline != nil {
+		rp.timeline.update(sim, rp.currentRunicPower, rp.maxRunicPower)
+	}
 }
 
 func (unit *Unit) EnableRunicPowerBar(currentRunicPower float64, maxRunicPower float64, runeCD time.Duration,
@@ -125,6 +135,8 @@ func (unit *Unit) EnableRunicPowerBar(currentRunicPower float64, maxRunicPower f
 
 		onRuneChange:     onRuneChange,
 		onRunicPowerGain: onRunicPowerGain,
+
+		metrics: unit.Metrics.newRuneMetrics(),
 	}
 
 	unit.bloodRuneGainMetrics = unit.NewBloodRuneMetrics(ActionID{OtherID: proto.OtherAction_OtherActionBloodRuneGain, Tag: 1})
@@ -137,8 +149,23 @@ func (unit *Unit) HasRunicPowerBar() bool {
 	return unit.runicPowerBar.unit != nil
 }
 
-func (rp *runicPowerBar) SetRuneCd(runeCd time.Duration) {
+// SetRuneCd changes the rune cooldown. Runes which are regenerating keep
+// their progress, and regenerate the rest of the way at the new rate.
+func (rp *runicPowerBar) SetRuneCd(sim *Simulation, runeCd time.Duration) {
+	oldRuneCd := rp.runeCD
 	rp.runeCD = runeCd
+	if oldRuneCd == runeCd || rp.runeStates&allSpent == 0 {
+		return
+	}
+
+	for slot := range rp.runeMeta {
+		if rp.runeStates&isSpents[slot] == 0 || rp.runeMeta[slot].regenAt == NeverExpires {
+			continue
+		}
+		remaining := rp.runeMeta[slot].regenAt - sim.CurrentTime
+		rp.runeMeta[slot].regenAt = sim.CurrentTime + time.Duration(float64(remaining)*float64(runeCd)/float64(oldRuneCd))
+		rp.launchPA(sim, rp.runeMeta[slot].regenAt)
+	}
 }
 
 func (rp *runicPowerBar) CurrentRunicPower() float64 {
@@ -161,10 +188,13 @@ func (rp *runicPowerBar) addRunicPowerInterval(sim *Simulation, amount float64,
 	metrics.AddEvent(amount, newRunicPower-rp.currentRunicPower)
 
 	if sim.Log != nil {
-		rp.unit.Log(sim, "Gained %0.3f runic power from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, rp.currentRunicPower, newRunicPower)
+		rp.unit.logResource(sim, LogEventResourceGain, proto.ResourceType_ResourceTypeRunicPower, amount, metrics, rp.currentRunicPower, newRunicPower)
 	}
 
 	rp.currentRunicPower = newRunicPower
+	if rp.timeline != nil {
+		rp.timeline.update(sim, newRunicPower, rp.maxRunicPower)
+	}
 }
 
 func (rp *runicPowerBar) AddRunicPower(sim *Simulation, amount float64, metrics *ResourceMetrics) {
@@ -184,10 +214,13 @@ func (rp *runicPowerBar) spendRunicPower(sim *Simulation, amount float64, metric
 	metrics.AddEvent(-amount, -amount)
 
 	if sim.Log != nil {
-		rp.unit.Log(sim, "Spent %0.3f runic power from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, rp.currentRunicPower, newRunicPower)
+		rp.unit.logResource(sim, LogEventResourceSpend, proto.ResourceType_ResourceTypeRunicPower, amount, metrics, rp.currentRunicPower, newRunicPower)
 	}
 
 	rp.currentRunicPower = newRunicPower
+	if rp.timeline != nil {
+		rp.timeline.update(sim, newRunicPower, rp.maxRunicPower)
+	}
 }
 
 // DeathRuneRegenAt returns the time the given death rune will regen at.
@@ -214,7 +247,7 @@ func (rp *runicPowerBar) DeathRuneRevertAt() time.Duration {
 
 func (rp *runicPowerBar) runeGraceRemaining(sim *Simulation, slot int8) time.Duration {
 	if runeGrace := rp.CurrentRuneGrace(sim, slot); runeGrace > 0 {
-		return time.Millisecond*2500 - runeGrace
+		return RuneGracePeriod - runeGrace
 	}
 	return 0
 }
@@ -228,7 +261,7 @@ func (rp *runicPowerBar) CurrentRuneGrace(sim *Simulation, slot int8) time.Durat
 	}
 
 	if lastRegenTime < sim.CurrentTime {
-		return min(2500*time.Millisecond, sim.CurrentTime-lastRegenTime)
+		return min(RuneGracePeriod, sim.CurrentTime-lastRegenTime)
 	}
 	return 0
 }
@@ -639,6 +672,9 @@ func (rp *runicPowerBar) spendRuneMetrics(sim *Simulation, metrics *ResourceMetr
 
 func (rp *runicPowerBar) regenRune(sim *Simulation, regenAt time.Duration, slot int8) {
 	rp.runeStates ^= isSpents[slot] // unset spent flag for this rune.
+	if tl := rp.unit.Metrics.timeline; tl != nil {
+		tl.onRuneReady(slot, regenAt)
+	}
 	rp.runeMeta[slot].lastRegenTime = regenAt
 	rp.runeMeta[slot].regenAt = NeverExpires
 
@@ -672,7 +708,7 @@ func (rp *runicPowerBar) RuneGraceAt(slot int8, at time.Duration) time.Duration
 	if at <= 0 || lastRegenTime <= 0 {
 		return 0
 	}
-	return min(time.Millisecond*2500, at-lastRegenTime)
+	return min(RuneGracePeriod, at-lastRegenTime)
 }
 
 func (rp *runicPowerBar) launchRuneRegen(sim *Simulation, slot int8) {
@@ -743,6 +779,7 @@ func (rp *runicPowerBar) spendRune(sim *Simulation, firstSlot int8, metrics *Res
 	rp.runeStates |= isSpents[slot]
 
 	rp.spendRuneMetrics(sim, metrics, 1)
+	rp.recordRuneSpend(sim, slot, false, metrics.ActionID)
 	rp.launchRuneRegen(sim, slot)
 	return slot
 }
@@ -768,10 +805,19 @@ func (rp *runicPowerBar) spendDeathRune(sim *Simulation, order []int8, metrics *
 	rp.runeStates |= isSpents[slot]
 
 	rp.spendRuneMetrics(sim, metrics, 1)
+	rp.recordRuneSpend(sim, slot, true, metrics.ActionID)
 	rp.launchRuneRegen(sim, slot)
 	return slot
 }
 
+// Should be called before launching the rune's regen, which resets its grace.
+func (rp *runicPowerBar) recordRuneSpend(sim *Simulation, slot int8, death bool, actionID ActionID) {
+	rp.metrics.onSpend(sim, rp, slot, death)
+	if tl := rp.unit.Metrics.timeline; tl != nil {
+		tl.onRuneSpent(sim, slot, death, actionID)
+	}
+}
+
 // findReadyDeathRune returns the slot of first available death rune in the order given.
 func (rp *runicPowerBar) findReadyDeathRune(order []int8) int8 {
 	for _, slot := range order {
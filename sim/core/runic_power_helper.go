@@ -116,7 +116,7 @@ func (p *Predictor) runeGraceAt(slot int8, at time.Duration) time.Duration {
 	if at <= 0 || lastRegenTime <= 0 {
 		return 0
 	}
-	return min(time.Millisecond*2500, at-lastRegenTime)
+	return min(RuneGracePeriod, at-lastRegenTime)
 }
 
 func (p *Predictor) CurrentBloodRunes() int8 {
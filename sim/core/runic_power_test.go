@@ -0,0 +1,93 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestRuneCooldowns(t *testing.T) {
+	sim := &Simulation{}
+	unit := &Unit{Metrics: NewUnitMetrics()}
+	env := &Environment{Raid: &Raid{AllUnits: []*Unit{unit}}}
+	env.enableTimelines(&proto.TimelineOptions{Iterations: 1, ResolutionMs: 100}, 1)
+
+	unit.EnableRunicPowerBar(0, 100, 10*time.Second, nil, nil)
+	rp := &unit.runicPowerBar
+	metrics := unit.NewBloodRuneMetrics(ActionID{SpellID: 1})
+	unit.Metrics.reset()
+	rp.reset(sim)
+
+	// Spending at 1s wastes 1s, since runes start ready without any grace.
+	sim.CurrentTime = time.Second
+	rp.spendRune(sim, 0, metrics)
+
+	// Halving the rune cooldown halves the remaining 6s.
+	sim.CurrentTime = 5 * time.Second
+	rp.SetRuneCd(sim, 5*time.Second)
+	if regenAt := rp.RuneReadyAt(sim, 0); regenAt != 8*time.Second {
+		t.Fatalf("Expected the rune to regen at 8s, got %s", regenAt)
+	}
+
+	// Spending 1s after the regen takes the 1s off of the cooldown as grace.
+	sim.CurrentTime = 8 * time.Second
+	rp.Advance(sim, sim.CurrentTime)
+	sim.CurrentTime = 9 * time.Second
+	rp.spendRune(sim, 0, metrics)
+	if regenAt := rp.RuneReadyAt(sim, 0); regenAt != 13*time.Second {
+		t.Fatalf("Expected the rune to regen at 13s, got %s", regenAt)
+	}
+
+	sim.CurrentTime = 10 * time.Second
+	unit.Metrics.timeline.doneIteration(sim)
+	unit.Metrics.runes.doneIteration(sim, rp)
+
+	runeMetrics := unit.Metrics.runes.ToProto()
+	// The other 5 runes were ready the whole 10s.
+	if runeMetrics.WastedSecondsAvg != 51 || runeMetrics.GraceSecondsAvg != 1 {
+		t.Errorf("Unexpected rune metrics: %v", runeMetrics)
+	}
+	if expected := []float64{2, 0, 0, 0, 0, 0}; !reflect.DeepEqual(runeMetrics.SpendsAvg, expected) {
+		t.Errorf("Expected spends %v, got %v", expected, runeMetrics.SpendsAvg)
+	}
+
+	decoded, err := DecodeTimeline(unit.Metrics.timeline.ToProto(), 0)
+	if err != nil {
+		t.Fatalf("Failed to decode timeline: %s", err)
+	}
+	var runeEvents []TimelineEvent
+	for _, event := range decoded {
+		if event.Kind == proto.TimelineEventKind_TimelineEventRune {
+			runeEvents = append(runeEvents, event)
+		}
+	}
+	expected := []TimelineEvent{
+		{Kind: proto.TimelineEventKind_TimelineEventRune, Start: time.Second, Duration: 7 * time.Second, ActionID: ActionID{SpellID: 1}},
+		// Still regenerating at the end of the iteration.
+		{Kind: proto.TimelineEventKind_TimelineEventRune, Start: 9 * time.Second, Duration: time.Second, ActionID: ActionID{SpellID: 1}},
+	}
+	if !reflect.DeepEqual(runeEvents, expected) {
+		t.Fatalf("Expected rune events %v, got %v", expected, runeEvents)
+	}
+}
+
+func TestTimelineDeathRunes(t *testing.T) {
+	tl := &unitTimeline{resolution: time.Millisecond, actionIndices: make(map[ActionID]uint64), lastByKind: make(map[proto.TimelineEventKind]int)}
+	tl.events = []TimelineEvent{
+		{Kind: proto.TimelineEventKind_TimelineEventRune, Start: time.Second, Duration: 10 * time.Second, ActionID: ActionID{SpellID: 2}, RuneSlot: 5, DeathRune: true},
+	}
+
+	decoded, err := DecodeTimeline(&proto.UnitTimeline{
+		ResolutionMs: 1,
+		Iterations:   [][]byte{tl.encode(tl.events)},
+		Actions:      []*proto.ActionID{ActionID{SpellID: 2}.ToProto()},
+	}, 0)
+	if err != nil {
+		t.Fatalf("Failed to decode timeline: %s", err)
+	}
+	if !reflect.DeepEqual(decoded, tl.events) {
+		t.Fatalf("Expected events %v, got %v", tl.events, decoded)
+	}
+}
@@ -0,0 +1,223 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	googleProto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Settings files are migrated as generic JSON, since the fields a migration
+// reads may no longer exist in the current protos.
+type settingsJSON = map[string]interface{}
+
+// Upgrades settings from one version to the next. Migrations must never be
+// changed once released, only appended to.
+type settingsMigration struct {
+	description string
+	migrate     func(request settingsJSON)
+}
+
+// settingsMigrations[i] upgrades a file from version i+1 to version i+2.
+// Version 1 is any file written before versioning was added.
+var settingsMigrations = []settingsMigration{
+	{
+		description: "APL prepull do_at strings became do_at_value, and rotation.enabled became rotation.type.",
+		migrate: func(request settingsJSON) {
+			forEachSettingsPlayer(request, func(player settingsJSON) {
+				rotation, ok := settingsObject(player, "rotation")
+				if !ok {
+					return
+				}
+				for _, prepull := range settingsArray(rotation, "prepullActions") {
+					if prepull, ok := prepull.(settingsJSON); ok {
+						if doAt, ok := takeSettingsField(prepull, "doAt").(string); ok && doAt != "" {
+							prepull["doAtValue"] = settingsJSON{"const": settingsJSON{"val": doAt}}
+						}
+					}
+				}
+				if enabled, ok := takeSettingsField(rotation, "enabled").(bool); ok && enabled {
+					rotation["type"] = proto.APLRotation_TypeAPL.String()
+				}
+			})
+		},
+	},
+	{
+		description: "Target tight_enemy_damage became damage_spread.",
+		migrate: func(request settingsJSON) {
+			encounter, ok := settingsObject(request, "encounter")
+			if !ok {
+				return
+			}
+			for _, target := range settingsArray(encounter, "targets") {
+				if target, ok := target.(settingsJSON); ok {
+					tight, _ := takeSettingsField(target, "tightEnemyDamage").(bool)
+					if _, ok := settingsField(target, "damageSpread"); !ok {
+						target["damageSpread"] = TernaryFloat64(tight, 0.1667, 0.3333)
+					}
+				}
+			}
+		},
+	},
+	{
+		description: "UnitReference target_index became type and index.",
+		migrate: func(request settingsJSON) {
+			walkSettings(request, func(obj settingsJSON) {
+				targetIndex, ok := takeSettingsField(obj, "targetIndex").(float64)
+				if !ok || targetIndex < 0 {
+					return
+				}
+				obj["type"] = proto.UnitReference_Player.String()
+				obj["index"] = targetIndex
+			})
+		},
+	},
+}
+
+// The version written by SaveSettingsFile.
+var CurrentSettingsFileVersion = int32(len(settingsMigrations) + 1)
+
+// Serializes settings as JSON, tagged with the current version.
+func SaveSettingsFile(request *proto.RaidSimRequest) ([]byte, error) {
+	return protojson.MarshalOptions{Multiline: true}.Marshal(&proto.SimSettingsFile{
+		Version: CurrentSettingsFileVersion,
+		Request: request,
+	})
+}
+
+// Parses a settings file in JSON or binary proto format, upgrading it from the
+// version it was written with. Fields which no migration accounts for are an
+// error rather than being dropped, so old settings never silently change.
+func LoadSettingsFile(data []byte) (*proto.SimSettingsFile, error) {
+	var raw settingsJSON
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("invalid settings JSON: %w", err)
+		}
+	} else {
+		// Deprecated fields are kept in the protos until their migration is
+		// released, so binary files can be converted to JSON first.
+		file := &proto.SimSettingsFile{}
+		if err := googleProto.Unmarshal(data, file); err != nil {
+			return nil, fmt.Errorf("invalid settings file: %w", err)
+		}
+		jsonData, err := protojson.Marshal(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(jsonData, &raw); err != nil {
+			return nil, err
+		}
+	}
+
+	version := int32(1)
+	if v, ok := settingsField(raw, "version"); ok {
+		vf, ok := v.(float64)
+		if !ok || vf < 1 {
+			return nil, fmt.Errorf("invalid settings version %v", v)
+		}
+		version = int32(vf)
+	}
+	if version > CurrentSettingsFileVersion {
+		return nil, fmt.Errorf("settings version %d is newer than this sim supports (%d), please update", version, CurrentSettingsFileVersion)
+	}
+
+	request, _ := settingsObject(raw, "request")
+	if request == nil {
+		request = settingsJSON{}
+	}
+	for ; version < CurrentSettingsFileVersion; version++ {
+		settingsMigrations[version-1].migrate(request)
+	}
+
+	migrated, err := json.Marshal(settingsJSON{
+		"version": CurrentSettingsFileVersion,
+		"request": request,
+	})
+	if err != nil {
+		return nil, err
+	}
+	file := &proto.SimSettingsFile{}
+	if err := protojson.Unmarshal(migrated, file); err != nil {
+		return nil, fmt.Errorf("failed to upgrade settings: %w", err)
+	}
+	return file, nil
+}
+
+// Returns a field by its JSON name, also accepting the original proto name,
+// e.g. "prepullActions" or "prepull_actions".
+func settingsField(obj settingsJSON, jsonName string) (interface{}, bool) {
+	if value, ok := obj[jsonName]; ok {
+		return value, true
+	}
+	value, ok := obj[protoFieldName(jsonName)]
+	return value, ok
+}
+
+// Like settingsField, but also removes the field.
+func takeSettingsField(obj settingsJSON, jsonName string) interface{} {
+	value, _ := settingsField(obj, jsonName)
+	delete(obj, jsonName)
+	delete(obj, protoFieldName(jsonName))
+	return value
+}
+
+func settingsObject(obj settingsJSON, jsonName string) (settingsJSON, bool) {
+	value, _ := settingsField(obj, jsonName)
+	child, ok := value.(settingsJSON)
+	return child, ok
+}
+
+func settingsArray(obj settingsJSON, jsonName string) []interface{} {
+	value, _ := settingsField(obj, jsonName)
+	array, _ := value.([]interface{})
+	return array
+}
+
+func protoFieldName(jsonName string) string {
+	var sb strings.Builder
+	for _, r := range jsonName {
+		if unicode.IsUpper(r) {
+			sb.WriteRune('_')
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+func forEachSettingsPlayer(request settingsJSON, f func(player settingsJSON)) {
+	raid, ok := settingsObject(request, "raid")
+	if !ok {
+		return
+	}
+	for _, party := range settingsArray(raid, "parties") {
+		if party, ok := party.(settingsJSON); ok {
+			for _, player := range settingsArray(party, "players") {
+				if player, ok := player.(settingsJSON); ok {
+					f(player)
+				}
+			}
+		}
+	}
+}
+
+// Calls f on every object in the tree, parents first.
+func walkSettings(value interface{}, f func(obj settingsJSON)) {
+	switch v := value.(type) {
+	case settingsJSON:
+		f(v)
+		for _, child := range v {
+			walkSettings(child, f)
+		}
+	case []interface{}:
+		for _, child := range v {
+			walkSettings(child, f)
+		}
+	}
+}
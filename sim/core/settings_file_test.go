@@ -0,0 +1,77 @@
+package core
+
+import (
+	"testing"
+
+	googleProto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestLoadSettingsFileMigrations(t *testing.T) {
+	// Written before versioning, using proto field names.
+	file, err := LoadSettingsFile([]byte(`{
+		"request": {
+			"raid": {"parties": [{"players": [{
+				"name": "Player",
+				"rotation": {
+					"enabled": true,
+					"prepull_actions": [{"do_at": "-1s"}]
+				},
+				"balance_druid": {"options": {"innervate_target": {"target_index": 2}}}
+			}]}]},
+			"encounter": {"targets": [{"tight_enemy_damage": true}, {}]},
+			"sim_options": {"iterations": 1000}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadSettingsFile() returned error: %s", err)
+	}
+	if file.Version != CurrentSettingsFileVersion {
+		t.Errorf("Expected version %d, got %d", CurrentSettingsFileVersion, file.Version)
+	}
+
+	player := file.Request.Raid.Parties[0].Players[0]
+	if player.Rotation.Type != proto.APLRotation_TypeAPL {
+		t.Errorf("Expected an APL rotation, got %s", player.Rotation.Type)
+	}
+	if doAt := player.Rotation.PrepullActions[0].DoAtValue.GetConst().GetVal(); doAt != "-1s" {
+		t.Errorf("Expected do_at_value -1s, got %s", doAt)
+	}
+	if target := player.GetBalanceDruid().Options.InnervateTarget; target.Type != proto.UnitReference_Player || target.Index != 2 {
+		t.Errorf("Unexpected innervate target %v", target)
+	}
+	if targets := file.Request.Encounter.Targets; targets[0].DamageSpread != 0.1667 || targets[1].DamageSpread != 0.3333 {
+		t.Errorf("Unexpected damage spreads %v", targets)
+	}
+	if file.Request.SimOptions.Iterations != 1000 {
+		t.Errorf("Expected iterations to be kept")
+	}
+
+	// Round trip through the current format, in both encodings.
+	saved, err := SaveSettingsFile(file.Request)
+	if err != nil {
+		t.Fatalf("SaveSettingsFile() returned error: %s", err)
+	}
+	reloaded, err := LoadSettingsFile(saved)
+	if err != nil || !googleProto.Equal(reloaded, file) {
+		t.Errorf("JSON round trip mismatch: %v", err)
+	}
+	binary, _ := googleProto.Marshal(file)
+	reloaded, err = LoadSettingsFile(binary)
+	if err != nil || !googleProto.Equal(reloaded, file) {
+		t.Errorf("Binary round trip mismatch: %v", err)
+	}
+}
+
+func TestLoadSettingsFileErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"version": 1000, "request": {}}`,
+		`{"version": 1, "request": {"raid": {"unknown_field": 1}}}`,
+		`{"version": "two"}`,
+	} {
+		if _, err := LoadSettingsFile([]byte(data)); err == nil {
+			t.Errorf("Expected an error loading %s", data)
+		}
+	}
+}
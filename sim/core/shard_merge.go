@@ -0,0 +1,647 @@
+package core
+
+import (
+	"math"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Merges the results of shards which ran separately, in other processes (see
+// SimCluster) or before a checkpoint (see runCheckpointedSim).
+// Unlike Environment.mergeMetrics, only each shard's final metrics are
+// available, so aggregate sums are recovered by multiplying averages by the
+// number of iterations in the shard. This is exact, except for threat race
+// percentiles and resource timelines, which are averaged across shards.
+//
+// Shards must be in iteration order and have completed at least 1 iteration.
+// options are those of the sim the shards are from.
+func mergeShardResults(shards []*proto.RaidSimShardResult, options *proto.SimOptions) *proto.RaidSimResult {
+	if len(shards) == 1 {
+		return shards[0].Result
+	}
+
+	results := MapSlice(shards, func(shard *proto.RaidSimShardResult) *proto.RaidSimResult { return shard.Result })
+	ns := MapSlice(shards, func(shard *proto.RaidSimShardResult) float64 { return float64(shard.CompletedIterations) })
+	first := results[0]
+
+	merged := &proto.RaidSimResult{
+		RaidMetrics: mergeRaidMetrics(MapSlice(results, func(result *proto.RaidSimResult) *proto.RaidMetrics { return result.RaidMetrics }), ns),
+		EncounterMetrics: &proto.EncounterMetrics{
+			Targets: mergeUnitMetricsLists(MapSlice(results, func(result *proto.RaidSimResult) []*proto.UnitMetrics { return result.EncounterMetrics.GetTargets() }), ns),
+		},
+
+		FirstIterationDuration: first.FirstIterationDuration,
+		AvgIterationDuration:   shardAverage(results, ns, func(result *proto.RaidSimResult) float64 { return result.AvgIterationDuration }),
+
+		ExternalAssignments: first.ExternalAssignments,
+		Warnings:            first.Warnings,
+	}
+	for _, result := range results {
+		merged.Cancelled = merged.Cancelled || result.Cancelled
+	}
+	if count := options.OutlierIterations; count > 0 {
+		merged.HighestIterations = mergeOutliers(MapSlice(results, func(result *proto.RaidSimResult) []*proto.OutlierIteration { return result.HighestIterations }), count, true)
+		merged.LowestIterations = mergeOutliers(MapSlice(results, func(result *proto.RaidSimResult) []*proto.OutlierIteration { return result.LowestIterations }), count, false)
+	}
+	return merged
+}
+
+// Average of a per-iteration average over all shards, weighted by each shard's
+// number of iterations, or whatever else the average is over.
+func shardAverage[T any](shards []T, weights []float64, value func(T) float64) float64 {
+	var sum, totalWeight float64
+	for i, shard := range shards {
+		sum += value(shard) * weights[i]
+		totalWeight += weights[i]
+	}
+	if totalWeight == 0 {
+		return 0
+	}
+	return sum / totalWeight
+}
+
+// Like shardAverage, for each second of a timeline. If sparse, shards without
+// a value for a second are left out of its average, rather than counting as 0.
+func shardAverageTimeline(timelines [][]float64, weights []float64, sparse bool) []float64 {
+	var length int
+	for _, timeline := range timelines {
+		length = max(length, len(timeline))
+	}
+	merged := make([]float64, length)
+	for second := range merged {
+		var sum, totalWeight float64
+		for i, timeline := range timelines {
+			if second < len(timeline) {
+				sum += timeline[second] * weights[i]
+				totalWeight += weights[i]
+			} else if !sparse {
+				totalWeight += weights[i]
+			}
+		}
+		if totalWeight > 0 {
+			merged[second] = sum / totalWeight
+		}
+	}
+	return merged
+}
+
+func mergeDistributions(dists []*proto.DistributionMetrics) *proto.DistributionMetrics {
+	merged := NewDistributionMetrics()
+	// The sums for the fight length fit aren't in the proto, so average each
+	// shard's slope instead, which is close since every shard has the same
+	// distribution of fight lengths.
+	var slopeSum float64
+	for _, dist := range dists {
+		if dist == nil {
+			continue
+		}
+		// Every value is counted in the histogram, which gives the number of
+		// values even for metrics which aren't recorded every iteration.
+		var n int32
+		for _, count := range dist.Hist {
+			n += count
+		}
+		fn := float64(n)
+		slopeSum += dist.DurationSlope * fn
+		merged.merge(&DistributionMetrics{
+			aggregator: aggregator{
+				n:     int(n),
+				sum:   dist.Avg * fn,
+				sumSq: (dist.Stdev*dist.Stdev + dist.Avg*dist.Avg) * fn,
+			},
+			max:     dist.Max,
+			min:     dist.Min,
+			maxSeed: dist.MaxSeed,
+			minSeed: dist.MinSeed,
+			maxIter: dist.MaxIteration,
+			minIter: dist.MinIteration,
+			hist:    dist.Hist,
+			sample:  dist.AllValues,
+			batches: convergenceBatchesFromProto(dist.Convergence),
+		})
+	}
+
+	result := merged.ToProto()
+	if merged.n > 0 {
+		result.DurationSlope = slopeSum / float64(merged.n)
+	}
+	return result
+}
+
+func mergeRaidMetrics(raids []*proto.RaidMetrics, ns []float64) *proto.RaidMetrics {
+	merged := &proto.RaidMetrics{
+		Dps: mergeDistributions(MapSlice(raids, func(raid *proto.RaidMetrics) *proto.DistributionMetrics { return raid.GetDps() })),
+		Hps: mergeDistributions(MapSlice(raids, func(raid *proto.RaidMetrics) *proto.DistributionMetrics { return raid.GetHps() })),
+	}
+	for i := range raids[0].GetParties() {
+		parties := MapSlice(raids, func(raid *proto.RaidMetrics) *proto.PartyMetrics { return raid.Parties[i] })
+		merged.Parties = append(merged.Parties, &proto.PartyMetrics{
+			Dps:     mergeDistributions(MapSlice(parties, func(party *proto.PartyMetrics) *proto.DistributionMetrics { return party.Dps })),
+			Hps:     mergeDistributions(MapSlice(parties, func(party *proto.PartyMetrics) *proto.DistributionMetrics { return party.Hps })),
+			Players: mergeUnitMetricsLists(MapSlice(parties, func(party *proto.PartyMetrics) []*proto.UnitMetrics { return party.Players }), ns),
+		})
+	}
+	return merged
+}
+
+// Merges each shard's list of units, which are in the same order in every shard.
+func mergeUnitMetricsLists(lists [][]*proto.UnitMetrics, ns []float64) []*proto.UnitMetrics {
+	merged := make([]*proto.UnitMetrics, len(lists[0]))
+	for i := range merged {
+		merged[i] = mergeUnitMetrics(MapSlice(lists, func(units []*proto.UnitMetrics) *proto.UnitMetrics { return units[i] }), ns)
+	}
+	return merged
+}
+
+func mergeUnitMetrics(units []*proto.UnitMetrics, ns []float64) *proto.UnitMetrics {
+	first := units[0]
+	distribution := func(get func(*proto.UnitMetrics) *proto.DistributionMetrics) *proto.DistributionMetrics {
+		return mergeDistributions(MapSlice(units, get))
+	}
+
+	merged := &proto.UnitMetrics{
+		Name:      first.Name,
+		UnitIndex: first.UnitIndex,
+
+		Dps:    distribution(func(unit *proto.UnitMetrics) *proto.DistributionMetrics { return unit.Dps }),
+		Dpasp:  distribution(func(unit *proto.UnitMetrics) *proto.DistributionMetrics { return unit.Dpasp }),
+		Threat: distribution(func(unit *proto.UnitMetrics) *proto.DistributionMetrics { return unit.Threat }),
+		Dtps:   distribution(func(unit *proto.UnitMetrics) *proto.DistributionMetrics { return unit.Dtps }),
+		Tmi:    distribution(func(unit *proto.UnitMetrics) *proto.DistributionMetrics { return unit.Tmi }),
+		Hps:    distribution(func(unit *proto.UnitMetrics) *proto.DistributionMetrics { return unit.Hps }),
+		Tto:    distribution(func(unit *proto.UnitMetrics) *proto.DistributionMetrics { return unit.Tto }),
+
+		SecondsOomAvg: shardAverage(units, ns, func(unit *proto.UnitMetrics) float64 { return unit.SecondsOomAvg }),
+		ChanceOfDeath: shardAverage(units, ns, func(unit *proto.UnitMetrics) float64 { return unit.ChanceOfDeath }),
+
+		Actions:           mergeActionMetrics(units),
+		Auras:             mergeAuraMetrics(units, ns),
+		Resources:         mergeResourceMetrics(units),
+		DamageTaken:       mergeDamageTakenMetrics(units, ns),
+		Dots:              mergeDotMetrics(units, ns),
+		Resists:           mergeResistMetrics(units, ns),
+		InternalCooldowns: mergeInternalCooldownMetrics(units, ns),
+		ExclusiveEffects:  mergeExclusiveEffectMetrics(units, ns),
+		Pets:              mergeUnitMetricsLists(MapSlice(units, func(unit *proto.UnitMetrics) []*proto.UnitMetrics { return unit.Pets }), ns),
+
+		// Timelines only record the first iterations, which are all in the
+		// first shard.
+		Timeline: first.Timeline,
+
+		// Caps don't depend on random rolls, so every shard has the same ones.
+		StatCaps: first.StatCaps,
+	}
+
+	// Downtime windows are the same for every shard, but shards whose
+	// iterations all ended before the first window ended may lack active DPS.
+	for _, unit := range units {
+		if unit.ActiveDps != nil {
+			merged.ActiveDps = distribution(func(unit *proto.UnitMetrics) *proto.DistributionMetrics { return unit.ActiveDps })
+			break
+		}
+	}
+	// Shards which never reached execute range don't have execute DPS.
+	for _, unit := range units {
+		if unit.ExecuteDps != nil {
+			merged.ExecuteDps = distribution(func(unit *proto.UnitMetrics) *proto.DistributionMetrics { return unit.ExecuteDps })
+			break
+		}
+	}
+	if first.DamageSplit != nil {
+		merged.DamageSplit = &proto.DamageSplitMetrics{
+			PriorityDps: shardAverage(units, ns, func(unit *proto.UnitMetrics) float64 { return unit.DamageSplit.PriorityDps }),
+			AoeDps:      shardAverage(units, ns, func(unit *proto.UnitMetrics) float64 { return unit.DamageSplit.AoeDps }),
+		}
+	}
+	if first.DpsRequirement != nil {
+		merged.DpsRequirement = &proto.DpsRequirementMetrics{
+			RequiredDps: first.DpsRequirement.RequiredDps,
+			Dps:         shardAverage(units, ns, func(unit *proto.UnitMetrics) float64 { return unit.DpsRequirement.Dps }),
+			SuccessRate: shardAverage(units, ns, func(unit *proto.UnitMetrics) float64 { return unit.DpsRequirement.SuccessRate }),
+		}
+	}
+	if first.Tank != nil {
+		merged.Tank = mergeTankMetrics(MapSlice(units, func(unit *proto.UnitMetrics) *proto.TankMetrics { return unit.Tank }), ns)
+	}
+	if first.ThreatRace != nil {
+		merged.ThreatRace = mergeThreatRaceMetrics(MapSlice(units, func(unit *proto.UnitMetrics) *proto.ThreatRaceMetrics { return unit.ThreatRace }), ns)
+	}
+	if first.CastEfficiency != nil {
+		merged.CastEfficiency = mergeCastEfficiencyMetrics(MapSlice(units, func(unit *proto.UnitMetrics) *proto.CastEfficiencyMetrics { return unit.CastEfficiency }), ns)
+	}
+	if first.TankSwap != nil {
+		merged.TankSwap = mergeTankSwapMetrics(MapSlice(units, func(unit *proto.UnitMetrics) *proto.TankSwapMetrics { return unit.TankSwap }), ns)
+	}
+	if deaths := mergeDeathMetrics(units, ns); deaths.DeathsAvg > 0 {
+		merged.Deaths = deaths
+	}
+	for i := range first.ResourceTimelines {
+		merged.ResourceTimelines = append(merged.ResourceTimelines, mergeResourceTimelineMetrics(MapSlice(units, func(unit *proto.UnitMetrics) *proto.ResourceTimelineMetrics { return unit.ResourceTimelines[i] }), ns))
+	}
+
+	return merged
+}
+
+// Action metrics are totals, so they're summed.
+func mergeActionMetrics(units []*proto.UnitMetrics) []*proto.ActionMetrics {
+	var merged []*proto.ActionMetrics
+	byID := make(map[ActionID]*proto.ActionMetrics)
+	for _, unit := range units {
+		for _, action := range unit.Actions {
+			id := ProtoToActionID(action.Id)
+			mergedAction, ok := byID[id]
+			if !ok {
+				mergedAction = &proto.ActionMetrics{Id: action.Id, IsMelee: action.IsMelee, RankOf: action.RankOf}
+				byID[id] = mergedAction
+				merged = append(merged, mergedAction)
+			}
+			for len(mergedAction.Targets) < len(action.Targets) {
+				mergedAction.Targets = append(mergedAction.Targets, &proto.TargetedActionMetrics{})
+			}
+			for i, tam := range action.Targets {
+				mergedTam := mergedAction.Targets[i]
+				mergedTam.UnitIndex = tam.UnitIndex
+				mergedTam.Casts += tam.Casts
+				mergedTam.Hits += tam.Hits
+				mergedTam.Crits += tam.Crits
+				mergedTam.Misses += tam.Misses
+				mergedTam.Dodges += tam.Dodges
+				mergedTam.Parries += tam.Parries
+				mergedTam.Blocks += tam.Blocks
+				mergedTam.Glances += tam.Glances
+				mergedTam.BlockedDamage += tam.BlockedDamage
+				mergedTam.CriticalBlocks += tam.CriticalBlocks
+				mergedTam.Damage += tam.Damage
+				mergedTam.Threat += tam.Threat
+				mergedTam.Healing += tam.Healing
+				mergedTam.Shielding += tam.Shielding
+				mergedTam.CastTimeMs += tam.CastTimeMs
+				for len(mergedTam.DamageBuckets) < len(tam.DamageBuckets) {
+					mergedTam.DamageBuckets = append(mergedTam.DamageBuckets, 0)
+				}
+				for bucket, damage := range tam.DamageBuckets {
+					mergedTam.DamageBuckets[bucket] += damage
+				}
+			}
+			for len(mergedAction.Bounces) < len(action.Bounces) {
+				mergedAction.Bounces = append(mergedAction.Bounces, &proto.ChainBounceMetrics{})
+			}
+			for i, bounce := range action.Bounces {
+				mergedAction.Bounces[i].Hits += bounce.Hits
+				mergedAction.Bounces[i].Damage += bounce.Damage
+				mergedAction.Bounces[i].Healing += bounce.Healing
+			}
+		}
+	}
+	return merged
+}
+
+// Auras are matched by ID, in order, as in Environment.mergeMetrics.
+func mergeAuraMetrics(units []*proto.UnitMetrics, ns []float64) []*proto.AuraMetrics {
+	var merged []*AuraMetrics
+	byID := make(map[ActionID][]*AuraMetrics)
+	for i, unit := range units {
+		seen := make(map[ActionID]int)
+		for _, aura := range unit.Auras {
+			id := ProtoToActionID(aura.Id)
+			n := ns[i]
+			sum := aura.UptimeSecondsAvg * n
+			shardMetrics := &AuraMetrics{
+				ID: id,
+				aggregator: aggregator{
+					n:     int(n),
+					sum:   sum,
+					sumSq: (aura.UptimeSecondsStdev*aura.UptimeSecondsStdev + aura.UptimeSecondsAvg*aura.UptimeSecondsAvg) * n,
+				},
+				procsSum:         int32(math.Round(aura.ProcsAvg * n)),
+				applicationsSum:  int32(math.Round(aura.ApplicationsAvg * n)),
+				uptimePercentSum: aura.UptimePercentAvg * n,
+				stackSecondsSum:  aura.StacksAvg * sum,
+				refreshWastedSum: aura.RefreshWastedSecondsAvg * n,
+			}
+
+			if matches := byID[id]; seen[id] < len(matches) {
+				matches[seen[id]].merge(shardMetrics)
+			} else {
+				byID[id] = append(byID[id], shardMetrics)
+				merged = append(merged, shardMetrics)
+			}
+			seen[id]++
+		}
+	}
+	return MapSlice(merged, func(aura *AuraMetrics) *proto.AuraMetrics { return aura.ToProto() })
+}
+
+// Resources are matched by ID and type, in order, as in UnitMetrics.merge.
+func mergeResourceMetrics(units []*proto.UnitMetrics) []*proto.ResourceMetrics {
+	var merged []*proto.ResourceMetrics
+	byKey := make(map[ResourceKey][]*proto.ResourceMetrics)
+	for _, unit := range units {
+		seen := make(map[ResourceKey]int)
+		for _, resource := range unit.Resources {
+			key := ResourceKey{ProtoToActionID(resource.Id), resource.Type}
+			if matches := byKey[key]; seen[key] < len(matches) {
+				match := matches[seen[key]]
+				match.Events += resource.Events
+				match.Gain += resource.Gain
+				match.ActualGain += resource.ActualGain
+			} else {
+				copied := &proto.ResourceMetrics{
+					Id:         resource.Id,
+					Type:       resource.Type,
+					Events:     resource.Events,
+					Gain:       resource.Gain,
+					ActualGain: resource.ActualGain,
+				}
+				byKey[key] = append(byKey[key], copied)
+				merged = append(merged, copied)
+			}
+			seen[key]++
+		}
+	}
+	return merged
+}
+
+func mergeTankMetrics(tanks []*proto.TankMetrics, ns []float64) *proto.TankMetrics {
+	return &proto.TankMetrics{
+		EffectiveHealth:     tanks[0].EffectiveHealth,
+		MaxBurstDamage:      mergeDistributions(MapSlice(tanks, func(tank *proto.TankMetrics) *proto.DistributionMetrics { return tank.MaxBurstDamage })),
+		DamageTakenTimeline: shardAverageTimeline(MapSlice(tanks, func(tank *proto.TankMetrics) []float64 { return tank.DamageTakenTimeline }), ns, false),
+	}
+}
+
+func mergeDamageTakenMetrics(units []*proto.UnitMetrics, ns []float64) []*proto.DamageTakenMetrics {
+	var merged []*proto.DamageTakenMetrics
+	var n float64
+	byKey := make(map[damageTakenKey]*proto.DamageTakenMetrics)
+	for i, unit := range units {
+		n += ns[i]
+		for _, dtm := range unit.DamageTaken {
+			key := damageTakenKey{ProtoToActionID(dtm.Id), dtm.SourceUnitIndex}
+			mergedDtm, ok := byKey[key]
+			if !ok {
+				mergedDtm = &proto.DamageTakenMetrics{Id: dtm.Id, SourceUnitIndex: dtm.SourceUnitIndex}
+				byKey[key] = mergedDtm
+				merged = append(merged, mergedDtm)
+			}
+			// Summed here, and averaged below.
+			mergedDtm.DamageAvg += dtm.DamageAvg * ns[i]
+			mergedDtm.HitsAvg += dtm.HitsAvg * ns[i]
+			mergedDtm.CritsAvg += dtm.CritsAvg * ns[i]
+			mergedDtm.CrushesAvg += dtm.CrushesAvg * ns[i]
+			mergedDtm.BlocksAvg += dtm.BlocksAvg * ns[i]
+			mergedDtm.MissesAvg += dtm.MissesAvg * ns[i]
+			mergedDtm.DodgesAvg += dtm.DodgesAvg * ns[i]
+			mergedDtm.ParriesAvg += dtm.ParriesAvg * ns[i]
+			mergedDtm.BlockedDamageAvg += dtm.BlockedDamageAvg * ns[i]
+			mergedDtm.CriticalBlocksAvg += dtm.CriticalBlocksAvg * ns[i]
+		}
+	}
+	for _, dtm := range merged {
+		dtm.DamageAvg /= n
+		dtm.HitsAvg /= n
+		dtm.CritsAvg /= n
+		dtm.CrushesAvg /= n
+		dtm.BlocksAvg /= n
+		dtm.MissesAvg /= n
+		dtm.DodgesAvg /= n
+		dtm.ParriesAvg /= n
+		dtm.BlockedDamageAvg /= n
+		dtm.CriticalBlocksAvg /= n
+	}
+	return merged
+}
+
+func mergeCastEfficiencyMetrics(efficiencies []*proto.CastEfficiencyMetrics, ns []float64) *proto.CastEfficiencyMetrics {
+	merged := &proto.CastEfficiencyMetrics{
+		CastsPerMinute:   shardAverage(efficiencies, ns, func(ce *proto.CastEfficiencyMetrics) float64 { return ce.GetCastsPerMinute() }),
+		GcdUptimePercent: shardAverage(efficiencies, ns, func(ce *proto.CastEfficiencyMetrics) float64 { return ce.GetGcdUptimePercent() }),
+		IdleSecondsAvg:   shardAverage(efficiencies, ns, func(ce *proto.CastEfficiencyMetrics) float64 { return ce.GetIdleSecondsAvg() }),
+	}
+
+	var n float64
+	byID := make(map[ActionID]*proto.CooldownUsageMetrics)
+	for i, ce := range efficiencies {
+		n += ns[i]
+		for _, cd := range ce.GetCooldowns() {
+			id := ProtoToActionID(cd.Id)
+			mergedCD, ok := byID[id]
+			if !ok {
+				mergedCD = &proto.CooldownUsageMetrics{Id: cd.Id}
+				byID[id] = mergedCD
+				merged.Cooldowns = append(merged.Cooldowns, mergedCD)
+			}
+			// Summed here, and averaged below.
+			mergedCD.CastsAvg += cd.CastsAvg * ns[i]
+			mergedCD.WastedSecondsAvg += cd.WastedSecondsAvg * ns[i]
+		}
+	}
+	for _, cd := range merged.Cooldowns {
+		cd.CastsAvg /= n
+		cd.WastedSecondsAvg /= n
+	}
+	return merged
+}
+
+func mergeDotMetrics(units []*proto.UnitMetrics, ns []float64) []*proto.DotMetrics {
+	type dotKey struct {
+		ActionID        ActionID
+		TargetUnitIndex int32
+	}
+	var merged []*proto.DotMetrics
+	var n float64
+	byKey := make(map[dotKey]*proto.DotMetrics)
+	for i, unit := range units {
+		n += ns[i]
+		for _, dm := range unit.Dots {
+			key := dotKey{ProtoToActionID(dm.Id), dm.TargetUnitIndex}
+			mergedDm, ok := byKey[key]
+			if !ok {
+				mergedDm = &proto.DotMetrics{Id: dm.Id, TargetUnitIndex: dm.TargetUnitIndex}
+				byKey[key] = mergedDm
+				merged = append(merged, mergedDm)
+			}
+			// Summed here, and averaged below.
+			mergedDm.UptimePercentAvg += dm.UptimePercentAvg * ns[i]
+			mergedDm.ApplicationsAvg += dm.ApplicationsAvg * ns[i]
+			mergedDm.RefreshesAvg += dm.RefreshesAvg * ns[i]
+			mergedDm.TicksAvg += dm.TicksAvg * ns[i]
+			mergedDm.ClippedTicksAvg += dm.ClippedTicksAvg * ns[i]
+			mergedDm.GapTicksAvg += dm.GapTicksAvg * ns[i]
+
+			for len(mergedDm.RefreshHistogram) < len(dm.RefreshHistogram) {
+				mergedDm.RefreshHistogram = append(mergedDm.RefreshHistogram, 0)
+			}
+			for j, count := range dm.RefreshHistogram {
+				mergedDm.RefreshHistogram[j] += count
+			}
+		}
+	}
+	for _, dm := range merged {
+		dm.UptimePercentAvg /= n
+		dm.ApplicationsAvg /= n
+		dm.RefreshesAvg /= n
+		dm.TicksAvg /= n
+		dm.ClippedTicksAvg /= n
+		dm.GapTicksAvg /= n
+	}
+	return merged
+}
+
+// Rates are weighted by each shard's number of hits.
+func mergeResistMetrics(units []*proto.UnitMetrics, ns []float64) []*proto.ResistMetrics {
+	var merged []*proto.ResistMetrics
+	var n float64
+	hits := make(map[proto.SpellSchool]float64)
+	bySchool := make(map[proto.SpellSchool]*proto.ResistMetrics)
+	for i, unit := range units {
+		n += ns[i]
+		for _, rm := range unit.Resists {
+			mergedRm, ok := bySchool[rm.School]
+			if !ok {
+				mergedRm = &proto.ResistMetrics{School: rm.School}
+				bySchool[rm.School] = mergedRm
+				merged = append(merged, mergedRm)
+			}
+			// Summed here, and averaged below.
+			shardHits := rm.HitsAvg * ns[i]
+			hits[rm.School] += shardHits
+			mergedRm.ResistRate += rm.ResistRate * shardHits
+			mergedRm.FullResistRate += rm.FullResistRate * shardHits
+		}
+	}
+	for _, rm := range merged {
+		rm.HitsAvg = hits[rm.School] / n
+		rm.ResistRate /= hits[rm.School]
+		rm.FullResistRate /= hits[rm.School]
+	}
+	return merged
+}
+
+func mergeInternalCooldownMetrics(units []*proto.UnitMetrics, ns []float64) []*proto.InternalCooldownMetrics {
+	var merged []*proto.InternalCooldownMetrics
+	var n float64
+	byID := make(map[ActionID]*proto.InternalCooldownMetrics)
+	for i, unit := range units {
+		n += ns[i]
+		for _, icdm := range unit.InternalCooldowns {
+			id := ProtoToActionID(icdm.Id)
+			mergedIcdm, ok := byID[id]
+			if !ok {
+				mergedIcdm = &proto.InternalCooldownMetrics{Id: icdm.Id, SharedId: icdm.SharedId}
+				byID[id] = mergedIcdm
+				merged = append(merged, mergedIcdm)
+			}
+			// Summed here, and averaged below.
+			mergedIcdm.ProcsAvg += icdm.ProcsAvg * ns[i]
+			mergedIcdm.SuppressedAvg += icdm.SuppressedAvg * ns[i]
+			mergedIcdm.WastedProcsAvg += icdm.WastedProcsAvg * ns[i]
+		}
+	}
+	for _, icdm := range merged {
+		icdm.ProcsAvg /= n
+		icdm.SuppressedAvg /= n
+		icdm.WastedProcsAvg /= n
+	}
+	return merged
+}
+
+func mergeExclusiveEffectMetrics(units []*proto.UnitMetrics, ns []float64) []*proto.ExclusiveEffectMetrics {
+	type effectKey struct {
+		ActionID ActionID
+		Category string
+	}
+	var merged []*proto.ExclusiveEffectMetrics
+	var n float64
+	byKey := make(map[effectKey]*proto.ExclusiveEffectMetrics)
+	for i, unit := range units {
+		n += ns[i]
+		for _, eem := range unit.ExclusiveEffects {
+			key := effectKey{ProtoToActionID(eem.Id), eem.Category}
+			mergedEem, ok := byKey[key]
+			if !ok {
+				mergedEem = &proto.ExclusiveEffectMetrics{Id: eem.Id, Category: eem.Category}
+				byKey[key] = mergedEem
+				merged = append(merged, mergedEem)
+			}
+			// Summed here, and averaged below.
+			mergedEem.ActivePercentAvg += eem.ActivePercentAvg * ns[i]
+			mergedEem.SuppressedPercentAvg += eem.SuppressedPercentAvg * ns[i]
+			mergedEem.BlockedAvg += eem.BlockedAvg * ns[i]
+		}
+	}
+	for _, eem := range merged {
+		eem.ActivePercentAvg /= n
+		eem.SuppressedPercentAvg /= n
+		eem.BlockedAvg /= n
+	}
+	return merged
+}
+
+func mergeDeathMetrics(units []*proto.UnitMetrics, ns []float64) *proto.DeathMetrics {
+	// DPS with and without deaths are averages over only the iterations with
+	// and without a death, respectively.
+	withDeath := make([]float64, len(units))
+	withoutDeath := make([]float64, len(units))
+	var n, nWithDeath float64
+	for i, unit := range units {
+		withDeath[i] = unit.ChanceOfDeath * ns[i]
+		withoutDeath[i] = ns[i] - withDeath[i]
+		n += ns[i]
+		nWithDeath += withDeath[i]
+	}
+
+	deaths := MapSlice(units, func(unit *proto.UnitMetrics) *proto.DeathMetrics { return unit.Deaths })
+	merged := &proto.DeathMetrics{
+		DeathsAvg:          shardAverage(deaths, ns, (*proto.DeathMetrics).GetDeathsAvg),
+		SecondsDeadAvg:     shardAverage(deaths, ns, (*proto.DeathMetrics).GetSecondsDeadAvg),
+		DpsWithDeathAvg:    shardAverage(deaths, withDeath, (*proto.DeathMetrics).GetDpsWithDeathAvg),
+		DpsWithoutDeathAvg: shardAverage(deaths, withoutDeath, (*proto.DeathMetrics).GetDpsWithoutDeathAvg),
+	}
+	// See deathMetrics.ToProto.
+	if p := nWithDeath / n; p > 0 && p < 1 {
+		diff := merged.DpsWithDeathAvg - merged.DpsWithoutDeathAvg
+		merged.DpsVarianceFromDeaths = p * (1 - p) * diff * diff
+	}
+	return merged
+}
+
+func mergeThreatRaceMetrics(races []*proto.ThreatRaceMetrics, ns []float64) *proto.ThreatRaceMetrics {
+	// Aggro times are only known as percentiles, so they're averaged over
+	// the iterations in which aggro was pulled.
+	aggros := make([]float64, len(races))
+	for i, race := range races {
+		aggros[i] = race.AggroChance * ns[i]
+	}
+	return &proto.ThreatRaceMetrics{
+		AggroChance:         shardAverage(races, ns, (*proto.ThreatRaceMetrics).GetAggroChance),
+		TimeToAggroP10:      shardAverage(races, aggros, (*proto.ThreatRaceMetrics).GetTimeToAggroP10),
+		TimeToAggroP50:      shardAverage(races, aggros, (*proto.ThreatRaceMetrics).GetTimeToAggroP50),
+		TimeToAggroP90:      shardAverage(races, aggros, (*proto.ThreatRaceMetrics).GetTimeToAggroP90),
+		FinalThreatRatioAvg: shardAverage(races, ns, (*proto.ThreatRaceMetrics).GetFinalThreatRatioAvg),
+	}
+}
+
+func mergeTankSwapMetrics(swaps []*proto.TankSwapMetrics, ns []float64) *proto.TankSwapMetrics {
+	taunts := make([]float64, len(swaps))
+	for i, swap := range swaps {
+		taunts[i] = swap.TauntsAvg * ns[i]
+	}
+	return &proto.TankSwapMetrics{
+		TauntsAvg:                shardAverage(swaps, ns, (*proto.TankSwapMetrics).GetTauntsAvg),
+		CooldownReadyAtTaunt:     shardAverage(swaps, taunts, (*proto.TankSwapMetrics).GetCooldownReadyAtTaunt),
+		SecondsTankingAvg:        shardAverage(swaps, ns, (*proto.TankSwapMetrics).GetSecondsTankingAvg),
+		DamageTakenTankingAvg:    shardAverage(swaps, ns, (*proto.TankSwapMetrics).GetDamageTakenTankingAvg),
+		DamageTakenOffTankingAvg: shardAverage(swaps, ns, (*proto.TankSwapMetrics).GetDamageTakenOffTankingAvg),
+	}
+}
+
+func mergeResourceTimelineMetrics(timelines []*proto.ResourceTimelineMetrics, ns []float64) *proto.ResourceTimelineMetrics {
+	return &proto.ResourceTimelineMetrics{
+		Type:             timelines[0].Type,
+		LevelAvg:         shardAverage(timelines, ns, (*proto.ResourceTimelineMetrics).GetLevelAvg),
+		CappedSecondsAvg: shardAverage(timelines, ns, (*proto.ResourceTimelineMetrics).GetCappedSecondsAvg),
+		WastedAvg:        shardAverage(timelines, ns, (*proto.ResourceTimelineMetrics).GetWastedAvg),
+		Timeline:         shardAverageTimeline(MapSlice(timelines, func(timeline *proto.ResourceTimelineMetrics) []float64 { return timeline.Timeline }), ns, true),
+	}
+}
@@ -36,7 +36,7 @@ func (shield *Shield) Apply(sim *Simulation, shieldAmount float64) {
 	shield.Spell.SpellMetrics[target.UnitIndex].Hits++
 
 	if sim.Log != nil {
-		caster.Log(sim, "%s %s Hit for %0.3f shielding. (Threat: %0.3f)", target.LogLabel(), shield.Spell.ActionID, shieldAmount, threat)
+		sim.logEvent(LogEvent{Type: LogEventShield, Actor: caster, Target: target, ActionID: shield.Spell.ActionID, Amount: shieldAmount, Threat: threat})
 	}
 }
 
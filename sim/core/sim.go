@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
@@ -9,10 +10,10 @@ import (
 	"runtime/debug"
 	"slices"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/wowsims/wotlk/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
 )
 
 type Task interface {
@@ -24,21 +25,68 @@ type Simulation struct {
 
 	Options *proto.SimOptions
 
+	// Cancels the remaining iterations, if set.
+	ctx context.Context
+
+	// Creates the Simulations for the other workers, with SimOptions.concurrency.
+	newWorker func() *Simulation
+
 	rand  Rand
 	rseed int64
 
-	// Used for testing only, see RandomFloat().
-	isTest    bool
-	testRands map[string]Rand
+	// See RandomFloat().
+	isTest       bool
+	labeledRands bool
+	labelRands   map[string]Rand
+
+	iteration int32 // Index of the current iteration.
+
+	// Set for the second iteration of each pair with SimOptions.antithetic_sampling.
+	antithetic bool
+
+	// Set with SimOptions.expected_value, see expectedProc.
+	expectedValue bool
+	expectedProcs map[any]float64
+
+	// Only does anything with the 'alloc_audit' build tag.
+	allocAudit allocAudit
+
+	// Only used with the 'sim_invariants' build tag.
+	invariants simInvariants
+
+	// Set in the wasm build, see lowMemoryMode.
+	lowMemory bool
+
+	// Only set with SimOptions.rng_diagnostics.
+	randomStreamStats map[string]*randomStreamStats
+
+	// From SimOptions.damage_buckets, 0 if disabled.
+	damageBucketWidth      time.Duration
+	damageBucketsFirstOnly bool
+
+	// From SimOptions.convergence, 0 if disabled.
+	convergenceBatchSize int32
+
+	// Only set with SimOptions.outlier_iterations.
+	outliers *outlierTracker
+
+	// Where sim.Log records events to, while debug logs are enabled.
+	eventLog *EventLog
+
+	// Receives debug logs instead of eventLog, see RunRaidSimWithLogEventsAsync.
+	onLogEvent LogEventFunc
 
 	// Current Simulation State
-	pendingActions []*PendingAction
+	pendingActions pendingActionQueue
 	CurrentTime    time.Duration // duration that has elapsed in the sim since starting
 	Duration       time.Duration // Duration of current iteration
 	NeedsInput     bool          // Sim is in interactive mode and needs input
 
 	ProgressReport func(*proto.ProgressMetrics)
 
+	// Chosen by the externals optimizer, if it was enabled.
+	externalAssignments []*proto.ExternalAssignment
+
 	Log func(string, ...interface{})
 
 	executePhase int32 // 20, 25, or 35 for the respective execute range, 100 otherwise
@@ -47,10 +95,14 @@ type Simulation struct {
 
 	nextExecuteDuration time.Duration
 	nextExecuteDamage   float64
+	execute35Start      time.Duration // When the current iteration reached 35%, or NeverExpires.
 
 	endOfCombatDuration time.Duration
 	endOfCombatDamage   float64
 
+	// Phase of shared resource ticks, as a fraction of the tick duration.
+	resourceTickPhase float64
+
 	minTrackerTime time.Duration
 	trackers       []*auraTracker
 
@@ -108,7 +160,21 @@ func RunSim(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics) *pr
 	return runSim(rsr, progress, false)
 }
 
-func runSim(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool) (result *proto.RaidSimResult) {
+// Like RunSim, but stops early when ctx is cancelled and returns the metrics
+// of the iterations completed so far.
+func RunSimWithContext(ctx context.Context, rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics) *proto.RaidSimResult {
+	return runSimWithContext(ctx, rsr, progress, false)
+}
+
+func runSim(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool) *proto.RaidSimResult {
+	return runSimWithContext(context.Background(), rsr, progress, skipPresim)
+}
+
+func runSimWithContext(ctx context.Context, rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool) *proto.RaidSimResult {
+	return runSimWithLogEvents(ctx, rsr, progress, skipPresim, nil)
+}
+
+func runSimWithLogEvents(ctx context.Context, rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool, onLogEvent LogEventFunc) (result *proto.RaidSimResult) {
 	if !rsr.SimOptions.IsTest {
 		defer func() {
 			if err := recover(); err != nil {
@@ -136,7 +202,32 @@ func runSim(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, ski
 		}()
 	}
 
+	if rsr.SimOptions.ExpectedValue && rsr.SimOptions.Iterations != 1 {
+		rsr = googleProto.Clone(rsr).(*proto.RaidSimRequest)
+		rsr.SimOptions.Iterations = 1
+	}
+
+	var externalAssignments []*proto.ExternalAssignment
+	if rsr.ExternalsOptimizer != nil && rsr.ExternalsOptimizer.Enabled {
+		optimized, assignments, err := optimizeExternals(rsr)
+		if err != nil {
+			result := &proto.RaidSimResult{
+				ErrorResult: err.Error(),
+			}
+			if progress != nil {
+				progress <- &proto.ProgressMetrics{
+					FinalRaidResult: result,
+				}
+			}
+			return result
+		}
+		rsr, externalAssignments = optimized, assignments
+	}
+
 	sim := NewSim(rsr)
+	sim.ctx = ctx
+	sim.externalAssignments = externalAssignments
+	sim.onLogEvent = onLogEvent
 
 	if !skipPresim {
 		if progress != nil {
@@ -166,11 +257,21 @@ func runSim(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, ski
 			}
 			runtime.Gosched() // allow time for message to make it back out.
 		}
-		// Use pre-sim as estimate for length of fight (when using health fight)
-		if sim.Encounter.EndFightAtHealth > 0 && presimResult != nil {
-			sim.BaseDuration = time.Duration(presimResult.AvgIterationDuration) * time.Second
-			sim.Duration = time.Duration(presimResult.AvgIterationDuration) * time.Second
-			sim.Encounter.DurationIsEstimate = false // we now have a pretty good value for duration
+		sim.usePresimDuration(presimResult)
+	}
+
+	if sim.numWorkers() > 1 {
+		sim.newWorker = func() *Simulation {
+			// Presims use a fixed seed, so each worker ends up with the same
+			// settings as the main sim. Workers start concurrently, so each
+			// builds from its own copy of the request.
+			workerRequest := googleProto.Clone(rsr).(*proto.RaidSimRequest)
+			worker := NewSim(workerRequest)
+			worker.ctx = ctx
+			if !skipPresim {
+				worker.usePresimDuration(worker.runPresims(workerRequest))
+			}
+			return worker
 		}
 	}
 
@@ -180,6 +281,41 @@ func runSim(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, ski
 	return result
 }
 
+// Normally distributed durations are truncated to this many standard
+// deviations from the mean.
+const normalDurationCutoff = 3
+
+// Picks the duration of the next iteration from the encounter's duration
+// distribution.
+func (sim *Simulation) rollDuration() time.Duration {
+	roll := sim.RandomFloat("sim duration")
+	switch sim.Encounter.DurationDistribution {
+	case proto.DurationDistribution_DurationDistributionNormal:
+		// Maps the roll through the inverse CDF of the truncated normal
+		// distribution, which keeps one roll per iteration.
+		cdf := func(z float64) float64 { return 0.5 * (1 + math.Erf(z/math.Sqrt2)) }
+		lo, hi := cdf(-normalDurationCutoff), cdf(normalDurationCutoff)
+		z := math.Sqrt2 * math.Erfinv(2*(lo+roll*(hi-lo))-1)
+		return max(sim.BaseDuration+time.Duration(z*float64(sim.DurationVariation)), time.Second)
+	case proto.DurationDistribution_DurationDistributionEmpirical:
+		if len(sim.Encounter.KillTimes) > 0 {
+			return sim.Encounter.KillTimes[min(int(roll*float64(len(sim.Encounter.KillTimes))), len(sim.Encounter.KillTimes)-1)]
+		}
+	}
+
+	variation := sim.DurationVariation * 2
+	return sim.BaseDuration + time.Duration(roll*float64(variation)) - sim.DurationVariation
+}
+
+// Use pre-sim as estimate for length of fight (when using health fight)
+func (sim *Simulation) usePresimDuration(presimResult *proto.RaidSimResult) {
+	if sim.Encounter.EndFightAtHealth > 0 && presimResult != nil {
+		sim.BaseDuration = time.Duration(presimResult.AvgIterationDuration) * time.Second
+		sim.Duration = time.Duration(presimResult.AvgIterationDuration) * time.Second
+		sim.Encounter.DurationIsEstimate = false // we now have a pretty good value for duration
+	}
+}
+
 func NewSim(rsr *proto.RaidSimRequest) *Simulation {
 	env, _, _ := NewEnvironment(rsr.Raid, rsr.Encounter, false)
 	return newSimWithEnv(env, rsr.SimOptions)
@@ -187,20 +323,53 @@ func NewSim(rsr *proto.RaidSimRequest) *Simulation {
 
 func newSimWithEnv(env *Environment, simOptions *proto.SimOptions) *Simulation {
 	rseed := simOptions.RandomSeed
-	if rseed == 0 {
+	if simOptions.ExpectedValue {
+		// Rolls which aren't replaced by expected values still have to come
+		// out the same for every seed.
+		rseed = 1
+	} else if rseed == 0 {
 		rseed = time.Now().UnixNano()
 	}
 
-	return &Simulation{
+	if simOptions.Timeline != nil && simOptions.Timeline.Iterations > 0 {
+		env.enableTimelines(simOptions.Timeline, timelineIterations(simOptions.Timeline, lowMemoryMode))
+	}
+	if simOptions.ResourceTimelines {
+		env.enableResourceTimelines()
+	}
+
+	sim := &Simulation{
 		Environment: env,
 		Options:     simOptions,
 
 		rand:  NewSplitMix(uint64(rseed)),
 		rseed: rseed,
 
-		isTest:    simOptions.IsTest,
-		testRands: make(map[string]Rand),
+		lowMemory: lowMemoryMode,
+
+		isTest:        simOptions.IsTest,
+		labeledRands:  simOptions.IsTest || simOptions.LabeledRngStreams || simOptions.ExpectedValue,
+		expectedValue: simOptions.ExpectedValue,
+		labelRands:    make(map[string]Rand),
+	}
+	if simOptions.ExpectedValue {
+		sim.expectedProcs = make(map[any]float64)
+	}
+	if simOptions.RngDiagnostics {
+		sim.randomStreamStats = make(map[string]*randomStreamStats)
 	}
+	if buckets := simOptions.DamageBuckets; buckets != nil && buckets.WidthMs > 0 {
+		sim.damageBucketWidth = time.Duration(buckets.WidthMs) * time.Millisecond
+		sim.damageBucketsFirstOnly = buckets.FirstIterationOnly
+	}
+	sim.convergenceBatchSize = convergenceBatchSize(simOptions)
+	if simOptions.OutlierIterations > 0 {
+		sim.outliers = newOutlierTracker(simOptions.OutlierIterations)
+	}
+	if simOptions.PendingActionQueue == proto.PendingActionQueue_PendingActionQueueTimeWheel {
+		sim.pendingActions.wheel = newPendingActionWheel()
+	}
+	return sim
 }
 
 // Returns a random float64 between 0.0 (inclusive) and 1.0 (exclusive).
@@ -208,41 +377,87 @@ func newSimWithEnv(env *Environment, simOptions *proto.SimOptions) *Simulation {
 // In tests, although we can set the initial seed, test results are still very
 // sensitive to the exact order of RandomFloat() calls. To mitigate this, when
 // testing we use a separate rand object for each RandomFloat callsite,
-// distinguished by the label string.
+// distinguished by the label string. The same is done outside of tests when
+// SimOptions.labeled_rng_streams is set.
 func (sim *Simulation) RandomFloat(label string) float64 {
-	return sim.labelRand(label).NextFloat64()
+	roll := sim.labelRand(label).NextFloat64()
+	if sim.antithetic {
+		roll = 1 - 0x1p-53 - roll
+	}
+	if sim.randomStreamStats != nil {
+		sim.recordRandomRoll(label, roll)
+	}
+	return roll
 }
 
 func (sim *Simulation) labelRand(label string) Rand {
-	if !sim.isTest {
+	if !sim.labeledRands {
 		return sim.rand
 	}
 
-	labelRng, ok := sim.testRands[label]
+	labelRng, ok := sim.labelRands[label]
 	if !ok {
+		// Streams are seeded from the current iteration, so that each iteration
+		// can be replayed on its own. Tests keep seeding from the initial seed,
+		// so that their results are unchanged.
+		seed := sim.rand.GetSeed()
+		if sim.isTest {
+			seed = sim.rseed
+		}
+
 		// Add rseed to the label, so we still have run-run variance for stat weights.
-		labelRng = NewSplitMix(uint64(makeTestRandSeed(sim.rseed, label)))
-		sim.testRands[label] = labelRng
+		labelRng = NewSplitMix(uint64(makeTestRandSeed(seed, label)))
+		sim.labelRands[label] = labelRng
 	}
 	return labelRng
 }
 
 func (sim *Simulation) reseedRands(i int64) {
+	sim.iteration = int32(i)
 	rseed := sim.Options.RandomSeed + i
+	if i == 0 {
+		// Same as the initial seed, which may have been picked at random.
+		rseed = sim.rseed
+	}
+	if sim.Options.AntitheticSampling {
+		// Both iterations of a pair share a seed, and the second one mirrors
+		// the rolls of the first.
+		rseed = sim.rseed + i/2
+		sim.antithetic = i%2 == 1
+	}
 	sim.rand.Seed(rseed)
 
-	if sim.isTest {
-		for label, rng := range sim.testRands {
+	if sim.labeledRands {
+		for label, rng := range sim.labelRands {
 			rng.Seed(makeTestRandSeed(rseed, label))
 		}
 	}
 }
 
+// Sets up sims which are compared against each other, e.g. bulk sim combos, to
+// use common random numbers: the same fixed seed, and a random stream per
+// label. Each spell then sees the same rolls in every sim, so the differences
+// come from the settings rather than from luck.
+func useCommonRandomNumbers(simOptions *proto.SimOptions) {
+	// Without a user-supplied seed this still has to be random, so that
+	// run-to-run differences still exist.
+	if simOptions.RandomSeed == 0 {
+		simOptions.RandomSeed = time.Now().UnixNano()
+	}
+	simOptions.LabeledRngStreams = true
+}
+
 func makeTestRandSeed(rseed int64, label string) int64 {
 	return int64(hash(label + strconv.FormatInt(rseed, 16)))
 }
 
 func (sim *Simulation) RandomExpFloat(label string) float64 {
+	if sim.expectedValue {
+		return 1 // The mean of the exponential distribution.
+	}
+	if sim.antithetic {
+		return rand.New(antitheticRand{sim.labelRand(label)}).ExpFloat64()
+	}
 	return rand.New(sim.labelRand(label)).ExpFloat64()
 }
 
@@ -252,6 +467,9 @@ func (sim *Simulation) Roll(min float64, max float64) float64 {
 	return sim.RollWithLabel(min, max, "Damage Roll")
 }
 func (sim *Simulation) RollWithLabel(min float64, max float64, label string) float64 {
+	if sim.expectedValue {
+		return (min + max) / 2 // The mean of the roll.
+	}
 	return min + (max-min)*sim.RandomFloat(label)
 }
 
@@ -261,6 +479,8 @@ func (sim *Simulation) Proc(p float64, label string) bool {
 		return true
 	case p <= 0:
 		return false
+	case sim.expectedValue:
+		return sim.expectedProc(label, p)
 	default:
 		return sim.RandomFloat(label) < p
 	}
@@ -279,15 +499,21 @@ func (sim *Simulation) Reseed(seed int64) {
 func (sim *Simulation) run() *proto.RaidSimResult {
 	t0 := time.Now()
 
-	logsBuffer := &strings.Builder{}
+	if sim.Options.ReplayIteration != nil {
+		return sim.runReplay(sim.Options.ReplayIteration.Index)
+	}
+	if sim.newWorker != nil {
+		return sim.runConcurrent(t0)
+	}
+
+	eventLog := sim.newEventLog()
 	if sim.Options.Debug || sim.Options.DebugFirstIteration {
-		sim.Log = func(message string, vals ...interface{}) {
-			logsBuffer.WriteString(fmt.Sprintf("[%0.2f] "+message+"\n", append([]interface{}{sim.CurrentTime.Seconds()}, vals...)...))
-		}
+		sim.startLogging(eventLog)
 	}
 
 	// Uncomment this to print logs directly to console.
 	// sim.Options.Debug = true
+	// sim.eventLog = nil
 	// sim.Log = func(message string, vals ...interface{}) {
 	// 	fmt.Printf(fmt.Sprintf("[%0.1f] "+message+"\n", append([]interface{}{sim.CurrentTime.Seconds()}, vals...)...))
 	// }
@@ -303,10 +529,26 @@ func (sim *Simulation) run() *proto.RaidSimResult {
 		sim.Log = nil
 	}
 
+	completed := int32(1)
+	cancelled := false
 	var st time.Time
 	for i := int32(1); i < sim.Options.Iterations; i++ {
+		if sim.reachedTargetConfidence(i) {
+			break
+		}
+		if sim.ctx != nil && sim.ctx.Err() != nil {
+			cancelled = true
+			break
+		}
+
 		// fmt.Printf("Iteration: %d\n", i)
-		if sim.ProgressReport != nil && time.Since(st) > time.Millisecond*100 {
+		if interval := sim.Options.ProgressInterval; interval > 0 {
+			if sim.ProgressReport != nil && i%interval == 0 {
+				metrics := sim.Raid.GetMetrics()
+				sim.ProgressReport(&proto.ProgressMetrics{TotalIterations: sim.Options.Iterations, CompletedIterations: i, Dps: metrics.Dps.Avg, Hps: metrics.Hps.Avg, RaidMetrics: metrics})
+				runtime.Gosched()
+			}
+		} else if sim.ProgressReport != nil && time.Since(st) > time.Millisecond*100 {
 			metrics := sim.Raid.GetMetrics()
 			sim.ProgressReport(&proto.ProgressMetrics{TotalIterations: sim.Options.Iterations, CompletedIterations: i, Dps: metrics.Dps.Avg, Hps: metrics.Hps.Avg})
 			runtime.Gosched() // ensure that reporting threads are given time to report, mostly only important in wasm (only 1 thread)
@@ -322,34 +564,109 @@ func (sim *Simulation) run() *proto.RaidSimResult {
 			iterDuration = sim.CurrentTime
 		}
 		totalDuration += iterDuration
+		completed++
 	}
+	result := sim.newResult(eventLog.Format(sim.Options.LogFormat), firstIterationDuration, totalDuration, completed, cancelled)
+	sim.finishRun(result, completed, t0)
+	return result
+}
+
+func (sim *Simulation) newResult(logs string, firstIterationDuration time.Duration, totalDuration time.Duration, completed int32, cancelled bool) *proto.RaidSimResult {
 	result := &proto.RaidSimResult{
 		RaidMetrics:      sim.Raid.GetMetrics(),
 		EncounterMetrics: sim.Encounter.GetMetricsProto(),
 
-		Logs:                   logsBuffer.String(),
+		Logs:                   logs,
 		FirstIterationDuration: firstIterationDuration.Seconds(),
-		AvgIterationDuration:   totalDuration.Seconds() / float64(sim.Options.Iterations),
+		AvgIterationDuration:   totalDuration.Seconds() / float64(completed),
+
+		ExternalAssignments: sim.externalAssignments,
+		Cancelled:           cancelled,
+	}
+	for _, party := range sim.Raid.Parties {
+		for _, player := range party.Players {
+			character := player.GetCharacter()
+			for _, warning := range character.Warnings {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %s", character.Name, warning))
+			}
+		}
+	}
+	if sim.expectedValue {
+		result.Warnings = append(result.Warnings, "Expected value mode: results are an estimate from a single iteration. Damage uses the average over hit, crit and avoidance chances, so effects which depend on crits or exact timing outside of proc triggers are only approximated. Confirm close comparisons with a normal sim.")
 	}
+	if sim.randomStreamStats != nil {
+		result.RandomStreams = sim.randomStreamsProto()
+	}
+	if sim.Options.TargetConfidence != nil {
+		result.DpsConfidence = sim.dpsConfidenceProto(completed)
+	}
+	if sim.outliers != nil {
+		result.HighestIterations, result.LowestIterations = sim.replayOutliers()
+	}
+	return result
+}
+
+func (sim *Simulation) finishRun(result *proto.RaidSimResult, completed int32, t0 time.Time) {
+	sim.allocAudit.record()
 
 	// Final progress report
 	if sim.ProgressReport != nil {
-		sim.ProgressReport(&proto.ProgressMetrics{TotalIterations: sim.Options.Iterations, CompletedIterations: sim.Options.Iterations, Dps: result.RaidMetrics.Dps.Avg, FinalRaidResult: result})
+		sim.ProgressReport(&proto.ProgressMetrics{TotalIterations: sim.Options.Iterations, CompletedIterations: completed, Dps: result.RaidMetrics.Dps.Avg, FinalRaidResult: result})
 	}
 
 	if d := sim.Options.Iterations; d > 3000 {
 		log.Printf("running %d iterations took %s", d, time.Since(t0))
 	}
+}
 
-	return result
+// Runs only the given iteration, with debug logs. RNG state only depends on
+// the seed and iteration index, but health-based fights still estimate their
+// duration from the presim, so those may not match the original run exactly.
+func (sim *Simulation) runReplay(index int32) *proto.RaidSimResult {
+	if sim.Options.RandomSeed == 0 {
+		panic("Replaying an iteration requires a fixed random seed")
+	}
+	if index < 0 || (sim.Options.Iterations > 0 && index >= sim.Options.Iterations) {
+		panic(fmt.Sprintf("Invalid replay iteration %d for %d iterations", index, sim.Options.Iterations))
+	}
+
+	eventLog := sim.newEventLog()
+	sim.startLogging(eventLog)
+
+	sim.reseedRands(int64(index))
+	sim.runOnce()
+
+	iterDuration := sim.Duration
+	if sim.Encounter.EndFightAtHealth != 0 {
+		iterDuration = sim.CurrentTime
+	}
+
+	return &proto.RaidSimResult{
+		RaidMetrics:      sim.Raid.GetMetrics(),
+		EncounterMetrics: sim.Encounter.GetMetricsProto(),
+
+		Logs:                   eventLog.Format(sim.Options.LogFormat),
+		FirstIterationDuration: iterDuration.Seconds(),
+		AvgIterationDuration:   iterDuration.Seconds(),
+
+		ExternalAssignments: sim.externalAssignments,
+	}
 }
 
 // RunOnce is the main event loop. It will run the simulation for number of seconds.
 func (sim *Simulation) runOnce() {
+	sim.allocAudit.startIteration(sim.iteration)
+	if simInvariantsEnabled {
+		sim.invariants.startIteration(sim)
+	}
 	sim.reset()
 	sim.PrePull()
 	sim.runPendingActions()
 	sim.Cleanup()
+	if simInvariantsEnabled {
+		sim.invariants.doneIteration(sim)
+	}
+	sim.allocAudit.doneIteration()
 }
 
 var (
@@ -374,17 +691,19 @@ func (sim *Simulation) reset() {
 		sim.Encounter.DurationIsEstimate = false
 	}
 	sim.Duration = sim.BaseDuration
-	if sim.DurationVariation != 0 {
-		variation := sim.DurationVariation * 2
-		sim.Duration += time.Duration(sim.RandomFloat("sim duration")*float64(variation)) - sim.DurationVariation
+	if sim.DurationVariation != 0 && !sim.expectedValue {
+		sim.Duration = sim.rollDuration()
 	}
 
-	sim.pendingActions = sim.pendingActions[:0]
-	sim.pendingActions = append(sim.pendingActions, sentinelPendingAction)
+	sim.pendingActions.reset()
+	sim.pendingActions.push(sentinelPendingAction)
+
+	clear(sim.expectedProcs)
 
 	sim.executePhase = 0
 	sim.nextExecutePhase()
 	sim.executePhaseCallbacks = nil
+	sim.execute35Start = NeverExpires
 
 	// Use duration as an end check if not using health.
 	sim.endOfCombatDuration = sim.Duration
@@ -405,6 +724,10 @@ func (sim *Simulation) reset() {
 	sim.tasks = sim.tasks[:0]
 	sim.minTaskTime = NeverExpires
 
+	if sim.resourceTickAlignment() == proto.ResourceTickAlignment_ResourceTickAlignmentShared {
+		sim.resourceTickPhase = sim.RandomFloat("Resource Tick Phase")
+	}
+
 	sim.Environment.reset(sim)
 
 	sim.initManaTickAction()
@@ -415,10 +738,20 @@ func (sim *Simulation) PrePull() {
 		sim.CurrentTime = sim.prepullActions[0].DoAt
 
 		for i, ppa := range sim.prepullActions {
+			ppa := ppa
+			onAction := ppa.Action
+			if ppa.Unit != nil {
+				onAction = func(sim *Simulation) {
+					if sim.Log != nil {
+						ppa.Unit.Log(sim, "Prepull action: %s", ppa.ActionID)
+					}
+					ppa.Action(sim)
+				}
+			}
 			sim.AddPendingAction(&PendingAction{
 				NextActionAt: ppa.DoAt,
 				Priority:     ActionPriorityPrePull + ActionPriority(len(sim.prepullActions)-i),
-				OnAction:     ppa.Action,
+				OnAction:     onAction,
 			})
 		}
 	}
@@ -432,6 +765,14 @@ func (sim *Simulation) PrePull() {
 					unit.startPull(sim)
 				}
 			}
+			for _, party := range sim.Raid.Parties {
+				for _, player := range party.Players {
+					character := player.GetCharacter()
+					// Cooldowns used on the prepull aren't ready at the pull.
+					character.UpdateMajorCooldowns()
+					character.recordStatCaps()
+				}
+			}
 		},
 	})
 }
@@ -443,19 +784,22 @@ func (sim *Simulation) Cleanup() {
 	// intuitive.
 	sim.CurrentTime = sim.Duration
 
-	for _, pa := range sim.pendingActions {
+	sim.pendingActions.forEach(func(pa *PendingAction) {
 		if pa.CleanUp != nil {
 			pa.CleanUp(sim)
 		}
-	}
+	})
 
 	sim.Raid.doneIteration(sim)
 	sim.Encounter.doneIteration(sim)
+	if sim.outliers != nil {
+		sim.outliers.add(outlierIteration{iteration: sim.iteration, dps: sim.Raid.dpsMetrics.Total / sim.Duration.Seconds()})
+	}
 
 	for _, unit := range sim.Raid.AllUnits {
 		unit.Metrics.doneIteration(unit, sim)
 	}
-	for _, target := range sim.Encounter.TargetUnits {
+	for _, target := range sim.Encounter.AllTargetUnits {
 		target.Metrics.doneIteration(target, sim)
 	}
 }
@@ -465,12 +809,14 @@ func (sim *Simulation) runPendingActions() {
 		if finished := sim.Step(); finished {
 			return
 		}
+		if simInvariantsEnabled {
+			sim.checkInvariants()
+		}
 	}
 }
 
 func (sim *Simulation) Step() bool {
-	last := len(sim.pendingActions) - 1
-	pa := sim.pendingActions[last]
+	pa := sim.pendingActions.peek()
 
 	if pa.NextActionAt >= sim.minWeaponAttackTime && sim.minWeaponAttackTime <= sim.minTaskTime {
 		if sim.minWeaponAttackTime > sim.endOfCombatDuration || sim.Encounter.DamageTaken > sim.endOfCombatDamage {
@@ -488,12 +834,14 @@ func (sim *Simulation) Step() bool {
 		return false
 	}
 
-	sim.pendingActions = sim.pendingActions[:last]
+	sim.pendingActions.pop()
 	if pa.cancelled {
+		pa.queued = false
 		return false
 	}
 
 	if pa.NextActionAt > sim.endOfCombatDuration || sim.Encounter.DamageTaken > sim.endOfCombatDamage {
+		pa.queued = false
 		return true
 	}
 
@@ -501,6 +849,7 @@ func (sim *Simulation) Step() bool {
 		sim.advance(pa.NextActionAt)
 	}
 	pa.consumed = true
+	pa.queued = false
 
 	if pa.cancelled {
 		return false
@@ -533,12 +882,27 @@ func (sim *Simulation) advanceTasks() {
 
 // Advance moves time forward counting down auras, CDs, mana regen, etc
 func (sim *Simulation) advance(nextTime time.Duration) {
+	// The rest of the raid only starts attacking at the pull.
+	unsimulatedDamage := 0.0
+	if sim.Encounter.UnsimulatedRaidDps > 0 && nextTime > 0 {
+		unsimulatedDamage = sim.Encounter.UnsimulatedRaidDps * (nextTime - max(sim.CurrentTime, 0)).Seconds()
+	}
 	sim.CurrentTime = nextTime
+	if unsimulatedDamage > 0 {
+		if sim.Encounter.healthPools != nil {
+			sim.Encounter.damageHealthPools(sim, unsimulatedDamage)
+		} else {
+			sim.Encounter.DamageTaken += unsimulatedDamage
+		}
+	}
 
 	// this is a loop to handle duplicate ExecuteProportions, e.g. if they're all set to 100%, you reach
 	// execute phases 35%, 25%, and 20% in the first advance() call.
 	for sim.CurrentTime >= sim.nextExecuteDuration || sim.Encounter.DamageTaken >= sim.nextExecuteDamage {
 		sim.nextExecutePhase()
+		if sim.executePhase == 35 {
+			sim.execute35Start = sim.CurrentTime
+		}
 		for _, callback := range sim.executePhaseCallbacks {
 			callback(sim, sim.executePhase)
 		}
@@ -556,8 +920,8 @@ func (sim *Simulation) advance(nextTime time.Duration) {
 func (sim *Simulation) nextExecutePhase() {
 	setup := func(phase int32, damage float64, health float64) {
 		sim.executePhase = phase
-		if sim.Encounter.EndFightAtHealth > 0 {
-			sim.nextExecuteDamage = (1 - damage) * sim.Encounter.EndFightAtHealth
+		if sim.Encounter.ExecuteAtHealth > 0 {
+			sim.nextExecuteDamage = (1 - damage) * sim.Encounter.ExecuteAtHealth
 		} else {
 			sim.nextExecuteDuration = time.Duration((1 - health) * float64(sim.Duration))
 		}
@@ -581,28 +945,14 @@ func (sim *Simulation) nextExecutePhase() {
 }
 
 func (sim *Simulation) AddPendingAction(pa *PendingAction) {
-	//if pa.NextActionAt < sim.CurrentTime {
-	//	panic(fmt.Sprintf("Cant add action in the past: %s", pa.NextActionAt))
-	//}
+	sim.allocAudit.begin(allocSectionScheduling)
+	sim.addPendingAction(pa)
+	sim.allocAudit.end()
+}
+func (sim *Simulation) addPendingAction(pa *PendingAction) {
 	pa.consumed = false
-	for index, v := range sim.pendingActions[1:] {
-		if v.NextActionAt < pa.NextActionAt || (v.NextActionAt == pa.NextActionAt && v.Priority >= pa.Priority) {
-			//if sim.Log != nil {
-			//	sim.Log("Adding action at index %d for time %s", index - len(sim.pendingActions), pa.NextActionAt)
-			//	for i := index; i < len(sim.pendingActions); i++ {
-			//		sim.Log("Upcoming action at %s", sim.pendingActions[i].NextActionAt)
-			//	}
-			//}
-			sim.pendingActions = append(sim.pendingActions, pa)
-			copy(sim.pendingActions[index+2:], sim.pendingActions[index+1:])
-			sim.pendingActions[index+1] = pa
-			return
-		}
-	}
-	//if sim.Log != nil {
-	//	sim.Log("Adding action at end for time %s", pa.NextActionAt)
-	//}
-	sim.pendingActions = append(sim.pendingActions, pa)
+	pa.queued = true
+	sim.pendingActions.push(pa)
 }
 
 func (sim *Simulation) RegisterExecutePhaseCallback(callback func(sim *Simulation, isExecute int32)) {
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Invariant checking validates the state of every unit after each step of the
+// sim, so that core bugs panic where they happen instead of quietly skewing
+// results. Checking and logging every step is slow, so it's only compiled in
+// with the 'sim_invariants' build tag:
+//
+//	go test --tags=with_db,sim_invariants ./sim/...
+//
+// Violations panic with the random seed and iteration, which can be replayed
+// with SimOptions.replay_iteration, and the tail of the iteration's logs.
+
+// Number of log events included when an invariant is violated.
+const invariantLogTail = 40
+
+type simInvariants struct {
+	// Set while the invariant checks are recording the logs, because debug
+	// logs weren't enabled for the iteration.
+	eventLog *EventLog
+}
+
+func (inv *simInvariants) startIteration(sim *Simulation) {
+	if sim.Log == nil {
+		inv.eventLog = &EventLog{}
+		sim.startLogging(inv.eventLog)
+	}
+}
+
+func (inv *simInvariants) doneIteration(sim *Simulation) {
+	if inv.eventLog != nil {
+		sim.Log = nil
+		sim.eventLog = nil
+		inv.eventLog = nil
+	}
+}
+
+// Panics with the violation, and what's needed to reproduce it.
+func (sim *Simulation) invariantViolation(format string, args ...interface{}) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Sim invariant violated at %s: %s\n", sim.CurrentTime, fmt.Sprintf(format, args...))
+	fmt.Fprintf(&sb, "Random seed %d, iteration %d.\n", sim.rseed, sim.iteration)
+	if sim.eventLog != nil {
+		tail := make([]*LogEvent, 0, invariantLogTail)
+		sim.eventLog.Each(func(event *LogEvent) {
+			if len(tail) == invariantLogTail {
+				tail = append(tail[:0], tail[1:]...)
+			}
+			tail = append(tail, event)
+		})
+		fmt.Fprintf(&sb, "Last %d log events:\n", len(tail))
+		for _, event := range tail {
+			fmt.Fprintf(&sb, "[%0.2f] %s\n", event.Timestamp.Seconds(), event.Line())
+		}
+	}
+	panic(sb.String())
+}
+
+// Checks the state of all units. Called after each step of the sim, when
+// built with 'sim_invariants'.
+func (sim *Simulation) checkInvariants() {
+	for _, unit := range sim.AllUnits {
+		unit.checkInvariants(sim)
+	}
+}
+
+func (unit *Unit) checkInvariants(sim *Simulation) {
+	// Mana isn't clamped when its maximum drops, e.g. when an intellect buff
+	// fades, so only its lower bound is checked. Health isn't checked, since
+	// units without health stats, e.g. fake agents in tests, start below 0.
+	if unit.HasManaBar() {
+		unit.checkResource(sim, "mana", unit.CurrentMana(), math.Inf(1))
+	}
+	if unit.HasRageBar() {
+		unit.checkResource(sim, "rage", unit.CurrentRage(), MaxRage)
+	}
+	if unit.HasEnergyBar() {
+		unit.checkResource(sim, "energy", unit.CurrentEnergy(), unit.maxEnergy)
+	}
+	if unit.HasFocusBar() {
+		unit.checkResource(sim, "focus", unit.CurrentFocus(), MaxFocus)
+	}
+	if unit.HasRunicPowerBar() {
+		unit.checkResource(sim, "runic power", unit.CurrentRunicPower(), unit.maxRunicPower)
+	}
+
+	for _, timer := range unit.cdTimers {
+		if timer.ReadyAt() < startingCDTime {
+			sim.invariantViolation("%s has a timer ready at %s, before its reset time", unit.Label, timer.ReadyAt())
+		}
+	}
+	for _, spell := range unit.Spellbook {
+		if spell.CD.Duration < 0 || spell.SharedCD.Duration < 0 {
+			sim.invariantViolation("%s %s has a negative cooldown", unit.Label, spell.ActionID)
+		}
+	}
+
+	for _, aura := range unit.auras {
+		if aura.stacks < 0 || aura.stacks > max(aura.MaxStacks, 0) {
+			sim.invariantViolation("%s aura %s has %d stacks, max is %d", unit.Label, aura.Label, aura.stacks, aura.MaxStacks)
+		}
+		if !aura.active {
+			if aura.stacks != 0 {
+				sim.invariantViolation("%s aura %s is inactive with %d stacks", unit.Label, aura.Label, aura.stacks)
+			}
+			continue
+		}
+		// Disabled pets' auras aren't advanced, but raid auras like
+		// Replenishment are still applied to them. Auras activated as permanent
+		// never expire, even if they're refreshed with a shorter duration later,
+		// e.g. Blood Frenzy when it's also a raid debuff.
+		if aura.activeIndex != Inactive && aura.expires < sim.CurrentTime && unit.IsEnabled() {
+			sim.invariantViolation("%s aura %s is still active, %s after it expired", unit.Label, aura.Label, sim.CurrentTime-aura.expires)
+		}
+	}
+}
+
+func (unit *Unit) checkResource(sim *Simulation, name string, current float64, maximum float64) {
+	if math.IsNaN(current) || current < 0 || current > maximum {
+		sim.invariantViolation("%s has %f %s, expected between 0 and %f", unit.Label, current, name, maximum)
+	}
+}
+
+// Checks that a result is still in use when it's dealt. Otherwise it has
+// already been dealt or disposed, and might have been reused by another
+// calculation since.
+func (spell *Spell) checkResultInUse(sim *Simulation, result *SpellResult) {
+	if !result.inUse {
+		sim.invariantViolation("%s %s dealt a result which was already disposed", spell.Unit.Label, spell.ActionID)
+	}
+}
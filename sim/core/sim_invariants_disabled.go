@@ -0,0 +1,5 @@
+//go:build !sim_invariants
+
+package core
+
+const simInvariantsEnabled = false
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func expectInvariantViolation(t *testing.T, expected string, check func()) {
+	t.Helper()
+	defer func() {
+		message, _ := recover().(string)
+		if !strings.Contains(message, expected) {
+			t.Errorf("Expected violation %q, got %q", expected, message)
+		}
+		if !strings.Contains(message, "Random seed 100, iteration 0") {
+			t.Errorf("Expected the seed and iteration in %q", message)
+		}
+	}()
+	check()
+}
+
+func TestSimInvariants(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	sim.startLogging(&EventLog{})
+	sim.Log("Before the violation")
+
+	fa.Dot.Apply(sim)
+	sim.checkInvariants()
+
+	fa.Dot.stacks = 2
+	expectInvariantViolation(t, "has 2 stacks, max is 0", sim.checkInvariants)
+	expectInvariantViolation(t, "Before the violation", sim.checkInvariants)
+	fa.Dot.stacks = 0
+
+	// Permanent auras stay active when refreshed with a finite duration.
+	permanent := MakePermanent(fa.RegisterAura(Aura{Label: "Permanent"}))
+	permanent.Activate(sim)
+	permanent.Duration = time.Second
+	permanent.Activate(sim)
+	sim.CurrentTime += 2 * time.Second
+	sim.checkInvariants()
+	permanent.Deactivate(sim)
+
+	fa.Dot.Aura.expires = sim.CurrentTime - time.Second
+	expectInvariantViolation(t, "is still active, 1s after it expired", sim.checkInvariants)
+	fa.Dot.Aura.expires = sim.CurrentTime
+
+	result := fa.Spell.NewResult(sim.GetTargetUnit(0))
+	fa.Spell.checkResultInUse(sim, result)
+	fa.Spell.DisposeResult(result)
+	expectInvariantViolation(t, "dealt a result which was already disposed", func() {
+		fa.Spell.checkResultInUse(sim, result)
+	})
+}
@@ -0,0 +1,316 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestReplayIteration(t *testing.T) {
+	for _, labeledRngStreams := range []bool{false, true} {
+		request := func(simOptions *proto.SimOptions) *proto.RaidSimRequest {
+			simOptions.RandomSeed = 101
+			simOptions.Interactive = true
+			simOptions.LabeledRngStreams = labeledRngStreams
+			return &proto.RaidSimRequest{
+				SimOptions: simOptions,
+				Raid: &proto.Raid{
+					Parties: []*proto.Party{{
+						Players: []*proto.Player{{
+							Name:      "Tank",
+							Class:     proto.Class_ClassShaman,
+							Consumes:  &proto.Consumes{},
+							Buffs:     &proto.IndividualBuffs{},
+							Spec:      &proto.Player_ElementalShaman{},
+							Equipment: &proto.EquipmentSpec{},
+						}},
+						Buffs: &proto.PartyBuffs{},
+					}},
+					Tanks: []*proto.UnitReference{{Type: proto.UnitReference_Player, Index: 0}},
+				},
+				Encounter: &proto.Encounter{
+					Duration:          20,
+					DurationVariation: 5,
+					Targets: []*proto.Target{{
+						Level:         83,
+						MinBaseDamage: 1000,
+						SwingSpeed:    2,
+					}},
+				},
+			}
+		}
+
+		full := RunRaidSim(request(&proto.SimOptions{Iterations: 3, Debug: true}))
+		if full.ErrorResult != "" {
+			t.Fatalf("Sim failed: %s", full.ErrorResult)
+		}
+		iterationLogs := splitIterationLogs(full.Logs)
+		if len(iterationLogs) != 3 {
+			t.Fatalf("Expected logs for 3 iterations, got %d", len(iterationLogs))
+		}
+
+		replay := RunRaidSim(request(&proto.SimOptions{Iterations: 3, ReplayIteration: &proto.ReplayIteration{Index: 2}}))
+		if replay.ErrorResult != "" {
+			t.Fatalf("Replay failed: %s", replay.ErrorResult)
+		}
+
+		expected := iterationLogs[2]
+		actual := splitIterationLogs(replay.Logs)[0]
+		if actual != expected {
+			t.Errorf("Replayed logs (labeled streams: %t) differ from iteration 2 of the full sim.\nExpected:\n%s\nGot:\n%s", labeledRngStreams, expected, actual)
+		}
+	}
+}
+
+// Splits debug logs on the SIM RESET header which starts each iteration.
+func splitIterationLogs(logs string) []string {
+	var iterations []string
+	lines := strings.SplitAfter(logs, "\n")
+	for i := 0; i < len(lines); i++ {
+		if strings.HasSuffix(lines[i], " SIM RESET\n") {
+			iterations = append(iterations, "")
+			i++ // Skip the divider line.
+			continue
+		}
+		if len(iterations) > 0 {
+			iterations[len(iterations)-1] += lines[i]
+		}
+	}
+	return iterations
+}
+
+func TestAntitheticSampling(t *testing.T) {
+	const seed = 5
+	for _, labeled := range []bool{false, true} {
+		sim := &Simulation{
+			Options:      &proto.SimOptions{RandomSeed: seed, AntitheticSampling: true},
+			rand:         NewSplitMix(seed),
+			rseed:        seed,
+			labeledRands: labeled,
+			labelRands:   make(map[string]Rand),
+		}
+
+		for pair := int64(0); pair < 2; pair++ {
+			sim.reseedRands(2 * pair)
+			first := []float64{sim.RandomFloat("A"), sim.RandomFloat("B"), sim.RandomFloat("A")}
+
+			sim.reseedRands(2*pair + 1)
+			for i, label := range []string{"A", "B", "A"} {
+				if roll := sim.RandomFloat(label); roll+first[i] != 1-0x1p-53 {
+					t.Fatalf("Pair %d roll %d: expected %v to mirror %v", pair, i, roll, first[i])
+				}
+			}
+		}
+	}
+}
+
+func TestExpectedValueMode(t *testing.T) {
+	var dtps []float64
+	for _, seed := range []int64{1, 2} {
+		result := RunRaidSim(tankSimRequest(&proto.SimOptions{Iterations: 100, RandomSeed: seed, ExpectedValue: true}))
+		if result.ErrorResult != "" {
+			t.Fatalf("Sim failed with error: %s", result.ErrorResult)
+		}
+		if len(result.Warnings) != 1 {
+			t.Fatalf("Expected a warning about expected value mode, got %v", result.Warnings)
+		}
+		dtps = append(dtps, result.RaidMetrics.Parties[0].Players[0].Dtps.Avg)
+	}
+	if dtps[0] != dtps[1] {
+		t.Fatalf("Expected the same DTPS for every seed, got %v", dtps)
+	}
+
+	normal := RunRaidSim(tankSimRequest(&proto.SimOptions{Iterations: 2000, RandomSeed: 3}))
+	if expected := normal.RaidMetrics.Parties[0].Players[0].Dtps.Avg; !WithinToleranceFloat64(expected, dtps[0], expected*0.1) {
+		t.Fatalf("Expected DTPS near %f, got %f", expected, dtps[0])
+	}
+}
+
+func TestTargetConfidence(t *testing.T) {
+	sim := &Simulation{
+		Environment: &Environment{Raid: &Raid{dpsMetrics: NewDistributionMetrics()}},
+		Options: &proto.SimOptions{
+			TargetConfidence: &proto.TargetConfidence{MaxRelativeHalfWidth: 0.001, MinIterations: 10},
+		},
+	}
+
+	// Mean 1000 DPS with a standard deviation of ~10, so the half-width drops
+	// below 1 DPS after ~385 iterations.
+	reachedAt := int32(0)
+	for completed := int32(1); completed <= 1000 && reachedAt == 0; completed++ {
+		sim.Raid.dpsMetrics.add(1000 + 10*float64(completed%2*2-1))
+		if sim.reachedTargetConfidence(completed) {
+			reachedAt = completed
+		}
+	}
+	if reachedAt < 380 || reachedAt > 400 {
+		t.Fatalf("Expected to reach the target after ~385 iterations, got %d", reachedAt)
+	}
+
+	ci := sim.dpsConfidenceProto(reachedAt)
+	if !ci.Converged || ci.HalfWidth > 1 || ci.Mean != 1000 {
+		t.Fatalf("Unexpected confidence interval %v", ci)
+	}
+}
+
+func TestCancelledSim(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := RunSimWithContext(ctx, &proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{Iterations: 1000, RandomSeed: 101, Interactive: true},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{{
+				Players: []*proto.Player{{
+					Name:      "Shaman",
+					Class:     proto.Class_ClassShaman,
+					Consumes:  &proto.Consumes{},
+					Buffs:     &proto.IndividualBuffs{},
+					Spec:      &proto.Player_ElementalShaman{},
+					Equipment: &proto.EquipmentSpec{},
+				}},
+				Buffs: &proto.PartyBuffs{},
+			}},
+		},
+		Encounter: &proto.Encounter{
+			Duration: 20,
+			Targets:  []*proto.Target{{Level: 83}},
+		},
+	}, nil)
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", result.ErrorResult)
+	}
+
+	// The first iteration always runs, so there are still some metrics.
+	if !result.Cancelled || len(result.RaidMetrics.Dps.Hist) != 1 || result.RaidMetrics.Dps.Hist[0] != 1 {
+		t.Fatalf("Expected a cancelled result with 1 iteration, got %v", result)
+	}
+}
+
+func TestRegisterPrepullCast(t *testing.T) {
+	unit := &Unit{Env: &Environment{}, CastSpeed: 0.8}
+	spell := &Spell{Unit: unit, CastTimeMultiplier: 1}
+	spell.DefaultCast.CastTime = time.Millisecond * 2500
+
+	// Hasted by 25%, so the cast needs to start 2s before it lands.
+	if castAt := unit.RegisterPrepullCast(spell, 0); castAt != -time.Second*2 {
+		t.Fatalf("Expected the cast to start at -2s, got %s", castAt)
+	}
+	if castAt := unit.RegisterPrepullCast(spell, -time.Second); castAt != -time.Second*3 {
+		t.Fatalf("Expected the cast to start at -3s, got %s", castAt)
+	}
+	if n := len(unit.Env.prepullActions); n != 2 || unit.Env.prepullActions[0].DoAt != -time.Second*2 {
+		t.Fatalf("Expected 2 prepull actions, got %v", unit.Env.prepullActions)
+	}
+}
+
+func TestDurationDistributions(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{RandomSeed: 1})
+	request.Encounter.Duration = 60
+	request.Encounter.DurationVariation = 5
+	request.Encounter.DurationDistribution = proto.DurationDistribution_DurationDistributionNormal
+	sim := NewSim(request)
+
+	var durations aggregator
+	for i := 0; i < 2000; i++ {
+		duration := sim.rollDuration()
+		if duration < 45*time.Second || duration > sim.GetMaxDuration() {
+			t.Fatalf("Normal duration %s outside of 3 standard deviations", duration)
+		}
+		durations.add(duration.Seconds())
+	}
+	if mean, stdev := durations.meanAndStdDev(); !WithinToleranceFloat64(60, mean, 0.5) || !WithinToleranceFloat64(5, stdev, 0.3) {
+		t.Fatalf("Expected durations of 60 +/- 5s, got %f +/- %f", mean, stdev)
+	}
+
+	request.Encounter.DurationDistribution = proto.DurationDistribution_DurationDistributionEmpirical
+	request.Encounter.KillTimes = []float64{20, 40, 90}
+	sim = NewSim(request)
+	if sim.BaseDuration != 50*time.Second || sim.GetMaxDuration() != 90*time.Second {
+		t.Fatalf("Expected an average of 50s and a max of 90s, got %s and %s", sim.BaseDuration, sim.GetMaxDuration())
+	}
+	seen := make(map[time.Duration]int)
+	for i := 0; i < 300; i++ {
+		seen[sim.rollDuration()]++
+	}
+	if len(seen) != 3 || seen[20*time.Second] == 0 || seen[40*time.Second] == 0 || seen[90*time.Second] == 0 {
+		t.Fatalf("Expected only the kill times, got %v", seen)
+	}
+}
+
+func TestExecuteFromHealth(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{RandomSeed: 1})
+	request.Encounter.ExecuteFromHealth = true
+	request.Encounter.UnsimulatedRaidDps = 1000
+	request.Encounter.Targets[0].Stats = make([]float64, stats.Len)
+	request.Encounter.Targets[0].Stats[stats.Health] = 100000
+	sim := NewSim(request)
+	sim.reset()
+
+	// Only the rest of the raid deals damage, so the targets reach 35% after
+	// 65 seconds and 20% after 80.
+	sim.advance(64 * time.Second)
+	if sim.IsExecutePhase35() {
+		t.Fatalf("Expected no execute phase at 64%% health")
+	}
+	sim.advance(66 * time.Second)
+	if !sim.IsExecutePhase35() || sim.IsExecutePhase25() || sim.execute35Start != 66*time.Second {
+		t.Fatalf("Expected the 35%% execute phase from 66s, got phase %d from %s", sim.executePhase, sim.execute35Start)
+	}
+	sim.advance(80 * time.Second)
+	if !sim.IsExecutePhase20() {
+		t.Fatalf("Expected the 20%% execute phase at 20%% health")
+	}
+}
+
+func TestExecuteDps(t *testing.T) {
+	// Thorns is the tank's only source of damage.
+	request := tankSimRequest(&proto.SimOptions{Iterations: 10, RandomSeed: 1})
+	request.Raid.Buffs = &proto.RaidBuffs{Thorns: proto.TristateEffect_TristateEffectRegular}
+	result := RunRaidSim(request)
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", result.ErrorResult)
+	}
+	if executeDps := result.RaidMetrics.Parties[0].Players[0].ExecuteDps; executeDps != nil {
+		t.Fatalf("Expected no execute DPS without an execute phase, got %v", executeDps)
+	}
+
+	request.Encounter.ExecuteProportion_35 = 0.5
+	result = RunRaidSim(request)
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", result.ErrorResult)
+	}
+	tank := result.RaidMetrics.Parties[0].Players[0]
+	if tank.ExecuteDps == nil || tank.ExecuteDps.Avg <= 0 {
+		t.Fatalf("Expected execute DPS over the last half of the fight, got %v", tank.ExecuteDps)
+	}
+}
+
+func TestPrepullPotion(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{RandomSeed: 101})
+	player := request.Raid.Parties[0].Players[0]
+	player.Consumes = &proto.Consumes{PrepopPotion: proto.Potions_PotionOfSpeed, DefaultPotion: proto.Potions_PotionOfSpeed}
+
+	sim := NewSim(request)
+	eventLog := &EventLog{}
+	sim.startLogging(eventLog)
+	sim.reset()
+	sim.PrePull()
+	runSimUntil(sim, 0)
+
+	character := sim.Raid.Parties[0].Players[0].GetCharacter()
+	potion := character.GetMajorCooldown(ActionID{ItemID: 40211})
+	if potion.ReadyAt() != time.Second*59 || potion.Spell.SpellMetrics[0].Casts != 1 {
+		t.Fatalf("Expected the potion to be used at -1s, got ready at %s with %d casts", potion.ReadyAt(), potion.Spell.SpellMetrics[0].Casts)
+	}
+	if character.minReady != potion.ReadyAt() {
+		t.Fatalf("Expected major cooldowns to be updated at the pull, got next ready at %s", character.minReady)
+	}
+	if logs := eventLog.Text(); !strings.Contains(logs, "[-1.00] [Tank (#1)] Prepull action: {ItemID: 40211}") {
+		t.Fatalf("Expected the prepull potion in the logs, got %q", logs)
+	}
+}
@@ -0,0 +1,322 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// SimulationCraft slot names, indexed by ItemSlot.
+var simcSlots = [proto.ItemSlot_ItemSlotRanged + 1]string{
+	"head", "neck", "shoulders", "back", "chest", "wrists", "hands", "waist", "legs",
+	"feet", "finger1", "finger2", "trinket1", "trinket2", "main_hand", "off_hand", "ranged",
+}
+
+// Engineering tinkers are enchants here, but addons in SimulationCraft.
+var simcAddonEnchantIDs = map[string]int32{
+	"personal_electromagnetic_pulse_generator": 3599,
+	"frag_belt":                 3601,
+	"hand_mounted_pyro_rocket":  3603,
+	"hyperspeed_accelerators":   3604,
+	"flexweave_underlay":        3605,
+	"nitro_boosts":              3606,
+	"springy_arachnoweave":      3859,
+	"reticulated_armor_webbing": 3860,
+	"mind_amplification_dish":   3878,
+}
+
+const simcTalentCalcURL = "https://www.wowhead.com/wotlk/talent-calc/"
+
+// Player fields written to SimulationCraft profiles. Others are reported as
+// untranslated.
+var simcPlayerFields = map[protoreflect.Name]bool{
+	"name":           true,
+	"race":           true,
+	"class":          true,
+	"equipment":      true,
+	"talents_string": true,
+	"glyphs":         true,
+	"profession1":    true,
+	"profession2":    true,
+}
+
+// Converts e.g. "NightElf" to "night_elf".
+func simcToken(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) && i > 0 {
+			sb.WriteRune('_')
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+func glyphEnum(class proto.Class, major bool) protoreflect.EnumDescriptor {
+	kind := "MinorGlyph"
+	if major {
+		kind = "MajorGlyph"
+	}
+	enumType, err := protoregistry.GlobalTypes.FindEnumByName(protoreflect.FullName("proto." + strings.TrimPrefix(class.String(), "Class") + kind))
+	if err != nil {
+		return nil
+	}
+	return enumType.Descriptor()
+}
+
+// Converts a player into a SimulationCraft profile.
+func exportSimc(request *proto.SimcExportRequest) *proto.SimcExportResult {
+	player := request.Player
+	if player == nil {
+		return &proto.SimcExportResult{ErrorResult: "No player to export."}
+	}
+
+	var untranslated []string
+	player.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !simcPlayerFields[fd.Name()] {
+			untranslated = append(untranslated, string(fd.Name()))
+		}
+		return true
+	})
+
+	var lines []string
+	lines = append(lines,
+		fmt.Sprintf("%s=\"%s\"", simcToken(strings.TrimPrefix(player.Class.String(), "Class")), player.Name),
+		"level=80",
+		"race="+simcToken(strings.TrimPrefix(player.Race.String(), "Race")))
+
+	if player.TalentsString != "" {
+		lines = append(lines, "talents="+simcTalentCalcURL+strings.ToLower(strings.TrimPrefix(player.Class.String(), "Class"))+"/"+player.TalentsString)
+	}
+
+	if glyphs := player.Glyphs; glyphs != nil {
+		var tokens []string
+		for i, glyphID := range []int32{glyphs.Major1, glyphs.Major2, glyphs.Major3, glyphs.Minor1, glyphs.Minor2, glyphs.Minor3} {
+			if glyphID == 0 {
+				continue
+			}
+			if enum := glyphEnum(player.Class, i < 3); enum != nil {
+				if value := enum.Values().ByNumber(protoreflect.EnumNumber(glyphID)); value != nil {
+					tokens = append(tokens, simcToken(strings.TrimPrefix(string(value.Name()), "GlyphOf")))
+					continue
+				}
+			}
+			untranslated = append(untranslated, fmt.Sprintf("glyphs.%d", glyphID))
+		}
+		if len(tokens) > 0 {
+			lines = append(lines, "glyphs="+strings.Join(tokens, "/"))
+		}
+	}
+
+	var professions []string
+	for _, profession := range []proto.Profession{player.Profession1, player.Profession2} {
+		if profession != proto.Profession_ProfessionUnknown {
+			professions = append(professions, simcToken(profession.String())+"=450")
+		}
+	}
+	if len(professions) > 0 {
+		lines = append(lines, "professions="+strings.Join(professions, "/"))
+	}
+
+	addonNames := make(map[int32]string, len(simcAddonEnchantIDs))
+	for name, enchantID := range simcAddonEnchantIDs {
+		addonNames[enchantID] = name
+	}
+	for slot, item := range player.GetEquipment().GetItems() {
+		if item.GetId() == 0 {
+			continue
+		}
+		if slot >= len(simcSlots) {
+			untranslated = append(untranslated, fmt.Sprintf("equipment.items.%d", slot))
+			continue
+		}
+
+		line := fmt.Sprintf("%s=,id=%d", simcSlots[slot], item.Id)
+		if len(item.Gems) > 0 {
+			line += ",gem_id=" + strings.Join(MapSlice(item.Gems, func(gemID int32) string { return strconv.Itoa(int(gemID)) }), "/")
+		}
+		if name, ok := addonNames[item.Enchant]; ok {
+			line += ",addon=" + name
+		} else if item.Enchant != 0 {
+			line += fmt.Sprintf(",enchant_id=%d", item.Enchant)
+		}
+		if item.Ilvl != 0 {
+			line += fmt.Sprintf(",ilevel=%d", item.Ilvl)
+		}
+		lines = append(lines, line)
+	}
+
+	if request.Strict && len(untranslated) > 0 {
+		return &proto.SimcExportResult{ErrorResult: "Untranslatable fields: " + strings.Join(untranslated, ", ")}
+	}
+	return &proto.SimcExportResult{
+		Profile:            strings.Join(lines, "\n") + "\n",
+		UntranslatedFields: untranslated,
+	}
+}
+
+// Converts a SimulationCraft profile into a player.
+func importSimc(request *proto.SimcImportRequest) *proto.SimcImportResult {
+	player := &proto.Player{
+		Equipment: &proto.EquipmentSpec{Items: make([]*proto.ItemSpec, len(simcSlots))},
+		Glyphs:    &proto.Glyphs{},
+	}
+	for i := range player.Equipment.Items {
+		player.Equipment.Items[i] = &proto.ItemSpec{}
+	}
+
+	var untranslated []string
+	var glyphTokens []string
+	for _, line := range strings.Split(request.Profile, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return &proto.SimcImportResult{ErrorResult: fmt.Sprintf("Invalid line: %s", line)}
+		}
+
+		if class, ok := enumValueByName(proto.Class_value, "Class", key); ok && class != int32(proto.Class_ClassUnknown) {
+			player.Class = proto.Class(class)
+			player.Name = strings.Trim(value, "\"")
+			continue
+		}
+		if slot := slices.Index(simcSlots[:], key); slot != -1 {
+			player.Equipment.Items[slot] = &proto.ItemSpec{}
+			untranslated = append(untranslated, parseSimcItem(key, value, player.Equipment.Items[slot])...)
+			continue
+		}
+
+		switch key {
+		case "level", "spec", "role", "position":
+			// Derived from the sim settings instead.
+		case "race":
+			race, ok := enumValueByName(proto.Race_value, "Race", value)
+			if !ok {
+				return &proto.SimcImportResult{ErrorResult: fmt.Sprintf("Unknown race '%s'", value)}
+			}
+			player.Race = proto.Race(race)
+		case "talents":
+			if strings.HasPrefix(value, "http") {
+				value = value[strings.LastIndex(value, "/")+1:]
+			}
+			player.TalentsString = value
+		case "glyphs":
+			glyphTokens = strings.Split(value, "/")
+		case "professions":
+			var professions []proto.Profession
+			for _, token := range strings.Split(value, "/") {
+				name, _, _ := strings.Cut(token, "=")
+				if profession, ok := enumValueByName(proto.Profession_value, "", name); ok {
+					professions = append(professions, proto.Profession(profession))
+				} else {
+					untranslated = append(untranslated, "professions."+name)
+				}
+			}
+			if len(professions) > 0 {
+				player.Profession1 = professions[0]
+			}
+			if len(professions) > 1 {
+				player.Profession2 = professions[1]
+			}
+		default:
+			untranslated = append(untranslated, key)
+		}
+	}
+
+	if player.Class == proto.Class_ClassUnknown {
+		return &proto.SimcImportResult{ErrorResult: "Profile has no class line."}
+	}
+
+	// Glyphs are listed without their type, so this waits for the class.
+	var majors, minors []int32
+	for _, token := range glyphTokens {
+		if glyphID := findSimcGlyph(player.Class, true, token); glyphID != 0 {
+			majors = append(majors, glyphID)
+		} else if glyphID := findSimcGlyph(player.Class, false, token); glyphID != 0 {
+			minors = append(minors, glyphID)
+		} else {
+			untranslated = append(untranslated, "glyphs."+token)
+		}
+	}
+	majors = append(majors, 0, 0, 0)
+	minors = append(minors, 0, 0, 0)
+	player.Glyphs.Major1, player.Glyphs.Major2, player.Glyphs.Major3 = majors[0], majors[1], majors[2]
+	player.Glyphs.Minor1, player.Glyphs.Minor2, player.Glyphs.Minor3 = minors[0], minors[1], minors[2]
+
+	if request.Strict && len(untranslated) > 0 {
+		return &proto.SimcImportResult{ErrorResult: "Untranslatable fields: " + strings.Join(untranslated, ", ")}
+	}
+	return &proto.SimcImportResult{
+		Player:             player,
+		UntranslatedFields: untranslated,
+	}
+}
+
+func findSimcGlyph(class proto.Class, major bool, token string) int32 {
+	enum := glyphEnum(class, major)
+	if enum == nil {
+		return 0
+	}
+	normalized := normalizeEnumName("GlyphOf" + token)
+	for i := 0; i < enum.Values().Len(); i++ {
+		if value := enum.Values().Get(i); value.Number() != 0 && normalizeEnumName(string(value.Name())) == normalized {
+			return int32(value.Number())
+		}
+	}
+	return 0
+}
+
+// Fills the item spec from a SimulationCraft item line, e.g.
+// ",id=40528,gem_id=41398/40111,enchant_id=3817". Returns untranslated options.
+func parseSimcItem(slot string, value string, item *proto.ItemSpec) []string {
+	var untranslated []string
+	options := strings.Split(value, ",")
+	// The first option is the item's name, which may be empty.
+	for _, option := range options[1:] {
+		key, optionValue, _ := strings.Cut(option, "=")
+		var err error
+		switch key {
+		case "id":
+			item.Id, err = parseSimcID(optionValue)
+		case "enchant_id":
+			item.Enchant, err = parseSimcID(optionValue)
+		case "ilevel":
+			item.Ilvl, err = parseSimcID(optionValue)
+		case "gem_id":
+			item.Gems = nil
+			for _, gem := range strings.Split(optionValue, "/") {
+				var gemID int32
+				if gemID, err = parseSimcID(gem); err != nil {
+					break
+				}
+				item.Gems = append(item.Gems, gemID)
+			}
+		case "addon":
+			if enchantID, ok := simcAddonEnchantIDs[optionValue]; ok {
+				item.Enchant = enchantID
+			} else {
+				err = fmt.Errorf("unknown addon")
+			}
+		default:
+			err = fmt.Errorf("unknown option")
+		}
+		if err != nil {
+			untranslated = append(untranslated, slot+"."+key)
+		}
+	}
+	return untranslated
+}
+
+func parseSimcID(value string) (int32, error) {
+	id, err := strconv.ParseInt(value, 10, 32)
+	return int32(id), err
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	goproto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestSimcRoundTrip(t *testing.T) {
+	player := &proto.Player{
+		Name:          "Tester",
+		Race:          proto.Race_RaceNightElf,
+		Class:         proto.Class_ClassWarrior,
+		TalentsString: "3022032023335100102012213231251-305-2033",
+		Glyphs: &proto.Glyphs{
+			Major1: int32(proto.WarriorMajorGlyph_GlyphOfHeroicStrike),
+			Major2: int32(proto.WarriorMajorGlyph_GlyphOfRending),
+			Minor1: int32(proto.WarriorMinorGlyph_GlyphOfBattle),
+		},
+		Profession1: proto.Profession_Blacksmithing,
+		Profession2: proto.Profession_Engineering,
+		Equipment: &proto.EquipmentSpec{Items: []*proto.ItemSpec{
+			proto.ItemSlot_ItemSlotHead:  {Id: 40528, Enchant: 3817, Gems: []int32{41398, 0}},
+			proto.ItemSlot_ItemSlotHands: {Id: 40527, Enchant: 3604, Ilvl: 226},
+		}},
+		Consumes: &proto.Consumes{Flask: proto.Flask_FlaskOfEndlessRage},
+	}
+
+	exported := ExportSimc(&proto.SimcExportRequest{Player: player})
+	if exported.ErrorResult != "" {
+		t.Fatalf("ExportSimc() returned error: %s", exported.ErrorResult)
+	}
+	for _, line := range []string{
+		"warrior=\"Tester\"",
+		"race=night_elf",
+		"glyphs=heroic_strike/rending/battle",
+		"professions=blacksmithing=450/engineering=450",
+		"head=,id=40528,gem_id=41398/0,enchant_id=3817",
+		"hands=,id=40527,addon=hyperspeed_accelerators,ilevel=226",
+	} {
+		if !strings.Contains(exported.Profile, line+"\n") {
+			t.Errorf("Expected line %s in profile:\n%s", line, exported.Profile)
+		}
+	}
+	if len(exported.UntranslatedFields) != 1 || exported.UntranslatedFields[0] != "consumes" {
+		t.Errorf("Expected consumes to be untranslated, got %v", exported.UntranslatedFields)
+	}
+	if strict := ExportSimc(&proto.SimcExportRequest{Player: player, Strict: true}); strict.ErrorResult == "" {
+		t.Errorf("Expected strict export to fail")
+	}
+
+	imported := ImportSimc(&proto.SimcImportRequest{Profile: exported.Profile + "role=attack\nhead.reforge=1\nhands=,id=40527,reforge=hit_crit\n"})
+	if imported.ErrorResult != "" {
+		t.Fatalf("ImportSimc() returned error: %s", imported.ErrorResult)
+	}
+	player.Consumes = nil
+	for i := len(player.Equipment.Items); i < len(simcSlots); i++ {
+		player.Equipment.Items = append(player.Equipment.Items, nil)
+	}
+	for i, item := range player.Equipment.Items {
+		if item == nil {
+			player.Equipment.Items[i] = &proto.ItemSpec{}
+		}
+	}
+	// The last hands line replaces the exported one.
+	player.Equipment.Items[proto.ItemSlot_ItemSlotHands] = &proto.ItemSpec{Id: 40527}
+	if !goproto.Equal(imported.Player, player) {
+		t.Errorf("Round trip mismatch:\n%v\n%v", imported.Player, player)
+	}
+	if strings.Join(imported.UntranslatedFields, ",") != "head.reforge,hands.reforge" {
+		t.Errorf("Unexpected untranslated fields %v", imported.UntranslatedFields)
+	}
+}
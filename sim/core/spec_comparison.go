@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+
+	goproto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Simulates a player with two sets of talents and glyphs over a range of fight
+// lengths, and returns the DPS of each along with the fight lengths at which
+// one overtakes the other.
+func CompareSpecs(ctx context.Context, request *proto.SpecComparisonRequest, progress chan *proto.ProgressMetrics) *proto.SpecComparisonResult {
+	result, err := runSpecComparison(ctx, request, progress)
+	if err != nil {
+		result = &proto.SpecComparisonResult{
+			ErrorResult: err.Error(),
+		}
+	}
+
+	if progress != nil {
+		progress <- &proto.ProgressMetrics{
+			FinalSpecComparisonResult: result,
+		}
+		close(progress)
+	}
+
+	return result
+}
+
+func runSpecComparison(ctx context.Context, request *proto.SpecComparisonRequest, progress chan *proto.ProgressMetrics) (*proto.SpecComparisonResult, error) {
+	if request.Base == nil {
+		return nil, errors.New("spec comparison request has no base")
+	}
+	if request.SpecA == nil || request.SpecB == nil {
+		return nil, errors.New("spec comparison request needs two specs")
+	}
+	if len(request.Durations) == 0 {
+		return nil, errors.New("spec comparison request has no durations")
+	}
+
+	base := pairedSimRequest(request.Base)
+	if base.Encounter == nil {
+		base.Encounter = &proto.Encounter{}
+	}
+	if _, err := requestPlayer(base, request.PlayerIndex); err != nil {
+		return nil, err
+	}
+	partyIndex, playerIndex := request.PlayerIndex/5, request.PlayerIndex%5
+
+	durations := slices.Clone(request.Durations)
+	slices.Sort(durations)
+	for _, duration := range durations {
+		if duration <= 0 {
+			return nil, errors.New("spec comparison durations must be positive")
+		}
+	}
+
+	totalSims := int32(2 * len(durations))
+	var completedSims int32
+	runSpec := func(spec *proto.SpecComparisonSpec, duration float64) ([]float64, float64, error) {
+		rsr := goproto.Clone(base).(*proto.RaidSimRequest)
+		rsr.Encounter.Duration = duration
+		player := rsr.Raid.Parties[partyIndex].Players[playerIndex]
+		player.TalentsString = spec.TalentsString
+		player.Glyphs = spec.Glyphs
+
+		simResult := RunSimWithContext(ctx, rsr, nil)
+		completedSims++
+		if progress != nil {
+			progress <- &proto.ProgressMetrics{
+				CompletedSims: completedSims,
+				TotalSims:     totalSims,
+			}
+		}
+		if simResult.ErrorResult != "" {
+			return nil, 0, errors.New(simResult.ErrorResult)
+		}
+
+		dps := simResult.RaidMetrics.Parties[partyIndex].Players[playerIndex].Dps
+		return dps.AllValues, dps.Avg, nil
+	}
+
+	result := &proto.SpecComparisonResult{
+		LabelA: request.SpecA.Label,
+		LabelB: request.SpecB.Label,
+		Points: make([]*proto.SpecComparisonPoint, 0, len(durations)),
+	}
+	if result.LabelA == "" {
+		result.LabelA = "A"
+	}
+	if result.LabelB == "" {
+		result.LabelB = "B"
+	}
+
+	for _, duration := range durations {
+		if ctx.Err() != nil {
+			break
+		}
+
+		valuesA, dpsA, err := runSpec(request.SpecA, duration)
+		if err != nil {
+			return nil, err
+		}
+		valuesB, dpsB, err := runSpec(request.SpecB, duration)
+		if err != nil {
+			return nil, err
+		}
+
+		point := &proto.SpecComparisonPoint{
+			Duration: duration,
+			DpsA:     dpsA,
+			DpsB:     dpsB,
+		}
+		point.DpsDelta, point.DpsDeltaHalfWidth = pairedDifference(valuesA, valuesB)
+		point.Significant = point.DpsDeltaHalfWidth < math.Abs(point.DpsDelta)
+		result.Points = append(result.Points, point)
+	}
+
+	result.CrossoverDurations = specCrossovers(result.Points)
+	return result, nil
+}
+
+// Returns the durations at which the DPS delta changes sign, interpolated
+// linearly between neighbouring points.
+func specCrossovers(points []*proto.SpecComparisonPoint) []float64 {
+	var crossovers []float64
+	for i := 1; i < len(points); i++ {
+		prev, cur := points[i-1], points[i]
+		if prev.DpsDelta == 0 || cur.DpsDelta == 0 || (prev.DpsDelta > 0) == (cur.DpsDelta > 0) {
+			continue
+		}
+		frac := prev.DpsDelta / (prev.DpsDelta - cur.DpsDelta)
+		crossovers = append(crossovers, prev.Duration+frac*(cur.Duration-prev.Duration))
+	}
+	return crossovers
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestSpecCrossovers(t *testing.T) {
+	points := []*proto.SpecComparisonPoint{
+		{Duration: 30, DpsDelta: -100},
+		{Duration: 60, DpsDelta: 50},
+		{Duration: 120, DpsDelta: 150},
+		{Duration: 180, DpsDelta: 0},
+		{Duration: 240, DpsDelta: -30},
+		{Duration: 300, DpsDelta: 30},
+	}
+
+	crossovers := specCrossovers(points)
+	expected := []float64{50, 270}
+	if len(crossovers) != len(expected) {
+		t.Fatalf("Expected crossovers %v, got %v", expected, crossovers)
+	}
+	for i := range expected {
+		if !WithinToleranceFloat64(expected[i], crossovers[i], 1e-9) {
+			t.Fatalf("Expected crossovers %v, got %v", expected, crossovers)
+		}
+	}
+}
+
+func TestCompareSpecs(t *testing.T) {
+	result := CompareSpecs(context.Background(), &proto.SpecComparisonRequest{
+		Base:      whatIfTestRequest(),
+		SpecA:     &proto.SpecComparisonSpec{},
+		SpecB:     &proto.SpecComparisonSpec{Label: "Same"},
+		Durations: []float64{40, 20},
+	}, nil)
+
+	if result.ErrorResult != "" {
+		t.Fatalf("Spec comparison failed: %s", result.ErrorResult)
+	}
+	if result.LabelA != "A" || result.LabelB != "Same" {
+		t.Errorf("Unexpected labels %q and %q", result.LabelA, result.LabelB)
+	}
+	if len(result.Points) != 2 || result.Points[0].Duration != 20 || result.Points[1].Duration != 40 {
+		t.Fatalf("Expected points sorted by duration, got %v", result.Points)
+	}
+
+	// Both specs are the same, so with shared seeds every iteration is
+	// identical and they never cross.
+	for _, point := range result.Points {
+		if point.DpsA != point.DpsB || point.DpsDelta != 0 || point.Significant {
+			t.Errorf("Expected no difference between identical specs, got %v", point)
+		}
+	}
+	if len(result.CrossoverDurations) != 0 {
+		t.Errorf("Expected no crossovers, got %v", result.CrossoverDurations)
+	}
+
+	invalid := CompareSpecs(context.Background(), &proto.SpecComparisonRequest{
+		Base:        whatIfTestRequest(),
+		PlayerIndex: 1,
+		SpecA:       &proto.SpecComparisonSpec{},
+		SpecB:       &proto.SpecComparisonSpec{},
+		Durations:   []float64{20},
+	}, nil)
+	if invalid.ErrorResult == "" {
+		t.Errorf("Expected an error for a missing player")
+	}
+}
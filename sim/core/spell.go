@@ -30,6 +30,12 @@ type SpellConfig struct {
 	Cast               CastConfig
 	ExtraCastCondition CanCastCondition
 
+	// How the spell's GCD, from Cast.DefaultCast.GCD, is triggered.
+	GCDCategory GCDCategory
+
+	// If set, the spell can only be cast in one of these stances.
+	RequiredStances Stance
+
 	BonusHitRating       float64
 	BonusCritRating      float64
 	BonusSpellPower      float64
@@ -54,8 +60,13 @@ type SpellConfig struct {
 	Dot    DotConfig
 	Hot    DotConfig
 	Shield ShieldConfig
+	Chain  ChainConfig
 
 	RelatedAuras []AuraArray
+
+	// Labels this spell passes to sim.RandomFloat(). Declaring them guards
+	// against other spells accidentally sharing the same random stream.
+	RandomStreams []string
 }
 
 type Spell struct {
@@ -86,7 +97,9 @@ type Spell struct {
 	DefaultCast        Cast      // Default cast parameters with all static effects applied.
 	CD                 Cooldown
 	SharedCD           Cooldown
+	GCDCategory        GCDCategory
 	ExtraCastCondition CanCastCondition
+	RequiredStances    Stance
 
 	castTimeFn func(spell *Spell) time.Duration // allows to override CastTime()
 
@@ -134,6 +147,9 @@ type Spell struct {
 	// Note that bonus expertise and armor pen are static, so we don't bother resetting them.
 
 	resultCache SpellResult
+	// Disposed results to reuse while resultCache is in use, e.g. by AoE
+	// spells that calculate all their results before dealing any of them.
+	resultPool []*SpellResult
 
 	dots   DotArray
 	aoeDot *Dot
@@ -141,8 +157,18 @@ type Spell struct {
 	shields    ShieldArray
 	selfShield *Shield
 
+	chain *spellChain // Only set for chain spells, see ChainConfig.
+
 	// Per-target auras that are related to this spell, usually buffs or debuffs applied by the spell.
 	RelatedAuras []AuraArray
+
+	// Labels this spell passes to sim.RandomFloat().
+	RandomStreams []string
+
+	// For lower ranks, the max rank of the spell. See RegisterSpellRank.
+	RankOf *Spell
+	// Lower ranks of this spell, if it is the max rank.
+	Ranks []*Spell
 }
 
 func (unit *Unit) OnSpellRegistered(handler SpellRegisteredHandler) {
@@ -177,6 +203,13 @@ func (unit *Unit) RegisterSpell(config SpellConfig) *Spell {
 		panic("SpellSchool for spell " + config.ActionID.String() + " not set")
 	}
 
+	if config.RequiredStances != StanceNone {
+		extraCastCondition := config.ExtraCastCondition
+		config.ExtraCastCondition = func(sim *Simulation, target *Unit) bool {
+			return unit.StanceMatches(config.RequiredStances) && (extraCastCondition == nil || extraCastCondition(sim, target))
+		}
+	}
+
 	if config.Cast.CD.Timer != nil && config.Cast.CD.Duration == 0 {
 		panic("Cast.CD w/o Duration specified for spell " + config.ActionID.String())
 	}
@@ -185,6 +218,8 @@ func (unit *Unit) RegisterSpell(config SpellConfig) *Spell {
 		panic("Cast.SharedCD w/o Duration specified for spell " + config.ActionID.String())
 	}
 
+	config.validateGCDCategory()
+
 	if config.Cast.CastTime == nil {
 		config.Cast.CastTime = func(spell *Spell) time.Duration {
 			return spell.Unit.ApplyCastSpeedForSpell(spell.DefaultCast.CastTime, spell)
@@ -202,7 +237,9 @@ func (unit *Unit) RegisterSpell(config SpellConfig) *Spell {
 		DefaultCast:        config.Cast.DefaultCast,
 		CD:                 config.Cast.CD,
 		SharedCD:           config.Cast.SharedCD,
+		GCDCategory:        config.GCDCategory,
 		ExtraCastCondition: config.ExtraCastCondition,
+		RequiredStances:    config.RequiredStances,
 
 		castTimeFn: config.Cast.CastTime,
 
@@ -227,7 +264,8 @@ func (unit *Unit) RegisterSpell(config SpellConfig) *Spell {
 
 		splitSpellMetrics: make([][]SpellMetrics, max(1, config.MetricSplits)),
 
-		RelatedAuras: config.RelatedAuras,
+		RelatedAuras:  config.RelatedAuras,
+		RandomStreams: config.RandomStreams,
 	}
 
 	switch {
@@ -263,6 +301,7 @@ func (unit *Unit) RegisterSpell(config SpellConfig) *Spell {
 	spell.createDots(config.Dot, false)
 	spell.createDots(config.Hot, true)
 	spell.createShields(config.Shield)
+	spell.createChain(config.Chain)
 
 	var emptyCast Cast
 
@@ -397,15 +436,23 @@ func (spell *Spell) finalize() {
 		spell.splitSpellMetrics[i] = make([]SpellMetrics, len(spell.Unit.Env.AllUnits))
 	}
 	spell.SpellMetrics = spell.splitSpellMetrics[0]
+
+	spell.Unit.Env.registerRandomStreams(spell)
 }
 
 func (spell *Spell) reset(_ *Simulation) {
 	for i := range spell.splitSpellMetrics {
 		for j := range spell.SpellMetrics {
-			spell.splitSpellMetrics[i][j] = SpellMetrics{}
+			// Keep the buckets' memory, they're the same length every iteration.
+			buckets := spell.splitSpellMetrics[i][j].DamageBuckets
+			clear(buckets)
+			spell.splitSpellMetrics[i][j] = SpellMetrics{DamageBuckets: buckets[:0]}
 		}
 	}
 	spell.casts = 0
+	if spell.chain != nil {
+		clear(spell.chain.bounces)
+	}
 
 	// Reset dynamic effects.
 	spell.BonusHitRating = spell.initialBonusHitRating
@@ -436,6 +483,11 @@ func (spell *Spell) doneIteration() {
 			spell.Unit.Metrics.addSpellMetrics(spell, spell.ActionID.WithTag(int32(i)), spellMetrics)
 		}
 	}
+	if spell.chain != nil {
+		// Bounces aren't split, so they're added to the untagged metrics.
+		actionID := Ternary(len(spell.splitSpellMetrics) == 1, spell.ActionID, spell.ActionID.WithTag(0))
+		spell.Unit.Metrics.actions[actionID].addChainBounces(spell.chain.bounces)
+	}
 }
 
 func (spell *Spell) HealthMetrics(target *Unit) *ResourceMetrics {
@@ -463,6 +515,50 @@ func (spell *Spell) TimeToReady(sim *Simulation) time.Duration {
 	return MaxTimeToReady(spell.CD.Timer, spell.SharedCD.Timer, sim)
 }
 
+// Number of uses stored by the spell's cooldown, see Cooldown.MaxCharges.
+// Spells without a cooldown always have 1.
+func (spell *Spell) Charges(sim *Simulation) int32 {
+	if spell.CD.Timer == nil {
+		return 1
+	}
+	return spell.CD.Charges(sim)
+}
+
+// Shortens the spell's remaining cooldown by amount, e.g. for set bonuses which
+// reduce a cooldown whenever another spell is cast. With charges, this counts
+// towards recharging all of them. The time saved is logged, and attributed to
+// source in the cooldown metrics.
+func (spell *Spell) ReduceCooldown(sim *Simulation, amount time.Duration, source ActionID) {
+	if spell.CD.Timer == nil {
+		return
+	}
+	spell.addCooldownSaved(sim, spell.CD.reduce(sim, amount), source, "reduced")
+}
+
+// Finishes the spell's cooldown and restores all of its charges, e.g. for procs
+// like Rime. Like ReduceCooldown, the time saved is logged and attributed to
+// source.
+func (spell *Spell) ResetCooldown(sim *Simulation, source ActionID) {
+	if spell.CD.Timer == nil {
+		return
+	}
+	saved := spell.CD.timeToFull(sim)
+	spell.CD.Reset()
+	spell.addCooldownSaved(sim, saved, source, "reset")
+}
+
+func (spell *Spell) addCooldownSaved(sim *Simulation, saved time.Duration, source ActionID, verb string) {
+	if saved <= 0 {
+		return
+	}
+	if sim.Log != nil {
+		spell.Unit.Log(sim, "%s cooldown %s by %s, saving %s", spell.ActionID, verb, source, saved)
+	}
+	cdm := spell.Unit.Metrics.getCooldown(source)
+	cdm.events++
+	cdm.saved += saved
+}
+
 // Returns whether a call to Cast() would be successful, without actually
 // doing a cast.
 func (spell *Spell) CanCast(sim *Simulation, target *Unit) bool {
@@ -477,6 +573,17 @@ func (spell *Spell) CanCast(sim *Simulation, target *Unit) bool {
 		return false
 	}
 
+	if lc := spell.Unit.lossOfControl; lc != nil && lc.prevents(spell) {
+		//if sim.Log != nil {
+		//	sim.Log("Cant cast because of a stun or silence")
+		//}
+		return false
+	}
+
+	if spell.Unit.IsOutOfCombat() {
+		return false
+	}
+
 	// While casting or channeling, no other action is possible
 	if spell.Unit.Hardcast.Expires > sim.CurrentTime {
 		//if sim.Log != nil {
@@ -525,15 +632,24 @@ func (spell *Spell) Cast(sim *Simulation, target *Unit) bool {
 	if target == nil {
 		target = spell.Unit.CurrentTarget
 	}
-	return spell.castFn(sim, target)
+
+	ce := spell.Unit.Metrics.castEfficiency
+	if ce == nil || !spell.Flags.Matches(SpellFlagAPL) {
+		return spell.castFn(sim, target)
+	}
+	readyAt := spell.ReadyAt()
+	if !spell.castFn(sim, target) {
+		return false
+	}
+	ce.onCast(sim, spell, readyAt)
+	return true
 }
 
 // Skips the actual cast and applies spell effects immediately.
 func (spell *Spell) SkipCastAndApplyEffects(sim *Simulation, target *Unit) {
 	if sim.Log != nil && !spell.Flags.Matches(SpellFlagNoLogs) {
-		spell.Unit.Log(sim, "Casting %s (Cost = %0.03f, Cast Time = %s)",
-			spell.ActionID, spell.DefaultCast.Cost, time.Duration(0))
-		spell.Unit.Log(sim, "Completed cast %s", spell.ActionID)
+		spell.logCast(sim, spell.DefaultCast.Cost, 0, 0)
+		spell.logCastComplete(sim)
 	}
 	spell.applyEffects(sim, target)
 }
@@ -545,10 +661,16 @@ func (spell *Spell) applyEffects(sim *Simulation, target *Unit) {
 	spell.ApplyEffects(sim, target, spell)
 }
 
+func (spell *Spell) addThreat(sim *Simulation, target *Unit, threat float64) {
+	spell.SpellMetrics[target.UnitIndex].TotalThreat += threat
+	if spell.Unit.Env.threat != nil {
+		spell.Unit.Env.threat.addThreat(sim, spell.Unit, target, threat)
+	}
+}
+
 func (spell *Spell) ApplyAOEThreatIgnoreMultipliers(threatAmount float64) {
-	numTargets := spell.Unit.Env.GetNumTargets()
-	for i := int32(0); i < numTargets; i++ {
-		spell.SpellMetrics[i].TotalThreat += threatAmount
+	for _, target := range spell.Unit.Env.Encounter.TargetUnits {
+		spell.SpellMetrics[target.Index].TotalThreat += threatAmount
 	}
 }
 func (spell *Spell) ApplyAOEThreat(threatAmount float64) {
@@ -561,7 +683,7 @@ func (spell *Spell) finalizeExpectedDamage(result *SpellResult) {
 		result.Damage *= AverageMagicPartialResistMultiplier
 		result.ResistanceMultiplier = AverageMagicPartialResistMultiplier
 	}
-	result.inUse = false
+	spell.DisposeResult(result)
 }
 func (spell *Spell) ExpectedInitialDamage(sim *Simulation, target *Unit) float64 {
 	result := spell.expectedInitialDamageInternal(sim, target, spell, false)
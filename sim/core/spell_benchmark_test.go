@@ -0,0 +1,103 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+// Micro-benchmarks for the sim's hot paths. Run with:
+//
+//	go test -run=^$ -bench=. ./sim/core/
+
+func registerBenchmarkSpell(character *Character, actionID ActionID, defaultCast Cast) *Spell {
+	return character.RegisterSpell(SpellConfig{
+		ActionID:    actionID,
+		SpellSchool: SpellSchoolFire,
+		ProcMask:    ProcMaskSpellDamage,
+
+		Cast: CastConfig{
+			DefaultCast: defaultCast,
+		},
+
+		DamageMultiplier: 1,
+		CritMultiplier:   character.DefaultSpellCritMultiplier(),
+		ThreatMultiplier: 1,
+
+		ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {
+			spell.CalcAndDealDamage(sim, target, 1000, spell.OutcomeMagicHitAndCrit)
+		},
+	})
+}
+
+func BenchmarkCalcAndDealDamage(b *testing.B) {
+	sim := SetupFakeSim()
+	character := sim.Raid.Parties[0].Players[0].GetCharacter()
+	target := sim.Encounter.TargetUnits[0]
+	spell := registerBenchmarkSpell(character, ActionID{SpellID: 43}, Cast{})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		spell.CalcAndDealDamage(sim, target, 1000, spell.OutcomeMagicHitAndCrit)
+	}
+}
+
+func BenchmarkDotTicks(b *testing.B) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !fa.Dot.IsActive() {
+			fa.Dot.Apply(sim)
+		}
+		fa.Dot.TickOnce(sim)
+		if i%int(fa.Dot.NumberOfTicks) == 0 {
+			fa.Dot.Deactivate(sim)
+		}
+	}
+}
+
+func BenchmarkAuraStacks(b *testing.B) {
+	sim := SetupFakeSim()
+	character := sim.Raid.Parties[0].Players[0].GetCharacter()
+	aura := character.RegisterAura(Aura{
+		Label:     "Benchmark Stacks",
+		ActionID:  ActionID{SpellID: 44},
+		Duration:  time.Second * 10,
+		MaxStacks: 5,
+	})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		aura.Activate(sim)
+		aura.AddStack(sim)
+		if aura.GetStacks() == aura.MaxStacks {
+			aura.Deactivate(sim)
+		}
+	}
+}
+
+// Casts a spell with a cast time and GCD back to back, running the sim's
+// pending actions in between, and restarting the sim once the fight ends.
+func BenchmarkCastScheduling(b *testing.B) {
+	sim := SetupFakeSim()
+	character := sim.Raid.Parties[0].Players[0].GetCharacter()
+	target := sim.Encounter.TargetUnits[0]
+	spell := registerBenchmarkSpell(character, ActionID{SpellID: 45}, Cast{
+		GCD:      GCDDefault,
+		CastTime: time.Second * 2,
+	})
+	// Casts are chosen below, rather than by the agent.
+	sim.Options.Interactive = true
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for !spell.CanCast(sim, target) {
+			if finished := sim.Step(); finished {
+				sim.Cleanup()
+				sim.reset()
+			}
+		}
+		spell.Cast(sim, target)
+	}
+}
@@ -31,6 +31,10 @@ func (dot *Dot) OutcomeTickCounted(_ *Simulation, result *SpellResult, _ *Attack
 }
 
 func (dot *Dot) OutcomeTickPhysicalCrit(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
+	if sim.expectedValue {
+		result.applyExpectedHitAndCrit(dot.Spell, 0, dot.Spell.PhysicalCritChance(attackTable), false)
+		return
+	}
 	if dot.Spell.PhysicalCritCheck(sim, attackTable) {
 		result.Outcome = OutcomeCrit
 		result.Damage *= dot.Spell.CritMultiplier
@@ -43,6 +47,10 @@ func (dot *Dot) OutcomeSnapshotCrit(sim *Simulation, result *SpellResult, _ *Att
 	if dot.Spell.CritMultiplier == 0 {
 		panic("Spell " + dot.Spell.ActionID.String() + " missing CritMultiplier")
 	}
+	if sim.expectedValue {
+		result.applyExpectedHitAndCrit(dot.Spell, 0, dot.SnapshotCritChance, true)
+		return
+	}
 	if sim.RandomFloat("Snapshot Crit Roll") < dot.SnapshotCritChance {
 		result.Outcome = OutcomeCrit
 		result.Damage *= dot.Spell.CritMultiplier
@@ -57,6 +65,10 @@ func (dot *Dot) OutcomeMagicHitAndSnapshotCrit(sim *Simulation, result *SpellRes
 	if dot.Spell.CritMultiplier == 0 {
 		panic("Spell " + dot.Spell.ActionID.String() + " missing CritMultiplier")
 	}
+	if sim.expectedValue {
+		result.applyExpectedHitAndCrit(dot.Spell, dot.Spell.SpellChanceToMiss(attackTable), dot.SnapshotCritChance, true)
+		return
+	}
 	if dot.Spell.MagicHitCheck(sim, attackTable) {
 		if sim.RandomFloat("Snapshot Crit Roll") < dot.SnapshotCritChance {
 			result.Outcome = OutcomeCrit
@@ -77,6 +89,10 @@ func (spell *Spell) OutcomeMagicHitAndCrit(sim *Simulation, result *SpellResult,
 	if spell.CritMultiplier == 0 {
 		panic("Spell " + spell.ActionID.String() + " missing CritMultiplier")
 	}
+	if sim.expectedValue {
+		result.applyExpectedHitAndCrit(spell, spell.SpellChanceToMiss(attackTable), spell.SpellCritChance(result.Target), true)
+		return
+	}
 	if spell.MagicHitCheck(sim, attackTable) {
 		if spell.MagicCritCheck(sim, result.Target) {
 			result.Outcome = OutcomeCrit
@@ -97,6 +113,10 @@ func (spell *Spell) OutcomeMagicCrit(sim *Simulation, result *SpellResult, _ *At
 	if spell.CritMultiplier == 0 {
 		panic("Spell " + spell.ActionID.String() + " missing CritMultiplier")
 	}
+	if sim.expectedValue {
+		result.applyExpectedHitAndCrit(spell, 0, spell.SpellCritChance(result.Target), true)
+		return
+	}
 	if spell.MagicCritCheck(sim, result.Target) {
 		result.Outcome = OutcomeCrit
 		result.Damage *= spell.CritMultiplier
@@ -116,6 +136,10 @@ func (spell *Spell) OutcomeHealingCrit(sim *Simulation, result *SpellResult, _ *
 	if spell.CritMultiplier == 0 {
 		panic("Spell " + spell.ActionID.String() + " missing CritMultiplier")
 	}
+	if sim.expectedValue {
+		result.applyExpectedHitAndCrit(spell, 0, spell.HealingCritChance(), true)
+		return
+	}
 	if spell.HealingCritCheck(sim) {
 		result.Outcome = OutcomeCrit
 		result.Damage *= spell.CritMultiplier
@@ -127,6 +151,10 @@ func (spell *Spell) OutcomeHealingCrit(sim *Simulation, result *SpellResult, _ *
 }
 
 func (spell *Spell) OutcomeTickMagicHit(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
+	if sim.expectedValue {
+		result.applyExpectedHitAndCrit(spell, spell.SpellChanceToMiss(attackTable), 0, false)
+		return
+	}
 	if spell.MagicHitCheck(sim, attackTable) {
 		result.Outcome = OutcomeHit
 	} else {
@@ -135,6 +163,10 @@ func (spell *Spell) OutcomeTickMagicHit(sim *Simulation, result *SpellResult, at
 	}
 }
 func (spell *Spell) OutcomeMagicHit(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
+	if sim.expectedValue {
+		result.applyExpectedHitAndCrit(spell, spell.SpellChanceToMiss(attackTable), 0, true)
+		return
+	}
 	if spell.MagicHitCheck(sim, attackTable) {
 		result.Outcome = OutcomeHit
 		spell.SpellMetrics[result.Target.UnitIndex].Hits++
@@ -147,7 +179,7 @@ func (spell *Spell) OutcomeMagicHit(sim *Simulation, result *SpellResult, attack
 
 func (spell *Spell) OutcomeMeleeWhite(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
 	unit := spell.Unit
-	roll := sim.RandomFloat("White Hit Table")
+	roll := result.rollAttackTable(sim, "White Hit Table")
 	chance := 0.0
 
 	if unit.PseudoStats.InFrontOfTarget {
@@ -171,7 +203,7 @@ func (spell *Spell) OutcomeMeleeWhite(sim *Simulation, result *SpellResult, atta
 
 func (spell *Spell) OutcomeMeleeSpecialHit(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
 	unit := spell.Unit
-	roll := sim.RandomFloat("White Hit Table")
+	roll := result.rollAttackTable(sim, "White Hit Table")
 	chance := 0.0
 
 	if unit.PseudoStats.InFrontOfTarget {
@@ -190,7 +222,7 @@ func (spell *Spell) OutcomeMeleeSpecialHit(sim *Simulation, result *SpellResult,
 
 func (spell *Spell) OutcomeMeleeSpecialHitAndCrit(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
 	unit := spell.Unit
-	roll := sim.RandomFloat("White Hit Table")
+	roll := result.rollAttackTable(sim, "White Hit Table")
 	chance := 0.0
 
 	if unit.PseudoStats.InFrontOfTarget {
@@ -217,7 +249,7 @@ func (spell *Spell) OutcomeMeleeSpecialHitAndCrit(sim *Simulation, result *Spell
 // Like OutcomeMeleeSpecialHitAndCrit, but blocks prevent crits (all weapon damage based attacks).
 func (spell *Spell) OutcomeMeleeWeaponSpecialHitAndCrit(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
 	if spell.Unit.PseudoStats.InFrontOfTarget {
-		roll := sim.RandomFloat("White Hit Table")
+		roll := result.rollAttackTable(sim, "White Hit Table")
 		chance := 0.0
 
 		if !result.applyAttackTableMissNoDWPenalty(spell, attackTable, roll, &chance) &&
@@ -234,7 +266,7 @@ func (spell *Spell) OutcomeMeleeWeaponSpecialHitAndCrit(sim *Simulation, result
 
 func (spell *Spell) OutcomeMeleeWeaponSpecialNoCrit(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
 	unit := spell.Unit
-	roll := sim.RandomFloat("White Hit Table")
+	roll := result.rollAttackTable(sim, "White Hit Table")
 	chance := 0.0
 
 	if unit.PseudoStats.InFrontOfTarget {
@@ -253,7 +285,7 @@ func (spell *Spell) OutcomeMeleeWeaponSpecialNoCrit(sim *Simulation, result *Spe
 }
 
 func (spell *Spell) OutcomeMeleeSpecialNoBlockDodgeParry(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
-	roll := sim.RandomFloat("White Hit Table")
+	roll := result.rollAttackTable(sim, "White Hit Table")
 	chance := 0.0
 
 	if !result.applyAttackTableMissNoDWPenalty(spell, attackTable, roll, &chance) &&
@@ -263,7 +295,7 @@ func (spell *Spell) OutcomeMeleeSpecialNoBlockDodgeParry(sim *Simulation, result
 }
 
 func (spell *Spell) OutcomeMeleeSpecialNoBlockDodgeParryNoCrit(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
-	roll := sim.RandomFloat("White Hit Table")
+	roll := result.rollAttackTable(sim, "White Hit Table")
 	chance := 0.0
 
 	if !result.applyAttackTableMissNoDWPenalty(spell, attackTable, roll, &chance) {
@@ -278,7 +310,7 @@ func (spell *Spell) OutcomeMeleeSpecialCritOnly(sim *Simulation, result *SpellRe
 }
 
 func (spell *Spell) OutcomeRangedHit(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
-	roll := sim.RandomFloat("White Hit Table")
+	roll := result.rollAttackTable(sim, "White Hit Table")
 	chance := 0.0
 
 	if !result.applyAttackTableMissNoDWPenalty(spell, attackTable, roll, &chance) {
@@ -287,7 +319,7 @@ func (spell *Spell) OutcomeRangedHit(sim *Simulation, result *SpellResult, attac
 }
 
 func (spell *Spell) OutcomeRangedHitAndCrit(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
-	roll := sim.RandomFloat("White Hit Table")
+	roll := result.rollAttackTable(sim, "White Hit Table")
 	chance := 0.0
 
 	if spell.Unit.PseudoStats.InFrontOfTarget {
@@ -308,7 +340,7 @@ func (spell *Spell) OutcomeRangedHitAndCrit(sim *Simulation, result *SpellResult
 	}
 }
 func (dot *Dot) OutcomeRangedHitAndCritSnapshot(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
-	roll := sim.RandomFloat("White Hit Table")
+	roll := result.rollAttackTable(sim, "White Hit Table")
 	chance := 0.0
 
 	if dot.Spell.Unit.PseudoStats.InFrontOfTarget {
@@ -330,7 +362,7 @@ func (dot *Dot) OutcomeRangedHitAndCritSnapshot(sim *Simulation, result *SpellRe
 }
 
 func (spell *Spell) OutcomeRangedHitAndCritNoBlock(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
-	roll := sim.RandomFloat("White Hit Table")
+	roll := result.rollAttackTable(sim, "White Hit Table")
 	chance := 0.0
 
 	if !result.applyAttackTableMissNoDWPenalty(spell, attackTable, roll, &chance) &&
@@ -342,7 +374,7 @@ func (spell *Spell) OutcomeRangedHitAndCritNoBlock(sim *Simulation, result *Spel
 func (spell *Spell) OutcomeRangedCritOnly(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
 	// Block already checks for this, but we can skip the RNG roll which is expensive.
 	if spell.Unit.PseudoStats.InFrontOfTarget {
-		roll := sim.RandomFloat("White Hit Table")
+		roll := result.rollAttackTable(sim, "White Hit Table")
 		chance := 0.0
 
 		if result.applyAttackTableCritSeparateRoll(sim, spell, attackTable) {
@@ -360,7 +392,7 @@ func (spell *Spell) OutcomeRangedCritOnly(sim *Simulation, result *SpellResult,
 }
 
 func (spell *Spell) OutcomeEnemyMeleeWhite(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
-	roll := sim.RandomFloat("Enemy White Hit Table")
+	roll := result.rollAttackTable(sim, "Enemy White Hit Table")
 	chance := 0.0
 
 	if !result.applyEnemyAttackTableMiss(spell, attackTable, roll, &chance) &&
@@ -377,11 +409,11 @@ func (spell *Spell) fixedCritCheck(sim *Simulation, critChance float64) bool {
 }
 
 func (result *SpellResult) applyAttackTableMiss(spell *Spell, attackTable *AttackTable, roll float64, chance *float64) bool {
-	missChance := attackTable.BaseMissChance - spell.PhysicalHitChance(attackTable)
-	if spell.Unit.AutoAttacks.IsDualWielding && !spell.Unit.PseudoStats.DisableDWMissPenalty {
-		missChance += 0.19
+	*chance = spell.PhysicalMissChance(attackTable, true)
+	if result.expected.active {
+		result.expected.miss = result.expected.fromTable(*chance)
+		return false
 	}
-	*chance = max(0, missChance)
 
 	if roll < *chance {
 		result.Outcome = OutcomeMiss
@@ -393,8 +425,11 @@ func (result *SpellResult) applyAttackTableMiss(spell *Spell, attackTable *Attac
 }
 
 func (result *SpellResult) applyAttackTableMissNoDWPenalty(spell *Spell, attackTable *AttackTable, roll float64, chance *float64) bool {
-	missChance := attackTable.BaseMissChance - spell.PhysicalHitChance(attackTable)
-	*chance = max(0, missChance)
+	*chance = spell.PhysicalMissChance(attackTable, false)
+	if result.expected.active {
+		result.expected.miss = result.expected.fromTable(*chance)
+		return false
+	}
 
 	if roll < *chance {
 		result.Outcome = OutcomeMiss
@@ -407,11 +442,16 @@ func (result *SpellResult) applyAttackTableMissNoDWPenalty(spell *Spell, attackT
 
 func (result *SpellResult) applyAttackTableBlock(spell *Spell, attackTable *AttackTable, roll float64, chance *float64) bool {
 	*chance += attackTable.BaseBlockChance
+	if result.expected.active {
+		result.expected.block = result.expected.fromTable(*chance)
+		result.expected.blockValue = result.Target.BlockValue()
+		return false
+	}
 
 	if roll < *chance {
 		result.Outcome |= OutcomeBlock
 		spell.SpellMetrics[result.Target.UnitIndex].Blocks++
-		result.Damage = max(0, result.Damage-result.Target.BlockValue())
+		result.applyBlockValue(spell, result.Target.BlockValue())
 		return true
 	}
 	return false
@@ -422,7 +462,11 @@ func (result *SpellResult) applyAttackTableDodge(spell *Spell, attackTable *Atta
 		return false
 	}
 
-	*chance += max(0, attackTable.BaseDodgeChance-spell.ExpertisePercentage()-spell.Unit.PseudoStats.DodgeReduction)
+	*chance += spell.PhysicalDodgeChance(attackTable)
+	if result.expected.active {
+		result.expected.dodge = result.expected.fromTable(*chance)
+		return false
+	}
 
 	if roll < *chance {
 		result.Outcome = OutcomeDodge
@@ -434,7 +478,11 @@ func (result *SpellResult) applyAttackTableDodge(spell *Spell, attackTable *Atta
 }
 
 func (result *SpellResult) applyAttackTableParry(spell *Spell, attackTable *AttackTable, roll float64, chance *float64) bool {
-	*chance += max(0, attackTable.BaseParryChance-spell.ExpertisePercentage())
+	*chance += spell.PhysicalParryChance(attackTable)
+	if result.expected.active {
+		result.expected.parry = result.expected.fromTable(*chance)
+		return false
+	}
 
 	if roll < *chance {
 		result.Outcome = OutcomeParry
@@ -447,6 +495,11 @@ func (result *SpellResult) applyAttackTableParry(spell *Spell, attackTable *Atta
 
 func (result *SpellResult) applyAttackTableGlance(spell *Spell, attackTable *AttackTable, roll float64, chance *float64) bool {
 	*chance += attackTable.BaseGlanceChance
+	if result.expected.active {
+		result.expected.glance = result.expected.fromTable(*chance)
+		result.expected.glanceMultiplier = attackTable.GlanceMultiplier
+		return false
+	}
 
 	if roll < *chance {
 		result.Outcome = OutcomeGlance
@@ -463,6 +516,11 @@ func (result *SpellResult) applyAttackTableCrit(spell *Spell, attackTable *Attac
 		panic("Spell " + spell.ActionID.String() + " missing CritMultiplier")
 	}
 	*chance += spell.PhysicalCritChance(attackTable)
+	if result.expected.active {
+		result.expected.crit = result.expected.fromTable(*chance)
+		result.expected.critMultiplier = spell.CritMultiplier
+		return false
+	}
 
 	if roll < *chance {
 		result.Outcome = OutcomeCrit
@@ -477,6 +535,14 @@ func (result *SpellResult) applyAttackTableCritSeparateRoll(sim *Simulation, spe
 	if spell.CritMultiplier == 0 {
 		panic("Spell " + spell.ActionID.String() + " missing CritMultiplier")
 	}
+	if sim.expectedValue {
+		if !result.expected.active {
+			result.startExpectedOutcome()
+		}
+		result.expected.crit = result.expected.fromSeparateRoll(spell.PhysicalCritChance(attackTable))
+		result.expected.critMultiplier = spell.CritMultiplier
+		return false
+	}
 	if spell.PhysicalCritCheck(sim, attackTable) {
 		result.Outcome = OutcomeCrit
 		spell.SpellMetrics[result.Target.UnitIndex].Crits++
@@ -489,6 +555,14 @@ func (result *SpellResult) applyAttackTableCritSeparateRollSnapshot(sim *Simulat
 	if dot.Spell.CritMultiplier == 0 {
 		panic("Spell " + dot.Spell.ActionID.String() + " missing CritMultiplier")
 	}
+	if sim.expectedValue {
+		if !result.expected.active {
+			result.startExpectedOutcome()
+		}
+		result.expected.crit = result.expected.fromSeparateRoll(dot.SnapshotCritChance)
+		result.expected.critMultiplier = dot.Spell.CritMultiplier
+		return false
+	}
 	if sim.RandomFloat("Physical Crit Roll") < dot.SnapshotCritChance {
 		result.Outcome = OutcomeCrit
 		result.Damage *= dot.Spell.CritMultiplier
@@ -499,6 +573,10 @@ func (result *SpellResult) applyAttackTableCritSeparateRollSnapshot(sim *Simulat
 }
 
 func (result *SpellResult) applyAttackTableHit(spell *Spell) {
+	if result.expected.active {
+		result.applyExpectedOutcome(spell, true)
+		return
+	}
 	result.Outcome = OutcomeHit
 	spell.SpellMetrics[result.Target.UnitIndex].Hits++
 }
@@ -509,6 +587,10 @@ func (result *SpellResult) applyEnemyAttackTableMiss(spell *Spell, attackTable *
 		missChance += 0.19
 	}
 	*chance = max(0, missChance)
+	if result.expected.active {
+		result.expected.miss = result.expected.fromTable(*chance)
+		return false
+	}
 
 	if roll < *chance {
 		result.Outcome = OutcomeMiss
@@ -528,11 +610,16 @@ func (result *SpellResult) applyEnemyAttackTableBlock(spell *Spell, attackTable
 		result.Target.stats[stats.Block]/BlockRatingPerBlockChance/100 +
 		result.Target.stats[stats.Defense]*DefenseRatingToChanceReduction
 	*chance += max(0, blockChance)
+	if result.expected.active {
+		result.expected.block = result.expected.fromTable(*chance)
+		result.expected.blockValue = result.Target.BlockValue()
+		return false
+	}
 
 	if roll < *chance {
 		result.Outcome |= OutcomeBlock
 		spell.SpellMetrics[result.Target.UnitIndex].Blocks++
-		result.Damage = max(0, result.Damage-result.Target.BlockValue())
+		result.applyBlockValue(spell, result.Target.BlockValue())
 		return true
 	}
 	return false
@@ -548,6 +635,10 @@ func (result *SpellResult) applyEnemyAttackTableDodge(spell *Spell, attackTable
 		result.Target.GetDiminishedDodgeChance() -
 		spell.Unit.PseudoStats.DodgeReduction
 	*chance += max(0, dodgeChance)
+	if result.expected.active {
+		result.expected.dodge = result.expected.fromTable(*chance)
+		return false
+	}
 
 	if roll < *chance {
 		result.Outcome = OutcomeDodge
@@ -567,6 +658,10 @@ func (result *SpellResult) applyEnemyAttackTableParry(spell *Spell, attackTable
 		result.Target.PseudoStats.BaseParry +
 		result.Target.GetDiminishedParryChance()
 	*chance += max(0, parryChance)
+	if result.expected.active {
+		result.expected.parry = result.expected.fromTable(*chance)
+		return false
+	}
 
 	if roll < *chance {
 		result.Outcome = OutcomeParry
@@ -585,6 +680,11 @@ func (result *SpellResult) applyEnemyAttackTableCrit(spell *Spell, _ *AttackTabl
 	critChance -= result.Target.stats[stats.Resilience] / ResilienceRatingPerCritReductionChance / 100
 	critChance -= result.Target.PseudoStats.ReducedCritTakenChance
 	*chance += max(0, critChance)
+	if result.expected.active {
+		result.expected.crit = result.expected.fromTable(*chance)
+		result.expected.critMultiplier = 2
+		return false
+	}
 
 	if roll < *chance {
 		result.Outcome = OutcomeCrit
@@ -597,6 +697,15 @@ func (result *SpellResult) applyEnemyAttackTableCrit(spell *Spell, _ *AttackTabl
 	return false
 }
 
+// Resilience of PvP targets also reduces the damage of critical strikes, by
+// 2.2 times as much as the crit chance, see resilienceCritReduction().
+func (result *SpellResult) applyResilienceCritDamage(attackTable *AttackTable) {
+	if !attackTable.Defender.PvP || !result.DidCrit() {
+		return
+	}
+	result.Damage *= max(0, 1-result.Target.stats[stats.Resilience]/ResilienceRatingPerCritDamageReductionPercent/100)
+}
+
 func (spell *Spell) OutcomeExpectedTick(_ *Simulation, _ *SpellResult, _ *AttackTable) {
 	// result.Damage *= 1
 }
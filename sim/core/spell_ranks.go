@@ -0,0 +1,58 @@
+package core
+
+import (
+	"fmt"
+)
+
+// Registers a lower rank of a spell, e.g. for downranking heals for mana
+// efficiency, or for effects which cast a specific rank. The rank shares
+// maxRank's cooldowns unless config sets its own, and its metrics are grouped
+// under maxRank. Ranks should be registered from highest to lowest.
+func (unit *Unit) RegisterSpellRank(maxRank *Spell, config SpellConfig) *Spell {
+	if maxRank.Unit != unit {
+		panic(fmt.Sprintf("%s registering a rank of %s, which belongs to %s", unit.Label, maxRank.ActionID, maxRank.Unit.Label))
+	}
+	if maxRank.RankOf != nil {
+		panic(fmt.Sprintf("%s is itself a lower rank, register ranks with the max rank", maxRank.ActionID))
+	}
+	if config.ActionID.SameActionIgnoreTag(maxRank.ActionID) {
+		panic(fmt.Sprintf("Rank of %s needs its own spell ID", maxRank.ActionID))
+	}
+
+	if config.Cast.CD.Timer == nil {
+		config.Cast.CD = maxRank.CD
+	}
+	if config.Cast.SharedCD.Timer == nil {
+		config.Cast.SharedCD = maxRank.SharedCD
+	}
+
+	spell := unit.RegisterSpell(config)
+	spell.RankOf = maxRank
+	maxRank.Ranks = append(maxRank.Ranks, spell)
+	return spell
+}
+
+// Returns the spell's rank from the DBC, or 0 if it has no rank or wasn't
+// generated.
+func (spell *Spell) Rank() int32 {
+	return dbcSpellData[spell.SpellID].Rank
+}
+
+// Returns the spell and its lower ranks, highest rank first.
+func (spell *Spell) AllRanks() []*Spell {
+	if spell.RankOf != nil {
+		return spell.RankOf.AllRanks()
+	}
+	return append([]*Spell{spell}, spell.Ranks...)
+}
+
+// Returns the highest ranked of the spell's ranks which is ready to cast, or
+// nil if none are, e.g. to fall back to a cheaper rank when low on mana.
+func (spell *Spell) HighestReadyRank(sim *Simulation, target *Unit) *Spell {
+	for _, rank := range spell.AllRanks() {
+		if rank.CanCast(sim, target) {
+			return rank
+		}
+	}
+	return nil
+}
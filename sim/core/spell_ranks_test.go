@@ -0,0 +1,115 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpellRanks(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+
+	maxRank := fa.RegisterSpell(SpellConfig{
+		ActionID: ActionID{SpellID: 100},
+		Cast: CastConfig{
+			CD: Cooldown{
+				Timer:    fa.NewTimer(),
+				Duration: time.Second * 10,
+			},
+		},
+		ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {},
+	})
+	rank2 := fa.RegisterSpellRank(maxRank, SpellConfig{
+		ActionID:     ActionID{SpellID: 99},
+		ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {},
+	})
+	rank1 := fa.RegisterSpellRank(maxRank, SpellConfig{
+		ActionID:     ActionID{SpellID: 98},
+		ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {},
+	})
+
+	if rank2.RankOf != maxRank || rank1.RankOf != maxRank {
+		t.Fatalf("Expected ranks to point to the max rank")
+	}
+	for _, spell := range []*Spell{maxRank, rank2, rank1} {
+		ranks := spell.AllRanks()
+		if len(ranks) != 3 || ranks[0] != maxRank || ranks[1] != rank2 || ranks[2] != rank1 {
+			t.Fatalf("Unexpected ranks for %s: %v", spell.ActionID, ranks)
+		}
+	}
+
+	// Ranks share the max rank's cooldown.
+	if rank1.CD.Timer != maxRank.CD.Timer {
+		t.Fatalf("Expected ranks to share the max rank's cooldown")
+	}
+	maxRank.CD.Use(sim)
+	if rank2.CD.IsReady(sim) {
+		t.Fatalf("Expected casting the max rank to put lower ranks on cooldown")
+	}
+
+	dbcSpellData[99] = DBCSpellData{
+		Rank:    2,
+		Effects: []DBCSpellEffect{{Index: 1, Effect: 2, BasePointsMin: 100, BasePointsMax: 120}},
+	}
+	t.Cleanup(func() { delete(dbcSpellData, 99) })
+
+	if rank2.Rank() != 2 || rank1.Rank() != 0 {
+		t.Fatalf("Unexpected DBC ranks: %d, %d", rank2.Rank(), rank1.Rank())
+	}
+	data, _ := DBCSpell(99)
+	if effect, ok := data.Effect(1); !ok || effect.BasePointsMax != 120 {
+		t.Fatalf("Expected effect 1 to be found, got %v", effect)
+	}
+	if _, ok := data.Effect(0); ok {
+		t.Fatalf("Expected effect 0 to be missing")
+	}
+
+	// Lower ranks are grouped under the max rank in metrics.
+	fa.Metrics.addSpellMetrics(rank1, rank1.ActionID, make([]SpellMetrics, 1))
+	fa.Metrics.addSpellMetrics(maxRank, maxRank.ActionID, make([]SpellMetrics, 1))
+	if rankOf := fa.Metrics.actions[rank1.ActionID].ToProto(rank1.ActionID).RankOf; rankOf.GetSpellId() != 100 {
+		t.Errorf("Expected rank 1 metrics to be grouped under the max rank, got %v", rankOf)
+	}
+	if rankOf := fa.Metrics.actions[maxRank.ActionID].ToProto(maxRank.ActionID).RankOf; rankOf != nil {
+		t.Errorf("Expected the max rank not to be grouped, got %v", rankOf)
+	}
+}
+
+func TestSpellRankPanics(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+
+	maxRank := fa.RegisterSpell(SpellConfig{
+		ActionID:     ActionID{SpellID: 100},
+		ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {},
+	})
+	rank1 := fa.RegisterSpellRank(maxRank, SpellConfig{
+		ActionID:     ActionID{SpellID: 99},
+		ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {},
+	})
+
+	testCases := []struct {
+		name    string
+		unit    *Unit
+		maxRank *Spell
+		spellID int32
+	}{
+		{"OtherUnit", sim.Encounter.TargetUnits[0], maxRank, 98},
+		{"LowerRank", &fa.Unit, rank1, 98},
+		{"SameSpellID", &fa.Unit, maxRank, 100},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("Expected RegisterSpellRank to panic")
+				}
+			}()
+			testCase.unit.RegisterSpellRank(testCase.maxRank, SpellConfig{
+				ActionID:     ActionID{SpellID: testCase.spellID},
+				ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {},
+			})
+		})
+	}
+}
@@ -60,8 +60,13 @@ func (spell *Spell) ResistanceMultiplier(sim *Simulation, isPeriodic bool, attac
 	}
 }
 
+// The armor at which physical damage from the attacker is halved.
+func (at *AttackTable) armorConstant() float64 {
+	return float64(at.Attacker.Level)*467.5 - 22167.5
+}
+
 func (at *AttackTable) GetArmorDamageModifier(spell *Spell) float64 {
-	armorConstant := float64(at.Attacker.Level)*467.5 - 22167.5
+	armorConstant := at.armorConstant()
 	defenderArmor := at.Defender.Armor()
 	reducibleArmor := min((defenderArmor+armorConstant)/3, defenderArmor)
 	armorPenRating := at.Attacker.stats[stats.ArmorPenetration] + spell.BonusArmorPenRating
@@ -147,3 +147,62 @@ func Test_PartialResistsVsBoss(t *testing.T) {
 		}
 	}
 }
+
+func Test_PartialResistDistribution(t *testing.T) {
+	attacker := &Unit{
+		Type:  EnemyUnit,
+		Level: 83,
+		stats: stats.Stats{},
+	}
+	defender := &Unit{
+		Type:  PlayerUnit,
+		Level: 80,
+		stats: stats.Stats{},
+	}
+
+	attackTable := NewAttackTable(attacker, defender)
+
+	sim := NewSim(&proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{RandomSeed: 101},
+		Encounter:  &proto.Encounter{},
+		Raid:       &proto.Raid{},
+	})
+
+	partial := &Spell{SpellSchool: SpellSchoolFrost}
+	binary := &Spell{SpellSchool: SpellSchoolFrost, Flags: SpellFlagBinary}
+
+	const n = 100_000
+	for _, resist := range []float64{30, 130, 255, 510, 1000} {
+		defender.stats[stats.FrostResistance] = resist
+		averageResist := defender.averageResist(SpellSchoolFrost, attacker)
+
+		// Each bracket should be rolled as often as its threshold says.
+		brackets := make(map[int]int)
+		for i := 0; i < n; i++ {
+			multiplier := partial.ResistanceMultiplier(sim, false, attackTable)
+			brackets[int(math.Round((1-multiplier)*10))]++
+		}
+		var previous float64
+		for _, th := range defender.partialResistRollThresholds(averageResist) {
+			expected := th.cumulativeChance - previous
+			if actual := float64(brackets[th.bracket]) / n; math.Abs(actual-expected) > 0.01 {
+				t.Errorf("resist = %.0f: expected %.2f%% of %d%% resists, got %.2f%%", resist, expected*100, th.bracket*10, actual*100)
+			}
+			if th.cumulativeChance >= 1 {
+				break
+			}
+			previous = th.cumulativeChance
+		}
+
+		// Binary spells are fully resisted as often as the average resist.
+		fullResists := 0
+		for i := 0; i < n; i++ {
+			if binary.ResistanceMultiplier(sim, false, attackTable) == 0 {
+				fullResists++
+			}
+		}
+		if actual := float64(fullResists) / n; math.Abs(actual-averageResist) > 0.01 {
+			t.Errorf("resist = %.0f: expected %.2f%% binary resists, got %.2f%%", resist, averageResist*100, actual*100)
+		}
+	}
+}
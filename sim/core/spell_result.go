@@ -18,6 +18,20 @@ type SpellResult struct {
 
 	ResistanceMultiplier float64 // Partial Resists / Armor multiplier
 	PreOutcomeDamage     float64 // Damage done by this cast before Outcome is applied
+	BlockedDamage        float64 // Damage prevented by the target's block value
+
+	// Damage done by this cast before any of the target's modifiers,
+	// resistances or avoidance, i.e. what the target would take unmitigated.
+	PreMitigationDamage float64
+
+	// Health returned to the caster when a landed result is dealt, for drain
+	// effects: a fraction of the damage dealt, plus a flat amount. Both are
+	// scaled by the caster's healing taken multiplier.
+	HealthReturnMultiplier float64
+	HealthReturn           float64
+
+	// Only set in expected value mode.
+	expected expectedOutcome
 
 	inUse bool
 }
@@ -25,19 +39,35 @@ type SpellResult struct {
 func (spell *Spell) NewResult(target *Unit) *SpellResult {
 	result := &spell.resultCache
 	if result.inUse {
-		result = &SpellResult{}
+		if n := len(spell.resultPool); n > 0 {
+			result = spell.resultPool[n-1]
+			spell.resultPool = spell.resultPool[:n-1]
+		} else {
+			result = &SpellResult{}
+		}
 	}
 
 	result.Target = target
 	result.Damage = 0
 	result.Threat = 0
+	result.BlockedDamage = 0
+	result.PreMitigationDamage = 0
+	result.HealthReturnMultiplier = 0
+	result.HealthReturn = 0
 	result.Outcome = OutcomeEmpty // for blocks
+	result.expected.active = false
 	result.inUse = true
 
 	return result
 }
 func (spell *Spell) DisposeResult(result *SpellResult) {
+	if !result.inUse {
+		return
+	}
 	result.inUse = false
+	if result != &spell.resultCache {
+		spell.resultPool = append(spell.resultPool, result)
+	}
 }
 
 func (result *SpellResult) Landed() bool {
@@ -101,7 +131,7 @@ func (spell *Spell) PhysicalCritChance(attackTable *AttackTable) float64 {
 	critRating := spell.Unit.stats[stats.MeleeCrit] +
 		spell.BonusCritRating +
 		attackTable.Defender.PseudoStats.BonusCritRatingTaken
-	return critRating/(CritRatingPerCritChance*100) - attackTable.MeleeCritSuppression
+	return critRating/(CritRatingPerCritChance*100) - attackTable.MeleeCritSuppression - attackTable.resilienceCritReduction()
 }
 func (spell *Spell) PhysicalCritCheck(sim *Simulation, attackTable *AttackTable) bool {
 	return sim.RandomFloat("Physical Crit Roll") < spell.PhysicalCritChance(attackTable)
@@ -134,7 +164,8 @@ func (spell *Spell) spellCritRating(target *Unit) float64 {
 		target.PseudoStats.BonusSpellCritRatingTaken
 }
 func (spell *Spell) SpellCritChance(target *Unit) float64 {
-	return spell.spellCritRating(target)/(CritRatingPerCritChance*100) - spell.Unit.AttackTables[target.UnitIndex].SpellCritSuppression
+	attackTable := spell.Unit.AttackTables[target.UnitIndex]
+	return spell.spellCritRating(target)/(CritRatingPerCritChance*100) - attackTable.SpellCritSuppression - attackTable.resilienceCritReduction()
 }
 func (spell *Spell) MagicCritCheck(sim *Simulation, target *Unit) bool {
 	critChance := spell.SpellCritChance(target)
@@ -165,15 +196,18 @@ func (spell *Spell) ApplyPostOutcomeDamageModifiers(sim *Simulation, result *Spe
 
 // For spells that do no damage but still have a hit/miss check.
 func (spell *Spell) CalcOutcome(sim *Simulation, target *Unit, outcomeApplier OutcomeApplier) *SpellResult {
+	sim.allocAudit.begin(allocSectionDamage)
 	attackTable := spell.Unit.AttackTables[target.UnitIndex]
 	result := spell.NewResult(target)
 
 	outcomeApplier(sim, result, attackTable)
 	result.Threat = spell.ThreatFromDamage(result.Outcome, result.Damage)
+	sim.allocAudit.end()
 	return result
 }
 
 func (spell *Spell) calcDamageInternal(sim *Simulation, target *Unit, baseDamage float64, attackerMultiplier float64, isPeriodic bool, outcomeApplier OutcomeApplier) *SpellResult {
+	sim.allocAudit.begin(allocSectionDamage)
 	attackTable := spell.Unit.AttackTables[target.UnitIndex]
 
 	result := spell.NewResult(target)
@@ -181,30 +215,35 @@ func (spell *Spell) calcDamageInternal(sim *Simulation, target *Unit, baseDamage
 
 	if sim.Log == nil {
 		result.Damage *= attackerMultiplier
+		result.PreMitigationDamage = result.Damage
 		result.applyTargetModifiers(spell, attackTable, isPeriodic)
 		result.applyResistances(sim, spell, isPeriodic, attackTable)
 		outcomeApplier(sim, result, attackTable)
+		result.applyResilienceCritDamage(attackTable)
 		spell.ApplyPostOutcomeDamageModifiers(sim, result)
 	} else {
 		result.Damage *= attackerMultiplier
 		afterAttackMods := result.Damage
-		result.applyResistances(sim, spell, isPeriodic, attackTable)
-		afterResistances := result.Damage
+		result.PreMitigationDamage = result.Damage
 		result.applyTargetModifiers(spell, attackTable, isPeriodic)
 		afterTargetMods := result.Damage
+		result.applyResistances(sim, spell, isPeriodic, attackTable)
+		afterResistances := result.Damage
 		outcomeApplier(sim, result, attackTable)
+		result.applyResilienceCritDamage(attackTable)
 		afterOutcome := result.Damage
 		spell.ApplyPostOutcomeDamageModifiers(sim, result)
 		afterPostOutcome := result.Damage
 
 		spell.Unit.Log(
 			sim,
-			"%s %s [DEBUG] MAP: %0.01f, RAP: %0.01f, SP: %0.01f, BaseDamage:%0.01f, AfterAttackerMods:%0.01f, AfterResistances:%0.01f, AfterTargetMods:%0.01f, AfterOutcome:%0.01f, AfterPostOutcome:%0.01f",
-			target.LogLabel(), spell.ActionID, spell.Unit.GetStat(stats.AttackPower), spell.Unit.GetStat(stats.RangedAttackPower), spell.Unit.GetStat(stats.SpellPower), baseDamage, afterAttackMods, afterResistances, afterTargetMods, afterOutcome, afterPostOutcome)
+			"%s %s [DEBUG] MAP: %0.01f, RAP: %0.01f, SP: %0.01f, BaseDamage:%0.01f, AfterAttackerMods:%0.01f, AfterTargetMods:%0.01f, AfterResistances:%0.01f, AfterOutcome:%0.01f, AfterPostOutcome:%0.01f",
+			target.LogLabel(), spell.ActionID, spell.Unit.GetStat(stats.AttackPower), spell.Unit.GetStat(stats.RangedAttackPower), spell.Unit.GetStat(stats.SpellPower), baseDamage, afterAttackMods, afterTargetMods, afterResistances, afterOutcome, afterPostOutcome)
 	}
 
 	result.Threat = spell.ThreatFromDamage(result.Outcome, result.Damage)
 
+	sim.allocAudit.end()
 	return result
 }
 func (spell *Spell) CalcDamage(sim *Simulation, target *Unit, baseDamage float64, outcomeApplier OutcomeApplier) *SpellResult {
@@ -230,21 +269,36 @@ func (spell *Spell) CalcAndDealOutcome(sim *Simulation, target *Unit, outcomeApp
 
 // Applies the fully computed spell result to the sim.
 func (spell *Spell) dealDamageInternal(sim *Simulation, isPeriodic bool, result *SpellResult) {
+	sim.allocAudit.begin(allocSectionDamage)
+	if simInvariantsEnabled {
+		spell.checkResultInUse(sim, result)
+	}
 	spell.SpellMetrics[result.Target.UnitIndex].TotalDamage += result.Damage
-	spell.SpellMetrics[result.Target.UnitIndex].TotalThreat += result.Threat
+	if sim.damageBucketWidth > 0 {
+		spell.SpellMetrics[result.Target.UnitIndex].addBucketDamage(sim, result.Damage)
+	}
+	spell.addThreat(sim, result.Target, result.Threat)
 
 	// Mark total damage done in raid so far for health based fights.
-	// Don't include damage done by EnemyUnits to Players
+	// Don't include damage done by EnemyUnits to Players, or damage to adds.
 	if result.Target.Type == EnemyUnit {
-		sim.Encounter.DamageTaken += result.Damage
+		if sim.Encounter.addsByIndex == nil || !sim.Encounter.damageAdd(sim, result.Target, result.Damage) {
+			sim.Encounter.damageTarget(sim, result.Target, result.Damage)
+		}
+		if drm := result.Target.Metrics.dpsRequirement; drm != nil {
+			drm.damage += result.Damage
+		}
+		if sim.executePhase <= 35 {
+			spell.Unit.Metrics.executeDps.Total += result.Damage
+		}
 	}
 
 	if sim.Log != nil {
-		if isPeriodic {
-			spell.Unit.Log(sim, "%s %s tick %s. (Threat: %0.3f)", result.Target.LogLabel(), spell.ActionID, result.DamageString(), result.Threat)
-		} else {
-			spell.Unit.Log(sim, "%s %s %s. (Threat: %0.3f)", result.Target.LogLabel(), spell.ActionID, result.DamageString(), result.Threat)
-		}
+		spell.logResult(sim, LogEventDamage, result, isPeriodic)
+	}
+
+	if result.HealthReturnMultiplier != 0 || result.HealthReturn != 0 {
+		result.applyHealthReturn(sim, spell)
 	}
 
 	if !spell.Flags.Matches(SpellFlagNoOnDamageDealt) {
@@ -258,7 +312,22 @@ func (spell *Spell) dealDamageInternal(sim *Simulation, isPeriodic bool, result
 	}
 
 	spell.DisposeResult(result)
+	sim.allocAudit.end()
+}
+
+// Heals the caster for drain effects, with health metrics attributed to the
+// spell.
+func (result *SpellResult) applyHealthReturn(sim *Simulation, spell *Spell) {
+	if !result.Landed() {
+		return
+	}
+	healthGain := result.Damage*result.HealthReturnMultiplier + result.HealthReturn
+	if healthGain > 0 {
+		caster := spell.Unit
+		caster.GainHealth(sim, healthGain*caster.PseudoStats.HealingTakenMultiplier, spell.HealthMetrics(caster))
+	}
 }
+
 func (spell *Spell) DealDamage(sim *Simulation, result *SpellResult) {
 	spell.dealDamageInternal(sim, false, result)
 }
@@ -283,6 +352,7 @@ func (dot *Dot) CalcAndDealPeriodicSnapshotDamage(sim *Simulation, target *Unit,
 }
 
 func (spell *Spell) calcHealingInternal(sim *Simulation, target *Unit, baseHealing float64, casterMultiplier float64, outcomeApplier OutcomeApplier) *SpellResult {
+	sim.allocAudit.begin(allocSectionDamage)
 	attackTable := spell.Unit.AttackTables[target.UnitIndex]
 
 	result := spell.NewResult(target)
@@ -308,6 +378,7 @@ func (spell *Spell) calcHealingInternal(sim *Simulation, target *Unit, baseHeali
 
 	result.Threat = spell.ThreatFromDamage(result.Outcome, result.Damage)
 
+	sim.allocAudit.end()
 	return result
 }
 func (spell *Spell) CalcHealing(sim *Simulation, target *Unit, baseHealing float64, outcomeApplier OutcomeApplier) *SpellResult {
@@ -319,18 +390,18 @@ func (dot *Dot) CalcSnapshotHealing(sim *Simulation, target *Unit, outcomeApplie
 
 // Applies the fully computed spell result to the sim.
 func (spell *Spell) dealHealingInternal(sim *Simulation, isPeriodic bool, result *SpellResult) {
+	sim.allocAudit.begin(allocSectionDamage)
+	if simInvariantsEnabled {
+		spell.checkResultInUse(sim, result)
+	}
 	spell.SpellMetrics[result.Target.UnitIndex].TotalHealing += result.Damage
-	spell.SpellMetrics[result.Target.UnitIndex].TotalThreat += result.Threat
+	spell.addThreat(sim, result.Target, result.Threat)
 	if result.Target.HasHealthBar() {
 		result.Target.GainHealth(sim, result.Damage, spell.HealthMetrics(result.Target))
 	}
 
 	if sim.Log != nil {
-		if isPeriodic {
-			spell.Unit.Log(sim, "%s %s tick %s. (Threat: %0.3f)", result.Target.LogLabel(), spell.ActionID, result.HealingString(), result.Threat)
-		} else {
-			spell.Unit.Log(sim, "%s %s %s. (Threat: %0.3f)", result.Target.LogLabel(), spell.ActionID, result.HealingString(), result.Threat)
-		}
+		spell.logResult(sim, LogEventHealing, result, isPeriodic)
 	}
 
 	if isPeriodic {
@@ -342,6 +413,7 @@ func (spell *Spell) dealHealingInternal(sim *Simulation, isPeriodic bool, result
 	}
 
 	spell.DisposeResult(result)
+	sim.allocAudit.end()
 }
 func (spell *Spell) DealHealing(sim *Simulation, result *SpellResult) {
 	spell.dealHealingInternal(sim, false, result)
@@ -422,6 +494,10 @@ func (spell *Spell) TargetDamageMultiplier(attackTable *AttackTable, isPeriodic
 		multiplier *= attackTable.Defender.PseudoStats.PeriodicPhysicalDamageTakenMultiplier
 	}
 
+	if attackTable.Defender.damageWindows != nil {
+		multiplier *= attackTable.Defender.damageWindows.damageTakenMultiplier(isPeriodic)
+	}
+
 	return multiplier
 }
 
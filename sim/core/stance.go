@@ -0,0 +1,111 @@
+package core
+
+import (
+	"time"
+)
+
+// Mutually exclusive states of a unit which change its stats and which spells
+// it can cast, like warrior stances or death knight presences. Classes define
+// their own stances as bits, so spells can require any of several stances.
+type Stance uint8
+
+const StanceNone Stance = 0
+
+const stanceEffectCategory = "Stance"
+
+type stanceAura struct {
+	stance Stance
+	aura   *Aura
+}
+
+// Registers the aura which is active while this unit is in the given stance,
+// and which applies all of the stance's effects. Only one stance aura can be
+// active at a time. Its uptime is tracked like for any other aura.
+func (unit *Unit) RegisterStance(stance Stance, aura *Aura) {
+	aura.NewExclusiveEffect(stanceEffectCategory, true, ExclusiveEffect{})
+	unit.stanceAuras = append(unit.stanceAuras, stanceAura{stance: stance, aura: aura})
+}
+
+func (unit *Unit) CurrentStance() Stance {
+	return unit.stance
+}
+
+// Whether this unit is in any of the given stances.
+func (unit *Unit) StanceMatches(stances Stance) bool {
+	return (unit.stance & stances) != 0
+}
+
+// Switches this unit to the given stance, replacing the aura of its previous
+// stance. Switching to StanceNone just removes the previous stance aura.
+func (unit *Unit) SetStance(sim *Simulation, stance Stance) {
+	unit.stance = stance
+	unit.activateStanceAura(sim, stance)
+}
+
+func (unit *Unit) activateStanceAura(sim *Simulation, stance Stance) {
+	for _, sa := range unit.stanceAuras {
+		if sa.stance == stance {
+			sa.aura.Activate(sim)
+		} else if stance == StanceNone {
+			sa.aura.Deactivate(sim)
+		}
+	}
+}
+
+type StanceSpellConfig struct {
+	Stance Stance
+	Flags  SpellFlag
+
+	RuneCost RuneCostOptions
+	Cast     CastConfig
+
+	// Delays activating the new stance's aura, so that casts on the same
+	// timestamp are still affected by the previous stance.
+	AuraDelay time.Duration
+
+	// Additional effects of switching, e.g. losing resources.
+	OnSwitch func(sim *Simulation)
+}
+
+// Registers the spell which switches this unit into a registered stance. The
+// spell can't be cast while already in that stance.
+func (unit *Unit) RegisterStanceSpell(config StanceSpellConfig) *Spell {
+	var aura *Aura
+	for _, sa := range unit.stanceAuras {
+		if sa.stance == config.Stance {
+			aura = sa.aura
+		}
+	}
+	if aura == nil {
+		panic("Stance spell registered before its stance")
+	}
+
+	return unit.RegisterSpell(SpellConfig{
+		ActionID: aura.ActionID,
+		Flags:    config.Flags,
+
+		RuneCost: config.RuneCost,
+		Cast:     config.Cast,
+		ExtraCastCondition: func(sim *Simulation, target *Unit) bool {
+			return unit.stance != config.Stance
+		},
+
+		ApplyEffects: func(sim *Simulation, _ *Unit, _ *Spell) {
+			if config.OnSwitch != nil {
+				config.OnSwitch(sim)
+			}
+
+			if config.AuraDelay == 0 {
+				unit.SetStance(sim, config.Stance)
+				return
+			}
+			unit.stance = config.Stance
+			StartDelayedAction(sim, DelayedActionOptions{
+				DoAt: sim.CurrentTime + config.AuraDelay,
+				OnAction: func(sim *Simulation) {
+					unit.activateStanceAura(sim, config.Stance)
+				},
+			})
+		},
+	})
+}
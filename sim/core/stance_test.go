@@ -0,0 +1,46 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStances(t *testing.T) {
+	sim := &Simulation{}
+
+	unit := &Unit{
+		Type:        EnemyUnit,
+		auraTracker: newAuraTracker(),
+	}
+	const (
+		stanceA Stance = 1 << iota
+		stanceB
+	)
+	auraA := unit.RegisterAura(Aura{Label: "Stance A", ActionID: ActionID{SpellID: 1}, Duration: NeverExpires})
+	auraB := unit.RegisterAura(Aura{Label: "Stance B", ActionID: ActionID{SpellID: 2}, Duration: NeverExpires})
+	unit.RegisterStance(stanceA, auraA)
+	unit.RegisterStance(stanceB, auraB)
+
+	if unit.StanceMatches(stanceA|stanceB) || unit.CurrentStance() != StanceNone {
+		t.Fatalf("Expected no stance initially")
+	}
+
+	unit.SetStance(sim, stanceA)
+	if !unit.StanceMatches(stanceA) || !auraA.IsActive() || auraB.IsActive() {
+		t.Fatalf("Expected only stance A to be active")
+	}
+
+	sim.CurrentTime = time.Second * 10
+	unit.SetStance(sim, stanceB)
+	if unit.StanceMatches(stanceA) || !unit.StanceMatches(stanceA|stanceB) || auraA.IsActive() || !auraB.IsActive() {
+		t.Fatalf("Expected switching to stance B to replace stance A")
+	}
+	if uptime := auraA.metrics.Uptime; uptime != time.Second*10 {
+		t.Fatalf("Expected 10s of stance A uptime, got %s", uptime)
+	}
+
+	unit.SetStance(sim, StanceNone)
+	if auraA.IsActive() || auraB.IsActive() {
+		t.Fatalf("Expected no stance auras to be active")
+	}
+}
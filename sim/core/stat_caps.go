@@ -0,0 +1,51 @@
+package core
+
+import (
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+// Records the caps which apply to this character, once per sim. This is done
+// when the pull starts, so that permanent debuffs on the target are active,
+// but before any procs.
+func (character *Character) recordStatCaps() {
+	if character.statCaps != nil || character.defaultTarget == nil {
+		return
+	}
+	character.statCaps = make([]*proto.StatCap, 0, 6)
+
+	target := character.defaultTarget
+	attackTable := character.AttackTables[target.UnitIndex]
+	addCap := func(capType proto.StatCapType, stat stats.Stat, capRating float64) {
+		current := character.GetStat(stat)
+		character.statCaps = append(character.statCaps, &proto.StatCap{
+			Type:          capType,
+			Stat:          proto.Stat(stat),
+			CapRating:     max(0, capRating),
+			CurrentRating: current,
+			RatingToCap:   max(0, capRating) - current,
+		})
+	}
+
+	if len(character.GetSpellsMatchingSchool(SpellSchoolMagic)) > 0 {
+		addCap(proto.StatCapType_StatCapSpellHit, stats.SpellHit,
+			attackTable.BaseSpellMissChance*100*SpellHitRatingPerHitChance-target.PseudoStats.BonusSpellHitRatingTaken)
+	}
+
+	aa := &character.AutoAttacks
+	if !aa.AutoSwingMelee && !aa.AutoSwingRanged {
+		return
+	}
+	meleeHitCap := attackTable.BaseMissChance*100*MeleeHitRatingPerHitChance - target.PseudoStats.BonusMeleeHitRatingTaken
+	addCap(proto.StatCapType_StatCapMeleeHitSpecial, stats.MeleeHit, meleeHitCap)
+	if aa.AutoSwingMelee && aa.IsDualWielding && !character.PseudoStats.DisableDWMissPenalty {
+		addCap(proto.StatCapType_StatCapMeleeHitWhite, stats.MeleeHit, meleeHitCap+19*MeleeHitRatingPerHitChance)
+	}
+	if aa.AutoSwingMelee {
+		// See Spell.ExpertisePercentage().
+		dodgeChance := attackTable.BaseDodgeChance - character.PseudoStats.DodgeReduction
+		addCap(proto.StatCapType_StatCapExpertiseSoft, stats.Expertise, dodgeChance*400*ExpertisePerQuarterPercentReduction)
+		addCap(proto.StatCapType_StatCapExpertiseHard, stats.Expertise, attackTable.BaseParryChance*400*ExpertisePerQuarterPercentReduction)
+	}
+	addCap(proto.StatCapType_StatCapArmorPenetration, stats.ArmorPenetration, 100*ArmorPenPerPercentArmor)
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestStatCaps(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{Iterations: 10, RandomSeed: 1})
+	request.Raid.Debuffs = &proto.Debuffs{Misery: true}
+	request.Raid.Parties[0].Players[0].BonusStats = &proto.UnitStats{Stats: make([]float64, stats.Len)}
+	request.Raid.Parties[0].Players[0].BonusStats.Stats[stats.SpellHit] = 100
+
+	result := RunRaidSim(request)
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed with error: %s", result.ErrorResult)
+	}
+
+	// 17% base miss chance, minus 3% from Misery. The player has no auto
+	// attacks, so only the spell hit cap applies.
+	caps := result.RaidMetrics.Parties[0].Players[0].StatCaps
+	if len(caps) != 1 || caps[0].Type != proto.StatCapType_StatCapSpellHit {
+		t.Fatalf("Expected only the spell hit cap, got %v", caps)
+	}
+	if expected := 14 * SpellHitRatingPerHitChance; !WithinToleranceFloat64(expected, caps[0].CapRating, 1e-6) ||
+		!WithinToleranceFloat64(expected-100, caps[0].RatingToCap, 1e-6) {
+		t.Fatalf("Expected a cap of %f rating, %f away, got %v", expected, expected-100, caps[0])
+	}
+}
+
+func TestStatWeightCaps(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{Iterations: 20, RandomSeed: 1})
+	swr := &proto.StatWeightsRequest{
+		Player:       request.Raid.Parties[0].Players[0],
+		RaidBuffs:    &proto.RaidBuffs{},
+		PartyBuffs:   &proto.PartyBuffs{},
+		Debuffs:      &proto.Debuffs{},
+		Encounter:    request.Encounter,
+		SimOptions:   request.SimOptions,
+		Tanks:        request.Raid.Tanks,
+		StatsToWeigh: []proto.Stat{proto.Stat_StatSpellHit},
+	}
+
+	var mu sync.Mutex
+	var spellHit []float64
+	runSim := func(ctx context.Context, rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool) *proto.RaidSimResult {
+		mu.Lock()
+		spellHit = append(spellHit, rsr.Raid.Parties[0].Players[0].BonusStats.Stats[stats.SpellHit])
+		mu.Unlock()
+		return runSimWithContext(ctx, rsr, progress, skipPresim)
+	}
+	result := calcStatWeight(context.Background(), swr, stats.SpellPower, nil, runSim, 4)
+
+	if len(result.Caps) != 1 || result.Caps[0].Cap.Type != proto.StatCapType_StatCapSpellHit {
+		t.Fatalf("Expected the spell hit cap, got %v", result.Caps)
+	}
+	capRating := 17 * SpellHitRatingPerHitChance
+	for _, bonus := range []float64{capRating - 20, capRating, capRating + 20} {
+		if !slices.ContainsFunc(spellHit, func(hit float64) bool { return WithinToleranceFloat64(bonus, hit, 1e-6) }) {
+			t.Errorf("Expected a sim with %f spell hit, got %v", bonus, spellHit)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+// Returns the average stats from the character's stat procs. Procs without an
+// estimated uptime are only included if they have an internal cooldown, and
+// are assumed to proc again as soon as it is ready, so this is an upper bound
+// for procs with a low chance.
+func (character *Character) averageProcStats(uptimes map[string]float64) stats.Stats {
+	var procStats stats.Stats
+	for _, aura := range character.auras {
+		if aura.tempStats == (stats.Stats{}) || aura.Duration <= 0 || aura.Duration == NeverExpires {
+			continue
+		}
+		uptime, ok := uptimes[aura.Label]
+		if !ok {
+			if aura.Icd == nil || aura.Icd.Duration <= 0 {
+				continue
+			}
+			uptime = min(1, float64(aura.Duration)/float64(aura.Icd.Duration))
+		}
+		procStats = procStats.Add(aura.tempStats.Multiply(uptime))
+	}
+	return procStats
+}
+
+// Runs the requested calibration iterations, and returns the estimated proc
+// uptimes of the given player.
+func calibrateProcUptimes(rsr *proto.RaidSimRequest, player *proto.UnitReference, iterations int32) ([]*proto.ProcUptime, map[string]float64) {
+	sim := NewSim(rsr)
+	var totalDuration time.Duration
+	for i := int32(0); i < iterations; i++ {
+		sim.reseedRands(int64(i))
+		sim.runOnce()
+		if sim.Encounter.EndFightAtHealth != 0 {
+			totalDuration += sim.CurrentTime
+		} else {
+			totalDuration += sim.Duration
+		}
+	}
+
+	unit := sim.GetUnit(player, nil)
+	return sim.Raid.GetPlayerFromUnit(unit).GetCharacter().procUptimes(totalDuration)
+}
+
+// Computes the stat sheet of a single player as it stands when the fight
+// starts, with permanent buffs and debuffs active.
+func computeStatSheet(request *proto.StatSheetRequest) (result *proto.StatSheetResult) {
+	defer func() {
+		if err := recover(); err != nil {
+			result = &proto.StatSheetResult{
+				ErrorResult: fmt.Sprintf("%v\nStack Trace:\n%s", err, debug.Stack()),
+			}
+		}
+	}()
+
+	rsr := request.Request
+	if rsr.SimOptions == nil {
+		rsr.SimOptions = &proto.SimOptions{}
+	}
+	sim := NewSim(rsr)
+	sim.reset()
+
+	unit := sim.GetUnit(request.Player, nil)
+	if unit == nil || unit.Type != PlayerUnit {
+		panic("Invalid player")
+	}
+	character := sim.Raid.GetPlayerFromUnit(unit).GetCharacter()
+	character.recordStatCaps()
+
+	var procUptimes []*proto.ProcUptime
+	var uptimes map[string]float64
+	if request.CalibrationIterations > 0 {
+		procUptimes, uptimes = calibrateProcUptimes(rsr, request.Player, request.CalibrationIterations)
+	}
+
+	pseudoStats := character.GetPseudoStatsProto()
+	withProcs := character.ApplyStatDependencies(character.statsWithoutDeps.Add(character.averageProcStats(uptimes)))
+	result = &proto.StatSheetResult{
+		Stats: &proto.UnitStats{
+			Stats:       character.GetStats().ToFloatArray(),
+			PseudoStats: pseudoStats,
+		},
+		StatsWithProcs: &proto.UnitStats{
+			Stats:       withProcs.ToFloatArray(),
+			PseudoStats: pseudoStats,
+		},
+		Gcd:         character.SpellGCD().Seconds(),
+		StatCaps:    character.statCaps,
+		ProcUptimes: procUptimes,
+	}
+
+	if target := character.defaultTarget; target != nil {
+		attackTable := character.AttackTables[target.UnitIndex]
+		meleeSpell := &Spell{Unit: unit}
+		if character.AutoAttacks.AutoSwingMelee {
+			meleeSpell = character.AutoAttacks.MHAuto()
+		}
+		rangedSpell := &Spell{Unit: unit}
+		if character.AutoAttacks.AutoSwingRanged {
+			rangedSpell = character.AutoAttacks.RangedAuto()
+		}
+		result.MeleeCritChance = max(0, meleeSpell.PhysicalCritChance(attackTable))
+		result.RangedCritChance = max(0, rangedSpell.PhysicalCritChance(attackTable))
+		result.SpellCritChance = max(0, (&Spell{Unit: unit}).SpellCritChance(target))
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestStatSheet(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{})
+	request.Raid.Parties[0].Players[0].BonusStats = &proto.UnitStats{Stats: stats.Stats{
+		stats.MeleeCrit:  20 * CritRatingPerCritChance,
+		stats.SpellCrit:  10 * CritRatingPerCritChance,
+		stats.SpellHaste: 50 * HasteRatingPerHastePercent,
+	}.ToFloatArray()}
+
+	result := ComputeStatSheet(&proto.StatSheetRequest{
+		Request: request,
+		Player:  &proto.UnitReference{Type: proto.UnitReference_Player, Index: 0},
+	})
+	if result.ErrorResult != "" {
+		t.Fatalf("Failed to compute stat sheet: %s", result.ErrorResult)
+	}
+
+	// Crit against a level 83 target is suppressed by 4.8% for melee and 2.1%
+	// for spells.
+	if !WithinToleranceFloat64(0.2-0.048, result.MeleeCritChance, 1e-9) {
+		t.Fatalf("Expected 15.2%% melee crit, got %f", result.MeleeCritChance)
+	}
+	if !WithinToleranceFloat64(0.1-0.021, result.SpellCritChance, 1e-9) {
+		t.Fatalf("Expected 7.9%% spell crit, got %f", result.SpellCritChance)
+	}
+	if !WithinToleranceFloat64(1.0, result.Gcd, 1e-9) {
+		t.Fatalf("Expected a 1s GCD at 50%% haste, got %f", result.Gcd)
+	}
+	if len(result.StatCaps) == 0 {
+		t.Fatalf("Expected stat caps")
+	}
+}
+
+func TestAverageProcStats(t *testing.T) {
+	sim := NewSim(tankSimRequest(&proto.SimOptions{}))
+	character := sim.Raid.Parties[0].Players[0].GetCharacter()
+
+	procAura := character.NewTemporaryStatsAura("Test Proc", ActionID{SpellID: 1}, stats.Stats{stats.AttackPower: 1000}, time.Second*10)
+	procAura.Icd = &Cooldown{Timer: character.NewTimer(), Duration: time.Second * 45}
+	// Procs without an internal cooldown aren't included.
+	character.NewTemporaryStatsAura("Test Proc Without ICD", ActionID{SpellID: 2}, stats.Stats{stats.AttackPower: 1000}, time.Second*10)
+
+	if procStats := character.averageProcStats(nil); !WithinToleranceFloat64(1000.0/4.5, procStats[stats.AttackPower], 1e-9) {
+		t.Fatalf("Expected %f average attack power, got %f", 1000.0/4.5, procStats[stats.AttackPower])
+	}
+	// Estimated uptimes replace the cooldown based ones.
+	uptimes := map[string]float64{"Test Proc": 0.1, "Test Proc Without ICD": 0.5}
+	if procStats := character.averageProcStats(uptimes); !WithinToleranceFloat64(600, procStats[stats.AttackPower], 1e-9) {
+		t.Fatalf("Expected 600 average attack power, got %f", procStats[stats.AttackPower])
+	}
+}
@@ -325,6 +325,9 @@ type PseudoStats struct {
 
 	ThreatMultiplier float64 // Modulates the threat generated. Affected by things like salv.
 
+	RageFromDamageDealtMultiplier float64 // Rage from white hits, e.g. Endless Rage.
+	RageFromDamageTakenMultiplier float64 // Rage from being hit.
+
 	DamageDealtMultiplier       float64            // All damage
 	SchoolDamageDealtMultiplier [SchoolLen]float64 // For specific spell schools (arcane, fire, shadow, etc).
 
@@ -392,6 +395,9 @@ func NewPseudoStats() PseudoStats {
 
 		ThreatMultiplier: 1,
 
+		RageFromDamageDealtMultiplier: 1,
+		RageFromDamageTakenMultiplier: 1,
+
 		DamageDealtMultiplier:       1,
 		SchoolDamageDealtMultiplier: NewSchoolFloatArray(),
 
@@ -1,11 +1,12 @@
 package core
 
 import (
+	"context"
 	"math"
 	"runtime"
+	"slices"
 	"sync"
 	"sync/atomic"
-	"time"
 
 	"github.com/wowsims/wotlk/sim/core/proto"
 	"github.com/wowsims/wotlk/sim/core/stats"
@@ -78,6 +79,7 @@ type StatWeightsResult struct {
 	Dtps   StatWeightValues
 	Tmi    StatWeightValues
 	PDeath StatWeightValues
+	Caps   []*proto.StatCapWeights
 }
 
 func NewStatWeightsResult() *StatWeightsResult {
@@ -99,10 +101,41 @@ func (swr *StatWeightsResult) ToProto() *proto.StatWeightsResult {
 		Dtps:   swr.Dtps.ToProto(),
 		Tmi:    swr.Tmi.ToProto(),
 		PDeath: swr.PDeath.ToProto(),
+		Caps:   swr.Caps,
 	}
 }
 
-func CalcStatWeight(swr *proto.StatWeightsRequest, referenceStat stats.Stat, progress chan *proto.ProgressMetrics) *StatWeightsResult {
+func CalcStatWeight(ctx context.Context, swr *proto.StatWeightsRequest, referenceStat stats.Stat, progress chan *proto.ProgressMetrics) *StatWeightsResult {
+	concurrency := (runtime.NumCPU() - 1) * 2
+	if concurrency <= 0 {
+		concurrency = 2
+	}
+	return calcStatWeight(ctx, swr, referenceStat, progress, runSimWithContext, concurrency)
+}
+
+// Most iterations calcStatWeight could run in total: the baseline, a sim above
+// and below for each stat, and 3 more for each weighed stat with a cap, each
+// with half the requested iterations.
+func statWeightsIterations(swr *proto.StatWeightsRequest) int64 {
+	statsToWeigh := stats.ProtoArrayToStatsList(swr.StatsToWeigh)
+	weighed := map[stats.UnitStat]bool{stats.UnitStatFromStat(stats.Stat(swr.EpReferenceStat)): true}
+	for _, s := range statsToWeigh {
+		weighed[stats.UnitStatFromStat(s)] = true
+	}
+	for _, s := range swr.PseudoStatsToWeigh {
+		weighed[stats.UnitStatFromPseudoStat(s)] = true
+	}
+	// Which stats have caps is only known after the baseline, so assume all.
+	numSims := 1 + 2*len(weighed) + 3*len(statsToWeigh)
+	return int64(swr.GetSimOptions().GetIterations()/2) * int64(numSims)
+}
+
+// Like CalcStatWeight, but runs each sim with runSim, with up to concurrency
+// sims at once.
+func calcStatWeight(ctx context.Context, swr *proto.StatWeightsRequest, referenceStat stats.Stat, progress chan *proto.ProgressMetrics, runSim raidSimRunner, concurrency int) *StatWeightsResult {
+	// The request is filled in and seeded below, which the caller shouldn't see.
+	swr = googleProto.Clone(swr).(*proto.StatWeightsRequest)
+
 	if swr.Player.BonusStats == nil {
 		swr.Player.BonusStats = &proto.UnitStats{}
 	}
@@ -123,12 +156,8 @@ func CalcStatWeight(swr *proto.StatWeightsRequest, referenceStat stats.Stat, pro
 	// This number needs to be the same for the baseline sim too, so that RNG lines up perfectly.
 	simOptions.Iterations /= 2
 
-	// Make sure an RNG seed is always set because it gives more consistent results.
-	// When there is no user-supplied seed it needs to be a randomly-selected seed
-	// though, so that run-run differences still exist.
-	if simOptions.RandomSeed == 0 {
-		simOptions.RandomSeed = time.Now().UnixNano()
-	}
+	// Each stat's sims are compared against the baseline, like bulk sim combos.
+	useCommonRandomNumbers(simOptions)
 
 	// Reduce variance even more by using test-level RNG controls.
 	simOptions.IsTest = true
@@ -143,7 +172,7 @@ func CalcStatWeight(swr *proto.StatWeightsRequest, referenceStat stats.Stat, pro
 		Encounter:  swr.Encounter,
 		SimOptions: simOptions,
 	}
-	baselineResult := RunRaidSim(baseSimRequest)
+	baselineResult := runSim(ctx, baseSimRequest, nil, false)
 	if baselineResult.ErrorResult != "" {
 		// TODO: get stack trace out.
 		return &StatWeightsResult{}
@@ -160,17 +189,12 @@ func CalcStatWeight(swr *proto.StatWeightsRequest, referenceStat stats.Stat, pro
 	var simsTotal int32
 	var simsCompleted int32
 
-	concurrency := (runtime.NumCPU() - 1) * 2
-	if concurrency <= 0 {
-		concurrency = 2
-	}
-
 	tickets := make(chan struct{}, concurrency)
 	for i := 0; i < concurrency; i++ {
 		tickets <- struct{}{}
 	}
 
-	doStat := func(stat stats.UnitStat, value float64, isLow bool) {
+	doStat := func(stat stats.UnitStat, value float64, dest **proto.RaidSimResult) {
 		defer waitGroup.Done()
 		// wait until we have CPU time available.
 		<-tickets
@@ -179,7 +203,7 @@ func CalcStatWeight(swr *proto.StatWeightsRequest, referenceStat stats.Stat, pro
 		stat.AddToStatsProto(simRequest.Raid.Parties[0].Players[0].BonusStats, value)
 
 		reporter := make(chan *proto.ProgressMetrics, 10)
-		go RunSim(simRequest, reporter) // RunRaidSim(simRequest)
+		go runSim(ctx, simRequest, reporter, false)
 
 		var localIterations int32
 		var errorStr string
@@ -210,11 +234,7 @@ func CalcStatWeight(swr *proto.StatWeightsRequest, referenceStat stats.Stat, pro
 			panic("Stat weights error: " + errorStr)
 		}
 
-		if isLow {
-			resultsLow[stat] = simResult
-		} else {
-			resultsHigh[stat] = simResult
-		}
+		*dest = simResult
 		tickets <- struct{}{}
 	}
 
@@ -255,8 +275,34 @@ func CalcStatWeight(swr *proto.StatWeightsRequest, referenceStat stats.Stat, pro
 		atomic.AddInt32(&iterationsTotal, swr.SimOptions.Iterations*2)
 		atomic.AddInt32(&simsTotal, 2)
 
-		go doStat(stat, statModsLow[stat], true)
-		go doStat(stat, statModsHigh[stat], false)
+		go doStat(stat, statModsLow[stat], &resultsLow[stat])
+		go doStat(stat, statModsHigh[stat], &resultsHigh[stat])
+	}
+
+	// Caps change the value of a stat abruptly, so for each weighed stat with
+	// a cap, also sim with the stat moved to the cap and one step to either
+	// side of it.
+	type capResults struct {
+		statCap *proto.StatCap
+		statMod float64
+		results [3]*proto.RaidSimResult // Below, at and above the cap.
+	}
+	var caps []*capResults
+	for _, statCap := range baselineResult.RaidMetrics.Parties[0].Players[0].StatCaps {
+		stat := stats.UnitStatFromStat(stats.Stat(statCap.Stat))
+		statMod := statModsHigh[stat]
+		if !slices.Contains(statsToWeigh, stats.Stat(statCap.Stat)) || statCap.CapRating < statMod {
+			continue
+		}
+
+		cr := &capResults{statCap: statCap, statMod: statMod}
+		caps = append(caps, cr)
+		for i := range cr.results {
+			waitGroup.Add(1)
+			atomic.AddInt32(&iterationsTotal, swr.SimOptions.Iterations)
+			atomic.AddInt32(&simsTotal, 1)
+			go doStat(stat, statCap.RatingToCap+float64(i-1)*statMod, &cr.results[i])
+		}
 	}
 
 	// Wait for thread results.
@@ -281,15 +327,19 @@ func CalcStatWeight(swr *proto.StatWeightsRequest, referenceStat stats.Stat, pro
 		}
 
 		calcWeightResults := func(baselineMetrics *proto.DistributionMetrics, modLowMetrics *proto.DistributionMetrics, modHighMetrics *proto.DistributionMetrics, weightResults *StatWeightValues) {
+			// Cancelled or adaptive sims may have stopped early, so only compare
+			// the iterations that every sim completed.
+			iterations := min(len(baselineMetrics.AllValues), len(modLowMetrics.AllValues), len(modHighMetrics.AllValues))
+
 			var lo, hi aggregator
 			if resultsLow != nil {
-				for i := 0; i < int(simOptions.Iterations); i++ {
+				for i := 0; i < iterations; i++ {
 					lo.add(modLowMetrics.AllValues[i] - baselineMetrics.AllValues[i])
 				}
 				lo.scale(1 / statModsLow[stat])
 			}
 			if resultsHigh != nil {
-				for i := 0; i < int(simOptions.Iterations); i++ {
+				for i := 0; i < iterations; i++ {
 					hi.add(modHighMetrics.AllValues[i] - baselineMetrics.AllValues[i])
 				}
 				hi.scale(1 / statModsHigh[stat])
@@ -336,5 +386,36 @@ func CalcStatWeight(swr *proto.StatWeightsRequest, referenceStat stats.Stat, pro
 		calcEpResults(&result.PDeath, DTPSReferenceStat)
 	}
 
+	for _, cr := range caps {
+		below := cr.results[0].RaidMetrics.Parties[0].Players[0]
+		atCap := cr.results[1].RaidMetrics.Parties[0].Players[0]
+		above := cr.results[2].RaidMetrics.Parties[0].Players[0]
+		result.Caps = append(result.Caps, &proto.StatCapWeights{
+			Cap:         cr.statCap,
+			DpsPreCap:   marginalValue(below.Dps, atCap.Dps, cr.statMod),
+			DpsPostCap:  marginalValue(atCap.Dps, above.Dps, cr.statMod),
+			HpsPreCap:   marginalValue(below.Hps, atCap.Hps, cr.statMod),
+			HpsPostCap:  marginalValue(atCap.Hps, above.Hps, cr.statMod),
+			TpsPreCap:   marginalValue(below.Threat, atCap.Threat, cr.statMod),
+			TpsPostCap:  marginalValue(atCap.Threat, above.Threat, cr.statMod),
+			DtpsPreCap:  marginalValue(below.Dtps, atCap.Dtps, cr.statMod),
+			DtpsPostCap: marginalValue(atCap.Dtps, above.Dtps, cr.statMod),
+		})
+	}
+
 	return result
 }
+
+// Average change per point of stat from one sim to another, over the
+// iterations both completed.
+func marginalValue(from *proto.DistributionMetrics, to *proto.DistributionMetrics, statMod float64) float64 {
+	iterations := min(len(from.AllValues), len(to.AllValues))
+	if iterations == 0 {
+		return 0
+	}
+	var sum float64
+	for i := 0; i < iterations; i++ {
+		sum += to.AllValues[i] - from.AllValues[i]
+	}
+	return sum / float64(iterations) / statMod
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestStatWeightCommonRandomNumbers(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{Iterations: 10})
+	swr := &proto.StatWeightsRequest{
+		Player:       request.Raid.Parties[0].Players[0],
+		RaidBuffs:    &proto.RaidBuffs{},
+		PartyBuffs:   &proto.PartyBuffs{},
+		Debuffs:      &proto.Debuffs{},
+		Encounter:    request.Encounter,
+		SimOptions:   request.SimOptions,
+		Tanks:        request.Raid.Tanks,
+		StatsToWeigh: []proto.Stat{proto.Stat_StatSpellPower},
+	}
+
+	var mu sync.Mutex
+	var simOptions []*proto.SimOptions
+	runSim := func(ctx context.Context, rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool) *proto.RaidSimResult {
+		mu.Lock()
+		simOptions = append(simOptions, rsr.SimOptions)
+		mu.Unlock()
+		return runSimWithContext(ctx, rsr, progress, skipPresim)
+	}
+	calcStatWeight(context.Background(), swr, stats.SpellPower, nil, runSim, 4)
+
+	if options := swr.SimOptions; options.RandomSeed != 0 || options.LabeledRngStreams || options.Iterations != 10 {
+		t.Errorf("Expected the caller's request to be unchanged, got %v", options)
+	}
+	if swr.Player.BonusStats != nil {
+		t.Errorf("Expected the caller's player to be unchanged, got %v", swr.Player.BonusStats)
+	}
+
+	// The baseline, plus a sim above and below for spell power.
+	if len(simOptions) != 3 {
+		t.Fatalf("Expected 3 sims, got %d", len(simOptions))
+	}
+	for _, options := range simOptions {
+		if options.RandomSeed == 0 || options.RandomSeed != simOptions[0].RandomSeed || !options.LabeledRngStreams {
+			t.Errorf("Expected every sim to use common random numbers, got %v", options)
+		}
+	}
+}
+
+func TestStatWeightsIterations(t *testing.T) {
+	swr := &proto.StatWeightsRequest{
+		SimOptions:         &proto.SimOptions{Iterations: 1000},
+		StatsToWeigh:       []proto.Stat{proto.Stat_StatSpellPower, proto.Stat_StatSpellHit},
+		PseudoStatsToWeigh: []proto.PseudoStat{proto.PseudoStat_PseudoStatMainHandDps},
+		EpReferenceStat:    proto.Stat_StatSpellPower,
+	}
+
+	// A baseline, 2 sims for each of the 3 stats, and 3 more sims around each
+	// stat's cap, all with 500 iterations.
+	if iterations := StatWeightsIterations(swr); iterations != 500*(1+2*3+3*2) {
+		t.Errorf("Expected %d iterations, got %d", 500*(1+2*3+3*2), iterations)
+	}
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"time"
+)
+
+// Options for keeping offhand swings from landing before the next mainhand
+// swing, so that both weapons benefit from the same swing-based haste procs,
+// e.g. Flurry.
+type OffhandSyncOptions struct {
+	// Offhand swings due within this long of a mainhand swing are left alone.
+	MinGap time.Duration
+
+	// Extra delay between the mainhand swing and the synced offhand swing.
+	Delay time.Duration
+}
+
+// The amount of time between two MH swings.
+func (aa *AutoAttacks) MainhandSwingSpeed() time.Duration {
+	return aa.mh.curSwingDuration
+}
+
+// The amount of time between two OH swings.
+func (aa *AutoAttacks) OffhandSwingSpeed() time.Duration {
+	return aa.oh.curSwingDuration
+}
+
+// The amount of time between two ranged attacks.
+func (aa *AutoAttacks) RangedSwingSpeed() time.Duration {
+	return aa.ranged.curSwingDuration
+}
+
+// Returns the time at which the next MH swing will occur.
+func (aa *AutoAttacks) MainhandSwingAt() time.Duration {
+	return aa.mh.swingAt
+}
+
+// Returns the time at which the next OH swing will occur.
+func (aa *AutoAttacks) OffhandSwingAt() time.Duration {
+	return aa.oh.swingAt
+}
+
+// Returns the time at which the next ranged attack will occur.
+func (aa *AutoAttacks) RangedSwingAt() time.Duration {
+	return aa.ranged.swingAt
+}
+
+// Moves the next MH swing to the given time.
+func (aa *AutoAttacks) SetMainhandSwingAt(sim *Simulation, swingAt time.Duration) {
+	aa.mh.setSwingAt(sim, swingAt)
+}
+
+// Moves the next OH swing to the given time.
+func (aa *AutoAttacks) SetOffhandSwingAt(sim *Simulation, swingAt time.Duration) {
+	aa.oh.setSwingAt(sim, swingAt)
+}
+
+func (wa *WeaponAttack) setSwingAt(sim *Simulation, swingAt time.Duration) {
+	wa.swingAt = max(swingAt, sim.CurrentTime)
+	sim.rescheduleWeaponAttack(wa.swingAt)
+}
+
+// Delays OH swings to follow MH swings, as described by the options. Passing
+// nil turns syncing off.
+func (aa *AutoAttacks) SetOffhandSync(options *OffhandSyncOptions) {
+	if options == nil {
+		aa.mh.syncOffhand = nil
+		return
+	}
+
+	minGap := options.MinGap
+	delay := options.Delay
+	aa.mh.syncOffhand = func(sim *Simulation) {
+		if !aa.IsDualWielding || aa.oh.swingAt-sim.CurrentTime <= minGap {
+			return
+		}
+		if nextMHSwingAt := sim.CurrentTime + aa.mh.curSwingDuration + delay; nextMHSwingAt > aa.oh.swingAt {
+			aa.oh.swingAt = nextMHSwingAt
+		}
+	}
+}
+
+// Recalculates swing durations after a change in attack speed. Melee swings in
+// progress have their remaining time rescaled by the change.
+func (aa *AutoAttacks) UpdateSwingTimers(sim *Simulation) {
+	if !aa.enabled {
+		return
+	}
+
+	if aa.AutoSwingRanged {
+		aa.ranged.updateSwingDuration(aa.ranged.unit.RangedSwingSpeed())
+		// ranged attack speed changes aren't applied mid-"swing"
+	}
+
+	if aa.AutoSwingMelee {
+		oldSwingSpeed := aa.mh.curSwingSpeed
+		aa.mh.updateSwingDuration(aa.mh.unit.SwingSpeed())
+		f := oldSwingSpeed / aa.mh.curSwingSpeed
+
+		aa.mh.rescaleRemainingSwing(sim, f)
+
+		if aa.IsDualWielding {
+			aa.oh.updateSwingDuration(aa.mh.curSwingSpeed)
+			aa.oh.rescaleRemainingSwing(sim, f)
+		}
+	}
+}
+
+func (wa *WeaponAttack) rescaleRemainingSwing(sim *Simulation, f float64) {
+	if remainingSwingTime := wa.swingAt - sim.CurrentTime; remainingSwingTime > 0 {
+		wa.swingAt = sim.CurrentTime + time.Duration(float64(remainingSwingTime)*f)
+	}
+
+	sim.rescheduleWeaponAttack(wa.swingAt)
+}
+
+// StopMeleeUntil should be used whenever a non-melee spell is cast. It stops melee, then restarts it
+// at end of cast, but with a reset swing timer (as if swings had just landed).
+func (aa *AutoAttacks) StopMeleeUntil(sim *Simulation, readyAt time.Duration, desyncOH bool) {
+	if !aa.AutoSwingMelee { // if not auto swinging, don't auto restart.
+		return
+	}
+
+	aa.mh.swingAt = readyAt + aa.mh.curSwingDuration
+	sim.rescheduleWeaponAttack(aa.mh.swingAt)
+
+	if aa.IsDualWielding {
+		aa.oh.swingAt = readyAt + aa.oh.curSwingDuration
+		if desyncOH {
+			// Used by warrior to desync offhand after unglyphed Shattering Throw.
+			aa.oh.swingAt += aa.oh.curSwingDuration / 2
+		}
+		sim.rescheduleWeaponAttack(aa.oh.swingAt)
+	}
+}
+
+// Delays all swing timers for the specified amount. Only used by Slam.
+func (aa *AutoAttacks) DelayMeleeBy(sim *Simulation, delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+
+	aa.mh.swingAt += delay
+	sim.rescheduleWeaponAttack(aa.mh.swingAt)
+
+	if aa.IsDualWielding {
+		aa.oh.swingAt += delay
+		sim.rescheduleWeaponAttack(aa.oh.swingAt)
+	}
+}
+
+func (aa *AutoAttacks) DelayRangedUntil(sim *Simulation, readyAt time.Duration) {
+	if readyAt <= aa.ranged.swingAt {
+		return
+	}
+
+	aa.ranged.swingAt = readyAt
+	sim.rescheduleWeaponAttack(aa.ranged.swingAt)
+}
+
+// Returns the time at which the next attack will occur.
+func (aa *AutoAttacks) NextAttackAt() time.Duration {
+	return min(aa.mh.swingAt, aa.oh.swingAt)
+}
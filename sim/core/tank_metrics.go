@@ -0,0 +1,129 @@
+package core
+
+import (
+	"math"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+const defaultBurstWindow = time.Second * 6
+
+// Survivability metrics, only collected for units which are tanking.
+type tankMetrics struct {
+	effectiveHealth float64
+	burstWindow     time.Duration
+
+	// Values for the current iteration. These are cleared after each iteration.
+	timeline []float64 // Damage taken during each second of the encounter.
+
+	// Aggregate values. These are updated after each iteration.
+	maxBurstDamage DistributionMetrics
+	timelineSum    []float64
+}
+
+func newTankMetrics(burstWindowSeconds int32) *tankMetrics {
+	burstWindow := time.Duration(burstWindowSeconds) * time.Second
+	if burstWindow <= 0 {
+		burstWindow = defaultBurstWindow
+	}
+
+	return &tankMetrics{
+		burstWindow:    burstWindow,
+		maxBurstDamage: NewDistributionMetrics(),
+	}
+}
+
+func (tm *tankMetrics) reset() {
+	tm.maxBurstDamage.reset()
+	tm.timeline = tm.timeline[:0]
+}
+
+func (tm *tankMetrics) addDamageTaken(sim *Simulation, damage float64) {
+	bucket := int(sim.CurrentTime / time.Second)
+	if bucket < 0 {
+		bucket = 0
+	}
+	for len(tm.timeline) <= bucket {
+		tm.timeline = append(tm.timeline, 0)
+	}
+	tm.timeline[bucket] += damage
+}
+
+// This should be called when a Sim iteration is complete.
+func (tm *tankMetrics) doneIteration(unitMetrics *UnitMetrics, sim *Simulation) {
+	tm.maxBurstDamage.Total = maxWindowDamage(unitMetrics.tmiList, tm.burstWindow) * 100
+
+	// Hack because of the way DistributionMetrics does its calculations.
+	tm.maxBurstDamage.Total *= sim.Duration.Seconds()
+	tm.maxBurstDamage.doneIteration(sim)
+
+	for len(tm.timelineSum) < len(tm.timeline) {
+		tm.timelineSum = append(tm.timelineSum, 0)
+	}
+	for i, damage := range tm.timeline {
+		tm.timelineSum[i] += damage
+	}
+}
+
+// Adds the aggregate values of other, for sims which are run in shards.
+func (tm *tankMetrics) merge(other *tankMetrics) {
+	tm.maxBurstDamage.merge(&other.maxBurstDamage)
+
+	for len(tm.timelineSum) < len(other.timelineSum) {
+		tm.timelineSum = append(tm.timelineSum, 0)
+	}
+	for i, damage := range other.timelineSum {
+		tm.timelineSum[i] += damage
+	}
+}
+
+// Returns the largest sum of weighted damage taken within any window of the
+// given length. Assumes events are sorted by timestamp.
+func maxWindowDamage(events []tmiListItem, window time.Duration) float64 {
+	maxDamage := 0.0
+	windowDamage := 0.0
+	windowStart := 0
+	for _, event := range events {
+		windowDamage += event.WeightedDamage
+		for event.Timestamp-events[windowStart].Timestamp >= window {
+			windowDamage -= events[windowStart].WeightedDamage
+			windowStart++
+		}
+		maxDamage = max(maxDamage, windowDamage)
+	}
+	return maxDamage
+}
+
+func (tm *tankMetrics) ToProto() *proto.TankMetrics {
+	n := float64(tm.maxBurstDamage.n)
+
+	protoMetrics := &proto.TankMetrics{
+		EffectiveHealth:     tm.effectiveHealth,
+		MaxBurstDamage:      tm.maxBurstDamage.ToProto(),
+		DamageTakenTimeline: make([]float64, len(tm.timelineSum)),
+	}
+
+	for i, damage := range tm.timelineSum {
+		protoMetrics.DamageTakenTimeline[i] = damage / n
+	}
+
+	return protoMetrics
+}
+
+// Effective health against physical attacks from the given attacker, based on
+// the defender's current max health and armor.
+func (at *AttackTable) EffectiveHealth() float64 {
+	armorConstant := at.armorConstant()
+	armor := at.Defender.Armor()
+	return at.Defender.MaxHealth() * (armor + armorConstant) / armorConstant
+}
+
+// Effective health against the hardest hitting of the given attackers.
+func (unit *Unit) worstCaseEffectiveHealth(attackers []*Unit) float64 {
+	effectiveHealth := math.MaxFloat64
+	for _, attacker := range attackers {
+		effectiveHealth = min(effectiveHealth, attacker.AttackTables[unit.UnitIndex].EffectiveHealth())
+	}
+	return effectiveHealth
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestMaxWindowDamage(t *testing.T) {
+	events := []tmiListItem{
+		{Timestamp: time.Second * 1, WeightedDamage: 0.1},
+		{Timestamp: time.Second * 2, WeightedDamage: 0.2},
+		{Timestamp: time.Second * 7, WeightedDamage: 0.3},
+		{Timestamp: time.Second * 8, WeightedDamage: 0.3},
+		{Timestamp: time.Second * 14, WeightedDamage: 0.1},
+	}
+
+	if got := maxWindowDamage(events, time.Second*6); !WithinToleranceFloat64(0.6, got, 1e-9) {
+		t.Fatalf("Expected max 6s window damage 0.6, got %f", got)
+	}
+	if got := maxWindowDamage(events, time.Second*7); !WithinToleranceFloat64(0.8, got, 1e-9) {
+		t.Fatalf("Expected max 7s window damage 0.8, got %f", got)
+	}
+	if got := maxWindowDamage(events, time.Second*1); !WithinToleranceFloat64(0.3, got, 1e-9) {
+		t.Fatalf("Expected max 1s window damage 0.3, got %f", got)
+	}
+	if got := maxWindowDamage(nil, time.Second*6); got != 0 {
+		t.Fatalf("Expected 0 damage with no events, got %f", got)
+	}
+}
+
+func TestEffectiveHealthWorstCaseTarget(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{})
+	request.Encounter.Targets[0].Level = 80
+	request.Encounter.Targets = append(request.Encounter.Targets, &proto.Target{
+		Level:         83,
+		MinBaseDamage: 1000,
+		SwingSpeed:    2,
+	})
+	player := request.Raid.Parties[0].Players[0]
+	player.BonusStats = &proto.UnitStats{Stats: make([]float64, stats.Len)}
+	player.BonusStats.Stats[stats.Health] = 30000
+	player.BonusStats.Stats[stats.Armor] = 20000
+	sim := NewSim(request)
+	sim.reset()
+
+	tank := &sim.Raid.Parties[0].Players[0].GetCharacter().Unit
+	add := sim.Encounter.TargetUnits[0].AttackTables[tank.UnitIndex].EffectiveHealth()
+	boss := sim.Encounter.TargetUnits[1].AttackTables[tank.UnitIndex].EffectiveHealth()
+	if boss >= add {
+		t.Fatalf("Expected less effective health against the higher level target, got %f vs %f", boss, add)
+	}
+	if got := tank.Metrics.tank.effectiveHealth; got != boss {
+		t.Fatalf("Expected effective health %f against the hardest hitting target, got %f", boss, got)
+	}
+}
@@ -0,0 +1,206 @@
+package core
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Runtime state for a mob's TankSwapDebuff. The mob applies the debuff to
+// whichever tank it is attacking, and the next tank in order taunts once that
+// tank reaches the configured number of stacks.
+type tankSwap struct {
+	target *Target
+	config *proto.TankSwapDebuff
+
+	interval   time.Duration
+	tauntDelay time.Duration
+
+	tanks   []*Unit
+	debuffs []*Aura // Indexed the same as tanks.
+
+	tauntPending bool
+	victimSince  time.Duration
+}
+
+func (target *Target) enableTankSwap(config *proto.TankSwapDebuff, tanks []*Unit) {
+	ts := &tankSwap{
+		target:     target,
+		config:     config,
+		interval:   max(DurationFromSeconds(config.Interval), time.Second),
+		tauntDelay: DurationFromSeconds(config.TauntDelay),
+		tanks:      tanks,
+	}
+
+	for _, tank := range tanks {
+		ts.debuffs = append(ts.debuffs, ts.registerDebuff(tank))
+
+		if tank.Metrics.tankSwap == nil {
+			tank.Metrics.tankSwap = &tankSwapMetrics{}
+			registerTankSwapDamageTracker(tank)
+		}
+	}
+
+	target.tankSwap = ts
+}
+
+func (ts *tankSwap) registerDebuff(tank *Unit) *Aura {
+	damageTakenPerStack := ts.config.DamageTakenPerStack
+	duration := DurationFromSeconds(ts.config.Duration)
+	if duration == 0 {
+		duration = NeverExpires
+	}
+
+	return tank.RegisterAura(Aura{
+		Label:     "Tank Swap Debuff-" + ts.target.Label,
+		ActionID:  ActionID{SpellID: ts.config.SpellId},
+		Duration:  duration,
+		MaxStacks: math.MaxInt32,
+		OnStacksChange: func(aura *Aura, sim *Simulation, oldStacks int32, newStacks int32) {
+			if damageTakenPerStack != 0 {
+				aura.Unit.PseudoStats.DamageTakenMultiplier *= (1 + damageTakenPerStack*float64(newStacks)) / (1 + damageTakenPerStack*float64(oldStacks))
+			}
+		},
+	})
+}
+
+// Splits damage taken by a swapping tank into damage from mobs which are
+// attacking it, and damage from everything else.
+func registerTankSwapDamageTracker(tank *Unit) {
+	onDamageTaken := func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
+		if result.Damage <= 0 {
+			return
+		}
+		tsm := aura.Unit.Metrics.tankSwap
+		if spell.Unit.CurrentTarget == aura.Unit {
+			tsm.damageTankingSum += result.Damage
+		} else {
+			tsm.damageOffTankingSum += result.Damage
+		}
+	}
+
+	MakePermanent(tank.RegisterAura(Aura{
+		Label:                 "Tank Swap Damage Tracker",
+		OnSpellHitTaken:       onDamageTaken,
+		OnPeriodicDamageTaken: onDamageTaken,
+	}))
+}
+
+func (ts *tankSwap) reset(sim *Simulation) {
+	ts.tauntPending = false
+	ts.victimSince = 0
+
+	StartPeriodicAction(sim, PeriodicActionOptions{
+		Period: ts.interval,
+		OnAction: func(sim *Simulation) {
+			ts.applyDebuff(sim)
+		},
+	})
+}
+
+func (ts *tankSwap) applyDebuff(sim *Simulation) {
+	tankIndex := slices.Index(ts.tanks, ts.target.CurrentTarget)
+	if tankIndex == -1 || !ts.tanks[tankIndex].IsEnabled() {
+		return
+	}
+
+	debuff := ts.debuffs[tankIndex]
+	debuff.Activate(sim)
+	debuff.AddStack(sim)
+
+	if ts.config.SwapStacks > 0 && debuff.GetStacks() >= ts.config.SwapStacks && !ts.tauntPending {
+		ts.tauntPending = true
+		StartDelayedAction(sim, DelayedActionOptions{
+			DoAt: sim.CurrentTime + ts.tauntDelay,
+			OnAction: func(sim *Simulation) {
+				ts.tauntPending = false
+				ts.swap(sim, tankIndex)
+			},
+		})
+	}
+}
+
+// Has the next living tank after the given one taunt the mob.
+func (ts *tankSwap) swap(sim *Simulation, fromIndex int) {
+	for i := 1; i < len(ts.tanks); i++ {
+		tank := ts.tanks[(fromIndex+i)%len(ts.tanks)]
+		if !tank.IsEnabled() {
+			continue
+		}
+		if tank == ts.target.CurrentTarget {
+			return
+		}
+
+		tsm := tank.Metrics.tankSwap
+		tsm.tauntsSum++
+		if ts.hasSurvivalCooldownReady(sim, tank) {
+			tsm.tauntsWithCooldownReadySum++
+		} else if sim.Log != nil {
+			tank.Log(sim, "No survival cooldowns ready for tank swap on %s", ts.target.Label)
+		}
+
+		tank.taunt(sim, &ts.target.Unit)
+		return
+	}
+}
+
+func (ts *tankSwap) hasSurvivalCooldownReady(sim *Simulation, tank *Unit) bool {
+	agent := ts.target.Env.Raid.GetPlayerFromUnit(tank)
+	if agent == nil {
+		return false
+	}
+	for _, mcd := range agent.GetCharacter().GetMajorCooldowns() {
+		if mcd.Type.Matches(CooldownTypeSurvival) && mcd.IsEnabled() && mcd.IsReady(sim) {
+			return true
+		}
+	}
+	return false
+}
+
+// Credits the time since the last victim change to the outgoing victim.
+func (ts *tankSwap) onVictimChange(sim *Simulation) {
+	if victim := ts.target.CurrentTarget; victim != nil && victim.Metrics.tankSwap != nil {
+		victim.Metrics.tankSwap.timeTankingSum += sim.CurrentTime - ts.victimSince
+	}
+	ts.victimSince = sim.CurrentTime
+}
+
+func (ts *tankSwap) doneIteration(sim *Simulation) {
+	ts.onVictimChange(sim)
+}
+
+// Swap statistics for a tank, aggregated across iterations.
+type tankSwapMetrics struct {
+	tauntsSum                  int
+	tauntsWithCooldownReadySum int
+	timeTankingSum             time.Duration
+	damageTankingSum           float64
+	damageOffTankingSum        float64
+}
+
+func (tsm *tankSwapMetrics) merge(other *tankSwapMetrics) {
+	tsm.tauntsSum += other.tauntsSum
+	tsm.tauntsWithCooldownReadySum += other.tauntsWithCooldownReadySum
+	tsm.timeTankingSum += other.timeTankingSum
+	tsm.damageTankingSum += other.damageTankingSum
+	tsm.damageOffTankingSum += other.damageOffTankingSum
+}
+
+func (tsm *tankSwapMetrics) ToProto(numIterations float64) *proto.TankSwapMetrics {
+	if numIterations == 0 {
+		return &proto.TankSwapMetrics{}
+	}
+
+	protoMetrics := &proto.TankSwapMetrics{
+		TauntsAvg:                float64(tsm.tauntsSum) / numIterations,
+		SecondsTankingAvg:        tsm.timeTankingSum.Seconds() / numIterations,
+		DamageTakenTankingAvg:    tsm.damageTankingSum / numIterations,
+		DamageTakenOffTankingAvg: tsm.damageOffTankingSum / numIterations,
+	}
+	if tsm.tauntsSum > 0 {
+		protoMetrics.CooldownReadyAtTaunt = float64(tsm.tauntsWithCooldownReadySum) / float64(tsm.tauntsSum)
+	}
+	return protoMetrics
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func tankSwapTestRequest(debuff *proto.TankSwapDebuff) *proto.RaidSimRequest {
+	player := func(name string) *proto.Player {
+		return &proto.Player{
+			Name:      name,
+			Class:     proto.Class_ClassShaman,
+			Consumes:  &proto.Consumes{},
+			Buffs:     &proto.IndividualBuffs{},
+			Spec:      &proto.Player_ElementalShaman{},
+			Equipment: &proto.EquipmentSpec{},
+		}
+	}
+
+	return &proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{
+			Iterations:  2,
+			RandomSeed:  100,
+			Interactive: true,
+		},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{player("Tank 1"), player("Tank 2"), player("DPS")},
+					Buffs:   &proto.PartyBuffs{},
+				},
+			},
+			Tanks: []*proto.UnitReference{
+				{Type: proto.UnitReference_Player, Index: 0},
+				{Type: proto.UnitReference_Player, Index: 1},
+			},
+		},
+		Encounter: &proto.Encounter{
+			Duration: 55,
+			Targets: []*proto.Target{{
+				Level:          83,
+				MinBaseDamage:  1000,
+				SwingSpeed:     2,
+				TankSwapDebuff: debuff,
+			}},
+		},
+	}
+}
+
+func TestTankSwap(t *testing.T) {
+	result := RunRaidSim(tankSwapTestRequest(&proto.TankSwapDebuff{
+		SpellId:    67477,
+		Interval:   5,
+		Duration:   10,
+		SwapStacks: 3,
+	}))
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", result.ErrorResult)
+	}
+
+	// Swaps every 15s, starting with tank 1.
+	players := result.RaidMetrics.Parties[0].Players
+	expectedTaunts := []float64{1, 2}
+	expectedSeconds := []float64{30, 25}
+	for i, expected := range expectedTaunts {
+		tankSwap := players[i].TankSwap
+		if tankSwap == nil {
+			t.Fatalf("Expected tank swap metrics for tank %d", i+1)
+		}
+		if tankSwap.TauntsAvg != expected {
+			t.Errorf("Expected %0.1f taunts for tank %d, got %0.1f", expected, i+1, tankSwap.TauntsAvg)
+		}
+		if tankSwap.SecondsTankingAvg != expectedSeconds[i] {
+			t.Errorf("Expected %0.1fs tanking for tank %d, got %0.3f", expectedSeconds[i], i+1, tankSwap.SecondsTankingAvg)
+		}
+		if tankSwap.DamageTakenTankingAvg <= 0 || tankSwap.DamageTakenOffTankingAvg != 0 {
+			t.Errorf("Unexpected damage taken for tank %d: %v", i+1, tankSwap)
+		}
+	}
+
+	if players[2].TankSwap != nil {
+		t.Errorf("Expected no tank swap metrics for non-tank players")
+	}
+}
+
+func TestTankSwapDebuffNeverExpires(t *testing.T) {
+	sim := NewSim(tankSwapTestRequest(&proto.TankSwapDebuff{
+		SpellId:    67477,
+		Interval:   5,
+		SwapStacks: 3,
+	}))
+	debuff := sim.Encounter.Targets[0].tankSwap.debuffs[0]
+	if debuff.Duration != NeverExpires {
+		t.Fatalf("Expected a debuff without a duration to never expire, got %s", debuff.Duration)
+	}
+
+	sim.reset()
+	sim.PrePull()
+	runSimUntil(sim, time.Second*30)
+	if stacks := debuff.GetStacks(); stacks != 3 {
+		t.Fatalf("Expected tank 1 to keep its stacks after the swap, got %d", stacks)
+	}
+}
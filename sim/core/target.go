@@ -1,6 +1,7 @@
 package core
 
 import (
+	"slices"
 	"strconv"
 	"time"
 
@@ -11,14 +12,27 @@ import (
 type Encounter struct {
 	Duration          time.Duration
 	DurationVariation time.Duration
-	Targets           []*Target
-	TargetUnits       []*Unit
+
+	DurationDistribution proto.DurationDistribution
+	KillTimes            []time.Duration // Only set for DurationDistributionEmpirical.
+	Targets              []*Target
+	// Only the targets which are currently in the fight, i.e. without adds
+	// which haven't spawned yet or have despawned.
+	TargetUnits []*Unit
+	// All targets, in the same order as Targets.
+	AllTargetUnits []*Unit
 
 	ExecuteProportion_20 float64
 	ExecuteProportion_25 float64
 	ExecuteProportion_35 float64
 
 	EndFightAtHealth float64
+	// Combined health of the targets, if execute phases are based on the damage
+	// they've taken rather than on the duration.
+	ExecuteAtHealth float64
+	// Damage per second dealt to the targets by raid members who aren't
+	// simulated, which is added to DamageTaken.
+	UnsimulatedRaidDps float64
 	// DamageTaken is used to track health fights instead of duration fights.
 	//  Once primary target has taken its health worth of damage, fight ends.
 	DamageTaken float64
@@ -27,28 +41,57 @@ type Encounter struct {
 
 	// Value to multiply by, for damage spells which are subject to the aoe cap.
 	aoeCapMultiplier float64
+
+	// Only set if the encounter has add waves.
+	adds              []*encounterAdd
+	addsByIndex       []*encounterAdd // Indexed by target index, nil for regular targets.
+	activeTargetUnits []*Unit         // Backing array for TargetUnits, if targets can leave the fight.
+
+	// Only set for health fights with separate health pools.
+	healthPools         []*targetHealthPool // Indexed by target index, nil for targets which can't die.
+	numHealthPoolsAlive int
+
+	lossOfControlEvents []*lossOfControlEvent
+
+	// Only set if the encounter has downtime windows. Sorted by start time.
+	downtimeWindows  []*downtimeWindow
+	outOfCombatUnits []*Unit
 }
 
 func NewEncounter(options *proto.Encounter) Encounter {
-	options.ExecuteProportion_25 = max(options.ExecuteProportion_25, options.ExecuteProportion_20)
-	options.ExecuteProportion_35 = max(options.ExecuteProportion_35, options.ExecuteProportion_25)
+	executeProportion_25 := max(options.ExecuteProportion_25, options.ExecuteProportion_20)
+	executeProportion_35 := max(options.ExecuteProportion_35, executeProportion_25)
 
 	encounter := Encounter{
 		Duration:             DurationFromSeconds(options.Duration),
 		DurationVariation:    DurationFromSeconds(options.DurationVariation),
+		DurationDistribution: options.DurationDistribution,
+		UnsimulatedRaidDps:   max(options.UnsimulatedRaidDps, 0),
 		ExecuteProportion_20: max(options.ExecuteProportion_20, 0),
-		ExecuteProportion_25: max(options.ExecuteProportion_25, 0),
-		ExecuteProportion_35: max(options.ExecuteProportion_35, 0),
+		ExecuteProportion_25: max(executeProportion_25, 0),
+		ExecuteProportion_35: max(executeProportion_35, 0),
 		Targets:              []*Target{},
 	}
+	if encounter.DurationDistribution == proto.DurationDistribution_DurationDistributionEmpirical {
+		encounter.setKillTimes(options.KillTimes)
+	}
+
+	// Adds don't count towards the encounter's health.
+	numTargets := max(len(options.Targets)-numAddWaveTargets(options), 0)
+
 	// If UseHealth is set, we use the sum of targets health.
 	if options.UseHealth {
-		for _, t := range options.Targets {
+		for _, t := range options.Targets[:numTargets] {
 			encounter.EndFightAtHealth += t.Stats[stats.Health]
 		}
 		if encounter.EndFightAtHealth == 0 {
 			encounter.EndFightAtHealth = 1 // default to something so we don't instantly end without anything.
 		}
+		encounter.ExecuteAtHealth = encounter.EndFightAtHealth
+	} else if options.ExecuteFromHealth {
+		for _, t := range options.Targets[:numTargets] {
+			encounter.ExecuteAtHealth += t.Stats[stats.Health]
+		}
 	}
 
 	for targetIndex, targetOptions := range options.Targets {
@@ -63,6 +106,13 @@ func NewEncounter(options *proto.Encounter) Encounter {
 		encounter.Targets = append(encounter.Targets, target)
 		encounter.TargetUnits = append(encounter.TargetUnits, &target.Unit)
 	}
+	encounter.AllTargetUnits = slices.Clone(encounter.TargetUnits)
+	if len(options.AddWaves) > 0 {
+		encounter.setupAdds(options)
+	}
+	if options.UseHealth && options.SeparateHealthPools {
+		encounter.setupHealthPools(numTargets)
+	}
 
 	if encounter.EndFightAtHealth > 0 {
 		// Until we pre-sim set duration to 10m
@@ -75,16 +125,79 @@ func NewEncounter(options *proto.Encounter) Encounter {
 	return encounter
 }
 
+// Uses the average kill time as the duration, and the furthest kill time from
+// it as the variation, so that GetMaxDuration() still covers every iteration.
+func (encounter *Encounter) setKillTimes(killTimes []float64) {
+	encounter.KillTimes = nil
+	var total time.Duration
+	for _, killTime := range killTimes {
+		if killTime > 0 {
+			encounter.KillTimes = append(encounter.KillTimes, DurationFromSeconds(killTime))
+			total += DurationFromSeconds(killTime)
+		}
+	}
+	if len(encounter.KillTimes) == 0 {
+		encounter.DurationDistribution = proto.DurationDistribution_DurationDistributionUniform
+		return
+	}
+
+	encounter.Duration = total / time.Duration(len(encounter.KillTimes))
+	encounter.DurationVariation = 0
+	for _, killTime := range encounter.KillTimes {
+		encounter.DurationVariation = max(encounter.DurationVariation, (killTime - encounter.Duration).Abs())
+	}
+}
+
+// Brings back the targets which left the fight in the previous iteration,
+// except for adds which spawn later.
+func (encounter *Encounter) resetTargetUnits() {
+	if encounter.activeTargetUnits == nil {
+		return
+	}
+	encounter.TargetUnits = encounter.activeTargetUnits[:0]
+	for _, unit := range encounter.AllTargetUnits {
+		if encounter.addsByIndex == nil || encounter.addsByIndex[unit.Index] == nil {
+			encounter.TargetUnits = append(encounter.TargetUnits, unit)
+		}
+	}
+}
+
+// Takes a target out of the fight, e.g. once it dies or despawns. Raid members
+// attacking it switch to the primary target.
+func (encounter *Encounter) removeTarget(sim *Simulation, target *Target) {
+	unit := &target.Unit
+	encounter.TargetUnits = slices.DeleteFunc(encounter.TargetUnits, func(target *Unit) bool { return target == unit })
+	target.AutoAttacks.CancelAutoSwing(sim)
+	if target.gcdAction != nil {
+		target.CancelGCDTimer(sim)
+	}
+	target.enabled = false
+	target.auraTracker.expireAll(sim)
+	encounter.updateAOECapMultiplier()
+
+	for _, raidUnit := range sim.Raid.AllUnits {
+		if raidUnit.CurrentTarget == unit {
+			raidUnit.CurrentTarget = encounter.TargetUnits[0]
+		}
+	}
+}
+
 func (encounter *Encounter) AOECapMultiplier() float64 {
 	return encounter.aoeCapMultiplier
 }
+
+// AoE damage is split between the targets in the fight once there are more
+// than 10 of them, so this is updated whenever a target enters or leaves it.
 func (encounter *Encounter) updateAOECapMultiplier() {
-	encounter.aoeCapMultiplier = min(10/float64(len(encounter.Targets)), 1)
+	encounter.aoeCapMultiplier = min(10/float64(max(len(encounter.TargetUnits), 1)), 1)
 }
 
 func (encounter *Encounter) doneIteration(sim *Simulation) {
 	for i := range encounter.Targets {
 		target := encounter.Targets[i]
+		if target.tankSwap != nil {
+			target.tankSwap.doneIteration(sim)
+		}
 		target.doneIteration(sim)
 	}
 }
@@ -108,6 +221,17 @@ type Target struct {
 	Unit
 
 	AI TargetAI
+
+	// If set and the encounter tracks threat, this target attacks whichever unit
+	// pulls aggro from its current victim.
+	attackHighestThreat bool
+	// Times at which all threat on this target is wiped.
+	threatWipeTimes []time.Duration
+	// The target won't switch victims due to threat before this time.
+	fixateExpiresAt time.Duration
+
+	tankSwap *tankSwap
+	spells   []*targetSpell
 }
 
 func NewTarget(options *proto.Target, targetIndex int32) *Target {
@@ -136,9 +260,16 @@ func NewTarget(options *proto.Target, targetIndex int32) *Target {
 	if target.Level == 0 {
 		target.Level = defaultRaidBossLevel
 	}
+	if options.Pvp {
+		target.PvP = true
+		target.Level = CharacterLevel
+		if target.stats[stats.Armor] == 0 {
+			target.stats[stats.Armor] = DefaultPvPTargetArmor
+		}
+	}
 	if target.stats[stats.MeleeCrit] == 0 {
 		// Treat any % crit buff an enemy would gain as though it was scaled with level 80 ratings
-		target.stats[stats.MeleeCrit] = UnitLevelFloat64(target.Level, 5.0, 5.2, 5.4, 5.6) * CritRatingPerCritChance
+		target.stats[stats.MeleeCrit] = LevelDeltaFloat64(target.Level-CharacterLevel, 5.0, 5.2, 5.4, 5.6, -0.2) * CritRatingPerCritChance
 	}
 
 	if target.Level == defaultRaidBossLevel && options.SuppressDodge {
@@ -152,6 +283,14 @@ func NewTarget(options *proto.Target, targetIndex int32) *Target {
 	target.PseudoStats.InFrontOfTarget = true
 	target.PseudoStats.DamageSpread = options.DamageSpread
 
+	target.attackHighestThreat = options.AttackHighestThreat
+	if options.RequiredDps > 0 {
+		target.Metrics.dpsRequirement = &dpsRequirementMetrics{requiredDps: options.RequiredDps}
+	}
+	for _, wipeTime := range options.ThreatWipeTimes {
+		target.threatWipeTimes = append(target.threatWipeTimes, DurationFromSeconds(wipeTime))
+	}
+
 	preset := GetPresetTargetWithID(options.Id)
 	if preset != nil && preset.AI != nil {
 		target.AI = preset.AI()
@@ -162,18 +301,53 @@ func NewTarget(options *proto.Target, targetIndex int32) *Target {
 
 func (target *Target) Reset(sim *Simulation) {
 	target.Unit.reset(sim, nil)
+	target.CurrentTarget = target.defaultTarget
+	target.fixateExpiresAt = 0
 	target.SetGCDTimer(sim, 0)
 	if target.AI != nil {
 		target.AI.Reset(sim)
 	}
+
+	if target.tankSwap != nil {
+		target.tankSwap.reset(sim)
+	}
+
+	if target.damageWindows != nil {
+		target.damageWindows.reset(sim)
+	}
+
+	for _, spell := range target.spells {
+		spell.reset(sim)
+	}
+
+	if tt := target.Env.threat; tt != nil {
+		for _, wipeTime := range target.threatWipeTimes {
+			StartDelayedAction(sim, DelayedActionOptions{
+				DoAt: wipeTime,
+				OnAction: func(sim *Simulation) {
+					tt.wipeThreat(sim, target)
+				},
+			})
+		}
+	}
 }
 
-func (target *Target) NextTarget() *Target {
-	nextIndex := target.Index + 1
-	if nextIndex >= target.Env.GetNumTargets() {
-		nextIndex = 0
+// Changes the unit this target is attacking.
+func (target *Target) setVictim(sim *Simulation, victim *Unit) {
+	if target.CurrentTarget == victim {
+		return
 	}
-	return target.Env.GetTarget(nextIndex)
+	if sim.Log != nil {
+		target.Log(sim, "Switched target to %s", victim.Label)
+	}
+	if target.tankSwap != nil {
+		target.tankSwap.onVictimChange(sim)
+	}
+	target.CurrentTarget = victim
+}
+
+func (target *Target) NextTarget() *Target {
+	return target.Env.NextTarget(&target.Unit)
 }
 
 func (target *Target) GetMetricsProto() *proto.UnitMetrics {
@@ -221,25 +395,46 @@ func NewAttackTable(attacker *Unit, defender *Unit) *AttackTable {
 	}
 
 	if defender.Type == EnemyUnit {
-		// Assumes attacker (the Player) is level 80.
-		table.BaseSpellMissChance = UnitLevelFloat64(defender.Level, 0.04, 0.05, 0.06, 0.17)
-		table.BaseMissChance = UnitLevelFloat64(defender.Level, 0.05, 0.055, 0.06, 0.08)
+		// Against lower level targets, each level below the attacker's is worth
+		// 5 weapon skill, at 0.04% per skill. Spells miss 1% less per level,
+		// down to 1%, and glancing blows only happen against targets of at
+		// least the attacker's level.
+		levelDelta := defender.Level - attacker.Level
+		table.BaseSpellMissChance = max(0.01, LevelDeltaFloat64(levelDelta, 0.04, 0.05, 0.06, 0.17, -0.01))
+		table.BaseMissChance = max(0, LevelDeltaFloat64(levelDelta, 0.05, 0.055, 0.06, 0.08, -0.002))
 		table.BaseBlockChance = 0.05
-		table.BaseDodgeChance = UnitLevelFloat64(defender.Level, 0.05, 0.055, 0.06, 0.065)
-		table.BaseParryChance = UnitLevelFloat64(defender.Level, 0.05, 0.055, 0.06, 0.14)
-		table.BaseGlanceChance = UnitLevelFloat64(defender.Level, 0.06, 0.12, 0.18, 0.24)
-
-		table.GlanceMultiplier = UnitLevelFloat64(defender.Level, 0.95, 0.95, 0.85, 0.75)
-		table.MeleeCritSuppression = UnitLevelFloat64(defender.Level, 0, 0.01, 0.02, 0.048)
-		table.SpellCritSuppression = UnitLevelFloat64(defender.Level, 0, 0, 0.003, 0.021)
+		table.BaseDodgeChance = max(0, LevelDeltaFloat64(levelDelta, 0.05, 0.055, 0.06, 0.065, -0.002))
+		table.BaseParryChance = max(0, LevelDeltaFloat64(levelDelta, 0.05, 0.055, 0.06, 0.14, -0.002))
+		table.BaseGlanceChance = max(0, LevelDeltaFloat64(levelDelta, 0.06, 0.12, 0.18, 0.24, -0.06))
+
+		table.GlanceMultiplier = LevelDeltaFloat64(levelDelta, 0.95, 0.95, 0.85, 0.75, 0)
+		// Negative against lower level targets, i.e. a bonus.
+		table.MeleeCritSuppression = LevelDeltaFloat64(levelDelta, 0, 0.01, 0.02, 0.048, -0.002)
+		table.SpellCritSuppression = LevelDeltaFloat64(levelDelta, 0, 0, 0.003, 0.021, 0)
+
+		if defender.PvP {
+			// Players can't be hit by glancing blows.
+			table.BaseGlanceChance = 0
+			table.GlanceMultiplier = 1
+		}
 	} else {
-		// Assumes defender (the Player) is level 80.
+		levelDelta := attacker.Level - defender.Level
 		table.BaseSpellMissChance = 0.05
-		table.BaseMissChance = UnitLevelFloat64(attacker.Level, 0.05, 0.048, 0.046, 0.044)
-		table.BaseBlockChance = UnitLevelFloat64(attacker.Level, 0.05, 0.048, 0.046, 0.044)
-		table.BaseDodgeChance = UnitLevelFloat64(attacker.Level, 0, -0.002, -0.004, -0.006)
-		table.BaseParryChance = UnitLevelFloat64(attacker.Level, 0, -0.002, -0.004, -0.006)
+		table.BaseMissChance = LevelDeltaFloat64(levelDelta, 0.05, 0.048, 0.046, 0.044, 0.002)
+		table.BaseBlockChance = LevelDeltaFloat64(levelDelta, 0.05, 0.048, 0.046, 0.044, 0.002)
+		table.BaseDodgeChance = LevelDeltaFloat64(levelDelta, 0, -0.002, -0.004, -0.006, 0.002)
+		table.BaseParryChance = LevelDeltaFloat64(levelDelta, 0, -0.002, -0.004, -0.006, 0.002)
 	}
 
 	return table
 }
+
+// Resilience of PvP targets reduces the chance of being critically hit by
+// players. Mobs attacking players handle Resilience in
+// applyEnemyAttackTableCrit() instead.
+func (at *AttackTable) resilienceCritReduction() float64 {
+	if !at.Defender.PvP {
+		return 0
+	}
+	return at.Defender.stats[stats.Resilience] / ResilienceRatingPerCritReductionChance / 100
+}
@@ -17,6 +17,9 @@ func (target *Target) initialize(config *proto.Target) {
 		return
 	}
 
+	target.registerDamageWindows(config.DamageWindows)
+	target.registerTargetSpells(config)
+
 	if target.CurrentTarget != nil {
 		if config.SwingSpeed > 0 {
 			aaOptions := AutoAttackOptions{
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// A spell which a target casts on a fixed interval, see proto.TargetSpell.
+type targetSpell struct {
+	spell  *Spell
+	config *proto.TargetSpell
+
+	// Only set if the spell hits every player.
+	players []*Unit
+}
+
+func (target *Target) registerTargetSpells(config *proto.Target) {
+	var players []*Unit
+	for _, party := range target.Env.Raid.Parties {
+		for _, player := range party.Players {
+			if _, ok := player.(*TargetDummy); !ok {
+				players = append(players, &player.GetCharacter().Unit)
+			}
+		}
+	}
+
+	for i, spellConfig := range config.Spells {
+		if spellConfig.Interval <= 0 {
+			continue
+		}
+		ts := target.registerTargetSpell(spellConfig, int32(i))
+		if spellConfig.HitsAllPlayers {
+			ts.players = players
+		}
+		target.spells = append(target.spells, ts)
+	}
+
+	elementalAttacks := config.SwingSpeed > 0 && config.SpellSchool != proto.SpellSchool_SpellSchoolPhysical
+	if len(target.spells) > 0 || elementalAttacks {
+		for _, player := range players {
+			enableResistMetrics(player)
+		}
+	}
+}
+
+func (target *Target) registerTargetSpell(config *proto.TargetSpell, tag int32) *targetSpell {
+	ts := &targetSpell{config: config}
+
+	flags := SpellFlagNone
+	if config.Binary {
+		flags |= SpellFlagBinary
+	}
+
+	ts.spell = target.RegisterSpell(SpellConfig{
+		ActionID:    ActionID{SpellID: config.SpellId, Tag: tag},
+		SpellSchool: SpellSchoolFromProto(config.School),
+		ProcMask:    ProcMaskSpellDamage,
+		Flags:       flags,
+
+		DamageMultiplier: 1,
+		CritMultiplier:   1,
+
+		ApplyEffects: func(sim *Simulation, victim *Unit, spell *Spell) {
+			if ts.players == nil {
+				ts.hit(sim, victim)
+				return
+			}
+			for _, player := range ts.players {
+				if player.IsEnabled() {
+					ts.hit(sim, player)
+				}
+			}
+		},
+	})
+	return ts
+}
+
+func (ts *targetSpell) hit(sim *Simulation, victim *Unit) {
+	baseDamage := ts.config.MinBaseDamage * (1 + ts.config.DamageSpread*sim.RandomFloat("Target Spell Damage"))
+	ts.spell.CalcAndDealDamage(sim, victim, baseDamage, ts.spell.OutcomeAlwaysHit)
+}
+
+func (ts *targetSpell) reset(sim *Simulation) {
+	StartPeriodicAction(sim, PeriodicActionOptions{
+		Period: DurationFromSeconds(ts.config.Interval),
+		OnAction: func(sim *Simulation) {
+			if !ts.spell.Unit.IsEnabled() {
+				return
+			}
+			victim := ts.spell.Unit.CurrentTarget
+			if ts.players != nil {
+				// The cast itself still needs a target, for metrics.
+				victim = ts.players[0]
+			} else if victim == nil || !victim.IsEnabled() {
+				return
+			}
+			ts.spell.Cast(sim, victim)
+		},
+	})
+}
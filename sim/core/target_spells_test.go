@@ -0,0 +1,52 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestTargetSpellResists(t *testing.T) {
+	request := tankSimRequest(&proto.SimOptions{Iterations: 200, RandomSeed: 101})
+	// 255 resistance against a level 83 mob resists a third of the damage.
+	request.Raid.Parties[0].Players[0].BonusStats = &proto.UnitStats{
+		Stats: stats.Stats{stats.FrostResistance: 255}.ToFloatArray(),
+	}
+	request.Encounter.Targets[0].Spells = []*proto.TargetSpell{
+		{SpellId: 1, School: proto.SpellSchool_SpellSchoolFrost, MinBaseDamage: 1000, Interval: 1},
+		{SpellId: 2, School: proto.SpellSchool_SpellSchoolFrost, MinBaseDamage: 1000, Interval: 1, Binary: true, HitsAllPlayers: true},
+		{SpellId: 3, School: proto.SpellSchool_SpellSchoolShadow, MinBaseDamage: 1000, Interval: 2},
+	}
+
+	result := RunRaidSim(request)
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s", result.ErrorResult)
+	}
+
+	resists := result.RaidMetrics.Parties[0].Players[0].Resists
+	if len(resists) != 2 {
+		t.Fatalf("Expected resist metrics for frost and shadow, got %v", resists)
+	}
+	frost, shadow := resists[0], resists[1]
+	if frost.School != proto.SpellSchool_SpellSchoolFrost || shadow.School != proto.SpellSchool_SpellSchoolShadow {
+		t.Fatalf("Expected resist metrics for frost and shadow, got %v", resists)
+	}
+
+	// Both frost spells hit about once per second, and resist a third of
+	// their damage on average. Half of them are binary, and fully resisted a
+	// third of the time.
+	if frost.HitsAvg < 50 || frost.HitsAvg > 70 {
+		t.Fatalf("Expected about 60 frost hits per iteration, got %f", frost.HitsAvg)
+	}
+	if math.Abs(frost.ResistRate-1.0/3) > 0.01 {
+		t.Fatalf("Expected a third of frost damage to be resisted, got %f", frost.ResistRate)
+	}
+	if math.Abs(frost.FullResistRate-1.0/6) > 0.01 {
+		t.Fatalf("Expected a sixth of frost hits to be fully resisted, got %f", frost.FullResistRate)
+	}
+	if shadow.ResistRate != 0 || shadow.FullResistRate != 0 {
+		t.Fatalf("Expected no shadow resists, got %v", shadow)
+	}
+}
@@ -558,6 +558,18 @@ func FullCharacterTestSuiteGenerator(config CharacterSuiteConfig) TestGenerator
 		},
 	})
 
+	generator.subgenerators = append(generator.subgenerators, SubGenerator{
+		name: "Breakdown",
+		generator: &SingleDpsTestGenerator{
+			Name: "Default",
+			Request: &proto.RaidSimRequest{
+				Raid:       defaultRaid,
+				Encounter:  MakeSingleTargetEncounter(0),
+				SimOptions: DefaultSimTestOptions,
+			},
+		},
+	})
+
 	if len(config.StatsToWeigh) > 0 {
 		generator.subgenerators = append(generator.subgenerators, SubGenerator{
 			name: "StatWeights",
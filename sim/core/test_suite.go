@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 
@@ -25,12 +26,14 @@ type IndividualTestSuite struct {
 	testNames []string
 
 	testResults *proto.TestSuiteResult
+	perfResults *proto.PerfTestResult
 }
 
 func NewIndividualTestSuite(suiteName string) *IndividualTestSuite {
 	return &IndividualTestSuite{
 		Name:        suiteName,
 		testResults: newTestSuiteResult(),
+		perfResults: newPerfTestResult(),
 	}
 }
 
@@ -102,12 +105,119 @@ func (testSuite *IndividualTestSuite) TestCasts(testName string, rsr *proto.Raid
 	testSuite.testResults.CastsResults[testName] = casts
 }
 
+func (testSuite *IndividualTestSuite) TestBreakdown(testName string, rsr *proto.RaidSimRequest) {
+	testSuite.testNames = append(testSuite.testNames, testName)
+	result := RunRaidSim(rsr)
+	if result.Logs != "" {
+		fmt.Printf("LOGS: %s\n", result.Logs)
+	}
+	if result.ErrorResult != "" {
+		panic("simulation failed to run: " + result.ErrorResult)
+	}
+
+	player := result.RaidMetrics.Parties[0].Players[0]
+	damageByAction := make(map[string]float64)
+	var totalDamage float64
+	addActions := func(prefix string, actions []*proto.ActionMetrics) {
+		for _, metric := range actions {
+			name := prefix + strings.ReplaceAll(metric.Id.String(), "  ", " ")
+			for _, targetMetrics := range metric.Targets {
+				damageByAction[name] += targetMetrics.Damage
+				totalDamage += targetMetrics.Damage
+			}
+		}
+	}
+	addActions("", player.Actions)
+	for _, pet := range player.Pets {
+		addActions(pet.Name+": ", pet.Actions)
+	}
+
+	dpsByAction := make(map[string]float64, len(damageByAction))
+	for name, damage := range damageByAction {
+		if damage > 0 {
+			dpsByAction[name] = toFixed(damage/totalDamage*result.RaidMetrics.Dps.Avg, storagePrecision)
+		}
+	}
+	testSuite.testResults.BreakdownResults[testName] = &proto.BreakdownTestResult{Dps: dpsByAction}
+}
+
 func (testSuite *IndividualTestSuite) Done(t *testing.T) {
 	testSuite.writeToFile()
+	testSuite.writePerfToFile()
 }
 
 const tolerance = 0.00001
 
+// Breakdown tests fail if any action's DPS moves by more than this fraction of
+// the total DPS, so small shifts don't need a reference update.
+const breakdownTolerance = 0.001
+
+// Compares per-action DPS against the reference. Returns a table of the actions
+// which changed, largest change first, and whether all changes are within
+// breakdownTolerance.
+// Fails the test if any hand-coded spell values drift from the DBC. Duplicate
+// spell IDs aren't always bugs, e.g. two of the same trinket, so they're only
+// logged.
+func checkSpellRegistrations(t *testing.T, rsr *proto.RaidSimRequest) {
+	sim := NewSim(rsr)
+	for _, unit := range sim.AllUnits {
+		for _, actionID := range unit.duplicateSpellIDs() {
+			t.Logf("%s registered %s (%s) more than once.", unit.Label, actionID.Name(), actionID)
+		}
+		for _, drift := range unit.dbcSpellDrift() {
+			t.Logf("%s If this is intended, add it to dbcDriftExceptions.", drift)
+			t.Fail()
+		}
+	}
+}
+
+func compareBreakdowns(expected map[string]float64, actual map[string]float64) (string, bool) {
+	var totalDps float64
+	for _, dps := range expected {
+		totalDps += dps
+	}
+	threshold := max(tolerance, totalDps*breakdownTolerance)
+
+	var names []string
+	for name := range expected {
+		names = append(names, name)
+	}
+	for name := range actual {
+		if _, ok := expected[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	delta := func(name string) float64 {
+		return actual[name] - expected[name]
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if da, db := math.Abs(delta(a)), math.Abs(delta(b)); da != db {
+			if da > db {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+
+	ok := true
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "  %-50s %12s %12s %12s\n", "Action", "Expected", "Actual", "Delta")
+	for _, name := range names {
+		d := delta(name)
+		if math.Abs(d) <= tolerance {
+			continue
+		}
+		marker := " "
+		if math.Abs(d) > threshold {
+			marker = "!"
+			ok = false
+		}
+		fmt.Fprintf(&sb, "%s %-50s %12.3f %12.3f %+12.3f\n", marker, name, expected[name], actual[name], d)
+	}
+	return sb.String(), ok
+}
+
 func (testSuite *IndividualTestSuite) writeToFile() {
 	str := prototext.Format(testSuite.testResults)
 	// For some reason the formatter sometimes outputs 2 spaces instead of one.
@@ -143,6 +253,7 @@ func newTestSuiteResult() *proto.TestSuiteResult {
 		StatWeightsResults:    make(map[string]*proto.StatWeightsTestResult),
 		DpsResults:            make(map[string]*proto.DpsTestResult),
 		CastsResults:          make(map[string]*proto.CastsTestResult),
+		BreakdownResults:      make(map[string]*proto.BreakdownTestResult),
 	}
 }
 
@@ -169,6 +280,14 @@ func RunTestSuite(t *testing.T, suiteName string, generator TestGenerator) {
 		t.Logf("\n\n----- FAILURE LOADING RESULTS FILE TESTS WILL FAIL-----\n%s\n-----\n\n", err)
 		t.Fail()
 	}
+	expectedPerf := newPerfTestResult()
+	if perfGateEnabled {
+		if expectedPerf, err = testSuite.readExpectedPerf(); err != nil {
+			t.Logf("\n\n----- FAILURE LOADING PERF FILE TESTS WILL FAIL-----\n%s\n-----\n\n", err)
+			t.Fail()
+			expectedPerf = newPerfTestResult()
+		}
+	}
 
 	numTests := generator.NumTests()
 	for i := 0; i < numTests; i++ {
@@ -180,6 +299,7 @@ func RunTestSuite(t *testing.T, suiteName string, generator TestGenerator) {
 
 		t.Run(currentTestName, func(t *testing.T) {
 			fullTestName := suiteName + "-" + testName
+			takeAllocAudit()
 			if csr != nil {
 				testSuite.TestCharacterStats(fullTestName, csr)
 				if actualCharacterStats, ok := testSuite.testResults.CharacterStatsResults[fullTestName]; ok {
@@ -216,8 +336,26 @@ func RunTestSuite(t *testing.T, suiteName string, generator TestGenerator) {
 					t.Logf("Missing Result for test %s", fullTestName)
 					t.Fail()
 				}
+			} else if rsr != nil && strings.Contains(testName, "Breakdown") {
+				checkSpellRegistrations(t, rsr)
+				testSuite.TestBreakdown(fullTestName, rsr)
+				actualBreakdown := testSuite.testResults.BreakdownResults[fullTestName]
+				if expectedBreakdown, ok := expectedResults.BreakdownResults[fullTestName]; ok {
+					if diff, ok := compareBreakdowns(expectedBreakdown.Dps, actualBreakdown.Dps); !ok {
+						t.Logf("Damage breakdown changed by more than %0.1f%% of total DPS (marked with !):\n%s", breakdownTolerance*100, diff)
+						t.Fail()
+					}
+				} else {
+					// Breakdowns were added after most references, so don't fail
+					// until one has been generated.
+					t.Logf("No reference breakdown for test %s, run 'make update-tests' to add one.", fullTestName)
+				}
 			} else if rsr != nil && !strings.Contains(testName, "Casts") {
 				testSuite.TestDPS(fullTestName, rsr)
+				checkAllocBudget(t)
+				if strings.Contains(testName, "Average") {
+					testSuite.checkPerf(t, fullTestName, rsr, expectedPerf)
+				}
 				if actualDpsResult, ok := testSuite.testResults.DpsResults[fullTestName]; ok {
 					if expectedDpsResult, ok := expectedResults.DpsResults[fullTestName]; ok {
 						// Check whichever of DPS/HPS is larger first, so we get better test diff printouts.
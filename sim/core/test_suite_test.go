@@ -0,0 +1,35 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareBreakdowns(t *testing.T) {
+	expected := map[string]float64{
+		"{SpellID: 1}": 900,
+		"{SpellID: 2}": 100,
+	}
+
+	// Shifts of up to 0.1% of the total DPS are allowed.
+	if _, ok := compareBreakdowns(expected, map[string]float64{"{SpellID: 1}": 900.5, "{SpellID: 2}": 100}); !ok {
+		t.Fatalf("Expected a small shift to be within tolerance")
+	}
+
+	diff, ok := compareBreakdowns(expected, map[string]float64{
+		"{SpellID: 1}": 880,
+		"{SpellID: 2}": 100,
+		"{SpellID: 3}": 20,
+	})
+	if ok {
+		t.Fatalf("Expected a 20 DPS shift to fail")
+	}
+	lines := strings.Split(strings.TrimSpace(diff), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 changed actions, got:\n%s", diff)
+	}
+	// Sorted by size of the change, then by name.
+	if !strings.HasPrefix(lines[1], "! {SpellID: 1}") || !strings.HasPrefix(lines[2], "! {SpellID: 3}") {
+		t.Fatalf("Unexpected diff:\n%s", diff)
+	}
+}
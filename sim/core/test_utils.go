@@ -1,9 +1,14 @@
 package core
 
 import (
+	"fmt"
 	"log"
+	"math"
 	"os"
+	"runtime"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/wowsims/wotlk/sim/core/proto"
 	"github.com/wowsims/wotlk/sim/core/stats"
@@ -176,6 +181,53 @@ func MakeSingleTargetEncounter(variation float64) *proto.Encounter {
 	}
 }
 
+// A tank being hit by a single target, which is quick to sim and has metrics
+// for most things.
+func tankSimRequest(options *proto.SimOptions) *proto.RaidSimRequest {
+	return &proto.RaidSimRequest{
+		SimOptions: options,
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{{
+				Players: []*proto.Player{{
+					Name:      "Tank",
+					Class:     proto.Class_ClassShaman,
+					Consumes:  &proto.Consumes{},
+					Buffs:     &proto.IndividualBuffs{},
+					Spec:      &proto.Player_ElementalShaman{},
+					Equipment: &proto.EquipmentSpec{},
+					// Interactive sims can't be sharded, so this just waits instead.
+					Rotation:     &proto.APLRotation{Type: proto.APLRotation_TypeAPL},
+					HealingModel: &proto.HealingModel{Hps: 500, CadenceSeconds: 2, BurstWindow: 6},
+				}},
+				Buffs: &proto.PartyBuffs{},
+			}},
+			Tanks: []*proto.UnitReference{{Type: proto.UnitReference_Player, Index: 0}},
+		},
+		Encounter: &proto.Encounter{
+			Duration:          30,
+			DurationVariation: 5,
+			Targets: []*proto.Target{{
+				Level:         83,
+				MinBaseDamage: 1000,
+				SwingSpeed:    2,
+			}},
+		},
+	}
+}
+
+// Runs pending actions up to the given time, then advances the clock to it.
+// Stops early if the encounter ends first.
+func runSimUntil(sim *Simulation, until time.Duration) {
+	for sim.pendingActions.peek().NextActionAt <= until {
+		if sim.Step() {
+			return
+		}
+	}
+	if sim.CurrentTime < until {
+		sim.advance(until)
+	}
+}
+
 func CharacterStatsTest(label string, t *testing.T, raid *proto.Raid, expectedStats stats.Stats) {
 	csr := &proto.ComputeStatsRequest{
 		Raid: raid,
@@ -234,6 +286,62 @@ func RaidBenchmark(b *testing.B, rsr *proto.RaidSimRequest) {
 			b.Fatalf("RaidBenchmark() at iteration %d failed: %v", i, result.ErrorResult)
 		}
 	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "iterations/s")
+}
+
+// Runs the sim with an increasing number of workers, up to at least 32, to
+// show how well SimOptions.concurrency scales. Reports iterations per second.
+func RaidConcurrencyBenchmark(b *testing.B, rsr *proto.RaidSimRequest) {
+	const iterationsPerWorker = 100
+	maxWorkers := max(32, runtime.NumCPU())
+
+	for workers := 1; workers <= maxWorkers; workers *= 2 {
+		request := googleProto.Clone(rsr).(*proto.RaidSimRequest)
+		request.SimOptions.IsTest = false
+		request.SimOptions.Iterations = int32(maxWorkers * iterationsPerWorker)
+		request.SimOptions.Concurrency = int32(workers)
+
+		b.Run(fmt.Sprintf("Workers-%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				result := RunRaidSim(request)
+				if result.ErrorResult != "" {
+					b.Fatalf("RaidConcurrencyBenchmark() with %d workers failed: %v", workers, result.ErrorResult)
+				}
+			}
+			b.ReportMetric(float64(b.N)*float64(request.SimOptions.Iterations)/b.Elapsed().Seconds(), "iterations/s")
+		})
+	}
+}
+
+// Checks that running the sim across several workers gives the same
+// per-iteration results as running it serially.
+func ConcurrencyParityTest(t *testing.T, rsr *proto.RaidSimRequest) {
+	serialRequest := googleProto.Clone(rsr).(*proto.RaidSimRequest)
+	serialRequest.SimOptions.IsTest = false
+	serialRequest.SimOptions.SaveAllValues = true
+	serialRequest.SimOptions.Iterations = 300
+
+	concurrentRequest := googleProto.Clone(serialRequest).(*proto.RaidSimRequest)
+	concurrentRequest.SimOptions.Concurrency = 3
+
+	serial := RunRaidSim(serialRequest)
+	concurrent := RunRaidSim(concurrentRequest)
+	if serial.ErrorResult != "" || concurrent.ErrorResult != "" {
+		t.Fatalf("Sim failed: %s%s", serial.ErrorResult, concurrent.ErrorResult)
+	}
+
+	serialPlayer := serial.RaidMetrics.Parties[0].Players[0]
+	concurrentPlayer := concurrent.RaidMetrics.Parties[0].Players[0]
+	if !slices.Equal(serialPlayer.Dps.AllValues, concurrentPlayer.Dps.AllValues) {
+		t.Fatalf("Per-iteration DPS differs between serial and concurrent sims")
+	}
+	if len(serialPlayer.Actions) != len(concurrentPlayer.Actions) || len(serialPlayer.Auras) != len(concurrentPlayer.Auras) {
+		t.Fatalf("Expected the same actions and auras, got %d/%d and %d/%d",
+			len(serialPlayer.Actions), len(concurrentPlayer.Actions), len(serialPlayer.Auras), len(concurrentPlayer.Auras))
+	}
+	if math.Abs(serialPlayer.Dps.Stdev-concurrentPlayer.Dps.Stdev) > 1e-6 {
+		t.Fatalf("Expected DPS stdev %f, got %f", serialPlayer.Dps.Stdev, concurrentPlayer.Dps.Stdev)
+	}
 }
 
 func GetAplRotation(dir string, file string) RotationCombo {
@@ -0,0 +1,245 @@
+package core
+
+import (
+	"slices"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// A unit pulls aggro once its threat exceeds the tank's by these ratios.
+const (
+	meleeAggroThreshold  = 1.1
+	rangedAggroThreshold = 1.3
+)
+
+// How long a taunted target is forced to attack the taunting unit.
+const TauntDuration = time.Second * 3
+
+// Running threat tables for each target. Only used when the encounter tracks
+// threat. Threat from resource gains is only totaled at the end of each
+// iteration, so it isn't included here.
+type threatTracker struct {
+	// Threat of each unit, indexed by [target.UnitIndex][unit.UnitIndex].
+	tables [][]float64
+}
+
+func (env *Environment) enableThreatTracking() {
+	env.threat = &threatTracker{
+		tables: make([][]float64, len(env.Encounter.TargetUnits)),
+	}
+	for i := range env.threat.tables {
+		env.threat.tables[i] = make([]float64, len(env.AllUnits))
+	}
+
+	for _, unit := range env.Raid.AllUnits {
+		if unit.Type == PlayerUnit && !env.isTank(unit) {
+			unit.Metrics.threatRace = &threatRaceMetrics{}
+		}
+	}
+}
+
+func (env *Environment) isTank(unit *Unit) bool {
+	for _, target := range env.Encounter.Targets {
+		if target.CurrentTarget == unit || (target.tankSwap != nil && slices.Contains(target.tankSwap.tanks, unit)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (tt *threatTracker) reset() {
+	for _, table := range tt.tables {
+		clear(table)
+	}
+}
+
+func (tt *threatTracker) addThreat(sim *Simulation, unit *Unit, target *Unit, threat float64) {
+	if target.Type != EnemyUnit {
+		return
+	}
+	if unit.threatRedirect != nil && threat > 0 {
+		unit = unit.threatRedirect
+	}
+
+	table := tt.tables[target.UnitIndex]
+	table[unit.UnitIndex] = max(0, table[unit.UnitIndex]+threat)
+
+	trm := unit.Metrics.threatRace
+	victim := target.CurrentTarget
+	if trm != nil && !trm.pulledAggro && victim != nil && table[unit.UnitIndex] > table[victim.UnitIndex]*aggroThreshold(unit) {
+		trm.pulledAggro = true
+		trm.aggroTimes = append(trm.aggroTimes, sim.CurrentTime)
+		if sim.Log != nil {
+			unit.Log(sim, "Pulled aggro on %s from %s", target.Label, victim.Label)
+		}
+	}
+
+	tt.updateVictim(sim, unit.Env.Encounter.Targets[target.Index])
+}
+
+func aggroThreshold(unit *Unit) float64 {
+	if unit.AutoAttacks.AutoSwingMelee {
+		return meleeAggroThreshold
+	}
+	return rangedAggroThreshold
+}
+
+// Switches targets which attack the highest threat unit to whichever unit has
+// pulled aggro from their current victim, if any.
+func (tt *threatTracker) updateVictim(sim *Simulation, target *Target) {
+	if !target.attackHighestThreat || sim.CurrentTime < target.fixateExpiresAt {
+		return
+	}
+
+	table := tt.tables[target.UnitIndex]
+	victimThreat := 0.0
+	if target.CurrentTarget != nil {
+		victimThreat = table[target.CurrentTarget.UnitIndex]
+	}
+
+	var newVictim *Unit
+	newVictimThreat := 0.0
+	for _, unit := range target.Env.Raid.AllUnits {
+		threat := table[unit.UnitIndex]
+		if unit == target.CurrentTarget || !unit.IsEnabled() || threat <= newVictimThreat {
+			continue
+		}
+		if threat > victimThreat*aggroThreshold(unit) {
+			newVictim = unit
+			newVictimThreat = threat
+		}
+	}
+
+	if newVictim != nil {
+		target.setVictim(sim, newVictim)
+	}
+}
+
+// Sends all threat generated by this unit to the given unit instead, until
+// called again with nil.
+func (unit *Unit) RedirectThreat(redirectTo *Unit) {
+	unit.threatRedirect = redirectTo
+}
+
+// Returns this unit's current threat on the target, or 0 if the encounter
+// doesn't track threat.
+func (unit *Unit) GetThreat(target *Unit) float64 {
+	if unit.Env.threat == nil {
+		return 0
+	}
+	return unit.Env.threat.tables[target.UnitIndex][unit.UnitIndex]
+}
+
+// Directly modifies this unit's threat on the target, for effects which drop
+// or restore threat rather than generating it. Threat cannot go below 0.
+func (unit *Unit) AddThreat(sim *Simulation, target *Unit, threat float64) {
+	tt := unit.Env.threat
+	if tt == nil {
+		return
+	}
+	table := tt.tables[target.UnitIndex]
+	table[unit.UnitIndex] = max(0, table[unit.UnitIndex]+threat)
+	tt.updateVictim(sim, unit.Env.Encounter.Targets[target.Index])
+}
+
+// Removes this unit from all threat tables, e.g. on death.
+func (unit *Unit) ClearThreat(sim *Simulation) {
+	if unit.Env.threat == nil {
+		return
+	}
+	for _, target := range unit.Env.Encounter.AllTargetUnits {
+		unit.AddThreat(sim, target, -unit.GetThreat(target))
+	}
+}
+
+// Taunts the target from a player's taunt spell. Without threat tracking the
+// target always attacks its configured tank, so this does nothing.
+func (unit *Unit) ApplyTaunt(sim *Simulation, target *Unit) {
+	if unit.Env.threat == nil {
+		return
+	}
+	unit.taunt(sim, target)
+}
+
+// Forces the target to attack this unit for TauntDuration, and raises this
+// unit's threat to match the highest threat on the target's threat table.
+func (unit *Unit) taunt(sim *Simulation, target *Unit) {
+	if tt := unit.Env.threat; tt != nil {
+		table := tt.tables[target.UnitIndex]
+		table[unit.UnitIndex] = slices.Max(table)
+	}
+
+	enemy := unit.Env.Encounter.Targets[target.Index]
+	enemy.fixateExpiresAt = sim.CurrentTime + TauntDuration
+	enemy.setVictim(sim, unit)
+}
+
+// Wipes all threat on the target, e.g. on a phase transition.
+func (tt *threatTracker) wipeThreat(sim *Simulation, target *Target) {
+	clear(tt.tables[target.UnitIndex])
+	if sim.Log != nil {
+		target.Log(sim, "Threat wiped")
+	}
+}
+
+// Per-iteration aggro statistics for a non-tank player, aggregated across
+// iterations.
+type threatRaceMetrics struct {
+	// Values for the current iteration.
+	pulledAggro bool
+
+	// Aggregate values.
+	iterations     int
+	aggroTimes     []time.Duration
+	threatRatioSum float64
+}
+
+func (trm *threatRaceMetrics) reset() {
+	trm.pulledAggro = false
+}
+
+func (trm *threatRaceMetrics) doneIteration(unit *Unit) {
+	trm.iterations++
+
+	maxRatio := 0.0
+	for _, target := range unit.Env.Encounter.AllTargetUnits {
+		if target.CurrentTarget == nil {
+			continue
+		}
+		if tankThreat := target.CurrentTarget.GetThreat(target); tankThreat > 0 {
+			maxRatio = max(maxRatio, unit.GetThreat(target)/tankThreat)
+		}
+	}
+	trm.threatRatioSum += maxRatio
+}
+
+func (trm *threatRaceMetrics) merge(other *threatRaceMetrics) {
+	trm.iterations += other.iterations
+	trm.aggroTimes = append(trm.aggroTimes, other.aggroTimes...)
+	trm.threatRatioSum += other.threatRatioSum
+}
+
+func (trm *threatRaceMetrics) ToProto() *proto.ThreatRaceMetrics {
+	if trm.iterations == 0 {
+		return &proto.ThreatRaceMetrics{}
+	}
+
+	protoMetrics := &proto.ThreatRaceMetrics{
+		AggroChance:         float64(len(trm.aggroTimes)) / float64(trm.iterations),
+		FinalThreatRatioAvg: trm.threatRatioSum / float64(trm.iterations),
+	}
+
+	if len(trm.aggroTimes) > 0 {
+		sorted := slices.Clone(trm.aggroTimes)
+		slices.Sort(sorted)
+		percentile := func(p float64) float64 {
+			return sorted[int(p*float64(len(sorted)-1)+0.5)].Seconds()
+		}
+		protoMetrics.TimeToAggroP10 = percentile(0.1)
+		protoMetrics.TimeToAggroP50 = percentile(0.5)
+		protoMetrics.TimeToAggroP90 = percentile(0.9)
+	}
+
+	return protoMetrics
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func setupThreatSim(target *proto.Target) *Simulation {
+	caster := func(name string) *proto.Player {
+		return &proto.Player{
+			Name:      name,
+			Class:     proto.Class_ClassShaman,
+			Consumes:  &proto.Consumes{},
+			Buffs:     &proto.IndividualBuffs{},
+			Spec:      &proto.Player_ElementalShaman{},
+			Equipment: &proto.EquipmentSpec{},
+		}
+	}
+
+	sim := NewSim(&proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{
+			RandomSeed: 100,
+		},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{caster("Tank"), caster("DPS")},
+					Buffs:   &proto.PartyBuffs{},
+				},
+			},
+			Tanks: []*proto.UnitReference{
+				{Type: proto.UnitReference_Player, Index: 0},
+			},
+		},
+		Encounter: &proto.Encounter{
+			Duration:    180,
+			TrackThreat: true,
+			Targets:     []*proto.Target{target},
+		},
+	})
+	sim.Reset()
+
+	return sim
+}
+
+func TestThreatRaceAggro(t *testing.T) {
+	sim := setupThreatSim(&proto.Target{Level: 83})
+	target := sim.Encounter.TargetUnits[0]
+	tank := &sim.Raid.Parties[0].Players[0].GetCharacter().Unit
+	dps := &sim.Raid.Parties[0].Players[1].GetCharacter().Unit
+
+	if dps.Metrics.threatRace == nil || tank.Metrics.threatRace != nil {
+		t.Fatalf("Expected threat race metrics only for the non-tank player")
+	}
+
+	sim.Environment.threat.addThreat(sim, tank, target, 1000)
+	sim.Environment.threat.addThreat(sim, dps, target, 1250)
+	if dps.Metrics.threatRace.pulledAggro {
+		t.Fatalf("Ranged player should not pull aggro below 130%% of tank threat")
+	}
+
+	// Redirected threat goes to the tank.
+	dps.RedirectThreat(tank)
+	sim.Environment.threat.addThreat(sim, dps, target, 500)
+	dps.RedirectThreat(nil)
+	if tank.GetThreat(target) != 1500 || dps.GetThreat(target) != 1250 {
+		t.Fatalf("Expected threat to be redirected, got tank: %0.1f, dps: %0.1f", tank.GetThreat(target), dps.GetThreat(target))
+	}
+
+	dps.AddThreat(sim, target, -2000)
+	if dps.GetThreat(target) != 0 {
+		t.Fatalf("Threat should not go below 0, got %0.1f", dps.GetThreat(target))
+	}
+
+	sim.CurrentTime = time.Second * 10
+	sim.Environment.threat.addThreat(sim, dps, target, 2000)
+	if !dps.Metrics.threatRace.pulledAggro {
+		t.Fatalf("Expected ranged player to pull aggro above 130%% of tank threat")
+	}
+
+	dps.Metrics.threatRace.doneIteration(dps)
+	metrics := dps.Metrics.threatRace.ToProto()
+	if metrics.AggroChance != 1 || metrics.TimeToAggroP50 != 10 {
+		t.Fatalf("Unexpected threat race metrics: %v", metrics)
+	}
+}
+
+func TestThreatTargetSwitching(t *testing.T) {
+	sim := setupThreatSim(&proto.Target{Level: 83, AttackHighestThreat: true})
+	target := sim.Encounter.TargetUnits[0]
+	tank := &sim.Raid.Parties[0].Players[0].GetCharacter().Unit
+	dps := &sim.Raid.Parties[0].Players[1].GetCharacter().Unit
+
+	sim.Environment.threat.addThreat(sim, tank, target, 1000)
+	sim.Environment.threat.addThreat(sim, dps, target, 1400)
+	if target.CurrentTarget != dps {
+		t.Fatalf("Expected target to switch to the player who pulled aggro")
+	}
+
+	tank.ApplyTaunt(sim, target)
+	if target.CurrentTarget != tank || tank.GetThreat(target) != 1400 {
+		t.Fatalf("Expected taunt to switch target and match highest threat, got threat %0.1f", tank.GetThreat(target))
+	}
+
+	// Fixated on the tank, so no switch even though the dps is far ahead.
+	sim.Environment.threat.addThreat(sim, dps, target, 5000)
+	if target.CurrentTarget != tank {
+		t.Fatalf("Expected target to stay fixated on the taunting tank")
+	}
+
+	sim.CurrentTime = TauntDuration
+	sim.Environment.threat.addThreat(sim, dps, target, 1)
+	if target.CurrentTarget != dps {
+		t.Fatalf("Expected target to switch once the taunt fixate expires")
+	}
+
+	sim.Environment.threat.wipeThreat(sim, sim.Encounter.Targets[0])
+	sim.Environment.threat.addThreat(sim, tank, target, 1)
+	if target.CurrentTarget != tank {
+		t.Fatalf("Expected first unit to gain threat after a wipe to take aggro")
+	}
+}
+
+func TestTauntWithoutThreatTracking(t *testing.T) {
+	sim := setupThreatSim(&proto.Target{Level: 83})
+	sim.Environment.threat = nil
+	target := sim.Encounter.TargetUnits[0]
+	dps := &sim.Raid.Parties[0].Players[1].GetCharacter().Unit
+
+	dps.ApplyTaunt(sim, target)
+	if target.CurrentTarget == dps {
+		t.Fatalf("Expected taunts to be ignored without threat tracking")
+	}
+}
@@ -0,0 +1,359 @@
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+type TimelineEvent struct {
+	Kind     proto.TimelineEventKind
+	Start    time.Duration
+	Duration time.Duration
+	ActionID ActionID // Zero for events without an action, e.g. idle time.
+	Stacks   int32    // Only for TimelineEventAura.
+
+	// Only for TimelineEventRune.
+	RuneSlot  int8
+	DeathRune bool
+}
+
+func (event TimelineEvent) end() time.Duration {
+	return event.Start + event.Duration
+}
+
+// Records swings, casts, GCDs, idle time and selected auras for a unit, for
+// the first few iterations of a sim.
+type unitTimeline struct {
+	maxIterations int32
+	resolution    time.Duration
+	auras         []ActionID
+
+	actions       []ActionID
+	actionIndices map[ActionID]uint64
+	iterations    [][]byte
+
+	// State for the current iteration.
+	recording  bool
+	events     []TimelineEvent
+	lastByKind map[proto.TimelineEventKind]int
+	busyUntil  time.Duration
+
+	// Start and stacks of the current event for each active recorded aura.
+	auraEvents map[*Aura]TimelineEvent
+
+	// The current event of each regenerating rune, by slot.
+	runeEvents [6]TimelineEvent
+	runeSpent  [6]bool
+}
+
+func (env *Environment) enableTimelines(options *proto.TimelineOptions, maxIterations int32) {
+	resolution := time.Duration(max(options.ResolutionMs, 1)) * time.Millisecond
+	auras := make([]ActionID, len(options.Auras))
+	for i, auraID := range options.Auras {
+		auras[i] = ProtoToActionID(auraID)
+	}
+	for _, unit := range env.Raid.AllUnits {
+		unit.Metrics.timeline = &unitTimeline{
+			maxIterations: maxIterations,
+			resolution:    resolution,
+			auras:         auras,
+			actionIndices: make(map[ActionID]uint64),
+			lastByKind:    make(map[proto.TimelineEventKind]int),
+			auraEvents:    make(map[*Aura]TimelineEvent),
+		}
+	}
+}
+
+func (tl *unitTimeline) reset() {
+	tl.recording = int32(len(tl.iterations)) < tl.maxIterations
+	tl.events = tl.events[:0]
+	clear(tl.lastByKind)
+	tl.busyUntil = 0
+	clear(tl.auraEvents)
+	tl.runeSpent = [6]bool{}
+}
+
+func (tl *unitTimeline) record(event TimelineEvent) {
+	event.Start = event.Start / tl.resolution * tl.resolution
+	event.Duration = (event.Duration + tl.resolution - 1) / tl.resolution * tl.resolution
+
+	// Merge idle time and aura segments with the previous event of the same
+	// kind if they touch, which is what keeps coarse resolutions small. Swings,
+	// casts, GCDs and runes are never merged, so that each of them stays
+	// visible, e.g. to the cooldown export.
+	if i, ok := tl.lastByKind[event.Kind]; ok && mergesInTimeline(event.Kind) {
+		if last := &tl.events[i]; last.ActionID == event.ActionID && last.Stacks == event.Stacks && event.Start <= last.end() {
+			last.Duration = max(last.end(), event.end()) - last.Start
+			return
+		}
+	}
+
+	tl.lastByKind[event.Kind] = len(tl.events)
+	tl.events = append(tl.events, event)
+}
+
+func mergesInTimeline(kind proto.TimelineEventKind) bool {
+	return kind == proto.TimelineEventKind_TimelineEventIdle || kind == proto.TimelineEventKind_TimelineEventAura
+}
+
+func (tl *unitTimeline) recordSwing(sim *Simulation, wa *WeaponAttack, spell *Spell) {
+	if !tl.recording {
+		return
+	}
+
+	kind := proto.TimelineEventKind_TimelineEventMainhandSwing
+	if aa := &wa.unit.AutoAttacks; wa == &aa.oh {
+		kind = proto.TimelineEventKind_TimelineEventOffhandSwing
+	} else if wa == &aa.ranged {
+		kind = proto.TimelineEventKind_TimelineEventRangedSwing
+	}
+
+	tl.record(TimelineEvent{
+		Kind:     kind,
+		Start:    sim.CurrentTime,
+		Duration: wa.curSwingDuration,
+		ActionID: spell.ActionID,
+	})
+}
+
+func (tl *unitTimeline) recordCast(sim *Simulation, spell *Spell) {
+	if !tl.recording {
+		return
+	}
+
+	effectiveTime := spell.CurCast.EffectiveTime()
+	if effectiveTime > 0 {
+		tl.recordIdle(sim.CurrentTime)
+		tl.busyUntil = max(tl.busyUntil, sim.CurrentTime+effectiveTime)
+	}
+
+	tl.record(TimelineEvent{
+		Kind:     proto.TimelineEventKind_TimelineEventCast,
+		Start:    sim.CurrentTime,
+		Duration: spell.CurCast.CastTime + spell.CurCast.ChannelTime,
+		ActionID: spell.ActionID,
+	})
+	if spell.CurCast.GCD > 0 {
+		tl.record(TimelineEvent{
+			Kind:     proto.TimelineEventKind_TimelineEventGCD,
+			Start:    sim.CurrentTime,
+			Duration: effectiveTime,
+			ActionID: spell.ActionID,
+		})
+	}
+}
+
+// Records the time between the end of the last cast or GCD and the given time.
+func (tl *unitTimeline) recordIdle(until time.Duration) {
+	if until-tl.busyUntil < tl.resolution {
+		return
+	}
+	tl.record(TimelineEvent{
+		Kind:     proto.TimelineEventKind_TimelineEventIdle,
+		Start:    tl.busyUntil,
+		Duration: until - tl.busyUntil,
+	})
+	tl.busyUntil = until
+}
+
+func (tl *unitTimeline) recordsAura(aura *Aura) bool {
+	for _, auraID := range tl.auras {
+		if auraID.SameAction(aura.ActionID) || (auraID.Tag == 0 && auraID.SameActionIgnoreTag(aura.ActionID)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (tl *unitTimeline) onAuraGain(sim *Simulation, aura *Aura) {
+	if !tl.recording || !tl.recordsAura(aura) {
+		return
+	}
+	tl.auraEvents[aura] = TimelineEvent{
+		Kind:     proto.TimelineEventKind_TimelineEventAura,
+		Start:    sim.CurrentTime,
+		ActionID: aura.ActionID,
+		Stacks:   aura.stacks,
+	}
+}
+
+// Records the aura's current event up to the given time, returning false if
+// the aura isn't being recorded.
+func (tl *unitTimeline) endAuraEvent(aura *Aura, end time.Duration) bool {
+	event, ok := tl.auraEvents[aura]
+	if !ok {
+		return false
+	}
+	delete(tl.auraEvents, aura)
+	if end > event.Start {
+		event.Duration = end - event.Start
+		tl.record(event)
+	}
+	return true
+}
+
+func (tl *unitTimeline) onAuraStacksChange(sim *Simulation, aura *Aura) {
+	if tl.endAuraEvent(aura, sim.CurrentTime) {
+		tl.onAuraGain(sim, aura)
+	}
+}
+
+func (tl *unitTimeline) onAuraExpire(sim *Simulation, aura *Aura) {
+	tl.endAuraEvent(aura, min(sim.CurrentTime, aura.expires))
+}
+
+func (tl *unitTimeline) onRuneSpent(sim *Simulation, slot int8, death bool, actionID ActionID) {
+	if !tl.recording || sim.CurrentTime < 0 {
+		return
+	}
+	tl.runeEvents[slot] = TimelineEvent{
+		Kind:      proto.TimelineEventKind_TimelineEventRune,
+		Start:     sim.CurrentTime,
+		ActionID:  actionID,
+		RuneSlot:  slot,
+		DeathRune: death,
+	}
+	tl.runeSpent[slot] = true
+}
+
+// Records the rune's cooldown up to the given time, which is earlier than its
+// full cooldown if e.g. Blood Tap or Empower Rune Weapon regenerated it.
+func (tl *unitTimeline) onRuneReady(slot int8, at time.Duration) {
+	if !tl.runeSpent[slot] {
+		return
+	}
+	tl.runeSpent[slot] = false
+	event := tl.runeEvents[slot]
+	if at > event.Start {
+		event.Duration = at - event.Start
+		tl.record(event)
+	}
+}
+
+func (tl *unitTimeline) doneIteration(sim *Simulation) {
+	if !tl.recording {
+		return
+	}
+	tl.recordIdle(sim.CurrentTime)
+	for slot := range tl.runeEvents {
+		tl.onRuneReady(int8(slot), sim.CurrentTime)
+	}
+	tl.iterations = append(tl.iterations, tl.encode(tl.events))
+}
+
+func (tl *unitTimeline) encode(events []TimelineEvent) []byte {
+	data := make([]byte, 0, len(events)*8)
+	prevStart := int64(0)
+	for _, event := range events {
+		actionIndex := uint64(0)
+		if event.ActionID != (ActionID{}) {
+			idx, ok := tl.actionIndices[event.ActionID]
+			if !ok {
+				tl.actions = append(tl.actions, event.ActionID)
+				idx = uint64(len(tl.actions))
+				tl.actionIndices[event.ActionID] = idx
+			}
+			actionIndex = idx
+		}
+
+		start := int64(event.Start / tl.resolution)
+		data = binary.AppendUvarint(data, uint64(event.Kind))
+		data = binary.AppendVarint(data, start-prevStart)
+		data = binary.AppendUvarint(data, uint64(event.Duration/tl.resolution))
+		data = binary.AppendUvarint(data, actionIndex)
+		if event.Kind == proto.TimelineEventKind_TimelineEventAura {
+			data = binary.AppendUvarint(data, uint64(event.Stacks))
+		} else if event.Kind == proto.TimelineEventKind_TimelineEventRune {
+			runeBits := uint64(event.RuneSlot)
+			if event.DeathRune {
+				runeBits |= 8
+			}
+			data = binary.AppendUvarint(data, runeBits)
+		}
+		prevStart = start
+	}
+	return data
+}
+
+func (tl *unitTimeline) ToProto() *proto.UnitTimeline {
+	protoTimeline := &proto.UnitTimeline{
+		ResolutionMs: int32(tl.resolution / time.Millisecond),
+		Iterations:   tl.iterations,
+	}
+	for _, actionID := range tl.actions {
+		protoTimeline.Actions = append(protoTimeline.Actions, actionID.ToProto())
+	}
+	return protoTimeline
+}
+
+// Decodes the events of one recorded iteration of a timeline.
+func DecodeTimeline(timeline *proto.UnitTimeline, iteration int) ([]TimelineEvent, error) {
+	if iteration < 0 || iteration >= len(timeline.Iterations) {
+		return nil, fmt.Errorf("timeline has no iteration %d", iteration)
+	}
+	resolution := time.Duration(timeline.ResolutionMs) * time.Millisecond
+
+	data := timeline.Iterations[iteration]
+	readUvarint := func() (uint64, error) {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, fmt.Errorf("malformed timeline data")
+		}
+		data = data[n:]
+		return v, nil
+	}
+
+	var events []TimelineEvent
+	start := int64(0)
+	for len(data) > 0 {
+		kind, err := readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		startDelta, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("malformed timeline data")
+		}
+		data = data[n:]
+		duration, err := readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		actionIndex, err := readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		if actionIndex > uint64(len(timeline.Actions)) {
+			return nil, fmt.Errorf("timeline action index %d out of range", actionIndex)
+		}
+
+		start += startDelta
+		event := TimelineEvent{
+			Kind:     proto.TimelineEventKind(kind),
+			Start:    time.Duration(start) * resolution,
+			Duration: time.Duration(duration) * resolution,
+		}
+		if actionIndex > 0 {
+			event.ActionID = ProtoToActionID(timeline.Actions[actionIndex-1])
+		}
+		if event.Kind == proto.TimelineEventKind_TimelineEventAura {
+			stacks, err := readUvarint()
+			if err != nil {
+				return nil, err
+			}
+			event.Stacks = int32(stacks)
+		} else if event.Kind == proto.TimelineEventKind_TimelineEventRune {
+			runeBits, err := readUvarint()
+			if err != nil {
+				return nil, err
+			}
+			event.RuneSlot = int8(runeBits & 7)
+			event.DeathRune = runeBits&8 != 0
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
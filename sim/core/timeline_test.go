@@ -0,0 +1,116 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestTimelineRoundTrip(t *testing.T) {
+	env := &Environment{Raid: &Raid{AllUnits: []*Unit{{}}}}
+	env.enableTimelines(&proto.TimelineOptions{Iterations: 1, ResolutionMs: 100}, 1)
+	tl := env.Raid.AllUnits[0].Metrics.timeline
+	tl.reset()
+
+	fireball := ActionID{SpellID: 42833}
+	events := []TimelineEvent{
+		{Kind: proto.TimelineEventKind_TimelineEventCast, Start: -time.Second, Duration: 3 * time.Second, ActionID: fireball},
+		{Kind: proto.TimelineEventKind_TimelineEventGCD, Start: 2 * time.Second, Duration: 1500 * time.Millisecond, ActionID: fireball},
+		{Kind: proto.TimelineEventKind_TimelineEventIdle, Start: 3500 * time.Millisecond, Duration: 1000 * time.Millisecond},
+		// Touches the previous idle time, so gets merged into it.
+		{Kind: proto.TimelineEventKind_TimelineEventIdle, Start: 4540 * time.Millisecond, Duration: 250 * time.Millisecond},
+	}
+	for _, event := range events {
+		tl.record(event)
+	}
+	tl.iterations = append(tl.iterations, tl.encode(tl.events))
+
+	decoded, err := DecodeTimeline(tl.ToProto(), 0)
+	if err != nil {
+		t.Fatalf("Failed to decode timeline: %s", err)
+	}
+
+	expected := []TimelineEvent{
+		{Kind: proto.TimelineEventKind_TimelineEventCast, Start: -time.Second, Duration: 3 * time.Second, ActionID: fireball},
+		{Kind: proto.TimelineEventKind_TimelineEventGCD, Start: 2 * time.Second, Duration: 1500 * time.Millisecond, ActionID: fireball},
+		{Kind: proto.TimelineEventKind_TimelineEventIdle, Start: 3500 * time.Millisecond, Duration: 1300 * time.Millisecond},
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Fatalf("Expected events %v, got %v", expected, decoded)
+	}
+}
+
+func TestTimelineBackToBackCasts(t *testing.T) {
+	env := &Environment{Raid: &Raid{AllUnits: []*Unit{{}}}}
+	env.enableTimelines(&proto.TimelineOptions{Iterations: 1, ResolutionMs: 100}, 1)
+	tl := env.Raid.AllUnits[0].Metrics.timeline
+	tl.reset()
+
+	fireball := ActionID{SpellID: 42833}
+	var expected []TimelineEvent
+	for i := 0; i < 3; i++ {
+		cast := TimelineEvent{Kind: proto.TimelineEventKind_TimelineEventCast, Start: time.Duration(i) * 3 * time.Second, Duration: 3 * time.Second, ActionID: fireball}
+		gcd := TimelineEvent{Kind: proto.TimelineEventKind_TimelineEventGCD, Start: cast.Start, Duration: 3 * time.Second, ActionID: fireball}
+		tl.record(cast)
+		tl.record(gcd)
+		expected = append(expected, cast, gcd)
+	}
+	tl.iterations = append(tl.iterations, tl.encode(tl.events))
+
+	decoded, err := DecodeTimeline(tl.ToProto(), 0)
+	if err != nil {
+		t.Fatalf("Failed to decode timeline: %s", err)
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Fatalf("Expected each cast to be its own event %v, got %v", expected, decoded)
+	}
+}
+
+func TestTimelineAuras(t *testing.T) {
+	sim := &Simulation{}
+	unit := &Unit{auraTracker: newAuraTracker()}
+	env := &Environment{Raid: &Raid{AllUnits: []*Unit{unit}}}
+
+	stackingID := ActionID{SpellID: 1}
+	env.enableTimelines(&proto.TimelineOptions{
+		Iterations:   1,
+		ResolutionMs: 100,
+		Auras:        []*proto.ActionID{stackingID.ToProto()},
+	}, 1)
+	tl := unit.Metrics.timeline
+	tl.reset()
+
+	stacking := unit.RegisterAura(Aura{Label: "Stacking", ActionID: stackingID.WithTag(1), Duration: 10 * time.Second, MaxStacks: 5})
+	other := unit.RegisterAura(Aura{Label: "Other", ActionID: ActionID{SpellID: 2}, Duration: 10 * time.Second})
+
+	stacking.Activate(sim)
+	stacking.SetStacks(sim, 1)
+	other.Activate(sim)
+
+	sim.CurrentTime = time.Second
+	stacking.Activate(sim) // Refreshes don't start a new event.
+
+	sim.CurrentTime = 2 * time.Second
+	stacking.SetStacks(sim, 3)
+
+	sim.CurrentTime = 5 * time.Second
+	stacking.Deactivate(sim)
+	other.Deactivate(sim)
+
+	tl.doneIteration(sim)
+	decoded, err := DecodeTimeline(tl.ToProto(), 0)
+	if err != nil {
+		t.Fatalf("Failed to decode timeline: %s", err)
+	}
+
+	expected := []TimelineEvent{
+		{Kind: proto.TimelineEventKind_TimelineEventAura, Start: 0, Duration: 2 * time.Second, ActionID: stackingID.WithTag(1), Stacks: 1},
+		{Kind: proto.TimelineEventKind_TimelineEventAura, Start: 2 * time.Second, Duration: 3 * time.Second, ActionID: stackingID.WithTag(1), Stacks: 3},
+		{Kind: proto.TimelineEventKind_TimelineEventIdle, Start: 0, Duration: 5 * time.Second},
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Fatalf("Expected events %v, got %v", expected, decoded)
+	}
+}
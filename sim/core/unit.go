@@ -49,6 +49,9 @@ type Unit struct {
 
 	MobType proto.MobType
 
+	// Whether this is an enemy player rather than a mob, see proto.Target.pvp.
+	PvP bool
+
 	// Amount of time it takes for the human agent to react to in-game events.
 	// Used by certain APL values and actions.
 	ReactionTime time.Duration
@@ -70,6 +73,17 @@ type Unit struct {
 	// Whether this unit is able to perform actions.
 	enabled bool
 
+	// Only set for targets with scripted damage windows.
+	damageWindows *damageWindows
+
+	// Only set for players hit by loss of control events, or with a PvP
+	// trinket.
+	lossOfControl *lossOfControl
+
+	// Only set for players and their pets when the encounter has downtime
+	// windows.
+	outOfCombat *outOfCombat
+
 	// Stats this Unit will have at the very start of each Sim iteration.
 	// Includes all equipment / buffs / permanent effects but not temporary
 	// effects from items / abilities.
@@ -144,6 +158,7 @@ type Unit struct {
 
 	// GCD-related PendingActions.
 	gcdAction      *PendingAction
+	gcdActions     pendingActionPool
 	hardcastAction *PendingAction
 
 	// Fields related to waiting for certain events to happen.
@@ -163,6 +178,16 @@ type Unit struct {
 	// The currently-channeled DOT spell, otherwise nil.
 	ChanneledDot *Dot
 
+	stance      Stance
+	stanceAuras []stanceAura
+
+	// Pulsing auras sourced from this unit, see RegisterPulsingAura.
+	pulsingAuras []*PulsingAura
+
+	// If set, threat generated by this unit is given to this unit instead, e.g.
+	// from Misdirection or Tricks of the Trade.
+	threatRedirect *Unit
+
 	ManaRequired float64
 }
 
@@ -203,7 +228,7 @@ func (unit *Unit) LogLabel() string {
 }
 
 func (unit *Unit) Log(sim *Simulation, message string, vals ...interface{}) {
-	sim.Log(unit.LogLabel()+" "+message, vals...)
+	sim.logEvent(newLogMessage(LogEventMessage, unit, message, vals))
 }
 
 func (unit *Unit) GetInitialStat(stat stats.Stat) float64 {
@@ -444,6 +469,13 @@ func (unit *Unit) finalize() {
 		spell.finalize()
 	}
 
+	if unit.Type != EnemyUnit {
+		unit.Metrics.castEfficiency = newCastEfficiencyMetrics(unit.Spellbook)
+		if len(unit.Env.Encounter.adds) > 0 {
+			unit.Metrics.damageSplit = &damageSplitMetrics{}
+		}
+	}
+
 	// For now, restrict this optimization to rogues only. Ferals will require
 	// some extra logic to handle their ExcessEnergy() calc.
 	agent := unit.Env.Raid.GetPlayerFromUnit(unit)
@@ -459,6 +491,11 @@ func (unit *Unit) reset(sim *Simulation, _ Agent) {
 	unit.resetCDs(sim)
 	unit.Hardcast.Expires = startingCDTime
 	unit.ChanneledDot = nil
+	if unit.lossOfControl != nil {
+		unit.lossOfControl.reset()
+	}
+	unit.stance = StanceNone
+	unit.threatRedirect = nil
 	unit.Metrics.reset()
 	unit.ResetStatDeps()
 	unit.statsWithoutDeps = unit.initialStatsWithoutDeps
@@ -470,7 +507,7 @@ func (unit *Unit) reset(sim *Simulation, _ Agent) {
 		spell.reset(sim)
 	}
 
-	unit.manaBar.reset()
+	unit.manaBar.reset(sim)
 	unit.focusBar.reset(sim)
 	unit.healthBar.reset(sim)
 	unit.UpdateManaRegenRates()
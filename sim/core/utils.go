@@ -100,6 +100,24 @@ func UnitLevelFloat64(unitLevel int32, maxLevelPlus0Val float64, maxLevelPlus1Va
 	}
 }
 
+// Like UnitLevelFloat64, but for how many levels higher one unit is than
+// another. Deltas above +3 use the +3 value, and each level below +0 changes
+// the +0 value by perLevelBelow.
+func LevelDeltaFloat64(levelDelta int32, plus0Val float64, plus1Val float64, plus2Val float64, plus3Val float64, perLevelBelow float64) float64 {
+	switch {
+	case levelDelta < 0:
+		return plus0Val - perLevelBelow*float64(levelDelta)
+	case levelDelta == 0:
+		return plus0Val
+	case levelDelta == 1:
+		return plus1Val
+	case levelDelta == 2:
+		return plus2Val
+	default:
+		return plus3Val
+	}
+}
+
 func WithinToleranceFloat64(expectedValue float64, actualValue float64, tolerance float64) bool {
 	return actualValue >= (expectedValue-tolerance) && actualValue <= (expectedValue+tolerance)
 }
@@ -0,0 +1,240 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	goproto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Simulates each of the request's deltas against the base request, and
+// returns the change in raid DPS from each one.
+func WhatIf(ctx context.Context, request *proto.WhatIfRequest, progress chan *proto.ProgressMetrics) *proto.WhatIfResult {
+	result, err := runWhatIf(ctx, request, progress)
+	if err != nil {
+		result = &proto.WhatIfResult{
+			ErrorResult: err.Error(),
+		}
+	}
+
+	if progress != nil {
+		progress <- &proto.ProgressMetrics{
+			FinalWhatIfResult: result,
+		}
+		close(progress)
+	}
+
+	return result
+}
+
+func runWhatIf(ctx context.Context, request *proto.WhatIfRequest, progress chan *proto.ProgressMetrics) (*proto.WhatIfResult, error) {
+	if request.Base == nil {
+		return nil, errors.New("what-if request has no base")
+	}
+
+	base := pairedSimRequest(request.Base)
+
+	totalSims := int32(len(request.Deltas) + 1)
+	var completedSims int32
+	runOne := func(rsr *proto.RaidSimRequest) *proto.RaidSimResult {
+		result := RunSimWithContext(ctx, rsr, nil)
+		completedSims++
+		if progress != nil {
+			progress <- &proto.ProgressMetrics{
+				CompletedSims: completedSims,
+				TotalSims:     totalSims,
+			}
+		}
+		return result
+	}
+
+	baseline := runOne(base)
+	if baseline.ErrorResult != "" {
+		return nil, errors.New(baseline.ErrorResult)
+	}
+
+	result := &proto.WhatIfResult{
+		BaseDps: baseline.RaidMetrics.Dps.Avg,
+		Deltas:  make([]*proto.WhatIfDeltaResult, 0, len(request.Deltas)),
+	}
+	for _, delta := range request.Deltas {
+		if ctx.Err() != nil {
+			break
+		}
+
+		deltaResult := &proto.WhatIfDeltaResult{
+			Label: delta.Label,
+		}
+		if deltaResult.Label == "" {
+			deltaResult.Label = whatIfDeltaLabel(delta)
+		}
+		result.Deltas = append(result.Deltas, deltaResult)
+
+		rsr := goproto.Clone(base).(*proto.RaidSimRequest)
+		if err := applyWhatIfDelta(rsr, delta); err != nil {
+			deltaResult.ErrorResult = err.Error()
+			completedSims++
+			continue
+		}
+
+		simResult := runOne(rsr)
+		if simResult.ErrorResult != "" {
+			deltaResult.ErrorResult = simResult.ErrorResult
+			continue
+		}
+
+		deltaResult.DpsDelta, deltaResult.DpsDeltaHalfWidth = pairedDifference(baseline.RaidMetrics.Dps.AllValues, simResult.RaidMetrics.Dps.AllValues)
+		deltaResult.Significant = deltaResult.DpsDeltaHalfWidth < math.Abs(deltaResult.DpsDelta)
+	}
+
+	return result, nil
+}
+
+// Returns a copy of the request, set up so that sims of changed copies of it
+// can be compared iteration by iteration. This needs every sim to run the same
+// iterations with the same random rolls.
+func pairedSimRequest(request *proto.RaidSimRequest) *proto.RaidSimRequest {
+	base := goproto.Clone(request).(*proto.RaidSimRequest)
+	base.ExternalsOptimizer = nil
+	if base.SimOptions == nil {
+		base.SimOptions = &proto.SimOptions{}
+	}
+	simOptions := base.SimOptions
+	simOptions.Debug = false
+	simOptions.DebugFirstIteration = false
+	simOptions.ReplayIteration = nil
+	simOptions.OutlierIterations = 0
+	simOptions.Timeline = nil
+	simOptions.DamageBuckets = nil
+	simOptions.TargetConfidence = nil
+	simOptions.SaveAllValues = true
+	simOptions.LabeledRngStreams = true
+	if simOptions.RandomSeed == 0 {
+		simOptions.RandomSeed = time.Now().UnixNano()
+	}
+	return base
+}
+
+// Returns the average and 95% confidence interval half-width of the
+// differences between paired iterations. Cancelled sims may have stopped
+// early, so only the iterations that both sims completed are compared.
+func pairedDifference(baseValues, changedValues []float64) (float64, float64) {
+	var diff aggregator
+	for i := 0; i < min(len(baseValues), len(changedValues)); i++ {
+		diff.add(changedValues[i] - baseValues[i])
+	}
+	return diff.confidenceInterval()
+}
+
+func applyWhatIfDelta(rsr *proto.RaidSimRequest, delta *proto.WhatIfDelta) error {
+	switch change := delta.Change.(type) {
+	case *proto.WhatIfDelta_Item:
+		return applyWhatIfItemSwap(rsr, change.Item)
+	case *proto.WhatIfDelta_Field:
+		return setProtoField(rsr.ProtoReflect(), change.Field.Path, change.Field.Value)
+	default:
+		return errors.New("what-if delta has no change")
+	}
+}
+
+func applyWhatIfItemSwap(rsr *proto.RaidSimRequest, swap *proto.WhatIfItemSwap) error {
+	player, err := requestPlayer(rsr, swap.PlayerIndex)
+	if err != nil {
+		return err
+	}
+	if player.Equipment == nil {
+		return fmt.Errorf("no equipment for player at index %d", swap.PlayerIndex)
+	}
+	if swap.Item == nil || swap.Item.Item == nil {
+		return errors.New("item swap has no item")
+	}
+
+	slot := int(swap.Item.Slot)
+	for len(player.Equipment.Items) <= slot {
+		player.Equipment.Items = append(player.Equipment.Items, &proto.ItemSpec{})
+	}
+	player.Equipment.Items[slot] = goproto.Clone(swap.Item.Item).(*proto.ItemSpec)
+	return nil
+}
+
+// Returns the player at the given raid index, as in UnitReference.index.
+func requestPlayer(rsr *proto.RaidSimRequest, index int32) (*proto.Player, error) {
+	partyIndex, playerIndex := int(index/5), int(index%5)
+	if index < 0 || partyIndex >= len(rsr.Raid.GetParties()) || playerIndex >= len(rsr.Raid.Parties[partyIndex].GetPlayers()) {
+		return nil, fmt.Errorf("no player at index %d", index)
+	}
+	player := rsr.Raid.Parties[partyIndex].Players[playerIndex]
+	if player == nil {
+		return nil, fmt.Errorf("no player at index %d", index)
+	}
+	return player, nil
+}
+
+// Sets the field at the given dot-separated path of field names and list
+// indices, with a value in proto JSON format.
+func setProtoField(msg protoreflect.Message, path string, value string) error {
+	names := strings.Split(path, ".")
+	for i := 0; i < len(names)-1; i++ {
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(names[i]))
+		if fd == nil || fd.Message() == nil || fd.IsMap() {
+			return fmt.Errorf("%s: %s has no message field %s", path, msg.Descriptor().Name(), names[i])
+		}
+		// Setting a field of an unset oneof member would silently switch the
+		// oneof, e.g. a player's spec.
+		if fd.ContainingOneof() != nil && !msg.Has(fd) {
+			return fmt.Errorf("%s: %s is not set", path, names[i])
+		}
+
+		if !fd.IsList() {
+			msg = msg.Mutable(fd).Message()
+			continue
+		}
+
+		i++
+		list := msg.Mutable(fd).List()
+		index, err := strconv.Atoi(names[i])
+		if err != nil || index < 0 || index >= list.Len() || i == len(names)-1 {
+			return fmt.Errorf("%s: invalid index %s into %s", path, names[i], names[i-1])
+		}
+		msg = list.Get(index).Message()
+	}
+
+	name := names[len(names)-1]
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if fd == nil {
+		return fmt.Errorf("%s: %s has no field %s", path, msg.Descriptor().Name(), name)
+	}
+
+	// Parse the value as the field of an otherwise empty message, so it has
+	// the same format as anywhere else in JSON requests.
+	parsed := msg.New()
+	if err := protojson.Unmarshal([]byte(fmt.Sprintf("{%q: %s}", fd.JSONName(), value)), parsed.Interface()); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if parsed.Has(fd) {
+		msg.Set(fd, parsed.Get(fd))
+	} else {
+		msg.Clear(fd)
+	}
+	return nil
+}
+
+func whatIfDeltaLabel(delta *proto.WhatIfDelta) string {
+	switch change := delta.Change.(type) {
+	case *proto.WhatIfDelta_Item:
+		return fmt.Sprintf("Player %d %s: %d", change.Item.PlayerIndex, change.Item.GetItem().GetSlot(), change.Item.GetItem().GetItem().GetId())
+	case *proto.WhatIfDelta_Field:
+		return fmt.Sprintf("%s = %s", change.Field.Path, change.Field.Value)
+	default:
+		return ""
+	}
+}
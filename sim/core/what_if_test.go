@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func whatIfTestRequest() *proto.RaidSimRequest {
+	return &proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{Iterations: 10, RandomSeed: 101, Interactive: true},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{{
+				Players: []*proto.Player{{
+					Name:      "Shaman",
+					Class:     proto.Class_ClassShaman,
+					Consumes:  &proto.Consumes{},
+					Buffs:     &proto.IndividualBuffs{},
+					Spec:      &proto.Player_ElementalShaman{ElementalShaman: &proto.ElementalShaman{}},
+					Equipment: &proto.EquipmentSpec{},
+				}},
+				Buffs: &proto.PartyBuffs{},
+			}},
+			Buffs: &proto.RaidBuffs{},
+		},
+		Encounter: &proto.Encounter{
+			Duration: 20,
+			Targets:  []*proto.Target{{Level: 83}},
+		},
+	}
+}
+
+func TestSetProtoField(t *testing.T) {
+	rsr := whatIfTestRequest()
+	for _, change := range []struct{ path, value string }{
+		{"raid.buffs.gift_of_the_wild", `"TristateEffectImproved"`},
+		{"raid.parties.0.players.0.consumes.flask", "2"},
+		{"raid.parties.0.players.0.name", `"Renamed"`},
+		{"encounter.duration", "30"},
+	} {
+		if err := setProtoField(rsr.ProtoReflect(), change.path, change.value); err != nil {
+			t.Fatalf("Failed to set %s: %s", change.path, err)
+		}
+	}
+
+	player := rsr.Raid.Parties[0].Players[0]
+	if rsr.Raid.Buffs.GiftOfTheWild != proto.TristateEffect_TristateEffectImproved ||
+		player.Consumes.Flask != proto.Flask(2) ||
+		player.Name != "Renamed" ||
+		rsr.Encounter.Duration != 30 {
+		t.Errorf("Fields weren't set: %v", rsr)
+	}
+
+	for _, path := range []string{
+		"raid.buffs.not_a_buff",
+		"raid.parties.1.players.0.name",
+		"raid.parties.0.players.0.warlock.rotation.corruption", // Not the player's spec.
+		"raid.parties.0",
+	} {
+		if err := setProtoField(rsr.ProtoReflect(), path, "true"); err == nil {
+			t.Errorf("Expected an error setting %s", path)
+		}
+	}
+}
+
+func TestWhatIf(t *testing.T) {
+	result := WhatIf(context.Background(), &proto.WhatIfRequest{
+		Base: whatIfTestRequest(),
+		Deltas: []*proto.WhatIfDelta{
+			{Change: &proto.WhatIfDelta_Field{Field: &proto.WhatIfFieldChange{Path: "raid.parties.0.players.0.name", Value: `"Renamed"`}}},
+			{Change: &proto.WhatIfDelta_Field{Field: &proto.WhatIfFieldChange{Path: "raid.buffs.not_a_buff", Value: "true"}}},
+			{Label: "Helm", Change: &proto.WhatIfDelta_Item{Item: &proto.WhatIfItemSwap{
+				PlayerIndex: 0,
+				Item:        &proto.ItemSpecWithSlot{Item: &proto.ItemSpec{}, Slot: proto.ItemSlot_ItemSlotHead},
+			}}},
+		},
+	}, nil)
+
+	if result.ErrorResult != "" {
+		t.Fatalf("What-if failed: %s", result.ErrorResult)
+	}
+	if len(result.Deltas) != 3 {
+		t.Fatalf("Expected 3 delta results, got %d", len(result.Deltas))
+	}
+
+	// Renaming a player doesn't change anything, so with shared seeds every
+	// iteration is identical to the base.
+	if renamed := result.Deltas[0]; renamed.ErrorResult != "" || renamed.DpsDelta != 0 || renamed.Significant {
+		t.Errorf("Expected no change from renaming, got %v", renamed)
+	}
+	if renamed := result.Deltas[0]; renamed.Label != `raid.parties.0.players.0.name = "Renamed"` {
+		t.Errorf("Unexpected default label %q", renamed.Label)
+	}
+	if invalid := result.Deltas[1]; invalid.ErrorResult == "" {
+		t.Errorf("Expected an error from an invalid path")
+	}
+	if helm := result.Deltas[2]; helm.ErrorResult != "" || helm.Label != "Helm" {
+		t.Errorf("Unexpected item swap result %v", helm)
+	}
+}
@@ -77,10 +77,8 @@ var bloodwormPetBaseStats = stats.Stats{
 }
 
 func (dk *Deathknight) bloodwormStatInheritance() core.PetStatInheritance {
-	return func(ownerStats stats.Stats) stats.Stats {
-		return stats.Stats{
-			stats.AttackPower: ownerStats[stats.AttackPower] * 0.112,
-			stats.MeleeHaste:  ownerStats[stats.MeleeHaste],
-		}
-	}
+	return core.NewPetStatInheritance(
+		core.PetStatRatio{OwnerStat: stats.AttackPower, PetStat: stats.AttackPower, Ratio: 0.112},
+		core.PetStatRatio{OwnerStat: stats.MeleeHaste, PetStat: stats.MeleeHaste, Ratio: 1},
+	)
 }
@@ -0,0 +1,34 @@
+package deathknight
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func (dk *Deathknight) registerDarkCommandSpell() {
+	dk.DarkCommand = dk.RegisterSpell(core.SpellConfig{
+		ActionID:    core.ActionID{SpellID: 56222},
+		SpellSchool: core.SpellSchoolShadow,
+		ProcMask:    core.ProcMaskEmpty,
+		Flags:       core.SpellFlagAPL,
+
+		Cast: core.CastConfig{
+			CD: core.Cooldown{
+				Timer:    dk.NewTimer(),
+				Duration: time.Second * 8,
+			},
+		},
+
+		BonusHitRating:   core.TernaryFloat64(dk.HasMajorGlyph(proto.DeathknightMajorGlyph_GlyphOfDarkCommand), 8*core.SpellHitRatingPerHitChance, 0),
+		ThreatMultiplier: 1,
+
+		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
+			result := spell.CalcAndDealOutcome(sim, target, spell.OutcomeMagicHit)
+			if result.Landed() {
+				dk.ApplyTaunt(sim, target)
+			}
+		},
+	})
+}
@@ -13,11 +13,6 @@ func (dk *Deathknight) newDeathStrikeSpell(isMH bool) *core.Spell {
 	hasGlyph := dk.HasMajorGlyph(proto.DeathknightMajorGlyph_GlyphOfDeathStrike)
 	deathConvertChance := float64(dk.Talents.DeathRuneMastery) / 3
 
-	var healthMetrics *core.ResourceMetrics
-	if isMH {
-		healthMetrics = dk.NewHealthMetrics(DeathStrikeActionID)
-	}
-
 	conf := core.SpellConfig{
 		ActionID:    DeathStrikeActionID.WithTag(core.TernaryInt32(isMH, 1, 2)),
 		SpellSchool: core.SpellSchoolPhysical,
@@ -70,7 +65,7 @@ func (dk *Deathknight) newDeathStrikeSpell(isMH bool) *core.Spell {
 
 				if result.Landed() {
 					healingAmount := 0.05 * dk.dkCountActiveDiseases(target) * dk.MaxHealth() * (1.0 + 0.5*float64(dk.Talents.ImprovedDeathStrike))
-					dk.GainHealth(sim, healingAmount*dk.PseudoStats.HealingTakenMultiplier, healthMetrics)
+					result.HealthReturn = healingAmount
 					dk.DeathStrikeHeals = append(dk.DeathStrikeHeals, healingAmount)
 				}
 
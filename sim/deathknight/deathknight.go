@@ -96,8 +96,6 @@ type Deathknight struct {
 
 	Bloodworm []*BloodwormPet
 
-	Presence Presence
-
 	IcyTouch   *core.Spell
 	BloodBoil  *core.Spell
 	Pestilence *core.Spell
@@ -141,6 +139,8 @@ type Deathknight struct {
 
 	DeathCoil *core.Spell
 
+	DarkCommand *core.Spell
+
 	DeathAndDecay *core.Spell
 
 	HowlingBlast *core.Spell
@@ -306,6 +306,7 @@ func (dk *Deathknight) Initialize() {
 	dk.registerAntiMagicShellSpell()
 	dk.registerRuneStrikeSpell()
 	dk.registerMindFreeze()
+	dk.registerDarkCommandSpell()
 
 	dk.registerRaiseDeadCD()
 	dk.registerSummonGargoyleCD()
@@ -329,15 +330,11 @@ func (dk *Deathknight) Initialize() {
 
 	if !dk.IsUsingAPL {
 		if dk.Inputs.PrecastHornOfWinter {
-			dk.RegisterPrepullAction(-1500*time.Millisecond, func(sim *core.Simulation) {
-				dk.HornOfWinter.Cast(sim, nil)
-			})
+			dk.RegisterPrepullSpell(-1500*time.Millisecond, dk.HornOfWinter, nil)
 		}
 
 		if dk.Inputs.ArmyOfTheDeadType == proto.Deathknight_Rotation_PreCast {
-			dk.RegisterPrepullAction(-10*time.Second, func(sim *core.Simulation) {
-				dk.ArmyOfTheDead.Cast(sim, nil)
-			})
+			dk.RegisterPrepullSpell(-10*time.Second, dk.ArmyOfTheDead, nil)
 		}
 	}
 
@@ -526,8 +526,6 @@ func (dk *DpsDeathknight) Reset(sim *core.Simulation) {
 	dk.RotationSequence.Clear()
 	dk.rotationSetup()
 
-	dk.Presence = deathknight.UnsetPresence
-
 	b, f, u := deathknight.PointsInTalents(dk.Talents)
 
 	if dk.IsUsingAPL {
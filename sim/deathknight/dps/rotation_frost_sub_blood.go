@@ -195,7 +195,7 @@ func (dk *DpsDeathknight) RotationActionCallback_FrostSubBlood_FS_Dump(sim *core
 			} else {
 				if dk.canCastFrostUnholySpell(sim, target) {
 					dndIn := max(bAt-sim.CurrentTime, dk.DeathAndDecay.TimeToReady(sim))
-					if dk.Rotation.UseDeathAndDecay && dk.Env.GetNumTargets() >= 3 && dndIn < 3*time.Second && !dk.UnbreakableArmorAura.IsActive() && dk.UnbreakableArmor.TimeToReady(sim) > 8*time.Second {
+					if dk.Rotation.UseDeathAndDecay && sim.GetNumActiveTargets() >= 3 && dndIn < 3*time.Second && !dk.UnbreakableArmorAura.IsActive() && dk.UnbreakableArmor.TimeToReady(sim) > 8*time.Second {
 						if dk.DeathAndDecay.CanCast(sim, target) {
 							casted = dk.DeathAndDecay.Cast(sim, target)
 							dk.fr.oblitCount += 1
@@ -86,14 +86,14 @@ func (dk *DpsDeathknight) shRecastAvailableCheck(expiresAt time.Duration, afterC
 	return false
 }
 
-func (dk *DpsDeathknight) shShouldSpreadDisease(_ *core.Simulation) bool {
-	prioritizeSpread := dk.Env.GetNumTargets() > 1
+func (dk *DpsDeathknight) shShouldSpreadDisease(sim *core.Simulation) bool {
+	numTargets := sim.GetNumActiveTargets()
+	prioritizeSpread := numTargets > 1
 
 	// on 2 or 3 targets, we don't want to spread if we have diseases up on all targets already (to maximize Desolation uptime)
 	// on 4+ targets always spread to maximize disease and Wandering Plague uptime
-	if dk.Env.GetNumTargets() > 1 && dk.Env.GetNumTargets() < 4 {
-		for i := int32(1); i < dk.Env.GetNumTargets(); i++ {
-			target := dk.Env.GetTargetUnit(i)
+	if numTargets > 1 && numTargets < 4 {
+		for _, target := range sim.Encounter.TargetUnits[1:] {
 			if dk.FrostFeverSpell.Dot(target).IsActive() && dk.BloodPlagueSpell.Dot(target).IsActive() {
 				prioritizeSpread = false
 				break
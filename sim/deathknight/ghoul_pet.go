@@ -59,9 +59,7 @@ func (dk *Deathknight) NewArmyGhoulPet(_ int) *GhoulPet {
 		AutoSwingMelee: true,
 	})
 
-	ghoulPet.AddStatDependency(stats.Strength, stats.AttackPower, 1)
-	ghoulPet.AddStatDependency(stats.Agility, stats.AttackPower, 1)
-	ghoulPet.AddStatDependency(stats.Agility, stats.MeleeCrit, core.CritRatingPerCritChance/85.5)
+	ghoulPet.AddMeleeStatDependencies(ghoulMeleeScaling)
 
 	// command doesn't apply to army ghoul
 	if dk.Race == proto.Race_RaceOrc {
@@ -112,9 +110,7 @@ func (dk *Deathknight) NewGhoulPet(permanent bool) *GhoulPet {
 		AutoSwingMelee: true,
 	})
 
-	ghoulPet.AddStatDependency(stats.Strength, stats.AttackPower, 1)
-	ghoulPet.AddStatDependency(stats.Agility, stats.AttackPower, 1)
-	ghoulPet.AddStatDependency(stats.Agility, stats.MeleeCrit, core.CritRatingPerCritChance/85.5)
+	ghoulPet.AddMeleeStatDependencies(ghoulMeleeScaling)
 
 	if permanent {
 		core.ApplyPetConsumeEffects(&ghoulPet.Character, dk.Consumes)
@@ -126,11 +122,7 @@ func (dk *Deathknight) NewGhoulPet(permanent bool) *GhoulPet {
 func (dk *Deathknight) SetupGhoul(ghoulPet *GhoulPet) {
 	ghoulPet.Pet.OnPetEnable = ghoulPet.enable
 
-	ghoulPet.Unit.EnableFocusBar(2, func(sim *core.Simulation) {
-		if ghoulPet.GCD.IsReady(sim) {
-			ghoulPet.OnGCDReady(sim)
-		}
-	})
+	ghoulPet.Unit.EnableFocusBar(2, ghoulPet.ResumeOnFocusGain(ghoulPet))
 
 	dk.AddPet(ghoulPet)
 }
@@ -185,6 +177,12 @@ func (ghoulPet *GhoulPet) enable(sim *core.Simulation) {
 	})
 }
 
+var ghoulMeleeScaling = core.PetMeleeScaling{
+	AttackPowerPerStrength: 1,
+	AttackPowerPerAgility:  1,
+	AgilityPerCritPercent:  85.5,
+}
+
 func (dk *Deathknight) ghoulStatInheritance() core.PetStatInheritance {
 	ravenousDead := 1.0 + 0.2*float64(dk.Talents.RavenousDead)
 	glyphBonus := 0.0
@@ -194,31 +192,27 @@ func (dk *Deathknight) ghoulStatInheritance() core.PetStatInheritance {
 
 	baseStatsScale := glyphBonus + 0.7*ravenousDead
 
-	return func(ownerStats stats.Stats) stats.Stats {
-		return stats.Stats{
-			stats.Stamina:  ownerStats[stats.Stamina] * baseStatsScale,
-			stats.Strength: ownerStats[stats.Strength] * baseStatsScale,
+	return core.NewPetStatInheritance(
+		core.PetStatRatio{OwnerStat: stats.Stamina, PetStat: stats.Stamina, Ratio: baseStatsScale},
+		core.PetStatRatio{OwnerStat: stats.Strength, PetStat: stats.Strength, Ratio: baseStatsScale},
 
-			stats.MeleeHit:  ownerStats[stats.MeleeHit],
-			stats.Expertise: ownerStats[stats.MeleeHit] * PetExpertiseScale,
+		core.PetStatRatio{OwnerStat: stats.MeleeHit, PetStat: stats.MeleeHit, Ratio: 1},
+		core.PetStatRatio{OwnerStat: stats.MeleeHit, PetStat: stats.Expertise, Ratio: PetExpertiseScale},
 
-			stats.MeleeHaste: ownerStats[stats.MeleeHaste],
-		}
-	}
+		core.PetStatRatio{OwnerStat: stats.MeleeHaste, PetStat: stats.MeleeHaste, Ratio: 1},
+	)
 }
 
 func (dk *Deathknight) armyGhoulStatInheritance() core.PetStatInheritance {
-	return func(ownerStats stats.Stats) stats.Stats {
-		return stats.Stats{
-			stats.Stamina:     ownerStats[stats.Stamina] * 0.2,
-			stats.AttackPower: ownerStats[stats.AttackPower] * 0.065,
+	return core.NewPetStatInheritance(
+		core.PetStatRatio{OwnerStat: stats.Stamina, PetStat: stats.Stamina, Ratio: 0.2},
+		core.PetStatRatio{OwnerStat: stats.AttackPower, PetStat: stats.AttackPower, Ratio: 0.065},
 
-			stats.MeleeHit:  ownerStats[stats.MeleeHit],
-			stats.Expertise: ownerStats[stats.MeleeHit] * PetExpertiseScale,
+		core.PetStatRatio{OwnerStat: stats.MeleeHit, PetStat: stats.MeleeHit, Ratio: 1},
+		core.PetStatRatio{OwnerStat: stats.MeleeHit, PetStat: stats.Expertise, Ratio: PetExpertiseScale},
 
-			stats.MeleeHaste: ownerStats[stats.MeleeHaste],
-		}
-	}
+		core.PetStatRatio{OwnerStat: stats.MeleeHaste, PetStat: stats.MeleeHaste, Ratio: 1},
+	)
 }
 
 func (ghoulPet *GhoulPet) registerClaw() *core.Spell {
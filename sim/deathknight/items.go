@@ -513,7 +513,7 @@ func (dk *Deathknight) registerItems() {
 		},
 		OnSpellHitDealt: func(aura *core.Aura, sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
 			if spell.ActionID == HowlingBlastActionID || spell.ActionID == BloodBoilActionID {
-				if result.Target.Index == sim.GetNumTargets()-1 {
+				if targetUnits := sim.Encounter.TargetUnits; result.Target == targetUnits[len(targetUnits)-1] {
 					// Last target, consume a stack for every target hit
 					for i := int32(0); i < dk.AoESpellNumTargetsHit; i++ {
 						if aura.IsActive() {
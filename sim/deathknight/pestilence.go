@@ -109,7 +109,7 @@ func (dk *Deathknight) registerDrwPestilenceSpell() {
 			// DRW and Pestilence have a weird interaction where the drws Dots can be applied
 			// with the spread effect from pestilence if the target has the Dks dots up but it
 			// only works if there is a valid target for spread mechanic to happen (2+ mobs)
-			shouldApplyDrwDots := dk.Env.GetNumTargets() > 1 || dk.Inputs.DrwPestiApply
+			shouldApplyDrwDots := sim.GetNumActiveTargets() > 1 || dk.Inputs.DrwPestiApply
 			for _, aoeTarget := range sim.Encounter.TargetUnits {
 				// Zero damage spell with a Hit mechanic, thanks blizz!
 				result := spell.CalcAndDealDamage(sim, aoeTarget, 0, spell.OutcomeMagicHit)
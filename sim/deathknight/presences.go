@@ -7,40 +7,23 @@ import (
 	"github.com/wowsims/wotlk/sim/core/stats"
 )
 
-type Presence uint8
+type Presence = core.Stance
 
 const (
 	BloodPresence Presence = 1 << iota
 	FrostPresence
 	UnholyPresence
-	UnsetPresence
 )
 
-const presenceEffectCategory = "Presence"
-
 func (dk *Deathknight) PresenceMatches(other Presence) bool {
-	return (dk.Presence & other) != 0
+	return dk.StanceMatches(other)
 }
 
 func (dk *Deathknight) ChangePresence(sim *core.Simulation, newPresence Presence) {
 	if dk.PresenceMatches(newPresence) {
 		return
 	}
-
-	dk.Presence = newPresence
-	if dk.PresenceMatches(BloodPresence) {
-		dk.BloodPresenceAura.Activate(sim)
-		dk.FrostPresenceAura.Deactivate(sim)
-		dk.UnholyPresenceAura.Deactivate(sim)
-	} else if dk.PresenceMatches(FrostPresence) {
-		dk.FrostPresenceAura.Activate(sim)
-		dk.BloodPresenceAura.Deactivate(sim)
-		dk.UnholyPresenceAura.Deactivate(sim)
-	} else if dk.PresenceMatches(UnholyPresence) {
-		dk.UnholyPresenceAura.Activate(sim)
-		dk.BloodPresenceAura.Deactivate(sim)
-		dk.FrostPresenceAura.Deactivate(sim)
-	}
+	dk.SetStance(sim, newPresence)
 }
 
 func (dk *Deathknight) registerBloodPresenceAura(timer *core.Timer) {
@@ -105,7 +88,7 @@ func (dk *Deathknight) registerBloodPresenceAura(timer *core.Timer) {
 	}
 
 	dk.BloodPresenceAura = dk.GetOrRegisterAura(aura)
-	dk.BloodPresenceAura.NewExclusiveEffect(presenceEffectCategory, true, core.ExclusiveEffect{})
+	dk.RegisterStance(BloodPresence, dk.BloodPresenceAura)
 }
 
 func (dk *Deathknight) registerFrostPresenceAura(timer *core.Timer) {
@@ -154,7 +137,7 @@ func (dk *Deathknight) registerFrostPresenceAura(timer *core.Timer) {
 			dk.IcyTouch.ThreatMultiplier /= 7
 		},
 	})
-	dk.FrostPresenceAura.NewExclusiveEffect(presenceEffectCategory, true, core.ExclusiveEffect{})
+	dk.RegisterStance(FrostPresence, dk.FrostPresenceAura)
 
 	if !dk.Inputs.IsDps && dk.Talents.ImprovedBloodPresence > 0 {
 		healFactor := 0.02 * float64(dk.Talents.ImprovedBloodPresence)
@@ -228,7 +211,7 @@ func (dk *Deathknight) registerUnholyPresenceAura(timer *core.Timer) {
 				spell.DefaultCast.GCD = time.Second
 			}
 			if dk.Talents.ImprovedUnholyPresence > 0 {
-				aura.Unit.SetRuneCd(runeCd - impUp)
+				aura.Unit.SetRuneCd(sim, runeCd-impUp)
 			}
 			aura.Unit.PseudoStats.ThreatMultiplier *= threatMultSubversion
 			aura.Unit.EnableDynamicStatDep(sim, stamDep)
@@ -239,14 +222,14 @@ func (dk *Deathknight) registerUnholyPresenceAura(timer *core.Timer) {
 				spell.DefaultCast.GCD = core.GCDDefault
 			}
 			if dk.Talents.ImprovedUnholyPresence > 0 {
-				aura.Unit.SetRuneCd(runeCd)
+				aura.Unit.SetRuneCd(sim, runeCd)
 			}
 			aura.Unit.PseudoStats.ThreatMultiplier /= threatMultSubversion
 			aura.Unit.DisableDynamicStatDep(sim, stamDep)
 			dk.MultiplyMeleeSpeed(sim, 1/1.15)
 		},
 	})
-	dk.UnholyPresenceAura.NewExclusiveEffect(presenceEffectCategory, true, core.ExclusiveEffect{})
+	dk.RegisterStance(UnholyPresence, dk.UnholyPresenceAura)
 
 	if !dk.Inputs.IsDps && dk.Talents.ImprovedBloodPresence > 0 {
 		healFactor := 0.02 * float64(dk.Talents.ImprovedBloodPresence)
@@ -106,15 +106,13 @@ func (dk *Deathknight) registerRuneStrikeSpell() {
 		Duration: 6 * time.Second,
 	})
 
-	core.MakePermanent(dk.GetOrRegisterAura(core.Aura{
-		Label:    "Rune Strike Trigger",
-		Duration: core.NeverExpires,
-		OnSpellHitTaken: func(aura *core.Aura, sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
-			if result.Outcome.Matches(core.OutcomeDodge | core.OutcomeParry) {
-				dk.RuneStrikeAura.Activate(sim)
-			}
+	dk.RegisterDamageTakenGain(core.DamageTakenGain{
+		Label:   "Rune Strike Trigger",
+		Outcome: core.OutcomeDodge | core.OutcomeParry,
+		OnGain: func(sim *core.Simulation, _ *core.Spell, _ *core.SpellResult, _ float64) {
+			dk.RuneStrikeAura.Activate(sim)
 		},
-	}))
+	})
 }
 
 func (dk *Deathknight) registerDrwRuneStrikeSpell() {
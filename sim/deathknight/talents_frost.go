@@ -127,7 +127,7 @@ func (dk *Deathknight) applyRime() {
 		Duration: time.Second * 15,
 		OnGain: func(aura *core.Aura, sim *core.Simulation) {
 			if dk.HowlingBlast != nil {
-				dk.HowlingBlast.CD.Reset()
+				dk.HowlingBlast.ResetCooldown(sim, aura.ActionID)
 				// No rune cost AND no runic power regen.
 				dk.HowlingBlast.CostMultiplier -= 1
 			}
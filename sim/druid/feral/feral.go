@@ -47,7 +47,7 @@ func NewFeralDruid(character *core.Character, options *proto.Player) *FeralDruid
 
 	cat.EnableEnergyBar(100.0, cat.OnEnergyGain)
 
-	cat.EnableRageBar(core.RageBarOptions{RageMultiplier: 1, MHSwingSpeed: 2.5}, func(sim *core.Simulation) {})
+	cat.EnableRageBar(core.RageBarOptions{MHSwingSpeed: 2.5}, func(sim *core.Simulation) {})
 
 	cat.EnableAutoAttacks(cat, core.AutoAttackOptions{
 		// Base paw weapon.
@@ -101,15 +101,11 @@ func (cat *FeralDruid) Initialize() {
 	}
 
 	if cat.prepopOoc && cat.Talents.OmenOfClarity {
-		cat.RegisterPrepullAction(-time.Second, func(sim *core.Simulation) {
-			cat.ProcOoc(sim)
-		})
+		cat.RegisterPrepullAura(-time.Second, cat.ClearcastingAura)
 	}
 
 	if cat.PrePopBerserk && cat.Talents.Berserk {
-		cat.RegisterPrepullAction(-time.Second, func(sim *core.Simulation) {
-			cat.Berserk.Cast(sim, nil)
-		})
+		cat.RegisterPrepullSpell(-time.Second, cat.Berserk.Spell, nil)
 	}
 }
 
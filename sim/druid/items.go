@@ -188,14 +188,9 @@ var ItemSetGladiatorsWildhide = core.NewItemSet(core.ItemSet{
 
 var ItemSetGladiatorsSanctuary = core.NewItemSet(core.ItemSet{
 	Name: "Gladiator's Sanctuary",
-	Bonuses: map[int32]core.ApplyEffect{
-		2: func(agent core.Agent) {
-			agent.GetCharacter().AddStat(stats.Resilience, 100)
-			agent.GetCharacter().AddStat(stats.AttackPower, 50)
-		},
-		4: func(agent core.Agent) {
-			agent.GetCharacter().AddStat(stats.AttackPower, 150)
-		},
+	Records: map[int32]core.SetBonusRecord{
+		2: {Stats: stats.Stats{stats.AttackPower: 50, stats.Resilience: 100}},
+		4: {Stats: stats.Stats{stats.AttackPower: 150}},
 	},
 })
 
@@ -55,7 +55,7 @@ func (druid *Druid) registerMangleBearSpell() {
 			}
 
 			if druid.BerserkAura.IsActive() {
-				spell.CD.Reset()
+				spell.ResetCooldown(sim, druid.BerserkAura.ActionID)
 			}
 		},
 
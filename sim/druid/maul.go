@@ -45,6 +45,7 @@ func (druid *Druid) registerMaulSpell(rageThreshold float64) {
 				modifier *= 1.0 + (0.04 * float64(druid.Talents.RendAndTear))
 			}
 
+			numHits := min(numHits, sim.GetNumActiveTargets())
 			curTarget := target
 			for hitIndex := int32(0); hitIndex < numHits; hitIndex++ {
 				baseDamage := flatBaseDamage +
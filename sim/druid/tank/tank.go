@@ -39,9 +39,8 @@ func NewFeralTankDruid(character *core.Character, options *proto.Player) *FeralT
 	}
 
 	rbo := core.RageBarOptions{
-		StartingRage:   bear.Options.StartingRage,
-		RageMultiplier: 1,
-		MHSwingSpeed:   2.5,
+		StartingRage: bear.Options.StartingRage,
+		MHSwingSpeed: 2.5,
 	}
 
 	bear.EnableRageBar(rbo, func(sim *core.Simulation) {
@@ -0,0 +1,32 @@
+package hunter
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func (hunter *Hunter) registerFeignDeathSpell() {
+	hunter.FeignDeath = hunter.RegisterSpell(core.SpellConfig{
+		ActionID: core.ActionID{SpellID: 5384},
+		Flags:    core.SpellFlagAPL,
+
+		ManaCost: core.ManaCostOptions{
+			BaseCost: 0.03,
+		},
+		Cast: core.CastConfig{
+			CD: core.Cooldown{
+				Timer:    hunter.NewTimer(),
+				Duration: time.Second*30 - core.TernaryDuration(hunter.HasMinorGlyph(proto.HunterMinorGlyph_GlyphOfFeignDeath), time.Second*5, 0),
+			},
+		},
+
+		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
+			// Drops all threat on every target.
+			for _, target := range sim.Encounter.TargetUnits {
+				hunter.AddThreat(sim, target, -hunter.GetThreat(target))
+			}
+		},
+	})
+}
@@ -62,8 +62,10 @@ type Hunter struct {
 	ExplosiveShotR4 *core.Spell
 	ExplosiveShotR3 *core.Spell
 	ExplosiveTrap   *core.Spell
+	FeignDeath      *core.Spell
 	KillCommand     *core.Spell
 	KillShot        *core.Spell
+	Misdirection    *core.Spell
 	MultiShot       *core.Spell
 	RapidFire       *core.Spell
 	RaptorStrike    *core.Spell
@@ -134,7 +136,9 @@ func (hunter *Hunter) Initialize() {
 	hunter.registerChimeraShotSpell()
 	hunter.registerExplosiveShotSpell(arcaneShotTimer)
 	hunter.registerExplosiveTrapSpell(fireTrapTimer)
+	hunter.registerFeignDeathSpell()
 	hunter.registerKillShotSpell()
+	hunter.registerMisdirectionSpell()
 	hunter.registerMultiShotSpell(multiShotTimer)
 	hunter.registerRaptorStrikeSpell()
 	hunter.registerScorpidStingSpell()
@@ -157,10 +161,8 @@ func (hunter *Hunter) Initialize() {
 	}
 
 	if hunter.Options.UseHuntersMark {
-		hunter.RegisterPrepullAction(0, func(sim *core.Simulation) {
-			huntersMarkAura := core.HuntersMarkAura(hunter.CurrentTarget, hunter.Talents.ImprovedHuntersMark, hunter.HasMajorGlyph(proto.HunterMajorGlyph_GlyphOfHuntersMark))
-			huntersMarkAura.Activate(sim)
-		})
+		huntersMarkAura := core.HuntersMarkAura(hunter.CurrentTarget, hunter.Talents.ImprovedHuntersMark, hunter.HasMajorGlyph(proto.HunterMajorGlyph_GlyphOfHuntersMark))
+		hunter.RegisterPrepullAura(0, huntersMarkAura)
 	}
 }
 
@@ -1,8 +1,11 @@
 package hunter
 
 import (
+	"strings"
 	"testing"
 
+	googleProto "google.golang.org/protobuf/proto"
+
 	_ "github.com/wowsims/wotlk/sim/common" // imported to get item effects included.
 	"github.com/wowsims/wotlk/sim/core"
 	"github.com/wowsims/wotlk/sim/core/proto"
@@ -89,8 +92,8 @@ var ItemFilter = core.ItemFilter{
 	},
 }
 
-func BenchmarkSimulate(b *testing.B) {
-	rsr := &proto.RaidSimRequest{
+func benchmarkRequest() *proto.RaidSimRequest {
+	return &proto.RaidSimRequest{
 		Raid: core.SinglePlayerRaidProto(
 			&proto.Player{
 				Race:          proto.Race_RaceOrc,
@@ -113,8 +116,67 @@ func BenchmarkSimulate(b *testing.B) {
 		},
 		SimOptions: core.AverageDefaultSimTestOptions,
 	}
+}
 
-	core.RaidBenchmark(b, rsr)
+func BenchmarkSimulate(b *testing.B) {
+	core.RaidBenchmark(b, benchmarkRequest())
+}
+
+func BenchmarkConcurrency(b *testing.B) {
+	core.RaidConcurrencyBenchmark(b, benchmarkRequest())
+}
+
+func TestConcurrencyParity(t *testing.T) {
+	core.ConcurrencyParityTest(t, benchmarkRequest())
+}
+
+func TestPetFamilies(t *testing.T) {
+	for petType := range proto.Hunter_Options_PetType_name {
+		petType := proto.Hunter_Options_PetType(petType)
+		if petType == proto.Hunter_Options_PetNone {
+			continue
+		}
+		config, ok := PetConfigs[petType]
+		if !ok {
+			t.Fatalf("No pet config for %s", petType)
+		}
+		for _, ability := range []PetAbilityType{config.SpecialAbility, config.FocusDump} {
+			if _, ok := PetAbilities[ability]; ability != Unknown && !ok {
+				t.Fatalf("No ability data for %s's ability %d", config.Name, ability)
+			}
+		}
+
+		t.Run(config.Name, func(t *testing.T) {
+			request := benchmarkRequest()
+			request.SimOptions = &proto.SimOptions{Iterations: 1, RandomSeed: 101}
+			player := request.Raid.Parties[0].Players[0]
+			player.Spec = &proto.Player_Hunter{Hunter: googleProto.Clone(PlayerOptionsBasic.Hunter).(*proto.Hunter)}
+			player.GetHunter().Options.PetType = petType
+
+			result := core.RunRaidSim(request)
+			if result.ErrorResult != "" {
+				t.Fatalf("Sim failed: %s", result.ErrorResult)
+			}
+			if pets := result.RaidMetrics.Parties[0].Players[0].Pets; len(pets) == 0 || pets[0].Dps.Avg <= 0 {
+				t.Fatalf("Expected %s to do damage", config.Name)
+			}
+		})
+	}
+}
+
+func TestPetTalentTreeFilter(t *testing.T) {
+	filtered, removed := PetTenacity.filterTalents(FerocityTalents)
+	if filtered.SpikedCollar != 3 || filtered.CobraReflexes != 2 || filtered.Rabid || filtered.Dive {
+		t.Fatalf("Unexpected filtered talents: %v", filtered)
+	}
+	expected := []string{"dive", "spiders_bite", "rabid", "call_of_the_wild"}
+	if strings.Join(removed, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected %v to be removed, got %v", expected, removed)
+	}
+
+	if _, removed := PetFerocity.filterTalents(FerocityTalents); len(removed) != 0 {
+		t.Fatalf("Expected no ferocity talents to be removed, got %v", removed)
+	}
 }
 
 var FullConsumes = &proto.Consumes{
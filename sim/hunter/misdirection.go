@@ -0,0 +1,62 @@
+package hunter
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core"
+)
+
+func (hunter *Hunter) registerMisdirectionSpell() {
+	if hunter.Options.MisdirectionTarget == nil {
+		return
+	}
+	targetUnit := hunter.GetUnit(hunter.Options.MisdirectionTarget)
+	if targetUnit == nil {
+		return
+	}
+
+	actionID := core.ActionID{SpellID: 34477}
+
+	misdirectionAura := hunter.RegisterAura(core.Aura{
+		Label:     "Misdirection",
+		ActionID:  actionID,
+		Duration:  time.Second * 30,
+		MaxStacks: 3,
+		OnGain: func(aura *core.Aura, sim *core.Simulation) {
+			aura.SetStacks(sim, aura.MaxStacks)
+			hunter.RedirectThreat(targetUnit)
+		},
+		OnExpire: func(aura *core.Aura, sim *core.Simulation) {
+			hunter.RedirectThreat(nil)
+		},
+		OnSpellHitDealt: func(aura *core.Aura, sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
+			// Threat from the attack has already been redirected at this point.
+			if !result.Landed() || !spell.ProcMask.Matches(core.ProcMaskDirect) {
+				return
+			}
+			aura.RemoveStack(sim)
+			if aura.GetStacks() == 0 {
+				aura.Deactivate(sim)
+			}
+		},
+	})
+
+	hunter.Misdirection = hunter.RegisterSpell(core.SpellConfig{
+		ActionID: actionID,
+		Flags:    core.SpellFlagAPL,
+
+		ManaCost: core.ManaCostOptions{
+			BaseCost: 0.09,
+		},
+		Cast: core.CastConfig{
+			CD: core.Cooldown{
+				Timer:    hunter.NewTimer(),
+				Duration: time.Second * 30,
+			},
+		},
+
+		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
+			misdirectionAura.Activate(sim)
+		},
+	})
+}
@@ -53,6 +53,7 @@ func (hunter *Hunter) registerMultiShotSpell(timer *core.Timer) {
 				spell.BonusWeaponDamage() +
 				408
 
+			numHits := min(numHits, sim.GetNumActiveTargets())
 			curTarget := target
 			for hitIndex := int32(0); hitIndex < numHits; hitIndex++ {
 				baseDamage := sharedDmg + 0.2*spell.RangedAttackPower(curTarget)
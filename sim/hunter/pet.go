@@ -31,6 +31,7 @@ func (hunter *Hunter) NewHunterPet() *HunterPet {
 		return nil
 	}
 	petConfig := PetConfigs[hunter.Options.PetType]
+	hunter.applyPetTalentTree(petConfig)
 
 	hp := &HunterPet{
 		Pet:         core.NewPet(petConfig.Name, &hunter.Character, hunterPetBaseStats, hunter.makeStatInheritance(), true, false),
@@ -40,11 +41,7 @@ func (hunter *Hunter) NewHunterPet() *HunterPet {
 		hasOwnerCooldown: petConfig.SpecialAbility == FuriousHowl || petConfig.SpecialAbility == SavageRend,
 	}
 
-	hp.EnableFocusBar(1.0+0.5*float64(hunter.Talents.BestialDiscipline), func(sim *core.Simulation) {
-		if hp.GCD.IsReady(sim) {
-			hp.OnGCDReady(sim)
-		}
-	})
+	hp.EnableFocusBar(1.0+0.5*float64(hunter.Talents.BestialDiscipline), hp.ResumeOnFocusGain(hp))
 
 	atkSpd := 2 / (1 + 0.15*float64(hp.Talents().CobraReflexes))
 	hp.EnableAutoAttacks(hp, core.AutoAttackOptions{
@@ -63,8 +60,10 @@ func (hunter *Hunter) NewHunterPet() *HunterPet {
 	// Pet family bonus is now the same for all pets.
 	hp.PseudoStats.SchoolDamageDealtMultiplier[stats.SchoolIndexPhysical] *= 1.05
 
-	hp.AddStatDependency(stats.Strength, stats.AttackPower, 2)
-	hp.AddStatDependency(stats.Agility, stats.MeleeCrit, core.CritRatingPerCritChance/62.77)
+	hp.AddMeleeStatDependencies(core.PetMeleeScaling{
+		AttackPowerPerStrength: 2,
+		AgilityPerCritPercent:  62.77,
+	})
 	core.ApplyPetConsumeEffects(&hp.Character, hunter.Consumes)
 
 	hunter.AddPet(hp)
@@ -188,178 +187,3 @@ func (hunter *Hunter) makeStatInheritance() core.PetStatInheritance {
 		}
 	}
 }
-
-type PetConfig struct {
-	Name string
-
-	SpecialAbility PetAbilityType
-	FocusDump      PetAbilityType
-
-	// Randomly select between abilities instead of using a prio.
-	RandomSelection bool
-}
-
-// Abilities reference: https://wotlk.wowhead.com/hunter-pets
-// https://wotlk.wowhead.com/guides/hunter-dps-best-pets-taming-loyalty-burning-crusade-classic
-var PetConfigs = map[proto.Hunter_Options_PetType]PetConfig{
-	proto.Hunter_Options_Bat: {
-		Name:           "Bat",
-		SpecialAbility: SonicBlast,
-		FocusDump:      Claw,
-	},
-	proto.Hunter_Options_Bear: {
-		Name:           "Bear",
-		SpecialAbility: Swipe,
-		FocusDump:      Claw,
-	},
-	proto.Hunter_Options_BirdOfPrey: {
-		Name:           "Bird of Prey",
-		SpecialAbility: Snatch,
-		FocusDump:      Claw,
-	},
-	proto.Hunter_Options_Boar: {
-		Name:           "Boar",
-		SpecialAbility: Gore,
-		FocusDump:      Bite,
-	},
-	proto.Hunter_Options_CarrionBird: {
-		Name:           "Carrion Bird",
-		SpecialAbility: DemoralizingScreech,
-		FocusDump:      Bite,
-	},
-	proto.Hunter_Options_Cat: {
-		Name:           "Cat",
-		SpecialAbility: Rake,
-		FocusDump:      Claw,
-	},
-	proto.Hunter_Options_Chimaera: {
-		Name:           "Chimaera",
-		SpecialAbility: FroststormBreath,
-		FocusDump:      Bite,
-	},
-	proto.Hunter_Options_CoreHound: {
-		Name:           "Core Hound",
-		SpecialAbility: LavaBreath,
-		FocusDump:      Bite,
-	},
-	proto.Hunter_Options_Crab: {
-		Name:           "Crab",
-		SpecialAbility: Pin,
-		FocusDump:      Claw,
-	},
-	proto.Hunter_Options_Crocolisk: {
-		Name: "Crocolisk",
-		//SpecialAbility: BadAttitude,
-		FocusDump: Bite,
-	},
-	proto.Hunter_Options_Devilsaur: {
-		Name:           "Devilsaur",
-		SpecialAbility: MonstrousBite,
-		FocusDump:      Bite,
-	},
-	proto.Hunter_Options_Dragonhawk: {
-		Name:           "Dragonhawk",
-		SpecialAbility: FireBreath,
-		FocusDump:      Bite,
-	},
-	proto.Hunter_Options_Gorilla: {
-		Name: "Gorilla",
-		//SpecialAbility: Pummel,
-		FocusDump: Smack,
-	},
-	proto.Hunter_Options_Hyena: {
-		Name:           "Hyena",
-		SpecialAbility: TendonRip,
-		FocusDump:      Bite,
-	},
-	proto.Hunter_Options_Moth: {
-		Name: "Moth",
-		//SpecialAbility:   SerentiyDust,
-		FocusDump: Smack,
-	},
-	proto.Hunter_Options_NetherRay: {
-		Name:           "Nether Ray",
-		SpecialAbility: NetherShock,
-		FocusDump:      Bite,
-	},
-	proto.Hunter_Options_Raptor: {
-		Name:           "Raptor",
-		SpecialAbility: SavageRend,
-		FocusDump:      Claw,
-	},
-	proto.Hunter_Options_Ravager: {
-		Name:           "Ravager",
-		SpecialAbility: Ravage,
-		FocusDump:      Bite,
-	},
-	proto.Hunter_Options_Rhino: {
-		Name:           "Rhino",
-		SpecialAbility: Stampede,
-		FocusDump:      Bite,
-	},
-	proto.Hunter_Options_Scorpid: {
-		Name:           "Scorpid",
-		SpecialAbility: ScorpidPoison,
-		FocusDump:      Bite,
-	},
-	proto.Hunter_Options_Serpent: {
-		Name:           "Serpent",
-		SpecialAbility: PoisonSpit,
-		FocusDump:      Bite,
-	},
-	proto.Hunter_Options_Silithid: {
-		Name:           "Silithid",
-		SpecialAbility: VenomWebSpray,
-		FocusDump:      Claw,
-	},
-	proto.Hunter_Options_Spider: {
-		Name: "Spider",
-		//SpecialAbility:   Web,
-		FocusDump: Bite,
-	},
-	proto.Hunter_Options_SpiritBeast: {
-		Name:           "Spirit Beast",
-		SpecialAbility: SpiritStrike,
-		FocusDump:      Claw,
-	},
-	proto.Hunter_Options_SporeBat: {
-		Name:           "Spore Bat",
-		SpecialAbility: SporeCloud,
-		FocusDump:      Smack,
-	},
-	proto.Hunter_Options_Tallstrider: {
-		Name: "Tallstrider",
-		//SpecialAbility:   DustCloud,
-		FocusDump: Claw,
-	},
-	proto.Hunter_Options_Turtle: {
-		Name: "Turtle",
-		//SpecialAbility: ShellShield,
-		FocusDump: Bite,
-	},
-	proto.Hunter_Options_WarpStalker: {
-		Name: "Warp Stalker",
-		//SpecialAbility:   Warp,
-		FocusDump: Bite,
-	},
-	proto.Hunter_Options_Wasp: {
-		Name:           "Wasp",
-		SpecialAbility: Sting,
-		FocusDump:      Smack,
-	},
-	proto.Hunter_Options_WindSerpent: {
-		Name:           "Wind Serpent",
-		SpecialAbility: LightningBreath,
-		FocusDump:      Bite,
-	},
-	proto.Hunter_Options_Wolf: {
-		Name:           "Wolf",
-		SpecialAbility: FuriousHowl,
-		FocusDump:      Bite,
-	},
-	proto.Hunter_Options_Worm: {
-		Name:           "Worm",
-		SpecialAbility: AcidSpit,
-		FocusDump:      Bite,
-	},
-}
@@ -50,6 +50,46 @@ const BiteSpellID = 52474
 const ClawSpellID = 52472
 const SmackSpellID = 52476
 
+// Spell ID, focus cost, GCD and cooldown of each ability. Cooldowns are before
+// Longevity.
+type PetAbilityData struct {
+	SpellID int32
+	Cost    float64
+	GCD     time.Duration
+	CD      time.Duration
+}
+
+var PetAbilities = map[PetAbilityType]PetAbilityData{
+	AcidSpit:            {SpellID: 55754, Cost: 20, GCD: PetGCD, CD: time.Second * 10},
+	Bite:                {SpellID: BiteSpellID, Cost: 25, GCD: PetGCD},
+	Claw:                {SpellID: ClawSpellID, Cost: 25, GCD: PetGCD},
+	DemoralizingScreech: {SpellID: 55487, Cost: 20, GCD: PetGCD, CD: time.Second * 10},
+	FireBreath:          {SpellID: 55485, Cost: 20, GCD: PetGCD, CD: time.Second * 10},
+	FuriousHowl:         {SpellID: 64495, Cost: 20, CD: time.Second * 40},
+	FroststormBreath:    {SpellID: 55492, Cost: 20, CD: time.Second * 10},
+	Gore:                {SpellID: 35295, Cost: 20, GCD: PetGCD, CD: time.Second * 10},
+	LavaBreath:          {SpellID: 58611, Cost: 20, GCD: PetGCD, CD: time.Second * 10},
+	LightningBreath:     {SpellID: 25012, Cost: 20, GCD: PetGCD, CD: time.Second * 10},
+	MonstrousBite:       {SpellID: 55499, Cost: 20, GCD: PetGCD, CD: time.Second * 10},
+	NetherShock:         {SpellID: 53589, Cost: 20, GCD: PetGCD, CD: time.Second * 10},
+	Pin:                 {SpellID: 53548, GCD: PetGCD, CD: time.Second * 40},
+	PoisonSpit:          {SpellID: 55557, Cost: 20, GCD: PetGCD, CD: time.Second * 10},
+	Rake:                {SpellID: 59886, Cost: 20, GCD: PetGCD, CD: time.Second * 10},
+	Ravage:              {SpellID: 53562, CD: time.Second * 40},
+	SavageRend:          {SpellID: 53582, Cost: 20, CD: time.Second * 60},
+	ScorpidPoison:       {SpellID: 55728, Cost: 20, GCD: PetGCD, CD: time.Second * 10},
+	Smack:               {SpellID: SmackSpellID, Cost: 25, GCD: PetGCD},
+	Snatch:              {SpellID: 53543, Cost: 20, CD: time.Second * 60},
+	SonicBlast:          {SpellID: 53568, Cost: 80, CD: time.Second * 60},
+	SpiritStrike:        {SpellID: 61198, Cost: 20, CD: time.Second * 10},
+	SporeCloud:          {SpellID: 53598, Cost: 20, GCD: PetGCD, CD: time.Second * 10},
+	Stampede:            {SpellID: 57393, CD: time.Second * 60},
+	Sting:               {SpellID: 56631, Cost: 20, GCD: PetGCD, CD: time.Second * 6},
+	Swipe:               {SpellID: 53533, Cost: 20, GCD: PetGCD, CD: time.Second * 5},
+	TendonRip:           {SpellID: 53575, Cost: 20, CD: time.Second * 20},
+	VenomWebSpray:       {SpellID: 55509, CD: time.Second * 40},
+}
+
 func (hp *HunterPet) NewPetAbility(abilityType PetAbilityType, isPrimary bool) *core.Spell {
 	switch abilityType {
 	case AcidSpit:
@@ -115,19 +155,20 @@ func (hp *HunterPet) NewPetAbility(abilityType PetAbilityType, isPrimary bool) *
 	}
 }
 
-func (hp *HunterPet) newFocusDump(pat PetAbilityType, spellID int32) *core.Spell {
+func (hp *HunterPet) newFocusDump(pat PetAbilityType) *core.Spell {
+	data := PetAbilities[pat]
 	return hp.RegisterSpell(core.SpellConfig{
-		ActionID:    core.ActionID{SpellID: spellID},
+		ActionID:    core.ActionID{SpellID: data.SpellID},
 		SpellSchool: core.SpellSchoolPhysical,
 		ProcMask:    core.ProcMaskMeleeMHSpecial,
 		Flags:       core.SpellFlagMeleeMetrics | core.SpellFlagIncludeTargetBonusDamage,
 
 		FocusCost: core.FocusCostOptions{
-			Cost: 25,
+			Cost: data.Cost,
 		},
 		Cast: core.CastConfig{
 			DefaultCast: core.Cast{
-				GCD: PetGCD,
+				GCD: data.GCD,
 			},
 			IgnoreHaste: true,
 		},
@@ -145,22 +186,18 @@ func (hp *HunterPet) newFocusDump(pat PetAbilityType, spellID int32) *core.Spell
 }
 
 func (hp *HunterPet) newBite() *core.Spell {
-	return hp.newFocusDump(Bite, BiteSpellID)
+	return hp.newFocusDump(Bite)
 }
 func (hp *HunterPet) newClaw() *core.Spell {
-	return hp.newFocusDump(Claw, ClawSpellID)
+	return hp.newFocusDump(Claw)
 }
 func (hp *HunterPet) newSmack() *core.Spell {
-	return hp.newFocusDump(Smack, SmackSpellID)
+	return hp.newFocusDump(Smack)
 }
 
 type PetSpecialAbilityConfig struct {
 	Type    PetAbilityType
-	Cost    float64
-	SpellID int32
 	School  core.SpellSchool
-	GCD     time.Duration
-	CD      time.Duration
 	MinDmg  float64
 	MaxDmg  float64
 	APRatio float64
@@ -198,8 +235,9 @@ func (hp *HunterPet) newSpecialAbility(config PetSpecialAbilityConfig) *core.Spe
 		}
 	}
 
+	data := PetAbilities[config.Type]
 	return hp.RegisterSpell(core.SpellConfig{
-		ActionID:    core.ActionID{SpellID: config.SpellID},
+		ActionID:    core.ActionID{SpellID: data.SpellID},
 		SpellSchool: config.School,
 		ProcMask:    procMask,
 		Flags:       flags,
@@ -209,16 +247,16 @@ func (hp *HunterPet) newSpecialAbility(config PetSpecialAbilityConfig) *core.Spe
 		ThreatMultiplier: 1,
 
 		FocusCost: core.FocusCostOptions{
-			Cost: config.Cost,
+			Cost: data.Cost,
 		},
 		Cast: core.CastConfig{
 			DefaultCast: core.Cast{
-				GCD: config.GCD,
+				GCD: data.GCD,
 			},
 			IgnoreHaste: true,
 			CD: core.Cooldown{
 				Timer:    hp.NewTimer(),
-				Duration: hp.hunterOwner.applyLongevity(config.CD),
+				Duration: hp.hunterOwner.applyLongevity(data.CD),
 			},
 		},
 		Dot:          config.Dot,
@@ -230,10 +268,6 @@ func (hp *HunterPet) newAcidSpit() *core.Spell {
 	acidSpitAuras := hp.NewEnemyAuraArray(core.AcidSpitAura)
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    AcidSpit,
-		Cost:    20,
-		GCD:     PetGCD,
-		CD:      time.Second * 10,
-		SpellID: 55754,
 		School:  core.SpellSchoolNature,
 		MinDmg:  124,
 		MaxDmg:  176,
@@ -255,10 +289,6 @@ func (hp *HunterPet) newDemoralizingScreech() *core.Spell {
 
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    DemoralizingScreech,
-		Cost:    20,
-		GCD:     PetGCD,
-		CD:      time.Second * 10,
-		SpellID: 55487,
 		School:  core.SpellSchoolPhysical,
 		MinDmg:  85,
 		MaxDmg:  129,
@@ -276,10 +306,6 @@ func (hp *HunterPet) newDemoralizingScreech() *core.Spell {
 func (hp *HunterPet) newFireBreath() *core.Spell {
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    FireBreath,
-		Cost:    20,
-		GCD:     PetGCD,
-		CD:      time.Second * 10,
-		SpellID: 55485,
 		School:  core.SpellSchoolFire,
 		MinDmg:  43,
 		MaxDmg:  57,
@@ -310,10 +336,6 @@ func (hp *HunterPet) newFireBreath() *core.Spell {
 func (hp *HunterPet) newFroststormBreath() *core.Spell {
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    FroststormBreath,
-		Cost:    20,
-		GCD:     0,
-		CD:      time.Second * 10,
-		SpellID: 55492,
 		School:  core.SpellSchoolFrost,
 		MinDmg:  128,
 		MaxDmg:  172,
@@ -322,7 +344,8 @@ func (hp *HunterPet) newFroststormBreath() *core.Spell {
 }
 
 func (hp *HunterPet) newFuriousHowl() *core.Spell {
-	actionID := core.ActionID{SpellID: 64495}
+	data := PetAbilities[FuriousHowl]
+	actionID := core.ActionID{SpellID: data.SpellID}
 
 	petAura := hp.NewTemporaryStatsAura("FuriousHowl", actionID, stats.Stats{stats.AttackPower: 320, stats.RangedAttackPower: 320}, time.Second*20)
 	ownerAura := hp.hunterOwner.NewTemporaryStatsAura("FuriousHowl", actionID, stats.Stats{stats.AttackPower: 320, stats.RangedAttackPower: 320}, time.Second*20)
@@ -331,12 +354,12 @@ func (hp *HunterPet) newFuriousHowl() *core.Spell {
 		ActionID: actionID,
 
 		FocusCost: core.FocusCostOptions{
-			Cost: 20,
+			Cost: data.Cost,
 		},
 		Cast: core.CastConfig{
 			CD: core.Cooldown{
 				Timer:    hp.NewTimer(),
-				Duration: hp.hunterOwner.applyLongevity(time.Second * 40),
+				Duration: hp.hunterOwner.applyLongevity(data.CD),
 			},
 		},
 		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
@@ -370,10 +393,6 @@ func (hp *HunterPet) newFuriousHowl() *core.Spell {
 func (hp *HunterPet) newGore() *core.Spell {
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    Gore,
-		Cost:    20,
-		GCD:     PetGCD,
-		CD:      time.Second * 10,
-		SpellID: 35295,
 		School:  core.SpellSchoolPhysical,
 		MinDmg:  122,
 		MaxDmg:  164,
@@ -384,10 +403,6 @@ func (hp *HunterPet) newGore() *core.Spell {
 func (hp *HunterPet) newLavaBreath() *core.Spell {
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    LavaBreath,
-		Cost:    20,
-		GCD:     PetGCD,
-		CD:      time.Second * 10,
-		SpellID: 58611,
 		School:  core.SpellSchoolFire,
 		MinDmg:  128,
 		MaxDmg:  172,
@@ -398,10 +413,6 @@ func (hp *HunterPet) newLavaBreath() *core.Spell {
 func (hp *HunterPet) newLightningBreath() *core.Spell {
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    LightningBreath,
-		Cost:    20,
-		GCD:     PetGCD,
-		CD:      time.Second * 10,
-		SpellID: 25012,
 		School:  core.SpellSchoolNature,
 		MinDmg:  80,
 		MaxDmg:  120,
@@ -423,10 +434,6 @@ func (hp *HunterPet) newMonstrousBite() *core.Spell {
 
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    MonstrousBite,
-		Cost:    20,
-		GCD:     PetGCD,
-		CD:      time.Second * 10,
-		SpellID: 55499,
 		School:  core.SpellSchoolPhysical,
 		MinDmg:  91,
 		MaxDmg:  123,
@@ -443,10 +450,6 @@ func (hp *HunterPet) newMonstrousBite() *core.Spell {
 func (hp *HunterPet) newNetherShock() *core.Spell {
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    NetherShock,
-		Cost:    20,
-		GCD:     PetGCD,
-		CD:      time.Second * 10,
-		SpellID: 53589,
 		School:  core.SpellSchoolShadow,
 		MinDmg:  64,
 		MaxDmg:  86,
@@ -455,20 +458,21 @@ func (hp *HunterPet) newNetherShock() *core.Spell {
 }
 
 func (hp *HunterPet) newPin() *core.Spell {
+	data := PetAbilities[Pin]
 	return hp.RegisterSpell(core.SpellConfig{
-		ActionID:    core.ActionID{SpellID: 53548},
+		ActionID:    core.ActionID{SpellID: data.SpellID},
 		SpellSchool: core.SpellSchoolPhysical,
 		ProcMask:    core.ProcMaskEmpty,
 
 		Cast: core.CastConfig{
 			DefaultCast: core.Cast{
-				GCD:         PetGCD,
+				GCD:         data.GCD,
 				ChannelTime: time.Second * 4,
 			},
 			IgnoreHaste: true,
 			CD: core.Cooldown{
 				Timer:    hp.NewTimer(),
-				Duration: hp.hunterOwner.applyLongevity(time.Second * 40),
+				Duration: hp.hunterOwner.applyLongevity(data.CD),
 			},
 		},
 
@@ -501,22 +505,23 @@ func (hp *HunterPet) newPin() *core.Spell {
 }
 
 func (hp *HunterPet) newPoisonSpit() *core.Spell {
+	data := PetAbilities[PoisonSpit]
 	return hp.RegisterSpell(core.SpellConfig{
-		ActionID:    core.ActionID{SpellID: 55557},
+		ActionID:    core.ActionID{SpellID: data.SpellID},
 		SpellSchool: core.SpellSchoolNature,
 		ProcMask:    core.ProcMaskEmpty,
 
 		FocusCost: core.FocusCostOptions{
-			Cost: 20,
+			Cost: data.Cost,
 		},
 		Cast: core.CastConfig{
 			DefaultCast: core.Cast{
-				GCD: PetGCD,
+				GCD: data.GCD,
 			},
 			IgnoreHaste: true,
 			CD: core.Cooldown{
 				Timer:    hp.NewTimer(),
-				Duration: hp.hunterOwner.applyLongevity(time.Second * 10),
+				Duration: hp.hunterOwner.applyLongevity(data.CD),
 			},
 		},
 
@@ -551,10 +556,6 @@ func (hp *HunterPet) newPoisonSpit() *core.Spell {
 func (hp *HunterPet) newRake() *core.Spell {
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    Rake,
-		Cost:    20,
-		GCD:     PetGCD,
-		CD:      time.Second * 10,
-		SpellID: 59886,
 		School:  core.SpellSchoolPhysical,
 		MinDmg:  47,
 		MaxDmg:  67,
@@ -585,9 +586,6 @@ func (hp *HunterPet) newRake() *core.Spell {
 func (hp *HunterPet) newRavage() *core.Spell {
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    Ravage,
-		Cost:    0,
-		CD:      time.Second * 40,
-		SpellID: 53562,
 		School:  core.SpellSchoolPhysical,
 		MinDmg:  106,
 		MaxDmg:  150,
@@ -596,7 +594,8 @@ func (hp *HunterPet) newRavage() *core.Spell {
 }
 
 func (hp *HunterPet) newSavageRend() *core.Spell {
-	actionID := core.ActionID{SpellID: 53582}
+	data := PetAbilities[SavageRend]
+	actionID := core.ActionID{SpellID: data.SpellID}
 
 	procAura := hp.RegisterAura(core.Aura{
 		Label:    "Savage Rend",
@@ -617,12 +616,12 @@ func (hp *HunterPet) newSavageRend() *core.Spell {
 		Flags:       core.SpellFlagMeleeMetrics | core.SpellFlagIncludeTargetBonusDamage | core.SpellFlagApplyArmorReduction,
 
 		FocusCost: core.FocusCostOptions{
-			Cost: 20,
+			Cost: data.Cost,
 		},
 		Cast: core.CastConfig{
 			CD: core.Cooldown{
 				Timer:    hp.NewTimer(),
-				Duration: hp.hunterOwner.applyLongevity(time.Second * 60),
+				Duration: hp.hunterOwner.applyLongevity(data.CD),
 			},
 		},
 		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
@@ -672,22 +671,23 @@ func (hp *HunterPet) newSavageRend() *core.Spell {
 }
 
 func (hp *HunterPet) newScorpidPoison() *core.Spell {
+	data := PetAbilities[ScorpidPoison]
 	return hp.RegisterSpell(core.SpellConfig{
-		ActionID:    core.ActionID{SpellID: 55728},
+		ActionID:    core.ActionID{SpellID: data.SpellID},
 		SpellSchool: core.SpellSchoolNature,
 		ProcMask:    core.ProcMaskEmpty,
 
 		FocusCost: core.FocusCostOptions{
-			Cost: 20,
+			Cost: data.Cost,
 		},
 		Cast: core.CastConfig{
 			DefaultCast: core.Cast{
-				GCD: PetGCD,
+				GCD: data.GCD,
 			},
 			IgnoreHaste: true,
 			CD: core.Cooldown{
 				Timer:    hp.NewTimer(),
-				Duration: hp.hunterOwner.applyLongevity(time.Second * 10),
+				Duration: hp.hunterOwner.applyLongevity(data.CD),
 			},
 		},
 
@@ -722,9 +722,6 @@ func (hp *HunterPet) newScorpidPoison() *core.Spell {
 func (hp *HunterPet) newSnatch() *core.Spell {
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    Snatch,
-		Cost:    20,
-		CD:      time.Second * 60,
-		SpellID: 53543,
 		School:  core.SpellSchoolPhysical,
 		MinDmg:  89,
 		MaxDmg:  125,
@@ -735,9 +732,6 @@ func (hp *HunterPet) newSnatch() *core.Spell {
 func (hp *HunterPet) newSonicBlast() *core.Spell {
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    SonicBlast,
-		Cost:    80,
-		CD:      time.Second * 60,
-		SpellID: 53568,
 		School:  core.SpellSchoolNature,
 		MinDmg:  62,
 		MaxDmg:  88,
@@ -748,10 +742,6 @@ func (hp *HunterPet) newSonicBlast() *core.Spell {
 func (hp *HunterPet) newSpiritStrike() *core.Spell {
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    SpiritStrike,
-		Cost:    20,
-		GCD:     0,
-		CD:      time.Second * 10,
-		SpellID: 61198,
 		School:  core.SpellSchoolArcane,
 		MinDmg:  49,
 		MaxDmg:  65,
@@ -781,23 +771,24 @@ func (hp *HunterPet) newSpiritStrike() *core.Spell {
 }
 
 func (hp *HunterPet) newSporeCloud() *core.Spell {
+	data := PetAbilities[SporeCloud]
 	debuffs := hp.NewEnemyAuraArray(core.SporeCloudAura)
 	return hp.RegisterSpell(core.SpellConfig{
-		ActionID:    core.ActionID{SpellID: 53598},
+		ActionID:    core.ActionID{SpellID: data.SpellID},
 		SpellSchool: core.SpellSchoolNature,
 		ProcMask:    core.ProcMaskSpellDamage,
 
 		FocusCost: core.FocusCostOptions{
-			Cost: 20,
+			Cost: data.Cost,
 		},
 		Cast: core.CastConfig{
 			DefaultCast: core.Cast{
-				GCD: PetGCD,
+				GCD: data.GCD,
 			},
 			IgnoreHaste: true,
 			CD: core.Cooldown{
 				Timer:    hp.NewTimer(),
-				Duration: hp.hunterOwner.applyLongevity(time.Second * 10),
+				Duration: hp.hunterOwner.applyLongevity(data.CD),
 			},
 		},
 
@@ -837,9 +828,6 @@ func (hp *HunterPet) newStampede() *core.Spell {
 	debuffs := hp.NewEnemyAuraArray(core.StampedeAura)
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    Stampede,
-		Cost:    0,
-		CD:      time.Second * 60,
-		SpellID: 57393,
 		School:  core.SpellSchoolPhysical,
 		MinDmg:  182,
 		MaxDmg:  264,
@@ -856,10 +844,6 @@ func (hp *HunterPet) newSting() *core.Spell {
 	debuffs := hp.NewEnemyAuraArray(core.StingAura)
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    Sting,
-		Cost:    20,
-		GCD:     PetGCD,
-		CD:      time.Second * 6,
-		SpellID: 56631,
 		School:  core.SpellSchoolNature,
 		MinDmg:  64,
 		MaxDmg:  86,
@@ -877,10 +861,6 @@ func (hp *HunterPet) newSwipe() *core.Spell {
 	// since pets are hard to control.
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    Swipe,
-		Cost:    20,
-		GCD:     PetGCD,
-		CD:      time.Second * 5,
-		SpellID: 53533,
 		School:  core.SpellSchoolPhysical,
 		MinDmg:  90,
 		MaxDmg:  126,
@@ -891,9 +871,6 @@ func (hp *HunterPet) newSwipe() *core.Spell {
 func (hp *HunterPet) newTendonRip() *core.Spell {
 	return hp.newSpecialAbility(PetSpecialAbilityConfig{
 		Type:    TendonRip,
-		Cost:    20,
-		CD:      time.Second * 20,
-		SpellID: 53575,
 		School:  core.SpellSchoolPhysical,
 		MinDmg:  49,
 		MaxDmg:  69,
@@ -902,15 +879,16 @@ func (hp *HunterPet) newTendonRip() *core.Spell {
 }
 
 func (hp *HunterPet) newVenomWebSpray() *core.Spell {
+	data := PetAbilities[VenomWebSpray]
 	return hp.RegisterSpell(core.SpellConfig{
-		ActionID:    core.ActionID{SpellID: 55509},
+		ActionID:    core.ActionID{SpellID: data.SpellID},
 		SpellSchool: core.SpellSchoolNature,
 		ProcMask:    core.ProcMaskEmpty,
 
 		Cast: core.CastConfig{
 			CD: core.Cooldown{
 				Timer:    hp.NewTimer(),
-				Duration: hp.hunterOwner.applyLongevity(time.Second * 40),
+				Duration: hp.hunterOwner.applyLongevity(data.CD),
 			},
 		},
 
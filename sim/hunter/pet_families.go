@@ -0,0 +1,292 @@
+package hunter
+
+import (
+	"fmt"
+	"strings"
+
+	googleProto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+type PetTalentTree int
+
+const (
+	PetCunning PetTalentTree = iota
+	PetFerocity
+	PetTenacity
+)
+
+func (tree PetTalentTree) String() string {
+	return [...]string{"Cunning", "Ferocity", "Tenacity"}[tree]
+}
+
+// Talents available in each tree, by HunterPetTalents field name, as in
+// ui/core/talents/trees/hunter_*.json.
+var petTalentTrees = map[PetTalentTree][]protoreflect.Name{
+	PetCunning: {
+		"cobra_reflexes", "dive", "great_stamina", "natural_armor", "boars_speed", "mobility", "owls_focus",
+		"spiked_collar", "culling_the_herd", "lionhearted", "carrion_feeder", "great_resistance", "cornered",
+		"feeding_frenzy", "wolverine_bite", "roar_of_recovery", "bullheaded", "grace_of_the_mantis", "wild_hunt",
+		"roar_of_sacrifice",
+	},
+	PetFerocity: {
+		"cobra_reflexes", "dive", "great_stamina", "natural_armor", "improved_cower", "bloodthirsty", "spiked_collar",
+		"boars_speed", "culling_the_herd", "lionhearted", "charge", "heart_of_the_pheonix", "spiders_bite",
+		"great_resistance", "rabid", "lick_your_wounds", "call_of_the_wild", "shark_attack", "wild_hunt",
+	},
+	PetTenacity: {
+		"cobra_reflexes", "charge", "great_stamina", "natural_armor", "spiked_collar", "boars_speed",
+		"blood_of_the_rhino", "pet_barding", "culling_the_herd", "guard_dog", "lionhearted", "thunderstomp",
+		"grace_of_the_mantis", "great_resistance", "last_stand", "taunt", "roar_of_sacrifice", "intervene",
+		"silverback", "wild_hunt",
+	},
+}
+
+// Returns a copy of talents with only the talents in this tree, and the names
+// of the talents which were removed.
+func (tree PetTalentTree) filterTalents(talents *proto.HunterPetTalents) (*proto.HunterPetTalents, []string) {
+	inTree := make(map[protoreflect.Name]bool)
+	for _, name := range petTalentTrees[tree] {
+		inTree[name] = true
+	}
+
+	filtered := &proto.HunterPetTalents{}
+	var removed []string
+	talents.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if inTree[fd.Name()] {
+			filtered.ProtoReflect().Set(fd, v)
+		} else {
+			removed = append(removed, string(fd.Name()))
+		}
+		return true
+	})
+	return filtered, removed
+}
+
+// Removes the owner's pet talents which aren't in the pet's tree, with a
+// warning, since pets can only learn talents from their own family's tree.
+func (hunter *Hunter) applyPetTalentTree(config PetConfig) {
+	talents := hunter.Options.PetTalents
+	if talents == nil {
+		return
+	}
+	filtered, removed := config.Tree.filterTalents(talents)
+	if len(removed) == 0 {
+		return
+	}
+
+	options := googleProto.Clone(hunter.Options).(*proto.Hunter_Options)
+	options.PetTalents = filtered
+	hunter.Options = options
+	hunter.Warnings = append(hunter.Warnings, fmt.Sprintf("Removed pet talents which a %s (%s) can't learn: %s.", config.Name, config.Tree, strings.Join(removed, ", ")))
+}
+
+type PetConfig struct {
+	Name string
+	Tree PetTalentTree
+
+	SpecialAbility PetAbilityType
+	FocusDump      PetAbilityType
+
+	// Randomly select between abilities instead of using a prio.
+	RandomSelection bool
+}
+
+// Abilities reference: https://wotlk.wowhead.com/hunter-pets
+// https://wotlk.wowhead.com/guides/hunter-dps-best-pets-taming-loyalty-burning-crusade-classic
+var PetConfigs = map[proto.Hunter_Options_PetType]PetConfig{
+	proto.Hunter_Options_Bat: {
+		Name:           "Bat",
+		Tree:           PetCunning,
+		SpecialAbility: SonicBlast,
+		FocusDump:      Claw,
+	},
+	proto.Hunter_Options_Bear: {
+		Name:           "Bear",
+		Tree:           PetTenacity,
+		SpecialAbility: Swipe,
+		FocusDump:      Claw,
+	},
+	proto.Hunter_Options_BirdOfPrey: {
+		Name:           "Bird of Prey",
+		Tree:           PetCunning,
+		SpecialAbility: Snatch,
+		FocusDump:      Claw,
+	},
+	proto.Hunter_Options_Boar: {
+		Name:           "Boar",
+		Tree:           PetTenacity,
+		SpecialAbility: Gore,
+		FocusDump:      Bite,
+	},
+	proto.Hunter_Options_CarrionBird: {
+		Name:           "Carrion Bird",
+		Tree:           PetFerocity,
+		SpecialAbility: DemoralizingScreech,
+		FocusDump:      Bite,
+	},
+	proto.Hunter_Options_Cat: {
+		Name:           "Cat",
+		Tree:           PetFerocity,
+		SpecialAbility: Rake,
+		FocusDump:      Claw,
+	},
+	proto.Hunter_Options_Chimaera: {
+		Name:           "Chimaera",
+		Tree:           PetCunning,
+		SpecialAbility: FroststormBreath,
+		FocusDump:      Bite,
+	},
+	proto.Hunter_Options_CoreHound: {
+		Name:           "Core Hound",
+		Tree:           PetFerocity,
+		SpecialAbility: LavaBreath,
+		FocusDump:      Bite,
+	},
+	proto.Hunter_Options_Crab: {
+		Name:           "Crab",
+		Tree:           PetTenacity,
+		SpecialAbility: Pin,
+		FocusDump:      Claw,
+	},
+	proto.Hunter_Options_Crocolisk: {
+		Name: "Crocolisk",
+		Tree: PetTenacity,
+		//SpecialAbility: BadAttitude,
+		FocusDump: Bite,
+	},
+	proto.Hunter_Options_Devilsaur: {
+		Name:           "Devilsaur",
+		Tree:           PetFerocity,
+		SpecialAbility: MonstrousBite,
+		FocusDump:      Bite,
+	},
+	proto.Hunter_Options_Dragonhawk: {
+		Name:           "Dragonhawk",
+		Tree:           PetCunning,
+		SpecialAbility: FireBreath,
+		FocusDump:      Bite,
+	},
+	proto.Hunter_Options_Gorilla: {
+		Name: "Gorilla",
+		Tree: PetTenacity,
+		//SpecialAbility: Pummel,
+		FocusDump: Smack,
+	},
+	proto.Hunter_Options_Hyena: {
+		Name:           "Hyena",
+		Tree:           PetFerocity,
+		SpecialAbility: TendonRip,
+		FocusDump:      Bite,
+	},
+	proto.Hunter_Options_Moth: {
+		Name: "Moth",
+		Tree: PetFerocity,
+		//SpecialAbility:   SerentiyDust,
+		FocusDump: Smack,
+	},
+	proto.Hunter_Options_NetherRay: {
+		Name:           "Nether Ray",
+		Tree:           PetCunning,
+		SpecialAbility: NetherShock,
+		FocusDump:      Bite,
+	},
+	proto.Hunter_Options_Raptor: {
+		Name:           "Raptor",
+		Tree:           PetFerocity,
+		SpecialAbility: SavageRend,
+		FocusDump:      Claw,
+	},
+	proto.Hunter_Options_Ravager: {
+		Name:           "Ravager",
+		Tree:           PetCunning,
+		SpecialAbility: Ravage,
+		FocusDump:      Bite,
+	},
+	proto.Hunter_Options_Rhino: {
+		Name:           "Rhino",
+		Tree:           PetTenacity,
+		SpecialAbility: Stampede,
+		FocusDump:      Bite,
+	},
+	proto.Hunter_Options_Scorpid: {
+		Name:           "Scorpid",
+		Tree:           PetTenacity,
+		SpecialAbility: ScorpidPoison,
+		FocusDump:      Bite,
+	},
+	proto.Hunter_Options_Serpent: {
+		Name:           "Serpent",
+		Tree:           PetCunning,
+		SpecialAbility: PoisonSpit,
+		FocusDump:      Bite,
+	},
+	proto.Hunter_Options_Silithid: {
+		Name:           "Silithid",
+		Tree:           PetCunning,
+		SpecialAbility: VenomWebSpray,
+		FocusDump:      Claw,
+	},
+	proto.Hunter_Options_Spider: {
+		Name: "Spider",
+		Tree: PetCunning,
+		//SpecialAbility:   Web,
+		FocusDump: Bite,
+	},
+	proto.Hunter_Options_SpiritBeast: {
+		Name:           "Spirit Beast",
+		Tree:           PetFerocity,
+		SpecialAbility: SpiritStrike,
+		FocusDump:      Claw,
+	},
+	proto.Hunter_Options_SporeBat: {
+		Name:           "Spore Bat",
+		Tree:           PetCunning,
+		SpecialAbility: SporeCloud,
+		FocusDump:      Smack,
+	},
+	proto.Hunter_Options_Tallstrider: {
+		Name: "Tallstrider",
+		Tree: PetFerocity,
+		//SpecialAbility:   DustCloud,
+		FocusDump: Claw,
+	},
+	proto.Hunter_Options_Turtle: {
+		Name: "Turtle",
+		Tree: PetTenacity,
+		//SpecialAbility: ShellShield,
+		FocusDump: Bite,
+	},
+	proto.Hunter_Options_WarpStalker: {
+		Name: "Warp Stalker",
+		Tree: PetTenacity,
+		//SpecialAbility:   Warp,
+		FocusDump: Bite,
+	},
+	proto.Hunter_Options_Wasp: {
+		Name:           "Wasp",
+		Tree:           PetFerocity,
+		SpecialAbility: Sting,
+		FocusDump:      Smack,
+	},
+	proto.Hunter_Options_WindSerpent: {
+		Name:           "Wind Serpent",
+		Tree:           PetCunning,
+		SpecialAbility: LightningBreath,
+		FocusDump:      Bite,
+	},
+	proto.Hunter_Options_Wolf: {
+		Name:           "Wolf",
+		Tree:           PetFerocity,
+		SpecialAbility: FuriousHowl,
+		FocusDump:      Bite,
+	},
+	proto.Hunter_Options_Worm: {
+		Name:           "Worm",
+		Tree:           PetTenacity,
+		SpecialAbility: AcidSpit,
+		FocusDump:      Bite,
+	},
+}
@@ -70,9 +70,9 @@ func (hunter *Hunter) singleTargetChooseSpell(sim *core.Simulation) (*core.Spell
 			continue
 		}
 
-		for i := int32(0); i < hunter.Env.GetNumTargets(); i++ {
-			if hunter.rotationConditions[spell].CanUse(sim, hunter.Env.GetTargetUnit(i)) {
-				return spell, hunter.Env.GetTargetUnit(i)
+		for _, target := range sim.Encounter.TargetUnits {
+			if hunter.rotationConditions[spell].CanUse(sim, target) {
+				return spell, target
 			}
 		}
 	}
@@ -213,8 +213,7 @@ func (hunter *Hunter) makeCustomRotation() *common.CustomRotation {
 		int32(proto.Hunter_Rotation_SerpentStingSpell): {
 			Action: func(sim *core.Simulation, target *core.Unit) (bool, float64) {
 				cost := hunter.SerpentSting.CurCast.Cost
-				for i := int32(0); i < hunter.Env.GetNumTargets(); i++ {
-					target := hunter.Env.GetTargetUnit(i)
+				for _, target := range sim.Encounter.TargetUnits {
 					if !hunter.SerpentSting.Dot(target).IsActive() {
 						return hunter.SerpentSting.Cast(sim, target), cost
 					}
@@ -226,8 +225,8 @@ func (hunter *Hunter) makeCustomRotation() *common.CustomRotation {
 					return false
 				}
 				if hunter.Rotation.MultiDotSerpentSting {
-					for i := int32(0); i < hunter.Env.GetNumTargets(); i++ {
-						if !hunter.SerpentSting.Dot(hunter.Env.GetTargetUnit(i)).IsActive() {
+					for _, target := range sim.Encounter.TargetUnits {
+						if !hunter.SerpentSting.Dot(target).IsActive() {
 							return true
 						}
 					}
@@ -571,7 +571,7 @@ func (hunter *Hunter) applyLockAndLoad() {
 		OnSpellHitDealt: func(aura *core.Aura, sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
 			if spell == hunter.ArcaneShot || spell == hunter.ExplosiveShotR4 || spell == hunter.ExplosiveShotR3 {
 				aura.RemoveStack(sim)
-				hunter.ArcaneShot.CD.Reset() // Shares the CD with explosive shot.
+				hunter.ArcaneShot.ResetCooldown(sim, aura.ActionID) // Shares the CD with explosive shot.
 			}
 		},
 	})
@@ -827,27 +827,27 @@ func (hunter *Hunter) registerReadinessCD() {
 			return !hunter.RapidFire.IsReady(sim)
 		},
 
-		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
-			hunter.RapidFire.CD.Reset()
-			hunter.MultiShot.CD.Reset()
-			hunter.ArcaneShot.CD.Reset()
-			hunter.KillShot.CD.Reset()
-			hunter.RaptorStrike.CD.Reset()
-			hunter.ExplosiveTrap.CD.Reset()
+		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
+			hunter.RapidFire.ResetCooldown(sim, spell.ActionID)
+			hunter.MultiShot.ResetCooldown(sim, spell.ActionID)
+			hunter.ArcaneShot.ResetCooldown(sim, spell.ActionID)
+			hunter.KillShot.ResetCooldown(sim, spell.ActionID)
+			hunter.RaptorStrike.ResetCooldown(sim, spell.ActionID)
+			hunter.ExplosiveTrap.ResetCooldown(sim, spell.ActionID)
 			if hunter.KillCommand != nil {
-				hunter.KillCommand.CD.Reset()
+				hunter.KillCommand.ResetCooldown(sim, spell.ActionID)
 			}
 			if hunter.AimedShot != nil {
-				hunter.AimedShot.CD.Reset()
+				hunter.AimedShot.ResetCooldown(sim, spell.ActionID)
 			}
 			if hunter.SilencingShot != nil {
-				hunter.SilencingShot.CD.Reset()
+				hunter.SilencingShot.ResetCooldown(sim, spell.ActionID)
 			}
 			if hunter.ChimeraShot != nil {
-				hunter.ChimeraShot.CD.Reset()
+				hunter.ChimeraShot.ResetCooldown(sim, spell.ActionID)
 			}
 			if hunter.BlackArrow != nil {
-				hunter.BlackArrow.CD.Reset()
+				hunter.BlackArrow.ResetCooldown(sim, spell.ActionID)
 			}
 
 			// TODO: This is needed because there are edge cases where core doesn't re-use Rapid Fire.
@@ -119,22 +119,31 @@ func getDatabase(itemIds *int32, numItems int32, enchantIds *int32, numEnchants
 			WeaponDamageMax:  item.WeaponDamageMax,
 			WeaponSpeed:      item.SwingSpeed,
 			SetName:          item.SetName,
+
+			RequiredProfession: item.RequiredProfession,
+			Ilvl:               item.Ilvl,
+			Quality:            item.Quality,
+			Heroic:             item.Heroic,
+			Phase:              item.Phase,
 		}
 	}
 	for i, enchantId := range eids {
 		enchant := core.EnchantsByEffectID[enchantId]
 		simDB.Enchants[i] = &proto.SimEnchant{
-			EffectId: enchant.EffectID,
-			Stats:    enchant.Stats[:],
+			EffectId:           enchant.EffectID,
+			Stats:              enchant.Stats[:],
+			RequiredProfession: enchant.RequiredProfession,
 		}
 	}
 	for i, gemId := range gids {
 		gem := core.GemsByID[gemId]
 		simDB.Gems[i] = &proto.SimGem{
-			Id:    gem.ID,
-			Name:  gem.Name,
-			Color: gem.Color,
-			Stats: gem.Stats[:],
+			Id:                 gem.ID,
+			Name:               gem.Name,
+			Color:              gem.Color,
+			Stats:              gem.Stats[:],
+			Unique:             gem.Unique,
+			RequiredProfession: gem.RequiredProfession,
 		}
 	}
 	out, err := protojson.Marshal(simDB)
@@ -122,13 +122,8 @@ func (mage *Mage) BloodmagesRegalia2pcAura() *core.Aura {
 
 var ItemSetGladiatorsRegalia = core.NewItemSet(core.ItemSet{
 	Name: "Gladiator's Regalia",
-	Bonuses: map[int32]core.ApplyEffect{
-		2: func(agent core.Agent) {
-			agent.GetCharacter().AddStat(stats.Resilience, 100)
-			agent.GetCharacter().AddStat(stats.SpellPower, 29)
-		},
-		4: func(agent core.Agent) {
-			agent.GetCharacter().AddStat(stats.SpellPower, 88)
-		},
+	Records: map[int32]core.SetBonusRecord{
+		2: {Stats: stats.Stats{stats.SpellPower: 29, stats.Resilience: 100}},
+		4: {Stats: stats.Stats{stats.SpellPower: 88}},
 	},
 })
@@ -138,14 +138,14 @@ func (mage *Mage) Initialize() {
 	mage.registerBlastWaveSpell()
 	mage.registerDragonsBreathSpell()
 
-	mage.RegisterPrepullAction(-2000*time.Millisecond, func(sim *core.Simulation) {
-		if mirrorImageMCD := mage.GetMajorCooldownIgnoreTag(mage.MirrorImage.ActionID); !mage.IsUsingAPL && mirrorImageMCD != nil {
-			if len(mirrorImageMCD.GetTimings()) == 0 {
-				mage.MirrorImage.Cast(sim, nil)
-				mage.UpdateMajorCooldowns()
+	if !mage.IsUsingAPL {
+		// Cooldown timings are only known once finalized.
+		mage.Env.RegisterPostFinalizeEffect(func() {
+			if mirrorImageMCD := mage.GetInitialMajorCooldown(mage.MirrorImage.ActionID); mirrorImageMCD.Spell != nil && len(mirrorImageMCD.GetTimings()) == 0 {
+				mage.RegisterPrepullSpell(-2000*time.Millisecond, mage.MirrorImage, nil)
 			}
-		}
-	})
+		})
+	}
 }
 
 func (mage *Mage) Reset(sim *core.Simulation) {
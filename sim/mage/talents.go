@@ -406,30 +406,14 @@ func (mage *Mage) applyMasterOfElements() {
 		return
 	}
 
-	refundCoeff := 0.1*float64(mage.Talents.MasterOfElements) - .01*float64(mage.Talents.Burnout)
-	manaMetrics := mage.NewManaMetrics(core.ActionID{SpellID: 29076})
-
-	mage.RegisterAura(core.Aura{
-		Label:    "Master of Elements",
-		Duration: core.NeverExpires,
-		OnReset: func(aura *core.Aura, sim *core.Simulation) {
-			aura.Activate(sim)
-		},
-		OnSpellHitDealt: func(aura *core.Aura, sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
-			if spell.ProcMask.Matches(core.ProcMaskMeleeOrRanged) {
-				return
-			}
-			if spell.CurCast.Cost == 0 {
-				return
-			}
-			if result.DidCrit() {
-				if refundCoeff < 0 {
-					mage.SpendMana(sim, -1*spell.DefaultCast.Cost*refundCoeff, manaMetrics)
-				} else {
-					mage.AddMana(sim, spell.DefaultCast.Cost*refundCoeff, manaMetrics)
-				}
-			}
-		},
+	// Burnout costs extra mana instead.
+	mage.RegisterCostRefund(core.CostRefund{
+		Label:           "Master of Elements",
+		ActionID:        core.ActionID{SpellID: 29076},
+		ProcMaskExclude: core.ProcMaskMeleeOrRanged,
+		Outcome:         core.OutcomeCrit,
+		Fraction:        0.1*float64(mage.Talents.MasterOfElements) - .01*float64(mage.Talents.Burnout),
+		FromDefaultCost: true,
 	})
 }
 
@@ -599,12 +583,12 @@ func (mage *Mage) registerColdSnapCD() {
 			return (mage.IcyVeins != nil && !mage.IcyVeins.IsReady(sim)) ||
 				(mage.SummonWaterElemental != nil && !mage.SummonWaterElemental.IsReady(sim))
 		},
-		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
+		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
 			if mage.IcyVeins != nil {
-				mage.IcyVeins.CD.Reset()
+				mage.IcyVeins.ResetCooldown(sim, spell.ActionID)
 			}
 			if mage.SummonWaterElemental != nil {
-				mage.SummonWaterElemental.CD.Reset()
+				mage.SummonWaterElemental.ResetCooldown(sim, spell.ActionID)
 			}
 		},
 	})
@@ -41,6 +41,7 @@ func (paladin *Paladin) registerAvengersShieldSpell() {
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
 			constBaseDamage := .07*spell.SpellPower() + .07*spell.MeleeAttackPower()
 
+			numHits := min(numHits, sim.GetNumActiveTargets())
 			curTarget := target
 			for hitIndex := int32(0); hitIndex < numHits; hitIndex++ {
 				baseDamage := constBaseDamage + sim.Roll(1100, 1344)
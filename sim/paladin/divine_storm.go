@@ -43,6 +43,7 @@ func (paladin *Paladin) registerDivineStormSpell() {
 		ThreatMultiplier: 1,
 
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
+			numHits := min(numHits, sim.GetNumActiveTargets())
 			curTarget := target
 			for hitIndex := int32(0); hitIndex < numHits; hitIndex++ {
 				baseDamage := bonusDmg +
@@ -44,6 +44,7 @@ func (paladin *Paladin) registerHammerOfTheRighteousSpell() {
 			speed := spell.Unit.AutoAttacks.MH().SwingSpeed
 			baseDamage := (avgWeaponDamage / speed) * 4
 
+			numHits := min(numHits, sim.GetNumActiveTargets())
 			curTarget := target
 			for hitIndex := int32(0); hitIndex < numHits; hitIndex++ {
 				results[hitIndex] = spell.CalcDamage(sim, curTarget, baseDamage, spell.OutcomeMeleeSpecialHitAndCrit)
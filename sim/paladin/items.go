@@ -99,8 +99,8 @@ var ItemSetLightswornBattlegear = core.NewItemSet(core.ItemSet{
 
 			procSpell := paladin.RegisterSpell(core.SpellConfig{
 				ActionID: core.ActionID{SpellID: 70765},
-				ApplyEffects: func(_ *core.Simulation, _ *core.Unit, _ *core.Spell) {
-					paladin.DivineStorm.CD.Reset()
+				ApplyEffects: func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
+					paladin.DivineStorm.ResetCooldown(sim, spell.ActionID)
 				},
 			})
 
@@ -134,17 +134,10 @@ func (paladin *Paladin) getItemSetLightswornBattlegearBonus4() float64 {
 // PvP ret
 var ItemSetGladiatorsVindication = core.NewItemSet(core.ItemSet{
 	Name: "Gladiator's Vindication",
-	Bonuses: map[int32]core.ApplyEffect{
-		2: func(agent core.Agent) {
-			paladin := agent.(PaladinAgent).GetPaladin()
-			paladin.AddStat(stats.AttackPower, 50)
-			paladin.AddStat(stats.Resilience, 100)
-		},
-		4: func(agent core.Agent) {
-			paladin := agent.(PaladinAgent).GetPaladin()
-			paladin.AddStat(stats.AttackPower, 150)
-			// Rest implemented in judgement.go
-		},
+	Records: map[int32]core.SetBonusRecord{
+		2: {Stats: stats.Stats{stats.AttackPower: 50, stats.Resilience: 100}},
+		// Rest implemented in judgement.go
+		4: {Stats: stats.Stats{stats.AttackPower: 150}},
 	},
 })
 
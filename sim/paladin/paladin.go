@@ -68,8 +68,6 @@ type Paladin struct {
 
 	HasTuralyonsOrLiadrinsBattlegear2Pc bool
 
-	DemonAndUndeadTargetCount int32
-
 	AvoidClippingConsecration           bool
 	HoldLastAvengingWrathUntilExecution bool
 	CancelChaosBane                     bool
@@ -154,13 +152,17 @@ func (paladin *Paladin) Initialize() {
 	paladin.registerDivinePleaSpell()
 	paladin.registerDivineProtectionSpell()
 	paladin.registerForbearanceDebuff()
+}
 
-	for i := int32(0); i < paladin.Env.GetNumTargets(); i++ {
-		unit := paladin.Env.GetTargetUnit(i)
+// The number of demon and undead targets which are currently in the fight.
+func (paladin *Paladin) NumDemonAndUndeadTargets(sim *core.Simulation) int32 {
+	count := int32(0)
+	for _, unit := range sim.Encounter.TargetUnits {
 		if unit.MobType == proto.MobType_MobTypeDemon || unit.MobType == proto.MobType_MobTypeUndead {
-			paladin.DemonAndUndeadTargetCount += 1
+			count++
 		}
 	}
+	return count
 }
 
 func (paladin *Paladin) Reset(_ *core.Simulation) {
@@ -94,15 +94,8 @@ func (prot *ProtectionPaladin) Initialize() {
 	}
 
 	if !prot.IsUsingAPL {
-		prot.RegisterPrepullAction(-3*time.Second, func(sim *core.Simulation) {
-			prot.HolyShield.Cast(sim, nil)
-		})
-	}
-
-	if !prot.IsUsingAPL {
-		prot.RegisterPrepullAction(-1500*time.Millisecond, func(sim *core.Simulation) {
-			prot.DivinePlea.Cast(sim, nil)
-		})
+		prot.RegisterPrepullSpell(-3*time.Second, prot.HolyShield, nil)
+		prot.RegisterPrepullSpell(-1500*time.Millisecond, prot.DivinePlea, nil)
 	}
 }
 
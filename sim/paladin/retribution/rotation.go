@@ -9,13 +9,12 @@ import (
 )
 
 func (ret *RetributionPaladin) OnAutoAttack(sim *core.Simulation, _ *core.Spell) {
-	if ret.SealOfVengeanceAura.IsActive() && min(ret.MaxSoVTargets, ret.Env.GetNumTargets()) > 1 {
+	if ret.SealOfVengeanceAura.IsActive() && min(ret.MaxSoVTargets, sim.GetNumActiveTargets()) > 1 {
 		minVengeanceDotDuration := time.Second * 15
 		var minVengeanceDotDurationTarget *core.Unit
 		minVengeanceDotStacks := int32(5)
 		var minVengeanceDotStacksTarget *core.Unit
-		for i := int32(0); i < min(ret.MaxSoVTargets, ret.Env.GetNumTargets()); i++ {
-			target := ret.Env.GetTargetUnit(i)
+		for _, target := range sim.Encounter.TargetUnits[:min(ret.MaxSoVTargets, sim.GetNumActiveTargets())] {
 			dot := ret.SovDotSpell.Dot(target)
 			remainingDuration := dot.RemainingDuration(sim)
 			stackCount := dot.GetStacks()
@@ -224,7 +223,7 @@ func (ret *RetributionPaladin) mainRotation(sim *core.Simulation) {
 			if !success {
 				ret.WaitForMana(sim, ret.DivineStorm.CurCast.Cost)
 			}
-		case ret.Env.GetNumTargets() > 1 && ret.Consecration.IsReady(sim):
+		case sim.GetNumActiveTargets() > 1 && ret.Consecration.IsReady(sim):
 			success := ret.Consecration.Cast(sim, target)
 			if !success {
 				ret.WaitForMana(sim, ret.Consecration.CurCast.Cost)
@@ -234,7 +233,7 @@ func (ret *RetributionPaladin) mainRotation(sim *core.Simulation) {
 			if !success {
 				ret.WaitForMana(sim, ret.HammerOfWrath.CurCast.Cost)
 			}
-		case ret.DemonAndUndeadTargetCount >= ret.HolyWrathThreshold && ret.HolyWrath.IsReady(sim):
+		case ret.NumDemonAndUndeadTargets(sim) >= ret.HolyWrathThreshold && ret.HolyWrath.IsReady(sim):
 			success := ret.HolyWrath.Cast(sim, target)
 			if !success {
 				ret.WaitForMana(sim, ret.HolyWrath.CurCast.Cost)
@@ -267,7 +266,7 @@ func (ret *RetributionPaladin) mainRotation(sim *core.Simulation) {
 			if !success {
 				ret.WaitForMana(sim, ret.Exorcism.CurCast.Cost)
 			}
-		case ret.DemonAndUndeadTargetCount >= 1 && ret.HolyWrath.IsReady(sim):
+		case ret.NumDemonAndUndeadTargets(sim) >= 1 && ret.HolyWrath.IsReady(sim):
 			// Holy Wrath isn't worth casting if it will reduce usages of CS/DS
 			if ret.CrusaderStrike.ReadyAt()-sim.CurrentTime < 500*time.Millisecond {
 				break
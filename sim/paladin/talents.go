@@ -113,8 +113,8 @@ func (paladin *Paladin) applyMinorGlyphOfSenseUndead() {
 	paladin.RegisterResetEffect(
 		func(s *core.Simulation) {
 			if !applied {
-				for i := int32(0); i < paladin.Env.GetNumTargets(); i++ {
-					unit := paladin.Env.GetTargetUnit(i)
+				// Includes adds which haven't spawned yet, for when they do.
+				for _, unit := range paladin.Env.Encounter.AllTargetUnits {
 					if unit.MobType == proto.MobType_MobTypeUndead {
 						paladin.AttackTables[unit.UnitIndex].DamageDealtMultiplier *= 1.01
 					}
@@ -321,8 +321,8 @@ func (paladin *Paladin) applyCrusade() {
 	paladin.RegisterResetEffect(
 		func(s *core.Simulation) {
 			if !applied {
-				for i := int32(0); i < paladin.Env.GetNumTargets(); i++ {
-					unit := paladin.Env.GetTargetUnit(i)
+				// Includes adds which haven't spawned yet, for when they do.
+				for _, unit := range paladin.Env.Encounter.AllTargetUnits {
 					crusadeMod := 1.0 + (0.01 * float64(paladin.Talents.Crusade))
 					switch unit.MobType {
 					case proto.MobType_MobTypeHumanoid, proto.MobType_MobTypeDemon, proto.MobType_MobTypeUndead, proto.MobType_MobTypeElemental:
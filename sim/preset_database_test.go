@@ -0,0 +1,45 @@
+package sim
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core"
+)
+
+// Presets with known bad references, which are dropped when simming. See
+// assets/database/validation_report.json for the details.
+var knownBadPresets = map[string]bool{
+	"../ui/feral_tank_druid/gear_sets/p1.gear.json":   true, // Spell IDs instead of enchant effect IDs.
+	"../ui/feral_tank_druid/gear_sets/p2.gear.json":   true,
+	"../ui/shadow_priest/gear_sets/preraid.gear.json": true, // Filtered out item.
+	"../ui/smite_priest/gear_sets/preraid.gear.json":  true,
+	"../ui/warlock/gear_sets/swp.gear.json":           true, // TBC gems.
+}
+
+// Checks that every item, enchant and gem in the UI's gear presets is in the
+// generated database.
+func TestPresetDatabaseReferences(t *testing.T) {
+	if !core.WITH_DB {
+		t.Skip("Needs the 'with_db' tag.")
+	}
+
+	presets, err := filepath.Glob("../ui/*/gear_sets/*.gear.json")
+	if err != nil || len(presets) == 0 {
+		t.Fatalf("No gear presets found: %v", err)
+	}
+
+	for _, preset := range presets {
+		if knownBadPresets[preset] {
+			continue
+		}
+		data, err := os.ReadFile(preset)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %s", preset, err)
+		}
+		for _, discrepancy := range core.ValidateDatabaseReferences(preset, core.EquipmentSpecFromJsonString(string(data)), nil) {
+			t.Errorf("%s: %s", discrepancy.Source, discrepancy.Message)
+		}
+	}
+}
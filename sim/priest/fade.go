@@ -0,0 +1,55 @@
+package priest
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func (priest *Priest) registerFadeSpell() {
+	actionID := core.ActionID{SpellID: 586}
+
+	// Threat removed from each target, which is restored when Fade expires.
+	fadedThreat := make([]float64, len(priest.Env.Encounter.TargetUnits))
+
+	fadeAura := priest.RegisterAura(core.Aura{
+		Label:    "Fade",
+		ActionID: actionID,
+		Duration: time.Second * 10,
+		OnGain: func(aura *core.Aura, sim *core.Simulation) {
+			for i, target := range sim.Encounter.TargetUnits {
+				fadedThreat[i] = priest.GetThreat(target)
+				priest.AddThreat(sim, target, -fadedThreat[i])
+			}
+		},
+		OnExpire: func(aura *core.Aura, sim *core.Simulation) {
+			for i, target := range sim.Encounter.TargetUnits {
+				priest.AddThreat(sim, target, fadedThreat[i])
+			}
+		},
+	})
+
+	cooldown := time.Second*30 -
+		time.Second*3*time.Duration(priest.Talents.VeiledShadows) -
+		core.TernaryDuration(priest.HasMajorGlyph(proto.PriestMajorGlyph_GlyphOfFade), time.Second*9, 0)
+
+	priest.Fade = priest.RegisterSpell(core.SpellConfig{
+		ActionID: actionID,
+		Flags:    core.SpellFlagAPL,
+
+		Cast: core.CastConfig{
+			DefaultCast: core.Cast{
+				GCD: core.GCDDefault,
+			},
+			CD: core.Cooldown{
+				Timer:    priest.NewTimer(),
+				Duration: cooldown,
+			},
+		},
+
+		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
+			fadeAura.Activate(sim)
+		},
+	})
+}
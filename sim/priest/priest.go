@@ -59,6 +59,7 @@ type Priest struct {
 	Smite             *core.Spell
 	VampiricTouch     *core.Spell
 	Dispersion        *core.Spell
+	Fade              *core.Spell
 
 	WeakenedSouls core.AuraArray
 
@@ -133,6 +134,7 @@ func (priest *Priest) Initialize() {
 	priest.registerShadowfiendSpell()
 	priest.registerVampiricTouchSpell()
 	priest.registerDispersionSpell()
+	priest.registerFadeSpell()
 
 	priest.registerPowerInfusionCD()
 
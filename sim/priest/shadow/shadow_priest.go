@@ -83,11 +83,7 @@ func (spriest *ShadowPriest) Initialize() {
 
 		// Do this post-finalize so cast speed is updated with new stats
 		spriest.Env.RegisterPostFinalizeEffect(func() {
-			precastSpellAt := -spriest.ApplyCastSpeedForSpell(precastSpell.DefaultCast.CastTime, precastSpell)
-
-			spriest.RegisterPrepullAction(precastSpellAt, func(sim *core.Simulation) {
-				precastSpell.Cast(sim, spriest.CurrentTarget)
-			})
+			spriest.RegisterPrepullCast(precastSpell, 0)
 		})
 	}
 }
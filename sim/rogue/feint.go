@@ -7,6 +7,9 @@ import (
 	"github.com/wowsims/wotlk/sim/core/proto"
 )
 
+// Threat removed by Feint (Rank 8).
+const FeintThreatReduction = 1625
+
 func (rogue *Rogue) registerFeintSpell() {
 	rogue.Feint = rogue.RegisterSpell(core.SpellConfig{
 		ActionID:    core.ActionID{SpellID: 48659},
@@ -33,7 +36,10 @@ func (rogue *Rogue) registerFeintSpell() {
 
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
 			rogue.BreakStealth(sim)
-			spell.CalcAndDealOutcome(sim, target, spell.OutcomeMeleeSpecialHit)
+			result := spell.CalcAndDealOutcome(sim, target, spell.OutcomeMeleeSpecialHit)
+			if result.Landed() {
+				rogue.AddThreat(sim, target, -FeintThreatReduction*rogue.PseudoStats.ThreatMultiplier)
+			}
 		},
 	})
 	// Feint
@@ -9,15 +9,10 @@ import (
 
 var Arena = core.NewItemSet(core.ItemSet{
 	Name: "Gladiator's Vestments",
-	Bonuses: map[int32]core.ApplyEffect{
-		2: func(agent core.Agent) {
-			agent.GetCharacter().AddStat(stats.Resilience, 100)
-			agent.GetCharacter().AddStat(stats.AttackPower, 50)
-		},
-		4: func(agent core.Agent) {
-			agent.GetCharacter().AddStat(stats.AttackPower, 150)
-			// 10 maximum energy added in rogue.go
-		},
+	Records: map[int32]core.SetBonusRecord{
+		2: {Stats: stats.Stats{stats.AttackPower: 50, stats.Resilience: 100}},
+		// 10 maximum energy added in rogue.go
+		4: {Stats: stats.Stats{stats.AttackPower: 150}},
 	},
 })
 
@@ -53,11 +53,11 @@ func (rogue *Rogue) registerKillingSpreeSpell() {
 				NumTicks:        5,
 				TickImmediately: true,
 				OnAction: func(s *core.Simulation) {
-					targetCount := sim.GetNumTargets()
+					targetUnits := sim.Encounter.TargetUnits
 					target := rogue.CurrentTarget
-					if targetCount > 1 {
-						newUnitIndex := int32(math.Ceil(float64(targetCount)*sim.RandomFloat("Killing Spree"))) - 1
-						target = sim.GetTargetUnit(newUnitIndex)
+					if len(targetUnits) > 1 {
+						newUnitIndex := int(math.Ceil(float64(len(targetUnits))*sim.RandomFloat("Killing Spree"))) - 1
+						target = targetUnits[newUnitIndex]
 					}
 					mhWeaponSwing.Cast(sim, target)
 					ohWeaponSwing.Cast(sim, target)
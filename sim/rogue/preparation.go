@@ -35,7 +35,7 @@ func (rogue *Rogue) registerPreparationCD() {
 			// Reset Cooldown on affected spells
 			for _, affectedSpell := range affectedSpells {
 				if affectedSpell != nil {
-					affectedSpell.CD.Reset()
+					affectedSpell.ResetCooldown(sim, spell.ActionID)
 				}
 			}
 		},
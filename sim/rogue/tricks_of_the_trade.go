@@ -23,6 +23,14 @@ func (rogue *Rogue) registerTricksOfTheTradeSpell() {
 		ActionID: core.ActionID{SpellID: 59628},
 		Label:    "TricksOfTheTradeThreatTransfer",
 		Duration: core.TernaryDuration(hasGlyph, time.Second*10, time.Second*6),
+		OnGain: func(aura *core.Aura, sim *core.Simulation) {
+			if targetUnit != nil {
+				rogue.RedirectThreat(targetUnit)
+			}
+		},
+		OnExpire: func(aura *core.Aura, sim *core.Simulation) {
+			rogue.RedirectThreat(nil)
+		},
 	})
 
 	tricksOfTheTradeApplicationAura := rogue.GetOrRegisterAura(core.Aura{
@@ -30,8 +30,10 @@ func (shaman *Shaman) newChainLightningSpell(isLightningOverload bool) *core.Spe
 		}
 	}
 
-	numHits := min(core.TernaryInt32(shaman.HasMajorGlyph(proto.ShamanMajorGlyph_GlyphOfChainLightning), 4, 3), shaman.Env.GetNumTargets())
-	dmgReductionPerBounce := core.TernaryFloat64(shaman.HasSetBonus(ItemSetTidefury, 2), 0.83, 0.7)
+	spellConfig.Chain = core.ChainConfig{
+		NumHits:          core.TernaryInt32(shaman.HasMajorGlyph(proto.ShamanMajorGlyph_GlyphOfChainLightning), 4, 3),
+		BounceMultiplier: core.TernaryFloat64(shaman.HasSetBonus(ItemSetTidefury, 2), 0.83, 0.7),
+	}
 	dmgBonus := shaman.electricSpellBonusDamage(0.5714)
 	spellCoeff := 0.5714 + 0.04*float64(shaman.Talents.Shamanism)
 
@@ -39,9 +41,7 @@ func (shaman *Shaman) newChainLightningSpell(isLightningOverload bool) *core.Spe
 	lightningOverloadChance := float64(shaman.Talents.LightningOverload) * 0.11 / 3
 
 	spellConfig.ApplyEffects = func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
-		bounceCoeff := 1.0
-		curTarget := target
-		for hitIndex := int32(0); hitIndex < numHits; hitIndex++ {
+		spell.ApplyChain(sim, target, func(sim *core.Simulation, curTarget *core.Unit, hitIndex int32, bounceCoeff float64) {
 			baseDamage := dmgBonus + sim.Roll(973, 1111) + spellCoeff*spell.SpellPower()
 			baseDamage *= bounceCoeff
 			result := spell.CalcDamage(sim, curTarget, baseDamage, spell.OutcomeMagicHitAndCrit)
@@ -50,11 +50,8 @@ func (shaman *Shaman) newChainLightningSpell(isLightningOverload bool) *core.Spe
 				shaman.ChainLightningLOs[hitIndex].Cast(sim, curTarget)
 			}
 
-			spell.DealDamage(sim, result)
-
-			bounceCoeff *= dmgReductionPerBounce
-			curTarget = sim.Environment.NextTargetUnit(curTarget)
-		}
+			spell.DealBounceDamage(sim, result, hitIndex)
+		})
 	}
 
 	return shaman.RegisterSpell(spellConfig)
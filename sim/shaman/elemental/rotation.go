@@ -55,7 +55,7 @@ func (rotation *AdaptiveRotation) DoAction(eleShaman *ElementalShaman, sim *core
 	shouldTS := false
 	cmp := eleShaman.CurrentManaPercent()
 	percent := sim.GetRemainingDurationPercent() - 0.1
-	if eleShaman.Env.GetNumTargets() > 1 {
+	if sim.GetNumActiveTargets() > 1 {
 		percent = 0.9 // single target we need less mana.
 	}
 	if cmp < percent {
@@ -100,7 +100,7 @@ func (rotation *AdaptiveRotation) DoAction(eleShaman *ElementalShaman, sim *core
 	if cmp > rotation.clmm && eleShaman.ChainLightning.IsReady(sim) {
 		clTime := eleShaman.ApplyCastSpeed(eleShaman.ChainLightning.DefaultCast.CastTime)
 		// Only CL if cast time > 1 second than CL or there is more than 1 target.
-		if clTime > core.GCDMin || eleShaman.Env.GetNumTargets() > 1 {
+		if clTime > core.GCDMin || sim.GetNumActiveTargets() > 1 {
 			if !eleShaman.ChainLightning.Cast(sim, target) {
 				eleShaman.WaitForMana(sim, eleShaman.ChainLightning.CurCast.Cost)
 			}
@@ -182,7 +182,7 @@ func (rotation *ManualRotation) DoAction(eleShaman *ElementalShaman, sim *core.S
 
 	// TODO: expose these percents to let user tweak
 	percent := sim.GetRemainingDurationPercent() - 0.1
-	if eleShaman.Env.GetNumTargets() > 1 {
+	if sim.GetNumActiveTargets() > 1 {
 		percent = 0.9
 	}
 	if cmp < percent {
@@ -216,7 +216,7 @@ func (rotation *ManualRotation) DoAction(eleShaman *ElementalShaman, sim *core.S
 
 	// Never cast CL if single target and cast time <= 1 second
 	// This is effecively a waste of haste (that is probably temporary.)
-	if clTime <= time.Second && eleShaman.Env.GetNumTargets() == 1 {
+	if clTime <= time.Second && sim.GetNumActiveTargets() == 1 {
 		shouldCL = false
 	}
 	lvbCD := eleShaman.LavaBurst.CD.TimeToReady(sim)
@@ -160,25 +160,11 @@ func (enh *EnhancementShaman) ApplySyncType(syncType proto.ShamanSyncType) {
 
 	switch syncType {
 	case proto.ShamanSyncType_SyncMainhandOffhandSwings:
-		enh.AutoAttacks.SetReplaceMHSwing(func(sim *core.Simulation, mhSwingSpell *core.Spell) *core.Spell {
-			if aa := &enh.AutoAttacks; aa.OffhandSwingAt()-sim.CurrentTime > FlurryICD {
-				if nextMHSwingAt := sim.CurrentTime + aa.MainhandSwingSpeed(); nextMHSwingAt > aa.OffhandSwingAt() {
-					aa.SetOffhandSwingAt(nextMHSwingAt)
-				}
-			}
-			return mhSwingSpell
-		})
+		enh.AutoAttacks.SetOffhandSync(&core.OffhandSyncOptions{MinGap: FlurryICD})
 	case proto.ShamanSyncType_DelayOffhandSwings:
-		enh.AutoAttacks.SetReplaceMHSwing(func(sim *core.Simulation, mhSwingSpell *core.Spell) *core.Spell {
-			if aa := &enh.AutoAttacks; aa.OffhandSwingAt()-sim.CurrentTime > FlurryICD {
-				if nextMHSwingAt := sim.CurrentTime + aa.MainhandSwingSpeed() + 100*time.Millisecond; nextMHSwingAt > aa.OffhandSwingAt() {
-					aa.SetOffhandSwingAt(nextMHSwingAt)
-				}
-			}
-			return mhSwingSpell
-		})
+		enh.AutoAttacks.SetOffhandSync(&core.OffhandSyncOptions{MinGap: FlurryICD, Delay: 100 * time.Millisecond})
 	default:
-		enh.AutoAttacks.SetReplaceMHSwing(nil)
+		enh.AutoAttacks.SetOffhandSync(nil)
 	}
 }
 
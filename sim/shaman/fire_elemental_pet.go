@@ -137,12 +137,7 @@ func (fireElemental *FireElemental) TryCast(sim *core.Simulation, target *core.U
 		return false
 	}
 
-	if !spell.Cast(sim, target) {
-		return false
-	}
-	// all spell casts reset the elemental's swing timer
-	fireElemental.AutoAttacks.StopMeleeUntil(sim, sim.CurrentTime+spell.CurCast.CastTime, false)
-	return true
+	return spell.Cast(sim, target)
 }
 
 var fireElementalPetBaseStats = stats.Stats{
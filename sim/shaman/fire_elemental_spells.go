@@ -11,6 +11,7 @@ func (fireElemental *FireElemental) registerFireBlast() {
 		ActionID:    core.ActionID{SpellID: 13339},
 		SpellSchool: core.SpellSchoolFire,
 		ProcMask:    core.ProcMaskSpellDamage,
+		Flags:       core.SpellFlagResetsSwingTimer,
 
 		ManaCost: core.ManaCostOptions{
 			FlatCost: 276,
@@ -43,6 +44,7 @@ func (fireElemental *FireElemental) registerFireNova() {
 		ActionID:    core.ActionID{SpellID: 12470},
 		SpellSchool: core.SpellSchoolFire,
 		ProcMask:    core.ProcMaskSpellDamage,
+		Flags:       core.SpellFlagResetsSwingTimer,
 
 		ManaCost: core.ManaCostOptions{
 			FlatCost: 207,
@@ -312,8 +312,6 @@ func (shaman *Shaman) registerChainHealSpell() {
 
 	hasGlyph := shaman.HasMajorGlyph(proto.ShamanMajorGlyph_GlyphOfChainHeal)
 
-	numHits := min(core.TernaryInt32(hasGlyph, 4, 3), int32(len(shaman.Env.Raid.AllUnits)))
-
 	bonusHeal := 0 +
 		core.TernaryFloat64(shaman.Ranged().ID == 28523, 87, 0) +
 		core.TernaryFloat64(shaman.Ranged().ID == 38368, 102, 0) +
@@ -344,13 +342,14 @@ func (shaman *Shaman) registerChainHealSpell() {
 		CritMultiplier:   shaman.DefaultHealingCritMultiplier(),
 		ThreatMultiplier: 1 - (float64(shaman.Talents.HealingGrace) * 0.05),
 
+		Chain: core.ChainConfig{
+			NumHits:          core.TernaryInt32(hasGlyph, 4, 3),
+			BounceMultiplier: 0.6,
+			TargetRule:       core.ChainTargetMostInjuredAlly,
+		},
+
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
-			bounceCoeff := 1.0
-			dmgReductionPerBounce := 0.6
-			curTarget := target
-			// TODO: This bounces to most hurt friendly...
-			targets := sim.Environment.Raid.GetFirstNPlayersOrPets(numHits)
-			for hitIndex := int32(0); hitIndex < numHits; hitIndex++ {
+			spell.ApplyChain(sim, target, func(sim *core.Simulation, curTarget *core.Unit, hitIndex int32, bounceCoeff float64) {
 				healPower := spell.HealingPower(target)
 				baseHealing := sim.Roll(1055, 1205) + spellCoeff*healPower + bonusHeal
 				baseHealing *= bounceCoeff
@@ -361,8 +360,10 @@ func (shaman *Shaman) registerChainHealSpell() {
 					baseHealing *= 1.25
 				}
 
-				result := spell.CalcAndDealHealing(sim, curTarget, baseHealing, spell.OutcomeHealingCrit)
-				if result.Outcome.Matches(core.OutcomeCrit) {
+				result := spell.CalcHealing(sim, curTarget, baseHealing, spell.OutcomeHealingCrit)
+				isCrit := result.Outcome.Matches(core.OutcomeCrit)
+				spell.DealBounceHealing(sim, result, hitIndex)
+				if isCrit {
 					if impShieldChance > 0 {
 						if sim.RandomFloat("imp water shield") > impShieldChance {
 							shaman.AddMana(sim, impShieldManaGain, shaman.waterShieldManaMetrics)
@@ -373,10 +374,7 @@ func (shaman *Shaman) registerChainHealSpell() {
 					shaman.tidalWaveProc.Activate(sim)
 					shaman.tidalWaveProc.SetStacks(sim, 2)
 				}
-
-				bounceCoeff *= dmgReductionPerBounce
-				curTarget = targets[hitIndex]
-			}
+			})
 		},
 	})
 }
@@ -248,31 +248,17 @@ var ItemSetFrostWitchBattlegear = core.NewItemSet(core.ItemSet{
 
 var ItemSetGladiatorsEarthshaker = core.NewItemSet(core.ItemSet{
 	Name: "Gladiator's Earthshaker",
-	Bonuses: map[int32]core.ApplyEffect{
-		2: func(agent core.Agent) {
-			shaman := agent.(ShamanAgent).GetShaman()
-			shaman.AddStat(stats.AttackPower, 50)
-			shaman.AddStat(stats.Resilience, 100)
-		},
-		4: func(agent core.Agent) {
-			shaman := agent.(ShamanAgent).GetShaman()
-			shaman.AddStat(stats.AttackPower, 150)
-			// also -2s on stormstrike CD
-		},
+	Records: map[int32]core.SetBonusRecord{
+		2: {Stats: stats.Stats{stats.AttackPower: 50, stats.Resilience: 100}},
+		// also -2s on stormstrike CD
+		4: {Stats: stats.Stats{stats.AttackPower: 150}},
 	},
 })
 
 var ItemSetGladiatorsWartide = core.NewItemSet(core.ItemSet{
 	Name: "Gladiator's Wartide",
-	Bonuses: map[int32]core.ApplyEffect{
-		2: func(agent core.Agent) {
-			shaman := agent.(ShamanAgent).GetShaman()
-			shaman.AddStat(stats.SpellPower, 29)
-			shaman.AddStat(stats.Resilience, 100)
-		},
-		4: func(agent core.Agent) {
-			shaman := agent.(ShamanAgent).GetShaman()
-			shaman.AddStat(stats.SpellPower, 88)
-		},
+	Records: map[int32]core.SetBonusRecord{
+		2: {Stats: stats.Stats{stats.SpellPower: 29, stats.Resilience: 100}},
+		4: {Stats: stats.Stats{stats.SpellPower: 88}},
 	},
 })
@@ -34,6 +34,7 @@ func (warlock *Warlock) registerCurseOfElementsSpell() {
 			if result.Landed() {
 				warlock.CurseOfElementsAuras.Get(target).Activate(sim)
 			}
+			spell.DisposeResult(result)
 		},
 
 		RelatedAuras: []core.AuraArray{warlock.CurseOfElementsAuras},
@@ -69,6 +70,7 @@ func (warlock *Warlock) registerCurseOfWeaknessSpell() {
 			if result.Landed() {
 				warlock.CurseOfWeaknessAuras.Get(target).Activate(sim)
 			}
+			spell.DisposeResult(result)
 		},
 
 		RelatedAuras: []core.AuraArray{warlock.CurseOfWeaknessAuras},
@@ -111,6 +113,7 @@ func (warlock *Warlock) registerCurseOfTonguesSpell() {
 			if result.Landed() {
 				warlock.CurseOfTonguesAuras.Get(target).Activate(sim)
 			}
+			spell.DisposeResult(result)
 		},
 
 		RelatedAuras: []core.AuraArray{warlock.CurseOfTonguesAuras},
@@ -169,6 +172,7 @@ func (warlock *Warlock) registerCurseOfAgonySpell() {
 				warlock.CurseOfDoom.Dot(target).Cancel(sim)
 				spell.Dot(target).Apply(sim)
 			}
+			spell.DisposeResult(result)
 		},
 	})
 }
@@ -220,6 +224,7 @@ func (warlock *Warlock) registerCurseOfDoomSpell() {
 				warlock.CurseOfAgony.Dot(target).Cancel(sim)
 				spell.Dot(target).Apply(sim)
 			}
+			spell.DisposeResult(result)
 		},
 	})
 }
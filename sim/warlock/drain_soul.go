@@ -83,6 +83,7 @@ func (warlock *Warlock) registerDrainSoulSpell() {
 
 				warlock.everlastingAfflictionRefresh(sim, target)
 			}
+			spell.DisposeResult(result)
 		},
 		ExpectedTickDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, useSnapshot bool) *core.SpellResult {
 			if useSnapshot {
@@ -149,12 +149,10 @@ var ItemSetDarkCovensRegalia = core.NewItemSet(core.ItemSet{
 				},
 			})
 
-			var petDeviousMindsAura *core.Aura
 			if warlock.Pet != nil {
-				petDeviousMindsAura = warlock.Pet.RegisterAura(core.Aura{
+				warlock.Pet.RegisterOwnerLinkedAura(deviousMindsAura, core.Aura{
 					Label:    "Devious Minds",
 					ActionID: core.ActionID{SpellID: 70840},
-					Duration: time.Second * 10,
 					OnGain: func(aura *core.Aura, sim *core.Simulation) {
 						aura.Unit.PseudoStats.DamageDealtMultiplier *= 1.1
 					},
@@ -174,9 +172,6 @@ var ItemSetDarkCovensRegalia = core.NewItemSet(core.ItemSet{
 					if spell == warlock.UnstableAffliction || spell == warlock.Immolate {
 						if sim.Proc(0.15, "4pT10") {
 							deviousMindsAura.Activate(sim)
-							if petDeviousMindsAura != nil {
-								petDeviousMindsAura.Activate(sim)
-							}
 						}
 					}
 				},
@@ -122,15 +122,12 @@ func (warlock *Warlock) NewWarlockPet() *WarlockPet {
 	wp.EnableManaBarWithModifier(cfg.PowerModifier)
 	wp.EnableResumeAfterManaWait(wp.OnGCDReady)
 
-	wp.AddStatDependency(stats.Strength, stats.AttackPower, 2)
 	wp.AddStat(stats.AttackPower, -20)
-
-	if warlock.Options.Summon == proto.Warlock_Options_Imp {
+	wp.AddMeleeStatDependencies(core.PetMeleeScaling{
+		AttackPowerPerStrength: 2,
 		// imp has a slightly different agi crit scaling coef for some reason
-		wp.AddStatDependency(stats.Agility, stats.MeleeCrit, core.CritRatingPerCritChance*1/51.0204)
-	} else {
-		wp.AddStatDependency(stats.Agility, stats.MeleeCrit, core.CritRatingPerCritChance*1/52.0833)
-	}
+		AgilityPerCritPercent: core.TernaryFloat64(warlock.Options.Summon == proto.Warlock_Options_Imp, 51.0204, 52.0833),
+	})
 
 	wp.AddStats(stats.Stats{
 		stats.MeleeCrit: float64(warlock.Talents.DemonicTactics) * 2 * core.CritRatingPerCritChance,
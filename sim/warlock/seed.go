@@ -114,6 +114,7 @@ func (warlock *Warlock) registerSeedSpell() {
 						spell.Dot(target).Apply(sim)
 					}
 				}
+				spell.DisposeResult(result)
 			})
 		},
 	})
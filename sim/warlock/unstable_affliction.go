@@ -68,6 +68,7 @@ func (warlock *Warlock) registerUnstableAfflictionSpell() {
 				spell.SpellMetrics[target.UnitIndex].Hits--
 				spell.Dot(target).Apply(sim)
 			}
+			spell.DisposeResult(result)
 		},
 	})
 }
@@ -169,15 +169,9 @@ func (warlock *Warlock) Initialize() {
 			return
 		}
 
-		precastSpellAt := -warlock.ApplyCastSpeedForSpell(precastSpell.DefaultCast.CastTime, precastSpell)
-
-		warlock.RegisterPrepullAction(precastSpellAt, func(sim *core.Simulation) {
-			precastSpell.Cast(sim, warlock.CurrentTarget)
-		})
+		precastSpellAt := warlock.RegisterPrepullCast(precastSpell, 0)
 		if warlock.GlyphOfLifeTapAura != nil || warlock.SpiritsoftheDamnedAura != nil {
-			warlock.RegisterPrepullAction(precastSpellAt-warlock.SpellGCD(), func(sim *core.Simulation) {
-				warlock.LifeTap.Cast(sim, nil)
-			})
+			warlock.RegisterPrepullSpell(precastSpellAt-warlock.SpellGCD(), warlock.LifeTap, nil)
 		}
 		if warlock.ItemSwap.IsEnabled() {
 			warlock.AddStats(correction.Invert())
@@ -1,6 +1,7 @@
 package warlock
 
 import (
+	"fmt"
 	"testing"
 
 	_ "github.com/wowsims/wotlk/sim/common"
@@ -65,6 +66,45 @@ func TestDestruction(t *testing.T) {
 	}))
 }
 
+// Affliction multidots up to 4 targets and seeds the rest, so this keeps a lot
+// of DoT ticks queued at once.
+func multiTargetBenchmarkRequest(numTargets int) *proto.RaidSimRequest {
+	targets := make([]*proto.Target, numTargets)
+	for i := range targets {
+		targets[i] = core.NewDefaultTarget()
+	}
+
+	return &proto.RaidSimRequest{
+		Raid: core.SinglePlayerRaidProto(
+			&proto.Player{
+				Race:          proto.Race_RaceOrc,
+				Class:         proto.Class_ClassWarlock,
+				Equipment:     core.GetGearSet("../../ui/warlock/gear_sets", "p3_affliction_alliance").GearSet,
+				Consumes:      FullConsumes,
+				Spec:          DefaultAfflictionWarlock,
+				Glyphs:        AfflictionGlyphs,
+				TalentsString: AfflictionTalents,
+				Buffs:         core.FullIndividualBuffs,
+			},
+			core.FullPartyBuffs,
+			core.FullRaidBuffs,
+			core.FullDebuffs),
+		Encounter: &proto.Encounter{
+			Duration: 300,
+			Targets:  targets,
+		},
+		SimOptions: core.AverageDefaultSimTestOptions,
+	}
+}
+
+func BenchmarkSimulateMultiTarget(b *testing.B) {
+	for _, numTargets := range []int{1, 3, 8} {
+		b.Run(fmt.Sprintf("%dTargets", numTargets), func(b *testing.B) {
+			core.RaidBenchmark(b, multiTargetBenchmarkRequest(numTargets))
+		})
+	}
+}
+
 var ItemFilter = core.ItemFilter{
 	WeaponTypes: []proto.WeaponType{
 		proto.WeaponType_WeaponTypeSword,
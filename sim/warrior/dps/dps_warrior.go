@@ -59,8 +59,7 @@ func NewDpsWarrior(character *core.Character, options *proto.Player) *DpsWarrior
 	}
 
 	rbo := core.RageBarOptions{
-		StartingRage:   warOptions.Options.StartingRage,
-		RageMultiplier: core.TernaryFloat64(war.Talents.EndlessRage, 1.25, 1),
+		StartingRage: warOptions.Options.StartingRage,
 	}
 	if mh := war.GetMHWeapon(); mh != nil {
 		rbo.MHSwingSpeed = mh.SwingSpeed
@@ -144,12 +143,10 @@ func (war *DpsWarrior) Initialize() {
 func (war *DpsWarrior) Reset(sim *core.Simulation) {
 	if war.Rotation.StanceOption == proto.Warrior_Rotation_BerserkerStance {
 		war.Warrior.Reset(sim)
-		war.BerserkerStanceAura.Activate(sim)
-		war.Stance = warrior.BerserkerStance
+		war.SetStance(sim, warrior.BerserkerStance)
 	} else if war.Rotation.StanceOption == proto.Warrior_Rotation_BattleStance {
 		war.Warrior.Reset(sim)
-		war.BattleStanceAura.Activate(sim)
-		war.Stance = warrior.BattleStance
+		war.SetStance(sim, warrior.BattleStance)
 	}
 	war.canSwapStanceAt = 0
 	war.maintainSunder = war.Rotation.SunderArmor != proto.Warrior_Rotation_SunderArmorNone
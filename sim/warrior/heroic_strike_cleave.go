@@ -74,6 +74,7 @@ func (warrior *Warrior) registerCleaveSpell() *core.Spell {
 		FlatThreatBonus:  225,
 
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
+			numHits := min(numHits, sim.GetNumActiveTargets())
 			curTarget := target
 			for hitIndex := int32(0); hitIndex < numHits; hitIndex++ {
 				baseDamage := flatDamageBonus +
@@ -43,18 +43,10 @@ var ItemSetOnslaughtBattlegear = core.NewItemSet(core.ItemSet{
 
 var ItemSetGladiatorsBattlegear = core.NewItemSet(core.ItemSet{
 	Name: "Gladiator's Battlegear",
-	Bonuses: map[int32]core.ApplyEffect{
-		2: func(agent core.Agent) {
-			// Increases attack power by 50.
-			// +100 resilience rating.
-			agent.GetCharacter().AddStat(stats.Resilience, 100)
-			agent.GetCharacter().AddStat(stats.AttackPower, 50)
-		},
-		4: func(agent core.Agent) {
-			// Reduces the cooldown of your Intercept ability by 5 sec.
-			// Increases attack power by 150.
-			agent.GetCharacter().AddStat(stats.AttackPower, 150)
-		},
+	Records: map[int32]core.SetBonusRecord{
+		2: {Stats: stats.Stats{stats.AttackPower: 50, stats.Resilience: 100}},
+		// Intercept cooldown reduction isn't modeled.
+		4: {Stats: stats.Stats{stats.AttackPower: 150}},
 	},
 })
 
@@ -92,7 +84,7 @@ var ItemSetWrynnsPlate = core.NewItemSet(core.ItemSet{
 	Bonuses: map[int32]core.ApplyEffect{
 		2: func(agent core.Agent) {
 			// Decreases the cooldown on Taunt by 2sec
-			// NYI
+			// Handled in taunt.go
 
 			// Increases damage done by Devastate by 5%
 			// Handled in devastate.go
@@ -61,8 +61,9 @@ func (warrior *Warrior) registerOverpowerSpell(cdTimer *core.Timer) {
 				Duration: cooldownDur,
 			},
 		},
+		RequiredStances: BattleStance,
 		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
-			return warrior.OverpowerAura.IsActive() && warrior.StanceMatches(BattleStance)
+			return warrior.OverpowerAura.IsActive()
 		},
 
 		BonusCritRating:  25 * core.CritRatingPerCritChance * float64(warrior.Talents.ImprovedOverpower),
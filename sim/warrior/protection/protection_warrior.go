@@ -45,8 +45,7 @@ func NewProtectionWarrior(character *core.Character, options *proto.Player) *Pro
 	}
 
 	rbo := core.RageBarOptions{
-		StartingRage:   warOptions.Options.StartingRage,
-		RageMultiplier: core.TernaryFloat64(war.Talents.EndlessRage, 1.25, 1),
+		StartingRage: warOptions.Options.StartingRage,
 	}
 	if mh := war.GetMHWeapon(); mh != nil {
 		rbo.MHSwingSpeed = mh.SwingSpeed
@@ -102,7 +101,6 @@ func (war *ProtectionWarrior) Initialize() {
 
 func (war *ProtectionWarrior) Reset(sim *core.Simulation) {
 	war.Warrior.Reset(sim)
-	war.DefensiveStanceAura.Activate(sim)
-	war.Stance = warrior.DefensiveStance
+	war.SetStance(sim, warrior.DefensiveStance)
 	war.Warrior.PseudoStats.Stunned = false
 }
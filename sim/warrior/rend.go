@@ -33,9 +33,7 @@ func (warrior *Warrior) RegisterRendSpell(rageThreshold float64, healthThreshold
 			IgnoreHaste: true,
 		},
 
-		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
-			return warrior.StanceMatches(BattleStance)
-		},
+		RequiredStances: BattleStance,
 
 		DamageMultiplier: 1 + 0.1*float64(warrior.Talents.ImprovedRend),
 		ThreatMultiplier: 1,
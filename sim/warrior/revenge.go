@@ -36,16 +36,11 @@ func (warrior *Warrior) registerRevengeSpell(cdTimer *core.Timer) {
 		})
 	}
 
-	warrior.RegisterAura(core.Aura{
-		Label:    "Revenge Trigger",
-		Duration: core.NeverExpires,
-		OnReset: func(aura *core.Aura, sim *core.Simulation) {
-			aura.Activate(sim)
-		},
-		OnSpellHitTaken: func(aura *core.Aura, sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
-			if result.Outcome.Matches(core.OutcomeBlock | core.OutcomeDodge | core.OutcomeParry) {
-				warrior.revengeProcAura.Activate(sim)
-			}
+	warrior.RegisterDamageTakenGain(core.DamageTakenGain{
+		Label:   "Revenge Trigger",
+		Outcome: core.OutcomeBlock | core.OutcomeDodge | core.OutcomeParry,
+		OnGain: func(sim *core.Simulation, _ *core.Spell, _ *core.SpellResult, _ float64) {
+			warrior.revengeProcAura.Activate(sim)
 		},
 	})
 
@@ -81,8 +76,9 @@ func (warrior *Warrior) registerRevengeSpell(cdTimer *core.Timer) {
 				Duration: cooldownDur,
 			},
 		},
+		RequiredStances: DefensiveStance,
 		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
-			return warrior.StanceMatches(DefensiveStance) && warrior.revengeProcAura.IsActive()
+			return warrior.revengeProcAura.IsActive()
 		},
 
 		DamageMultiplier: 1.0 + 0.1*float64(warrior.Talents.UnrelentingAssault) + 0.3*float64(warrior.Talents.ImprovedRevenge),
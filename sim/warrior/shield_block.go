@@ -37,8 +37,9 @@ func (warrior *Warrior) RegisterShieldBlockCD() {
 				Duration: cooldownDur,
 			},
 		},
+		RequiredStances: DefensiveStance,
 		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
-			return warrior.PseudoStats.CanBlock && warrior.StanceMatches(DefensiveStance)
+			return warrior.PseudoStats.CanBlock
 		},
 
 		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
@@ -47,9 +47,7 @@ func (warrior *Warrior) RegisterShieldWallCD() {
 				Duration: cooldownDur,
 			},
 		},
-		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
-			return warrior.StanceMatches(DefensiveStance)
-		},
+		RequiredStances: DefensiveStance,
 
 		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
 			swAura.Activate(sim)
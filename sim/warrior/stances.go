@@ -7,7 +7,7 @@ import (
 	"github.com/wowsims/wotlk/sim/core/stats"
 )
 
-type Stance uint8
+type Stance = core.Stance
 
 const (
 	BattleStance Stance = 1 << iota
@@ -15,20 +15,13 @@ const (
 	BerserkerStance
 )
 
-const stanceEffectCategory = "Stance"
-
-func (warrior *Warrior) StanceMatches(other Stance) bool {
-	return (warrior.Stance & other) != 0
-}
-
 func (warrior *Warrior) makeStanceSpell(stance Stance, aura *core.Aura, stanceCD *core.Timer) *core.Spell {
 	maxRetainedRage := 10.0 + 5*float64(warrior.Talents.TacticalMastery)
-	actionID := aura.ActionID
-	rageMetrics := warrior.NewRageMetrics(actionID)
+	rageMetrics := warrior.NewRageMetrics(aura.ActionID)
 
-	return warrior.RegisterSpell(core.SpellConfig{
-		ActionID: actionID,
-		Flags:    core.SpellFlagNoOnCastComplete | core.SpellFlagAPL,
+	return warrior.RegisterStanceSpell(core.StanceSpellConfig{
+		Stance: stance,
+		Flags:  core.SpellFlagNoOnCastComplete | core.SpellFlagAPL,
 
 		Cast: core.CastConfig{
 			CD: core.Cooldown{
@@ -36,27 +29,15 @@ func (warrior *Warrior) makeStanceSpell(stance Stance, aura *core.Aura, stanceCD
 				Duration: time.Second,
 			},
 		},
-		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
-			return warrior.Stance != stance
-		},
 
-		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
+		// Delayed, so same-GCD casts are affected by the current aura.
+		//  Alternatively, those casts could just (artificially) happen before the stance change.
+		AuraDelay: core.TernaryDuration(warrior.WarriorInputs.StanceSnapshot, 10*time.Millisecond, 0),
+
+		OnSwitch: func(sim *core.Simulation) {
 			if warrior.CurrentRage() > maxRetainedRage {
 				warrior.SpendRage(sim, warrior.CurrentRage()-maxRetainedRage, rageMetrics)
 			}
-
-			if warrior.WarriorInputs.StanceSnapshot {
-				// Delayed, so same-GCD casts are affected by the current aura.
-				//  Alternatively, those casts could just (artificially) happen before the stance change.
-				core.StartDelayedAction(sim, core.DelayedActionOptions{
-					DoAt:     sim.CurrentTime + 10*time.Millisecond,
-					OnAction: aura.Activate,
-				})
-			} else {
-				aura.Activate(sim)
-			}
-
-			warrior.Stance = stance
 		},
 	})
 }
@@ -80,7 +61,6 @@ func (warrior *Warrior) registerBattleStanceAura() {
 			aura.Unit.AddStatDynamic(sim, stats.ArmorPenetration, -armorPenBonus)
 		},
 	})
-	warrior.BattleStanceAura.NewExclusiveEffect(stanceEffectCategory, true, core.ExclusiveEffect{})
 }
 
 func (warrior *Warrior) registerDefensiveStanceAura() {
@@ -155,7 +135,6 @@ func (warrior *Warrior) registerDefensiveStanceAura() {
 			}
 		},
 	})
-	warrior.DefensiveStanceAura.NewExclusiveEffect(stanceEffectCategory, true, core.ExclusiveEffect{})
 }
 
 func (warrior *Warrior) registerBerserkerStanceAura() {
@@ -187,7 +166,6 @@ func (warrior *Warrior) registerBerserkerStanceAura() {
 			}
 		},
 	})
-	warrior.BerserkerStanceAura.NewExclusiveEffect(stanceEffectCategory, true, core.ExclusiveEffect{})
 }
 
 func (warrior *Warrior) registerStances() {
@@ -195,6 +173,9 @@ func (warrior *Warrior) registerStances() {
 	warrior.registerBattleStanceAura()
 	warrior.registerDefensiveStanceAura()
 	warrior.registerBerserkerStanceAura()
+	warrior.RegisterStance(BattleStance, warrior.BattleStanceAura)
+	warrior.RegisterStance(DefensiveStance, warrior.DefensiveStanceAura)
+	warrior.RegisterStance(BerserkerStance, warrior.BerserkerStanceAura)
 	warrior.BattleStance = warrior.makeStanceSpell(BattleStance, warrior.BattleStanceAura, stanceCD)
 	warrior.DefensiveStance = warrior.makeStanceSpell(DefensiveStance, warrior.DefensiveStanceAura, stanceCD)
 	warrior.BerserkerStance = warrior.makeStanceSpell(BerserkerStance, warrior.BerserkerStanceAura, stanceCD)
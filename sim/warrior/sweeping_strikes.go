@@ -77,7 +77,7 @@ func (warrior *Warrior) registerSweepingStrikesCD() {
 			},
 		},
 		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
-			return sim.GetNumTargets() > 1
+			return sim.GetNumActiveTargets() > 1
 		},
 
 		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
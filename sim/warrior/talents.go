@@ -21,6 +21,10 @@ func (warrior *Warrior) ApplyTalents() {
 	warrior.PseudoStats.DodgeReduction += 0.01 * float64(warrior.Talents.WeaponMastery)
 	warrior.AutoAttacks.OHConfig().DamageMultiplier *= 1 + 0.05*float64(warrior.Talents.DualWieldSpecialization)
 
+	if warrior.Talents.EndlessRage {
+		warrior.PseudoStats.RageFromDamageDealtMultiplier *= 1.25
+	}
+
 	if warrior.Talents.ArmoredToTheTeeth > 0 {
 		coeff := float64(warrior.Talents.ArmoredToTheTeeth)
 		warrior.AddStatDependency(stats.Armor, stats.AttackPower, coeff/108.0)
@@ -73,21 +77,7 @@ func (warrior *Warrior) applyCriticalBlock() {
 		return
 	}
 
-	dummyCriticalBlockSpell := warrior.GetOrRegisterSpell(core.SpellConfig{
-		ActionID: core.ActionID{SpellID: 47296},
-		Flags:    core.SpellFlagMeleeMetrics | core.SpellFlagNoOnCastComplete,
-	})
-
-	warrior.AddDynamicDamageTakenModifier(func(sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
-		if result.Outcome.Matches(core.OutcomeBlock) && !result.Outcome.Matches(core.OutcomeMiss) && !result.Outcome.Matches(core.OutcomeParry) && !result.Outcome.Matches(core.OutcomeDodge) {
-			procChance := 0.2 * float64(warrior.Talents.CriticalBlock)
-			if sim.RandomFloat("Critical Block Roll") <= procChance {
-				blockValue := warrior.BlockValue()
-				result.Damage = max(0, result.Damage-blockValue)
-				dummyCriticalBlockSpell.Cast(sim, spell.Unit)
-			}
-		}
-	})
+	warrior.RegisterCriticalBlock(core.ActionID{SpellID: 47296}, 0.2*float64(warrior.Talents.CriticalBlock))
 }
 
 func (warrior *Warrior) applyDamageShield() {
@@ -96,34 +86,14 @@ func (warrior *Warrior) applyDamageShield() {
 	}
 
 	coeff := 0.1 * float64(warrior.Talents.DamageShield)
-	damageShieldProcSpell := warrior.GetOrRegisterSpell(core.SpellConfig{
+	core.MakePermanent(warrior.RegisterDamageShield(core.DamageShieldConfig{
+		Label:       "Damage Shield",
 		ActionID:    core.ActionID{SpellID: 58874},
 		SpellSchool: core.SpellSchoolPhysical,
-		ProcMask:    core.ProcMaskEmpty,
 		Flags:       core.SpellFlagMeleeMetrics | core.SpellFlagNoOnCastComplete,
-
-		DamageMultiplier: 1,
-		ThreatMultiplier: 1,
-
-		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
-			baseDamage := coeff * warrior.BlockValue()
-			spell.CalcAndDealDamage(sim, target, baseDamage, spell.OutcomeAlwaysHit)
-		},
-	})
-
-	core.MakePermanent(warrior.GetOrRegisterAura(core.Aura{
-		Label:    "Damage Shield Trigger",
-		Duration: core.NeverExpires,
-		OnSpellHitTaken: func(aura *core.Aura, sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
-			if !result.Landed() && !result.Outcome.Matches(core.OutcomeBlock) {
-				return
-			}
-
-			if spell.SpellSchool != core.SpellSchoolPhysical {
-				return
-			}
-
-			damageShieldProcSpell.Cast(sim, spell.Unit)
+		CannotMiss:  true,
+		BaseDamage: func(_ *core.Simulation) float64 {
+			return coeff * warrior.BlockValue()
 		},
 	}))
 }
@@ -664,23 +634,13 @@ func (warrior *Warrior) applyShieldSpecialization() {
 
 	warrior.AddStat(stats.Block, core.BlockRatingPerBlockChance*1*float64(warrior.Talents.ShieldSpecialization))
 
-	procChance := 0.2 * float64(warrior.Talents.ShieldSpecialization)
-	rageAdded := float64(warrior.Talents.ShieldSpecialization)
-	rageMetrics := warrior.NewRageMetrics(core.ActionID{SpellID: 12727})
-
-	warrior.RegisterAura(core.Aura{
-		Label:    "Shield Specialization",
-		Duration: core.NeverExpires,
-		OnReset: func(aura *core.Aura, sim *core.Simulation) {
-			aura.Activate(sim)
-		},
-		OnSpellHitTaken: func(aura *core.Aura, sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
-			if result.Outcome.Matches(core.OutcomeBlock | core.OutcomeDodge | core.OutcomeParry) {
-				if sim.Proc(procChance, "Shield Specialization") {
-					warrior.AddRage(sim, rageAdded, rageMetrics)
-				}
-			}
-		},
+	warrior.RegisterDamageTakenGain(core.DamageTakenGain{
+		Label:      "Shield Specialization",
+		ActionID:   core.ActionID{SpellID: 12727},
+		Outcome:    core.OutcomeBlock | core.OutcomeDodge | core.OutcomeParry,
+		ProcChance: 0.2 * float64(warrior.Talents.ShieldSpecialization),
+		Resource:   proto.ResourceType_ResourceTypeRage,
+		Flat:       float64(warrior.Talents.ShieldSpecialization),
 	})
 }
 
@@ -764,9 +724,7 @@ func (warrior *Warrior) registerLastStandCD() {
 				Duration: core.TernaryDuration(warrior.HasMajorGlyph(proto.WarriorMajorGlyph_GlyphOfLastStand), time.Minute*3, time.Minute*2),
 			},
 		},
-		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
-			return warrior.StanceMatches(DefensiveStance)
-		},
+		RequiredStances: DefensiveStance,
 
 		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
 			lastStandAura.Activate(sim)
@@ -837,6 +795,7 @@ func (warrior *Warrior) RegisterBladestormCD() {
 				target := warrior.CurrentTarget
 				spell := dot.Spell
 
+				numHits := min(numHits, sim.GetNumActiveTargets())
 				curTarget := target
 				for hitIndex := int32(0); hitIndex < numHits; hitIndex++ {
 					baseDamage := 0 +
@@ -922,7 +881,7 @@ func (warrior *Warrior) applySwordAndBoard() {
 
 			if sim.RandomFloat("Sword And Board") < procChance {
 				sabAura.Activate(sim)
-				warrior.ShieldSlam.CD.Reset()
+				warrior.ShieldSlam.ResetCooldown(sim, sabAura.ActionID)
 			}
 		},
 	}))
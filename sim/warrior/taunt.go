@@ -0,0 +1,35 @@
+package warrior
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func (warrior *Warrior) registerTauntSpell() {
+	warrior.Taunt = warrior.RegisterSpell(core.SpellConfig{
+		ActionID:    core.ActionID{SpellID: 355},
+		SpellSchool: core.SpellSchoolPhysical,
+		ProcMask:    core.ProcMaskEmpty,
+		Flags:       core.SpellFlagAPL,
+
+		Cast: core.CastConfig{
+			CD: core.Cooldown{
+				Timer:    warrior.NewTimer(),
+				Duration: time.Second*8 - core.TernaryDuration(warrior.HasSetBonus(ItemSetWrynnsPlate, 2), time.Second*2, 0),
+			},
+		},
+		RequiredStances: DefensiveStance,
+
+		BonusHitRating:   core.TernaryFloat64(warrior.HasMajorGlyph(proto.WarriorMajorGlyph_GlyphOfTaunt), 8*core.SpellHitRatingPerHitChance, 0),
+		ThreatMultiplier: 1,
+
+		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
+			result := spell.CalcAndDealOutcome(sim, target, spell.OutcomeMagicHit)
+			if result.Landed() {
+				warrior.ApplyTaunt(sim, target)
+			}
+		},
+	})
+}
@@ -34,9 +34,7 @@ func (warrior *Warrior) registerThunderClapSpell() {
 				Duration: time.Second * 6,
 			},
 		},
-		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
-			return warrior.StanceMatches(BattleStance | DefensiveStance)
-		},
+		RequiredStances: BattleStance | DefensiveStance,
 
 		// Cruelty doesn't apply to Thunder Clap
 		BonusCritRating:  (float64(warrior.Talents.Incite)*5 - float64(warrior.Talents.Cruelty)*1) * core.CritRatingPerCritChance,
@@ -36,7 +36,6 @@ type Warrior struct {
 	WarriorInputs
 
 	// Current state
-	Stance               Stance
 	RendValidUntil       time.Duration
 	BloodsurgeValidUntil time.Duration
 	revengeProcAura      *core.Aura
@@ -67,6 +66,7 @@ type Warrior struct {
 	Slam                 *core.Spell
 	SunderArmor          *core.Spell
 	SunderArmorDevastate *core.Spell
+	Taunt                *core.Spell
 	ThunderClap          *core.Spell
 	Whirlwind            *core.Spell
 	WhirlwindOH          *core.Spell
@@ -140,6 +140,7 @@ func (warrior *Warrior) Initialize() {
 	warrior.registerShockwaveSpell()
 	warrior.registerConcussionBlowSpell()
 	warrior.RegisterHeroicThrow()
+	warrior.registerTauntSpell()
 
 	warrior.SunderArmor = warrior.newSunderArmorSpell(false)
 	warrior.SunderArmorDevastate = warrior.newSunderArmorSpell(true)
@@ -147,9 +148,7 @@ func (warrior *Warrior) Initialize() {
 	warrior.registerBloodrageCD()
 
 	if !warrior.IsUsingAPL && warrior.Shout != nil && warrior.PrecastShout {
-		warrior.RegisterPrepullAction(-10*time.Second, func(sim *core.Simulation) {
-			warrior.Shout.SkipCastAndApplyEffects(sim, nil)
-		})
+		warrior.RegisterPrepullEffects(-10*time.Second, warrior.Shout, nil)
 	}
 }
 
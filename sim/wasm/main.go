@@ -23,14 +23,22 @@ func init() {
 func main() {
 	c := make(chan struct{}, 0)
 
+	js.Global().Set("attackTable", js.FuncOf(attackTable))
+	js.Global().Set("statSheet", js.FuncOf(statSheet))
+	js.Global().Set("diffResults", js.FuncOf(diffResults))
+	js.Global().Set("exportCooldowns", js.FuncOf(exportCooldowns))
 	js.Global().Set("computeStats", js.FuncOf(computeStats))
 	js.Global().Set("computeStatsJson", js.FuncOf(computeStatsJson))
+	js.Global().Set("estimateMemory", js.FuncOf(estimateMemory))
 	js.Global().Set("raidSim", js.FuncOf(raidSim))
 	js.Global().Set("raidSimJson", js.FuncOf(raidSimJson))
 	js.Global().Set("raidSimAsync", js.FuncOf(raidSimAsync))
 	js.Global().Set("statWeights", js.FuncOf(statWeights))
 	js.Global().Set("statWeightsAsync", js.FuncOf(statWeightsAsync))
 	js.Global().Set("bulkSimAsync", js.FuncOf(bulkSimAsync))
+	js.Global().Set("whatIfAsync", js.FuncOf(whatIfAsync))
+	js.Global().Set("specComparisonAsync", js.FuncOf(specComparisonAsync))
+	js.Global().Set("buffContributionAsync", js.FuncOf(buffContributionAsync))
 	js.Global().Call("wasmready")
 	<-c
 }
@@ -140,6 +148,86 @@ func raidSim(this js.Value, args []js.Value) interface{} {
 	return outArray
 }
 
+func estimateMemory(this js.Value, args []js.Value) interface{} {
+	request := &proto.EstimateMemoryRequest{}
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), request); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	result := core.EstimateMemory(request)
+
+	outbytes, err := googleProto.Marshal(result)
+	if err != nil {
+		log.Printf("[ERROR] Failed to marshal result: %s", err.Error())
+		return nil
+	}
+
+	outArray := js.Global().Get("Uint8Array").New(len(outbytes))
+	js.CopyBytesToJS(outArray, outbytes)
+
+	return outArray
+}
+
+func attackTable(this js.Value, args []js.Value) interface{} {
+	request := &proto.AttackTableRequest{}
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), request); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	result := core.DumpAttackTable(request)
+
+	outbytes, err := googleProto.Marshal(result)
+	if err != nil {
+		log.Printf("[ERROR] Failed to marshal result: %s", err.Error())
+		return nil
+	}
+
+	outArray := js.Global().Get("Uint8Array").New(len(outbytes))
+	js.CopyBytesToJS(outArray, outbytes)
+
+	return outArray
+}
+
+func statSheet(this js.Value, args []js.Value) interface{} {
+	request := &proto.StatSheetRequest{}
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), request); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	result := core.ComputeStatSheet(request)
+
+	outbytes, err := googleProto.Marshal(result)
+	if err != nil {
+		log.Printf("[ERROR] Failed to marshal result: %s", err.Error())
+		return nil
+	}
+
+	outArray := js.Global().Get("Uint8Array").New(len(outbytes))
+	js.CopyBytesToJS(outArray, outbytes)
+
+	return outArray
+}
+
+func diffResults(this js.Value, args []js.Value) interface{} {
+	request := &proto.DiffResultsRequest{}
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), request); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	result := core.DiffResults(request)
+
+	outbytes, err := googleProto.Marshal(result)
+	if err != nil {
+		log.Printf("[ERROR] Failed to marshal result: %s", err.Error())
+		return nil
+	}
+
+	outArray := js.Global().Get("Uint8Array").New(len(outbytes))
+	js.CopyBytesToJS(outArray, outbytes)
+
+	return outArray
+}
+
 func raidSimJson(this js.Value, args []js.Value) interface{} {
 	rsr := &proto.RaidSimRequest{}
 	if err := protojson.Unmarshal(getArgsJson(args[0]), rsr); err != nil {
@@ -165,7 +253,7 @@ func raidSimAsync(this js.Value, args []js.Value) interface{} {
 	}
 	reporter := make(chan *proto.ProgressMetrics, 100)
 
-	go core.RunRaidSimAsync(rsr, reporter)
+	go core.RunRaidSimAsync(context.Background(), rsr, reporter)
 	return processAsyncProgress(args[1], reporter)
 }
 
@@ -196,7 +284,7 @@ func statWeightsAsync(this js.Value, args []js.Value) interface{} {
 		return nil
 	}
 	reporter := make(chan *proto.ProgressMetrics, 100)
-	core.StatWeightsAsync(rsr, reporter)
+	core.StatWeightsAsync(context.Background(), rsr, reporter)
 
 	result := processAsyncProgress(args[1], reporter)
 	return result
@@ -217,6 +305,45 @@ func bulkSimAsync(this js.Value, args []js.Value) interface{} {
 	return result
 }
 
+func whatIfAsync(this js.Value, args []js.Value) interface{} {
+	wir := &proto.WhatIfRequest{}
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), wir); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	reporter := make(chan *proto.ProgressMetrics, 100)
+	core.RunWhatIfAsync(context.Background(), wir, reporter)
+
+	result := processAsyncProgress(args[1], reporter)
+	return result
+}
+
+func specComparisonAsync(this js.Value, args []js.Value) interface{} {
+	scr := &proto.SpecComparisonRequest{}
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), scr); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	reporter := make(chan *proto.ProgressMetrics, 100)
+	core.RunSpecComparisonAsync(context.Background(), scr, reporter)
+
+	result := processAsyncProgress(args[1], reporter)
+	return result
+}
+
+func buffContributionAsync(this js.Value, args []js.Value) interface{} {
+	bcr := &proto.BuffContributionRequest{}
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), bcr); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	reporter := make(chan *proto.ProgressMetrics, 100)
+	core.RunBuffContributionAsync(context.Background(), bcr, reporter)
+
+	result := processAsyncProgress(args[1], reporter)
+	return result
+}
+
 // Assumes args[0] is a Uint8Array
 func getArgsBinary(value js.Value) []byte {
 	data := make([]byte, value.Get("length").Int())
@@ -248,7 +375,7 @@ reader:
 			js.CopyBytesToJS(outArray, outbytes)
 			progFunc.Invoke(outArray)
 
-			if progMetric.FinalWeightResult != nil || progMetric.FinalRaidResult != nil || progMetric.FinalBulkResult != nil {
+			if progMetric.FinalWeightResult != nil || progMetric.FinalRaidResult != nil || progMetric.FinalBulkResult != nil || progMetric.FinalWhatIfResult != nil || progMetric.FinalSpecComparisonResult != nil || progMetric.FinalBuffContributionResult != nil {
 				return outArray
 			}
 		}
@@ -256,3 +383,23 @@ reader:
 
 	return js.Undefined()
 }
+
+func exportCooldowns(this js.Value, args []js.Value) interface{} {
+	request := &proto.CooldownExportRequest{}
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), request); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	result := core.ExportCooldowns(request)
+
+	outbytes, err := googleProto.Marshal(result)
+	if err != nil {
+		log.Printf("[ERROR] Failed to marshal result: %s", err.Error())
+		return nil
+	}
+
+	outArray := js.Global().Get("Uint8Array").New(len(outbytes))
+	js.CopyBytesToJS(outArray, outbytes)
+
+	return outArray
+}
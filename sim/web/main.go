@@ -21,11 +21,13 @@ import (
 
 	uuid "github.com/google/uuid"
 	"github.com/pkg/browser"
+	presetpacks "github.com/wowsims/wotlk/assets/preset_packs"
 	dist "github.com/wowsims/wotlk/binary_dist"
 	"github.com/wowsims/wotlk/sim"
 	"github.com/wowsims/wotlk/sim/core"
 	proto "github.com/wowsims/wotlk/sim/core/proto"
 
+	"google.golang.org/protobuf/encoding/protodelim"
 	googleProto "google.golang.org/protobuf/proto"
 )
 
@@ -48,6 +50,8 @@ func main() {
 	var host = flag.String("host", "localhost:3333", "URL to host the interface on.")
 	var launch = flag.Bool("launch", true, "auto launch browser")
 	var skipVersionCheck = flag.Bool("nvc", false, "set true to skip version check")
+	var presetPacksDir = flag.String("presetPacks", "", "Directory of extra preset pack files to load, in addition to the ones in the release.")
+	var simTimeout = flag.Duration("timeout", 0, "cancel sims which run longer than this, returning partial results (0 for no limit)")
 
 	flag.Parse()
 
@@ -82,63 +86,115 @@ func main() {
 		}()
 	}
 
+	if err := core.LoadPresetPacks(presetpacks.FS()); err != nil {
+		log.Fatalf("Failed to load embedded preset packs: %s", err)
+	}
+	if *presetPacksDir != "" {
+		if err := core.LoadPresetPacks(os.DirFS(*presetPacksDir)); err != nil {
+			log.Fatalf("Failed to load preset packs: %s", err)
+		}
+	}
+
 	s := &server{
 		progMut:         sync.RWMutex{},
 		asyncProgresses: map[string]*asyncProgress{},
+		simTimeout:      *simTimeout,
 	}
 	s.runServer(*useFS, *host, *launch, *simName, *wasm, bufio.NewReader(os.Stdin))
 }
 
 // Handlers to decode and handle each proto function
 var handlers = map[string]apiHandler{
-	"/raidSim": {msg: func() googleProto.Message { return &proto.RaidSimRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
-		return core.RunRaidSim(msg.(*proto.RaidSimRequest))
+	"/raidSim": {msg: func() googleProto.Message { return &proto.RaidSimRequest{} }, handle: func(ctx context.Context, msg googleProto.Message) googleProto.Message {
+		return core.RunRaidSimWithContext(ctx, msg.(*proto.RaidSimRequest))
 	}},
-	"/statWeights": {msg: func() googleProto.Message { return &proto.StatWeightsRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
-		return core.StatWeights(msg.(*proto.StatWeightsRequest))
+	"/statWeights": {msg: func() googleProto.Message { return &proto.StatWeightsRequest{} }, handle: func(ctx context.Context, msg googleProto.Message) googleProto.Message {
+		return core.StatWeightsWithContext(ctx, msg.(*proto.StatWeightsRequest))
 	}},
-	"/computeStats": {msg: func() googleProto.Message { return &proto.ComputeStatsRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+	"/computeStats": {msg: func() googleProto.Message { return &proto.ComputeStatsRequest{} }, handle: func(ctx context.Context, msg googleProto.Message) googleProto.Message {
 		return core.ComputeStats(msg.(*proto.ComputeStatsRequest))
 	}},
+	"/estimateMemory": {msg: func() googleProto.Message { return &proto.EstimateMemoryRequest{} }, handle: func(ctx context.Context, msg googleProto.Message) googleProto.Message {
+		return core.EstimateMemory(msg.(*proto.EstimateMemoryRequest))
+	}},
+	"/attackTable": {msg: func() googleProto.Message { return &proto.AttackTableRequest{} }, handle: func(ctx context.Context, msg googleProto.Message) googleProto.Message {
+		return core.DumpAttackTable(msg.(*proto.AttackTableRequest))
+	}},
+	"/statSheet": {msg: func() googleProto.Message { return &proto.StatSheetRequest{} }, handle: func(ctx context.Context, msg googleProto.Message) googleProto.Message {
+		return core.ComputeStatSheet(msg.(*proto.StatSheetRequest))
+	}},
+	"/diffResults": {msg: func() googleProto.Message { return &proto.DiffResultsRequest{} }, handle: func(ctx context.Context, msg googleProto.Message) googleProto.Message {
+		return core.DiffResults(msg.(*proto.DiffResultsRequest))
+	}},
+	"/fillGems": {msg: func() googleProto.Message { return &proto.FillGemsRequest{} }, handle: func(ctx context.Context, msg googleProto.Message) googleProto.Message {
+		return core.FillGems(msg.(*proto.FillGemsRequest))
+	}},
+	"/importCharacter": {msg: func() googleProto.Message { return &proto.ImportCharacterRequest{} }, handle: func(ctx context.Context, msg googleProto.Message) googleProto.Message {
+		return core.ImportCharacter(msg.(*proto.ImportCharacterRequest))
+	}},
+	"/presetPacks": {msg: func() googleProto.Message { return &proto.PresetPacksRequest{} }, handle: func(ctx context.Context, msg googleProto.Message) googleProto.Message {
+		return core.GetPresetPacks(msg.(*proto.PresetPacksRequest))
+	}},
+	"/exportCooldowns": {msg: func() googleProto.Message { return &proto.CooldownExportRequest{} }, handle: func(ctx context.Context, msg googleProto.Message) googleProto.Message {
+		return core.ExportCooldowns(msg.(*proto.CooldownExportRequest))
+	}},
+	"/importSimc": {msg: func() googleProto.Message { return &proto.SimcImportRequest{} }, handle: func(ctx context.Context, msg googleProto.Message) googleProto.Message {
+		return core.ImportSimc(msg.(*proto.SimcImportRequest))
+	}},
+	"/exportSimc": {msg: func() googleProto.Message { return &proto.SimcExportRequest{} }, handle: func(ctx context.Context, msg googleProto.Message) googleProto.Message {
+		return core.ExportSimc(msg.(*proto.SimcExportRequest))
+	}},
 }
 
 var asyncAPIHandlers = map[string]asyncAPIHandler{
-	"/raidSimAsync": {msg: func() googleProto.Message { return &proto.RaidSimRequest{} }, handle: func(msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
-		core.RunRaidSimAsync(msg.(*proto.RaidSimRequest), reporter)
+	"/raidSimAsync": {msg: func() googleProto.Message { return &proto.RaidSimRequest{} }, handle: func(ctx context.Context, msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
+		core.RunRaidSimAsync(ctx, msg.(*proto.RaidSimRequest), reporter)
+	}},
+	"/statWeightsAsync": {msg: func() googleProto.Message { return &proto.StatWeightsRequest{} }, handle: func(ctx context.Context, msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
+		core.StatWeightsAsync(ctx, msg.(*proto.StatWeightsRequest), reporter)
+	}},
+	"/bulkSimAsync": {msg: func() googleProto.Message { return &proto.BulkSimRequest{} }, handle: func(ctx context.Context, msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
+		core.RunBulkSimAsync(ctx, msg.(*proto.BulkSimRequest), reporter)
 	}},
-	"/statWeightsAsync": {msg: func() googleProto.Message { return &proto.StatWeightsRequest{} }, handle: func(msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
-		core.StatWeightsAsync(msg.(*proto.StatWeightsRequest), reporter)
+	"/whatIfAsync": {msg: func() googleProto.Message { return &proto.WhatIfRequest{} }, handle: func(ctx context.Context, msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
+		core.RunWhatIfAsync(ctx, msg.(*proto.WhatIfRequest), reporter)
 	}},
-	"/bulkSimAsync": {msg: func() googleProto.Message { return &proto.BulkSimRequest{} }, handle: func(msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
-		// TODO: we can use context's to cancel stuff.
-		// We should have all the async APIs take in context and let it be cancelled via its async ID.
-		core.RunBulkSimAsync(context.Background(), msg.(*proto.BulkSimRequest), reporter)
+	"/specComparisonAsync": {msg: func() googleProto.Message { return &proto.SpecComparisonRequest{} }, handle: func(ctx context.Context, msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
+		core.RunSpecComparisonAsync(ctx, msg.(*proto.SpecComparisonRequest), reporter)
+	}},
+	"/buffContributionAsync": {msg: func() googleProto.Message { return &proto.BuffContributionRequest{} }, handle: func(ctx context.Context, msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
+		core.RunBuffContributionAsync(ctx, msg.(*proto.BuffContributionRequest), reporter)
 	}},
 }
 
 type server struct {
 	progMut         sync.RWMutex
 	asyncProgresses map[string]*asyncProgress
+
+	// Sims are cancelled after this long, if set.
+	simTimeout time.Duration
 }
 
 type apiHandler struct {
 	msg    func() googleProto.Message
-	handle func(googleProto.Message) googleProto.Message
+	handle func(context.Context, googleProto.Message) googleProto.Message
 }
 type asyncAPIHandler struct {
 	msg    func() googleProto.Message
-	handle func(googleProto.Message, chan *proto.ProgressMetrics)
+	handle func(context.Context, googleProto.Message, chan *proto.ProgressMetrics)
 }
 
 type asyncProgress struct {
 	id             string
 	latestProgress atomic.Value
+	cancel         context.CancelFunc
 }
 
-func (s *server) addNewSim() *asyncProgress {
+func (s *server) addNewSim(cancel context.CancelFunc) *asyncProgress {
 	newID := uuid.NewString()
 	simProgress := &asyncProgress{
-		id: newID,
+		id:     newID,
+		cancel: cancel,
 	}
 	simProgress.latestProgress.Store(&proto.ProgressMetrics{})
 
@@ -173,14 +229,16 @@ func (s *server) handleAsyncAPI(w http.ResponseWriter, r *http.Request) {
 	//  as the simulation advances it will push changes to the channel
 	//  these changes will be consumed by the goroutine below so the asyncProgress endpoint can fetch the results.
 	reporter := make(chan *proto.ProgressMetrics, 100)
-	handler.handle(msg, reporter)
+	ctx, cancel := s.simContext(context.Background())
+	handler.handle(ctx, msg, reporter)
 
 	// Generate a new async simulation
-	simProgress := s.addNewSim()
+	simProgress := s.addNewSim(cancel)
 
 	// Now launch a background process that pulls progress reports off the reporter channel
 	// and pushes it into the async progress cache.
 	go func() {
+		defer cancel()
 		for {
 			select {
 			case <-time.After(time.Minute * 10):
@@ -194,7 +252,7 @@ func (s *server) handleAsyncAPI(w http.ResponseWriter, r *http.Request) {
 					return
 				}
 				simProgress.latestProgress.Store(progMetric)
-				if progMetric.FinalRaidResult != nil || progMetric.FinalWeightResult != nil || progMetric.FinalBulkResult != nil {
+				if progMetric.FinalRaidResult != nil || progMetric.FinalWeightResult != nil || progMetric.FinalBulkResult != nil || progMetric.FinalWhatIfResult != nil || progMetric.FinalSpecComparisonResult != nil || progMetric.FinalBuffContributionResult != nil {
 					return
 				}
 			}
@@ -254,7 +312,8 @@ func (s *server) setupAsyncServer() {
 		}
 
 		// If this was the last result, delete the cache for this simulation.
-		if latest.FinalRaidResult != nil || latest.FinalWeightResult != nil || latest.FinalBulkResult != nil {
+		// Cancelled sims still send a final result, with partial metrics.
+		if latest.FinalRaidResult != nil || latest.FinalWeightResult != nil || latest.FinalBulkResult != nil || latest.FinalWhatIfResult != nil || latest.FinalSpecComparisonResult != nil || latest.FinalBuffContributionResult != nil {
 			s.progMut.Lock()
 			delete(s.asyncProgresses, msg.ProgressId)
 			s.progMut.Unlock()
@@ -262,6 +321,79 @@ func (s *server) setupAsyncServer() {
 		w.Header().Add("Content-Type", "application/x-protobuf")
 		w.Write(outbytes)
 	})
+
+	// asyncCancel stops a simulation by its UUID. Its final, partial result can
+	// still be fetched with asyncProgress.
+	http.HandleFunc("/asyncCancel", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return
+		}
+		msg := &proto.AsyncAPIResult{}
+		if err := googleProto.Unmarshal(body, msg); err != nil {
+			log.Printf("Failed to parse request: %s", err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		s.progMut.RLock()
+		progress, ok := s.asyncProgresses[msg.ProgressId]
+		s.progMut.RUnlock()
+		if !ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		progress.cancel()
+	})
+
+	// raidSimStream runs a raid sim and streams every progress report back as
+	// size-delimited ProgressMetrics messages in a chunked response, ending with
+	// the one holding the final result. Closing the connection cancels the sim.
+	http.HandleFunc("/raidSimStream", s.handleRaidSimStream)
+}
+
+// Returns a context for a sim, which is cancelled after the server's sim
+// timeout if there is one.
+func (s *server) simContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if s.simTimeout > 0 {
+		return context.WithTimeout(parent, s.simTimeout)
+	}
+	return context.WithCancel(parent)
+}
+
+func (s *server) handleRaidSimStream(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return
+	}
+	msg := &proto.RaidSimRequest{}
+	if err := googleProto.Unmarshal(body, msg); err != nil {
+		log.Printf("Failed to parse request: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := s.simContext(r.Context())
+	defer cancel()
+
+	reporter := make(chan *proto.ProgressMetrics, 100)
+	core.RunRaidSimAsync(ctx, msg, reporter)
+
+	w.Header().Add("Content-Type", "application/x-protobuf")
+	flusher, _ := w.(http.Flusher)
+	for progMetric := range reporter {
+		if _, err := protodelim.MarshalTo(w, progMetric); err != nil {
+			// The client went away, which also cancels the sim through the
+			// request context. Keep draining until it finishes.
+			continue
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if progMetric.FinalRaidResult != nil {
+			return
+		}
+	}
 }
 
 func (s *server) runServer(useFS bool, host string, launchBrowser bool, simName string, wasm bool, inputReader *bufio.Reader) {
@@ -277,7 +409,7 @@ func (s *server) runServer(useFS bool, host string, launchBrowser bool, simName
 	}
 
 	for route := range handlers {
-		http.HandleFunc(route, handleAPI)
+		http.HandleFunc(route, s.handleAPI)
 	}
 
 	http.HandleFunc("/version", func(resp http.ResponseWriter, req *http.Request) {
@@ -388,8 +520,9 @@ func (s *server) runServer(useFS bool, host string, launchBrowser bool, simName
 	}
 }
 
-// handleAPI is generic handler for any api function using protos.
-func handleAPI(w http.ResponseWriter, r *http.Request) {
+// handleAPI is generic handler for any api function using protos. Sims are
+// cancelled when the client disconnects.
+func (s *server) handleAPI(w http.ResponseWriter, r *http.Request) {
 	endpoint := r.URL.Path
 
 	body, err := io.ReadAll(r.Body)
@@ -410,7 +543,9 @@ func handleAPI(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	result := handler.handle(msg)
+	ctx, cancel := s.simContext(r.Context())
+	defer cancel()
+	result := handler.handle(ctx, msg)
 
 	outbytes, err := googleProto.Marshal(result)
 	if err != nil {
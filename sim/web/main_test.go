@@ -13,6 +13,7 @@ import (
 	_ "github.com/wowsims/wotlk/sim/common"
 	"github.com/wowsims/wotlk/sim/core"
 	"github.com/wowsims/wotlk/sim/core/proto"
+	"google.golang.org/protobuf/encoding/protodelim"
 	googleProto "google.golang.org/protobuf/proto"
 )
 
@@ -113,3 +114,58 @@ func TestIndividualSim(t *testing.T) {
 
 	log.Printf("RESULT: %#v", rsr)
 }
+
+func TestRaidSimStream(t *testing.T) {
+	req := &proto.RaidSimRequest{
+		Raid: core.SinglePlayerRaidProto(
+			&proto.Player{
+				Race:      proto.Race_RaceTroll,
+				Class:     proto.Class_ClassShaman,
+				Equipment: p1Equip,
+				Spec:      basicSpec,
+			},
+			&proto.PartyBuffs{},
+			&proto.RaidBuffs{},
+			&proto.Debuffs{}),
+		Encounter: &proto.Encounter{
+			Duration: 120,
+			Targets: []*proto.Target{
+				{},
+			},
+		},
+		SimOptions: &proto.SimOptions{
+			Iterations:       5000,
+			RandomSeed:       1,
+			ProgressInterval: 1000,
+		},
+	}
+
+	msgBytes, err := googleProto.Marshal(req)
+	if err != nil {
+		t.Fatalf("Failed to encode request: %s", err.Error())
+	}
+
+	r, err := http.Post("http://localhost:3339/raidSimStream", "application/x-protobuf", bytes.NewReader(msgBytes))
+	if err != nil {
+		t.Fatalf("Failed to POST request: %s", err.Error())
+	}
+	defer r.Body.Close()
+
+	reader := bufio.NewReader(r.Body)
+	partials := 0
+	for {
+		progress := &proto.ProgressMetrics{}
+		if err := protodelim.UnmarshalFrom(reader, progress); err != nil {
+			t.Fatalf("Failed to read progress: %s", err.Error())
+		}
+		if progress.FinalRaidResult != nil {
+			if partials != 4 {
+				t.Fatalf("Expected 4 partial results before the final one, got %d", partials)
+			}
+			return
+		}
+		if progress.RaidMetrics != nil {
+			partials++
+		}
+	}
+}
@@ -169,7 +169,13 @@ func (db *WowDatabase) AddSpellIcon(id int32, tooltips map[int32]WowheadItemResp
 		if tooltip.GetName() == "" || tooltip.GetIcon() == "" {
 			return
 		}
-		db.SpellIcons[id] = &proto.IconData{Id: id, Name: tooltip.GetName(), Icon: tooltip.GetIcon()}
+		db.SpellIcons[id] = &proto.IconData{
+			Id:      id,
+			Name:    tooltip.GetName(),
+			Icon:    tooltip.GetIcon(),
+			Rank:    tooltip.GetSpellRank(),
+			Schools: tooltip.GetSpellSchools(),
+		}
 	} else {
 		panic(fmt.Sprintf("No spell tooltip with id %d", id))
 	}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core"
+)
+
+// DBC tables needed for spell data, exported as CSV in the wow.tools format.
+var DBCSpellTables = []string{"Spell", "SpellEffect", "SpellMisc", "SpellDuration"}
+
+var dbcSpellRankRegex = regexp.MustCompile(`^Rank ([0-9]+)$`)
+
+const (
+	spellEffectSchoolDamage = 2
+	spellEffectHeal         = 10
+	spellEffectApplyAura    = 6
+)
+
+type dbcTable struct {
+	headers map[string]int
+	rows    [][]string
+}
+
+func parseDBCTable(name string, contents string) dbcTable {
+	r := csv.NewReader(strings.NewReader(contents))
+	rawHeaders, err := r.Read()
+	if err != nil {
+		log.Fatalf("Cannot read %s csv header row: %v", name, err)
+	}
+
+	table := dbcTable{headers: map[string]int{}}
+	for i, header := range rawHeaders {
+		table.headers[header] = i
+	}
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("Cannot read %s csv row: %v", name, err)
+		}
+		table.rows = append(table.rows, row)
+	}
+	return table
+}
+
+// Returns 0 for columns missing from this build's export.
+func (table dbcTable) float(row []string, header string) float64 {
+	i, ok := table.headers[header]
+	if !ok || row[i] == "" {
+		return 0
+	}
+	val, err := strconv.ParseFloat(row[i], 64)
+	if err != nil {
+		log.Fatalf("Cannot parse %s from row %v: %v", header, row, err)
+	}
+	return val
+}
+
+func (table dbcTable) int(row []string, header string) int32 {
+	return int32(table.float(row, header))
+}
+
+// Returns the column as a string, or "" if it's missing from this build's export.
+func (table dbcTable) string(row []string, header string) string {
+	i, ok := table.headers[header]
+	if !ok {
+		return ""
+	}
+	return row[i]
+}
+
+// Builds spell data from the Spell, SpellEffect, SpellMisc and SpellDuration
+// tables, for the given spells only.
+func ParseDBCSpellData(tables map[string]string, spellIDs []int32) map[int32]core.DBCSpellData {
+	spells := parseDBCTable("Spell", tables["Spell"])
+	spellEffects := parseDBCTable("SpellEffect", tables["SpellEffect"])
+	spellMisc := parseDBCTable("SpellMisc", tables["SpellMisc"])
+	spellDurations := parseDBCTable("SpellDuration", tables["SpellDuration"])
+
+	durations := map[int32]time.Duration{}
+	for _, row := range spellDurations.rows {
+		// Negative durations are infinite.
+		if ms := spellDurations.int(row, "Duration"); ms > 0 {
+			durations[spellDurations.int(row, "ID")] = time.Millisecond * time.Duration(ms)
+		}
+	}
+
+	result := make(map[int32]core.DBCSpellData, len(spellIDs))
+	for _, spellID := range spellIDs {
+		result[spellID] = core.DBCSpellData{}
+	}
+
+	for _, row := range spells.rows {
+		spellID := spells.int(row, "ID")
+		data, ok := result[spellID]
+		if !ok {
+			continue
+		}
+		if match := dbcSpellRankRegex.FindStringSubmatch(spells.string(row, "NameSubtext_lang")); match != nil {
+			rank, _ := strconv.Atoi(match[1])
+			data.Rank = int32(rank)
+			result[spellID] = data
+		}
+	}
+
+	for _, row := range spellMisc.rows {
+		spellID := spellMisc.int(row, "SpellID")
+		data, ok := result[spellID]
+		if !ok || spellMisc.int(row, "DifficultyID") != 0 {
+			continue
+		}
+		data.MissileSpeed = spellMisc.float(row, "Speed")
+		data.Duration = durations[spellMisc.int(row, "DurationIndex")]
+		result[spellID] = data
+	}
+
+	for _, row := range spellEffects.rows {
+		spellID := spellEffects.int(row, "SpellID")
+		data, ok := result[spellID]
+		if !ok || spellEffects.int(row, "DifficultyID") != 0 {
+			continue
+		}
+
+		// Effects roll BasePoints + 1d(DieSides), or just BasePoints without die sides.
+		basePoints := spellEffects.float(row, "EffectBasePoints")
+		dieSides := spellEffects.float(row, "EffectDieSides")
+		effect := core.DBCSpellEffect{
+			Index:            spellEffects.int(row, "EffectIndex"),
+			Effect:           spellEffects.int(row, "Effect"),
+			Aura:             spellEffects.int(row, "EffectAura"),
+			BasePointsMin:    basePoints + min(dieSides, 1),
+			BasePointsMax:    basePoints + dieSides,
+			BonusCoefficient: spellEffects.float(row, "EffectBonusCoefficient"),
+			APCoefficient:    spellEffects.float(row, "BonusCoefficientFromAP"),
+			Period:           time.Millisecond * time.Duration(spellEffects.int(row, "EffectAuraPeriod")),
+		}
+		if effect.Effect != spellEffectSchoolDamage && effect.Effect != spellEffectHeal && effect.Effect != spellEffectApplyAura {
+			continue
+		}
+
+		data.Effects = append(data.Effects, effect)
+		result[spellID] = data
+	}
+
+	for spellID, data := range result {
+		slices.SortFunc(data.Effects, func(a, b core.DBCSpellEffect) int {
+			return int(a.Index - b.Index)
+		})
+		// Don't bother generating spells with nothing to compare against.
+		if data.Rank == 0 && data.MissileSpeed == 0 && data.Duration == 0 && len(data.Effects) == 0 {
+			delete(result, spellID)
+		}
+	}
+	return result
+}
+
+// Returns the Go source for sim/core/dbc_spells_gen.go.
+func GenerateDBCSpellDataFile(spells map[int32]core.DBCSpellData) []byte {
+	spellIDs := make([]int32, 0, len(spells))
+	for spellID := range spells {
+		spellIDs = append(spellIDs, spellID)
+	}
+	slices.Sort(spellIDs)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by tools/database/gen_db -gen=dbc-spells. DO NOT EDIT.\n\n")
+	buf.WriteString("package core\n\n")
+	if len(spellIDs) > 0 {
+		buf.WriteString("import \"time\"\n\n")
+	}
+	buf.WriteString("var dbcSpellData = map[int32]DBCSpellData{\n")
+	for _, spellID := range spellIDs {
+		data := spells[spellID]
+		fmt.Fprintf(&buf, "%d: {Rank: %d, MissileSpeed: %s, Duration: %s, Effects: []DBCSpellEffect{\n", spellID, data.Rank, formatDBCFloat(data.MissileSpeed), formatDBCDuration(data.Duration))
+		for _, effect := range data.Effects {
+			fmt.Fprintf(&buf, "{Index: %d, Effect: %d, Aura: %d, BasePointsMin: %s, BasePointsMax: %s, BonusCoefficient: %s, APCoefficient: %s, Period: %s},\n",
+				effect.Index, effect.Effect, effect.Aura,
+				formatDBCFloat(effect.BasePointsMin), formatDBCFloat(effect.BasePointsMax),
+				formatDBCFloat(effect.BonusCoefficient), formatDBCFloat(effect.APCoefficient),
+				formatDBCDuration(effect.Period))
+		}
+		buf.WriteString("}},\n")
+	}
+	buf.WriteString("}\n")
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("Cannot format generated DBC spell data: %v", err)
+	}
+	return formatted
+}
+
+func formatDBCFloat(val float64) string {
+	return strconv.FormatFloat(val, 'f', -1, 64)
+}
+
+func formatDBCDuration(duration time.Duration) string {
+	return fmt.Sprintf("time.Millisecond * %d", duration.Milliseconds())
+}
@@ -25,12 +25,18 @@ import (
 // go run ./tools/database/gen_db -outDir=assets -gen=wowhead-gearplannerdb
 // go run ./tools/database/gen_db -outDir=assets -gen=wotlk-items
 // go run ./tools/database/gen_db -outDir=assets -gen=wago-db2-items
+// go run ./tools/database/gen_db -outDir=assets -gen=wago-db2-spells
+// go run ./tools/database/gen_db -outDir=assets -gen=dbc-spells
 // go run ./tools/database/gen_db -outDir=assets -gen=db
+// go run ./tools/database/gen_db -outDir=assets -gen=db -patch=3.4.3 -maxPhase=4
 
 var minId = flag.Int("minid", 1, "Minimum ID to scan for")
 var maxId = flag.Int("maxid", 57000, "Maximum ID to scan for")
 var outDir = flag.String("outDir", "assets", "Path to output directory for writing generated .go files.")
-var genAsset = flag.String("gen", "", "Asset to generate. Valid values are 'db', 'atlasloot', 'wowhead-items', 'wowhead-spells', 'wowhead-itemdb', 'wotlk-items', and 'wago-db2-items'")
+var patch = flag.String("patch", "", "Current game patch, e.g. '3.4.3'. Items which are new or changed since the previous db are tagged with it.")
+var maxPhase = flag.Int("maxPhase", 0, "If set, also writes a db_phase<N> database without items, gems and enchants from later phases.")
+var maxDiscrepancies = flag.Int("maxDiscrepancies", 28, "Fails db generation if presets reference more missing or mismatched items, enchants and gems than this.")
+var genAsset = flag.String("gen", "", "Asset to generate. Valid values are 'db', 'atlasloot', 'wowhead-items', 'wowhead-spells', 'wowhead-itemdb', 'wotlk-items', 'wago-db2-items', 'wago-db2-spells' and 'dbc-spells'")
 
 func main() {
 	flag.Parse()
@@ -60,6 +66,23 @@ func main() {
 	} else if *genAsset == "wago-db2-items" {
 		tools.WriteFile(fmt.Sprintf("%s/wago_db2_items.csv", inputsDir), tools.ReadWebRequired("https://wago.tools/db2/ItemSparse/csv?build=3.4.2.49311"))
 		return
+	} else if *genAsset == "wago-db2-spells" {
+		for _, table := range database.DBCSpellTables {
+			tools.WriteFile(fmt.Sprintf("%s/dbc/%s.csv", inputsDir, table), tools.ReadWebRequired(fmt.Sprintf("https://wago.tools/db2/%s/csv?build=3.4.2.49311", table)))
+		}
+		return
+	} else if *genAsset == "dbc-spells" {
+		tables := make(map[string]string, len(database.DBCSpellTables))
+		for _, table := range database.DBCSpellTables {
+			tables[table] = tools.ReadFile(fmt.Sprintf("%s/dbc/%s.csv", inputsDir, table))
+		}
+		var spellIDs []int32
+		for _, rotationSpellIDs := range GetAllRotationSpellIds() {
+			spellIDs = append(spellIDs, rotationSpellIDs...)
+		}
+		spells := database.ParseDBCSpellData(tables, spellIDs)
+		tools.WriteFile("sim/core/dbc_spells_gen.go", string(database.GenerateDBCSpellDataFile(spells)))
+		return
 	} else if *genAsset != "db" {
 		panic("Invalid gen value")
 	}
@@ -109,6 +132,11 @@ func main() {
 	leftovers.WriteBinaryAndJson(fmt.Sprintf("%s/leftover_db.bin", dbDir), fmt.Sprintf("%s/leftover_db.json", dbDir))
 
 	ApplySimmableFilters(db)
+	if prevDBJson, err := os.ReadFile(fmt.Sprintf("%s/db.json", dbDir)); err == nil {
+		db.ApplyPatchTags(database.ReadDatabaseFromJson(string(prevDBJson)), *patch)
+	} else {
+		db.ApplyPatchTags(database.NewWowDatabase(), *patch)
+	}
 	for _, enchant := range db.Enchants {
 		if enchant.ItemId != 0 {
 			db.AddItemIcon(enchant.ItemId, itemTooltips)
@@ -151,6 +179,20 @@ func main() {
 	db.MergeNpcs(atlasDBProto.Npcs)
 
 	db.WriteBinaryAndJson(fmt.Sprintf("%s/db.bin", dbDir), fmt.Sprintf("%s/db.json", dbDir))
+
+	report := database.ValidatePresets(db, fmt.Sprintf("%s/../..", inputsDir), itemTooltips)
+	database.WriteValidationReport(report, fmt.Sprintf("%s/validation_report.json", dbDir))
+	if len(report.Discrepancies) > *maxDiscrepancies {
+		log.Fatalf("Found %d database discrepancies in presets, more than the maximum of %d. See %s/validation_report.json.", len(report.Discrepancies), *maxDiscrepancies, dbDir)
+	}
+
+	if *maxPhase > 0 {
+		phaseDB := db.Clone()
+		phaseDB.Encounters = db.Encounters
+		phaseDB.GlyphIDs = db.GlyphIDs
+		database.ApplyPhaseFilter(phaseDB, int32(*maxPhase))
+		phaseDB.WriteBinaryAndJson(fmt.Sprintf("%s/db_phase%d.bin", dbDir, *maxPhase), fmt.Sprintf("%s/db_phase%d.json", dbDir, *maxPhase))
+	}
 }
 
 // Filters out entities which shouldn't be included anywhere.
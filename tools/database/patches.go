@@ -0,0 +1,67 @@
+package database
+
+import (
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Patch which released each content phase, for items which were added before
+// patch tagging existed.
+var PhasePatches = map[int32]string{
+	1: "3.4.0",
+	2: "3.4.1",
+	3: "3.4.2",
+	4: "3.4.3",
+	5: "3.4.3",
+}
+
+// Tags items with the patch they were added or last changed in, by comparing
+// against the previously generated database. Tags from the previous database
+// are kept, so they accumulate as the database is regenerated each patch.
+// If patch is empty, only items without a tag are tagged, by their phase.
+func (db *WowDatabase) ApplyPatchTags(previous *WowDatabase, patch string) {
+	for id, item := range db.Items {
+		prevItem, ok := previous.Items[id]
+		if !ok {
+			item.AddedPatch = patch
+			if item.AddedPatch == "" {
+				item.AddedPatch = PhasePatches[item.Phase]
+			}
+			continue
+		}
+
+		item.AddedPatch = prevItem.AddedPatch
+		if item.AddedPatch == "" {
+			item.AddedPatch = PhasePatches[item.Phase]
+		}
+		item.ChangedPatch = prevItem.ChangedPatch
+		if patch != "" && itemChanged(prevItem, item) {
+			item.ChangedPatch = patch
+		}
+	}
+}
+
+// Compares everything but the patch tags, so re-tagging doesn't count as a
+// change.
+func itemChanged(prevItem *proto.UIItem, item *proto.UIItem) bool {
+	prevItem = googleProto.Clone(prevItem).(*proto.UIItem)
+	prevItem.AddedPatch, prevItem.ChangedPatch = "", ""
+	item = googleProto.Clone(item).(*proto.UIItem)
+	item.AddedPatch, item.ChangedPatch = "", ""
+	return !googleProto.Equal(prevItem, item)
+}
+
+// Removes items, gems and enchants from phases after maxPhase, so the
+// planner only offers what's currently obtainable.
+func ApplyPhaseFilter(db *WowDatabase, maxPhase int32) {
+	db.Items = core.FilterMap(db.Items, func(_ int32, item *proto.UIItem) bool {
+		return item.Phase <= maxPhase
+	})
+	db.Gems = core.FilterMap(db.Gems, func(_ int32, gem *proto.UIGem) bool {
+		return gem.Phase <= maxPhase
+	})
+	db.Enchants = core.FilterMap(db.Enchants, func(_ EnchantDBKey, enchant *proto.UIEnchant) bool {
+		return enchant.Phase <= maxPhase
+	})
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+	"github.com/wowsims/wotlk/tools"
+)
+
+// Cross-checks every item, enchant and gem in the UI's gear presets under
+// rootDir against the generated database. Gems are also checked against their
+// tooltip stats, to catch wrong overrides.
+func ValidatePresets(db *WowDatabase, rootDir string, itemTooltips map[int32]WowheadItemResponse) *proto.DatabaseValidationReport {
+	presets, err := filepath.Glob(filepath.Join(rootDir, "ui/*/gear_sets/*.gear.json"))
+	if err != nil {
+		panic(err)
+	}
+	slices.Sort(presets)
+
+	// The tool isn't built with the sim's database, so the generated one is
+	// passed in as provided entries.
+	simDB := core.SimDatabaseFromUI(db.ToUIProto())
+
+	report := &proto.DatabaseValidationReport{}
+	checkedGems := make(map[int32]bool)
+	for _, preset := range presets {
+		equipment := core.EquipmentSpecFromJsonString(tools.ReadFile(preset))
+		if relPath, err := filepath.Rel(rootDir, preset); err == nil {
+			preset = relPath
+		}
+		report.Discrepancies = append(report.Discrepancies, core.ValidateDatabaseReferences(preset, equipment, simDB)...)
+
+		for _, item := range equipment.Items {
+			for _, gemID := range item.Gems {
+				gem, ok := db.Gems[gemID]
+				if !ok || checkedGems[gemID] {
+					continue
+				}
+				checkedGems[gemID] = true
+
+				tooltip, ok := itemTooltips[gemID]
+				if !ok {
+					continue
+				}
+				tooltipStats := stats.FromFloatArray(tooltip.ToGemProto().Stats)
+				if dbStats := stats.FromFloatArray(gem.Stats); tooltipStats != dbStats {
+					report.Discrepancies = append(report.Discrepancies, &proto.DatabaseDiscrepancy{
+						Kind:    proto.DatabaseDiscrepancy_KindStatMismatch,
+						Entity:  proto.DatabaseDiscrepancy_EntityGem,
+						Id:      gemID,
+						Source:  preset,
+						Message: fmt.Sprintf("Stats of gem %d are %v, but %v in its tooltip.", gemID, dbStats, tooltipStats),
+					})
+				}
+			}
+		}
+	}
+	return report
+}
+
+// Writes the report as JSON, one discrepancy per line so it diffs well.
+func WriteValidationReport(report *proto.DatabaseValidationReport, filePath string) {
+	buffer := new(bytes.Buffer)
+	buffer.WriteString("{\n")
+	tools.WriteProtoArrayToBuffer(report.Discrepancies, buffer, "discrepancies")
+	buffer.WriteString("\n}")
+	tools.WriteFile(filePath, buffer.String())
+}
@@ -761,3 +761,30 @@ func (item WowheadItemResponse) GetRequiredProfession() proto.Profession {
 
 	return proto.Profession_ProfessionUnknown
 }
+
+var spellRankRegex = regexp.MustCompile(`>Rank ([0-9]+)<`)
+
+// Returns the rank of a spell, or 0 if it has none.
+func (item WowheadItemResponse) GetSpellRank() int32 {
+	return int32(GetRegexIntValue(item.Tooltip, spellRankRegex, 1))
+}
+
+var spellSchoolRegex = regexp.MustCompile(`(Physical|Arcane|Frostfire|Fire|Frost|Holy|Nature|Shadowflame|Shadow) damage`)
+
+var spellSchoolsByName = map[string][]proto.SpellSchool{
+	"Physical":    {proto.SpellSchool_SpellSchoolPhysical},
+	"Arcane":      {proto.SpellSchool_SpellSchoolArcane},
+	"Fire":        {proto.SpellSchool_SpellSchoolFire},
+	"Frost":       {proto.SpellSchool_SpellSchoolFrost},
+	"Frostfire":   {proto.SpellSchool_SpellSchoolFrost, proto.SpellSchool_SpellSchoolFire},
+	"Holy":        {proto.SpellSchool_SpellSchoolHoly},
+	"Nature":      {proto.SpellSchool_SpellSchoolNature},
+	"Shadow":      {proto.SpellSchool_SpellSchoolShadow},
+	"Shadowflame": {proto.SpellSchool_SpellSchoolShadow, proto.SpellSchool_SpellSchoolFire},
+}
+
+// Returns the schools of the first damage a spell's tooltip mentions, or nil
+// if it doesn't mention any.
+func (item WowheadItemResponse) GetSpellSchools() []proto.SpellSchool {
+	return spellSchoolsByName[GetRegexStringValue(item.Tooltip, spellSchoolRegex, 1)]
+}
@@ -164,6 +164,12 @@ export class CharacterStats extends Component {
 					<span>Total:</span>
 					<span>{this.statDisplayString(finalStats, finalStats, stat)}</span>
 				</div>
+				{(stat == Stat.StatMeleeHaste || stat == Stat.StatSpellHaste) &&
+				<div className="character-stats-tooltip-row">
+					<span>Effective:</span>
+					<span>{this.effectiveHasteDisplayString(finalStats, stat)}</span>
+				</div>
+				}
 			</div>;
 			Tooltip.getOrCreateInstance(valueElem, {
 				title: tooltipContent,
@@ -291,6 +297,15 @@ export class CharacterStats extends Component {
 		return displayStr;
 	}
 
+	// Haste including multiplicative effects, which rating alone doesn't show.
+	private effectiveHasteDisplayString(stats: Stats, stat: Stat): string {
+		const formatHaste = (pseudoStat: PseudoStat) => `${(stats.getPseudoStat(pseudoStat) * 100).toFixed(2)}%`;
+		if (stat == Stat.StatSpellHaste) {
+			return formatHaste(PseudoStat.PseudoStatSpellHaste);
+		}
+		return `${formatHaste(PseudoStat.PseudoStatMeleeHaste)} melee, ${formatHaste(PseudoStat.PseudoStatRangedHaste)} ranged`;
+	}
+
 	private getDebuffStats(): Stats {
 		let debuffStats = new Stats();
 
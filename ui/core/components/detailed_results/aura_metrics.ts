@@ -32,6 +32,30 @@ export class AuraMetricsTable extends MetricsTable<AuraMetrics> {
 				getValue: (metric: AuraMetrics) => metric.ppm,
 				getDisplayString: (metric: AuraMetrics) => metric.ppm.toFixed(2),
 			},
+			{
+				name: 'Applications',
+				tooltip: 'Procs, Excluding Refreshes',
+				getValue: (metric: AuraMetrics) => metric.averageApplications,
+				getDisplayString: (metric: AuraMetrics) => metric.averageApplications.toFixed(2),
+			},
+			{
+				name: 'Stacks',
+				tooltip: 'Average Stacks While Active',
+				getValue: (metric: AuraMetrics) => metric.averageStacks,
+				getDisplayString: (metric: AuraMetrics) => metric.averageStacks ? metric.averageStacks.toFixed(2) : '-',
+			},
+			{
+				name: 'Refresh Waste',
+				tooltip: 'Remaining Duration Lost to Early Refreshes',
+				getValue: (metric: AuraMetrics) => metric.refreshWastedSeconds,
+				getDisplayString: (metric: AuraMetrics) => metric.refreshWastedSeconds.toFixed(2) + 's',
+			},
+			{
+				name: 'Suppressed',
+				tooltip: 'Uptime While a Stronger Effect of the Same Kind Applied Instead',
+				getValue: (metric: AuraMetrics) => metric.suppressedPercent,
+				getDisplayString: (metric: AuraMetrics) => metric.suppressedPercent ? metric.suppressedPercent.toFixed(2) + '%' : '-',
+			},
 			{
 				name: 'Uptime',
 				tooltip: 'Uptime / Encounter Duration',
@@ -1,10 +1,17 @@
 import {
+	AddWave,
+	DamageWindow,
+	DowntimeWindow,
+	DurationDistribution,
 	InputType,
+	LossOfControlEvent,
+	LossOfControlType,
 	MobType,
 	SpellSchool,
 	Stat,
 	Target as TargetProto,
 	TargetInput,
+	TargetSpell,
 	Target,
 } from '../proto/common.js';
 import { Encounter } from '../encounter.js';
@@ -13,6 +20,7 @@ import { EventID, TypedEvent } from '../typed_event.js';
 import { BooleanPicker } from '../components/boolean_picker.js';
 import { EnumPicker } from '../components/enum_picker.js';
 import { ListItemPickerConfig, ListPicker } from '../components/list_picker.js';
+import { NumberListPicker } from '../components/number_list_picker.js';
 import { NumberPicker } from '../components/number_picker.js';
 import { Stats } from '../proto_utils/stats.js';
 import { isHealingSpec, isTankSpec } from '../proto_utils/utils.js';
@@ -159,10 +167,16 @@ class AdvancedEncounterModal extends BaseModal {
 		this.body.innerHTML = `
 			<div class="encounter-header"></div>
 			<div class="encounter-targets"></div>
+			<div class="encounter-add-waves"></div>
+			<div class="encounter-loss-of-control"></div>
+			<div class="encounter-downtime"></div>
 		`;
 
 		const header = this.rootElem.getElementsByClassName('encounter-header')[0] as HTMLElement;
 		const targetsElem = this.rootElem.getElementsByClassName('encounter-targets')[0] as HTMLElement;
+		const addWavesElem = this.rootElem.getElementsByClassName('encounter-add-waves')[0] as HTMLElement;
+		const lossOfControlElem = this.rootElem.getElementsByClassName('encounter-loss-of-control')[0] as HTMLElement;
+		const downtimeElem = this.rootElem.getElementsByClassName('encounter-downtime')[0] as HTMLElement;
 
 		addEncounterFieldPickers(header, this.encounter, true);
 		if (!simUI.isIndividualSim()) {
@@ -175,6 +189,16 @@ class AdvancedEncounterModal extends BaseModal {
 					encounter.setUseHealth(eventID, newValue);
 				},
 			});
+			new BooleanPicker<Encounter>(header, encounter, {
+				label: 'Separate Health Pools',
+				labelTooltip: 'Gives each target its own health instead of sharing one pool, e.g. for council fights. Targets leave the fight once they die, and the fight ends once all of them are dead.',
+				changedEvent: (encounter: Encounter) => encounter.changeEmitter,
+				getValue: (encounter: Encounter) => encounter.getSeparateHealthPools(),
+				setValue: (eventID: EventID, encounter: Encounter, newValue: boolean) => {
+					encounter.setSeparateHealthPools(eventID, newValue);
+				},
+				enableWhen: (obj) => { return encounter.getUseHealth() },
+			});
 		}
 		new ListPicker<Encounter, TargetProto>(targetsElem, this.encounter, {
 			extraCssClasses: ['targets-picker', 'mb-0'],
@@ -189,6 +213,51 @@ class AdvancedEncounterModal extends BaseModal {
 			copyItem: (oldItem: TargetProto) => TargetProto.clone(oldItem),
 			newItemPicker: (parent: HTMLElement, listPicker: ListPicker<Encounter, TargetProto>, index: number, config: ListItemPickerConfig<Encounter, TargetProto>) => new TargetPicker(parent, encounter, index, config),
 		});
+		new ListPicker<Encounter, AddWave>(addWavesElem, this.encounter, {
+			extraCssClasses: ['add-waves-picker', 'mb-0'],
+			title: 'Add Waves',
+			titleTooltip: 'Groups of adds which spawn during the fight. Adds count towards the number of targets while they are alive, but not towards the encounter\'s health.',
+			itemLabel: 'Add Wave',
+			changedEvent: (encounter: Encounter) => encounter.targetsChangeEmitter,
+			getValue: (encounter: Encounter) => encounter.addWaves,
+			setValue: (eventID: EventID, encounter: Encounter, newValue: Array<AddWave>) => {
+				encounter.addWaves = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+			newItem: () => AddWave.create({ count: 1 }),
+			copyItem: (oldItem: AddWave) => AddWave.clone(oldItem),
+			newItemPicker: (parent: HTMLElement, listPicker: ListPicker<Encounter, AddWave>, index: number, config: ListItemPickerConfig<Encounter, AddWave>) => new AddWavePicker(parent, encounter, index, config),
+		});
+		new ListPicker<Encounter, LossOfControlEvent>(lossOfControlElem, this.encounter, {
+			extraCssClasses: ['loss-of-control-picker', 'mb-0'],
+			title: 'Stuns and Silences',
+			titleTooltip: 'Scripted stuns and silences on every player, which interrupt their casts. Stuns also stop auto attacks and push back the GCD.',
+			itemLabel: 'Stun or Silence',
+			changedEvent: (encounter: Encounter) => encounter.targetsChangeEmitter,
+			getValue: (encounter: Encounter) => encounter.lossOfControlEvents,
+			setValue: (eventID: EventID, encounter: Encounter, newValue: Array<LossOfControlEvent>) => {
+				encounter.lossOfControlEvents = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+			newItem: () => LossOfControlEvent.create({ duration: 2 }),
+			copyItem: (oldItem: LossOfControlEvent) => LossOfControlEvent.clone(oldItem),
+			newItemPicker: (parent: HTMLElement, listPicker: ListPicker<Encounter, LossOfControlEvent>, index: number, config: ListItemPickerConfig<Encounter, LossOfControlEvent>) => new LossOfControlEventPicker(parent, encounter, index, config),
+		});
+		new ListPicker<Encounter, DowntimeWindow>(downtimeElem, this.encounter, {
+			extraCssClasses: ['downtime-picker', 'mb-0'],
+			title: 'Downtime',
+			titleTooltip: 'Windows in which the targets can\'t be attacked, e.g. phase transitions. Players stop casting and attacking, and regen mana out of combat. Results also show DPS over the time outside of these windows.',
+			itemLabel: 'Downtime Window',
+			changedEvent: (encounter: Encounter) => encounter.targetsChangeEmitter,
+			getValue: (encounter: Encounter) => encounter.downtimeWindows,
+			setValue: (eventID: EventID, encounter: Encounter, newValue: Array<DowntimeWindow>) => {
+				encounter.downtimeWindows = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+			newItem: () => DowntimeWindow.create({ duration: 10 }),
+			copyItem: (oldItem: DowntimeWindow) => DowntimeWindow.clone(oldItem),
+			newItemPicker: (parent: HTMLElement, listPicker: ListPicker<Encounter, DowntimeWindow>, index: number, config: ListItemPickerConfig<Encounter, DowntimeWindow>) => new DowntimeWindowPicker(parent, encounter, index, config),
+		});
 	}
 
 	private addHeader() {
@@ -232,8 +301,12 @@ class TargetPicker extends Input<Encounter, TargetProto> {
 	private readonly parryHastePicker: Input<null, boolean>;
 	private readonly spellSchoolPicker: Input<null, number>;
 	private readonly suppressDodgePicker: Input<null, boolean>;
+	private readonly pvpPicker: Input<null, boolean>;
 	private readonly damageSpreadPicker: Input<null, number>;
+	private readonly requiredDpsPicker: Input<null, number>;
 	private readonly targetInputPickers: ListPicker<Encounter, TargetInput>;
+	private readonly damageWindowPickers: ListPicker<Encounter, DamageWindow>;
+	private readonly spellPickers: ListPicker<Encounter, TargetSpell>;
 
 	private getTarget(): TargetProto {
 		return this.encounter.targets[this.targetIndex] || Target.create();
@@ -312,6 +385,8 @@ class TargetPicker extends Input<Encounter, TargetProto> {
 				{ name: '82', value: 82 },
 				{ name: '81', value: 81 },
 				{ name: '80', value: 80 },
+				{ name: '79', value: 79 },
+				{ name: '78', value: 78 },
 			],
 			changedEvent: () => encounter.targetsChangeEmitter,
 			getValue: () => this.getTarget().level,
@@ -350,6 +425,8 @@ class TargetPicker extends Input<Encounter, TargetProto> {
 		});
 
 		this.targetInputPickers = makeTargetInputsPicker(section1, encounter, this.targetIndex);
+		this.damageWindowPickers = makeDamageWindowsPicker(section1, encounter, this.targetIndex);
+		this.spellPickers = makeTargetSpellsPicker(section1, encounter, this.targetIndex);
 
 		this.statPickers = ALL_TARGET_STATS.map(statData => {
 			const stat = statData.stat;
@@ -399,6 +476,16 @@ class TargetPicker extends Input<Encounter, TargetProto> {
 				encounter.targetsChangeEmitter.emit(eventID);
 			},
 		});
+		this.requiredDpsPicker = new NumberPicker(section3, null, {
+			label: 'Required DPS',
+			labelTooltip: 'DPS this enemy needs to take from the simulated raid, e.g. to keep council bosses at even health. Results report how often it was met.',
+			changedEvent: () => encounter.targetsChangeEmitter,
+			getValue: () => this.getTarget().requiredDps,
+			setValue: (eventID: EventID, _: null, newValue: number) => {
+				this.getTarget().requiredDps = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+		});
 		this.dualWieldPicker = new BooleanPicker(section3, null, {
 			label: 'Dual Wield',
 			labelTooltip: 'Uses 2 separate weapons to attack.',
@@ -436,15 +523,7 @@ class TargetPicker extends Input<Encounter, TargetProto> {
 		this.spellSchoolPicker = new EnumPicker<null>(section3, null, {
 			label: 'Spell School',
 			labelTooltip: 'Type of damage caused by auto attacks. This is usually Physical, but some enemies have elemental attacks.',
-			values: [
-				{ name: 'Physical', value: SpellSchool.SpellSchoolPhysical },
-				{ name: 'Arcane', value: SpellSchool.SpellSchoolArcane },
-				{ name: 'Fire', value: SpellSchool.SpellSchoolFire },
-				{ name: 'Frost', value: SpellSchool.SpellSchoolFrost },
-				{ name: 'Holy', value: SpellSchool.SpellSchoolHoly },
-				{ name: 'Nature', value: SpellSchool.SpellSchoolNature },
-				{ name: 'Shadow', value: SpellSchool.SpellSchoolShadow },
-			],
+			values: spellSchoolEnumValues,
 			changedEvent: () => encounter.targetsChangeEmitter,
 			getValue: () => this.getTarget().spellSchool,
 			setValue: (eventID: EventID, _: null, newValue: number) => {
@@ -464,6 +543,17 @@ class TargetPicker extends Input<Encounter, TargetProto> {
 			},
 			enableWhen: () => this.getTarget().level == Mechanics.BOSS_LEVEL,
 		});
+		this.pvpPicker = new BooleanPicker(section3, null, {
+			label: 'PvP Target',
+			labelTooltip: 'Treats this enemy as a level 80 player: no glancing blows or crit suppression, and Resilience reduces critical strikes against it. Uses typical player armor if Armor is 0.',
+			inline: true,
+			changedEvent: () => encounter.targetsChangeEmitter,
+			getValue: () => this.getTarget().pvp,
+			setValue: (eventID: EventID, _: null, newValue: boolean) => {
+				this.getTarget().pvp = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+		});
 
 		this.init();
 	}
@@ -480,16 +570,20 @@ class TargetPicker extends Input<Encounter, TargetProto> {
 			swingSpeed: this.swingSpeedPicker.getInputValue(),
 			minBaseDamage: this.minBaseDamagePicker.getInputValue(),
 			suppressDodge: this.suppressDodgePicker.getInputValue(),
+			pvp: this.pvpPicker.getInputValue(),
 			dualWield: this.dualWieldPicker.getInputValue(),
 			dualWieldPenalty: this.dwMissPenaltyPicker.getInputValue(),
 			parryHaste: this.parryHastePicker.getInputValue(),
 			spellSchool: this.spellSchoolPicker.getInputValue(),
 			damageSpread: this.damageSpreadPicker.getInputValue(),
+			requiredDps: this.requiredDpsPicker.getInputValue(),
 			stats: this.statPickers
 				.map(picker => picker.getInputValue())
 				.map((statValue, i) => new Stats().withStat(ALL_TARGET_STATS[i].stat, statValue))
 				.reduce((totalStats, curStats) => totalStats.add(curStats)).asArray(),
 			targetInputs: this.targetInputPickers.getInputValue(),
+			damageWindows: this.damageWindowPickers.getInputValue(),
+			spells: this.spellPickers.getInputValue(),
 		});
 	}
 	setInputValue(newValue: TargetProto) {
@@ -503,13 +597,238 @@ class TargetPicker extends Input<Encounter, TargetProto> {
 		this.swingSpeedPicker.setInputValue(newValue.swingSpeed);
 		this.minBaseDamagePicker.setInputValue(newValue.minBaseDamage);
 		this.suppressDodgePicker.setInputValue(newValue.suppressDodge);
+		this.pvpPicker.setInputValue(newValue.pvp);
 		this.dualWieldPicker.setInputValue(newValue.dualWield);
 		this.dwMissPenaltyPicker.setInputValue(newValue.dualWieldPenalty);
 		this.parryHastePicker.setInputValue(newValue.parryHaste);
 		this.spellSchoolPicker.setInputValue(newValue.spellSchool);
 		this.damageSpreadPicker.setInputValue(newValue.damageSpread);
+		this.requiredDpsPicker.setInputValue(newValue.requiredDps);
 		ALL_TARGET_STATS.forEach((statData, i) => this.statPickers[i].setInputValue(newValue.stats[statData.stat]));
 		this.targetInputPickers.setInputValue(newValue.targetInputs);
+		this.damageWindowPickers.setInputValue(newValue.damageWindows);
+		this.spellPickers.setInputValue(newValue.spells);
+	}
+}
+
+type AddWaveNumberField = 'count' | 'spawnTime' | 'health' | 'armor' | 'lifetime';
+
+class AddWavePicker extends Input<Encounter, AddWave> {
+	private readonly encounter: Encounter;
+	private readonly waveIndex: number;
+
+	private readonly numberPickers: Array<[AddWaveNumberField, Input<null, number>]>;
+	private readonly mustDiePicker: Input<null, boolean>;
+
+	private getWave(): AddWave {
+		return this.encounter.addWaves[this.waveIndex] || AddWave.create();
+	}
+
+	constructor(parent: HTMLElement, encounter: Encounter, waveIndex: number, config: ListItemPickerConfig<Encounter, AddWave>) {
+		super(parent, 'add-wave-picker-root', encounter, config)
+		this.encounter = encounter;
+		this.waveIndex = waveIndex;
+
+		const fields: Array<{ field: AddWaveNumberField, label: string, tooltip: string, float: boolean }> = [
+			{ field: 'count', label: 'Count', tooltip: 'Number of adds in the wave.', float: false },
+			{ field: 'spawnTime', label: 'Spawn Time', tooltip: 'Seconds after the pull at which the adds spawn.', float: true },
+			{ field: 'health', label: 'Health', tooltip: 'Adds die once they\'ve taken this much damage. Adds without health can\'t be killed.', float: false },
+			{ field: 'armor', label: 'Armor', tooltip: '', float: false },
+			{ field: 'lifetime', label: 'Lifetime', tooltip: 'Seconds after spawning at which the adds despawn, if they\'re still alive. 0 keeps them until the end of the fight.', float: true },
+		];
+		this.numberPickers = fields.map(fieldData => [fieldData.field, new NumberPicker(this.rootElem, null, {
+			inline: true,
+			float: fieldData.float,
+			positive: true,
+			label: fieldData.label,
+			labelTooltip: fieldData.tooltip,
+			changedEvent: () => encounter.targetsChangeEmitter,
+			getValue: () => this.getWave()[fieldData.field],
+			setValue: (eventID: EventID, _: null, newValue: number) => {
+				this.getWave()[fieldData.field] = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+		})]);
+		this.mustDiePicker = new BooleanPicker(this.rootElem, null, {
+			label: 'Must Die',
+			labelTooltip: 'Adds stay until they\'re killed, regardless of their lifetime. Damage against them counts as priority damage.',
+			changedEvent: () => encounter.targetsChangeEmitter,
+			getValue: () => this.getWave().mustDie,
+			setValue: (eventID: EventID, _: null, newValue: boolean) => {
+				this.getWave().mustDie = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+		});
+
+		this.init();
+	}
+
+	getInputElem(): HTMLElement | null {
+		return null;
+	}
+	getInputValue(): AddWave {
+		const wave = AddWave.clone(this.getWave());
+		this.numberPickers.forEach(([field, picker]) => wave[field] = picker.getInputValue());
+		wave.mustDie = this.mustDiePicker.getInputValue();
+		return wave;
+	}
+	setInputValue(newValue: AddWave) {
+		if (!newValue) {
+			return;
+		}
+		this.numberPickers.forEach(([field, picker]) => picker.setInputValue(newValue[field]));
+		this.mustDiePicker.setInputValue(newValue.mustDie);
+	}
+}
+
+type LossOfControlEventNumberField = 'startTime' | 'interval' | 'duration';
+
+class LossOfControlEventPicker extends Input<Encounter, LossOfControlEvent> {
+	private readonly encounter: Encounter;
+	private readonly eventIndex: number;
+
+	private readonly typePicker: Input<null, number>;
+	private readonly numberPickers: Array<[LossOfControlEventNumberField, Input<null, number>]>;
+	private readonly diminishingReturnsPicker: Input<null, boolean>;
+
+	private getEvent(): LossOfControlEvent {
+		return this.encounter.lossOfControlEvents[this.eventIndex] || LossOfControlEvent.create();
+	}
+
+	constructor(parent: HTMLElement, encounter: Encounter, eventIndex: number, config: ListItemPickerConfig<Encounter, LossOfControlEvent>) {
+		super(parent, 'loss-of-control-picker-root', encounter, config)
+		this.encounter = encounter;
+		this.eventIndex = eventIndex;
+
+		this.typePicker = new EnumPicker<null>(this.rootElem, null, {
+			label: 'Type',
+			values: [
+				{ name: 'Stun', value: LossOfControlType.LossOfControlStun },
+				{ name: 'Silence', value: LossOfControlType.LossOfControlSilence },
+			],
+			changedEvent: () => encounter.targetsChangeEmitter,
+			getValue: () => this.getEvent().type,
+			setValue: (eventID: EventID, _: null, newValue: number) => {
+				this.getEvent().type = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+		});
+		const fields: Array<{ field: LossOfControlEventNumberField, label: string, tooltip: string }> = [
+			{ field: 'startTime', label: 'Start Time', tooltip: 'Seconds after the pull at which it\'s first applied.' },
+			{ field: 'interval', label: 'Interval', tooltip: 'Seconds between applications. 0 only applies it once.' },
+			{ field: 'duration', label: 'Duration', tooltip: 'Seconds it lasts, before diminishing returns.' },
+		];
+		this.numberPickers = fields.map(fieldData => [fieldData.field, new NumberPicker(this.rootElem, null, {
+			inline: true,
+			float: true,
+			positive: true,
+			label: fieldData.label,
+			labelTooltip: fieldData.tooltip,
+			changedEvent: () => encounter.targetsChangeEmitter,
+			getValue: () => this.getEvent()[fieldData.field],
+			setValue: (eventID: EventID, _: null, newValue: number) => {
+				this.getEvent()[fieldData.field] = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+		})]);
+		this.diminishingReturnsPicker = new BooleanPicker(this.rootElem, null, {
+			label: 'Diminishing Returns',
+			labelTooltip: 'Each application within 18s of the previous one ending lasts half as long, and the 4th one is resisted.',
+			changedEvent: () => encounter.targetsChangeEmitter,
+			getValue: () => this.getEvent().diminishingReturns,
+			setValue: (eventID: EventID, _: null, newValue: boolean) => {
+				this.getEvent().diminishingReturns = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+		});
+
+		this.init();
+	}
+
+	getInputElem(): HTMLElement | null {
+		return null;
+	}
+	getInputValue(): LossOfControlEvent {
+		const event = LossOfControlEvent.clone(this.getEvent());
+		event.type = this.typePicker.getInputValue();
+		this.numberPickers.forEach(([field, picker]) => event[field] = picker.getInputValue());
+		event.diminishingReturns = this.diminishingReturnsPicker.getInputValue();
+		return event;
+	}
+	setInputValue(newValue: LossOfControlEvent) {
+		if (!newValue) {
+			return;
+		}
+		this.typePicker.setInputValue(newValue.type);
+		this.numberPickers.forEach(([field, picker]) => picker.setInputValue(newValue[field]));
+		this.diminishingReturnsPicker.setInputValue(newValue.diminishingReturns);
+	}
+}
+
+type DowntimeWindowNumberField = 'startTime' | 'duration';
+
+class DowntimeWindowPicker extends Input<Encounter, DowntimeWindow> {
+	private readonly encounter: Encounter;
+	private readonly windowIndex: number;
+
+	private readonly numberPickers: Array<[DowntimeWindowNumberField, Input<null, number>]>;
+	private readonly drinkPicker: Input<null, boolean>;
+
+	private getWindow(): DowntimeWindow {
+		return this.encounter.downtimeWindows[this.windowIndex] || DowntimeWindow.create();
+	}
+
+	constructor(parent: HTMLElement, encounter: Encounter, windowIndex: number, config: ListItemPickerConfig<Encounter, DowntimeWindow>) {
+		super(parent, 'downtime-picker-root', encounter, config)
+		this.encounter = encounter;
+		this.windowIndex = windowIndex;
+
+		const fields: Array<{ field: DowntimeWindowNumberField, label: string, tooltip: string }> = [
+			{ field: 'startTime', label: 'Start Time', tooltip: 'Seconds after the pull at which the window starts.' },
+			{ field: 'duration', label: 'Duration', tooltip: 'Length of the window, in seconds.' },
+		];
+		this.numberPickers = fields.map(fieldData => [fieldData.field, new NumberPicker(this.rootElem, null, {
+			inline: true,
+			float: true,
+			positive: true,
+			label: fieldData.label,
+			labelTooltip: fieldData.tooltip,
+			changedEvent: () => encounter.targetsChangeEmitter,
+			getValue: () => this.getWindow()[fieldData.field],
+			setValue: (eventID: EventID, _: null, newValue: number) => {
+				this.getWindow()[fieldData.field] = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+		})]);
+		this.drinkPicker = new BooleanPicker(this.rootElem, null, {
+			label: 'Drink',
+			labelTooltip: 'Players with mana drink during the window.',
+			changedEvent: () => encounter.targetsChangeEmitter,
+			getValue: () => this.getWindow().drink,
+			setValue: (eventID: EventID, _: null, newValue: boolean) => {
+				this.getWindow().drink = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+		});
+
+		this.init();
+	}
+
+	getInputElem(): HTMLElement | null {
+		return null;
+	}
+	getInputValue(): DowntimeWindow {
+		const downtimeWindow = DowntimeWindow.clone(this.getWindow());
+		this.numberPickers.forEach(([field, picker]) => downtimeWindow[field] = picker.getInputValue());
+		downtimeWindow.drink = this.drinkPicker.getInputValue();
+		return downtimeWindow;
+	}
+	setInputValue(newValue: DowntimeWindow) {
+		if (!newValue) {
+			return;
+		}
+		this.numberPickers.forEach(([field, picker]) => picker.setInputValue(newValue[field]));
+		this.drinkPicker.setInputValue(newValue.drink);
 	}
 }
 
@@ -595,7 +914,7 @@ function addEncounterFieldPickers(rootElem: HTMLElement, encounter: Encounter, s
 		setValue: (eventID: EventID, encounter: Encounter, newValue: number) => {
 			encounter.setDuration(eventID, newValue);
 		},
-		enableWhen: (obj) => { return !encounter.getUseHealth() },
+		enableWhen: (obj) => { return !encounter.getUseHealth() && encounter.getDurationDistribution() != DurationDistribution.DurationDistributionEmpirical },
 	});
 	new NumberPicker(durationGroup, encounter, {
 		label: 'Duration +/-',
@@ -605,8 +924,34 @@ function addEncounterFieldPickers(rootElem: HTMLElement, encounter: Encounter, s
 		setValue: (eventID: EventID, encounter: Encounter, newValue: number) => {
 			encounter.setDurationVariation(eventID, newValue);
 		},
+		enableWhen: (obj) => { return !encounter.getUseHealth() && encounter.getDurationDistribution() != DurationDistribution.DurationDistributionEmpirical },
+	});
+	new EnumPicker<Encounter>(durationGroup, encounter, {
+		label: 'Duration Distribution',
+		labelTooltip: 'How the fight length of each sim iteration is picked. Uniform picks evenly between Duration - Duration +/- and Duration + Duration +/-. Normal uses Duration +/- as the standard deviation around Duration. Kill Times picks one of the given fight lengths.',
+		values: [
+			{ name: 'Uniform', value: DurationDistribution.DurationDistributionUniform },
+			{ name: 'Normal', value: DurationDistribution.DurationDistributionNormal },
+			{ name: 'Kill Times', value: DurationDistribution.DurationDistributionEmpirical },
+		],
+		changedEvent: (encounter: Encounter) => encounter.changeEmitter,
+		getValue: (encounter: Encounter) => encounter.getDurationDistribution(),
+		setValue: (eventID: EventID, encounter: Encounter, newValue: number) => {
+			encounter.setDurationDistribution(eventID, newValue);
+		},
 		enableWhen: (obj) => { return !encounter.getUseHealth() },
 	});
+	new NumberListPicker<Encounter>(durationGroup, encounter, {
+		label: 'Kill Times',
+		labelTooltip: 'Comma-separated fight lengths in seconds, e.g. from logs. Each sim iteration picks one of them.',
+		placeholder: '150,180,210',
+		changedEvent: (encounter: Encounter) => encounter.changeEmitter,
+		getValue: (encounter: Encounter) => encounter.getKillTimes(),
+		setValue: (eventID: EventID, encounter: Encounter, newValue: Array<number>) => {
+			encounter.setKillTimes(eventID, newValue);
+		},
+		showWhen: (obj) => { return encounter.getDurationDistribution() == DurationDistribution.DurationDistributionEmpirical },
+	});
 
 	if (showExecuteProportion) {
 		let executeGroup = Input.newGroupContainer();
@@ -621,7 +966,7 @@ function addEncounterFieldPickers(rootElem: HTMLElement, encounter: Encounter, s
 			setValue: (eventID: EventID, encounter: Encounter, newValue: number) => {
 				encounter.setExecuteProportion20(eventID, newValue / 100);
 			},
-			enableWhen: (obj) => { return !encounter.getUseHealth() },
+			enableWhen: (obj) => { return !encounter.getUseHealth() && !encounter.getExecuteFromHealth() },
 		});
 		new NumberPicker(executeGroup, encounter, {
 			label: 'Execute Duration 25 (%)',
@@ -631,7 +976,7 @@ function addEncounterFieldPickers(rootElem: HTMLElement, encounter: Encounter, s
 			setValue: (eventID: EventID, encounter: Encounter, newValue: number) => {
 				encounter.setExecuteProportion25(eventID, newValue / 100);
 			},
-			enableWhen: (obj) => { return !encounter.getUseHealth() },
+			enableWhen: (obj) => { return !encounter.getUseHealth() && !encounter.getExecuteFromHealth() },
 		});
 		new NumberPicker(executeGroup, encounter, {
 			label: 'Execute Duration 35 (%)',
@@ -641,8 +986,28 @@ function addEncounterFieldPickers(rootElem: HTMLElement, encounter: Encounter, s
 			setValue: (eventID: EventID, encounter: Encounter, newValue: number) => {
 				encounter.setExecuteProportion35(eventID, newValue / 100);
 			},
+			enableWhen: (obj) => { return !encounter.getUseHealth() && !encounter.getExecuteFromHealth() },
+		});
+		new BooleanPicker<Encounter>(executeGroup, encounter, {
+			label: 'Execute From Health',
+			labelTooltip: 'Targets enter execute range once the damage they\'ve taken brings their combined health below 35%, 25% and 20%, instead of after the durations above. The fight still lasts for the full duration.',
+			changedEvent: (encounter: Encounter) => encounter.changeEmitter,
+			getValue: (encounter: Encounter) => encounter.getExecuteFromHealth(),
+			setValue: (eventID: EventID, encounter: Encounter, newValue: boolean) => {
+				encounter.setExecuteFromHealth(eventID, newValue);
+			},
 			enableWhen: (obj) => { return !encounter.getUseHealth() },
 		});
+		new NumberPicker(executeGroup, encounter, {
+			label: 'Unsimulated Raid DPS',
+			labelTooltip: 'Damage per second dealt to the targets by the rest of the raid, which isn\'t simulated. Counts towards the targets\' health when using health or Execute From Health.',
+			changedEvent: (encounter: Encounter) => encounter.changeEmitter,
+			getValue: (encounter: Encounter) => encounter.getUnsimulatedRaidDps(),
+			setValue: (eventID: EventID, encounter: Encounter, newValue: number) => {
+				encounter.setUnsimulatedRaidDps(eventID, newValue);
+			},
+			enableWhen: (obj) => { return encounter.getUseHealth() || encounter.getExecuteFromHealth() },
+		});
 	}
 }
 
@@ -662,6 +1027,207 @@ function makeTargetInputsPicker(parent: HTMLElement, encounter: Encounter, targe
 	});
 }
 
+function makeDamageWindowsPicker(parent: HTMLElement, encounter: Encounter, targetIndex: number): ListPicker<Encounter, DamageWindow> {
+	return new ListPicker<Encounter, DamageWindow>(parent, encounter, {
+		title: 'Damage Windows',
+		titleTooltip: 'Scripted windows in which the target takes modified damage, e.g. shield phases during which it\'s immune, or vulnerability phases.',
+		itemLabel: 'Damage Window',
+		changedEvent: (encounter: Encounter) => encounter.targetsChangeEmitter,
+		getValue: (encounter: Encounter) => encounter.targets[targetIndex].damageWindows,
+		setValue: (eventID: EventID, encounter: Encounter, newValue: Array<DamageWindow>) => {
+			encounter.targets[targetIndex].damageWindows = newValue;
+			encounter.targetsChangeEmitter.emit(eventID);
+		},
+		newItem: () => DamageWindow.create({ duration: 10 }),
+		copyItem: (oldItem: DamageWindow) => DamageWindow.clone(oldItem),
+		newItemPicker: (parent: HTMLElement, listPicker: ListPicker<Encounter, DamageWindow>, index: number, config: ListItemPickerConfig<Encounter, DamageWindow>) => new DamageWindowPicker(parent, encounter, targetIndex, index, config),
+	});
+}
+
+class DamageWindowPicker extends Input<Encounter, DamageWindow> {
+	private readonly encounter: Encounter;
+	private readonly targetIndex: number;
+	private readonly windowIndex: number;
+
+	private readonly startTimePicker: Input<null, number>;
+	private readonly durationPicker: Input<null, number>;
+	private readonly multiplierPicker: Input<null, number>;
+	private readonly dotsUnaffectedPicker: Input<null, boolean>;
+
+	private getWindow(): DamageWindow {
+		return this.encounter.targets[this.targetIndex].damageWindows[this.windowIndex] || DamageWindow.create();
+	}
+
+	constructor(parent: HTMLElement, encounter: Encounter, targetIndex: number, windowIndex: number, config: ListItemPickerConfig<Encounter, DamageWindow>) {
+		super(parent, 'damage-window-picker-root', encounter, config)
+		this.encounter = encounter;
+		this.targetIndex = targetIndex;
+		this.windowIndex = windowIndex;
+
+		const makeNumberPicker = (label: string, labelTooltip: string, field: 'startTime' | 'duration' | 'damageTakenMultiplier') => new NumberPicker(this.rootElem, null, {
+			inline: true,
+			float: true,
+			positive: true,
+			label: label,
+			labelTooltip: labelTooltip,
+			changedEvent: () => encounter.targetsChangeEmitter,
+			getValue: () => this.getWindow()[field],
+			setValue: (eventID: EventID, _: null, newValue: number) => {
+				this.getWindow()[field] = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+		});
+		this.startTimePicker = makeNumberPicker('Start Time', 'Seconds after the pull at which the window starts.', 'startTime');
+		this.durationPicker = makeNumberPicker('Duration', 'Length of the window, in seconds.', 'duration');
+		this.multiplierPicker = makeNumberPicker('Damage Taken Multiplier', '0 makes the target immune during the window.', 'damageTakenMultiplier');
+		this.dotsUnaffectedPicker = new BooleanPicker(this.rootElem, null, {
+			label: 'DoTs Unaffected',
+			labelTooltip: 'DoTs keep ticking for their normal damage during the window.',
+			changedEvent: () => encounter.targetsChangeEmitter,
+			getValue: () => this.getWindow().dotsUnaffected,
+			setValue: (eventID: EventID, _: null, newValue: boolean) => {
+				this.getWindow().dotsUnaffected = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+		});
+
+		this.init();
+	}
+
+	getInputElem(): HTMLElement | null {
+		return null;
+	}
+	getInputValue(): DamageWindow {
+		return DamageWindow.create({
+			startTime: this.startTimePicker.getInputValue(),
+			duration: this.durationPicker.getInputValue(),
+			damageTakenMultiplier: this.multiplierPicker.getInputValue(),
+			dotsUnaffected: this.dotsUnaffectedPicker.getInputValue(),
+		});
+	}
+	setInputValue(newValue: DamageWindow) {
+		if (!newValue) {
+			return;
+		}
+		this.startTimePicker.setInputValue(newValue.startTime);
+		this.durationPicker.setInputValue(newValue.duration);
+		this.multiplierPicker.setInputValue(newValue.damageTakenMultiplier);
+		this.dotsUnaffectedPicker.setInputValue(newValue.dotsUnaffected);
+	}
+}
+
+function makeTargetSpellsPicker(parent: HTMLElement, encounter: Encounter, targetIndex: number): ListPicker<Encounter, TargetSpell> {
+	return new ListPicker<Encounter, TargetSpell>(parent, encounter, {
+		title: 'Spells',
+		titleTooltip: 'Spells the target casts on a fixed interval, e.g. frost bolts for fights which call for resistance gear. Magic spells are resisted based on the players\' resistances.',
+		itemLabel: 'Spell',
+		changedEvent: (encounter: Encounter) => encounter.targetsChangeEmitter,
+		getValue: (encounter: Encounter) => encounter.targets[targetIndex].spells,
+		setValue: (eventID: EventID, encounter: Encounter, newValue: Array<TargetSpell>) => {
+			encounter.targets[targetIndex].spells = newValue;
+			encounter.targetsChangeEmitter.emit(eventID);
+		},
+		newItem: () => TargetSpell.create({ school: SpellSchool.SpellSchoolFrost, minBaseDamage: 5000, interval: 10 }),
+		copyItem: (oldItem: TargetSpell) => TargetSpell.clone(oldItem),
+		newItemPicker: (parent: HTMLElement, listPicker: ListPicker<Encounter, TargetSpell>, index: number, config: ListItemPickerConfig<Encounter, TargetSpell>) => new TargetSpellPicker(parent, encounter, targetIndex, index, config),
+	});
+}
+
+class TargetSpellPicker extends Input<Encounter, TargetSpell> {
+	private readonly encounter: Encounter;
+	private readonly targetIndex: number;
+	private readonly spellIndex: number;
+
+	private readonly spellIdPicker: Input<null, number>;
+	private readonly schoolPicker: Input<null, number>;
+	private readonly minBaseDamagePicker: Input<null, number>;
+	private readonly damageSpreadPicker: Input<null, number>;
+	private readonly intervalPicker: Input<null, number>;
+	private readonly binaryPicker: Input<null, boolean>;
+	private readonly hitsAllPlayersPicker: Input<null, boolean>;
+
+	private getSpell(): TargetSpell {
+		return this.encounter.targets[this.targetIndex].spells[this.spellIndex] || TargetSpell.create();
+	}
+
+	constructor(parent: HTMLElement, encounter: Encounter, targetIndex: number, spellIndex: number, config: ListItemPickerConfig<Encounter, TargetSpell>) {
+		super(parent, 'target-spell-picker-root', encounter, config)
+		this.encounter = encounter;
+		this.targetIndex = targetIndex;
+		this.spellIndex = spellIndex;
+
+		const makeNumberPicker = (label: string, labelTooltip: string, field: 'spellId' | 'minBaseDamage' | 'damageSpread' | 'interval') => new NumberPicker(this.rootElem, null, {
+			inline: true,
+			float: field != 'spellId',
+			positive: true,
+			label: label,
+			labelTooltip: labelTooltip,
+			changedEvent: () => encounter.targetsChangeEmitter,
+			getValue: () => this.getSpell()[field],
+			setValue: (eventID: EventID, _: null, newValue: number) => {
+				this.getSpell()[field] = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+		});
+		const makeBooleanPicker = (label: string, labelTooltip: string, field: 'binary' | 'hitsAllPlayers') => new BooleanPicker(this.rootElem, null, {
+			label: label,
+			labelTooltip: labelTooltip,
+			changedEvent: () => encounter.targetsChangeEmitter,
+			getValue: () => this.getSpell()[field],
+			setValue: (eventID: EventID, _: null, newValue: boolean) => {
+				this.getSpell()[field] = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+		});
+
+		this.spellIdPicker = makeNumberPicker('Spell ID', 'Used for metrics.', 'spellId');
+		this.schoolPicker = new EnumPicker<null>(this.rootElem, null, {
+			label: 'Spell School',
+			values: spellSchoolEnumValues,
+			changedEvent: () => encounter.targetsChangeEmitter,
+			getValue: () => this.getSpell().school,
+			setValue: (eventID: EventID, _: null, newValue: number) => {
+				this.getSpell().school = newValue;
+				encounter.targetsChangeEmitter.emit(eventID);
+			},
+		});
+		this.minBaseDamagePicker = makeNumberPicker('Min Base Damage', 'Damage before resistances.', 'minBaseDamage');
+		this.damageSpreadPicker = makeNumberPicker('Damage Spread', 'Fractional spread of damage above the minimum, e.g. 0.2 for up to 20% more.', 'damageSpread');
+		this.intervalPicker = makeNumberPicker('Interval', 'Seconds between casts, starting one interval after the pull.', 'interval');
+		this.binaryPicker = makeBooleanPicker('Binary', 'Binary spells are either fully resisted or not at all, instead of being partially resisted.', 'binary');
+		this.hitsAllPlayersPicker = makeBooleanPicker('Hits All Players', 'Hits every player instead of only the target\'s current target.', 'hitsAllPlayers');
+
+		this.init();
+	}
+
+	getInputElem(): HTMLElement | null {
+		return null;
+	}
+	getInputValue(): TargetSpell {
+		return TargetSpell.create({
+			spellId: this.spellIdPicker.getInputValue(),
+			school: this.schoolPicker.getInputValue(),
+			minBaseDamage: this.minBaseDamagePicker.getInputValue(),
+			damageSpread: this.damageSpreadPicker.getInputValue(),
+			interval: this.intervalPicker.getInputValue(),
+			binary: this.binaryPicker.getInputValue(),
+			hitsAllPlayers: this.hitsAllPlayersPicker.getInputValue(),
+		});
+	}
+	setInputValue(newValue: TargetSpell) {
+		if (!newValue) {
+			return;
+		}
+		this.spellIdPicker.setInputValue(newValue.spellId);
+		this.schoolPicker.setInputValue(newValue.school);
+		this.minBaseDamagePicker.setInputValue(newValue.minBaseDamage);
+		this.damageSpreadPicker.setInputValue(newValue.damageSpread);
+		this.intervalPicker.setInputValue(newValue.interval);
+		this.binaryPicker.setInputValue(newValue.binary);
+		this.hitsAllPlayersPicker.setInputValue(newValue.hitsAllPlayers);
+	}
+}
+
 function equalTargetsIgnoreInputs(target1: TargetProto | undefined, target2: TargetProto | undefined): boolean {
 	if ((target1 == null) != (target2 == null)) {
 		return false;
@@ -684,6 +1250,17 @@ const ALL_TARGET_STATS: Array<{ stat: Stat, tooltip: string, extraCssClasses: Ar
 	{ stat: Stat.StatShadowResistance, tooltip: '', extraCssClasses: [] },
 	{ stat: Stat.StatAttackPower, tooltip: '', extraCssClasses: ['threat-metrics'] },
 	{ stat: Stat.StatBlockValue, tooltip: '', extraCssClasses: ['threat-metrics'] },
+	{ stat: Stat.StatResilience, tooltip: 'Only used by PvP targets.', extraCssClasses: [] },
+];
+
+const spellSchoolEnumValues = [
+	{ name: 'Physical', value: SpellSchool.SpellSchoolPhysical },
+	{ name: 'Arcane', value: SpellSchool.SpellSchoolArcane },
+	{ name: 'Fire', value: SpellSchool.SpellSchoolFire },
+	{ name: 'Frost', value: SpellSchool.SpellSchoolFrost },
+	{ name: 'Holy', value: SpellSchool.SpellSchoolHoly },
+	{ name: 'Nature', value: SpellSchool.SpellSchoolNature },
+	{ name: 'Shadow', value: SpellSchool.SpellSchoolShadow },
 ];
 
 const mobTypeEnumValues = [
@@ -18,6 +18,7 @@ import {
 	APLActionChangeTarget,
 	APLActionActivateAura,
 	APLActionCancelAura,
+	APLActionCancelCast,
 	APLActionTriggerICD,
 
 	APLValue,
@@ -391,6 +392,23 @@ const actionKindFactories: {[f in NonNullable<APLActionKind>]: ActionKindConfig<
 			}),
 		],
 	}),
+	['cancelCast']: inputBuilder({
+		label: 'Cancel Cast',
+		submenu: ['Casting'],
+		shortDescription: 'Stops the current cast or channel, e.g. to re-prioritize.',
+		fullDescription: `
+			<ul>
+				<li>A cancelled cast costs nothing and doesn't trigger its cooldown, but the GCD it triggered still has to finish.</li>
+				<li>A cancelled channel keeps its cost, and its remaining ticks are lost.</li>
+				<li>Only checked when the rotation is evaluated mid-cast, e.g. on mana ticks. To clip channels, prefer the <b>Interrupt If</b> of <b>Channel</b>.</li>
+			</ul>
+		`,
+		includeIf: (player: Player<any>, isPrepull: boolean) => !isPrepull,
+		newValue: () => APLActionCancelCast.create(),
+		fields: [
+			AplHelpers.actionIdFieldConfig('spellId', 'castable_spells', ''),
+		],
+	}),
 	['autocastOtherCooldowns']: inputBuilder({
 		label: 'Autocast Other Cooldowns',
 		submenu: ['Casting'],
@@ -31,6 +31,7 @@ import {
 	APLValueCurrentManaPercent,
 	APLValueCurrentRage,
 	APLValueCurrentEnergy,
+	APLValueEnergyTimeToNextTick,
 	APLValueCurrentComboPoints,
 	APLValueCurrentRunicPower,
 	APLValueCurrentRuneCount,
@@ -46,6 +47,7 @@ import {
 	APLValueSpellCanCast,
 	APLValueSpellIsReady,
 	APLValueSpellTimeToReady,
+	APLValueSpellCharges,
 	APLValueSpellCastTime,
 	APLValueSpellTravelTime,
 	APLValueSpellChannelTime,
@@ -70,6 +72,9 @@ import {
 	APLValueRuneCooldown,
 	APLValueNextRuneCooldown,
 	APLValueNumberTargets,
+	APLValueTargetDamageWindowMultiplier,
+	APLValueTargetTimeToDamageWindow,
+	APLValueTargetDamageWindowRemainingTime,
 	APLValueTotemRemainingTime,
 	APLValueCatExcessEnergy,
 	APLValueWarlockShouldRecastDrainSoul,
@@ -542,10 +547,37 @@ const valueKindFactories: {[f in NonNullable<APLValueKind>]: ValueKindConfig<APL
 	'numberTargets': inputBuilder({
 		label: 'Number of Targets',
 		submenu: ['Encounter'],
-		shortDescription: 'Count of targets in the current encounter',
+		shortDescription: 'Count of targets in the current encounter, including adds which are currently alive',
 		newValue: APLValueNumberTargets.create,
 		fields: [],
 	}),
+	'targetDamageWindowMultiplier': inputBuilder({
+		label: 'Damage Window Multiplier',
+		submenu: ['Encounter'],
+		shortDescription: 'Multiplier for direct damage taken by the target from its scripted damage windows, e.g. <b>0</b> while it\'s immune, or <b>1</b> outside of any windows.',
+		newValue: APLValueTargetDamageWindowMultiplier.create,
+		fields: [
+			AplHelpers.unitFieldConfig('targetUnit', 'targets'),
+		],
+	}),
+	'targetTimeToDamageWindow': inputBuilder({
+		label: 'Time to Damage Window',
+		submenu: ['Encounter'],
+		shortDescription: 'Time until the target\'s next scripted damage window starts, or <b>0</b> if one is currently active.',
+		newValue: APLValueTargetTimeToDamageWindow.create,
+		fields: [
+			AplHelpers.unitFieldConfig('targetUnit', 'targets'),
+		],
+	}),
+	'targetDamageWindowRemainingTime': inputBuilder({
+		label: 'Damage Window Remaining Time',
+		submenu: ['Encounter'],
+		shortDescription: 'Time until the target\'s active scripted damage windows end, or <b>0</b> if none are active.',
+		newValue: APLValueTargetDamageWindowRemainingTime.create,
+		fields: [
+			AplHelpers.unitFieldConfig('targetUnit', 'targets'),
+		],
+	}),
 	'frontOfTarget': inputBuilder({
 		label: 'Front of Target',
 		submenu: ['Encounter'],
@@ -601,6 +633,17 @@ const valueKindFactories: {[f in NonNullable<APLValueKind>]: ValueKindConfig<APL
 		newValue: APLValueCurrentEnergy.create,
 		fields: [],
 	}),
+	'energyTimeToNextTick': inputBuilder({
+		label: 'Time to Next Energy Tick',
+		submenu: ['Resources'],
+		shortDescription: 'Amount of time until the next energy tick.',
+		fullDescription: `
+			<p>Energy ticks every 100ms by default, so this mostly matters when the sim is set up with longer server ticks.</p>
+		`,
+		newValue: APLValueEnergyTimeToNextTick.create,
+		includeIf: (player: Player<any>, isPrepull: boolean) => player.getClass() == Class.ClassRogue || player.getClass() == Class.ClassDruid,
+		fields: [],
+	}),
 	'currentComboPoints': inputBuilder({
 		label: 'Combo Points',
 		submenu: ['Resources'],
@@ -774,6 +817,15 @@ const valueKindFactories: {[f in NonNullable<APLValueKind>]: ValueKindConfig<APL
 			AplHelpers.actionIdFieldConfig('spellId', 'castable_spells', ''),
 		],
 	}),
+	'spellCharges': inputBuilder({
+		label: 'Charges',
+		submenu: ['Spell'],
+		shortDescription: 'Number of stored uses of the spell which are ready. Spells without charges have <b>1</b> while off cooldown, otherwise <b>0</b>.',
+		newValue: APLValueSpellCharges.create,
+		fields: [
+			AplHelpers.actionIdFieldConfig('spellId', 'castable_spells', ''),
+		],
+	}),
 	'spellCastTime': inputBuilder({
 		label: 'Cast Time',
 		submenu: ['Spell'],
@@ -1,5 +1,9 @@
 import {
+	AddWave as AddWaveProto,
+	DowntimeWindow,
+	DurationDistribution,
 	Encounter as EncounterProto,
+	LossOfControlEvent,
 	MobType,
 	SpellSchool,
 	Stat,
@@ -14,6 +18,7 @@ import * as Mechanics from './constants/mechanics.js';
 import { Sim } from './sim.js';
 import { UnitMetadataList } from './player.js';
 import { EventID, TypedEvent } from './typed_event.js';
+import { arrayEquals } from './utils.js';
 
 // Manages all the settings for an Encounter.
 export class Encounter {
@@ -21,11 +26,19 @@ export class Encounter {
 
 	private duration: number = 180;
 	private durationVariation: number = 5;
+	private durationDistribution: DurationDistribution = DurationDistribution.DurationDistributionUniform;
+	private killTimes: Array<number> = [];
 	private executeProportion20: number = 0.2;
 	private executeProportion25: number = 0.25;
 	private executeProportion35: number = 0.35;
 	private useHealth: boolean = false;
+	private executeFromHealth: boolean = false;
+	private unsimulatedRaidDps: number = 0;
+	private separateHealthPools: boolean = false;
 	targets: Array<TargetProto>;
+	addWaves: Array<AddWaveProto> = [];
+	lossOfControlEvents: Array<LossOfControlEvent> = [];
+	downtimeWindows: Array<DowntimeWindow> = [];
 	targetsMetadata: UnitMetadataList;
 
 	readonly targetsChangeEmitter = new TypedEvent<void>();
@@ -62,6 +75,28 @@ export class Encounter {
 		this.durationChangeEmitter.emit(eventID);
 	}
 
+	getDurationDistribution(): DurationDistribution {
+		return this.durationDistribution;
+	}
+	setDurationDistribution(eventID: EventID, newDurationDistribution: DurationDistribution) {
+		if (newDurationDistribution == this.durationDistribution)
+			return;
+
+		this.durationDistribution = newDurationDistribution;
+		this.durationChangeEmitter.emit(eventID);
+	}
+
+	getKillTimes(): Array<number> {
+		return this.killTimes.slice();
+	}
+	setKillTimes(eventID: EventID, newKillTimes: Array<number>) {
+		if (arrayEquals(newKillTimes, this.killTimes))
+			return;
+
+		this.killTimes = newKillTimes.slice();
+		this.durationChangeEmitter.emit(eventID);
+	}
+
 	getDuration(): number {
 		return this.duration;
 	}
@@ -116,12 +151,47 @@ export class Encounter {
 		this.executeProportionChangeEmitter.emit(eventID);
 	}
 
+	getExecuteFromHealth(): boolean {
+		return this.executeFromHealth;
+	}
+	setExecuteFromHealth(eventID: EventID, newExecuteFromHealth: boolean) {
+		if (newExecuteFromHealth == this.executeFromHealth)
+			return;
+
+		this.executeFromHealth = newExecuteFromHealth;
+		this.executeProportionChangeEmitter.emit(eventID);
+	}
+
+	getUnsimulatedRaidDps(): number {
+		return this.unsimulatedRaidDps;
+	}
+	setUnsimulatedRaidDps(eventID: EventID, newUnsimulatedRaidDps: number) {
+		if (newUnsimulatedRaidDps == this.unsimulatedRaidDps)
+			return;
+
+		this.unsimulatedRaidDps = newUnsimulatedRaidDps;
+		this.executeProportionChangeEmitter.emit(eventID);
+	}
+
+	getSeparateHealthPools(): boolean {
+		return this.separateHealthPools;
+	}
+	setSeparateHealthPools(eventID: EventID, newSeparateHealthPools: boolean) {
+		if (newSeparateHealthPools == this.separateHealthPools)
+			return;
+
+		this.separateHealthPools = newSeparateHealthPools;
+		this.durationChangeEmitter.emit(eventID);
+	}
+
 	matchesPreset(preset: PresetEncounter): boolean {
-		return preset.targets.length == this.targets.length && this.targets.every((t, i) => TargetProto.equals(t, preset.targets[i].target));
+		return preset.targets.length == this.targets.length && this.targets.every((t, i) => TargetProto.equals(t, preset.targets[i].target))
+			&& preset.addWaves.length == this.addWaves.length && this.addWaves.every((w, i) => AddWaveProto.equals(w, preset.addWaves[i]));
 	}
 
 	applyPreset(eventID: EventID, preset: PresetEncounter) {
 		this.targets = preset.targets.map(presetTarget => presetTarget.target || TargetProto.create());
+		this.addWaves = preset.addWaves.map(wave => AddWaveProto.clone(wave));
 		this.targetsChangeEmitter.emit(eventID);
 	}
 
@@ -134,11 +204,19 @@ export class Encounter {
 		return EncounterProto.create({
 			duration: this.duration,
 			durationVariation: this.durationVariation,
+			durationDistribution: this.durationDistribution,
+			killTimes: this.killTimes,
 			executeProportion20: this.executeProportion20,
 			executeProportion25: this.executeProportion25,
 			executeProportion35: this.executeProportion35,
 			useHealth: this.useHealth,
+			executeFromHealth: this.executeFromHealth,
+			unsimulatedRaidDps: this.unsimulatedRaidDps,
+			separateHealthPools: this.separateHealthPools,
 			targets: this.targets,
+			addWaves: this.addWaves,
+			lossOfControlEvents: this.lossOfControlEvents,
+			downtimeWindows: this.downtimeWindows,
 		});
 	}
 
@@ -146,11 +224,19 @@ export class Encounter {
 		TypedEvent.freezeAllAndDo(() => {
 			this.setDuration(eventID, proto.duration);
 			this.setDurationVariation(eventID, proto.durationVariation);
+			this.setDurationDistribution(eventID, proto.durationDistribution);
+			this.setKillTimes(eventID, proto.killTimes);
 			this.setExecuteProportion20(eventID, proto.executeProportion20);
 			this.setExecuteProportion25(eventID, proto.executeProportion25);
 			this.setExecuteProportion35(eventID, proto.executeProportion35);
 			this.setUseHealth(eventID, proto.useHealth);
+			this.setExecuteFromHealth(eventID, proto.executeFromHealth);
+			this.setUnsimulatedRaidDps(eventID, proto.unsimulatedRaidDps);
+			this.setSeparateHealthPools(eventID, proto.separateHealthPools);
 			this.targets = proto.targets;
+			this.addWaves = proto.addWaves;
+			this.lossOfControlEvents = proto.lossOfControlEvents;
+			this.downtimeWindows = proto.downtimeWindows;
 			this.targetsChangeEmitter.emit(eventID);
 		});
 	}
@@ -109,6 +109,18 @@ export class ActionId {
 				baseName = 'Potion';
 				iconUrl = 'https://wow.zamimg.com/images/wow/icons/large/inv_alchemy_elixir_04.jpg';
 				break;
+			case OtherAction.OtherActionStun:
+				baseName = 'Stunned';
+				iconUrl = 'https://wow.zamimg.com/images/wow/icons/large/spell_frost_stun.jpg';
+				break;
+			case OtherAction.OtherActionSilence:
+				baseName = 'Silenced';
+				iconUrl = 'https://wow.zamimg.com/images/wow/icons/large/spell_shadow_impphaseshift.jpg';
+				break;
+			case OtherAction.OtherActionOutOfCombat:
+				baseName = 'Out of Combat';
+				iconUrl = 'https://wow.zamimg.com/images/wow/icons/large/inv_misc_pocketwatch_01.jpg';
+				break;
 		}
 		this.baseName = baseName;
 		this.name = name || baseName;
@@ -222,6 +222,9 @@ export const pseudoStatNames: Map<PseudoStat, string> = new Map([
 	[PseudoStat.PseudoStatBlockValueMultiplier, 'Block Value Multiplier'],
 	[PseudoStat.PseudoStatDodge, 'Dodge Chance'],
 	[PseudoStat.PseudoStatParry, 'Parry Chance'],
+	[PseudoStat.PseudoStatMeleeHaste, 'Melee Haste'],
+	[PseudoStat.PseudoStatRangedHaste, 'Ranged Haste'],
+	[PseudoStat.PseudoStatSpellHaste, 'Spell Haste'],
 ]);
 
 export function getClassStatName(stat: Stat, playerClass: Class): string {
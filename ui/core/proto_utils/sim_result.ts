@@ -469,7 +469,10 @@ export class UnitMetrics {
 			action.unit = playerMetrics;
 			action.resources = resources.filter(resourceMetrics => resourceMetrics.actionId.equals(action.actionId));
 		});
-		auras.forEach(aura => aura.unit = playerMetrics);
+		auras.forEach(aura => {
+			aura.unit = playerMetrics;
+			aura.setSuppressedPercent(metrics);
+		});
 		resources.forEach(resource => resource.unit = playerMetrics);
 		return playerMetrics;
 	}
@@ -485,7 +488,10 @@ export class UnitMetrics {
 
 		const targetMetrics = new UnitMetrics(null, target, null, metrics, index, actions, auras, [], [], targetLogs, resultData);
 		actions.forEach(action => action.unit = targetMetrics);
-		auras.forEach(aura => aura.unit = targetMetrics);
+		auras.forEach(aura => {
+			aura.unit = targetMetrics;
+			aura.setSuppressedPercent(metrics);
+		});
 		return targetMetrics;
 	}
 }
@@ -531,6 +537,10 @@ export class AuraMetrics {
 	private readonly duration: number;
 	private readonly data: AuraMetricsProto;
 
+	// Uptime during which a stronger buff or debuff of the same kind applied
+	// instead, see ExclusiveEffectMetrics.
+	suppressedPercent: number = 0;
+
 	private constructor(unit: UnitMetrics | null, actionId: ActionId, data: AuraMetricsProto, resultData: SimResultData) {
 		this.unit = unit;
 		this.actionId = actionId;
@@ -543,17 +553,35 @@ export class AuraMetrics {
 	}
 
 	get uptimePercent() {
-		return this.data.uptimeSecondsAvg / this.duration * 100;
+		// Results from before uptimePercentAvg was added only have the uptime in seconds.
+		return this.data.uptimePercentAvg || this.data.uptimeSecondsAvg / this.duration * 100;
 	}
 
 	get averageProcs() {
 		return this.data.procsAvg
 	}
 
+	get averageApplications() {
+		return this.data.applicationsAvg;
+	}
+
+	get averageStacks() {
+		return this.data.stacksAvg;
+	}
+
+	get refreshWastedSeconds() {
+		return this.data.refreshWastedSecondsAvg;
+	}
+
 	get ppm() {
 		return this.data.procsAvg / (this.duration / 60);
 	}
 
+	setSuppressedPercent(unitMetrics: UnitMetricsProto) {
+		const effects = unitMetrics.exclusiveEffects.filter(effect => ActionId.fromProto(effect.id!).equalsIgnoringTag(this.actionId));
+		this.suppressedPercent = Math.max(0, ...effects.map(effect => effect.suppressedPercentAvg));
+	}
+
 	static async makeNew(unit: UnitMetrics | null, resultData: SimResultData, auraMetrics: AuraMetricsProto, playerIndex?: number): Promise<AuraMetrics> {
 		const actionId = await ActionId.fromProto(auraMetrics.id!).fill(playerIndex);
 		return new AuraMetrics(unit, actionId, auraMetrics, resultData);
@@ -567,13 +595,20 @@ export class AuraMetrics {
 		if (removeTag) {
 			actionId = actionId.withoutTag();
 		}
-		return new AuraMetrics(
+		const merged = new AuraMetrics(
 			unit,
 			actionId,
 			AuraMetricsProto.create({
 				uptimeSecondsAvg: Math.max(...auras.map(a => a.data.uptimeSecondsAvg)),
+				uptimePercentAvg: Math.max(...auras.map(a => a.data.uptimePercentAvg)),
+				procsAvg: sum(auras.map(a => a.data.procsAvg)),
+				applicationsAvg: sum(auras.map(a => a.data.applicationsAvg)),
+				stacksAvg: Math.max(...auras.map(a => a.data.stacksAvg)),
+				refreshWastedSecondsAvg: sum(auras.map(a => a.data.refreshWastedSecondsAvg)),
 			}),
 			firstAura.resultData);
+		merged.suppressedPercent = Math.max(...auras.map(a => a.suppressedPercent));
+		return merged;
 	}
 
 	// Groups similar metrics, i.e. metrics with the same item/spell/other ID but
@@ -865,12 +900,14 @@ export class ActionMetrics {
 	}
 
 	// Groups similar metrics, i.e. metrics with the same item/spell/other ID but
-	// different tags, and returns them as separate arrays.
+	// different tags, and returns them as separate arrays. Lower ranks of a
+	// spell are grouped with its max rank.
 	static groupById(actions: Array<ActionMetrics>, useTag?: boolean): Array<Array<ActionMetrics>> {
+		const groupId = (action: ActionMetrics) => action.data.rankOf ? ActionId.fromProto(action.data.rankOf) : action.actionId;
 		if (useTag) {
-			return Object.values(bucket(actions, action => action.actionId.toString()));
+			return Object.values(bucket(actions, action => groupId(action).toString()));
 		} else {
-			return Object.values(bucket(actions, action => action.actionId.toStringIgnoringTag()));
+			return Object.values(bucket(actions, action => groupId(action).toStringIgnoringTag()));
 		}
 	}
 
@@ -1034,6 +1071,14 @@ export class TargetedActionMetrics {
 				healing: sum(actions.map(a => a.data.healing)),
 				shielding: sum(actions.map(a => a.data.shielding)),
 				castTimeMs: sum(actions.map(a => a.data.castTimeMs)),
+				damageBuckets: sumBuckets(actions.map(a => a.data.damageBuckets)),
 			}));
 	}
 }
+
+// Element-wise sum of damage buckets, which may have different lengths.
+function sumBuckets(buckets: Array<Array<number>>): Array<number> {
+	const total: Array<number> = [];
+	buckets.forEach(b => b.forEach((damage, i) => total[i] = (total[i] || 0) + damage));
+	return total;
+}
@@ -6,10 +6,17 @@ import { ItemSpec } from './proto/common.js';
 import { ItemType } from './proto/common.js';
 import { Stat } from './proto/common.js';
 
+import { AttackTableRequest, AttackTableResult } from './proto/api.js';
 import { ComputeStatsRequest, ComputeStatsResult } from './proto/api.js';
+import { DiffResultsRequest, DiffResultsResult } from './proto/api.js';
+import { EstimateMemoryRequest, EstimateMemoryResult } from './proto/api.js';
 import { RaidSimRequest, RaidSimResult, ProgressMetrics } from './proto/api.js';
+import { StatSheetRequest, StatSheetResult } from './proto/api.js';
+import { CooldownExportRequest, CooldownExportResult } from './proto/api.js';
 import { StatWeightsRequest, StatWeightsResult } from './proto/api.js';
 import { BulkSimRequest, BulkSimResult } from './proto/api.js';
+import { BuffContributionRequest, BuffContributionResult } from './proto/api.js';
+import { SpecComparisonRequest, SpecComparisonResult, WhatIfRequest, WhatIfResult } from './proto/api.js';
 
 import { wait } from './utils.js';
 
@@ -40,6 +47,31 @@ export class WorkerPool {
 		return ComputeStatsResult.fromBinary(result);
 	}
 
+	async attackTable(request: AttackTableRequest): Promise<AttackTableResult> {
+		const result = await this.makeApiCall('attackTable', AttackTableRequest.toBinary(request));
+		return AttackTableResult.fromBinary(result);
+	}
+
+	async statSheet(request: StatSheetRequest): Promise<StatSheetResult> {
+		const result = await this.makeApiCall('statSheet', StatSheetRequest.toBinary(request));
+		return StatSheetResult.fromBinary(result);
+	}
+
+	async diffResults(request: DiffResultsRequest): Promise<DiffResultsResult> {
+		const result = await this.makeApiCall('diffResults', DiffResultsRequest.toBinary(request));
+		return DiffResultsResult.fromBinary(result);
+	}
+
+	async exportCooldowns(request: CooldownExportRequest): Promise<CooldownExportResult> {
+		const result = await this.makeApiCall('exportCooldowns', CooldownExportRequest.toBinary(request));
+		return CooldownExportResult.fromBinary(result);
+	}
+
+	async estimateMemory(request: EstimateMemoryRequest): Promise<EstimateMemoryResult> {
+		const result = await this.makeApiCall('estimateMemory', EstimateMemoryRequest.toBinary(request));
+		return EstimateMemoryResult.fromBinary(result);
+	}
+
 	async statWeightsAsync(request: StatWeightsRequest, onProgress: Function): Promise<StatWeightsResult> {
 		console.log('Stat weights request: ' + StatWeightsRequest.toJsonString(request));
 		const worker = this.getLeastBusyWorker();
@@ -69,6 +101,48 @@ export class WorkerPool {
 		return result.finalBulkResult!;
 	}
 
+	async whatIfAsync(request: WhatIfRequest, onProgress: Function): Promise<WhatIfResult> {
+		console.log('What-if request: ' + WhatIfRequest.toJsonString(request));
+		const worker = this.getLeastBusyWorker();
+		const id = worker.makeTaskId();
+		// Add handler for the progress events
+		worker.addPromiseFunc(id + "progress", this.newProgressHandler(id, worker, onProgress), (err) => { })
+
+		// Now start the async sim
+		const resultData = await worker.doApiCall('whatIfAsync', WhatIfRequest.toBinary(request), id);
+		const result = ProgressMetrics.fromBinary(resultData)
+		console.log('What-if result: ' + WhatIfResult.toJsonString(result.finalWhatIfResult!));
+		return result.finalWhatIfResult!;
+	}
+
+	async specComparisonAsync(request: SpecComparisonRequest, onProgress: Function): Promise<SpecComparisonResult> {
+		console.log('Spec comparison request: ' + SpecComparisonRequest.toJsonString(request));
+		const worker = this.getLeastBusyWorker();
+		const id = worker.makeTaskId();
+		// Add handler for the progress events
+		worker.addPromiseFunc(id + "progress", this.newProgressHandler(id, worker, onProgress), (err) => { })
+
+		// Now start the async sim
+		const resultData = await worker.doApiCall('specComparisonAsync', SpecComparisonRequest.toBinary(request), id);
+		const result = ProgressMetrics.fromBinary(resultData)
+		console.log('Spec comparison result: ' + SpecComparisonResult.toJsonString(result.finalSpecComparisonResult!));
+		return result.finalSpecComparisonResult!;
+	}
+
+	async buffContributionAsync(request: BuffContributionRequest, onProgress: Function): Promise<BuffContributionResult> {
+		console.log('Buff contribution request: ' + BuffContributionRequest.toJsonString(request));
+		const worker = this.getLeastBusyWorker();
+		const id = worker.makeTaskId();
+		// Add handler for the progress events
+		worker.addPromiseFunc(id + "progress", this.newProgressHandler(id, worker, onProgress), (err) => { })
+
+		// Now start the async sim
+		const resultData = await worker.doApiCall('buffContributionAsync', BuffContributionRequest.toBinary(request), id);
+		const result = ProgressMetrics.fromBinary(resultData)
+		console.log('Buff contribution result: ' + BuffContributionResult.toJsonString(result.finalBuffContributionResult!));
+		return result.finalBuffContributionResult!;
+	}
+
 	async raidSimAsync(request: RaidSimRequest, onProgress: Function): Promise<RaidSimResult> {
 		console.log('Raid sim request: ' + RaidSimRequest.toJsonString(request));
 		const worker = this.getLeastBusyWorker();
@@ -92,7 +166,7 @@ export class WorkerPool {
 			var progress = ProgressMetrics.fromBinary(progressData);
 			onProgress(progress);
 			// If we are done, stop adding the handler.
-			if (progress.finalRaidResult != null || progress.finalWeightResult != null) {
+			if (progress.finalRaidResult != null || progress.finalWeightResult != null || progress.finalWhatIfResult != null || progress.finalSpecComparisonResult != null || progress.finalBuffContributionResult != null) {
 				return;
 			}
 
@@ -35,10 +35,14 @@ addEventListener('message', async (e) => {
 				});
 			});
 		}],
+		['attackTable', attackTable],
 		['computeStats', computeStats],
 		['computeStatsJson', computeStatsJson],
+		['diffResults', diffResults],
+		['estimateMemory', estimateMemory],
 		['raidSim', raidSim],
 		['raidSimJson', raidSimJson],
+		['statSheet', statSheet],
 		['raidSimAsync', (data) => {
 			return raidSimAsync(data, (result) => {
 				postMessage({
@@ -58,6 +62,33 @@ addEventListener('message', async (e) => {
 				});
 			});
 		}],
+		['whatIfAsync', (data) => {
+			return whatIfAsync(data, (result) => {
+				postMessage({
+					msg: "progress",
+					outputData: result,
+					id: id + "progress",
+				});
+			});
+		}],
+		['specComparisonAsync', (data) => {
+			return specComparisonAsync(data, (result) => {
+				postMessage({
+					msg: "progress",
+					outputData: result,
+					id: id + "progress",
+				});
+			});
+		}],
+		['buffContributionAsync', (data) => {
+			return buffContributionAsync(data, (result) => {
+				postMessage({
+					msg: "progress",
+					outputData: result,
+					id: id + "progress",
+				});
+			});
+		}],
 	].forEach(funcData => {
 		const funcName = funcData[0];
 		const func = funcData[1];